@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lucas-soria/microblogging/internal/analytics"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+)
+
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
+// analytics-replay rebuilds the analytics state derived from the stored events
+func main() {
+	restart := flag.Bool("restart", false, "Start over instead of resuming an interrupted replay")
+	batchSize := flag.Int("batch-size", 1000, "Number of events replayed per transaction")
+	flag.Parse()
+
+	// Get database configuration from environment variables
+	dbHost := getEnv("DB_HOST", "postgres-primary")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbUser := getEnv("DB_USER", "postgres")
+	dbPassword := getEnv("DB_PASSWORD", "")
+	dbName := getEnv("DB_NAME", "not-found")
+
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
+		dbUser, dbPassword, dbHost, dbPort, dbName)
+
+	log.Println("Initializing analytics database connection")
+	db, err := database.NewPostgresClient(dsn)
+	if err != nil {
+		log.Fatalf("Failed to initialize analytics database: %v", err)
+	}
+
+	analyticsRepo := analytics.NewPostgresAnalyticsRepository(db)
+
+	// Stop between batches on interrupt, the replay can be resumed later
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	replayer := analytics.NewReplayer(analyticsRepo, *batchSize)
+	if err := replayer.Replay(ctx, *restart); err != nil {
+		log.Fatalf("Failed to replay events: %v", err)
+	}
+}
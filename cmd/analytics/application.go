@@ -7,12 +7,14 @@ import (
 // Application holds the dependencies for the HTTP server
 type Application struct {
 	analyticsHandler *handlers.AnalyticsHandler
+	roleSecret       string // secret the gateway signs the roles with
 }
 
 // NewApplication creates a new HTTP server and sets up routing
-func NewApplication(analyticsHandler *handlers.AnalyticsHandler) *Application {
+func NewApplication(analyticsHandler *handlers.AnalyticsHandler, roleSecret string) *Application {
 	application := &Application{
 		analyticsHandler: analyticsHandler,
+		roleSecret:       roleSecret,
 	}
 
 	return application
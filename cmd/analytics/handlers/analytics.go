@@ -1,10 +1,18 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/lucas-soria/microblogging/internal/analytics"
+	"github.com/lucas-soria/microblogging/pkg/auth"
 )
 
 // AnalyticsHandler handles HTTP requests for analytics
@@ -21,9 +29,32 @@ func NewAnalyticsHandler(service analytics.Service) *AnalyticsHandler {
 
 // GetUserAnalyticsResponse represents the response for GetUserAnalytics
 type GetUserAnalyticsResponse struct {
-	Handler      string `json:"handler"`
-	IsInfluencer bool   `json:"is_influencer"`
-	IsActive     bool   `json:"is_active"`
+	Handler            string     `json:"handler"`
+	IsInfluencer       bool       `json:"is_influencer"`
+	IsActive           bool       `json:"is_active"`
+	TweetCount         int64      `json:"tweet_count"`
+	FollowerCount      int64      `json:"follower_count"`
+	EngagementScore    float64    `json:"engagement_score"`
+	LastActivityAt     *time.Time `json:"last_activity_at"`
+	LastTimelineViewAt *time.Time `json:"last_timeline_view_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// newGetUserAnalyticsResponse converts the user analytics to their response
+func newGetUserAnalyticsResponse(a *analytics.UserAnalytics) GetUserAnalyticsResponse {
+	return GetUserAnalyticsResponse{
+		Handler:            a.Handler,
+		IsInfluencer:       a.IsInfluencer,
+		IsActive:           a.IsActive,
+		TweetCount:         a.TweetCount,
+		FollowerCount:      a.FollowerCount,
+		EngagementScore:    a.EngagementScore,
+		LastActivityAt:     a.LastActivityAt,
+		LastTimelineViewAt: a.LastTimelineViewAt,
+		CreatedAt:          a.CreatedAt,
+		UpdatedAt:          a.UpdatedAt,
+	}
 }
 
 // GetUserAnalytics handles GET /v1/analytics/users/:id
@@ -38,41 +69,214 @@ func (handler *AnalyticsHandler) GetUserAnalytics(ctx *gin.Context) {
 	}
 
 	// Return response
-	response := GetUserAnalyticsResponse{
-		Handler:      analytics.Handler,
-		IsInfluencer: analytics.IsInfluencer,
-		IsActive:     analytics.IsActive,
-	}
-
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, newGetUserAnalyticsResponse(analytics))
 }
 
 // GetAllUserAnalytics handles GET /v1/analytics/users
 func (handler *AnalyticsHandler) GetAllUserAnalytics(c *gin.Context) {
+	// Parse query parameters
+	filter, ok := parseUserAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+	limit, errLimit := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if errLimit != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+	filter.Limit = limit
+
 	// Call service
-	analyticsList, err := handler.service.GetAllUserAnalytics(c.Request.Context())
+	page, err := handler.service.GetAllUserAnalytics(c.Request.Context(), filter, c.Query("cursor"))
 	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidUserAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user analytics"})
 		return
 	}
 
 	// Convert to response type
-	response := make([]GetUserAnalyticsResponse, 0, len(analyticsList))
-	for _, a := range analyticsList {
-		response = append(response, GetUserAnalyticsResponse{
-			Handler:      a.Handler,
-			IsInfluencer: a.IsInfluencer,
-			IsActive:     a.IsActive,
-		})
+	response := make([]GetUserAnalyticsResponse, 0, len(page.Users))
+	for _, a := range page.Users {
+		response = append(response, newGetUserAnalyticsResponse(a))
 	}
 
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
 	c.JSON(http.StatusOK, response)
 }
 
+// ExportUserAnalytics handles GET /v1/analytics/users/export, streaming the filtered user analytics as CSV or NDJSON
+func (handler *AnalyticsHandler) ExportUserAnalytics(c *gin.Context) {
+	// Parse query parameters
+	filter, ok := parseUserAnalyticsFilter(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format parameter"})
+		return
+	}
+
+	// The headers are only written with the first batch, so an error before it is still reported as JSON
+	var csvWriter *csv.Writer
+	encoder := json.NewEncoder(c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv")
+		} else {
+			c.Header("Content-Type", "application/x-ndjson")
+		}
+		c.Header("Content-Disposition", `attachment; filename="user-analytics.`+format+`"`)
+		c.Status(http.StatusOK)
+		if format == "csv" {
+			csvWriter = csv.NewWriter(c.Writer)
+			return csvWriter.Write([]string{
+				"handler", "is_influencer", "is_active", "tweet_count", "follower_count", "engagement_score",
+				"last_activity_at", "last_timeline_view_at", "created_at", "updated_at",
+			})
+		}
+		return nil
+	}
+
+	// Call service
+	err := handler.service.ExportUserAnalytics(c.Request.Context(), filter, func(users []*analytics.UserAnalytics) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, a := range users {
+			if csvWriter != nil {
+				record := []string{
+					a.Handler,
+					strconv.FormatBool(a.IsInfluencer),
+					strconv.FormatBool(a.IsActive),
+					strconv.FormatInt(a.TweetCount, 10),
+					strconv.FormatInt(a.FollowerCount, 10),
+					strconv.FormatFloat(a.EngagementScore, 'f', -1, 64),
+					formatOptionalTime(a.LastActivityAt),
+					formatOptionalTime(a.LastTimelineViewAt),
+					a.CreatedAt.UTC().Format(time.RFC3339),
+					a.UpdatedAt.UTC().Format(time.RFC3339),
+				}
+				if err := csvWriter.Write(record); err != nil {
+					return err
+				}
+			} else if err := encoder.Encode(a); err != nil {
+				return err
+			}
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if started {
+			// The response is already being streamed, the client sees a truncated export
+			log.Printf("failed to export user analytics: %v", err)
+			return
+		}
+		if errors.Is(err, analytics.ErrInvalidUserAnalyticsQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export user analytics"})
+		return
+	}
+
+	// An empty export still has the CSV header
+	if !started {
+		if err := start(); err != nil {
+			log.Printf("failed to export user analytics: %v", err)
+			return
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+		}
+	}
+}
+
+// formatOptionalTime formats a time as RFC 3339 in UTC, or as an empty string if it is missing
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// parseUserAnalyticsFilter parses the filter and sort query parameters, responding with an error if any is invalid
+func parseUserAnalyticsFilter(c *gin.Context) (analytics.UserAnalyticsFilter, bool) {
+	var filter analytics.UserAnalyticsFilter
+	var err error
+
+	if filter.IsInfluencer, err = parseBoolQuery(c, "is_influencer"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid is_influencer parameter"})
+		return filter, false
+	}
+	if filter.IsActive, err = parseBoolQuery(c, "is_active"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid is_active parameter"})
+		return filter, false
+	}
+	if filter.UpdatedSince, err = parseTimeQuery(c, "updated_since"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid updated_since parameter"})
+		return filter, false
+	}
+	if filter.UpdatedUntil, err = parseTimeQuery(c, "updated_until"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid updated_until parameter"})
+		return filter, false
+	}
+
+	// A leading "-" sorts in descending order
+	filter.SortBy, filter.Descending = strings.CutPrefix(c.Query("sort"), "-")
+
+	return filter, true
+}
+
+// parseBoolQuery parses an optional boolean query parameter, it returns nil if it is missing
+func parseBoolQuery(c *gin.Context, name string) (*bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// parseTimeQuery parses an optional RFC 3339 time query parameter, it returns the zero time if it is missing
+func parseTimeQuery(c *gin.Context, name string) (time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // DeleteUserAnalytics handles DELETE /v1/analytics/users/:id
 func (handler *AnalyticsHandler) DeleteUserAnalytics(ctx *gin.Context) {
 	userID := ctx.Param("id")
 
+	// Only the owner of the account or an admin can delete its analytics
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to delete the analytics of this user"})
+		return
+	}
+
 	// Call service
 	err := handler.service.DeleteUserAnalytics(ctx.Request.Context(), userID)
 	if err != nil {
@@ -82,3 +286,365 @@ func (handler *AnalyticsHandler) DeleteUserAnalytics(ctx *gin.Context) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// requireAdmin responds with 403 and returns false unless the caller is an admin
+func requireAdmin(ctx *gin.Context) bool {
+	caller := auth.CallerFrom(ctx)
+	if !caller.IsAdmin() {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Admin role required"})
+		return false
+	}
+	return true
+}
+
+// GetTrends handles GET /v1/analytics/trends
+func (handler *AnalyticsHandler) GetTrends(ctx *gin.Context) {
+	// Parse query parameters
+	window, errWindow := time.ParseDuration(ctx.DefaultQuery("window", "1h"))
+	if errWindow != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window parameter"})
+		return
+	}
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "10"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	// Call service
+	trends, err := handler.service.GetTrends(ctx.Request.Context(), window, limit)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidTrendsWindow) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get trends"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trends)
+}
+
+// GetMetricsResponse represents the response for GetMetrics
+type GetMetricsResponse struct {
+	Metric      string                   `json:"metric"`
+	Granularity string                   `json:"granularity"`
+	Points      []*analytics.MetricPoint `json:"points"`
+}
+
+// GetMetrics handles GET /v1/analytics/metrics
+func (handler *AnalyticsHandler) GetMetrics(ctx *gin.Context) {
+	// Parse query parameters, by default the last 30 days
+	metric := ctx.Query("metric")
+	granularity := ctx.DefaultQuery("granularity", analytics.GranularityDay)
+	today := time.Now().UTC().Format(time.DateOnly)
+	to, errTo := time.Parse(time.DateOnly, ctx.DefaultQuery("to", today))
+	if errTo != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to parameter"})
+		return
+	}
+	from, errFrom := time.Parse(time.DateOnly, ctx.DefaultQuery("from", to.AddDate(0, 0, -29).Format(time.DateOnly)))
+	if errFrom != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from parameter"})
+		return
+	}
+
+	// Call service
+	points, err := handler.service.GetMetrics(ctx.Request.Context(), metric, granularity, from, to)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidMetricsQuery) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get metrics"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetMetricsResponse{
+		Metric:      metric,
+		Granularity: granularity,
+		Points:      points,
+	})
+}
+
+// GetTweetStats handles GET /v1/analytics/tweets/:id
+func (handler *AnalyticsHandler) GetTweetStats(ctx *gin.Context) {
+	tweetID := ctx.Param("id")
+
+	// Parse query parameters
+	days, errDays := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+	if errDays != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days parameter"})
+		return
+	}
+
+	// Call service
+	stats, err := handler.service.GetTweetStats(ctx.Request.Context(), tweetID, days)
+	if err != nil {
+		if errors.Is(err, analytics.ErrTweetNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tweet stats"})
+		return
+	}
+
+	// Only the author of the tweet or an admin can get its stats
+	if !auth.CallerFrom(ctx).CanManageUser(stats.Handler) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to get the stats of this tweet"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetUserTweetStats handles GET /v1/analytics/users/:id/tweets
+func (handler *AnalyticsHandler) GetUserTweetStats(ctx *gin.Context) {
+	userID := ctx.Param("id")
+
+	// Only the owner of the account or an admin can get the stats of its tweets
+	if !auth.CallerFrom(ctx).CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Not allowed to get the tweet stats of this user"})
+		return
+	}
+
+	// Parse query parameters
+	days, errDays := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+	if errDays != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days parameter"})
+		return
+	}
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errOffset != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// Call service
+	stats, err := handler.service.GetUserTweetStats(ctx.Request.Context(), userID, days, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user tweet stats"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetDeadLettersResponse represents the response for GetDeadLetters
+type GetDeadLettersResponse struct {
+	Count       int64                   `json:"count"`
+	DeadLetters []*analytics.DeadLetter `json:"dead_letters"`
+}
+
+// GetDeadLetters handles GET /v1/analytics/admin/dead-letters
+func (handler *AnalyticsHandler) GetDeadLetters(ctx *gin.Context) {
+	// Only admins can manage dead letters
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	// Parse query parameters
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errOffset != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// Call service
+	count, err := handler.service.CountDeadLetters(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dead letters"})
+		return
+	}
+	deadLetters, err := handler.service.GetDeadLetters(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dead letters"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetDeadLettersResponse{
+		Count:       count,
+		DeadLetters: deadLetters,
+	})
+}
+
+// GetDeadLetter handles GET /v1/analytics/admin/dead-letters/:id
+func (handler *AnalyticsHandler) GetDeadLetter(ctx *gin.Context) {
+	// Only admins can manage dead letters
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	// Call service
+	deadLetter, err := handler.service.GetDeadLetter(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, analytics.ErrDeadLetterNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dead letter"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deadLetter)
+}
+
+// ReplayDeadLetter handles POST /v1/analytics/admin/dead-letters/:id/replay
+func (handler *AnalyticsHandler) ReplayDeadLetter(ctx *gin.Context) {
+	// Only admins can manage dead letters
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	// Call service
+	err := handler.service.ReplayDeadLetter(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, analytics.ErrDeadLetterNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, analytics.ErrInvalidEvent) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, analytics.ErrReplayInProgress) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to replay dead letter"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetUserFlagsResponse represents the response for GetUserFlags
+type GetUserFlagsResponse struct {
+	Count int64                 `json:"count"`
+	Flags []*analytics.UserFlag `json:"flags"`
+}
+
+// GetUserFlags handles GET /v1/analytics/admin/flags
+func (handler *AnalyticsHandler) GetUserFlags(ctx *gin.Context) {
+	// Only admins can review user flags
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	// Parse query parameters
+	filter := analytics.UserFlagFilter{
+		Handler: ctx.Query("handler"),
+		Rule:    ctx.Query("rule"),
+		Status:  ctx.Query("status"),
+	}
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errOffset != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	// Call service
+	count, err := handler.service.CountUserFlags(ctx.Request.Context(), filter)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidUserFlagStatus) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user flags"})
+		return
+	}
+	flags, err := handler.service.GetUserFlags(ctx.Request.Context(), filter, limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user flags"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetUserFlagsResponse{
+		Count: count,
+		Flags: flags,
+	})
+}
+
+// GetUserFlag handles GET /v1/analytics/admin/flags/:id
+func (handler *AnalyticsHandler) GetUserFlag(ctx *gin.Context) {
+	// Only admins can review user flags
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	// Call service
+	flag, err := handler.service.GetUserFlag(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, analytics.ErrUserFlagNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user flag"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// ResolveUserFlagRequest represents the request for ResolveUserFlag
+type ResolveUserFlagRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ResolveUserFlag handles PATCH /v1/analytics/admin/flags/:id
+func (handler *AnalyticsHandler) ResolveUserFlag(ctx *gin.Context) {
+	// Only admins can review user flags
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	id := ctx.Param("id")
+
+	var request ResolveUserFlagRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Call service
+	flag, err := handler.service.ResolveUserFlag(ctx.Request.Context(), id, request.Status)
+	if err != nil {
+		if errors.Is(err, analytics.ErrInvalidUserFlagStatus) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, analytics.ErrUserFlagNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, analytics.ErrUserFlagResolved) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve user flag"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
@@ -1,13 +1,20 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/cmd/analytics/middleware"
 
 	"github.com/lucas-soria/microblogging/internal/analytics"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -15,12 +22,31 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// testRoleSecret is the secret the tests sign the roles with, like the gateway
+const testRoleSecret = "test-role-secret"
+
+// setRole sets the role of the caller of a request, signed like the gateway does
+func setRole(header http.Header, role string) {
+	header.Set("X-User-Role", role)
+	header.Set(auth.RoleSignatureHeader, auth.SignRole(testRoleSecret, header.Get("X-User-Id"), role))
+}
+
+// expectKnownUsers makes the users known by their handlers, each with the ID "<handler>-id"
+func expectKnownUsers(repoMock *analytics.MockRepository) {
+	repoMock.EXPECT().GetKnownUserID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, handler string) (string, error) {
+			return handler + "-id", nil
+		}).
+		AnyTimes()
+}
+
 func TestGetUserAnalytics(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repoMock := analytics.NewMockRepository(ctrl)
-	service := analytics.NewService(repoMock)
+	service := analytics.NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
 	handler := NewAnalyticsHandler(service)
 
 	gin.SetMode(gin.TestMode)
@@ -28,6 +54,8 @@ func TestGetUserAnalytics(t *testing.T) {
 	router.GET("/v1/analytics/users/:id", handler.GetUserAnalytics)
 
 	userID := uuid.New().String()
+	createdAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	lastActivity := createdAt.Add(time.Hour)
 
 	type want struct {
 		statusCode int
@@ -45,16 +73,24 @@ func TestGetUserAnalytics(t *testing.T) {
 			userID: userID,
 			expectations: func() {
 				repoMock.EXPECT().
-					GetUserAnalytics(gomock.Any(), userID).
+					GetUserAnalytics(gomock.Any(), userID+"-id").
 					Return(&analytics.UserAnalytics{
-						Handler:      userID,
-						IsInfluencer: true,
-						IsActive:     true,
+						Handler:            userID,
+						IsInfluencer:       true,
+						IsActive:           true,
+						TweetCount:         42,
+						FollowerCount:      7,
+						EngagementScore:    12.5,
+						LastActivityAt:     &lastActivity,
+						LastTimelineViewAt: nil,
+						CreatedAt:          createdAt,
+						UpdatedAt:          lastActivity,
 					}, nil)
 			},
 			want: want{
 				statusCode: http.StatusOK,
-				response:   []byte(`{"handler":"` + userID + `","is_influencer":true,"is_active":true}`),
+				response: []byte(`{"handler":"` + userID + `","is_influencer":true,"is_active":true,"tweet_count":42,"follower_count":7,"engagement_score":12.5,` +
+					`"last_activity_at":"2025-08-09T06:13:41Z","last_timeline_view_at":null,"created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T06:13:41Z"}`),
 			},
 		},
 		{
@@ -62,7 +98,7 @@ func TestGetUserAnalytics(t *testing.T) {
 			userID: userID,
 			expectations: func() {
 				repoMock.EXPECT().
-					GetUserAnalytics(gomock.Any(), userID).
+					GetUserAnalytics(gomock.Any(), userID+"-id").
 					Return(nil, errors.New("user analytics not found"))
 			},
 			want: want{
@@ -92,7 +128,7 @@ func TestGetAllUserAnalytics(t *testing.T) {
 	defer ctrl.Finish()
 
 	repoMock := analytics.NewMockRepository(ctrl)
-	service := analytics.NewService(repoMock)
+	service := analytics.NewService(repoMock, nil, nil)
 	handler := NewAnalyticsHandler(service)
 
 	gin.SetMode(gin.TestMode)
@@ -101,14 +137,18 @@ func TestGetAllUserAnalytics(t *testing.T) {
 
 	userID1 := uuid.New().String()
 	userID2 := uuid.New().String()
+	active := true
+	updatedSince := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
 
 	type want struct {
 		statusCode int
 		response   []byte
+		nextCursor bool
 	}
 
 	tt := []struct {
 		name         string
+		query        string
 		expectations func()
 		want         want
 	}{
@@ -116,7 +156,7 @@ func TestGetAllUserAnalytics(t *testing.T) {
 			name: "success",
 			expectations: func() {
 				repoMock.EXPECT().
-					GetAllUserAnalytics(gomock.Any()).
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
 					Return([]*analytics.UserAnalytics{
 						{
 							Handler:      userID1,
@@ -132,14 +172,84 @@ func TestGetAllUserAnalytics(t *testing.T) {
 			},
 			want: want{
 				statusCode: http.StatusOK,
-				response:   []byte(`[{"handler":"` + userID1 + `","is_influencer":true,"is_active":true},{"handler":"` + userID2 + `","is_influencer":false,"is_active":true}]`),
+				response: []byte(`[{"handler":"` + userID1 + `","is_influencer":true,"is_active":true,"tweet_count":0,"follower_count":0,"engagement_score":0,"last_activity_at":null,"last_timeline_view_at":null,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"},` +
+					`{"handler":"` + userID2 + `","is_influencer":false,"is_active":true,"tweet_count":0,"follower_count":0,"engagement_score":0,"last_activity_at":null,"last_timeline_view_at":null,"created_at":"0001-01-01T00:00:00Z","updated_at":"0001-01-01T00:00:00Z"}]`),
+			},
+		},
+		{
+			name:  "filtered, sorted and paginated",
+			query: "?is_active=true&updated_since=2025-08-01T00:00:00Z&sort=-updated_at&limit=1",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), analytics.UserAnalyticsFilter{
+						IsActive:     &active,
+						UpdatedSince: updatedSince,
+						SortBy:       analytics.SortByUpdatedAt,
+						Descending:   true,
+						Limit:        2,
+					}).
+					Return([]*analytics.UserAnalytics{
+						{Handler: userID1, IsActive: true, UpdatedAt: updatedSince.Add(time.Hour)},
+						{Handler: userID2, IsActive: true, UpdatedAt: updatedSince},
+					}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`[{"handler":"` + userID1 + `","is_influencer":false,"is_active":true,"tweet_count":0,"follower_count":0,"engagement_score":0,` +
+					`"last_activity_at":null,"last_timeline_view_at":null,"created_at":"0001-01-01T00:00:00Z","updated_at":"2025-08-01T01:00:00Z"}]`),
+				nextCursor: true,
+			},
+		},
+		{
+			name:         "invalid is_active",
+			query:        "?is_active=sometimes",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid is_active parameter"}`),
+			},
+		},
+		{
+			name:         "invalid updated_until",
+			query:        "?updated_until=yesterday",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid updated_until parameter"}`),
+			},
+		},
+		{
+			name:         "invalid limit",
+			query:        "?limit=ten",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name:         "unknown sort field",
+			query:        "?sort=followers",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid user analytics query: unknown sort field \"followers\""}`),
+			},
+		},
+		{
+			name:         "malformed cursor",
+			query:        "?cursor=abc$",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid user analytics query: malformed cursor"}`),
 			},
 		},
 		{
 			name: "internal server error",
 			expectations: func() {
 				repoMock.EXPECT().
-					GetAllUserAnalytics(gomock.Any()).
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
 					Return(nil, errors.New("database error"))
 			},
 			want: want{
@@ -152,7 +262,7 @@ func TestGetAllUserAnalytics(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.expectations()
 
-			req, err := http.NewRequest(http.MethodGet, "/v1/analytics/users", nil)
+			req, err := http.NewRequest(http.MethodGet, "/v1/analytics/users"+tc.query, nil)
 			require.NoError(t, err)
 
 			rr := httptest.NewRecorder()
@@ -160,6 +270,124 @@ func TestGetAllUserAnalytics(t *testing.T) {
 
 			assert.Equal(t, tc.want.statusCode, rr.Code)
 			assert.Equal(t, string(tc.want.response), rr.Body.String())
+			assert.Equal(t, tc.want.nextCursor, rr.Header().Get("X-Next-Cursor") != "")
+		})
+	}
+}
+
+func TestExportUserAnalytics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/analytics/users/export", handler.ExportUserAnalytics)
+
+	createdAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	users := []*analytics.UserAnalytics{
+		{Handler: "user1", IsInfluencer: true, IsActive: true, TweetCount: 3, FollowerCount: 2, EngagementScore: 4, LastActivityAt: &createdAt, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{Handler: "user2", IsActive: false, CreatedAt: createdAt, UpdatedAt: createdAt.Add(time.Hour)},
+	}
+
+	type want struct {
+		statusCode  int
+		contentType string
+		response    string
+	}
+
+	tt := []struct {
+		name         string
+		query        string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "csv",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
+					Return(users, nil)
+			},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "text/csv",
+				response: "handler,is_influencer,is_active,tweet_count,follower_count,engagement_score,last_activity_at,last_timeline_view_at,created_at,updated_at\n" +
+					"user1,true,true,3,2,4,2025-08-09T05:13:41Z,,2025-08-09T05:13:41Z,2025-08-09T05:13:41Z\n" +
+					"user2,false,false,0,0,0,,,2025-08-09T05:13:41Z,2025-08-09T06:13:41Z\n",
+			},
+		},
+		{
+			name:  "ndjson",
+			query: "?format=ndjson",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
+					Return(users, nil)
+			},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "application/x-ndjson",
+				response: `{"handler":"user1","is_influencer":true,"is_active":true,"tweet_count":3,"follower_count":2,"engagement_score":4,` +
+					`"last_activity_at":"2025-08-09T05:13:41Z","last_timeline_view_at":null,"created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T05:13:41Z"}` + "\n" +
+					`{"handler":"user2","is_influencer":false,"is_active":false,"tweet_count":0,"follower_count":0,"engagement_score":0,` +
+					`"last_activity_at":null,"last_timeline_view_at":null,"created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T06:13:41Z"}` + "\n",
+			},
+		},
+		{
+			name:  "empty csv",
+			query: "?is_influencer=true",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
+					Return([]*analytics.UserAnalytics{}, nil)
+			},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "text/csv",
+				response:    "handler,is_influencer,is_active,tweet_count,follower_count,engagement_score,last_activity_at,last_timeline_view_at,created_at,updated_at\n",
+			},
+		},
+		{
+			name:         "invalid format",
+			query:        "?format=xml",
+			expectations: func() {},
+			want: want{
+				statusCode:  http.StatusBadRequest,
+				contentType: "application/json; charset=utf-8",
+				response:    `{"error":"Invalid format parameter"}`,
+			},
+		},
+		{
+			name: "internal server error",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode:  http.StatusInternalServerError,
+				contentType: "application/json; charset=utf-8",
+				response:    `{"error":"failed to export user analytics"}`,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, "/v1/analytics/users/export"+tc.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, tc.want.contentType, rr.Header().Get("Content-Type"))
+			assert.Equal(t, tc.want.response, rr.Body.String())
 		})
 	}
 }
@@ -169,11 +397,13 @@ func TestDeleteUserAnalytics(t *testing.T) {
 	defer ctrl.Finish()
 
 	repoMock := analytics.NewMockRepository(ctrl)
-	service := analytics.NewService(repoMock)
+	service := analytics.NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
 	handler := NewAnalyticsHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.DELETE("/v1/analytics/users/:id", handler.DeleteUserAnalytics)
 
 	userID := uuid.New().String()
@@ -194,7 +424,7 @@ func TestDeleteUserAnalytics(t *testing.T) {
 			userID: userID,
 			expectations: func() {
 				repoMock.EXPECT().
-					DeleteUserAnalytics(gomock.Any(), userID).
+					DeleteUserAnalytics(gomock.Any(), userID+"-id").
 					Return(nil)
 			},
 			want: want{
@@ -207,7 +437,7 @@ func TestDeleteUserAnalytics(t *testing.T) {
 			userID: userID,
 			expectations: func() {
 				repoMock.EXPECT().
-					DeleteUserAnalytics(gomock.Any(), userID).
+					DeleteUserAnalytics(gomock.Any(), userID+"-id").
 					Return(errors.New("user not found"))
 			},
 			want: want{
@@ -222,6 +452,1199 @@ func TestDeleteUserAnalytics(t *testing.T) {
 
 			req, err := http.NewRequest(http.MethodDelete, "/v1/analytics/users/"+tc.userID, nil)
 			require.NoError(t, err)
+			req.Header.Set("X-User-Id", tc.userID)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestDeleteUserAnalyticsAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := analytics.NewMockService(ctrl)
+	handler := NewAnalyticsHandler(serviceMock)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.DELETE("/v1/analytics/users/:id", handler.DeleteUserAnalytics)
+
+	userID := uuid.New().String()
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		caller       string
+		role         string
+		expectations func()
+		want         want
+	}{
+		{
+			name:   "owner",
+			caller: userID,
+			expectations: func() {
+				serviceMock.EXPECT().
+					DeleteUserAnalytics(gomock.Any(), userID).
+					Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   []byte(``),
+			},
+		},
+		{
+			name:   "admin",
+			caller: "admin",
+			role:   "admin",
+			expectations: func() {
+				serviceMock.EXPECT().
+					DeleteUserAnalytics(gomock.Any(), userID).
+					Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   []byte(``),
+			},
+		},
+		{
+			name:         "another user",
+			caller:       "otheruser",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Not allowed to delete the analytics of this user"}`),
+			},
+		},
+		{
+			name:         "another role",
+			caller:       "otheruser",
+			role:         "moderator",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Not allowed to delete the analytics of this user"}`),
+			},
+		},
+		{
+			name:         "anonymous",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodDelete, "/v1/analytics/users/"+userID, nil)
+			require.NoError(t, err)
+			if tc.caller != "" {
+				req.Header.Set("X-User-Id", tc.caller)
+			}
+			if tc.role != "" {
+				setRole(req.Header, tc.role)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetTrends(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/analytics/trends", handler.GetTrends)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		queryParams  string
+		expectations func()
+		want         want
+	}{
+		{
+			name:        "success",
+			queryParams: "?window=1h&limit=5",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetHashtagCounts(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return([]*analytics.HashtagCount{{Tag: "go", WindowCount: 0, BaselineCount: 0}}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[{"tag":"go","count":0,"velocity":0,"baseline_velocity":0,"spike_ratio":1,"is_spike":false}]`),
+			},
+		},
+		{
+			name:         "invalid window",
+			queryParams:  "?window=abc",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid window parameter"}`),
+			},
+		},
+		{
+			name:         "window out of range",
+			queryParams:  "?window=48h",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"window must be between 5m0s and 12h0m0s"}`),
+			},
+		},
+		{
+			name:         "invalid limit",
+			queryParams:  "?limit=abc",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name:        "repository error",
+			queryParams: "",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetHashtagCounts(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get trends"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, "/v1/analytics/trends"+tc.queryParams, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/v1/analytics/metrics", handler.GetMetrics)
+
+	from := time.Date(2025, 8, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		queryParams  string
+		expectations func()
+		want         want
+	}{
+		{
+			name:        "success",
+			queryParams: "?metric=dau&from=2025-08-09&to=2025-08-10&granularity=day",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), analytics.MetricDAU, from, to).
+					Return([]*analytics.DailyMetric{{Day: from, Metric: analytics.MetricDAU, Value: 3}}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"metric":"dau","granularity":"day","points":[{"date":"2025-08-09T00:00:00Z","value":3},{"date":"2025-08-10T00:00:00Z","value":0}]}`),
+			},
+		},
+		{
+			name:         "invalid from",
+			queryParams:  "?metric=dau&from=09/08/2025",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid from parameter"}`),
+			},
+		},
+		{
+			name:         "invalid to",
+			queryParams:  "?metric=dau&to=yesterday",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid to parameter"}`),
+			},
+		},
+		{
+			name:         "unknown metric",
+			queryParams:  "?metric=clicks",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid metrics query: unknown metric \"clicks\""}`),
+			},
+		},
+		{
+			name:        "repository error",
+			queryParams: "?metric=wau&from=2025-08-09&to=2025-08-10",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), analytics.MetricWAU, from, to).
+					Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get metrics"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, "/v1/analytics/metrics"+tc.queryParams, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetTweetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/tweets/:id", handler.GetTweetStats)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	engagement := &analytics.TweetEngagement{TweetID: "tweet1", Handler: "author", CreatedAt: today}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		caller       string
+		role         string
+		expectations func()
+		want         want
+	}{
+		{
+			name:   "success",
+			path:   "/v1/analytics/tweets/tweet1?days=7",
+			caller: "author",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(engagement, nil)
+				repoMock.EXPECT().
+					GetTweetDailyStats(gomock.Any(), []string{"tweet1"}, today).
+					Return([]*analytics.TweetDailyStats{{TweetID: "tweet1", Day: today, Impressions: 4, Likes: 1}}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"tweet_id":"tweet1","handler":"author","created_at":"` + today.Format(time.RFC3339) + `",` +
+					`"impressions":4,"unique_viewers":0,"profile_views":0,"likes":1,"replies":0,` +
+					`"daily":[{"date":"` + today.Format(time.RFC3339) + `","impressions":4,"unique_viewers":0,"profile_views":0,"likes":1,"replies":0}]}`),
+			},
+		},
+		{
+			name:         "invalid days",
+			path:         "/v1/analytics/tweets/tweet1?days=abc",
+			caller:       "author",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid days parameter"}`),
+			},
+		},
+		{
+			name:   "not found",
+			path:   "/v1/analytics/tweets/missing",
+			caller: "author",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "missing").Return(nil, analytics.ErrTweetNotFound)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"tweet not found"}`),
+			},
+		},
+		{
+			name:   "repository error",
+			path:   "/v1/analytics/tweets/tweet1",
+			caller: "author",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get tweet stats"}`),
+			},
+		},
+		{
+			name:   "other user",
+			path:   "/v1/analytics/tweets/tweet1?days=1",
+			caller: "other",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(engagement, nil)
+				repoMock.EXPECT().GetTweetDailyStats(gomock.Any(), []string{"tweet1"}, today).Return(nil, nil)
+			},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Not allowed to get the stats of this tweet"}`),
+			},
+		},
+		{
+			name:   "admin",
+			path:   "/v1/analytics/tweets/tweet1?days=1",
+			caller: "admin",
+			role:   "admin",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(engagement, nil)
+				repoMock.EXPECT().GetTweetDailyStats(gomock.Any(), []string{"tweet1"}, today).Return(nil, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"tweet_id":"tweet1","handler":"author","created_at":"` + today.Format(time.RFC3339) + `",` +
+					`"impressions":0,"unique_viewers":0,"profile_views":0,"likes":0,"replies":0,` +
+					`"daily":[{"date":"` + today.Format(time.RFC3339) + `","impressions":0,"unique_viewers":0,"profile_views":0,"likes":0,"replies":0}]}`),
+			},
+		},
+		{
+			name:         "anonymous",
+			path:         "/v1/analytics/tweets/tweet1?days=1",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			if tc.caller != "" {
+				req.Header.Set("X-User-Id", tc.caller)
+			}
+			if tc.role != "" {
+				setRole(req.Header, tc.role)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetUserTweetStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/users/:id/tweets", handler.GetUserTweetStats)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		caller       string
+		role         string
+		expectations func()
+		want         want
+	}{
+		{
+			name:   "user without tweets",
+			path:   "/v1/analytics/users/author/tweets?limit=10&offset=10",
+			caller: "author",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 10, 10).Return([]*analytics.TweetEngagement{}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[]`),
+			},
+		},
+		{
+			name:         "invalid days",
+			path:         "/v1/analytics/users/author/tweets?days=abc",
+			caller:       "author",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid days parameter"}`),
+			},
+		},
+		{
+			name:         "invalid limit",
+			path:         "/v1/analytics/users/author/tweets?limit=abc",
+			caller:       "author",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name:         "invalid offset",
+			path:         "/v1/analytics/users/author/tweets?offset=abc",
+			caller:       "author",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid offset parameter"}`),
+			},
+		},
+		{
+			name:   "repository error",
+			path:   "/v1/analytics/users/author/tweets",
+			caller: "author",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 20, 0).Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get user tweet stats"}`),
+			},
+		},
+		{
+			name:   "other user",
+			path:   "/v1/analytics/users/author/tweets",
+			caller: "other",
+			expectations: func() {
+			},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Not allowed to get the tweet stats of this user"}`),
+			},
+		},
+		{
+			name:   "admin",
+			path:   "/v1/analytics/users/author/tweets",
+			caller: "admin",
+			role:   "admin",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 20, 0).Return([]*analytics.TweetEngagement{}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[]`),
+			},
+		},
+		{
+			name:         "anonymous",
+			path:         "/v1/analytics/users/author/tweets",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			if tc.caller != "" {
+				req.Header.Set("X-User-Id", tc.caller)
+			}
+			if tc.role != "" {
+				setRole(req.Header, tc.role)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/admin/dead-letters", handler.GetDeadLetters)
+
+	createdAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "success",
+			path: "/v1/analytics/admin/dead-letters?limit=1",
+			expectations: func() {
+				repoMock.EXPECT().CountDeadLetters(gomock.Any()).Return(int64(2), nil)
+				repoMock.EXPECT().
+					GetDeadLetters(gomock.Any(), 1, 0).
+					Return([]*analytics.DeadLetter{{
+						ID:        "dead-letter-1",
+						Topic:     "TweetPosted",
+						Key:       "user1",
+						Payload:   "not json",
+						Reason:    "failed to decode TweetPosted message",
+						Attempts:  1,
+						CreatedAt: createdAt,
+						UpdatedAt: createdAt,
+					}}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"count":2,"dead_letters":[{"id":"dead-letter-1","topic":"TweetPosted","key":"user1","payload":"not json",` +
+					`"reason":"failed to decode TweetPosted message","attempts":1,"created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T05:13:41Z"}]}`),
+			},
+		},
+		{
+			name:         "invalid limit",
+			path:         "/v1/analytics/admin/dead-letters?limit=abc",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name: "repository error",
+			path: "/v1/analytics/admin/dead-letters",
+			expectations: func() {
+				repoMock.EXPECT().CountDeadLetters(gomock.Any()).Return(int64(0), errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get dead letters"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-User-Id", uuid.New().String())
+			setRole(req.Header, "admin")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/analytics/admin/dead-letters/:id/replay", handler.ReplayDeadLetter)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "success",
+			path: "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&analytics.DeadLetter{ID: "dead-letter-1", Payload: `{"id":"event-1","event_type":"tweet_created","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`}, nil)
+				repoMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(nil)
+				repoMock.EXPECT().DeleteDeadLetter(gomock.Any(), "dead-letter-1").Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "still invalid",
+			path: "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&analytics.DeadLetter{ID: "dead-letter-1", Payload: `{"id":"event-1","event_type":"tweet_created"}`}, nil)
+				repoMock.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"invalid event: user ID is required in event"}`),
+			},
+		},
+		{
+			name: "not found",
+			path: "/v1/analytics/admin/dead-letters/missing/replay",
+			expectations: func() {
+				repoMock.EXPECT().GetDeadLetter(gomock.Any(), "missing").Return(nil, analytics.ErrDeadLetterNotFound)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"dead letter not found"}`),
+			},
+		},
+		{
+			name: "events replay in progress",
+			path: "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&analytics.DeadLetter{ID: "dead-letter-1", Payload: `{"id":"event-1","event_type":"tweet_created","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`}, nil)
+				repoMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(analytics.ErrReplayInProgress)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"events replay in progress"}`),
+			},
+		},
+		{
+			name: "failed again",
+			path: "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&analytics.DeadLetter{ID: "dead-letter-1", Payload: `{"id":"event-1","event_type":"tweet_created","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`}, nil)
+				repoMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+				repoMock.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to replay dead letter"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodPost, tc.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-User-Id", uuid.New().String())
+			setRole(req.Header, "admin")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestDeadLettersAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := analytics.NewMockService(ctrl)
+	handler := NewAnalyticsHandler(serviceMock)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/admin/dead-letters", handler.GetDeadLetters)
+	router.GET("/v1/analytics/admin/dead-letters/:id", handler.GetDeadLetter)
+	router.POST("/v1/analytics/admin/dead-letters/:id/replay", handler.ReplayDeadLetter)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name   string
+		method string
+		path   string
+		caller string
+		role   string
+		want   want
+	}{
+		{
+			name:   "list as user",
+			method: http.MethodGet,
+			path:   "/v1/analytics/admin/dead-letters",
+			caller: uuid.New().String(),
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "get as user",
+			method: http.MethodGet,
+			path:   "/v1/analytics/admin/dead-letters/dead-letter-1",
+			caller: uuid.New().String(),
+			role:   "user",
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "replay as user",
+			method: http.MethodPost,
+			path:   "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			caller: uuid.New().String(),
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "anonymous",
+			method: http.MethodPost,
+			path:   "/v1/analytics/admin/dead-letters/dead-letter-1/replay",
+			role:   "admin",
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.path, nil)
+			require.NoError(t, err)
+			if tc.caller != "" {
+				req.Header.Set("X-User-Id", tc.caller)
+			}
+			if tc.role != "" {
+				setRole(req.Header, tc.role)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetUserFlags(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/admin/flags", handler.GetUserFlags)
+
+	createdAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "success",
+			path: "/v1/analytics/admin/flags?handler=user1&status=open&limit=1",
+			expectations: func() {
+				filter := analytics.UserFlagFilter{Handler: "user1", UserID: "user1-id", Status: analytics.UserFlagStatusOpen}
+				repoMock.EXPECT().CountUserFlags(gomock.Any(), filter).Return(int64(2), nil)
+				repoMock.EXPECT().
+					GetUserFlags(gomock.Any(), filter, 1, 0).
+					Return([]*analytics.UserFlag{{
+						ID:          "flag-1",
+						Handler:     "user1",
+						Rule:        analytics.RuleDuplicateTweets,
+						Reason:      "posted 3 identical tweets within 10m0s",
+						Occurrences: 1,
+						Status:      analytics.UserFlagStatusOpen,
+						CreatedAt:   createdAt,
+						UpdatedAt:   createdAt,
+					}}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"count":2,"flags":[{"id":"flag-1","handler":"user1","rule":"duplicate_tweets","reason":"posted 3 identical tweets within 10m0s",` +
+					`"occurrences":1,"status":"open","created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T05:13:41Z"}]}`),
+			},
+		},
+		{
+			name:         "invalid status",
+			path:         "/v1/analytics/admin/flags?status=closed",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid user flag status: \"closed\""}`),
+			},
+		},
+		{
+			name:         "invalid offset",
+			path:         "/v1/analytics/admin/flags?offset=abc",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid offset parameter"}`),
+			},
+		},
+		{
+			name: "repository error",
+			path: "/v1/analytics/admin/flags",
+			expectations: func() {
+				repoMock.EXPECT().CountUserFlags(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"failed to get user flags"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			req.Header.Set("X-User-Id", uuid.New().String())
+			setRole(req.Header, "admin")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestGetUserFlag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/admin/flags/:id", handler.GetUserFlag)
+
+	repoMock.EXPECT().GetUserFlag(gomock.Any(), "missing").Return(nil, analytics.ErrUserFlagNotFound)
+
+	req, err := http.NewRequest(http.MethodGet, "/v1/analytics/admin/flags/missing", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-User-Id", uuid.New().String())
+	setRole(req.Header, "admin")
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, `{"error":"user flag not found"}`, rr.Body.String())
+}
+
+func TestResolveUserFlag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := analytics.NewMockRepository(ctrl)
+	service := analytics.NewService(repoMock, nil, nil)
+	handler := NewAnalyticsHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.PATCH("/v1/analytics/admin/flags/:id", handler.ResolveUserFlag)
+
+	createdAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		body         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "success",
+			body: `{"status":"confirmed"}`,
+			expectations: func() {
+				repoMock.EXPECT().
+					ResolveUserFlag(gomock.Any(), "flag-1", analytics.UserFlagStatusConfirmed).
+					Return(&analytics.UserFlag{
+						ID:          "flag-1",
+						Handler:     "user1",
+						Rule:        analytics.RuleFollowChurn,
+						Reason:      "followed and unfollowed 20 users within 1h0m0s",
+						Occurrences: 3,
+						Status:      analytics.UserFlagStatusConfirmed,
+						CreatedAt:   createdAt,
+						UpdatedAt:   createdAt.Add(time.Hour),
+					}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"id":"flag-1","handler":"user1","rule":"follow_churn","reason":"followed and unfollowed 20 users within 1h0m0s",` +
+					`"occurrences":3,"status":"confirmed","created_at":"2025-08-09T05:13:41Z","updated_at":"2025-08-09T06:13:41Z"}`),
+			},
+		},
+		{
+			name:         "missing status",
+			body:         `{}`,
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid request body"}`),
+			},
+		},
+		{
+			name:         "invalid status",
+			body:         `{"status":"open"}`,
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid user flag status: \"open\", it must be confirmed or dismissed"}`),
+			},
+		},
+		{
+			name: "not found",
+			body: `{"status":"dismissed"}`,
+			expectations: func() {
+				repoMock.EXPECT().
+					ResolveUserFlag(gomock.Any(), "flag-1", analytics.UserFlagStatusDismissed).
+					Return(nil, analytics.ErrUserFlagNotFound)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user flag not found"}`),
+			},
+		},
+		{
+			name: "already resolved",
+			body: `{"status":"dismissed"}`,
+			expectations: func() {
+				repoMock.EXPECT().
+					ResolveUserFlag(gomock.Any(), "flag-1", analytics.UserFlagStatusDismissed).
+					Return(nil, analytics.ErrUserFlagResolved)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"user flag is already resolved"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			req, err := http.NewRequest(http.MethodPatch, "/v1/analytics/admin/flags/flag-1", strings.NewReader(tc.body))
+			require.NoError(t, err)
+			req.Header.Set("X-User-Id", uuid.New().String())
+			setRole(req.Header, "admin")
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tc.want.statusCode, rr.Code)
+			assert.Equal(t, string(tc.want.response), rr.Body.String())
+		})
+	}
+}
+
+func TestUserFlagsAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := analytics.NewMockService(ctrl)
+	handler := NewAnalyticsHandler(serviceMock)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/analytics/admin/flags", handler.GetUserFlags)
+	router.GET("/v1/analytics/admin/flags/:id", handler.GetUserFlag)
+	router.PATCH("/v1/analytics/admin/flags/:id", handler.ResolveUserFlag)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		caller string
+		role   string
+		want   want
+	}{
+		{
+			name:   "list as user",
+			method: http.MethodGet,
+			path:   "/v1/analytics/admin/flags",
+			caller: uuid.New().String(),
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "get as user",
+			method: http.MethodGet,
+			path:   "/v1/analytics/admin/flags/flag-1",
+			caller: uuid.New().String(),
+			role:   "moderator",
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "resolve as user",
+			method: http.MethodPatch,
+			path:   "/v1/analytics/admin/flags/flag-1",
+			body:   `{"status":"dismissed"}`,
+			caller: uuid.New().String(),
+			role:   "user",
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:   "anonymous",
+			method: http.MethodPatch,
+			path:   "/v1/analytics/admin/flags/flag-1",
+			body:   `{"status":"dismissed"}`,
+			role:   "admin",
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.caller != "" {
+				req.Header.Set("X-User-Id", tc.caller)
+			}
+			if tc.role != "" {
+				setRole(req.Header, tc.role)
+			}
 
 			rr := httptest.NewRecorder()
 			router.ServeHTTP(rr, req)
@@ -1,15 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/lucas-soria/microblogging/cmd/analytics/handlers"
 
 	"github.com/lucas-soria/microblogging/internal/analytics"
 
+	"github.com/lucas-soria/microblogging/pkg/cache"
 	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/queue"
 )
 
 // getEnv gets an environment variable or returns a default value
@@ -20,6 +25,32 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getIntEnv gets an integer environment variable or returns a default value
+func getIntEnv(key string, defaultValue int) int {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid integer for %s: %v", key, err)
+	}
+	return number
+}
+
+// getDurationEnv gets a duration environment variable or returns a default value
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return duration
+}
+
 func main() {
 	// Get database configuration from environment variables
 	dbHost := getEnv("DB_HOST", "postgres-primary")
@@ -41,9 +72,124 @@ func main() {
 	log.Println("Initializing feed repository")
 	analyticsRepo := analytics.NewPostgresAnalyticsRepository(db)
 
-	// Initialize service with repository
-	log.Println("Initializing feed service")
-	analyticsService := analytics.NewService(analyticsRepo)
+	// Initialize message queue, shared with the other services through Kafka
+	log.Println("Initializing analytics message queue")
+	messageQueue, err := queue.NewKafkaQueue(queue.KafkaConfig{
+		Brokers:         getEnv("KAFKA_BROKERS", "kafka:9092"),
+		GroupID:         getEnv("KAFKA_GROUP_ID", "analytics-service"),
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize analytics message queue: %v", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize cache, shared with the feed service through Redis
+	log.Println("Initializing analytics cache")
+	popularCache := cache.NewRedisCache(getEnv("REDIS_ADDR", "redis:6379"))
+	defer popularCache.Close()
+
+	// Initialize spam detector
+	log.Println("Initializing spam detector")
+	spamDetector := analytics.NewSpamDetector(
+		analyticsRepo,
+		analytics.DuplicateTweetsRule{
+			Threshold: getIntEnv("SPAM_DUPLICATE_TWEETS_THRESHOLD", 3),
+			Period:    getDurationEnv("SPAM_DUPLICATE_TWEETS_PERIOD", 10*time.Minute),
+		},
+		analytics.FollowChurnRule{
+			Threshold: getIntEnv("SPAM_FOLLOW_CHURN_THRESHOLD", 20),
+			Period:    getDurationEnv("SPAM_FOLLOW_CHURN_PERIOD", time.Hour),
+		},
+		analytics.ExcessiveMentionsRule{
+			MaxPerTweet:  getIntEnv("SPAM_MAX_MENTIONS_PER_TWEET", 10),
+			MaxPerPeriod: getIntEnv("SPAM_MAX_MENTIONS_PER_PERIOD", 50),
+			Period:       getDurationEnv("SPAM_MENTIONS_PERIOD", time.Hour),
+		},
+	)
+
+	// Initialize the users client, which identifies the users not known yet
+	log.Println("Initializing users client")
+	usersClient := analytics.NewHTTPUsersClient(getEnv("USERS_SERVICE_URL", "http://users-service"), 5*time.Second)
+
+	// Initialize service with repository, replayed dead letters are checked for spam too
+	log.Println("Initializing analytics service")
+	analyticsService := analytics.NewService(analyticsRepo, spamDetector, usersClient)
+	analytics.PublishDeadLettersGauge(analyticsService)
+	analytics.PublishReplayCheckpointAgeGauge(analyticsRepo)
+
+	// Subscribe to events
+	log.Println("Subscribing analytics consumer")
+	analyticsConsumer := analytics.NewConsumer(
+		analyticsService,
+		spamDetector,
+		getIntEnv("EVENT_MAX_ATTEMPTS", 3),
+		getDurationEnv("EVENT_RETRY_BACKOFF", 100*time.Millisecond),
+	)
+	analyticsConsumer.Subscribe(messageQueue)
+
+	// Subscribe to the deletion of users, deleting their analytics
+	log.Println("Subscribing user deletion consumer")
+	userDeletionConsumer := analytics.NewUserDeletionConsumer(analyticsService, messageQueue)
+	userDeletionConsumer.Subscribe(messageQueue)
+
+	// Subscribe to the handle changes of users, moving their analytics to the new handle
+	log.Println("Subscribing user handle change consumer")
+	userHandleChangeConsumer := analytics.NewUserHandleChangeConsumer(analyticsService)
+	userHandleChangeConsumer.Subscribe(messageQueue)
+
+	// Start background jobs
+	ctx := context.Background()
+
+	log.Println("Starting analytics message queue consumer")
+	go func() {
+		if err := messageQueue.Run(ctx); err != nil {
+			log.Fatalf("Failed to consume analytics messages: %v", err)
+		}
+	}()
+
+	log.Println("Starting inactivity sweeper")
+	inactivitySweeper := analytics.NewInactivitySweeper(
+		analyticsRepo,
+		messageQueue,
+		getDurationEnv("INACTIVITY_WINDOW", 30*24*time.Hour),
+		getDurationEnv("INACTIVITY_SWEEP_INTERVAL", time.Hour),
+	)
+	go inactivitySweeper.Run(ctx)
+
+	log.Println("Starting popular tweets ranker")
+	popularTweetsRanker := analytics.NewPopularTweetsRanker(
+		analyticsRepo,
+		popularCache,
+		getDurationEnv("POPULAR_TWEETS_WINDOW", 24*time.Hour),
+		getIntEnv("POPULAR_TWEETS_SIZE", 100),
+		getDurationEnv("POPULAR_TWEETS_REFRESH_INTERVAL", time.Minute),
+	)
+	go popularTweetsRanker.Run(ctx)
+
+	log.Println("Starting hashtag buckets pruner")
+	hashtagBucketsPruner := analytics.NewHashtagBucketsPruner(
+		analyticsRepo,
+		getDurationEnv("HASHTAG_BUCKETS_PRUNE_INTERVAL", analytics.HashtagBucketSize),
+	)
+	go hashtagBucketsPruner.Run(ctx)
+
+	log.Println("Starting metrics aggregator")
+	metricsAggregator := analytics.NewMetricsAggregator(
+		analyticsRepo,
+		getDurationEnv("METRICS_AGGREGATION_INTERVAL", 5*time.Minute),
+	)
+	go metricsAggregator.Run(ctx)
+
+	log.Println("Starting event partitions manager")
+	eventPartitionsManager := analytics.NewEventPartitionsManager(
+		analyticsRepo,
+		getDurationEnv("EVENTS_RETENTION", 365*24*time.Hour),
+		getEnv("EVENTS_ARCHIVE_DIR", "/var/lib/analytics/archive"),
+		getDurationEnv("EVENT_PARTITIONS_INTERVAL", time.Hour),
+	)
+	go eventPartitionsManager.Run(ctx)
 
 	// Initialize handlers with service
 	log.Println("Initializing feed handlers")
@@ -51,9 +197,9 @@ func main() {
 
 	// Create application
 	log.Println("Creating feed application")
-	application := NewApplication(analyticsHandler)
+	application := NewApplication(analyticsHandler, getEnv("AUTH_ROLE_SECRET", ""))
 
-	server := newServer()
+	server := newServer(getEnv("METRICS_ADDR", ":9090"))
 
 	addRoutes(server, application)
 
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/lucas-soria/microblogging/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthMiddleware requires the X-User-Id header, trusting the X-User-Role header only if signed with the role secret
+func AuthMiddleware(roleSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := auth.Authenticate(c.Request.Header, roleSecret)
+		if caller.ID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "X-User-Id header is required"})
+			c.Abort()
+			return
+		}
+
+		auth.SetCaller(c, caller)
+		c.Next()
+	}
+}
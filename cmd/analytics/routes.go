@@ -1,12 +1,17 @@
 package main
 
 import (
+	"github.com/lucas-soria/microblogging/cmd/analytics/middleware"
+
+	"github.com/lucas-soria/microblogging/internal/analytics"
+
 	"github.com/gin-gonic/gin"
 )
 
 // setupRoutes configures all the routes for the application
 func addRoutes(server *Server, application *Application) {
 	healthCheck(server.router)
+	metrics(server.metricsRouter)
 	groupV1 := newGroup(server.router, "/v1")
 	analyticsRoutes(groupV1, application)
 }
@@ -23,8 +28,27 @@ func healthCheck(router *gin.Engine) {
 	})
 }
 
+// metrics exposes the analytics expvars, like the dead letters gauge and counter, to be scraped
+func metrics(router *gin.Engine) {
+	router.GET("/debug/vars", gin.WrapH(analytics.VarsHandler()))
+}
+
 func analyticsRoutes(group *gin.RouterGroup, application *Application) {
 	group.GET("/analytics/users", application.analyticsHandler.GetAllUserAnalytics)
+	group.GET("/analytics/users/export", application.analyticsHandler.ExportUserAnalytics)
 	group.GET("/analytics/users/:id", application.analyticsHandler.GetUserAnalytics)
-	group.DELETE("/analytics/users/:id", application.analyticsHandler.DeleteUserAnalytics)
+	group.GET("/analytics/trends", application.analyticsHandler.GetTrends)
+	group.GET("/analytics/metrics", application.analyticsHandler.GetMetrics)
+
+	protectedGroup := group.Group("")
+	protectedGroup.Use(middleware.AuthMiddleware(application.roleSecret))
+	protectedGroup.GET("/analytics/users/:id/tweets", application.analyticsHandler.GetUserTweetStats)
+	protectedGroup.GET("/analytics/tweets/:id", application.analyticsHandler.GetTweetStats)
+	protectedGroup.DELETE("/analytics/users/:id", application.analyticsHandler.DeleteUserAnalytics)
+	protectedGroup.GET("/analytics/admin/dead-letters", application.analyticsHandler.GetDeadLetters)
+	protectedGroup.GET("/analytics/admin/dead-letters/:id", application.analyticsHandler.GetDeadLetter)
+	protectedGroup.POST("/analytics/admin/dead-letters/:id/replay", application.analyticsHandler.ReplayDeadLetter)
+	protectedGroup.GET("/analytics/admin/flags", application.analyticsHandler.GetUserFlags)
+	protectedGroup.GET("/analytics/admin/flags/:id", application.analyticsHandler.GetUserFlag)
+	protectedGroup.PATCH("/analytics/admin/flags/:id", application.analyticsHandler.ResolveUserFlag)
 }
@@ -7,20 +7,31 @@ import (
 )
 
 type Server struct {
-	router *gin.Engine
+	router        *gin.Engine
+	metricsRouter *gin.Engine // served on the internal metrics address, not exposed through the gateway
+	metricsAddr   string
 }
 
-func newServer() *Server {
+func newServer(metricsAddr string) *Server {
 	router := gin.Default()
 
 	server := &Server{
-		router: router,
+		router:        router,
+		metricsRouter: gin.New(),
+		metricsAddr:   metricsAddr,
 	}
 
 	return server
 }
 
 func (server *Server) Start() {
+	log.Printf("Starting metrics on %s", server.metricsAddr)
+	go func() {
+		if err := server.metricsRouter.Run(server.metricsAddr); err != nil {
+			log.Fatalf("Failed to start metrics server: %v", err)
+		}
+	}()
+
 	log.Println("Starting service on :8080")
 	if err := server.router.Run(":8080"); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
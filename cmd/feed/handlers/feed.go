@@ -48,3 +48,22 @@ func (handler *FeedHandler) GetUserTimeline(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, timeline)
 }
+
+// GetPopularTweets handles GET /v1/feed/popular
+func (handler *FeedHandler) GetPopularTweets(ctx *gin.Context) {
+	// Parse query parameters
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	// Get popular tweets
+	tweets, err := handler.service.GetPopularTweets(ctx.Request.Context(), limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get popular tweets"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feed.PopularResponse{Tweets: tweets})
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +12,9 @@ import (
 
 	"github.com/lucas-soria/microblogging/internal/feed"
 
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -20,7 +25,10 @@ func TestFeedHandler_GetUserTimeline(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockRepo := feed.NewMockRepository(ctrl)
-	service := feed.NewService(mockRepo)
+	mockUsersClient := feed.NewMockUsersClient(ctrl)
+	mockTweetsClient := feed.NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	service := feed.NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, queue.NewInMemoryQueue())
 	handler := NewFeedHandler(service)
 
 	gin.SetMode(gin.TestMode)
@@ -47,6 +55,10 @@ func TestFeedHandler_GetUserTimeline(t *testing.T) {
 		{
 			name: "successful timeline retrieval",
 			expectations: func() {
+				mockRepo.EXPECT().
+					HasUserTimeline(gomock.Any(), "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(gomock.Any(), "user1", 20, 0).
 					Return([]*feed.Tweet{
@@ -70,6 +82,10 @@ func TestFeedHandler_GetUserTimeline(t *testing.T) {
 		{
 			name: "with pagination",
 			expectations: func() {
+				mockRepo.EXPECT().
+					HasUserTimeline(gomock.Any(), "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(gomock.Any(), "user1", 10, 5).
 					Return([]*feed.Tweet{}, nil).
@@ -99,6 +115,10 @@ func TestFeedHandler_GetUserTimeline(t *testing.T) {
 		{
 			name: "service error",
 			expectations: func() {
+				mockRepo.EXPECT().
+					HasUserTimeline(gomock.Any(), "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(gomock.Any(), "user1", 20, 0).
 					Return(nil, assert.AnError).
@@ -153,3 +173,105 @@ func TestFeedHandler_GetUserTimeline(t *testing.T) {
 		})
 	}
 }
+
+func TestFeedHandler_GetPopularTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := feed.NewMockRepository(ctrl)
+	mockUsersClient := feed.NewMockUsersClient(ctrl)
+	mockTweetsClient := feed.NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	service := feed.NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, queue.NewInMemoryQueue())
+	handler := NewFeedHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware())
+	router.GET("/v1/feed/popular", handler.GetPopularTweets)
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		queryParams  string
+		want         want
+	}{
+		{
+			name: "successful popular tweets retrieval",
+			expectations: func() {
+				mockCache.EXPECT().
+					Get(gomock.Any(), cache.KeyPopularTweets, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, key string, dest any) error {
+						*dest.(*[]cache.PopularTweet) = []cache.PopularTweet{{TweetID: "1", Handler: "user2", Score: 1}}
+						return nil
+					}).
+					Times(1)
+				mockTweetsClient.EXPECT().
+					GetTweet(gomock.Any(), "1").
+					Return(&feed.Tweet{ID: "1", Handler: "user2", Content: feed.Content{Text: "Hello"}}, nil).
+					Times(1)
+			},
+			queryParams: "?limit=10",
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"tweets":[{"id":"1","handler":"user2","content":{"text":"Hello"},"created_at":"0001-01-01T00:00:00Z"}]}`),
+			},
+		},
+		{
+			name: "no popular tweets",
+			expectations: func() {
+				mockCache.EXPECT().
+					Get(gomock.Any(), cache.KeyPopularTweets, gomock.Any()).
+					Return(cache.ErrCacheMiss).
+					Times(1)
+			},
+			queryParams: "",
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"tweets":[]}`),
+			},
+		},
+		{
+			name:         "invalid limit",
+			expectations: func() {},
+			queryParams:  "?limit=abc",
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name: "service error",
+			expectations: func() {
+				mockCache.EXPECT().
+					Get(gomock.Any(), cache.KeyPopularTweets, gomock.Any()).
+					Return(errors.New("cache error")).
+					Times(1)
+			},
+			queryParams: "",
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"Failed to get popular tweets"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			url := fmt.Sprintf("/v1/feed/popular%s", tc.queryParams)
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			r.Header.Set("X-User-Id", "user1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, string(tc.want.response), w.Body.String())
+		})
+	}
+}
@@ -1,21 +1,75 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"time"
 
 	"github.com/lucas-soria/microblogging/cmd/feed/handlers"
 
 	"github.com/lucas-soria/microblogging/internal/feed"
+
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/queue"
 )
 
+// getEnv gets an environment variable or returns a default value
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+}
+
 func main() {
 	// Initialize repository
 	log.Println("Initializing feed repository")
 	feedRepo := feed.NewInMemoryFeedRepository()
 
+	// Initialize cache with the popular tweets ranked by the analytics service, shared through Redis
+	log.Println("Initializing popular tweets cache")
+	popularCache := cache.NewRedisCache(getEnv("REDIS_ADDR", "redis:6379"))
+	defer popularCache.Close()
+
+	// Initialize clients for the services the feed is built from
+	log.Println("Initializing users and tweets clients")
+	usersClient := feed.NewHTTPUsersClient(getEnv("USERS_SERVICE_URL", "http://users-service"), 5*time.Second)
+	tweetsClient := feed.NewHTTPTweetsClient(getEnv("TWEETS_SERVICE_URL", "http://tweets-service"), 5*time.Second)
+
+	// Initialize message queue, shared with the other services through Kafka
+	log.Println("Initializing feed message queue")
+	messageQueue, err := queue.NewKafkaQueue(queue.KafkaConfig{
+		Brokers:         getEnv("KAFKA_BROKERS", "kafka:9092"),
+		GroupID:         getEnv("KAFKA_GROUP_ID", "feed-service"),
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize feed message queue: %v", err)
+	}
+	defer messageQueue.Close()
+
 	// Initialize service with repository
 	log.Println("Initializing feed service")
-	feedService := feed.NewService(feedRepo)
+	feedService := feed.NewService(feedRepo, popularCache, usersClient, tweetsClient, messageQueue)
+
+	// Subscribe to events
+	log.Println("Subscribing feed consumer")
+	feedConsumer := feed.NewConsumer(feedService)
+	feedConsumer.Subscribe(messageQueue)
+
+	// Subscribe to the deletion of users, deleting their cached timeline data
+	log.Println("Subscribing user deletion consumer")
+	userDeletionConsumer := feed.NewUserDeletionConsumer(feedService, messageQueue)
+	userDeletionConsumer.Subscribe(messageQueue)
+
+	log.Println("Starting feed message queue consumer")
+	go func() {
+		if err := messageQueue.Run(context.Background()); err != nil {
+			log.Fatalf("Failed to consume feed messages: %v", err)
+		}
+	}()
 
 	// Initialize handlers with service
 	log.Println("Initializing feed handlers")
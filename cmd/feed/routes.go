@@ -28,4 +28,5 @@ func healthCheck(router *gin.Engine) {
 func feedRoutes(group *gin.RouterGroup, application *Application) {
 	group.Use(middleware.AuthMiddleware())
 	group.GET("/feed/timeline", application.feedHandler.GetUserTimeline)
+	group.GET("/feed/popular", application.feedHandler.GetPopularTweets)
 }
@@ -7,12 +7,18 @@ import (
 // Application holds the dependencies for the HTTP server
 type Application struct {
 	tweetHandler *handlers.TweetHandler
+	mediaHandler *handlers.MediaHandler
+	draftHandler *handlers.DraftHandler
+	roleSecret   string // secret the gateway signs the roles with
 }
 
 // NewApplication creates a new HTTP server and sets up routing
-func NewApplication(tweetHandler *handlers.TweetHandler) *Application {
+func NewApplication(tweetHandler *handlers.TweetHandler, mediaHandler *handlers.MediaHandler, draftHandler *handlers.DraftHandler, roleSecret string) *Application {
 	application := &Application{
 		tweetHandler: tweetHandler,
+		mediaHandler: mediaHandler,
+		draftHandler: draftHandler,
+		roleSecret:   roleSecret,
 	}
 
 	return application
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lucas-soria/microblogging/cmd/tweets/models"
+
+	"github.com/lucas-soria/microblogging/internal/tweets"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DraftHandler handles HTTP requests for draft operations
+type DraftHandler struct {
+	service tweets.DraftService
+}
+
+// NewDraftHandler creates a new draft handler
+func NewDraftHandler(service tweets.DraftService) *DraftHandler {
+	return &DraftHandler{
+		service: service,
+	}
+}
+
+// CreateDraft handles POST /v1/tweets/drafts
+func (handler *DraftHandler) CreateDraft(ctx *gin.Context) {
+	var draftRequest models.SaveDraftRequest
+	if err := ctx.ShouldBindJSON(&draftRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	draftToCreate := draftRequest.ToDraft()
+	userID, _ := ctx.Get("user_id")
+	draftToCreate.Handler = userID.(string)
+
+	draft, err := handler.service.CreateDraft(ctx.Request.Context(), draftToCreate)
+	if err != nil {
+		handler.draftError(ctx, err, "Failed to create draft")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, draft)
+}
+
+// GetDrafts handles GET /v1/tweets/drafts
+func (handler *DraftHandler) GetDrafts(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	drafts, err := handler.service.GetDrafts(ctx.Request.Context(), userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get drafts"})
+		return
+	}
+
+	if drafts == nil {
+		drafts = []*tweets.Draft{} // Return empty array instead of null
+	}
+
+	ctx.JSON(http.StatusOK, drafts)
+}
+
+// GetDraft handles GET /v1/tweets/drafts/:id
+func (handler *DraftHandler) GetDraft(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	draft, err := handler.service.GetDraft(ctx.Request.Context(), ctx.Param("id"), userID.(string))
+	if err != nil {
+		handler.draftError(ctx, err, "Failed to get draft")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, draft)
+}
+
+// UpdateDraft handles PUT /v1/tweets/drafts/:id
+func (handler *DraftHandler) UpdateDraft(ctx *gin.Context) {
+	var draftRequest models.SaveDraftRequest
+	if err := ctx.ShouldBindJSON(&draftRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	draftToUpdate := draftRequest.ToDraft()
+	userID, _ := ctx.Get("user_id")
+	draftToUpdate.ID = ctx.Param("id")
+	draftToUpdate.Handler = userID.(string)
+
+	draft, err := handler.service.UpdateDraft(ctx.Request.Context(), draftToUpdate)
+	if err != nil {
+		handler.draftError(ctx, err, "Failed to update draft")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, draft)
+}
+
+// DeleteDraft handles DELETE /v1/tweets/drafts/:id
+func (handler *DraftHandler) DeleteDraft(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	if err := handler.service.DeleteDraft(ctx.Request.Context(), ctx.Param("id"), userID.(string)); err != nil {
+		handler.draftError(ctx, err, "Failed to delete draft")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// PublishDraft handles POST /v1/tweets/drafts/:id/publish
+func (handler *DraftHandler) PublishDraft(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	tweet, err := handler.service.PublishDraft(ctx.Request.Context(), ctx.Param("id"), userID.(string))
+	if err != nil {
+		if errors.Is(err, tweets.ErrDraftNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+			return
+		}
+		// The tweet of the draft is validated like any new tweet
+		createTweetError(ctx, err)
+		return
+	}
+
+	createdTweet(ctx, tweet)
+}
+
+// draftError responds with the error of a draft operation
+func (handler *DraftHandler) draftError(ctx *gin.Context, err error, message string) {
+	if errors.Is(err, tweets.ErrDraftNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Draft not found"})
+		return
+	}
+	if errors.Is(err, tweets.ErrInvalidDraft) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
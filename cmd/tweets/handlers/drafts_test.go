@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/cmd/users/middleware"
+
+	"github.com/lucas-soria/microblogging/internal/tweets"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDrafts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockService := tweets.NewMockDraftService(ctrl)
+	handler := NewDraftHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets/drafts", handler.CreateDraft)
+	router.GET("/v1/tweets/drafts", handler.GetDrafts)
+	router.GET("/v1/tweets/drafts/:id", handler.GetDraft)
+	router.PUT("/v1/tweets/drafts/:id", handler.UpdateDraft)
+	router.DELETE("/v1/tweets/drafts/:id", handler.DeleteDraft)
+	router.POST("/v1/tweets/drafts/:id/publish", handler.PublishDraft)
+
+	now := time.Now().UTC()
+	draft := &tweets.Draft{
+		ID:        "draft-123",
+		Handler:   "test-user-123",
+		Content:   tweets.Content{Text: "Unfinished", Media: []tweets.Attachment{{ID: "media-123"}}},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	draftJSON := `{"id":"draft-123","handler":"test-user-123","content":{"text":"Unfinished","media":[{"id":"media-123"}]},` +
+		`"created_at":"` + now.Format(time.RFC3339Nano) + `","updated_at":"` + now.Format(time.RFC3339Nano) + `"}`
+
+	type args struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Create draft",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts",
+				body:   `{"content":{"text":"Unfinished","media_ids":["media-123"]}}`,
+			},
+			expectations: func() {
+				mockService.EXPECT().CreateDraft(ctx, &tweets.Draft{
+					Handler: "test-user-123",
+					Content: tweets.Content{Text: "Unfinished", Media: []tweets.Attachment{{ID: "media-123"}}},
+				}).Return(draft, nil)
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				response:   []byte(draftJSON),
+			},
+		},
+		{
+			name: "Create empty draft",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts",
+				body:   `{"content":{}}`,
+			},
+			expectations: func() {
+				mockService.EXPECT().CreateDraft(ctx, gomock.Any()).
+					Return(nil, fmt.Errorf("%w: a draft must have text, media or a poll", tweets.ErrInvalidDraft))
+			},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"invalid draft: a draft must have text, media or a poll"}`),
+			},
+		},
+		{
+			name: "Create draft with invalid body",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts",
+				body:   `{"content":`,
+			},
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid request body"}`),
+			},
+		},
+		{
+			name: "List drafts",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/drafts",
+			},
+			expectations: func() {
+				mockService.EXPECT().GetDrafts(ctx, "test-user-123").Return([]*tweets.Draft{draft}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[` + draftJSON + `]`),
+			},
+		},
+		{
+			name: "List no drafts",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/drafts",
+			},
+			expectations: func() {
+				mockService.EXPECT().GetDrafts(ctx, "test-user-123").Return(nil, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[]`),
+			},
+		},
+		{
+			name: "Get draft",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/drafts/draft-123",
+			},
+			expectations: func() {
+				mockService.EXPECT().GetDraft(ctx, "draft-123", "test-user-123").Return(draft, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(draftJSON),
+			},
+		},
+		{
+			name: "Get draft of another user",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/drafts/draft-456",
+			},
+			expectations: func() {
+				mockService.EXPECT().GetDraft(ctx, "draft-456", "test-user-123").Return(nil, tweets.ErrDraftNotFound)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Draft not found"}`),
+			},
+		},
+		{
+			name: "Update draft",
+			args: args{
+				method: http.MethodPut,
+				path:   "/v1/tweets/drafts/draft-123",
+				body:   `{"content":{"text":"Unfinished","media_ids":["media-123"]}}`,
+			},
+			expectations: func() {
+				mockService.EXPECT().UpdateDraft(ctx, &tweets.Draft{
+					ID:      "draft-123",
+					Handler: "test-user-123",
+					Content: tweets.Content{Text: "Unfinished", Media: []tweets.Attachment{{ID: "media-123"}}},
+				}).Return(draft, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(draftJSON),
+			},
+		},
+		{
+			name: "Update draft with database error",
+			args: args{
+				method: http.MethodPut,
+				path:   "/v1/tweets/drafts/draft-123",
+				body:   `{"content":{"text":"Unfinished"}}`,
+			},
+			expectations: func() {
+				mockService.EXPECT().UpdateDraft(ctx, gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+				response:   []byte(`{"error":"Failed to update draft"}`),
+			},
+		},
+		{
+			name: "Delete draft",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/v1/tweets/drafts/draft-123",
+			},
+			expectations: func() {
+				mockService.EXPECT().DeleteDraft(ctx, "draft-123", "test-user-123").Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "Publish draft",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts/draft-123/publish",
+			},
+			expectations: func() {
+				mockService.EXPECT().PublishDraft(ctx, "draft-123", "test-user-123").Return(&tweets.Tweet{
+					ID:        "tweet-123",
+					Handler:   "test-user-123",
+					Content:   tweets.Content{Text: "Unfinished"},
+					Status:    tweets.TweetStatusPublished,
+					CreatedAt: now,
+				}, nil)
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				response: []byte(`{"id":"tweet-123","handler":"test-user-123","content":{"text":"Unfinished"},"status":"published",` +
+					`"created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
+		{
+			name: "Publish missing draft",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts/draft-456/publish",
+			},
+			expectations: func() {
+				mockService.EXPECT().PublishDraft(ctx, "draft-456", "test-user-123").Return(nil, tweets.ErrDraftNotFound)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Draft not found"}`),
+			},
+		},
+		{
+			name: "Publish draft with invalid content",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets/drafts/draft-123/publish",
+			},
+			expectations: func() {
+				mockService.EXPECT().PublishDraft(ctx, "draft-123", "test-user-123").Return(nil, &tweets.ContentLengthError{Length: 300, MaxLength: 280})
+			},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"Tweet content is too long","length":300,"max_length":280}`),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(tc.args.method, tc.args.path, strings.NewReader(tc.args.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-User-Id", "test-user-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
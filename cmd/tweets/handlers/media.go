@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/lucas-soria/microblogging/internal/tweets"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaHandler handles HTTP requests for media operations
+type MediaHandler struct {
+	service tweets.MediaService
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(service tweets.MediaService) *MediaHandler {
+	return &MediaHandler{
+		service: service,
+	}
+}
+
+// UploadMedia handles POST /v1/tweets/media
+func (handler *MediaHandler) UploadMedia(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "File is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		return
+	}
+	defer file.Close()
+
+	userID, _ := ctx.Get("user_id")
+	media, err := handler.service.UploadMedia(ctx.Request.Context(), userID.(string), file)
+	if err != nil {
+		if errors.Is(err, tweets.ErrUnsupportedMediaType) {
+			ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, tweets.ErrMediaTooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload media"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, media)
+}
+
+// GetMedia handles GET /v1/tweets/media/:id
+func (handler *MediaHandler) GetMedia(ctx *gin.Context) {
+	handler.serveMedia(ctx, false)
+}
+
+// GetMediaThumbnail handles GET /v1/tweets/media/:id/thumbnail
+func (handler *MediaHandler) GetMediaThumbnail(ctx *gin.Context) {
+	handler.serveMedia(ctx, true)
+}
+
+// serveMedia responds with the blob of a media, or of its thumbnail
+func (handler *MediaHandler) serveMedia(ctx *gin.Context, thumbnail bool) {
+	media, blob, err := handler.service.OpenMedia(ctx.Request.Context(), ctx.Param("id"), thumbnail)
+	if err != nil {
+		if errors.Is(err, tweets.ErrMediaNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get media"})
+		return
+	}
+	defer blob.Close()
+
+	contentType, contentLength := media.MIMEType, media.Size
+	if thumbnail {
+		contentType, contentLength = "image/jpeg", -1
+	}
+	// The media never change, so they can be cached forever
+	ctx.DataFromReader(http.StatusOK, contentLength, contentType, blob, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
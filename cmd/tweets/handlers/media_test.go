@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/lucas-soria/microblogging/cmd/users/middleware"
+
+	"github.com/lucas-soria/microblogging/internal/tweets"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// multipartBody returns a multipart form with the file, and its content type
+func multipartBody(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile(field, "upload")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return &body, writer.FormDataContentType()
+}
+
+func TestUploadMedia(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	service := tweets.NewMediaService(mockRepo, tweets.NewLocalMediaStore(t.TempDir()), tweets.MediaLimits{MaxImageSize: 1 << 20}, nil)
+	expectKnownUsers(mockRepo)
+	handler := NewMediaHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets/media", handler.UploadMedia)
+
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 40, 30))))
+
+	type want struct {
+		statusCode int
+		response   string
+	}
+
+	tt := []struct {
+		name         string
+		field        string
+		data         []byte
+		expectations func()
+		want         want
+	}{
+		{
+			name:  "Uploaded",
+			field: "file",
+			data:  pngData.Bytes(),
+			expectations: func() {
+				mockRepo.EXPECT().
+					CreateMedia(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, media *tweets.Media) error {
+						assert.Equal(t, "test-user-123", media.Handler)
+						return nil
+					}).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				response:   `"handler":"test-user-123","type":"image","mime_type":"image/png","size":` + strconv.Itoa(pngData.Len()) + `,"width":40,"height":30`,
+			},
+		},
+		{
+			name:         "Unsupported media type",
+			field:        "file",
+			data:         []byte("Hello, world!"),
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusUnsupportedMediaType,
+				response:   `{"error":"unsupported media type: text/plain"}`,
+			},
+		},
+		{
+			name:         "Too large",
+			field:        "file",
+			data:         append(pngData.Bytes(), make([]byte, 1<<20)...),
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusRequestEntityTooLarge,
+				response:   `{"error":"media is too large: the maximum image size is 1048576 bytes"}`,
+			},
+		},
+		{
+			name:         "Missing file",
+			field:        "other",
+			data:         pngData.Bytes(),
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   `{"error":"File is required"}`,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			body, contentType := multipartBody(t, tc.field, tc.data)
+			r := httptest.NewRequest(http.MethodPost, "/v1/tweets/media", body)
+			r.Header.Set("Content-Type", contentType)
+			r.Header.Set("X-User-Id", "test-user-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Contains(t, w.Body.String(), tc.want.response)
+		})
+	}
+}
+
+func TestGetMedia(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	store := tweets.NewLocalMediaStore(t.TempDir())
+	service := tweets.NewMediaService(mockRepo, store, tweets.DefaultMediaLimits, nil)
+	handler := NewMediaHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/tweets/media/:id", handler.GetMedia)
+	router.GET("/v1/tweets/media/:id/thumbnail", handler.GetMediaThumbnail)
+
+	const mediaID = "7f1b6a52-1f1e-4c1a-9d43-5a2f3d1c8e01"
+	video := &tweets.Media{ID: mediaID, Handler: "test-user-123", Type: tweets.MediaTypeVideo, MIMEType: "video/mp4", Size: 5, StorageKey: "media/" + mediaID}
+	require.NoError(t, store.Put(ctx, video.StorageKey, bytes.NewReader([]byte("video"))))
+
+	type want struct {
+		statusCode  int
+		contentType string
+		response    string
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Media",
+			path: "/v1/tweets/media/" + mediaID,
+			expectations: func() {
+				mockRepo.EXPECT().GetMedia(ctx, mediaID).Return(video, nil).Times(1)
+			},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "video/mp4",
+				response:    "video",
+			},
+		},
+		{
+			name: "Videos have no thumbnail",
+			path: "/v1/tweets/media/" + mediaID + "/thumbnail",
+			expectations: func() {
+				mockRepo.EXPECT().GetMedia(ctx, mediaID).Return(video, nil).Times(1)
+			},
+			want: want{
+				statusCode:  http.StatusNotFound,
+				contentType: "application/json; charset=utf-8",
+				response:    `{"error":"Media not found"}`,
+			},
+		},
+		{
+			name:         "Media not found",
+			path:         "/v1/tweets/media/unknown",
+			expectations: func() {},
+			want: want{
+				statusCode:  http.StatusNotFound,
+				contentType: "application/json; charset=utf-8",
+				response:    `{"error":"Media not found"}`,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			r.Header.Set("X-User-Id", "test-user-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tc.want.response, w.Body.String())
+		})
+	}
+}
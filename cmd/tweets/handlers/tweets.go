@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/lucas-soria/microblogging/cmd/tweets/models"
 
 	"github.com/lucas-soria/microblogging/internal/tweets"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -36,7 +40,37 @@ func (handler *TweetHandler) CreateTweet(ctx *gin.Context) {
 
 	tweet, err := handler.service.CreateTweet(ctx.Request.Context(), contentToCreate)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tweet"})
+		createTweetError(ctx, err)
+		return
+	}
+
+	createdTweet(ctx, tweet)
+}
+
+// createTweetError responds with the error of a tweet creation
+func createTweetError(ctx *gin.Context, err error) {
+	var lengthErr *tweets.ContentLengthError
+	if errors.As(err, &lengthErr) {
+		ctx.JSON(http.StatusUnprocessableEntity, models.ContentTooLongResponse{
+			Error:     "Tweet content is too long",
+			Length:    lengthErr.Length,
+			MaxLength: lengthErr.MaxLength,
+		})
+		return
+	}
+	if errors.Is(err, tweets.ErrTweetRejected) || errors.Is(err, tweets.ErrInvalidMedia) || errors.Is(err, tweets.ErrInvalidPoll) ||
+		errors.Is(err, tweets.ErrInvalidSchedule) || errors.Is(err, tweets.ErrInvalidReply) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tweet"})
+}
+
+// createdTweet responds with a created tweet
+func createdTweet(ctx *gin.Context, tweet *tweets.Tweet) {
+	// Held and scheduled tweets are accepted, but only published once approved or at their publish time
+	if tweet.Status == tweets.TweetStatusHeld || tweet.Status == tweets.TweetStatusScheduled {
+		ctx.JSON(http.StatusAccepted, tweet)
 		return
 	}
 
@@ -57,14 +91,62 @@ func (handler *TweetHandler) GetTweet(ctx *gin.Context) {
 		return
 	}
 
-	if tweet == nil {
+	// Tweets held for review or rejected are only visible to their author
+	caller := auth.CallerFrom(ctx)
+	if tweet == nil || (!tweet.IsVisible() && tweet.Handler != caller.ID) {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tweet not found"})
 		return
 	}
 
+	// Polls are returned with their live tallies and the vote of the user
+	if tweet.Content.Poll != nil {
+		poll, err := handler.service.GetPoll(ctx.Request.Context(), tweet, caller.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tweet"})
+			return
+		}
+		response := *tweet
+		response.Content.Poll = poll
+		tweet = &response
+	}
+
 	ctx.JSON(http.StatusOK, tweet)
 }
 
+// VotePoll handles POST /v1/tweets/:id/poll/vote
+func (handler *TweetHandler) VotePoll(ctx *gin.Context) {
+	var voteRequest models.VoteRequest
+	if err := ctx.ShouldBindJSON(&voteRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	poll, err := handler.service.Vote(ctx.Request.Context(), ctx.Param("id"), userID.(string), *voteRequest.Option)
+	if err != nil {
+		if errors.Is(err, tweets.ErrTweetNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Tweet not found"})
+			return
+		}
+		if errors.Is(err, tweets.ErrPollNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "Poll not found"})
+			return
+		}
+		if errors.Is(err, tweets.ErrInvalidPoll) {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, tweets.ErrPollClosed) || errors.Is(err, tweets.ErrAlreadyVoted) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, poll)
+}
+
 // GetUserTweets handles GET /v1/tweets/users/:id
 func (handler *TweetHandler) GetUserTweets(ctx *gin.Context) {
 	userID := ctx.Param("id")
@@ -107,7 +189,7 @@ func (handler *TweetHandler) DeleteTweet(ctx *gin.Context) {
 	}
 
 	// Check if the authenticated user is the owner of the tweet
-	if tweet.Handler != ctx.GetHeader("X-User-Id") {
+	if tweet.Handler != auth.CallerFrom(ctx).ID {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "You can only delete your own tweets"})
 		return
 	}
@@ -119,3 +201,159 @@ func (handler *TweetHandler) DeleteTweet(ctx *gin.Context) {
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// GetHeldTweets handles GET /v1/tweets/admin/review
+func (handler *TweetHandler) GetHeldTweets(ctx *gin.Context) {
+	// Only admins can review the held tweets
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	limit, errLimit := strconv.Atoi(ctx.DefaultQuery("limit", "20"))
+	if errLimit != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+	offset, errOffset := strconv.Atoi(ctx.DefaultQuery("offset", "0"))
+	if errOffset != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+		return
+	}
+
+	count, err := handler.service.CountHeldTweets(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get held tweets"})
+		return
+	}
+	heldTweets, err := handler.service.GetHeldTweets(ctx.Request.Context(), limit, offset)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get held tweets"})
+		return
+	}
+
+	if heldTweets == nil {
+		heldTweets = []*tweets.Tweet{} // Return empty array instead of null
+	}
+
+	ctx.JSON(http.StatusOK, models.GetHeldTweetsResponse{
+		Count:  count,
+		Tweets: heldTweets,
+	})
+}
+
+// ApproveTweet handles POST /v1/tweets/admin/review/:id/approve
+func (handler *TweetHandler) ApproveTweet(ctx *gin.Context) {
+	// Only admins can review the held tweets
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	tweet, err := handler.service.ApproveTweet(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		handler.reviewError(ctx, err, "Failed to approve tweet")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tweet)
+}
+
+// RejectTweet handles POST /v1/tweets/admin/review/:id/reject
+func (handler *TweetHandler) RejectTweet(ctx *gin.Context) {
+	// Only admins can review the held tweets
+	if !requireAdmin(ctx) {
+		return
+	}
+
+	tweet, err := handler.service.RejectTweet(ctx.Request.Context(), ctx.Param("id"))
+	if err != nil {
+		handler.reviewError(ctx, err, "Failed to reject tweet")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tweet)
+}
+
+// requireAdmin responds with 403 and returns false unless the caller is an admin
+func requireAdmin(ctx *gin.Context) bool {
+	caller := auth.CallerFrom(ctx)
+	if !caller.IsAdmin() {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Admin role required"})
+		return false
+	}
+	return true
+}
+
+// reviewError responds with the error of a tweet review
+func (handler *TweetHandler) reviewError(ctx *gin.Context, err error, message string) {
+	if errors.Is(err, tweets.ErrTweetNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tweet not found"})
+		return
+	}
+	if errors.Is(err, tweets.ErrTweetNotHeld) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
+
+// GetScheduledTweets handles GET /v1/tweets/scheduled
+func (handler *TweetHandler) GetScheduledTweets(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	scheduledTweets, err := handler.service.GetScheduledTweets(ctx.Request.Context(), userID.(string))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get scheduled tweets"})
+		return
+	}
+
+	if scheduledTweets == nil {
+		scheduledTweets = []*tweets.Tweet{} // Return empty array instead of null
+	}
+
+	ctx.JSON(http.StatusOK, scheduledTweets)
+}
+
+// RescheduleTweet handles PATCH /v1/tweets/scheduled/:id
+func (handler *TweetHandler) RescheduleTweet(ctx *gin.Context) {
+	var rescheduleRequest models.RescheduleTweetRequest
+	if err := ctx.ShouldBindJSON(&rescheduleRequest); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	userID, _ := ctx.Get("user_id")
+	tweet, err := handler.service.RescheduleTweet(ctx.Request.Context(), ctx.Param("id"), userID.(string), *rescheduleRequest.PublishAt)
+	if err != nil {
+		handler.scheduleError(ctx, err, "Failed to reschedule tweet")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tweet)
+}
+
+// CancelScheduledTweet handles DELETE /v1/tweets/scheduled/:id
+func (handler *TweetHandler) CancelScheduledTweet(ctx *gin.Context) {
+	userID, _ := ctx.Get("user_id")
+	if err := handler.service.CancelScheduledTweet(ctx.Request.Context(), ctx.Param("id"), userID.(string)); err != nil {
+		handler.scheduleError(ctx, err, "Failed to cancel scheduled tweet")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// scheduleError responds with the error of a scheduled tweet operation
+func (handler *TweetHandler) scheduleError(ctx *gin.Context, err error, message string) {
+	if errors.Is(err, tweets.ErrTweetNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Tweet not found"})
+		return
+	}
+	if errors.Is(err, tweets.ErrTweetNotScheduled) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, tweets.ErrInvalidSchedule) || errors.Is(err, tweets.ErrInvalidPoll) {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,11 +14,33 @@ import (
 
 	"github.com/lucas-soria/microblogging/internal/tweets"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// testRoleSecret is the secret the tests sign the roles with, like the gateway
+const testRoleSecret = "test-role-secret"
+
+// setRole sets the role of the caller of a request, signed like the gateway does
+func setRole(header http.Header, role string) {
+	header.Set("X-User-Role", role)
+	header.Set(auth.RoleSignatureHeader, auth.SignRole(testRoleSecret, header.Get("X-User-Id"), role))
+}
+
+// expectKnownUsers makes the users known by their handlers, each with the ID "<handler>-id"
+func expectKnownUsers(mockRepo *tweets.MockRepository) {
+	mockRepo.EXPECT().GetKnownUserID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, handler string) (string, error) {
+			return handler + "-id", nil
+		}).
+		AnyTimes()
+}
+
 func TestGetTweet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -25,15 +48,18 @@ func TestGetTweet(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := tweets.NewMockRepository(ctrl)
-	service := tweets.NewService(mockRepo)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	// No author is deactivated
+	mockRepo.EXPECT().IsUserDeactivated(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
 	handler := NewTweetHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.GET("/v1/tweets/:id", handler.GetTweet)
 
 	now := time.Now().UTC()
+	closesAt := now.Add(time.Hour)
 	testTweet := &tweets.Tweet{
 		ID:      "test-tweet-123",
 		Handler: "test-user-123",
@@ -109,6 +135,84 @@ func TestGetTweet(t *testing.T) {
 				response:   []byte(`{"error":"Tweet not found"}`),
 			},
 		},
+		{
+			name: "Held tweet of another user",
+			args: args{
+				id: "held-tweet-123",
+				headers: map[string]string{
+					"X-User-Id": "other-user",
+				},
+			},
+			expectations: func(args args) {
+				mockRepo.EXPECT().
+					GetByID(ctx, args.id).
+					Return(&tweets.Tweet{ID: "held-tweet-123", Handler: "test-user-123", Status: tweets.TweetStatusHeld}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Tweet not found"}`),
+			},
+		},
+		{
+			name: "Held tweet of the user",
+			args: args{
+				id: "held-tweet-123",
+				headers: map[string]string{
+					"X-User-Id": "test-user-123",
+				},
+			},
+			expectations: func(args args) {
+				mockRepo.EXPECT().
+					GetByID(ctx, args.id).
+					Return(&tweets.Tweet{
+						ID:               "held-tweet-123",
+						Handler:          "test-user-123",
+						Content:          tweets.Content{Text: "This is a test tweet"},
+						Status:           tweets.TweetStatusHeld,
+						ModerationReason: "suspicious link",
+						CreatedAt:        now,
+					}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"id":"held-tweet-123","handler":"test-user-123","content":{"text":"This is a test tweet"},"status":"held",` +
+					`"moderation_reason":"suspicious link","created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
+		{
+			name: "Tweet with poll",
+			args: args{
+				id: "poll-tweet-123",
+				headers: map[string]string{
+					"X-User-Id": "voter",
+				},
+			},
+			expectations: func(args args) {
+				mockRepo.EXPECT().
+					GetByID(ctx, args.id).
+					Return(&tweets.Tweet{
+						ID:      "poll-tweet-123",
+						Handler: "test-user-123",
+						Content: tweets.Content{
+							Text: "Favorite language?",
+							Poll: &tweets.Poll{Options: []tweets.PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt},
+						},
+						CreatedAt: now,
+					}, nil).
+					Times(1)
+				mockRepo.EXPECT().CountVotes(ctx, args.id, closesAt).Return(map[int]int64{0: 3, 1: 1}, nil)
+				mockRepo.EXPECT().GetKnownUserID(ctx, "voter").Return("voter-id", nil)
+				mockRepo.EXPECT().GetVote(ctx, args.id, "voter-id").Return(&tweets.PollVote{TweetID: args.id, UserID: "voter-id", Option: 0}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"id":"poll-tweet-123","handler":"test-user-123","content":{"text":"Favorite language?","poll":{"options":[` +
+					`{"text":"Go","votes":3},{"text":"Rust","votes":1}],"closes_at":"` + closesAt.Format(time.RFC3339Nano) + `",` +
+					`"total_votes":4,"closed":false,"vote":0}},"created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
 	}
 
 	for _, tc := range tt {
@@ -136,12 +240,15 @@ func TestGetUserTweets(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := tweets.NewMockRepository(ctrl)
-	service := tweets.NewService(mockRepo)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
+	// No author is deactivated
+	mockRepo.EXPECT().IsUserDeactivated(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
 	handler := NewTweetHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.GET("/v1/tweets/users/:id", handler.GetUserTweets)
 
 	now := time.Now().UTC()
@@ -190,7 +297,7 @@ func TestGetUserTweets(t *testing.T) {
 			},
 			expectations: func(args args) {
 				mockRepo.EXPECT().
-					GetByUserID(ctx, args.userID).
+					GetByUserID(ctx, args.userID+"-id").
 					Return(testTweets, nil).
 					Times(1)
 			},
@@ -222,7 +329,7 @@ func TestGetUserTweets(t *testing.T) {
 			},
 			expectations: func(args args) {
 				mockRepo.EXPECT().
-					GetByUserID(ctx, args.userID).
+					GetByUserID(ctx, args.userID+"-id").
 					Return([]*tweets.Tweet{}, nil).
 					Times(1)
 			},
@@ -258,12 +365,14 @@ func TestDeleteTweet(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := tweets.NewMockRepository(ctrl)
-	service := tweets.NewService(mockRepo)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	// No author is deactivated
+	mockRepo.EXPECT().IsUserDeactivated(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
 	handler := NewTweetHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.DELETE("/v1/tweets/:id", handler.DeleteTweet)
 
 	now := time.Now().UTC()
@@ -397,12 +506,13 @@ func TestCreateTweet(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := tweets.NewMockRepository(ctrl)
-	service := tweets.NewService(mockRepo)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
 	handler := NewTweetHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.POST("/v1/tweets", handler.CreateTweet)
 
 	now := time.Now().UTC()
@@ -475,6 +585,34 @@ func TestCreateTweet(t *testing.T) {
 				tweet:      []byte(`{"error":"X-User-Id header is required"}`),
 			},
 		},
+		{
+			name: "Invalid media",
+			args: args{
+				body: []byte(`{"content":{"text":"This is a test tweet","media_ids":["invalid"]}}`),
+				headers: map[string]string{
+					"X-User-Id": "test-user-123",
+				},
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				tweet:      []byte(`{"error":"invalid tweet media: media \"invalid\" not found"}`),
+			},
+		},
+		{
+			name: "Content too long",
+			args: args{
+				body: []byte(`{"content":{"text":"` + strings.Repeat("👍", 141) + `"}}`),
+				headers: map[string]string{
+					"X-User-Id": "test-user-123",
+				},
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				tweet:      []byte(`{"error":"Tweet content is too long","length":282,"max_length":280}`),
+			},
+		},
 		{
 			name: "Could not create tweet",
 			args: args{
@@ -507,3 +645,675 @@ func TestCreateTweet(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateTweetModeration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	mockModerator := tweets.NewMockModerator(ctrl)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), mockModerator, nil)
+	expectKnownUsers(mockRepo)
+	handler := NewTweetHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets", handler.CreateTweet)
+
+	now := time.Now().UTC()
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Held for review",
+			expectations: func() {
+				mockModerator.EXPECT().
+					Moderate(ctx, gomock.Any()).
+					Return(tweets.ModerationResult{Action: tweets.ModerationHold, Reason: "suspicious link"}, nil).
+					Times(1)
+				mockRepo.EXPECT().
+					Create(ctx, gomock.Any()).
+					Return(&tweets.Tweet{
+						ID:               "test-tweet-123",
+						Handler:          "test-user-123",
+						Content:          tweets.Content{Text: "This is a test tweet"},
+						Status:           tweets.TweetStatusHeld,
+						ModerationReason: "suspicious link",
+						CreatedAt:        now,
+					}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusAccepted,
+				response: []byte(`{"id":"test-tweet-123","handler":"test-user-123","content":{"text":"This is a test tweet"},"status":"held",` +
+					`"moderation_reason":"suspicious link","created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
+		{
+			name: "Rejected",
+			expectations: func() {
+				mockModerator.EXPECT().
+					Moderate(ctx, gomock.Any()).
+					Return(tweets.ModerationResult{Action: tweets.ModerationReject, Reason: `contains blocked keyword "scam"`}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"tweet rejected: contains blocked keyword \"scam\""}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/tweets", bytes.NewReader([]byte(`{"content":{"text":"This is a test tweet"}}`)))
+			r.Header.Set("X-User-Id", "test-user-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, string(tc.want.response), w.Body.String())
+		})
+	}
+}
+
+func TestGetHeldTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	handler := NewTweetHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/tweets/admin/review", handler.GetHeldTweets)
+
+	now := time.Now().UTC()
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		query        string
+		role         string
+		expectations func()
+		want         want
+	}{
+		{
+			name:  "Held tweets",
+			query: "?limit=1",
+			role:  "admin",
+			expectations: func() {
+				mockRepo.EXPECT().CountByStatus(ctx, tweets.TweetStatusHeld).Return(int64(2), nil).Times(1)
+				mockRepo.EXPECT().
+					GetByStatus(ctx, tweets.TweetStatusHeld, 1, 0).
+					Return([]*tweets.Tweet{{
+						ID:               "test-tweet-123",
+						Handler:          "test-user-123",
+						Content:          tweets.Content{Text: "This is a test tweet"},
+						Status:           tweets.TweetStatusHeld,
+						ModerationReason: "suspicious link",
+						CreatedAt:        now,
+					}}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"count":2,"tweets":[{"id":"test-tweet-123","handler":"test-user-123","content":{"text":"This is a test tweet"},"status":"held",` +
+					`"moderation_reason":"suspicious link","created_at":"` + now.Format(time.RFC3339Nano) + `"}]}`),
+			},
+		},
+		{
+			name:  "Empty queue",
+			query: "",
+			role:  "admin",
+			expectations: func() {
+				mockRepo.EXPECT().CountByStatus(ctx, tweets.TweetStatusHeld).Return(int64(0), nil).Times(1)
+				mockRepo.EXPECT().GetByStatus(ctx, tweets.TweetStatusHeld, 20, 0).Return(nil, nil).Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"count":0,"tweets":[]}`),
+			},
+		},
+		{
+			name:         "Invalid limit",
+			query:        "?limit=abc",
+			role:         "admin",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid limit parameter"}`),
+			},
+		},
+		{
+			name:         "Not an admin",
+			query:        "",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/tweets/admin/review"+tc.query, nil)
+			r.Header.Set("X-User-Id", "admin")
+			setRole(r.Header, tc.role)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, string(tc.want.response), w.Body.String())
+		})
+	}
+}
+
+func TestReviewTweet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := tweets.NewService(mockRepo, mockPublisher, nil, nil)
+	handler := NewTweetHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets/admin/review/:id/approve", handler.ApproveTweet)
+	router.POST("/v1/tweets/admin/review/:id/reject", handler.RejectTweet)
+
+	now := time.Now().UTC()
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		path         string
+		role         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Approved",
+			path: "/v1/tweets/admin/review/test-tweet-123/approve",
+			role: "admin",
+			expectations: func() {
+				mockRepo.EXPECT().
+					Review(ctx, "test-tweet-123", tweets.TweetStatusPublished, gomock.Any()).
+					Return(&tweets.Tweet{
+						ID:               "test-tweet-123",
+						Handler:          "test-user-123",
+						Content:          tweets.Content{Text: "This is a test tweet"},
+						Status:           tweets.TweetStatusPublished,
+						ModerationReason: "suspicious link",
+						ReviewedAt:       &now,
+						CreatedAt:        now,
+					}, nil).
+					Times(1)
+				mockPublisher.EXPECT().Publish(ctx, events.TopicTweetPosted, "test-user-123", gomock.Any()).Return(nil).Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"id":"test-tweet-123","handler":"test-user-123","content":{"text":"This is a test tweet"},"status":"published",` +
+					`"moderation_reason":"suspicious link","reviewed_at":"` + now.Format(time.RFC3339Nano) + `","created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
+		{
+			name: "Rejected",
+			path: "/v1/tweets/admin/review/test-tweet-123/reject",
+			role: "admin",
+			expectations: func() {
+				mockRepo.EXPECT().
+					Review(ctx, "test-tweet-123", tweets.TweetStatusRejected, gomock.Any()).
+					Return(&tweets.Tweet{
+						ID:        "test-tweet-123",
+						Handler:   "test-user-123",
+						Content:   tweets.Content{Text: "This is a test tweet"},
+						Status:    tweets.TweetStatusRejected,
+						CreatedAt: now,
+					}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"test-tweet-123","handler":"test-user-123","content":{"text":"This is a test tweet"},"status":"rejected","created_at":"` + now.Format(time.RFC3339Nano) + `"}`),
+			},
+		},
+		{
+			name: "Tweet not found",
+			path: "/v1/tweets/admin/review/missing/approve",
+			role: "admin",
+			expectations: func() {
+				mockRepo.EXPECT().Review(ctx, "missing", tweets.TweetStatusPublished, gomock.Any()).Return(nil, tweets.ErrTweetNotFound).Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Tweet not found"}`),
+			},
+		},
+		{
+			name: "Tweet already reviewed",
+			path: "/v1/tweets/admin/review/test-tweet-123/reject",
+			role: "admin",
+			expectations: func() {
+				mockRepo.EXPECT().Review(ctx, "test-tweet-123", tweets.TweetStatusRejected, gomock.Any()).Return(nil, tweets.ErrTweetNotHeld).Times(1)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"tweet is not held for review"}`),
+			},
+		},
+		{
+			name:         "Approve as a user",
+			path:         "/v1/tweets/admin/review/test-tweet-123/approve",
+			role:         "user",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+		{
+			name:         "Reject without a role",
+			path:         "/v1/tweets/admin/review/test-tweet-123/reject",
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"Admin role required"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodPost, tc.path, nil)
+			r.Header.Set("X-User-Id", "admin")
+			setRole(r.Header, tc.role)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, string(tc.want.response), w.Body.String())
+		})
+	}
+}
+
+func TestVotePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
+	handler := NewTweetHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets/:id/poll/vote", handler.VotePoll)
+
+	closesAt := time.Now().UTC().Add(time.Hour)
+	pollTweet := func(closesAt time.Time) *tweets.Tweet {
+		return &tweets.Tweet{
+			ID:      "poll-tweet-123",
+			Handler: "test-user-123",
+			Content: tweets.Content{
+				Text: "Favorite language?",
+				Poll: &tweets.Poll{Options: []tweets.PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt},
+			},
+		}
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		body         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Voted",
+			body: `{"option":1}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(pollTweet(closesAt), nil)
+				mockRepo.EXPECT().CreateVote(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, vote *tweets.PollVote) error {
+						assert.Equal(t, "voter-id", vote.UserID)
+						assert.Equal(t, 1, vote.Option)
+						return nil
+					})
+				mockRepo.EXPECT().CountVotes(ctx, "poll-tweet-123", closesAt).Return(map[int]int64{1: 1}, nil)
+				mockRepo.EXPECT().GetVote(ctx, "poll-tweet-123", "voter-id").Return(&tweets.PollVote{Option: 1}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response: []byte(`{"options":[{"text":"Go","votes":0},{"text":"Rust","votes":1}],"closes_at":"` + closesAt.Format(time.RFC3339Nano) + `",` +
+					`"total_votes":1,"closed":false,"vote":1}`),
+			},
+		},
+		{
+			name:         "Missing option",
+			body:         `{}`,
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid request body"}`),
+			},
+		},
+		{
+			name: "Option out of range",
+			body: `{"option":2}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(pollTweet(closesAt), nil)
+			},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"invalid poll: option 2 does not exist"}`),
+			},
+		},
+		{
+			name: "Already voted",
+			body: `{"option":0}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(pollTweet(closesAt), nil)
+				mockRepo.EXPECT().CreateVote(ctx, gomock.Any()).Return(tweets.ErrAlreadyVoted)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"already voted on this poll"}`),
+			},
+		},
+		{
+			name: "Poll closed",
+			body: `{"option":0}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(pollTweet(time.Now().Add(-time.Minute)), nil)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"poll is closed"}`),
+			},
+		},
+		{
+			name: "Tweet without poll",
+			body: `{"option":0}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(&tweets.Tweet{ID: "poll-tweet-123", Handler: "test-user-123"}, nil)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Poll not found"}`),
+			},
+		},
+		{
+			name: "Tweet not found",
+			body: `{"option":0}`,
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "poll-tweet-123").Return(nil, nil)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Tweet not found"}`),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/tweets/poll-tweet-123/poll/vote", strings.NewReader(tc.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-User-Id", "voter")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
+func TestScheduledTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := tweets.NewMockRepository(ctrl)
+	service := tweets.NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
+	handler := NewTweetHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/tweets", handler.CreateTweet)
+	router.GET("/v1/tweets/scheduled", handler.GetScheduledTweets)
+	router.PATCH("/v1/tweets/scheduled/:id", handler.RescheduleTweet)
+	router.DELETE("/v1/tweets/scheduled/:id", handler.CancelScheduledTweet)
+
+	now := time.Now().UTC()
+	publishAt := now.Add(time.Hour).Truncate(time.Second)
+	scheduledTweet := func(publishAt time.Time) *tweets.Tweet {
+		return &tweets.Tweet{
+			ID:        "scheduled-tweet-123",
+			UserID:    "test-user-123-id",
+			Handler:   "test-user-123",
+			Content:   tweets.Content{Text: "Later"},
+			Status:    tweets.TweetStatusScheduled,
+			PublishAt: &publishAt,
+			CreatedAt: now,
+		}
+	}
+	scheduledJSON := func(publishAt time.Time) string {
+		return `{"id":"scheduled-tweet-123","handler":"test-user-123","content":{"text":"Later"},"status":"scheduled",` +
+			`"publish_at":"` + publishAt.Format(time.RFC3339Nano) + `","created_at":"` + now.Format(time.RFC3339Nano) + `"}`
+	}
+
+	type args struct {
+		method string
+		path   string
+		body   string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Create scheduled tweet",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets",
+				body:   `{"content":{"text":"Later"},"publish_at":"` + publishAt.Format(time.RFC3339) + `"}`,
+			},
+			expectations: func() {
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *tweets.Tweet) (*tweets.Tweet, error) {
+						assert.Equal(t, tweets.TweetStatusScheduled, tweet.Status)
+						assert.Equal(t, publishAt, *tweet.PublishAt)
+						return scheduledTweet(publishAt), nil
+					})
+			},
+			want: want{
+				statusCode: http.StatusAccepted,
+				response:   []byte(scheduledJSON(publishAt)),
+			},
+		},
+		{
+			name: "Create tweet scheduled in the past",
+			args: args{
+				method: http.MethodPost,
+				path:   "/v1/tweets",
+				body:   `{"content":{"text":"Later"},"publish_at":"` + now.Add(-time.Hour).Format(time.RFC3339) + `"}`,
+			},
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusUnprocessableEntity,
+				response:   []byte(`{"error":"invalid publish time: a tweet must be scheduled between 1 minute and 365 days ahead"}`),
+			},
+		},
+		{
+			name: "List scheduled tweets",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/scheduled",
+			},
+			expectations: func() {
+				mockRepo.EXPECT().GetScheduledByUserID(ctx, "test-user-123-id").Return([]*tweets.Tweet{scheduledTweet(publishAt)}, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[` + scheduledJSON(publishAt) + `]`),
+			},
+		},
+		{
+			name: "List no scheduled tweets",
+			args: args{
+				method: http.MethodGet,
+				path:   "/v1/tweets/scheduled",
+			},
+			expectations: func() {
+				mockRepo.EXPECT().GetScheduledByUserID(ctx, "test-user-123-id").Return(nil, nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`[]`),
+			},
+		},
+		{
+			name: "Reschedule tweet",
+			args: args{
+				method: http.MethodPatch,
+				path:   "/v1/tweets/scheduled/scheduled-tweet-123",
+				body:   `{"publish_at":"` + publishAt.Add(time.Hour).Format(time.RFC3339) + `"}`,
+			},
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "scheduled-tweet-123").Return(scheduledTweet(publishAt), nil)
+				mockRepo.EXPECT().Reschedule(ctx, "scheduled-tweet-123", publishAt.Add(time.Hour)).Return(scheduledTweet(publishAt.Add(time.Hour)), nil)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(scheduledJSON(publishAt.Add(time.Hour))),
+			},
+		},
+		{
+			name: "Reschedule without publish time",
+			args: args{
+				method: http.MethodPatch,
+				path:   "/v1/tweets/scheduled/scheduled-tweet-123",
+				body:   `{}`,
+			},
+			expectations: func() {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid request body"}`),
+			},
+		},
+		{
+			name: "Reschedule tweet of another user",
+			args: args{
+				method: http.MethodPatch,
+				path:   "/v1/tweets/scheduled/scheduled-tweet-123",
+				body:   `{"publish_at":"` + publishAt.Format(time.RFC3339) + `"}`,
+			},
+			expectations: func() {
+				tweet := scheduledTweet(publishAt)
+				tweet.UserID, tweet.Handler = "other-user-id", "other-user"
+				mockRepo.EXPECT().GetByID(ctx, "scheduled-tweet-123").Return(tweet, nil)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"Tweet not found"}`),
+			},
+		},
+		{
+			name: "Cancel scheduled tweet",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/v1/tweets/scheduled/scheduled-tweet-123",
+			},
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "scheduled-tweet-123").Return(scheduledTweet(publishAt), nil)
+				mockRepo.EXPECT().CancelScheduled(ctx, "scheduled-tweet-123").Return(nil)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "Cancel published tweet",
+			args: args{
+				method: http.MethodDelete,
+				path:   "/v1/tweets/scheduled/scheduled-tweet-123",
+			},
+			expectations: func() {
+				mockRepo.EXPECT().GetByID(ctx, "scheduled-tweet-123").Return(scheduledTweet(publishAt), nil)
+				mockRepo.EXPECT().CancelScheduled(ctx, "scheduled-tweet-123").Return(tweets.ErrTweetNotScheduled)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"tweet is not scheduled"}`),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(tc.args.method, tc.args.path, strings.NewReader(tc.args.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-User-Id", "test-user-123")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
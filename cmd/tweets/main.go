@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/lucas-soria/microblogging/cmd/tweets/handlers"
 
 	"github.com/lucas-soria/microblogging/internal/tweets"
 
 	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/queue"
 )
 
 // getEnv gets an environment variable or returns a default value
@@ -20,6 +24,36 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getDurationEnv gets a duration environment variable or returns a default value
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return duration
+}
+
+// loadModerator creates the tweets moderator from a JSON moderation config file, no file publishes every tweet
+func loadModerator(path string) (tweets.Moderator, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moderation config: %w", err)
+	}
+	var config tweets.ModerationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse moderation config: %w", err)
+	}
+	return tweets.NewModerator(config)
+}
+
 func main() {
 	// Get database configuration from environment variables
 	dbHost := getEnv("DB_HOST", "postgres-primary")
@@ -41,17 +75,94 @@ func main() {
 	log.Println("Initializing tweets repository")
 	tweetRepo := tweets.NewPostgresTweetRepository(db)
 
+	// Initialize message queue, shared with the other services through Kafka
+	log.Println("Initializing tweets message queue")
+	messageQueue, err := queue.NewKafkaQueue(queue.KafkaConfig{
+		Brokers:         getEnv("KAFKA_BROKERS", "kafka:9092"),
+		GroupID:         getEnv("KAFKA_GROUP_ID", "tweets-service"),
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize tweets message queue: %v", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize moderator
+	log.Println("Initializing tweets moderator")
+	moderator, err := loadModerator(getEnv("MODERATION_CONFIG_FILE", ""))
+	if err != nil {
+		log.Fatalf("Failed to initialize tweets moderator: %v", err)
+	}
+
+	// Initialize the users client, which identifies the users not known yet
+	log.Println("Initializing users client")
+	usersClient := tweets.NewHTTPUsersClient(getEnv("USERS_SERVICE_URL", "http://users-service"), 5*time.Second)
+
 	// Initialize service with repository
 	log.Println("Initializing tweets service")
-	tweetService := tweets.NewService(tweetRepo)
+	tweetService := tweets.NewService(tweetRepo, messageQueue, moderator, usersClient)
+
+	// Initialize media service with a local filesystem store
+	log.Println("Initializing media service")
+	mediaStore := tweets.NewLocalMediaStore(getEnv("MEDIA_DIR", "/var/lib/tweets/media"))
+	mediaService := tweets.NewMediaService(tweetRepo, mediaStore, tweets.DefaultMediaLimits, usersClient)
+
+	// Initialize draft service, publishing the drafts through the tweets service
+	log.Println("Initializing drafts service")
+	draftService := tweets.NewDraftService(tweetRepo, tweetService, usersClient)
+
+	// Subscribe to the deletion of users, deleting their tweets, drafts and votes
+	log.Println("Subscribing user deletion consumer")
+	userDeletionConsumer := tweets.NewUserDeletionConsumer(tweetService, messageQueue)
+	userDeletionConsumer.Subscribe(messageQueue)
+
+	// Subscribe to the deactivation of users, hiding their tweets until they reactivate their account
+	log.Println("Subscribing user deactivation consumer")
+	userDeactivationConsumer := tweets.NewUserDeactivationConsumer(tweetService)
+	userDeactivationConsumer.Subscribe(messageQueue)
+
+	// Subscribe to the handle changes of users, moving their tweets to the new handle
+	log.Println("Subscribing user handle change consumer")
+	userHandleChangeConsumer := tweets.NewUserHandleChangeConsumer(tweetService)
+	userHandleChangeConsumer.Subscribe(messageQueue)
+
+	// Initialize link preview worker, fetching the previews of the posted tweets in the background
+	log.Println("Initializing link preview worker")
+	previewWorker := tweets.NewPreviewWorker(tweetRepo, tweets.NewPreviewHTTPClient(5*time.Second), 1000)
+	previewWorker.Subscribe(messageQueue)
+	go previewWorker.Run(context.Background())
+
+	log.Println("Starting tweets message queue consumer")
+	go func() {
+		if err := messageQueue.Run(context.Background()); err != nil {
+			log.Fatalf("Failed to consume tweets messages: %v", err)
+		}
+	}()
+
+	// Initialize tweet scheduler, publishing the scheduled tweets at their publish time
+	log.Println("Initializing tweet scheduler")
+	tweetScheduler := tweets.NewTweetScheduler(tweetService, 10*time.Second)
+	go tweetScheduler.Run(context.Background())
+
+	// Initialize tweet purger, deleting for good the tombstones of the deleted tweets after a retention period
+	log.Println("Initializing tweet purger")
+	tweetPurger := tweets.NewTweetPurger(
+		tweetRepo,
+		getDurationEnv("DELETED_TWEETS_RETENTION", 30*24*time.Hour),
+		getDurationEnv("DELETED_TWEETS_PURGE_INTERVAL", time.Hour),
+	)
+	go tweetPurger.Run(context.Background())
 
 	// Initialize handlers with service
 	log.Println("Initializing tweets handlers")
 	tweetHandler := handlers.NewTweetHandler(tweetService)
+	mediaHandler := handlers.NewMediaHandler(mediaService)
+	draftHandler := handlers.NewDraftHandler(draftService)
 
 	// Create application
 	log.Println("Creating tweets application")
-	application := NewApplication(tweetHandler)
+	application := NewApplication(tweetHandler, mediaHandler, draftHandler, getEnv("AUTH_ROLE_SECRET", ""))
 
 	server := newServer()
 
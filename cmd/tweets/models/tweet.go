@@ -1,20 +1,94 @@
 package models
 
-import "github.com/lucas-soria/microblogging/internal/tweets"
+import (
+	"time"
+
+	"github.com/lucas-soria/microblogging/internal/tweets"
+)
 
 // CreateTweetRequest represents the request to create a new tweet
 type CreateTweetRequest struct {
-	Content Content `json:"content" binding:"required"`
+	Content   Content    `json:"content" binding:"required"`
+	PublishAt *time.Time `json:"publish_at"`  // Schedules the tweet to be published later
+	ReplyToID *string    `json:"reply_to_id"` // ID of the tweet to reply to
 }
 
 type Content struct {
-	Text string `json:"text" binding:"required"`
+	Text     string   `json:"text" binding:"required"`
+	MediaIDs []string `json:"media_ids"` // IDs of the media uploaded by the user
+	Poll     *Poll    `json:"poll"`
+}
+
+// Poll represents the poll to attach to a new tweet
+type Poll struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
 }
 
 func (c *CreateTweetRequest) ToTweet() *tweets.Tweet {
 	return &tweets.Tweet{
-		Content: tweets.Content{
-			Text: c.Content.Text,
-		},
+		Content:   toContent(c.Content.Text, c.Content.MediaIDs, c.Content.Poll),
+		PublishAt: c.PublishAt,
+		ReplyToID: c.ReplyToID,
+	}
+}
+
+// SaveDraftRequest represents the request to create or update a draft, whose content can be unfinished
+type SaveDraftRequest struct {
+	Content   DraftContent `json:"content"`
+	PublishAt *time.Time   `json:"publish_at"` // Schedules the tweet once the draft is published
+}
+
+// DraftContent represents the content of a draft, unlike the content of a tweet its text can be empty
+type DraftContent struct {
+	Text     string   `json:"text"`
+	MediaIDs []string `json:"media_ids"`
+	Poll     *Poll    `json:"poll"`
+}
+
+func (r *SaveDraftRequest) ToDraft() *tweets.Draft {
+	return &tweets.Draft{
+		Content:   toContent(r.Content.Text, r.Content.MediaIDs, r.Content.Poll),
+		PublishAt: r.PublishAt,
 	}
 }
+
+// toContent converts the content of a request to the content of a tweet, the media only have their IDs
+func toContent(text string, mediaIDs []string, poll *Poll) tweets.Content {
+	content := tweets.Content{
+		Text: text,
+	}
+	for _, id := range mediaIDs {
+		content.Media = append(content.Media, tweets.Attachment{ID: id})
+	}
+	if poll != nil {
+		content.Poll = &tweets.Poll{ClosesAt: poll.ClosesAt}
+		for _, option := range poll.Options {
+			content.Poll.Options = append(content.Poll.Options, tweets.PollOption{Text: option})
+		}
+	}
+	return content
+}
+
+// VoteRequest represents the request to vote on the poll of a tweet
+type VoteRequest struct {
+	Option *int `json:"option" binding:"required"` // Index of the option
+}
+
+// RescheduleTweetRequest represents the request to change the publish time of a scheduled tweet
+type RescheduleTweetRequest struct {
+	PublishAt *time.Time `json:"publish_at" binding:"required"`
+}
+
+// GetHeldTweetsResponse represents the response with the tweets held for review
+type GetHeldTweetsResponse struct {
+	Count  int64           `json:"count"`
+	Tweets []*tweets.Tweet `json:"tweets"`
+}
+
+// ContentTooLongResponse represents the error response for a tweet text longer than the maximum length
+type ContentTooLongResponse struct {
+	Error     string `json:"error"`
+	Length    int    `json:"length"`
+	MaxLength int    `json:"max_length"`
+}
@@ -26,9 +26,25 @@ func healthCheck(router *gin.Engine) {
 }
 
 func tweetsRoutes(group *gin.RouterGroup, application *Application) {
-	group.Use(middleware.AuthMiddleware())
+	group.Use(middleware.AuthMiddleware(application.roleSecret))
 	group.POST("/tweets", application.tweetHandler.CreateTweet)
 	group.GET("/tweets/:id", application.tweetHandler.GetTweet)
 	group.GET("/tweets/users/:id", application.tweetHandler.GetUserTweets)
 	group.DELETE("/tweets/:id", application.tweetHandler.DeleteTweet)
+	group.POST("/tweets/:id/poll/vote", application.tweetHandler.VotePoll)
+	group.GET("/tweets/scheduled", application.tweetHandler.GetScheduledTweets)
+	group.PATCH("/tweets/scheduled/:id", application.tweetHandler.RescheduleTweet)
+	group.DELETE("/tweets/scheduled/:id", application.tweetHandler.CancelScheduledTweet)
+	group.GET("/tweets/admin/review", application.tweetHandler.GetHeldTweets)
+	group.POST("/tweets/admin/review/:id/approve", application.tweetHandler.ApproveTweet)
+	group.POST("/tweets/admin/review/:id/reject", application.tweetHandler.RejectTweet)
+	group.POST("/tweets/media", application.mediaHandler.UploadMedia)
+	group.GET("/tweets/media/:id", application.mediaHandler.GetMedia)
+	group.GET("/tweets/media/:id/thumbnail", application.mediaHandler.GetMediaThumbnail)
+	group.POST("/tweets/drafts", application.draftHandler.CreateDraft)
+	group.GET("/tweets/drafts", application.draftHandler.GetDrafts)
+	group.GET("/tweets/drafts/:id", application.draftHandler.GetDraft)
+	group.PUT("/tweets/drafts/:id", application.draftHandler.UpdateDraft)
+	group.DELETE("/tweets/drafts/:id", application.draftHandler.DeleteDraft)
+	group.POST("/tweets/drafts/:id/publish", application.draftHandler.PublishDraft)
 }
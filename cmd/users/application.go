@@ -7,12 +7,14 @@ import (
 // Application holds the dependencies for the HTTP server
 type Application struct {
 	userHandler *handlers.UserHandler
+	roleSecret  string // secret the gateway signs the roles with
 }
 
 // NewApplication creates a new HTTP server and sets up routing
-func NewApplication(userHandler *handlers.UserHandler) *Application {
+func NewApplication(userHandler *handlers.UserHandler, roleSecret string) *Application {
 	application := &Application{
 		userHandler: userHandler,
+		roleSecret:  roleSecret,
 	}
 
 	return application
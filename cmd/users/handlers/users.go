@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/lucas-soria/microblogging/internal/users"
 
 	"github.com/lucas-soria/microblogging/cmd/users/models"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -54,6 +58,26 @@ func (handler *UserHandler) GetUser(ctx *gin.Context) {
 		return
 	}
 
+	// Deactivated accounts are only visible to their owner and the admins
+	caller := auth.CallerFrom(ctx)
+	if user.IsDeactivated() && !caller.CanManageUser(user.Handler) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+
+	// A previous handler redirects to the current one while the redirect lasts, so it is not permanent
+	if user.Handler != userID {
+		location := strings.Replace(ctx.FullPath(), ":id", url.PathEscape(user.Handler), 1)
+		if ctx.Request.URL.RawQuery != "" {
+			location += "?" + ctx.Request.URL.RawQuery
+		}
+		ctx.Redirect(http.StatusTemporaryRedirect, location)
+		return
+	}
+
+	// Count the visit for the tweet it came from, if any
+	handler.service.RecordProfileView(ctx.Request.Context(), caller.ID, user.Handler, ctx.Query("tweet_id"))
+
 	ctx.JSON(http.StatusOK, user)
 }
 
@@ -65,8 +89,12 @@ func (handler *UserHandler) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	// In a real app, you would check if the authenticated user has permission to delete this user
-	// For now, we'll just delete the user if they exist
+	// Only the owner of the account or an admin can delete it
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "not allowed to delete this user"})
+		return
+	}
 
 	if err := handler.service.DeleteUser(ctx.Request.Context(), userID); err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete user"})
@@ -76,6 +104,140 @@ func (handler *UserHandler) DeleteUser(ctx *gin.Context) {
 	ctx.Status(http.StatusNoContent)
 }
 
+// GetUserDeletion handles GET /v1/users/:id/deletion
+func (handler *UserHandler) GetUserDeletion(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	if userID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+		return
+	}
+
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "not allowed to get the deletion of this user"})
+		return
+	}
+
+	deletion, err := handler.service.GetUserDeletion(ctx.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, users.ErrDeletionNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "user deletion not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user deletion"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deletion)
+}
+
+// DeactivateUser handles POST /v1/users/:id/deactivate
+func (handler *UserHandler) DeactivateUser(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	if userID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+		return
+	}
+
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "not allowed to deactivate this user"})
+		return
+	}
+
+	err := handler.service.DeactivateUser(ctx.Request.Context(), userID)
+	if errors.Is(err, users.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if errors.Is(err, users.ErrUserDeactivated) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "user already deactivated"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to deactivate user"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ReactivateUser handles POST /v1/users/:id/reactivate
+func (handler *UserHandler) ReactivateUser(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	if userID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+		return
+	}
+
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "not allowed to reactivate this user"})
+		return
+	}
+
+	err := handler.service.ReactivateUser(ctx.Request.Context(), userID)
+	if errors.Is(err, users.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if errors.Is(err, users.ErrUserNotDeactivated) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "user not deactivated"})
+		return
+	}
+	if errors.Is(err, users.ErrDeactivationExpired) {
+		ctx.JSON(http.StatusGone, gin.H{"error": "user deactivation expired"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reactivate user"})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ChangeHandler handles POST /v1/users/:id/handle
+func (handler *UserHandler) ChangeHandler(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	if userID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user ID is required"})
+		return
+	}
+
+	var request models.ChangeHandlerRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	caller := auth.CallerFrom(ctx)
+	if !caller.CanManageUser(userID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "not allowed to change the handler of this user"})
+		return
+	}
+
+	user, err := handler.service.ChangeHandler(ctx.Request.Context(), userID, request.Handler)
+	if errors.Is(err, users.ErrInvalidHandler) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid handler"})
+		return
+	}
+	if errors.Is(err, users.ErrUserNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		return
+	}
+	if errors.Is(err, users.ErrHandlerExists) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "handler already exists"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to change handler"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
 // FollowUser handles POST /v1/users/:id/follow
 func (handler *UserHandler) FollowUser(ctx *gin.Context) {
 	// Get followee ID from URL path parameter
@@ -116,9 +278,8 @@ func (handler *UserHandler) UnfollowUser(ctx *gin.Context) {
 		return
 	}
 
-	// In a real app, you would get the follower ID from the authentication context
-	// For now, we'll use a header
-	followerID := ctx.GetHeader("X-User-Id")
+	// Get follower ID from the caller (set by auth middleware)
+	followerID := auth.CallerFrom(ctx).ID
 	if followerID == "" {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
 		return
@@ -7,16 +7,29 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/lucas-soria/microblogging/cmd/users/middleware"
 
 	"github.com/lucas-soria/microblogging/internal/users"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
+// testRoleSecret is the secret the tests sign the roles with, like the gateway
+const testRoleSecret = "test-role-secret"
+
+// setRole sets the role of the caller of a request, signed like the gateway does
+func setRole(header http.Header, role string) {
+	header.Set("X-User-Role", role)
+	header.Set(auth.RoleSignatureHeader, auth.SignRole(testRoleSecret, header.Get("X-User-Id"), role))
+}
+
 func TestCreateUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -24,7 +37,7 @@ func TestCreateUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := users.NewMockRepository(ctrl)
-	service := users.NewService(mockRepo)
+	service := users.NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 	handler := NewUserHandler(service)
 
 	gin.SetMode(gin.TestMode)
@@ -49,7 +62,7 @@ func TestCreateUser(t *testing.T) {
 		{
 			name: "Create user successfully",
 			args: args{
-				body: []byte(`{"handler":"testuser","first_name":"Test","last_name":"User"}`),
+				body: []byte(`{"id":"user-id","handler":"testuser","first_name":"Test","last_name":"User"}`),
 			},
 			expectations: func(args args) {
 				mockRepo.EXPECT().
@@ -58,12 +71,15 @@ func TestCreateUser(t *testing.T) {
 						FirstName: "Test",
 						LastName:  "User",
 					}).
-					Return(nil).
+					DoAndReturn(func(ctx context.Context, user *users.User) error {
+						user.ID = "user-id"
+						return nil
+					}).
 					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusCreated,
-				response:   []byte(`{"handler":"testuser","first_name":"Test","last_name":"User"}`),
+				response:   []byte(`{"id":"user-id","handler":"testuser","first_name":"Test","last_name":"User"}`),
 			},
 		},
 		{
@@ -117,11 +133,12 @@ func TestGetUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := users.NewMockRepository(ctrl)
-	service := users.NewService(mockRepo)
+	service := users.NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 	handler := NewUserHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
+	router.Use(middleware.CallerMiddleware(testRoleSecret))
 	router.GET("/v1/users/:id", handler.GetUser)
 
 	type args struct {
@@ -134,6 +151,7 @@ func TestGetUser(t *testing.T) {
 	}
 
 	testUser := &users.User{
+		ID:        "user-id",
 		Handler:   "testuser",
 		FirstName: "Test",
 		LastName:  "User",
@@ -158,7 +176,7 @@ func TestGetUser(t *testing.T) {
 			},
 			want: want{
 				statusCode: http.StatusOK,
-				response:   []byte(`{"handler":"testuser","first_name":"Test","last_name":"User"}`),
+				response:   []byte(`{"id":"user-id","handler":"testuser","first_name":"Test","last_name":"User"}`),
 			},
 		},
 		{
@@ -171,6 +189,10 @@ func TestGetUser(t *testing.T) {
 					GetUser(ctx, args.userID).
 					Return(nil, users.ErrUserNotFound).
 					Times(1)
+				mockRepo.EXPECT().
+					GetUserByPreviousHandler(ctx, args.userID, gomock.Any()).
+					Return(nil, users.ErrUserNotFound).
+					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusNotFound,
@@ -202,12 +224,12 @@ func TestDeleteUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := users.NewMockRepository(ctrl)
-	service := users.NewService(mockRepo)
+	service := users.NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 	handler := NewUserHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.DELETE("/v1/users/:id", handler.DeleteUser)
 
 	type args struct {
@@ -235,6 +257,10 @@ func TestDeleteUser(t *testing.T) {
 					DeleteUser(ctx, args.userID).
 					Return(nil).
 					Times(1)
+				mockRepo.EXPECT().
+					CreateDeletion(ctx, gomock.Any()).
+					Return(nil).
+					Times(1)
 			},
 			want: want{
 				statusCode: http.StatusNoContent,
@@ -275,6 +301,802 @@ func TestDeleteUser(t *testing.T) {
 	}
 }
 
+func TestGetUserDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := users.NewMockRepository(ctrl)
+	service := users.NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
+	handler := NewUserHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.GET("/v1/users/:id/deletion", handler.GetUserDeletion)
+
+	type args struct {
+		userID string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	requestedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	completedAt := requestedAt.Add(time.Second)
+	testDeletion := &users.UserDeletion{
+		ID:      "5f0c7a4e-8f0b-4c55-9a57-1d2f2a0f6f1e",
+		Handler: "testuser",
+		Status:  users.DeletionStatusPending,
+		Services: []users.UserDeletionService{
+			{DeletionID: "5f0c7a4e-8f0b-4c55-9a57-1d2f2a0f6f1e", Service: "analytics", CompletedAt: &completedAt},
+			{DeletionID: "5f0c7a4e-8f0b-4c55-9a57-1d2f2a0f6f1e", Service: "feed"},
+		},
+		RequestedAt: requestedAt,
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func(args args)
+		want         want
+	}{
+		{
+			name: "Get user deletion successfully",
+			args: args{
+				userID: "testuser",
+			},
+			expectations: func(args args) {
+				mockRepo.EXPECT().
+					GetLatestDeletion(ctx, args.userID).
+					Return(testDeletion, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"5f0c7a4e-8f0b-4c55-9a57-1d2f2a0f6f1e","handler":"testuser","status":"pending","services":[{"service":"analytics","completed_at":"2025-08-09T05:13:42Z"},{"service":"feed"}],"requested_at":"2025-08-09T05:13:41Z"}`),
+			},
+		},
+		{
+			name: "User deletion not found",
+			args: args{
+				userID: "nonexistent",
+			},
+			expectations: func(args args) {
+				mockRepo.EXPECT().
+					GetLatestDeletion(ctx, args.userID).
+					Return(nil, users.ErrDeletionNotFound).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user deletion not found"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations(tc.args)
+
+			url := fmt.Sprintf("/v1/users/%s/deletion", tc.args.userID)
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			r.Header.Set("X-User-Id", tc.args.userID)
+
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
+func TestUserDeletionAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := users.NewMockService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.DELETE("/v1/users/:id", handler.DeleteUser)
+	router.GET("/v1/users/:id/deletion", handler.GetUserDeletion)
+
+	type args struct {
+		method    string
+		userID    string
+		caller    string
+		role      string
+		signature string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func(args args)
+		want         want
+	}{
+		{
+			name: "Owner deletes their account",
+			args: args{
+				method: http.MethodDelete,
+				userID: "testuser",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					DeleteUser(gomock.Any(), args.userID).
+					Return(nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   nil,
+			},
+		},
+		{
+			name: "Admin deletes another account",
+			args: args{
+				method: http.MethodDelete,
+				userID: "testuser",
+				caller: "admin",
+				role:   "admin",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					DeleteUser(gomock.Any(), args.userID).
+					Return(nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   nil,
+			},
+		},
+		{
+			name: "Another user cannot delete the account",
+			args: args{
+				method: http.MethodDelete,
+				userID: "testuser",
+				caller: "otheruser",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to delete this user"}`),
+			},
+		},
+		{
+			name: "Another role cannot delete the account",
+			args: args{
+				method: http.MethodDelete,
+				userID: "testuser",
+				caller: "otheruser",
+				role:   "moderator",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to delete this user"}`),
+			},
+		},
+		{
+			name: "Unsigned admin role cannot delete another account",
+			args: args{
+				method:    http.MethodDelete,
+				userID:    "testuser",
+				caller:    "otheruser",
+				role:      "admin",
+				signature: "forged",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to delete this user"}`),
+			},
+		},
+		{
+			name: "Anonymous caller cannot delete the account",
+			args: args{
+				method: http.MethodDelete,
+				userID: "testuser",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusUnauthorized,
+				response:   []byte(`{"error":"X-User-Id header is required"}`),
+			},
+		},
+		{
+			name: "Admin gets the deletion of another account",
+			args: args{
+				method: http.MethodGet,
+				userID: "testuser",
+				caller: "admin",
+				role:   "admin",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					GetUserDeletion(gomock.Any(), args.userID).
+					Return(nil, users.ErrDeletionNotFound).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user deletion not found"}`),
+			},
+		},
+		{
+			name: "Another user cannot get the deletion of the account",
+			args: args{
+				method: http.MethodGet,
+				userID: "testuser",
+				caller: "otheruser",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to get the deletion of this user"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations(tc.args)
+
+			url := fmt.Sprintf("/v1/users/%s", tc.args.userID)
+			if tc.args.method == http.MethodGet {
+				url += "/deletion"
+			}
+			r := httptest.NewRequest(tc.args.method, url, nil)
+			if tc.args.caller != "" {
+				r.Header.Set("X-User-Id", tc.args.caller)
+			}
+			if tc.args.role != "" {
+				setRole(r.Header, tc.args.role)
+			}
+			if tc.args.signature != "" {
+				r.Header.Set(auth.RoleSignatureHeader, tc.args.signature)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
+func TestGetDeactivatedUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := users.NewMockService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CallerMiddleware(testRoleSecret))
+	router.GET("/v1/users/:id", handler.GetUser)
+
+	type args struct {
+		caller string
+		role   string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	deactivatedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	deactivatedUser := &users.User{
+		ID:            "user-id",
+		Handler:       "testuser",
+		FirstName:     "Test",
+		LastName:      "User",
+		DeactivatedAt: &deactivatedAt,
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func(args args)
+		want         want
+	}{
+		{
+			name: "Owner gets their deactivated account",
+			args: args{
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					GetUser(gomock.Any(), "testuser").
+					Return(deactivatedUser, nil).
+					Times(1)
+				mockService.EXPECT().
+					RecordProfileView(gomock.Any(), args.caller, "testuser", "").
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"user-id","handler":"testuser","first_name":"Test","last_name":"User","deactivated_at":"2025-08-09T05:13:41Z"}`),
+			},
+		},
+		{
+			name: "Admin gets a deactivated account",
+			args: args{
+				caller: "admin",
+				role:   "admin",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					GetUser(gomock.Any(), "testuser").
+					Return(deactivatedUser, nil).
+					Times(1)
+				mockService.EXPECT().
+					RecordProfileView(gomock.Any(), args.caller, "testuser", "").
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"user-id","handler":"testuser","first_name":"Test","last_name":"User","deactivated_at":"2025-08-09T05:13:41Z"}`),
+			},
+		},
+		{
+			name: "Deactivated account is not found by other users",
+			args: args{
+				caller: "otheruser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					GetUser(gomock.Any(), "testuser").
+					Return(deactivatedUser, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user not found"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations(tc.args)
+
+			r := httptest.NewRequest(http.MethodGet, "/v1/users/testuser", nil)
+			r.Header.Set("X-User-Id", tc.args.caller)
+			if tc.args.role != "" {
+				setRole(r.Header, tc.args.role)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
+func TestUserActivation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := users.NewMockService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/users/:id/deactivate", handler.DeactivateUser)
+	router.POST("/v1/users/:id/reactivate", handler.ReactivateUser)
+
+	type args struct {
+		action string
+		caller string
+		role   string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func(args args)
+		want         want
+	}{
+		{
+			name: "Owner deactivates their account",
+			args: args{
+				action: "deactivate",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					DeactivateUser(gomock.Any(), "testuser").
+					Return(nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   nil,
+			},
+		},
+		{
+			name: "Account already deactivated",
+			args: args{
+				action: "deactivate",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					DeactivateUser(gomock.Any(), "testuser").
+					Return(users.ErrUserDeactivated).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"user already deactivated"}`),
+			},
+		},
+		{
+			name: "Another user cannot deactivate the account",
+			args: args{
+				action: "deactivate",
+				caller: "otheruser",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to deactivate this user"}`),
+			},
+		},
+		{
+			name: "Admin reactivates an account",
+			args: args{
+				action: "reactivate",
+				caller: "admin",
+				role:   "admin",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ReactivateUser(gomock.Any(), "testuser").
+					Return(nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNoContent,
+				response:   nil,
+			},
+		},
+		{
+			name: "Account not deactivated",
+			args: args{
+				action: "reactivate",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ReactivateUser(gomock.Any(), "testuser").
+					Return(users.ErrUserNotDeactivated).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"user not deactivated"}`),
+			},
+		},
+		{
+			name: "Grace period over",
+			args: args{
+				action: "reactivate",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ReactivateUser(gomock.Any(), "testuser").
+					Return(users.ErrDeactivationExpired).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusGone,
+				response:   []byte(`{"error":"user deactivation expired"}`),
+			},
+		},
+		{
+			name: "Account deleted after the grace period",
+			args: args{
+				action: "reactivate",
+				caller: "testuser",
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ReactivateUser(gomock.Any(), "testuser").
+					Return(users.ErrUserNotFound).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user not found"}`),
+			},
+		},
+		{
+			name: "Another user cannot reactivate the account",
+			args: args{
+				action: "reactivate",
+				caller: "otheruser",
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to reactivate this user"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations(tc.args)
+
+			url := fmt.Sprintf("/v1/users/testuser/%s", tc.args.action)
+			r := httptest.NewRequest(http.MethodPost, url, nil)
+			r.Header.Set("X-User-Id", tc.args.caller)
+			if tc.args.role != "" {
+				setRole(r.Header, tc.args.role)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
+func TestGetUserByPreviousHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := users.NewMockService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CallerMiddleware(testRoleSecret))
+	router.GET("/v1/users/:id", handler.GetUser)
+
+	type want struct {
+		statusCode int
+		location   string
+	}
+
+	tt := []struct {
+		name         string
+		url          string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "Previous handler redirects to the current one",
+			url:  "/v1/users/olduser",
+			expectations: func() {
+				mockService.EXPECT().
+					GetUser(gomock.Any(), "olduser").
+					Return(&users.User{Handler: "newuser"}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusTemporaryRedirect,
+				location:   "/v1/users/newuser",
+			},
+		},
+		{
+			name: "Query parameters are kept",
+			url:  "/v1/users/olduser?tweet_id=123",
+			expectations: func() {
+				mockService.EXPECT().
+					GetUser(gomock.Any(), "olduser").
+					Return(&users.User{Handler: "newuser"}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusTemporaryRedirect,
+				location:   "/v1/users/newuser?tweet_id=123",
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			r.Header.Set("X-User-Id", "viewer")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestChangeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := users.NewMockService(ctrl)
+	handler := NewUserHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
+	router.POST("/v1/users/:id/handle", handler.ChangeHandler)
+
+	type args struct {
+		caller string
+		role   string
+		body   string
+	}
+
+	type want struct {
+		statusCode int
+		response   []byte
+	}
+
+	tt := []struct {
+		name         string
+		args         args
+		expectations func(args args)
+		want         want
+	}{
+		{
+			name: "Owner changes their handler",
+			args: args{
+				caller: "testuser",
+				body:   `{"handler":"newuser"}`,
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ChangeHandler(gomock.Any(), "testuser", "newuser").
+					Return(&users.User{ID: "user-id", Handler: "newuser", FirstName: "Test", LastName: "User"}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"user-id","handler":"newuser","first_name":"Test","last_name":"User"}`),
+			},
+		},
+		{
+			name: "Admin changes the handler of a user",
+			args: args{
+				caller: "admin",
+				role:   "admin",
+				body:   `{"handler":"newuser"}`,
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ChangeHandler(gomock.Any(), "testuser", "newuser").
+					Return(&users.User{ID: "user-id", Handler: "newuser"}, nil).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				response:   []byte(`{"id":"user-id","handler":"newuser","first_name":"","last_name":""}`),
+			},
+		},
+		{
+			name: "Handler taken",
+			args: args{
+				caller: "testuser",
+				body:   `{"handler":"newuser"}`,
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ChangeHandler(gomock.Any(), "testuser", "newuser").
+					Return(nil, users.ErrHandlerExists).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+				response:   []byte(`{"error":"handler already exists"}`),
+			},
+		},
+		{
+			name: "User not found",
+			args: args{
+				caller: "testuser",
+				body:   `{"handler":"newuser"}`,
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ChangeHandler(gomock.Any(), "testuser", "newuser").
+					Return(nil, users.ErrUserNotFound).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+				response:   []byte(`{"error":"user not found"}`),
+			},
+		},
+		{
+			name: "Invalid handler",
+			args: args{
+				caller: "testuser",
+				body:   `{"handler":"new user"}`,
+			},
+			expectations: func(args args) {
+				mockService.EXPECT().
+					ChangeHandler(gomock.Any(), "testuser", "new user").
+					Return(nil, users.ErrInvalidHandler).
+					Times(1)
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"invalid handler"}`),
+			},
+		},
+		{
+			name: "Missing handler",
+			args: args{
+				caller: "testuser",
+				body:   `{}`,
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				response:   []byte(`{"error":"Invalid request body"}`),
+			},
+		},
+		{
+			name: "Another user cannot change the handler",
+			args: args{
+				caller: "otheruser",
+				body:   `{"handler":"newuser"}`,
+			},
+			expectations: func(args args) {},
+			want: want{
+				statusCode: http.StatusForbidden,
+				response:   []byte(`{"error":"not allowed to change the handler of this user"}`),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations(tc.args)
+
+			r := httptest.NewRequest(http.MethodPost, "/v1/users/testuser/handle", bytes.NewBufferString(tc.args.body))
+			r.Header.Set("Content-Type", "application/json")
+			r.Header.Set("X-User-Id", tc.args.caller)
+			if tc.args.role != "" {
+				setRole(r.Header, tc.args.role)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, tc.want.statusCode, w.Code)
+			assert.Equal(t, tc.want.response, w.Body.Bytes())
+		})
+	}
+}
+
 func TestFollowUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -282,12 +1104,12 @@ func TestFollowUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := users.NewMockRepository(ctrl)
-	service := users.NewService(mockRepo)
+	service := users.NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 	handler := NewUserHandler(service)
 
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(middleware.AuthMiddleware())
+	router.Use(middleware.AuthMiddleware(testRoleSecret))
 	router.POST("/v1/users/:id/follow", handler.FollowUser)
 
 	type args struct {
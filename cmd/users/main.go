@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/lucas-soria/microblogging/cmd/users/handlers"
 
 	"github.com/lucas-soria/microblogging/internal/users"
 
 	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/queue"
 )
 
 // getEnv gets an environment variable or returns a default value
@@ -20,6 +23,19 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getDurationEnv gets a duration environment variable or returns a default value
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return duration
+}
+
 func main() {
 	// Get database configuration from environment variables
 	dbHost := getEnv("DB_HOST", "postgres-primary")
@@ -41,9 +57,47 @@ func main() {
 	log.Println("Initializing users repository")
 	userRepo := users.NewPostgresUserRepository(db)
 
-	// Initialize service with repository
+	// Initialize message queue, shared with the other services through Kafka
+	log.Println("Initializing users message queue")
+	messageQueue, err := queue.NewKafkaQueue(queue.KafkaConfig{
+		Brokers:         getEnv("KAFKA_BROKERS", "kafka:9092"),
+		GroupID:         getEnv("KAFKA_GROUP_ID", "users-service"),
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize users message queue: %v", err)
+	}
+	defer messageQueue.Close()
+
+	// Initialize service with repository, deactivated users can reactivate their account within the grace period
 	log.Println("Initializing users service")
-	userService := users.NewService(userRepo)
+	deactivationGracePeriod := getDurationEnv("DEACTIVATION_GRACE_PERIOD", 30*24*time.Hour)
+	userService := users.NewService(userRepo, messageQueue, deactivationGracePeriod)
+
+	// Subscribe to events, tracking the deletion of the data of the deleted users by the other services
+	log.Println("Subscribing users consumer")
+	usersConsumer := users.NewConsumer(userService)
+	usersConsumer.Subscribe(messageQueue)
+
+	// Start background jobs
+	ctx := context.Background()
+
+	log.Println("Starting users message queue consumer")
+	go func() {
+		if err := messageQueue.Run(ctx); err != nil {
+			log.Fatalf("Failed to consume users messages: %v", err)
+		}
+	}()
+
+	log.Println("Starting deactivated users deleter")
+	deactivatedUsersDeleter := users.NewDeactivatedUsersDeleter(
+		userRepo,
+		userService,
+		deactivationGracePeriod,
+		getDurationEnv("DEACTIVATED_USERS_DELETE_INTERVAL", time.Hour),
+	)
+	go deactivatedUsersDeleter.Run(ctx)
 
 	// Initialize handlers with service
 	log.Println("Initializing users handlers")
@@ -51,7 +105,7 @@ func main() {
 
 	// Create application
 	log.Println("Creating users application")
-	application := NewApplication(userHandler)
+	application := NewApplication(userHandler, getEnv("AUTH_ROLE_SECRET", ""))
 
 	server := newServer()
 
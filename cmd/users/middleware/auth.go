@@ -3,19 +3,30 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/lucas-soria/microblogging/pkg/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware requires the X-User-Id header, trusting the X-User-Role header only if signed with the role secret
+func AuthMiddleware(roleSecret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userID := c.GetHeader("X-User-Id")
-		if userID == "" {
+		caller := auth.Authenticate(c.Request.Header, roleSecret)
+		if caller.ID == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "X-User-Id header is required"})
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", userID)
+		auth.SetCaller(c, caller)
+		c.Next()
+	}
+}
+
+// CallerMiddleware sets the caller of the public routes, if any, like AuthMiddleware without requiring it
+func CallerMiddleware(roleSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth.SetCaller(c, auth.Authenticate(c.Request.Header, roleSecret))
 		c.Next()
 	}
 }
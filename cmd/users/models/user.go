@@ -15,3 +15,8 @@ func (c *CreateUserRequest) ToUser() *users.User {
 		LastName:  c.LastName,
 	}
 }
+
+// ChangeHandlerRequest is the new handler of a user
+type ChangeHandlerRequest struct {
+	Handler string `json:"handler" binding:"required"`
+}
@@ -26,14 +26,19 @@ func healthCheck(router *gin.Engine) {
 }
 
 func usersRoutes(group *gin.RouterGroup, application *Application) {
+	group.Use(middleware.CallerMiddleware(application.roleSecret))
 	group.POST("/users", application.userHandler.CreateUser)
 	group.GET("/users/:id", application.userHandler.GetUser)
 	group.GET("/users/:id/followers", application.userHandler.GetUserFollowers)
 	group.GET("/users/:id/followees", application.userHandler.GetUserFollowees)
 
 	protectedGroup := group.Group("")
-	protectedGroup.Use(middleware.AuthMiddleware())
+	protectedGroup.Use(middleware.AuthMiddleware(application.roleSecret))
 	protectedGroup.DELETE("/users/:id", application.userHandler.DeleteUser)
+	protectedGroup.GET("/users/:id/deletion", application.userHandler.GetUserDeletion)
+	protectedGroup.POST("/users/:id/deactivate", application.userHandler.DeactivateUser)
+	protectedGroup.POST("/users/:id/reactivate", application.userHandler.ReactivateUser)
+	protectedGroup.POST("/users/:id/handle", application.userHandler.ChangeHandler)
 	protectedGroup.POST("/users/:id/follow", application.userHandler.FollowUser)
 	protectedGroup.POST("/users/:id/unfollow", application.userHandler.UnfollowUser)
 }
@@ -1,19 +1,28 @@
 package analytics
 
 import (
+	"fmt"
 	"time"
 
-	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// UserAnalytics represents analytics data for a user
+// influencerTweetCount is the number of tweets a user must exceed to be an influencer
+const influencerTweetCount = 100
+
+// UserAnalytics represents analytics data for a user, referenced by their ID with their current handler for display
 type UserAnalytics struct {
-	Handler      string    `gorm:"primaryKey;size:64;not null" json:"handler"`
-	IsInfluencer bool      `gorm:"default:false" json:"is_influencer"`
-	IsActive     bool      `gorm:"default:true" json:"is_active"`
-	CreatedAt    time.Time `gorm:"not null;index" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"not null;index" json:"updated_at"`
+	UserID             string     `gorm:"primaryKey;type:uuid" json:"-"`
+	Handler            string     `gorm:"size:64;not null;index" json:"handler"`
+	IsInfluencer       bool       `gorm:"default:false" json:"is_influencer"`
+	IsActive           bool       `gorm:"default:true" json:"is_active"`
+	TweetCount         int64      `gorm:"not null;default:0" json:"tweet_count"`
+	FollowerCount      int64      `gorm:"not null;default:0" json:"follower_count"`
+	EngagementScore    float64    `gorm:"not null;default:0" json:"engagement_score"` // Likes and replies received, weighted as in the popular tweets
+	LastActivityAt     *time.Time `json:"last_activity_at"`                           // Time of the last event of the user
+	LastTimelineViewAt *time.Time `json:"last_timeline_view_at"`
+	CreatedAt          time.Time  `gorm:"not null;index" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"not null;index" json:"updated_at"`
 }
 
 // TableName specifies the table name for GORM
@@ -38,27 +47,254 @@ func (u *UserAnalytics) BeforeUpdate(tx *gorm.DB) error {
 	return nil
 }
 
-// Event represents an analytics event
+// Event represents an analytics event, identified by an ID assigned by its producer
 type Event struct {
-	ID        string    `gorm:"primaryKey;size:64" json:"id"`
-	EventType string    `gorm:"size:64;not null;index" json:"event_type"`
-	Handler   string    `gorm:"size:64;not null;index" json:"handler"`
-	TweetID   string    `gorm:"size:64;index" json:"tweet_id,omitempty"`
-	Timestamp time.Time `gorm:"not null;index" json:"timestamp"`
+	ID            string    `gorm:"primaryKey;size:64" json:"id"`
+	EventType     string    `gorm:"size:64;not null;index" json:"event_type"`
+	UserID        string    `gorm:"type:uuid;not null;index" json:"user_id,omitempty"`
+	Handler       string    `gorm:"size:64;not null" json:"handler"`
+	TargetUserID  string    `gorm:"type:uuid" json:"target_user_id,omitempty"`
+	TargetHandler string    `gorm:"size:64" json:"target_handler,omitempty"`
+	TweetID       string    `gorm:"size:64;index" json:"tweet_id,omitempty"`
+	TweetIDs      []string  `gorm:"type:jsonb;serializer:json" json:"tweet_ids,omitempty"`
+	Hashtags      []string  `gorm:"type:jsonb;serializer:json" json:"hashtags,omitempty"`
+	Mentions      []string  `gorm:"type:jsonb;serializer:json" json:"mentions,omitempty"`
+	ContentHash   string    `gorm:"size:64" json:"content_hash,omitempty"`
+	Timestamp     time.Time `gorm:"primaryKey;not null;index" json:"timestamp"`
 }
 
-// TableName specifies the table name for GORM
+// TableName specifies the table name for GORM, the table is partitioned by month
 func (Event) TableName() string {
 	return "events"
 }
 
-// BeforeCreate is a hook that runs before creating a new event
+// BeforeCreate is a hook that runs before creating a new event, its ID and timestamp are assigned by the producer
 func (e *Event) BeforeCreate(tx *gorm.DB) error {
-	if e.ID == "" {
-		e.ID = uuid.New().String()
-	}
 	if e.Timestamp.IsZero() {
-		e.Timestamp = time.Now()
+		return fmt.Errorf("%w: event timestamp is required", ErrInvalidEvent)
 	}
 	return nil
 }
+
+// TweetEngagement represents the engagement counters of a tweet
+type TweetEngagement struct {
+	TweetID   string    `gorm:"primaryKey;size:64" json:"tweet_id"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"-"` // Author of the tweet
+	Handler   string    `gorm:"size:64;not null" json:"handler"`
+	Likes     int64     `gorm:"not null;default:0" json:"likes"`
+	Replies   int64     `gorm:"not null;default:0" json:"replies"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TweetEngagement) TableName() string {
+	return "tweet_engagements"
+}
+
+// HashtagBucket represents the number of uses of a hashtag within a time bucket
+type HashtagBucket struct {
+	Tag         string    `gorm:"primaryKey;size:100" json:"tag"`
+	BucketStart time.Time `gorm:"primaryKey;index" json:"bucket_start"`
+	Count       int64     `gorm:"not null;default:0" json:"count"`
+}
+
+// TableName specifies the table name for GORM
+func (HashtagBucket) TableName() string {
+	return "hashtag_buckets"
+}
+
+// HashtagCount represents the uses of a hashtag within a window and within the baseline before it
+type HashtagCount struct {
+	Tag           string `json:"tag"`
+	WindowCount   int64  `json:"window_count"`
+	BaselineCount int64  `json:"baseline_count"`
+}
+
+// Trend represents a trending hashtag
+type Trend struct {
+	Tag              string  `json:"tag"`
+	Count            int64   `json:"count"`
+	Velocity         float64 `json:"velocity"`
+	BaselineVelocity float64 `json:"baseline_velocity"`
+	SpikeRatio       float64 `json:"spike_ratio"`
+	IsSpike          bool    `json:"is_spike"`
+}
+
+// DailyUserActivity represents the activity of a user during a day, rolled up from the events
+type DailyUserActivity struct {
+	Day           time.Time `gorm:"primaryKey;type:date" json:"day"`
+	UserID        string    `gorm:"primaryKey;type:uuid" json:"user_id"`
+	Tweets        int64     `gorm:"not null;default:0" json:"tweets"`
+	TimelineViews int64     `gorm:"not null;default:0" json:"timeline_views"`
+}
+
+// TableName specifies the table name for GORM
+func (DailyUserActivity) TableName() string {
+	return "daily_user_activity"
+}
+
+// DailyMetric represents the value of an activity metric on a day, rolled up from the daily user activity
+type DailyMetric struct {
+	Day    time.Time `gorm:"primaryKey;type:date" json:"day"`
+	Metric string    `gorm:"primaryKey;size:32" json:"metric"`
+	Value  int64     `gorm:"not null;default:0" json:"value"`
+}
+
+// TableName specifies the table name for GORM
+func (DailyMetric) TableName() string {
+	return "daily_metrics"
+}
+
+// MetricPoint represents the value of an activity metric over a period starting at Date
+type MetricPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// TweetDailyStats represents the counters of a tweet during a day, split in shards to spread the writes
+type TweetDailyStats struct {
+	TweetID       string    `gorm:"primaryKey;size:64" json:"-"`
+	Day           time.Time `gorm:"primaryKey;type:date" json:"date"`
+	Shard         int       `gorm:"primaryKey" json:"-"`
+	Impressions   int64     `gorm:"not null;default:0" json:"impressions"`
+	UniqueViewers uint64    `gorm:"-" json:"unique_viewers"`
+	ProfileViews  int64     `gorm:"not null;default:0" json:"profile_views"`
+	Likes         int64     `gorm:"not null;default:0" json:"likes"`
+	Replies       int64     `gorm:"not null;default:0" json:"replies"`
+	Viewers       []byte    `gorm:"-" json:"-"` // HyperLogLog sketch of the viewers
+}
+
+// TableName specifies the table name for GORM
+func (TweetDailyStats) TableName() string {
+	return "tweet_stats"
+}
+
+// TweetDailyViewers represents a shard of the HyperLogLog sketch of the users a tweet was served to during a day
+type TweetDailyViewers struct {
+	TweetID string    `gorm:"primaryKey;size:64"`
+	Day     time.Time `gorm:"primaryKey;type:date"`
+	Shard   int       `gorm:"primaryKey"`
+	Sketch  []byte    `gorm:"type:bytea;not null"`
+}
+
+// TableName specifies the table name for GORM
+func (TweetDailyViewers) TableName() string {
+	return "tweet_viewers"
+}
+
+// TweetStats represents the performance of a tweet, in total and per day
+type TweetStats struct {
+	TweetID       string             `json:"tweet_id"`
+	Handler       string             `json:"handler"`
+	CreatedAt     time.Time          `json:"created_at"`
+	Impressions   int64              `json:"impressions"`
+	UniqueViewers uint64             `json:"unique_viewers"`
+	ProfileViews  int64              `json:"profile_views"`
+	Likes         int64              `json:"likes"`
+	Replies       int64              `json:"replies"`
+	Daily         []*TweetDailyStats `json:"daily"`
+}
+
+// DeadLetter represents a message that could not be processed, with the reason of its last failure
+type DeadLetter struct {
+	ID        string    `gorm:"primaryKey;size:36" json:"id"`
+	Topic     string    `gorm:"size:64;not null;index" json:"topic"`
+	Key       string    `gorm:"size:64" json:"key"`
+	Payload   string    `gorm:"type:text;not null" json:"payload"` // Raw message, it may not be a valid event
+	Reason    string    `gorm:"type:text;not null" json:"reason"`
+	Attempts  int       `gorm:"not null" json:"attempts"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (DeadLetter) TableName() string {
+	return "dead_letter_events"
+}
+
+// ArchivedUserCounters are the tweets and followers of a user counted from the events of the dropped partitions
+type ArchivedUserCounters struct {
+	UserID        string    `gorm:"primaryKey;type:uuid" json:"user_id"`
+	TweetCount    int64     `gorm:"not null;default:0" json:"tweet_count"`
+	FollowerCount int64     `gorm:"not null;default:0" json:"follower_count"`
+	CreatedAt     time.Time `gorm:"not null" json:"created_at"` // Time of the first event of the user dropped
+}
+
+// TableName specifies the table name for GORM
+func (ArchivedUserCounters) TableName() string {
+	return "archived_user_counters"
+}
+
+// DroppedEventMonth is a month whose events partition was dropped, its events are no longer processed
+type DroppedEventMonth struct {
+	Month     time.Time `gorm:"primaryKey" json:"month"`
+	DroppedAt time.Time `gorm:"not null" json:"dropped_at"`
+}
+
+// TableName specifies the table name for GORM
+func (DroppedEventMonth) TableName() string {
+	return "dropped_event_months"
+}
+
+// KnownUser is the current handler of a user with analytics, by their ID
+type KnownUser struct {
+	ID        string    `gorm:"primaryKey;type:uuid" json:"id"`
+	Handler   string    `gorm:"size:64;not null;index" json:"handler"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (KnownUser) TableName() string {
+	return "known_users"
+}
+
+// ReplayCheckpoint represents the progress of an events replay, saved with every batch of events replayed
+type ReplayCheckpoint struct {
+	ID            string    `gorm:"primaryKey;size:64" json:"id"`
+	Until         time.Time `gorm:"not null" json:"until"` // Events after it are not replayed
+	LastTimestamp time.Time `gorm:"not null" json:"last_timestamp"`
+	LastEventID   string    `gorm:"size:64;not null" json:"last_event_id"`
+	Processed     int64     `gorm:"not null" json:"processed"`
+	Total         int64     `gorm:"not null" json:"total"`
+	StartedAt     time.Time `gorm:"not null" json:"started_at"`
+	UpdatedAt     time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ReplayCheckpoint) TableName() string {
+	return "replay_checkpoints"
+}
+
+// User flag statuses
+const (
+	UserFlagStatusOpen      = "open"
+	UserFlagStatusConfirmed = "confirmed"
+	UserFlagStatusDismissed = "dismissed"
+)
+
+// UserFlag represents suspicious behaviour of a user detected by a spam rule, at most one open flag per user and rule
+type UserFlag struct {
+	ID          string    `gorm:"primaryKey;size:36" json:"id"`
+	UserID      string    `gorm:"type:uuid;not null;index" json:"-"`
+	Handler     string    `gorm:"size:64;not null" json:"handler"`
+	Rule        string    `gorm:"size:64;not null" json:"rule"`
+	Reason      string    `gorm:"type:text;not null" json:"reason"` // Reason of the last occurrence
+	Occurrences int       `gorm:"not null;default:1" json:"occurrences"`
+	Status      string    `gorm:"size:16;not null;index" json:"status"`
+	CreatedAt   time.Time `gorm:"not null;index" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserFlag) TableName() string {
+	return "user_flags"
+}
+
+// UserFlagFilter filters the user flags, empty fields are ignored
+type UserFlagFilter struct {
+	Handler string
+	UserID  string // ID of the user with the handler, resolved by the service
+	Rule    string
+	Status  string
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: client.go
+//
+// Generated by this command:
+//
+//	mockgen -source=client.go -destination=client_mock.go -package=analytics
+//
+
+// Package analytics is a generated GoMock package.
+package analytics
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsersClient is a mock of UsersClient interface.
+type MockUsersClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersClientMockRecorder
+	isgomock struct{}
+}
+
+// MockUsersClientMockRecorder is the mock recorder for MockUsersClient.
+type MockUsersClientMockRecorder struct {
+	mock *MockUsersClient
+}
+
+// NewMockUsersClient creates a new mock instance.
+func NewMockUsersClient(ctrl *gomock.Controller) *MockUsersClient {
+	mock := &MockUsersClient{ctrl: ctrl}
+	mock.recorder = &MockUsersClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsersClient) EXPECT() *MockUsersClientMockRecorder {
+	return m.recorder
+}
+
+// GetUser mocks base method.
+func (m *MockUsersClient) GetUser(ctx context.Context, handler string) (*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, handler)
+	ret0, _ := ret[0].(*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockUsersClientMockRecorder) GetUser(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockUsersClient)(nil).GetUser), ctx, handler)
+}
@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// Consumer handles the events the analytics service is subscribed to, retrying and dead-lettering the failed ones
+type Consumer struct {
+	service     Service
+	detector    *SpamDetector
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// NewConsumer creates a new analytics event consumer that processes each event up to maxAttempts times
+func NewConsumer(service Service, detector *SpamDetector, maxAttempts int, backoff time.Duration) *Consumer {
+	return &Consumer{
+		service:     service,
+		detector:    detector,
+		maxAttempts: max(maxAttempts, 1),
+		backoff:     backoff,
+	}
+}
+
+// Subscribe registers the consumer handlers in the subscriber
+func (consumer *Consumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicTweetPosted, consumer.HandleEvent)
+	subscriber.Subscribe(events.TopicTimelineViewed, consumer.HandleEvent)
+	subscriber.Subscribe(events.TopicTweetEngaged, consumer.HandleEvent)
+	subscriber.Subscribe(events.TopicProfileViewed, consumer.HandleEvent)
+	subscriber.Subscribe(events.TopicFollowChanged, consumer.HandleEvent)
+}
+
+// HandleEvent processes an activity event, failing if it could not be dead-lettered or during an events replay
+func (consumer *Consumer) HandleEvent(ctx context.Context, message *queue.Message) error {
+	var event Event
+	if err := message.Decode(&event); err != nil {
+		return consumer.deadLetter(ctx, message, err, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := consumer.process(ctx, &event)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, ErrReplayInProgress) {
+			return fmt.Errorf("refused event %s: %w", event.ID, err)
+		}
+		if errors.Is(err, ErrInvalidEvent) || attempt >= consumer.maxAttempts {
+			return consumer.deadLetter(ctx, message, err, attempt)
+		}
+
+		// Wait before retrying, doubling the backoff on every attempt
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stopped retrying event %s: %w", event.ID, err)
+		case <-time.After(consumer.backoff << (attempt - 1)):
+		}
+	}
+}
+
+// process processes an event and checks it for spam
+func (consumer *Consumer) process(ctx context.Context, event *Event) error {
+	if err := consumer.service.ProcessEvent(ctx, event); err != nil {
+		return err
+	}
+	if consumer.detector == nil {
+		return nil
+	}
+	return consumer.detector.Check(ctx, event)
+}
+
+// deadLetter stores a message that could not be processed with the reason of its last failure
+func (consumer *Consumer) deadLetter(ctx context.Context, message *queue.Message, reason error, attempts int) error {
+	log.Printf("dead-lettering %s message with key %s after %d attempts: %v", message.Topic, message.Key, attempts, reason)
+
+	deadLetter := &DeadLetter{
+		Topic:    message.Topic,
+		Key:      message.Key,
+		Payload:  string(message.Payload),
+		Reason:   reason.Error(),
+		Attempts: attempts,
+	}
+	if err := consumer.service.SaveDeadLetter(ctx, deadLetter); err != nil {
+		return fmt.Errorf("failed to dead-letter %s message: %w", message.Topic, err)
+	}
+	deadLettersTotal.Add(1)
+	return nil
+}
@@ -0,0 +1,207 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConsumer_HandleEvent(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	consumer := NewConsumer(NewService(repo, nil, nil), nil, 3, time.Millisecond)
+
+	messageQueue := queue.NewInMemoryQueue()
+	consumer.Subscribe(messageQueue)
+
+	now := time.Now().UTC()
+	require.NoError(t, messageQueue.Publish(ctx, events.TopicTweetPosted, "user1", events.Event{
+		ID:        "event-1",
+		EventType: events.TypeTweetCreated,
+		Handler:   "user1",
+		TweetID:   "tweet-1",
+		Timestamp: now,
+	}))
+	require.NoError(t, messageQueue.Publish(ctx, events.TopicTweetEngaged, "user2", events.Event{
+		ID:        "event-2",
+		EventType: events.TypeTweetLiked,
+		Handler:   "user2",
+		TweetID:   "tweet-1",
+		Timestamp: now,
+	}))
+
+	// A redelivered message is not counted twice
+	require.NoError(t, messageQueue.Publish(ctx, events.TopicTweetEngaged, "user2", events.Event{
+		ID:        "event-2",
+		EventType: events.TypeTweetLiked,
+		Handler:   "user2",
+		TweetID:   "tweet-1",
+		Timestamp: now,
+	}))
+
+	require.NoError(t, messageQueue.Publish(ctx, events.TopicFollowChanged, "user1", events.Event{
+		ID:            "event-3",
+		EventType:     events.TypeUserFollowed,
+		Handler:       "user2",
+		TargetHandler: "user1",
+		Timestamp:     now,
+	}))
+
+	analytics, err := repo.GetUserAnalytics(ctx, database.LegacyUserIDOf("user1"))
+	require.NoError(t, err)
+	assert.True(t, analytics.IsActive)
+	assert.Equal(t, int64(1), analytics.FollowerCount)
+	assert.Equal(t, likeWeight, analytics.EngagementScore)
+
+	engagements, err := repo.GetTweetEngagements(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, engagements, 1)
+	assert.Equal(t, "tweet-1", engagements[0].TweetID)
+	assert.Equal(t, int64(1), engagements[0].Likes)
+}
+
+func TestConsumer_HandleEventInvalidPayload(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	consumer := NewConsumer(NewService(repo, nil, nil), nil, 3, time.Millisecond)
+
+	err := consumer.HandleEvent(ctx, &queue.Message{Topic: events.TopicTweetPosted, Key: "user1", Payload: []byte("not json")})
+	require.NoError(t, err)
+
+	deadLetters, err := repo.GetDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 1)
+	assert.Equal(t, events.TopicTweetPosted, deadLetters[0].Topic)
+	assert.Equal(t, "user1", deadLetters[0].Key)
+	assert.Equal(t, "not json", deadLetters[0].Payload)
+	assert.Equal(t, 1, deadLetters[0].Attempts)
+	assert.Contains(t, deadLetters[0].Reason, "failed to decode TweetPosted message")
+}
+
+func TestConsumer_HandleEventRetries(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	serviceMock := NewMockService(ctrl)
+	consumer := NewConsumer(serviceMock, nil, 3, time.Millisecond)
+
+	message := &queue.Message{
+		Topic:   events.TopicTweetPosted,
+		Key:     "user1",
+		Payload: []byte(`{"id":"event-1","event_type":"tweet_created","handler":"user1"}`),
+	}
+	transientErr := errors.New("connection refused")
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         error
+	}{
+		{
+			name: "transient error is retried",
+			expectations: func() {
+				gomock.InOrder(
+					serviceMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(transientErr),
+					serviceMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(nil),
+				)
+			},
+			want: nil,
+		},
+		{
+			name: "persistent transient error is dead-lettered after the last attempt",
+			expectations: func() {
+				serviceMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(transientErr).Times(3)
+				serviceMock.EXPECT().
+					SaveDeadLetter(gomock.Any(), &DeadLetter{
+						Topic:    events.TopicTweetPosted,
+						Key:      "user1",
+						Payload:  string(message.Payload),
+						Reason:   "connection refused",
+						Attempts: 3,
+					}).
+					Return(nil)
+			},
+			want: nil,
+		},
+		{
+			name: "invalid event is dead-lettered without retries",
+			expectations: func() {
+				invalidErr := fmt.Errorf("%w: unknown event type", ErrInvalidEvent)
+				serviceMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(invalidErr)
+				serviceMock.EXPECT().
+					SaveDeadLetter(gomock.Any(), &DeadLetter{
+						Topic:    events.TopicTweetPosted,
+						Key:      "user1",
+						Payload:  string(message.Payload),
+						Reason:   invalidErr.Error(),
+						Attempts: 1,
+					}).
+					Return(nil)
+			},
+			want: nil,
+		},
+		{
+			name: "dead letter save error",
+			expectations: func() {
+				serviceMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(transientErr).Times(3)
+				serviceMock.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			want: errors.New("failed to dead-letter TweetPosted message: database error"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleEvent(ctx, message)
+
+			if tc.want != nil {
+				assert.EqualError(t, err, tc.want.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestConsumer_HandleEventDetectsSpam(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	detector := NewSpamDetector(repo, ExcessiveMentionsRule{MaxPerTweet: 2, MaxPerPeriod: 10, Period: time.Hour})
+	consumer := NewConsumer(NewService(repo, nil, nil), detector, 3, time.Millisecond)
+
+	messageQueue := queue.NewInMemoryQueue()
+	consumer.Subscribe(messageQueue)
+
+	require.NoError(t, messageQueue.Publish(ctx, events.TopicTweetPosted, "user1", events.Event{
+		ID:        "event-1",
+		EventType: events.TypeTweetCreated,
+		Handler:   "user1",
+		TweetID:   "tweet-1",
+		Mentions:  []string{"user2", "user3", "user4"},
+		Timestamp: time.Now().UTC(),
+	}))
+
+	flags, err := repo.GetUserFlags(ctx, UserFlagFilter{UserID: database.LegacyUserIDOf("user1")}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, RuleExcessiveMentions, flags[0].Rule)
+
+	count, err := repo.CountEvents(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
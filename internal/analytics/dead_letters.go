@@ -0,0 +1,57 @@
+package analytics
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// deadLettersCountTimeout bounds how long reading the dead letters gauge waits for the repository
+const deadLettersCountTimeout = 2 * time.Second
+
+// deadLettersTotal counts the messages dead-lettered by the consumers since the service started
+var deadLettersTotal = expvar.NewInt("analytics_dead_letters_total")
+
+// VarsHandler serves the analytics_ expvars as JSON, without the ones of the process
+func VarsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprint(w, "{")
+		first := true
+		expvar.Do(func(kv expvar.KeyValue) {
+			if !strings.HasPrefix(kv.Key, "analytics_") {
+				return
+			}
+			if !first {
+				fmt.Fprint(w, ",")
+			}
+			first = false
+			fmt.Fprintf(w, "%q:%s", kv.Key, kv.Value)
+		})
+		fmt.Fprint(w, "}")
+	})
+}
+
+// PublishDeadLettersGauge publishes the number of stored dead letters as the analytics_dead_letters expvar
+func PublishDeadLettersGauge(service Service) {
+	expvar.Publish("analytics_dead_letters", deadLettersGauge(service))
+}
+
+// deadLettersGauge counts the stored dead letters every time it is read, a failure is reported as -1
+func deadLettersGauge(service Service) expvar.Func {
+	return func() any {
+		ctx, cancel := context.WithTimeout(context.Background(), deadLettersCountTimeout)
+		defer cancel()
+
+		count, err := service.CountDeadLetters(ctx)
+		if err != nil {
+			log.Printf("failed to count dead letters: %v", err)
+			return -1
+		}
+		return count
+	}
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeadLettersTotal(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	consumer := NewConsumer(NewService(repo, nil, nil), nil, 3, time.Millisecond)
+
+	before := deadLettersTotal.Value()
+	require.NoError(t, consumer.HandleEvent(ctx, &queue.Message{Topic: events.TopicTweetPosted, Key: "user1", Payload: []byte("not json")}))
+	require.NoError(t, consumer.HandleEvent(ctx, &queue.Message{Topic: events.TopicTweetPosted, Key: "user2", Payload: []byte("not json")}))
+
+	assert.Equal(t, before+2, deadLettersTotal.Value())
+}
+
+func TestVarsHandler(t *testing.T) {
+	rr := httptest.NewRecorder()
+	VarsHandler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/debug/vars", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var vars map[string]any
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &vars))
+	assert.Contains(t, vars, "analytics_dead_letters_total")
+	assert.NotContains(t, vars, "cmdline")
+	assert.NotContains(t, vars, "memstats")
+}
+
+func TestDeadLettersGauge(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	gauge := deadLettersGauge(NewService(repo, nil, nil))
+	assert.Equal(t, int64(0), gauge.Value())
+
+	require.NoError(t, repo.SaveDeadLetter(ctx, &DeadLetter{ID: "dead-letter-1", Topic: events.TopicTweetPosted, Payload: "not json"}))
+	require.NoError(t, repo.SaveDeadLetter(ctx, &DeadLetter{ID: "dead-letter-2", Topic: events.TopicTweetPosted, Payload: "not json"}))
+	assert.Equal(t, int64(2), gauge.Value())
+	assert.Equal(t, "2", gauge.String())
+}
+
+func TestDeadLettersGaugeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	repoMock.EXPECT().CountDeadLetters(gomock.Any()).Return(int64(0), errors.New("database error"))
+
+	gauge := deadLettersGauge(NewService(repoMock, nil, nil))
+	assert.Equal(t, -1, gauge.Value())
+}
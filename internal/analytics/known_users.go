@@ -0,0 +1,40 @@
+package analytics
+
+import (
+	"context"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+)
+
+// userIDs resolves the IDs the analytics of the users reference by their handlers
+type userIDs struct {
+	repository KnownUserRepository
+	users      UsersClient
+}
+
+// known returns the ID of the user known by a handler, "" if the user has no analytics
+func (ids *userIDs) known(ctx context.Context, handler string) (string, error) {
+	return ids.repository.GetKnownUserID(ctx, handler)
+}
+
+// resolve returns the ID and current handler of the user with a handler, asking the users service if it is not known
+func (ids *userIDs) resolve(ctx context.Context, handler string) (*User, error) {
+	id, err := ids.known(ctx, handler)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		return &User{ID: id, Handler: handler}, nil
+	}
+
+	user := &User{ID: database.LegacyUserIDOf(handler), Handler: handler}
+	if ids.users != nil {
+		if user, err = ids.users.GetUser(ctx, handler); err != nil {
+			return nil, err
+		}
+	}
+	if err := ids.repository.SaveKnownUser(ctx, user.ID, user.Handler); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
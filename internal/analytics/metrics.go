@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Activity metrics
+const (
+	MetricDAU           = "dau"
+	MetricWAU           = "wau"
+	MetricMAU           = "mau"
+	MetricTweets        = "tweets"
+	MetricTimelineViews = "timeline_views"
+)
+
+// Metric granularities
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+const (
+	// day is the duration of a metrics day, all the metrics days are in UTC
+	day = 24 * time.Hour
+	// maxMetricsRange is the longest range of days that can be queried at once
+	maxMetricsRange = 3 * 366 * day
+	// lateEventsAllowance is how far before the last aggregated day the aggregator starts,
+	// so events that arrive late are still rolled up
+	lateEventsAllowance = day
+)
+
+// summedMetrics are the metrics whose daily values are added up over a period, the others are averaged
+var summedMetrics = map[string]bool{
+	MetricTweets:        true,
+	MetricTimelineViews: true,
+}
+
+// isValidMetric reports whether metric is a known activity metric
+func isValidMetric(metric string) bool {
+	switch metric {
+	case MetricDAU, MetricWAU, MetricMAU, MetricTweets, MetricTimelineViews:
+		return true
+	}
+	return false
+}
+
+// truncateDay returns the start of the UTC day of t
+func truncateDay(t time.Time) time.Time {
+	year, month, dayOfMonth := t.UTC().Date()
+	return time.Date(year, month, dayOfMonth, 0, 0, 0, 0, time.UTC)
+}
+
+// periodStart returns the start of the period of the given granularity that contains the date, weeks start on Monday
+func periodStart(date time.Time, granularity string) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		daysSinceMonday := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -daysSinceMonday)
+	case GranularityMonth:
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return date
+	}
+}
+
+// MetricsAggregator periodically rolls up the events into the daily activity metrics
+type MetricsAggregator struct {
+	repository ActivityMetricsRepository
+	interval   time.Duration
+}
+
+// NewMetricsAggregator creates a new metrics aggregator
+func NewMetricsAggregator(repository ActivityMetricsRepository, interval time.Duration) *MetricsAggregator {
+	return &MetricsAggregator{
+		repository: repository,
+		interval:   interval,
+	}
+}
+
+// Run aggregates the metrics at startup and on every interval until the context is cancelled
+func (aggregator *MetricsAggregator) Run(ctx context.Context) {
+	ticker := time.NewTicker(aggregator.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := aggregator.Aggregate(ctx); err != nil {
+			log.Printf("failed to aggregate metrics: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Aggregate rolls up the events since the last aggregated day, recomputing whole days
+func (aggregator *MetricsAggregator) Aggregate(ctx context.Context) error {
+	from, err := aggregator.repository.GetLastAggregatedDay(ctx)
+	if err != nil {
+		return err
+	}
+
+	if from.IsZero() {
+		// First run, start from the first event
+		firstEvent, err := aggregator.repository.GetFirstEventTime(ctx)
+		if err != nil {
+			return err
+		}
+		if firstEvent.IsZero() {
+			return nil // Nothing to aggregate yet
+		}
+		from = truncateDay(firstEvent)
+	} else {
+		from = truncateDay(from).Add(-lateEventsAllowance)
+	}
+
+	// Aggregate up to the end of the current day, which is recomputed on the next run
+	to := truncateDay(time.Now()).Add(day)
+
+	return aggregator.repository.AggregateDailyMetrics(ctx, from, to)
+}
@@ -0,0 +1,93 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// Wednesday
+	date := time.Date(2025, 8, 13, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, date, periodStart(date, GranularityDay))
+	assert.Equal(t, time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC), periodStart(date, GranularityWeek))
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), periodStart(date, GranularityMonth))
+
+	// Sunday belongs to the week started on the previous Monday
+	sunday := time.Date(2025, 8, 17, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC), periodStart(sunday, GranularityWeek))
+}
+
+func TestTruncateDay(t *testing.T) {
+	// 23:30 at UTC-3 is already the next day in UTC
+	date := time.Date(2025, 8, 13, 23, 30, 0, 0, time.FixedZone("ART", -3*60*60))
+
+	assert.Equal(t, time.Date(2025, 8, 14, 0, 0, 0, 0, time.UTC), truncateDay(date))
+}
+
+func TestMetricsAggregator_Aggregate(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockActivityMetricsRepository(ctrl)
+	aggregator := NewMetricsAggregator(repoMock, time.Minute)
+
+	tomorrow := truncateDay(time.Now()).Add(day)
+	lastAggregatedDay := time.Date(2025, 8, 13, 0, 0, 0, 0, time.UTC)
+	firstEvent := time.Date(2025, 8, 1, 15, 4, 5, 0, time.UTC)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         error
+	}{
+		{
+			name: "resumes from the day before the last aggregated day",
+			expectations: func() {
+				repoMock.EXPECT().GetLastAggregatedDay(gomock.Any()).Return(lastAggregatedDay, nil)
+				repoMock.EXPECT().AggregateDailyMetrics(gomock.Any(), lastAggregatedDay.Add(-day), tomorrow).Return(nil)
+			},
+			want: nil,
+		},
+		{
+			name: "first run starts from the first event",
+			expectations: func() {
+				repoMock.EXPECT().GetLastAggregatedDay(gomock.Any()).Return(time.Time{}, nil)
+				repoMock.EXPECT().GetFirstEventTime(gomock.Any()).Return(firstEvent, nil)
+				repoMock.EXPECT().AggregateDailyMetrics(gomock.Any(), time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), tomorrow).Return(nil)
+			},
+			want: nil,
+		},
+		{
+			name: "no events to aggregate",
+			expectations: func() {
+				repoMock.EXPECT().GetLastAggregatedDay(gomock.Any()).Return(time.Time{}, nil)
+				repoMock.EXPECT().GetFirstEventTime(gomock.Any()).Return(time.Time{}, nil)
+			},
+			want: nil,
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().GetLastAggregatedDay(gomock.Any()).Return(lastAggregatedDay, nil)
+				repoMock.EXPECT().AggregateDailyMetrics(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+			},
+			want: errors.New("database error"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := aggregator.Aggregate(ctx)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/cache"
+)
+
+const (
+	// likeWeight is the number of points a like adds to the score of a tweet
+	likeWeight = 1.0
+	// replyWeight is the number of points a reply adds to the score of a tweet
+	replyWeight = 2.0
+	// gravity controls how fast the score of a tweet decays with its age
+	gravity = 1.8
+)
+
+// PopularTweetsRanker periodically scores recent tweets and caches the most popular ones
+type PopularTweetsRanker struct {
+	repository TweetEngagementRepository
+	cache      cache.Cache
+	window     time.Duration
+	size       int
+	interval   time.Duration
+}
+
+// NewPopularTweetsRanker creates a new popular tweets ranker
+func NewPopularTweetsRanker(repository TweetEngagementRepository, cache cache.Cache, window time.Duration, size int, interval time.Duration) *PopularTweetsRanker {
+	return &PopularTweetsRanker{
+		repository: repository,
+		cache:      cache,
+		window:     window,
+		size:       size,
+		interval:   interval,
+	}
+}
+
+// Run refreshes the popular tweets at startup and on every interval until the context is cancelled
+func (ranker *PopularTweetsRanker) Run(ctx context.Context) {
+	ticker := time.NewTicker(ranker.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ranker.Refresh(ctx); err != nil {
+			log.Printf("failed to refresh popular tweets: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh scores the tweets created within the window and caches the top ones
+func (ranker *PopularTweetsRanker) Refresh(ctx context.Context) ([]cache.PopularTweet, error) {
+	now := time.Now()
+
+	engagements, err := ranker.repository.GetTweetEngagements(ctx, now.Add(-ranker.window))
+	if err != nil {
+		return nil, err
+	}
+
+	popular := make([]cache.PopularTweet, 0, len(engagements))
+	for _, engagement := range engagements {
+		popular = append(popular, cache.PopularTweet{
+			TweetID: engagement.TweetID,
+			Handler: engagement.Handler,
+			Score:   popularityScore(engagement, now),
+		})
+	}
+	sort.SliceStable(popular, func(i, j int) bool {
+		return popular[i].Score > popular[j].Score
+	})
+	if len(popular) > ranker.size {
+		popular = popular[:ranker.size]
+	}
+
+	// Keep the list for two intervals so a failed refresh does not empty it
+	if err := ranker.cache.Set(ctx, cache.KeyPopularTweets, popular, 2*ranker.interval); err != nil {
+		return nil, err
+	}
+
+	return popular, nil
+}
+
+// popularityScore scores a tweet by its engagement, decayed by its age in hours
+func popularityScore(engagement *TweetEngagement, now time.Time) float64 {
+	points := 1 + likeWeight*float64(engagement.Likes) + replyWeight*float64(engagement.Replies)
+	ageHours := math.Max(now.Sub(engagement.CreatedAt).Hours(), 0)
+	return points / math.Pow(ageHours+2, gravity)
+}
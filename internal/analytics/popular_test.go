@@ -0,0 +1,105 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPopularTweetsRanker_Refresh(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	repoMock := NewMockTweetEngagementRepository(ctrl)
+	popularCache := cache.NewInMemoryCache()
+	ranker := NewPopularTweetsRanker(repoMock, popularCache, 24*time.Hour, 2, time.Minute)
+
+	type want struct {
+		tweetIDs []string
+		err      error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "ranks and caches the top tweets",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetTweetEngagements(gomock.Any(), gomock.Any()).
+					Return([]*TweetEngagement{
+						{TweetID: "old", Handler: "user1", Likes: 10, CreatedAt: now.Add(-20 * time.Hour)},
+						{TweetID: "liked", Handler: "user2", Likes: 10, CreatedAt: now.Add(-time.Hour)},
+						{TweetID: "replied", Handler: "user3", Replies: 10, CreatedAt: now.Add(-time.Hour)},
+					}, nil)
+			},
+			want: want{tweetIDs: []string{"replied", "liked"}, err: nil},
+		},
+		{
+			name: "no recent tweets",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetTweetEngagements(gomock.Any(), gomock.Any()).
+					Return([]*TweetEngagement{}, nil)
+			},
+			want: want{tweetIDs: []string{}, err: nil},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetTweetEngagements(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			want: want{tweetIDs: nil, err: errors.New("database error")},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			popular, err := ranker.Refresh(ctx)
+
+			assert.Equal(t, tc.want.err, err)
+			if tc.want.err != nil {
+				return
+			}
+
+			tweetIDs := []string{}
+			for _, tweet := range popular {
+				tweetIDs = append(tweetIDs, tweet.TweetID)
+			}
+			assert.Equal(t, tc.want.tweetIDs, tweetIDs)
+
+			var cached []cache.PopularTweet
+			require.NoError(t, popularCache.Get(ctx, cache.KeyPopularTweets, &cached))
+			assert.Equal(t, popular, cached)
+		})
+	}
+}
+
+func TestPopularityScore(t *testing.T) {
+	now := time.Now()
+
+	fresh := popularityScore(&TweetEngagement{CreatedAt: now}, now)
+	old := popularityScore(&TweetEngagement{CreatedAt: now.Add(-10 * time.Hour)}, now)
+	liked := popularityScore(&TweetEngagement{Likes: 1, CreatedAt: now}, now)
+	replied := popularityScore(&TweetEngagement{Replies: 1, CreatedAt: now}, now)
+	future := popularityScore(&TweetEngagement{CreatedAt: now.Add(time.Hour)}, now)
+
+	assert.Greater(t, fresh, old)
+	assert.Greater(t, liked, fresh)
+	assert.Greater(t, replied, liked)
+	assert.Equal(t, fresh, future)
+}
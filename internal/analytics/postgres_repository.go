@@ -2,16 +2,166 @@ package analytics
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"log"
+	"math/rand/v2"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/hyperloglog"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// eventsTableMigration creates the events table partitioned by month, converting an unpartitioned events table
+const eventsTableMigration = `
+DO $$
+DECLARE
+	unpartitioned boolean := to_regclass('events') IS NOT NULL
+		AND (SELECT relkind FROM pg_class WHERE oid = to_regclass('events')) = 'r';
+BEGIN
+	IF unpartitioned THEN
+		ALTER TABLE events ADD COLUMN IF NOT EXISTS tweet_ids jsonb, ADD COLUMN IF NOT EXISTS hashtags jsonb;
+		ALTER TABLE events RENAME TO events_unpartitioned;
+		ALTER INDEX events_pkey RENAME TO events_unpartitioned_pkey;
+		DROP INDEX IF EXISTS idx_events_user_id, idx_events_timestamp, idx_events_event_type, idx_events_tweet_id;
+	END IF;
+
+	CREATE TABLE IF NOT EXISTS events (
+		id varchar(64) NOT NULL,
+		event_type varchar(64) NOT NULL,
+		user_id uuid NOT NULL,
+		handler varchar(64) NOT NULL,
+		target_user_id uuid,
+		target_handler varchar(64),
+		tweet_id varchar(64),
+		tweet_ids jsonb,
+		hashtags jsonb,
+		mentions jsonb,
+		content_hash varchar(64),
+		timestamp timestamptz NOT NULL,
+		PRIMARY KEY (id, timestamp)
+	) PARTITION BY RANGE (timestamp);
+	CREATE TABLE IF NOT EXISTS events_default PARTITION OF events DEFAULT;
+	ALTER TABLE events ADD COLUMN IF NOT EXISTS target_handler varchar(64),
+		ADD COLUMN IF NOT EXISTS mentions jsonb,
+		ADD COLUMN IF NOT EXISTS content_hash varchar(64);
+
+	IF unpartitioned THEN
+		INSERT INTO events (id, event_type, user_id, handler, target_user_id, target_handler, tweet_id, tweet_ids, hashtags, timestamp)
+		SELECT id, event_type, user_id, handler, target_user_id, target_handler, tweet_id, tweet_ids, hashtags, timestamp
+		FROM events_unpartitioned;
+		DROP TABLE events_unpartitioned;
+	END IF;
+END $$;
+`
+
+// tweetViewersShardMigration makes the existing unique viewers sketches the first shard of their tweet and day
+const tweetViewersShardMigration = `
+DO $$
+BEGIN
+	IF to_regclass('tweet_viewers') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns WHERE table_name = 'tweet_viewers' AND column_name = 'shard'
+	) THEN
+		ALTER TABLE tweet_viewers ADD COLUMN shard bigint NOT NULL DEFAULT 0;
+		ALTER TABLE tweet_viewers DROP CONSTRAINT tweet_viewers_pkey;
+		ALTER TABLE tweet_viewers ADD PRIMARY KEY (tweet_id, day, shard);
+	END IF;
+END $$;
+`
+
+// userIDMigration references the users of the tables created before they had an ID by their database.LegacyUserID
+const userIDMigration = `
+DO $$
+BEGIN
+	IF to_regclass('user_analytics') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'user_analytics' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE user_analytics ADD COLUMN user_id uuid;
+		UPDATE user_analytics SET user_id = ` + database.LegacyUserID + `;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM user_analytics ON CONFLICT DO NOTHING;
+		ALTER TABLE user_analytics DROP CONSTRAINT IF EXISTS user_analytics_pkey;
+		ALTER TABLE user_analytics ALTER COLUMN user_id SET NOT NULL;
+		ALTER TABLE user_analytics ADD PRIMARY KEY (user_id);
+	END IF;
+
+	IF to_regclass('events') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'events' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE events ADD COLUMN user_id uuid, ADD COLUMN target_user_id uuid,
+			ADD COLUMN IF NOT EXISTS target_handler varchar(64);
+		UPDATE events SET user_id = ` + database.LegacyUserID + `;
+		UPDATE events SET target_user_id = targets.user_id
+		FROM (
+			SELECT handler, ` + database.LegacyUserID + ` AS user_id
+			FROM (SELECT DISTINCT target_handler AS handler FROM events WHERE target_handler IS NOT NULL) AS handlers
+		) AS targets
+		WHERE events.target_handler = targets.handler;
+		ALTER TABLE events ALTER COLUMN user_id SET NOT NULL;
+		DROP INDEX IF EXISTS idx_events_handler;
+	END IF;
+
+	IF to_regclass('tweet_engagements') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'tweet_engagements' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE tweet_engagements ADD COLUMN user_id uuid;
+		UPDATE tweet_engagements SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE tweet_engagements ALTER COLUMN user_id SET NOT NULL;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM tweet_engagements ON CONFLICT DO NOTHING;
+	END IF;
+
+	IF to_regclass('daily_user_activity') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'daily_user_activity' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE daily_user_activity ADD COLUMN user_id uuid;
+		UPDATE daily_user_activity SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE daily_user_activity DROP CONSTRAINT IF EXISTS daily_user_activity_pkey;
+		ALTER TABLE daily_user_activity DROP COLUMN handler;
+		ALTER TABLE daily_user_activity ALTER COLUMN user_id SET NOT NULL;
+		ALTER TABLE daily_user_activity ADD PRIMARY KEY (day, user_id);
+	END IF;
+
+	IF to_regclass('archived_user_counters') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'archived_user_counters' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE archived_user_counters ADD COLUMN user_id uuid;
+		UPDATE archived_user_counters SET user_id = ` + database.LegacyUserID + `;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM archived_user_counters ON CONFLICT DO NOTHING;
+		ALTER TABLE archived_user_counters DROP CONSTRAINT IF EXISTS archived_user_counters_pkey;
+		ALTER TABLE archived_user_counters DROP COLUMN handler;
+		ALTER TABLE archived_user_counters ALTER COLUMN user_id SET NOT NULL;
+		ALTER TABLE archived_user_counters ADD PRIMARY KEY (user_id);
+	END IF;
+
+	IF to_regclass('user_flags') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'user_flags' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE user_flags ADD COLUMN user_id uuid;
+		UPDATE user_flags SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE user_flags ALTER COLUMN user_id SET NOT NULL;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM user_flags ON CONFLICT DO NOTHING;
+		DROP INDEX IF EXISTS idx_user_flags_open;
+	END IF;
+END $$;
+`
+
 // PostgresAnalyticsRepository is a PostgreSQL implementation of the Repository interface
 type PostgresAnalyticsRepository struct {
 	db database.DBClient
@@ -19,18 +169,67 @@ type PostgresAnalyticsRepository struct {
 
 // NewPostgresAnalyticsRepository creates a new PostgreSQL analytics repository
 func NewPostgresAnalyticsRepository(db database.DBClient) *PostgresAnalyticsRepository {
+	// The existing data references the users by ID before the schemas are migrated, which AutoMigrate cannot add to a
+	// primary key
+	if err := db.AutoMigrate(&KnownUser{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate KnownUser table: %v", err))
+	}
+	if err := db.WithContext(context.Background()).Exec(userIDMigration).Error; err != nil {
+		panic(fmt.Sprintf("failed to migrate the users to IDs: %v", err))
+	}
+
 	// Auto migrate the schema one by one
 	if err := db.AutoMigrate(&UserAnalytics{}); err != nil {
 		panic(fmt.Sprintf("failed to migrate UserAnalytics table: %v", err))
 	}
-	if err := db.AutoMigrate(&Event{}); err != nil {
+	// The events table is partitioned by month, which AutoMigrate does not support
+	if err := db.WithContext(context.Background()).Exec(eventsTableMigration).Error; err != nil {
 		panic(fmt.Sprintf("failed to migrate Event table: %v", err))
 	}
+	if err := db.AutoMigrate(&TweetEngagement{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate TweetEngagement table: %v", err))
+	}
+	if err := db.AutoMigrate(&HashtagBucket{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate HashtagBucket table: %v", err))
+	}
+	if err := db.AutoMigrate(&DailyUserActivity{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate DailyUserActivity table: %v", err))
+	}
+	if err := db.AutoMigrate(&DailyMetric{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate DailyMetric table: %v", err))
+	}
+	if err := db.AutoMigrate(&TweetDailyStats{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate TweetDailyStats table: %v", err))
+	}
+	if err := db.WithContext(context.Background()).Exec(tweetViewersShardMigration).Error; err != nil {
+		panic(fmt.Sprintf("failed to migrate TweetDailyViewers shards: %v", err))
+	}
+	if err := db.AutoMigrate(&TweetDailyViewers{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate TweetDailyViewers table: %v", err))
+	}
+	if err := db.AutoMigrate(&DeadLetter{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate DeadLetter table: %v", err))
+	}
+	if err := db.AutoMigrate(&ReplayCheckpoint{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate ReplayCheckpoint table: %v", err))
+	}
+	if err := db.AutoMigrate(&UserFlag{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate UserFlag table: %v", err))
+	}
+	if err := db.AutoMigrate(&ArchivedUserCounters{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate ArchivedUserCounters table: %v", err))
+	}
+	if err := db.AutoMigrate(&DroppedEventMonth{}); err != nil {
+		panic(fmt.Sprintf("failed to migrate DroppedEventMonth table: %v", err))
+	}
 
 	// Create indexes if they don't exist
 	if err := db.WithContext(context.Background()).Exec(`
-		CREATE INDEX IF NOT EXISTS idx_events_handler ON events(handler);
+		CREATE INDEX IF NOT EXISTS idx_events_user_id ON events(user_id);
 		CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events(timestamp);
+		CREATE INDEX IF NOT EXISTS idx_events_event_type ON events(event_type);
+		CREATE INDEX IF NOT EXISTS idx_events_tweet_id ON events(tweet_id);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_user_flags_open ON user_flags(user_id, rule) WHERE status = 'open';
 	`).Error; err != nil {
 		panic(fmt.Sprintf("failed to create database indexes: %v", err))
 	}
@@ -62,10 +261,16 @@ func NewPostgresAnalyticsRepository(db database.DBClient) *PostgresAnalyticsRepo
 
 	// Save or update mock users in the database
 	ctx := context.Background()
+	repo := &PostgresAnalyticsRepository{db: db}
 	for _, user := range mockUsers {
+		user.UserID = database.LegacyUserIDOf(user.Handler)
+		if err := repo.SaveKnownUser(ctx, user.UserID, user.Handler); err != nil {
+			log.Printf("failed to save mock known user %s: %v", user.Handler, err)
+		}
+
 		// Try to find existing user
 		var existing UserAnalytics
-		err := db.First(ctx, &existing, "handler = ?", user.Handler)
+		err := db.First(ctx, &existing, "user_id = ?", user.UserID)
 
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -97,25 +302,60 @@ func NewPostgresAnalyticsRepository(db database.DBClient) *PostgresAnalyticsRepo
 		log.Printf("mock user: %s", user.Handler)
 	}
 
-	return &PostgresAnalyticsRepository{db: db}
+	return repo
 }
 
 // GetUserAnalytics retrieves analytics for a specific user
 func (r *PostgresAnalyticsRepository) GetUserAnalytics(ctx context.Context, userID string) (*UserAnalytics, error) {
 	var analytics UserAnalytics
-	if err := r.db.WithContext(ctx).Where("handler = ?", userID).First(&analytics).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&analytics).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user analytics not found")
+			return nil, ErrUserAnalyticsNotFound
 		}
 		return nil, fmt.Errorf("failed to get user analytics: %w", err)
 	}
 	return &analytics, nil
 }
 
-// GetAllUserAnalytics retrieves analytics for all users
-func (r *PostgresAnalyticsRepository) GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error) {
+// GetAllUserAnalytics retrieves the analytics of the users that pass the filter, sorted and paginated by it
+func (r *PostgresAnalyticsRepository) GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter) ([]*UserAnalytics, error) {
+	query := r.db.WithContext(ctx)
+	if filter.IsInfluencer != nil {
+		query = query.Where("is_influencer = ?", *filter.IsInfluencer)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+	if !filter.UpdatedSince.IsZero() {
+		query = query.Where("updated_at >= ?", filter.UpdatedSince)
+	}
+	if !filter.UpdatedUntil.IsZero() {
+		query = query.Where("updated_at < ?", filter.UpdatedUntil)
+	}
+
+	// Sort by the field and then by handler, in the same direction, starting after the cursor
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+	switch filter.SortBy {
+	case SortByCreatedAt, SortByUpdatedAt:
+		if filter.After != nil {
+			query = query.Where(fmt.Sprintf("(%s, handler) %s (?, ?)", filter.SortBy, comparison), filter.After.Time, filter.After.Handler)
+		}
+		query = query.Order(fmt.Sprintf("%s %s, handler %s", filter.SortBy, direction, direction))
+	default:
+		if filter.After != nil {
+			query = query.Where(fmt.Sprintf("handler %s ?", comparison), filter.After.Handler)
+		}
+		query = query.Order("handler " + direction)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
 	var analytics []*UserAnalytics
-	if err := r.db.WithContext(ctx).Find(&analytics).Error; err != nil {
+	if err := query.Find(&analytics).Error; err != nil {
 		return nil, fmt.Errorf("failed to get all user analytics: %w", err)
 	}
 	return analytics, nil
@@ -123,38 +363,943 @@ func (r *PostgresAnalyticsRepository) GetAllUserAnalytics(ctx context.Context) (
 
 // DeleteUserAnalytics deletes analytics data for a specific user
 func (r *PostgresAnalyticsRepository) DeleteUserAnalytics(ctx context.Context, userID string) error {
-	if err := r.db.WithContext(ctx).Where("handler = ?", userID).Delete(&UserAnalytics{}).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []any{&UserAnalytics{}, &ArchivedUserCounters{}} {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", userID).Delete(&KnownUser{}).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete user analytics: %w", err)
 	}
 	return nil
 }
 
-// ProcessEvent processes an analytics event
-func (r *PostgresAnalyticsRepository) ProcessEvent(ctx context.Context, event *Event) error {
-	// Set timestamp if not set
-	if event.Timestamp.IsZero() {
-		event.Timestamp = time.Now()
+// GetKnownUserID retrieves the ID of the user known by a handler, "" if there is none
+func (r *PostgresAnalyticsRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	var known KnownUser
+	if err := r.db.WithContext(ctx).Where("handler = ?", handler).Order("updated_at DESC").First(&known).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get known user: %w", err)
+	}
+	return known.ID, nil
+}
+
+// SaveKnownUser records the current handler of a user
+func (r *PostgresAnalyticsRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveKnownUser(tx, userID, handler)
+	}); err != nil {
+		return fmt.Errorf("failed to save known user: %w", err)
+	}
+	return nil
+}
+
+// saveKnownUser records the current handler of a user, which is no longer the one of the other users known by it
+func saveKnownUser(tx *gorm.DB, userID, handler string) error {
+	if err := tx.Where("handler = ? AND id <> ?", handler, userID).Delete(&KnownUser{}).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"handler", "updated_at"}),
+	}).Create(&KnownUser{ID: userID, Handler: handler}).Error
+}
+
+// RenameUser makes a user known by their new handler unless a later change was applied, and shows their analytics with it
+func (r *PostgresAnalyticsRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var known KnownUser
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&known, "id = ?", userID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		handler := known.Handler
+		if err != nil || known.Handler == oldHandler {
+			handler = newHandler
+			if err := saveKnownUser(tx, userID, handler); err != nil {
+				return err
+			}
+		}
+
+		// UpdateColumn not to show the analytics as updated
+		for _, model := range []any{&UserAnalytics{}, &TweetEngagement{}, &UserFlag{}} {
+			if err := tx.Model(model).Where("user_id = ? AND handler <> ?", userID, handler).UpdateColumn("handler", handler).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename user: %w", err)
 	}
+	return nil
+}
 
+// ProcessEvent processes an analytics event, events whose ID was already processed are skipped
+func (r *PostgresAnalyticsRepository) ProcessEvent(ctx context.Context, event *Event) error {
 	// Start a transaction
 	tx := r.db.WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return fmt.Errorf("failed to start transaction: %w", tx.Error)
 	}
 
-	// Save the event
-	if err := tx.WithContext(ctx).Create(event).Error; err != nil {
+	// Hold the replay lock shared until the transaction ends, so a replay waits for the event,
+	// and refuse the event while a replay holds the lock or was interrupted
+	var replaying bool
+	if err := tx.Raw(`SELECT NOT pg_try_advisory_xact_lock_shared(?) OR EXISTS (SELECT 1 FROM replay_checkpoints)`, replayLockKey).
+		Scan(&replaying).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to check events replay: %w", err)
+	}
+	if replaying {
+		tx.Rollback()
+		return ErrReplayInProgress
+	}
+
+	// Refuse the events of the dropped months, the archived counters already count them
+	var dropped bool
+	if err := tx.Raw(`SELECT EXISTS (SELECT 1 FROM dropped_event_months WHERE month = ?)`, truncateMonth(event.Timestamp)).
+		Scan(&dropped).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to check dropped event months: %w", err)
+	}
+	if dropped {
+		tx.Rollback()
+		return ErrEventExpired
+	}
+
+	// Save the event, the primary key on its ID and timestamp skips the events already processed
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to save event: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Already processed, e.g. a redelivered message
+		tx.Rollback()
+		return nil
+	}
+
+	if err := r.applyEvent(tx, event); err != nil {
 		tx.Rollback()
-		return fmt.Errorf("failed to save event: %w", err)
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// knownHandler is the current handler of the known user with an ID, or the handler of the event, given in this order
+const knownHandler = `COALESCE((SELECT handler FROM known_users WHERE id = ?), ?)`
+
+// applyEvent updates the state derived from an event within the transaction
+func (r *PostgresAnalyticsRepository) applyEvent(tx *gorm.DB, event *Event) error {
+	// Update the user analytics of the user of the event, tweets and timeline views mark the user as active
+	active := event.EventType == events.TypeTweetCreated || event.EventType == events.TypeTimelineViewed
+	var tweets int64
+	if event.EventType == events.TypeTweetCreated {
+		tweets = 1
+	}
+	var lastTimelineView *time.Time
+	if event.EventType == events.TypeTimelineViewed {
+		lastTimelineView = &event.Timestamp
+	}
+	if err := tx.Exec(`
+		INSERT INTO user_analytics (user_id, handler, is_influencer, is_active, tweet_count, last_activity_at, last_timeline_view_at, created_at, updated_at)
+		VALUES (?, `+knownHandler+`, false, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET is_influencer = user_analytics.is_influencer OR user_analytics.tweet_count + EXCLUDED.tweet_count > ?,
+			is_active = user_analytics.is_active OR EXCLUDED.is_active,
+			tweet_count = user_analytics.tweet_count + EXCLUDED.tweet_count,
+			last_activity_at = GREATEST(user_analytics.last_activity_at, EXCLUDED.last_activity_at),
+			last_timeline_view_at = GREATEST(user_analytics.last_timeline_view_at, EXCLUDED.last_timeline_view_at),
+			updated_at = GREATEST(user_analytics.updated_at, EXCLUDED.updated_at)
+	`, event.UserID, event.UserID, event.Handler, active, tweets, event.Timestamp, lastTimelineView, event.Timestamp, event.Timestamp,
+		influencerTweetCount).Error; err != nil {
+		return fmt.Errorf("failed to update user analytics: %w", err)
 	}
 
-	// Update user analytics based on event type
+	// Count the followers of the followed user, which may not have any activity yet
+	if event.EventType == events.TypeUserFollowed || event.EventType == events.TypeUserUnfollowed {
+		delta := 1
+		if event.EventType == events.TypeUserUnfollowed {
+			delta = -1
+		}
+		if err := tx.Exec(`
+			INSERT INTO user_analytics (user_id, handler, is_influencer, is_active, follower_count, created_at, updated_at)
+			VALUES (?, `+knownHandler+`, false, false, GREATEST(?, 0), ?, ?)
+			ON CONFLICT (user_id) DO UPDATE
+			SET follower_count = GREATEST(user_analytics.follower_count + ?, 0),
+				updated_at = GREATEST(user_analytics.updated_at, EXCLUDED.updated_at)
+		`, event.TargetUserID, event.TargetUserID, event.TargetHandler, delta, event.Timestamp, event.Timestamp, delta).Error; err != nil {
+			return fmt.Errorf("failed to update user analytics: %w", err)
+		}
+	}
+
+	// Update tweet engagement based on event type
+	var err error
 	switch event.EventType {
-	case "tweet_created":
-		return tx.Commit().Error
-	case "timeline_viewed":
-		return tx.Commit().Error
-	default:
-		return tx.Commit().Error
+	case events.TypeTweetCreated:
+		if event.TweetID != "" {
+			err = tx.Exec(`
+				INSERT INTO tweet_engagements (tweet_id, user_id, handler, likes, replies, created_at, updated_at)
+				VALUES (?, ?, `+knownHandler+`, 0, 0, ?, ?)
+				ON CONFLICT (tweet_id) DO NOTHING
+			`, event.TweetID, event.UserID, event.UserID, event.Handler, event.Timestamp, time.Now()).Error
+		}
+	case events.TypeTweetLiked:
+		err = tx.Exec(`
+			UPDATE tweet_engagements SET likes = likes + 1, updated_at = ? WHERE tweet_id = ?
+		`, time.Now(), event.TweetID).Error
+	case events.TypeTweetReplied:
+		err = tx.Exec(`
+			UPDATE tweet_engagements SET replies = replies + 1, updated_at = ? WHERE tweet_id = ?
+		`, time.Now(), event.TweetID).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update tweet engagement: %w", err)
+	}
+
+	// Add the engagement to the score of the author of the tweet, only known for the tweets created while tracking
+	if event.EventType == events.TypeTweetLiked || event.EventType == events.TypeTweetReplied {
+		points := likeWeight
+		if event.EventType == events.TypeTweetReplied {
+			points = replyWeight
+		}
+		if err := tx.Exec(`
+			UPDATE user_analytics SET engagement_score = engagement_score + ?, updated_at = GREATEST(updated_at, ?)
+			WHERE user_id = (SELECT user_id FROM tweet_engagements WHERE tweet_id = ?)
+		`, points, event.Timestamp, event.TweetID).Error; err != nil {
+			return fmt.Errorf("failed to update user analytics: %w", err)
+		}
+	}
+
+	// Update the daily tweet stats based on event type
+	if err := r.updateTweetStats(tx, event); err != nil {
+		return fmt.Errorf("failed to update tweet stats: %w", err)
+	}
+
+	// Count the hashtags of the tweet in its bucket
+	if event.EventType == events.TypeTweetCreated {
+		bucketStart := event.Timestamp.Truncate(HashtagBucketSize)
+		for _, tag := range normalizeHashtags(event.Hashtags) {
+			if err := tx.Exec(`
+				INSERT INTO hashtag_buckets (tag, bucket_start, count)
+				VALUES (?, ?, 1)
+				ON CONFLICT (tag, bucket_start) DO UPDATE
+				SET count = hashtag_buckets.count + 1
+			`, tag, bucketStart).Error; err != nil {
+				return fmt.Errorf("failed to update hashtag buckets: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetTweetEngagements retrieves the engagement of the tweets created since the given time
+func (r *PostgresAnalyticsRepository) GetTweetEngagements(ctx context.Context, since time.Time) ([]*TweetEngagement, error) {
+	var engagements []*TweetEngagement
+	if err := r.db.WithContext(ctx).Where("created_at >= ?", since).Find(&engagements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tweet engagements: %w", err)
+	}
+	return engagements, nil
+}
+
+// updateTweetStats updates random shards of the daily stats of the tweets of an event, locking the tweets in ID order
+func (r *PostgresAnalyticsRepository) updateTweetStats(tx *gorm.DB, event *Event) error {
+	day := truncateDay(event.Timestamp).Format(time.DateOnly)
+	increment := func(tweetID, counter string) error {
+		return tx.Exec(fmt.Sprintf(`
+			INSERT INTO tweet_stats (tweet_id, day, shard, %[1]s)
+			VALUES (?, ?::date, ?, 1)
+			ON CONFLICT (tweet_id, day, shard) DO UPDATE
+			SET %[1]s = tweet_stats.%[1]s + 1
+		`, counter), tweetID, day, rand.IntN(tweetStatsShards)).Error
+	}
+
+	switch event.EventType {
+	case events.TypeTimelineViewed:
+		tweetIDs := uniqueTweetIDs(event.TweetIDs)
+		slices.Sort(tweetIDs)
+		for _, tweetID := range tweetIDs {
+			if err := increment(tweetID, "impressions"); err != nil {
+				return err
+			}
+			if err := r.addTweetViewer(tx, tweetID, day, event.UserID); err != nil {
+				return err
+			}
+		}
+	case events.TypeProfileViewed:
+		// Only profile views coming from a tweet are counted
+		if event.TweetID != "" {
+			return increment(event.TweetID, "profile_views")
+		}
+	case events.TypeTweetLiked:
+		return increment(event.TweetID, "likes")
+	case events.TypeTweetReplied:
+		return increment(event.TweetID, "replies")
+	}
+	return nil
+}
+
+// addTweetViewer adds a viewer to a random shard of the unique viewers sketch of a tweet during a day
+func (r *PostgresAnalyticsRepository) addTweetViewer(tx *gorm.DB, tweetID, day, viewer string) error {
+	empty, err := hyperloglog.New(viewersSketchPrecision).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	shard := rand.IntN(tweetStatsShards)
+	if err := tx.Exec(`
+		INSERT INTO tweet_viewers (tweet_id, day, shard, sketch) VALUES (?, ?::date, ?, ?)
+		ON CONFLICT (tweet_id, day, shard) DO NOTHING
+	`, tweetID, day, shard, empty).Error; err != nil {
+		return err
+	}
+
+	// Lock the shard until the transaction ends so concurrent updates are not lost
+	var data []byte
+	if err := tx.Raw(`
+		SELECT sketch FROM tweet_viewers WHERE tweet_id = ? AND day = ?::date AND shard = ? FOR UPDATE
+	`, tweetID, day, shard).Row().Scan(&data); err != nil {
+		return err
+	}
+	var sketch hyperloglog.Sketch
+	if err := sketch.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	sketch.Add(viewer)
+	if data, err = sketch.MarshalBinary(); err != nil {
+		return err
+	}
+
+	return tx.Exec(`
+		UPDATE tweet_viewers SET sketch = ? WHERE tweet_id = ? AND day = ?::date AND shard = ?
+	`, data, tweetID, day, shard).Error
+}
+
+// GetTweetEngagement retrieves the engagement of a tweet
+func (r *PostgresAnalyticsRepository) GetTweetEngagement(ctx context.Context, tweetID string) (*TweetEngagement, error) {
+	var engagement TweetEngagement
+	if err := r.db.WithContext(ctx).Where("tweet_id = ?", tweetID).First(&engagement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTweetNotFound
+		}
+		return nil, fmt.Errorf("failed to get tweet engagement: %w", err)
+	}
+	return &engagement, nil
+}
+
+// GetUserTweetEngagements retrieves the engagement of the tweets of a user, newest first
+func (r *PostgresAnalyticsRepository) GetUserTweetEngagements(ctx context.Context, userID string, limit, offset int) ([]*TweetEngagement, error) {
+	var engagements []*TweetEngagement
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&engagements).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user tweet engagements: %w", err)
+	}
+	return engagements, nil
+}
+
+// GetTweetDailyStats retrieves the daily stats of the tweets since the day of from, merging their shards
+func (r *PostgresAnalyticsRepository) GetTweetDailyStats(ctx context.Context, tweetIDs []string, from time.Time) ([]*TweetDailyStats, error) {
+	var rows []struct {
+		TweetID      string
+		Day          time.Time
+		Impressions  int64
+		ProfileViews int64
+		Likes        int64
+		Replies      int64
+	}
+	since := truncateDay(from).Format(time.DateOnly)
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT tweet_id, day,
+		       SUM(impressions) AS impressions,
+		       SUM(profile_views) AS profile_views,
+		       SUM(likes) AS likes,
+		       SUM(replies) AS replies
+		FROM tweet_stats
+		WHERE tweet_id IN ? AND day >= ?::date
+		GROUP BY tweet_id, day
+		ORDER BY tweet_id, day
+	`, tweetIDs, since).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tweet stats: %w", err)
+	}
+
+	var shards []*TweetDailyViewers
+	if err := r.db.WithContext(ctx).
+		Where("tweet_id IN ? AND day >= ?::date", tweetIDs, since).
+		Find(&shards).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tweet viewers: %w", err)
+	}
+	viewers, err := mergeViewersShards(shards)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge tweet viewers: %w", err)
+	}
+
+	stats := make([]*TweetDailyStats, 0, len(rows))
+	for _, row := range rows {
+		day := row.Day.UTC()
+		stats = append(stats, &TweetDailyStats{
+			TweetID:      row.TweetID,
+			Day:          day,
+			Impressions:  row.Impressions,
+			ProfileViews: row.ProfileViews,
+			Likes:        row.Likes,
+			Replies:      row.Replies,
+			Viewers:      viewers[tweetDayKey{tweetID: row.TweetID, day: truncateDay(day).Unix()}],
+		})
+	}
+	return stats, nil
+}
+
+// GetHashtagCounts retrieves the uses of each hashtag since windowStart and between baselineStart and windowStart
+func (r *PostgresAnalyticsRepository) GetHashtagCounts(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error) {
+	var counts []*HashtagCount
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT tag,
+		       COALESCE(SUM(count) FILTER (WHERE bucket_start >= ?), 0) AS window_count,
+		       COALESCE(SUM(count) FILTER (WHERE bucket_start < ?), 0) AS baseline_count
+		FROM hashtag_buckets
+		WHERE bucket_start >= ?
+		GROUP BY tag
+		HAVING SUM(count) FILTER (WHERE bucket_start >= ?) > 0
+	`, windowStart, windowStart, baselineStart, windowStart).Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hashtag counts: %w", err)
+	}
+	return counts, nil
+}
+
+// DeleteHashtagBucketsBefore deletes the hashtag buckets that started before the given time
+func (r *PostgresAnalyticsRepository) DeleteHashtagBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("bucket_start < ?", before).Delete(&HashtagBucket{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete hashtag buckets: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetFirstEventTime retrieves the timestamp of the oldest event, or the zero time if there are no events
+func (r *PostgresAnalyticsRepository) GetFirstEventTime(ctx context.Context) (time.Time, error) {
+	var first sql.NullTime
+	if err := r.db.WithContext(ctx).Raw(`SELECT MIN(timestamp) FROM events`).Row().Scan(&first); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get first event time: %w", err)
+	}
+	return first.Time, nil
+}
+
+// GetLastAggregatedDay retrieves the last day with aggregated metrics, or the zero time if there are none
+func (r *PostgresAnalyticsRepository) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	var last sql.NullTime
+	if err := r.db.WithContext(ctx).Raw(`SELECT MAX(day) FROM daily_metrics`).Row().Scan(&last); err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last aggregated day: %w", err)
+	}
+	return last.Time, nil
+}
+
+// AggregateDailyMetrics recomputes the daily user activity and the daily metrics of the days in [from, to)
+func (r *PostgresAnalyticsRepository) AggregateDailyMetrics(ctx context.Context, from, to time.Time) error {
+	tx := r.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to start transaction: %w", tx.Error)
+	}
+
+	// Recompute the daily user activity from the events
+	if err := tx.Exec(`
+		DELETE FROM daily_user_activity WHERE day >= ?::date AND day < ?::date
+	`, from.Format(time.DateOnly), to.Format(time.DateOnly)).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to reset daily user activity: %w", err)
+	}
+	if err := tx.Exec(`
+		INSERT INTO daily_user_activity (day, user_id, tweets, timeline_views)
+		SELECT (timestamp AT TIME ZONE 'UTC')::date,
+		       user_id,
+		       COUNT(*) FILTER (WHERE event_type = ?),
+		       COUNT(*) FILTER (WHERE event_type = ?)
+		FROM events
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY 1, 2
+	`, events.TypeTweetCreated, events.TypeTimelineViewed, from, to).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to aggregate daily user activity: %w", err)
+	}
+
+	// Recompute the daily metrics from the daily user activity
+	if err := tx.Exec(`
+		WITH days AS (
+			SELECT day::date AS day FROM generate_series(?::date, ?::date - 1, interval '1 day') AS day
+		)
+		INSERT INTO daily_metrics (day, metric, value)
+		SELECT days.day, metrics.metric, metrics.value
+		FROM days
+		CROSS JOIN LATERAL (VALUES
+			(?, (SELECT COUNT(*) FROM daily_user_activity a WHERE a.day = days.day)),
+			(?, (SELECT COUNT(DISTINCT user_id) FROM daily_user_activity a WHERE a.day > days.day - 7 AND a.day <= days.day)),
+			(?, (SELECT COUNT(DISTINCT user_id) FROM daily_user_activity a WHERE a.day > days.day - 30 AND a.day <= days.day)),
+			(?, (SELECT COALESCE(SUM(tweets), 0) FROM daily_user_activity a WHERE a.day = days.day)),
+			(?, (SELECT COALESCE(SUM(timeline_views), 0) FROM daily_user_activity a WHERE a.day = days.day))
+		) AS metrics(metric, value)
+		ON CONFLICT (day, metric) DO UPDATE SET value = EXCLUDED.value
+	`, from.Format(time.DateOnly), to.Format(time.DateOnly), MetricDAU, MetricWAU, MetricMAU, MetricTweets, MetricTimelineViews).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to aggregate daily metrics: %w", err)
+	}
+
+	return tx.Commit().Error
+}
+
+// GetDailyMetrics retrieves the daily values of a metric between from and to, both included, ordered by day
+func (r *PostgresAnalyticsRepository) GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error) {
+	var metrics []*DailyMetric
+	if err := r.db.WithContext(ctx).
+		Where("metric = ? AND day >= ?::date AND day <= ?::date", metric, from.Format(time.DateOnly), to.Format(time.DateOnly)).
+		Order("day").
+		Find(&metrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to get daily metrics: %w", err)
+	}
+	return metrics, nil
+}
+
+// DeactivateIdleUsers marks as inactive every active user with no activity since idleSince
+func (r *PostgresAnalyticsRepository) DeactivateIdleUsers(ctx context.Context, idleSince time.Time) ([]*UserAnalytics, error) {
+	var deactivated []*UserAnalytics
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE user_analytics ua
+		SET is_active = false, updated_at = ?
+		WHERE ua.is_active
+		  AND ua.updated_at < ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM events e WHERE e.user_id = ua.user_id AND e.timestamp >= ?
+		  )
+		RETURNING ua.*
+	`, time.Now(), idleSince, idleSince).Scan(&deactivated).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate idle users: %w", err)
+	}
+	return deactivated, nil
+}
+
+// SaveDeadLetter creates or updates a dead letter
+func (r *PostgresAnalyticsRepository) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	if err := r.db.WithContext(ctx).Save(deadLetter).Error; err != nil {
+		return fmt.Errorf("failed to save dead letter: %w", err)
+	}
+	return nil
+}
+
+// GetDeadLetter retrieves a dead letter
+func (r *PostgresAnalyticsRepository) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	var deadLetter DeadLetter
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&deadLetter).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeadLetterNotFound
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return &deadLetter, nil
+}
+
+// GetDeadLetters retrieves the dead letters, newest first
+func (r *PostgresAnalyticsRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	var deadLetters []*DeadLetter
+	if err := r.db.WithContext(ctx).
+		Order("created_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&deadLetters).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return deadLetters, nil
+}
+
+// CountDeadLetters counts the dead letters
+func (r *PostgresAnalyticsRepository) CountDeadLetters(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&DeadLetter{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteDeadLetter deletes a dead letter
+func (r *PostgresAnalyticsRepository) DeleteDeadLetter(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&DeadLetter{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// replayCheckpointID identifies the checkpoint row, only one replay can be in progress
+const replayCheckpointID = "events"
+
+// replayLockKey is the key of the advisory lock held by the replay, and shared by the events being processed
+const replayLockKey = 0x616e616c79746963 // "analytic"
+
+// CountEvents counts the events up to until
+func (r *PostgresAnalyticsRepository) CountEvents(ctx context.Context, until time.Time) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Event{}).Where("timestamp <= ?", until).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count events: %w", err)
+	}
+	return count, nil
+}
+
+// GetEventsAfter retrieves the events after the given timestamp and event ID up to until, ordered by timestamp and ID
+func (r *PostgresAnalyticsRepository) GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error) {
+	var events []*Event
+	if err := r.db.WithContext(ctx).
+		Where("timestamp >= ? AND (timestamp, id) > (?, ?) AND timestamp <= ?", timestamp, timestamp, eventID, until).
+		Order("timestamp, id").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	return events, nil
+}
+
+// ResetDerivedState deletes the state derived from the events, starting over from the archived counters
+func (r *PostgresAnalyticsRepository) ResetDerivedState(ctx context.Context) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			TRUNCATE user_analytics, tweet_engagements, hashtag_buckets, daily_user_activity, daily_metrics, tweet_stats, tweet_viewers
+		`).Error; err != nil {
+			return err
+		}
+
+		// Start from the counters of the events already dropped
+		return tx.Exec(`
+			INSERT INTO user_analytics (user_id, handler, is_influencer, is_active, tweet_count, follower_count, created_at, updated_at)
+			SELECT a.user_id, k.handler, a.tweet_count > ?, false, a.tweet_count, GREATEST(a.follower_count, 0), a.created_at, a.created_at
+			FROM archived_user_counters a JOIN known_users k ON k.id = a.user_id
+		`, influencerTweetCount).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset derived state: %w", err)
+	}
+	return nil
+}
+
+// ReplayEvents updates the state derived from the events and saves the replay checkpoint in the same transaction
+func (r *PostgresAnalyticsRepository) ReplayEvents(ctx context.Context, events []*Event, checkpoint *ReplayCheckpoint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			if err := r.applyEvent(tx, event); err != nil {
+				return err
+			}
+		}
+
+		checkpoint.ID = replayCheckpointID
+		if err := tx.Save(checkpoint).Error; err != nil {
+			return fmt.Errorf("failed to save replay checkpoint: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetReplayCheckpoint retrieves the checkpoint of the replay in progress, or nil if there is none
+func (r *PostgresAnalyticsRepository) GetReplayCheckpoint(ctx context.Context) (*ReplayCheckpoint, error) {
+	var checkpoint ReplayCheckpoint
+	if err := r.db.WithContext(ctx).Where("id = ?", replayCheckpointID).First(&checkpoint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get replay checkpoint: %w", err)
+	}
+	return &checkpoint, nil
+}
+
+// DeleteReplayCheckpoint deletes the checkpoint of the replay in progress
+func (r *PostgresAnalyticsRepository) DeleteReplayCheckpoint(ctx context.Context) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", replayCheckpointID).Delete(&ReplayCheckpoint{}).Error; err != nil {
+		return fmt.Errorf("failed to delete replay checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LockReplay takes the replay advisory lock on a connection of its own, refusing new events until it is unlocked
+func (r *PostgresAnalyticsRepository) LockReplay(ctx context.Context) (func(), error) {
+	sqlDB, err := r.db.WithContext(ctx).DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get replay lock connection: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, replayLockKey); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock events replay: %w", err)
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, replayLockKey); err != nil {
+			// Discard the connection instead of returning it to the pool, closing it releases the lock
+			log.Printf("failed to unlock events replay: %v", err)
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, nil
+}
+
+// eventPartitionName returns the name of the partition of the events of a month
+func eventPartitionName(month time.Time) string {
+	return month.Format("events_2006_01")
+}
+
+// getEventPartitions retrieves the months with a partition of the events
+func (r *PostgresAnalyticsRepository) getEventPartitions(tx *gorm.DB) ([]time.Time, error) {
+	rows, err := tx.Raw(`
+		SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = 'events'::regclass
+	`).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get event partitions: %w", err)
+	}
+	defer rows.Close()
+
+	months := []time.Time{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to get event partitions: %w", err)
+		}
+		// The default partition has no month
+		if month, err := time.Parse("events_2006_01", name); err == nil {
+			months = append(months, month)
+		}
+	}
+	return months, rows.Err()
+}
+
+// getDefaultPartitionMonths retrieves the months of the events stored in the default partition
+func (r *PostgresAnalyticsRepository) getDefaultPartitionMonths(tx *gorm.DB) ([]time.Time, error) {
+	rows, err := tx.Raw(`SELECT DISTINCT date_trunc('month', timestamp AT TIME ZONE 'UTC') FROM events_default`).Rows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default partition months: %w", err)
+	}
+	defer rows.Close()
+
+	months := []time.Time{}
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			return nil, fmt.Errorf("failed to get default partition months: %w", err)
+		}
+		months = append(months, truncateMonth(month))
+	}
+	return months, rows.Err()
+}
+
+// EnsureEventPartitions creates the missing partitions of the months between from and to and of the stored events
+func (r *PostgresAnalyticsRepository) EnsureEventPartitions(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	db := r.db.WithContext(ctx)
+
+	existing, err := r.getEventPartitions(db)
+	if err != nil {
+		return nil, err
+	}
+	defaultMonths, err := r.getDefaultPartitionMonths(db)
+	if err != nil {
+		return nil, err
+	}
+
+	partitioned := map[int64]time.Time{} // Unix seconds -> month
+	for _, month := range existing {
+		partitioned[month.Unix()] = month
+	}
+	missing := defaultMonths
+	for month := truncateMonth(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		missing = append(missing, month)
+	}
+
+	for _, month := range missing {
+		if _, exists := partitioned[month.Unix()]; exists {
+			continue
+		}
+
+		// The default partition must not contain events of the month when the partition is attached,
+		// so they are moved to the partition first
+		name := eventPartitionName(month)
+		start, end := month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339)
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(fmt.Sprintf(`CREATE TABLE %s (LIKE events INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, name)).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(fmt.Sprintf(`
+				WITH moved AS (
+					DELETE FROM events_default WHERE timestamp >= ? AND timestamp < ? RETURNING *
+				)
+				INSERT INTO %s SELECT * FROM moved
+			`, name), start, end).Error; err != nil {
+				return err
+			}
+			return tx.Exec(fmt.Sprintf(`ALTER TABLE events ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, name, start, end)).Error
+		}); err != nil {
+			return nil, fmt.Errorf("failed to create event partition %s: %w", name, err)
+		}
+		partitioned[month.Unix()] = month
+	}
+
+	months := make([]time.Time, 0, len(partitioned))
+	for _, month := range partitioned {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Before(months[j])
+	})
+	return months, nil
+}
+
+// DropEventPartition deletes the events partition of a month, adding the counters of its events to the archived ones
+func (r *PostgresAnalyticsRepository) DropEventPartition(ctx context.Context, month time.Time) error {
+	name := eventPartitionName(truncateMonth(month))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		if err := tx.Raw(`SELECT to_regclass(?) IS NOT NULL`, name).Scan(&exists).Error; err != nil {
+			return err
+		}
+		if !exists {
+			return nil
+		}
+
+		if err := tx.Exec(fmt.Sprintf(`
+			INSERT INTO archived_user_counters (user_id, tweet_count, follower_count, created_at)
+			SELECT user_id, SUM(tweets), SUM(followers), MIN(timestamp) FROM (
+				SELECT user_id, 1 AS tweets, 0 AS followers, timestamp FROM %[1]s WHERE event_type = ?
+				UNION ALL
+				SELECT target_user_id, 0, CASE WHEN event_type = ? THEN 1 ELSE -1 END, timestamp
+				FROM %[1]s WHERE event_type IN (?, ?)
+			) AS counters
+			GROUP BY user_id
+			ON CONFLICT (user_id) DO UPDATE
+			SET tweet_count = archived_user_counters.tweet_count + EXCLUDED.tweet_count,
+				follower_count = archived_user_counters.follower_count + EXCLUDED.follower_count,
+				created_at = LEAST(archived_user_counters.created_at, EXCLUDED.created_at)
+		`, name), events.TypeTweetCreated, events.TypeUserFollowed, events.TypeUserFollowed, events.TypeUserUnfollowed).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&DroppedEventMonth{Month: truncateMonth(month), DroppedAt: time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Exec(fmt.Sprintf(`DROP TABLE %s`, name)).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to drop event partition %s: %w", name, err)
+	}
+	return nil
+}
+
+// GetUserEvents retrieves the events of the given types of a user since the given time, ordered by timestamp and ID
+func (r *PostgresAnalyticsRepository) GetUserEvents(ctx context.Context, userID string, eventTypes []string, since time.Time) ([]*Event, error) {
+	var userEvents []*Event
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND event_type IN ? AND timestamp >= ?", userID, eventTypes, since).
+		Order("timestamp, id").
+		Find(&userEvents).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user events: %w", err)
+	}
+	return userEvents, nil
+}
+
+// FlagUser creates an open flag or counts another occurrence of it, and reports whether it was created
+func (r *PostgresAnalyticsRepository) FlagUser(ctx context.Context, flag *UserFlag) (bool, error) {
+	var stored struct {
+		UserFlag
+		Created bool
+	}
+	now := time.Now()
+	if err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO user_flags (id, user_id, handler, rule, reason, occurrences, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 1, ?, ?, ?)
+		ON CONFLICT (user_id, rule) WHERE status = 'open' DO UPDATE
+		SET reason = EXCLUDED.reason, occurrences = user_flags.occurrences + 1, updated_at = EXCLUDED.updated_at
+		RETURNING *, xmax = 0 AS created
+	`, flag.ID, flag.UserID, flag.Handler, flag.Rule, flag.Reason, UserFlagStatusOpen, now, now).Scan(&stored).Error; err != nil {
+		return false, fmt.Errorf("failed to flag user: %w", err)
+	}
+
+	*flag = stored.UserFlag
+	return stored.Created, nil
+}
+
+// GetUserFlag retrieves a user flag
+func (r *PostgresAnalyticsRepository) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	var flag UserFlag
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&flag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserFlagNotFound
+		}
+		return nil, fmt.Errorf("failed to get user flag: %w", err)
+	}
+	return &flag, nil
+}
+
+// GetUserFlags retrieves the user flags that pass the filter, newest first
+func (r *PostgresAnalyticsRepository) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	var flags []*UserFlag
+	if err := r.filterUserFlags(ctx, filter).
+		Order("created_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&flags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user flags: %w", err)
+	}
+	return flags, nil
+}
+
+// CountUserFlags counts the user flags that pass the filter
+func (r *PostgresAnalyticsRepository) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	var count int64
+	if err := r.filterUserFlags(ctx, filter).Model(&UserFlag{}).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count user flags: %w", err)
+	}
+	return count, nil
+}
+
+// filterUserFlags returns a query of the user flags that pass the filter
+func (r *PostgresAnalyticsRepository) filterUserFlags(ctx context.Context, filter UserFlagFilter) *gorm.DB {
+	query := r.db.WithContext(ctx)
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Rule != "" {
+		query = query.Where("rule = ?", filter.Rule)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	return query
+}
+
+// ResolveUserFlag sets the status of an open user flag
+func (r *PostgresAnalyticsRepository) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	var flags []*UserFlag
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE user_flags SET status = ?, updated_at = ? WHERE id = ? AND status = ? RETURNING *
+	`, status, time.Now(), id, UserFlagStatusOpen).Scan(&flags).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve user flag: %w", err)
+	}
+	if len(flags) == 0 {
+		// Tell a missing flag from a resolved one
+		if _, err := r.GetUserFlag(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrUserFlagResolved
 	}
+	return flags[0], nil
 }
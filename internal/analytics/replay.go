@@ -0,0 +1,128 @@
+package analytics
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+)
+
+// replayCheckpointAgeTimeout bounds how long reading the replay checkpoint age gauge waits for the repository
+const replayCheckpointAgeTimeout = 2 * time.Second
+
+// Replayer rebuilds the state derived from the events by replaying the stored events in timestamp order
+type Replayer struct {
+	repository ReplayRepository
+	batchSize  int
+}
+
+// NewReplayer creates a new events replayer that replays the events in batches of batchSize
+func NewReplayer(repository ReplayRepository, batchSize int) *Replayer {
+	return &Replayer{
+		repository: repository,
+		batchSize:  max(batchSize, 1),
+	}
+}
+
+// Replay replays the events up to the time the replay started, resuming an interrupted replay unless restart is set
+func (replayer *Replayer) Replay(ctx context.Context, restart bool) error {
+	// Keep the consumers from processing events while replaying, they pause until the replay is done
+	log.Println("waiting for the events being processed")
+	unlock, err := replayer.repository.LockReplay(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	checkpoint, err := replayer.repository.GetReplayCheckpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint == nil || restart {
+		now := time.Now()
+		total, err := replayer.repository.CountEvents(ctx, now)
+		if err != nil {
+			return err
+		}
+		if err := replayer.repository.ResetDerivedState(ctx); err != nil {
+			return err
+		}
+
+		// Save the checkpoint before replaying any event, so a resumed replay does not reset the state again
+		checkpoint = &ReplayCheckpoint{Until: now, Total: total, StartedAt: now}
+		if err := replayer.repository.ReplayEvents(ctx, nil, checkpoint); err != nil {
+			return err
+		}
+		log.Printf("replaying %d events up to %s", total, now.Format(time.RFC3339))
+	} else {
+		log.Printf("resuming replay at %d/%d events", checkpoint.Processed, checkpoint.Total)
+	}
+
+	for {
+		batch, err := replayer.repository.GetEventsAfter(ctx, checkpoint.LastTimestamp, checkpoint.LastEventID, checkpoint.Until, replayer.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		next := *checkpoint
+		next.LastTimestamp = batch[len(batch)-1].Timestamp
+		next.LastEventID = batch[len(batch)-1].ID
+		next.Processed += int64(len(batch))
+		if err := replayer.repository.ReplayEvents(ctx, batch, &next); err != nil {
+			return err
+		}
+		checkpoint = &next
+
+		log.Printf("replayed %d/%d events (%.1f%%)", checkpoint.Processed, checkpoint.Total, progress(checkpoint))
+	}
+
+	// Roll up the activity metrics of every day again
+	firstEvent, err := replayer.repository.GetFirstEventTime(ctx)
+	if err != nil {
+		return err
+	}
+	if !firstEvent.IsZero() {
+		if err := replayer.repository.AggregateDailyMetrics(ctx, truncateDay(firstEvent), truncateDay(checkpoint.Until).Add(day)); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("replayed %d events in %s", checkpoint.Processed, time.Since(checkpoint.StartedAt).Round(time.Second))
+	return replayer.repository.DeleteReplayCheckpoint(ctx)
+}
+
+// PublishReplayCheckpointAgeGauge publishes the seconds since the replay checkpoint was saved as an expvar
+func PublishReplayCheckpointAgeGauge(repository ReplayRepository) {
+	expvar.Publish("analytics_replay_checkpoint_age_seconds", replayCheckpointAgeGauge(repository))
+}
+
+// replayCheckpointAgeGauge reads the age of the replay checkpoint every time it is read, a failure is reported as -1
+func replayCheckpointAgeGauge(repository ReplayRepository) expvar.Func {
+	return func() any {
+		ctx, cancel := context.WithTimeout(context.Background(), replayCheckpointAgeTimeout)
+		defer cancel()
+
+		checkpoint, err := repository.GetReplayCheckpoint(ctx)
+		if err != nil {
+			log.Printf("failed to get replay checkpoint: %v", err)
+			return -1
+		}
+		if checkpoint == nil {
+			return int64(0)
+		}
+		return int64(time.Since(checkpoint.UpdatedAt).Seconds())
+	}
+}
+
+// progress returns the percentage of the events replayed
+func progress(checkpoint *ReplayCheckpoint) float64 {
+	if checkpoint.Total == 0 {
+		return 100
+	}
+	// Events that arrived late may make the processed events exceed the total
+	return min(float64(checkpoint.Processed)/float64(checkpoint.Total)*100, 100)
+}
@@ -0,0 +1,211 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replayedEvents are processed in a different order than their timestamps
+func replayedEvents(now time.Time) []*Event {
+	return []*Event{
+		{ID: "event-3", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet-1", Timestamp: now.Add(-time.Minute)},
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-1", Hashtags: []string{"go"}, Timestamp: now.Add(-time.Hour)},
+		{ID: "event-2", EventType: events.TypeTimelineViewed, UserID: "user2-id", Handler: "user2", TweetIDs: []string{"tweet-1"}, Timestamp: now.Add(-30 * time.Minute)},
+	}
+}
+
+// assertReplayedState checks the state derived from the replayed events is counted once
+func assertReplayedState(t *testing.T, repo *InMemoryRepository, now time.Time) {
+	ctx := context.Background()
+
+	engagement, err := repo.GetTweetEngagement(ctx, "tweet-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), engagement.Likes)
+
+	stats, err := repo.GetTweetDailyStats(ctx, []string{"tweet-1"}, now.Add(-time.Hour))
+	require.NoError(t, err)
+	var impressions, likes int64
+	for _, daily := range stats {
+		impressions += daily.Impressions
+		likes += daily.Likes
+	}
+	assert.Equal(t, int64(1), impressions)
+	assert.Equal(t, int64(1), likes)
+
+	counts, err := repo.GetHashtagCounts(ctx, now.Add(-2*time.Hour), now.Add(-HashtagRetention))
+	require.NoError(t, err)
+	assert.Equal(t, []*HashtagCount{{Tag: "go", WindowCount: 1, BaselineCount: 0}}, counts)
+
+	_, err = repo.GetUserAnalytics(ctx, "user1-id")
+	assert.NoError(t, err)
+
+	checkpoint, err := repo.GetReplayCheckpoint(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestReplayer_Replay(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	for _, event := range replayedEvents(now) {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+	require.NoError(t, repo.DeleteUserAnalytics(ctx, "user1-id"))
+
+	require.NoError(t, NewReplayer(repo, 2).Replay(ctx, false))
+
+	assertReplayedState(t, repo, now)
+
+	// The activity metrics are rolled up again
+	points, err := repo.GetDailyMetrics(ctx, MetricTweets, truncateDay(now.Add(-time.Hour)), truncateDay(now))
+	require.NoError(t, err)
+	var tweets int64
+	for _, point := range points {
+		tweets += point.Value
+	}
+	assert.Equal(t, int64(1), tweets)
+}
+
+func TestReplayer_ReplayResume(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	for _, event := range replayedEvents(now) {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	// Interrupt a replay after its first batch
+	require.NoError(t, repo.ResetDerivedState(ctx))
+	first, err := repo.GetEventsAfter(ctx, time.Time{}, "", now, 1)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	assert.Equal(t, "event-1", first[0].ID)
+	require.NoError(t, repo.ReplayEvents(ctx, first, &ReplayCheckpoint{
+		Until:         now,
+		LastTimestamp: first[0].Timestamp,
+		LastEventID:   first[0].ID,
+		Processed:     1,
+		Total:         3,
+		StartedAt:     now,
+	}))
+
+	require.NoError(t, NewReplayer(repo, 1).Replay(ctx, false))
+
+	assertReplayedState(t, repo, now)
+}
+
+func TestReplayer_ReplayRestart(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	for _, event := range replayedEvents(now) {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	// A checkpoint at the end of the events is ignored when restarting
+	require.NoError(t, repo.ReplayEvents(ctx, nil, &ReplayCheckpoint{
+		Until:         now,
+		LastTimestamp: now,
+		LastEventID:   "event-3",
+		Processed:     3,
+		Total:         3,
+		StartedAt:     now,
+	}))
+
+	require.NoError(t, NewReplayer(repo, 10).Replay(ctx, true))
+
+	assertReplayedState(t, repo, now)
+}
+
+func TestReplayer_ReplayPausesConsumer(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	consumer := NewConsumer(NewService(repo, nil, nil), nil, 1, time.Millisecond)
+	message := &queue.Message{
+		Topic:   events.TopicTweetPosted,
+		Key:     "user1",
+		Payload: []byte(`{"id":"event-1","event_type":"tweet_created","handler":"user1","tweet_id":"tweet-1","timestamp":"2025-08-09T05:13:41Z"}`),
+	}
+
+	// The event is refused while the replay holds the lock, so the queue delivers it again, and it is not dead-lettered
+	unlock, err := repo.LockReplay(ctx)
+	require.NoError(t, err)
+	assert.ErrorIs(t, consumer.HandleEvent(ctx, message), ErrReplayInProgress)
+	count, err := repo.CountEvents(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	unlock()
+	require.NoError(t, consumer.HandleEvent(ctx, message))
+
+	count, err = repo.CountEvents(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	deadLetters, err := repo.CountDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), deadLetters)
+}
+
+func TestReplayCheckpointAgeGauge(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	gauge := replayCheckpointAgeGauge(repo)
+
+	assert.Equal(t, int64(0), gauge())
+
+	now := time.Now()
+	require.NoError(t, repo.ReplayEvents(ctx, nil, &ReplayCheckpoint{Until: now, StartedAt: now}))
+	repo.checkpoint.UpdatedAt = now.Add(-time.Hour)
+	assert.Equal(t, int64(3600), gauge())
+}
+
+func TestReplayer_InterruptedReplayRefusesEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	require.NoError(t, repo.ReplayEvents(ctx, nil, &ReplayCheckpoint{Until: now, StartedAt: now}))
+
+	err := repo.ProcessEvent(ctx, &Event{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Timestamp: now})
+	assert.ErrorIs(t, err, ErrReplayInProgress)
+
+	// Resuming the replay lets the events be processed again
+	require.NoError(t, NewReplayer(repo, 10).Replay(ctx, false))
+	assert.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Timestamp: now}))
+}
+
+func TestReplayer_ReplayDerivesInfluencers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	for i := 0; i <= influencerTweetCount; i++ {
+		require.NoError(t, repo.ProcessEvent(ctx, &Event{
+			ID:        fmt.Sprintf("event-%d", i),
+			EventType: events.TypeTweetCreated,
+			UserID:    "user1-id",
+			Handler:   "user1",
+			Timestamp: now.Add(time.Duration(i-influencerTweetCount-1) * time.Second),
+		}))
+	}
+
+	require.NoError(t, NewReplayer(repo, 10).Replay(ctx, false))
+
+	// The influencer flag is derived again from the replayed tweets
+	analytics, err := repo.GetUserAnalytics(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.True(t, analytics.IsInfluencer)
+}
@@ -1,10 +1,17 @@
 package analytics
 
 import (
+	"container/list"
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/hyperloglog"
 )
 
 //go:generate mockgen -source=repository.go -destination=repository_mock.go -package=analytics
@@ -13,28 +20,253 @@ import (
 type Repository interface {
 	// User Analytics
 	GetUserAnalytics(ctx context.Context, userID string) (*UserAnalytics, error)
-	GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error)
+	GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter) ([]*UserAnalytics, error)
 	DeleteUserAnalytics(ctx context.Context, userID string) error
 
+	// Inactivity
+	InactivityRepository
+
+	// Known Users
+	KnownUserRepository
+
+	// User Handle Changes
+	RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error
+
 	// Event Processing
 	ProcessEvent(ctx context.Context, event *Event) error
+
+	// Tweet Engagement
+	TweetEngagementRepository
+
+	// Hashtag Trends
+	HashtagTrendRepository
+
+	// Activity Metrics
+	ActivityMetricsRepository
+
+	// Tweet Stats
+	TweetStatsRepository
+
+	// Dead Letters
+	DeadLetterRepository
+
+	// Events Replay
+	ReplayRepository
+
+	// Events Retention
+	EventRetentionRepository
+
+	// Spam Detection
+	SpamRepository
+}
+
+// KnownUserRepository defines the interface for known users data operations
+type KnownUserRepository interface {
+	GetKnownUserID(ctx context.Context, handler string) (string, error)
+	SaveKnownUser(ctx context.Context, userID, handler string) error
+}
+
+// InactivityRepository defines the interface for the inactivity data operations
+type InactivityRepository interface {
+	DeactivateIdleUsers(ctx context.Context, idleSince time.Time) ([]*UserAnalytics, error)
+}
+
+// TweetEngagementRepository defines the interface for tweet engagement data operations
+type TweetEngagementRepository interface {
+	GetTweetEngagements(ctx context.Context, since time.Time) ([]*TweetEngagement, error)
+}
+
+// HashtagTrendRepository defines the interface for hashtag trends data operations
+type HashtagTrendRepository interface {
+	GetHashtagCounts(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error)
+	DeleteHashtagBucketsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// ActivityMetricsRepository defines the interface for activity metrics data operations
+type ActivityMetricsRepository interface {
+	GetFirstEventTime(ctx context.Context) (time.Time, error)
+	GetLastAggregatedDay(ctx context.Context) (time.Time, error)
+	AggregateDailyMetrics(ctx context.Context, from, to time.Time) error
+	GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error)
+}
+
+// TweetStatsRepository defines the interface for tweet stats data operations
+type TweetStatsRepository interface {
+	GetTweetEngagement(ctx context.Context, tweetID string) (*TweetEngagement, error)
+	GetUserTweetEngagements(ctx context.Context, userID string, limit, offset int) ([]*TweetEngagement, error)
+	GetTweetDailyStats(ctx context.Context, tweetIDs []string, from time.Time) ([]*TweetDailyStats, error)
+}
+
+// DeadLetterRepository defines the interface for dead letters data operations
+type DeadLetterRepository interface {
+	SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error)
+	CountDeadLetters(ctx context.Context) (int64, error)
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+// ReplayRepository defines the interface for events replay data operations
+type ReplayRepository interface {
+	ActivityMetricsRepository
+
+	CountEvents(ctx context.Context, until time.Time) (int64, error)
+	GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error)
+	ResetDerivedState(ctx context.Context) error
+	ReplayEvents(ctx context.Context, events []*Event, checkpoint *ReplayCheckpoint) error
+	GetReplayCheckpoint(ctx context.Context) (*ReplayCheckpoint, error)
+	DeleteReplayCheckpoint(ctx context.Context) error
+	LockReplay(ctx context.Context) (unlock func(), err error)
+}
+
+// EventRetentionRepository defines the interface for events retention data operations
+type EventRetentionRepository interface {
+	GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error)
+	EnsureEventPartitions(ctx context.Context, from, to time.Time) ([]time.Time, error)
+	DropEventPartition(ctx context.Context, month time.Time) error
+}
+
+// SpamRepository defines the interface for spam detection data operations
+type SpamRepository interface {
+	GetUserEvents(ctx context.Context, userID string, eventTypes []string, since time.Time) ([]*Event, error)
+	FlagUser(ctx context.Context, flag *UserFlag) (bool, error)
+	GetUserFlag(ctx context.Context, id string) (*UserFlag, error)
+	GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error)
+	CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error)
+	ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error)
+}
+
+// ErrUserAnalyticsNotFound is returned when a user has no analytics
+var ErrUserAnalyticsNotFound = errors.New("user analytics not found")
+
+// ErrTweetNotFound is returned when a tweet is not tracked by the analytics service
+var ErrTweetNotFound = errors.New("tweet not found")
+
+// ErrDeadLetterNotFound is returned when a dead letter does not exist
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// ErrUserFlagNotFound is returned when a user flag does not exist
+var ErrUserFlagNotFound = errors.New("user flag not found")
+
+// ErrUserFlagResolved is returned when resolving a user flag that is not open
+var ErrUserFlagResolved = errors.New("user flag is already resolved")
+
+// ErrReplayInProgress is returned when processing an event while an events replay is running or was interrupted
+var ErrReplayInProgress = errors.New("events replay in progress")
+
+// ErrEventExpired is returned when processing an event of a month whose events partition was dropped
+var ErrEventExpired = fmt.Errorf("%w: event of a month dropped by the events retention", ErrInvalidEvent)
+
+// tweetDayKey identifies the stats of a tweet during a day in the in-memory repository
+type tweetDayKey struct {
+	tweetID string
+	day     int64 // Unix seconds
+}
+
+// dailyActivityKey identifies the daily activity of a user in the in-memory repository
+type dailyActivityKey struct {
+	day    int64 // Unix seconds
+	userID string
+}
+
+// dailyMetricKey identifies a daily metric in the in-memory repository
+type dailyMetricKey struct {
+	day    int64 // Unix seconds
+	metric string
+}
+
+// hashtagBucketKey identifies a hashtag bucket in the in-memory repository
+type hashtagBucketKey struct {
+	tag         string
+	bucketStart int64 // Unix seconds
+}
+
+// processedEventsWindow is the number of IDs of the last events processed kept by the in-memory repository
+const processedEventsWindow = 100_000
+
+// processedEvents is a bounded window of the IDs of the last events processed, used to skip their redeliveries
+type processedEvents struct {
+	capacity int
+	order    *list.List               // IDs, the most recently seen first
+	ids      map[string]*list.Element // ID -> element of order
+}
+
+// newProcessedEvents creates a window of up to capacity event IDs
+func newProcessedEvents(capacity int) *processedEvents {
+	return &processedEvents{
+		capacity: capacity,
+		order:    list.New(),
+		ids:      map[string]*list.Element{},
+	}
+}
+
+// add records an event ID, returning false if it is already in the window
+func (processed *processedEvents) add(id string) bool {
+	if element, exists := processed.ids[id]; exists {
+		processed.order.MoveToFront(element)
+		return false
+	}
+
+	processed.ids[id] = processed.order.PushFront(id)
+	if processed.order.Len() > processed.capacity {
+		oldest := processed.order.Back()
+		processed.order.Remove(oldest)
+		delete(processed.ids, oldest.Value.(string))
+	}
+	return true
+}
+
+// remove forgets an event ID
+func (processed *processedEvents) remove(id string) {
+	if element, exists := processed.ids[id]; exists {
+		processed.order.Remove(element)
+		delete(processed.ids, id)
+	}
 }
 
 // InMemoryRepository is an in-memory implementation of the Repository interface
 type InMemoryRepository struct {
-	mu        sync.RWMutex
-	analytics map[string]*UserAnalytics
-	eventsMu  sync.RWMutex
-	events    []*Event
+	mu          sync.RWMutex
+	analytics   map[string]*UserAnalytics   // user ID -> *UserAnalytics
+	engagements map[string]*TweetEngagement // tweetID -> *TweetEngagement
+	hashtags    map[hashtagBucketKey]int64
+	activity    map[dailyActivityKey]*DailyUserActivity
+	metrics     map[dailyMetricKey]int64
+	tweetStats  map[tweetDayKey]*TweetDailyStats
+	viewers     map[tweetDayKey]*hyperloglog.Sketch
+	deadLetters map[string]*DeadLetter
+	flags       map[string]*UserFlag
+	archived    map[string]*ArchivedUserCounters // user ID -> counters of the events of the dropped partitions
+	knownUsers  map[string]string                // user ID -> current handler
+	checkpoint  *ReplayCheckpoint
+	replayMu    sync.RWMutex // held by the replay, and tried by the events processed while it is not
+	eventsMu    sync.RWMutex
+	events      []*Event
+	processed   *processedEvents // IDs of the last events processed
+	partitions  map[int64]bool   // months (Unix seconds) with a partition
+	dropped     map[int64]bool   // months (Unix seconds) whose partition was dropped
 }
 
 // NewInMemoryRepository creates a new in-memory analytics repository
 func NewInMemoryRepository() *InMemoryRepository {
 	return &InMemoryRepository{
-		mu:        sync.RWMutex{},
-		eventsMu:  sync.RWMutex{},
-		analytics: map[string]*UserAnalytics{},
-		events:    []*Event{},
+		mu:          sync.RWMutex{},
+		eventsMu:    sync.RWMutex{},
+		analytics:   map[string]*UserAnalytics{},
+		engagements: map[string]*TweetEngagement{},
+		hashtags:    map[hashtagBucketKey]int64{},
+		activity:    map[dailyActivityKey]*DailyUserActivity{},
+		metrics:     map[dailyMetricKey]int64{},
+		tweetStats:  map[tweetDayKey]*TweetDailyStats{},
+		viewers:     map[tweetDayKey]*hyperloglog.Sketch{},
+		deadLetters: map[string]*DeadLetter{},
+		flags:       map[string]*UserFlag{},
+		archived:    map[string]*ArchivedUserCounters{},
+		knownUsers:  map[string]string{},
+		events:      []*Event{},
+		processed:   newProcessedEvents(processedEventsWindow),
+		partitions:  map[int64]bool{},
+		dropped:     map[int64]bool{},
 	}
 }
 
@@ -45,7 +277,7 @@ func (repository *InMemoryRepository) GetUserAnalytics(ctx context.Context, user
 
 	analytics, exists := repository.analytics[userID]
 	if !exists {
-		return nil, errors.New("user analytics not found")
+		return nil, ErrUserAnalyticsNotFound
 	}
 
 	// Return a copy to prevent external modifications
@@ -53,18 +285,25 @@ func (repository *InMemoryRepository) GetUserAnalytics(ctx context.Context, user
 	return &result, nil
 }
 
-// GetAllUserAnalytics retrieves analytics for all users
-func (repository *InMemoryRepository) GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error) {
+// GetAllUserAnalytics retrieves the analytics of the users that pass the filter, sorted and paginated by it
+func (repository *InMemoryRepository) GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter) ([]*UserAnalytics, error) {
 	repository.mu.RLock()
 	defer repository.mu.RUnlock()
 
-	result := make([]*UserAnalytics, 0, len(repository.analytics))
+	result := []*UserAnalytics{}
 	for _, analytics := range repository.analytics {
+		if !matchesUserAnalyticsFilter(analytics, filter) {
+			continue
+		}
 		// Create a copy to prevent external modifications
 		analyticsCopy := *analytics
 		result = append(result, &analyticsCopy)
 	}
+	sortUserAnalytics(result, filter)
 
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
 	return result, nil
 }
 
@@ -73,61 +312,924 @@ func (repository *InMemoryRepository) DeleteUserAnalytics(ctx context.Context, u
 	repository.mu.Lock()
 	defer repository.mu.Unlock()
 
+	delete(repository.archived, userID)
+	delete(repository.knownUsers, userID)
 	if _, exists := repository.analytics[userID]; !exists {
-		return errors.New("user analytics not found")
+		return ErrUserAnalyticsNotFound
 	}
 
 	delete(repository.analytics, userID)
 	return nil
 }
 
-// ProcessEvent processes an analytics event
+// DeactivateIdleUsers marks as inactive every active user with no activity since idleSince
+func (repository *InMemoryRepository) DeactivateIdleUsers(ctx context.Context, idleSince time.Time) ([]*UserAnalytics, error) {
+	// Find the latest event of each user
+	lastEvents := make(map[string]time.Time)
+	repository.eventsMu.RLock()
+	for _, event := range repository.events {
+		if event.Timestamp.After(lastEvents[event.UserID]) {
+			lastEvents[event.UserID] = event.Timestamp
+		}
+	}
+	repository.eventsMu.RUnlock()
+
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	deactivated := []*UserAnalytics{}
+	for userID, analytics := range repository.analytics {
+		if !analytics.IsActive || !analytics.UpdatedAt.Before(idleSince) {
+			continue
+		}
+		if !lastEvents[userID].Before(idleSince) {
+			continue
+		}
+
+		analytics.IsActive = false
+		analytics.UpdatedAt = now
+
+		// Create a copy to prevent external modifications
+		analyticsCopy := *analytics
+		deactivated = append(deactivated, &analyticsCopy)
+	}
+
+	return deactivated, nil
+}
+
+// GetKnownUserID retrieves the ID of the user known by a handler, "" if there is none
+func (repository *InMemoryRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	for userID, knownHandler := range repository.knownUsers {
+		if knownHandler == handler {
+			return userID, nil
+		}
+	}
+	return "", nil
+}
+
+// SaveKnownUser makes a user known by a handler, which is no longer the one of another user known by it
+func (repository *InMemoryRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.saveKnownUser(userID, handler)
+	return nil
+}
+
+// saveKnownUser makes a user known by a handler, the caller must hold the lock
+func (repository *InMemoryRepository) saveKnownUser(userID, handler string) {
+	for knownID, knownHandler := range repository.knownUsers {
+		if knownHandler == handler && knownID != userID {
+			delete(repository.knownUsers, knownID)
+		}
+	}
+	repository.knownUsers[userID] = handler
+}
+
+// RenameUser makes a user known by their new handler unless a later change was applied, and shows their analytics with it
+func (repository *InMemoryRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	handler, known := repository.knownUsers[userID]
+	if !known || handler == oldHandler {
+		handler = newHandler
+		repository.saveKnownUser(userID, handler)
+	}
+
+	// Replace the analytics, engagements and flags, since they may be shared with the callers
+	if analytics, exists := repository.analytics[userID]; exists && analytics.Handler != handler {
+		renamed := *analytics
+		renamed.Handler = handler
+		repository.analytics[userID] = &renamed
+	}
+	for tweetID, engagement := range repository.engagements {
+		if engagement.UserID == userID && engagement.Handler != handler {
+			renamed := *engagement
+			renamed.Handler = handler
+			repository.engagements[tweetID] = &renamed
+		}
+	}
+	for id, flag := range repository.flags {
+		if flag.UserID == userID && flag.Handler != handler {
+			renamed := *flag
+			renamed.Handler = handler
+			repository.flags[id] = &renamed
+		}
+	}
+	return nil
+}
+
+// ProcessEvent processes an analytics event, events whose ID is in the window of the last events processed are skipped
 func (repository *InMemoryRepository) ProcessEvent(ctx context.Context, event *Event) error {
+	if !repository.replayMu.TryRLock() {
+		return ErrReplayInProgress
+	}
+	defer repository.replayMu.RUnlock()
+	if checkpoint, _ := repository.GetReplayCheckpoint(ctx); checkpoint != nil {
+		return ErrReplayInProgress
+	}
+
 	repository.eventsMu.Lock()
+	if repository.dropped[truncateMonth(event.Timestamp).Unix()] {
+		repository.eventsMu.Unlock()
+		return ErrEventExpired
+	}
+	if !repository.processed.add(event.ID) {
+		// Already processed, e.g. a redelivered message
+		repository.eventsMu.Unlock()
+		return nil
+	}
 	repository.events = append(repository.events, event)
 	repository.eventsMu.Unlock()
 
 	repository.mu.Lock()
 	defer repository.mu.Unlock()
 
+	repository.applyEvent(event)
+	return nil
+}
+
+// applyEvent updates the state derived from an event, the caller must hold the lock
+func (repository *InMemoryRepository) applyEvent(event *Event) {
 	now := time.Now()
 
 	// Get or create user analytics
-	analytics, exists := repository.analytics[event.Handler]
-	if !exists {
-		analytics = &UserAnalytics{
-			Handler:   event.Handler,
-			CreatedAt: now,
-		}
+	analytics := repository.userAnalytics(event.UserID, event.Handler, now)
+
+	if analytics.LastActivityAt == nil || event.Timestamp.After(*analytics.LastActivityAt) {
+		lastActivity := event.Timestamp
+		analytics.LastActivityAt = &lastActivity
 	}
 
 	// Update analytics based on event type
 	switch event.EventType {
-	case "tweet_created":
+	case events.TypeTweetCreated:
 		// Mark user as active
 		analytics.IsActive = true
 		// If user has created many tweets, they might be an influencer
 		// This is a simple heuristic - in a real app, we'd have more sophisticated logic
-		tweetCount := 0
-		repository.eventsMu.RLock()
-		for _, e := range repository.events {
-			if e.Handler == event.Handler && e.EventType == "tweet_created" {
-				tweetCount++
+		analytics.TweetCount++
+		if analytics.TweetCount > influencerTweetCount {
+			analytics.IsInfluencer = true
+		}
+
+		// Start tracking the engagement of the tweet
+		if _, exists := repository.engagements[event.TweetID]; event.TweetID != "" && !exists {
+			repository.engagements[event.TweetID] = &TweetEngagement{
+				TweetID:   event.TweetID,
+				UserID:    event.UserID,
+				Handler:   analytics.Handler,
+				CreatedAt: event.Timestamp,
+				UpdatedAt: now,
 			}
 		}
-		repository.eventsMu.RUnlock()
 
-		if tweetCount > 100 { // Arbitrary threshold for demo
-			analytics.IsInfluencer = true
+		// Count the hashtags of the tweet in its bucket
+		bucketStart := event.Timestamp.Truncate(HashtagBucketSize).Unix()
+		for _, tag := range normalizeHashtags(event.Hashtags) {
+			repository.hashtags[hashtagBucketKey{tag: tag, bucketStart: bucketStart}]++
 		}
 
-	case "timeline_viewed":
+	case events.TypeTimelineViewed:
 		// Mark user as active
 		analytics.IsActive = true
+		if analytics.LastTimelineViewAt == nil || event.Timestamp.After(*analytics.LastTimelineViewAt) {
+			lastTimelineView := event.Timestamp
+			analytics.LastTimelineViewAt = &lastTimelineView
+		}
+
+		// Count an impression of each tweet served
+		for _, tweetID := range uniqueTweetIDs(event.TweetIDs) {
+			key := tweetDayKey{tweetID: tweetID, day: truncateDay(event.Timestamp).Unix()}
+			repository.dailyTweetStats(key).Impressions++
+			sketch, exists := repository.viewers[key]
+			if !exists {
+				sketch = hyperloglog.New(viewersSketchPrecision)
+				repository.viewers[key] = sketch
+			}
+			sketch.Add(event.UserID)
+		}
+
+	case events.TypeProfileViewed:
+		// Only profile views coming from a tweet are counted
+		if event.TweetID != "" {
+			repository.dailyTweetStats(tweetDayKey{tweetID: event.TweetID, day: truncateDay(event.Timestamp).Unix()}).ProfileViews++
+		}
+
+	case events.TypeTweetLiked, events.TypeTweetReplied:
+		stats := repository.dailyTweetStats(tweetDayKey{tweetID: event.TweetID, day: truncateDay(event.Timestamp).Unix()})
+		if event.EventType == events.TypeTweetLiked {
+			stats.Likes++
+		} else {
+			stats.Replies++
+		}
+
+		// Only tweets created while tracking have engagement counters
+		if engagement, exists := repository.engagements[event.TweetID]; exists {
+			if event.EventType == events.TypeTweetLiked {
+				engagement.Likes++
+			} else {
+				engagement.Replies++
+			}
+			engagement.UpdatedAt = now
+
+			// Add the engagement to the score of the author of the tweet
+			author := repository.userAnalytics(engagement.UserID, engagement.Handler, now)
+			if event.EventType == events.TypeTweetLiked {
+				author.EngagementScore += likeWeight
+			} else {
+				author.EngagementScore += replyWeight
+			}
+			author.UpdatedAt = now
+		}
+
+	case events.TypeUserFollowed, events.TypeUserUnfollowed:
+		// Count the followers of the followed user, which may not have any activity yet
+		followee := repository.userAnalytics(event.TargetUserID, event.TargetHandler, now)
+		if event.EventType == events.TypeUserFollowed {
+			followee.FollowerCount++
+		} else if followee.FollowerCount > 0 {
+			followee.FollowerCount--
+		}
+		followee.UpdatedAt = now
 	}
 
 	analytics.UpdatedAt = now
-	repository.analytics[event.Handler] = analytics
+}
+
+// userAnalytics returns the user analytics of a user, creating them with their current handler if needed
+func (repository *InMemoryRepository) userAnalytics(userID, handler string, now time.Time) *UserAnalytics {
+	analytics, exists := repository.analytics[userID]
+	if !exists {
+		if known, exists := repository.knownUsers[userID]; exists {
+			handler = known
+		}
+		analytics = &UserAnalytics{
+			UserID:    userID,
+			Handler:   handler,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		repository.analytics[userID] = analytics
+	}
+	return analytics
+}
+
+// GetTweetEngagements retrieves the engagement of the tweets created since the given time
+func (repository *InMemoryRepository) GetTweetEngagements(ctx context.Context, since time.Time) ([]*TweetEngagement, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	result := []*TweetEngagement{}
+	for _, engagement := range repository.engagements {
+		if engagement.CreatedAt.Before(since) {
+			continue
+		}
+		// Create a copy to prevent external modifications
+		engagementCopy := *engagement
+		result = append(result, &engagementCopy)
+	}
+
+	return result, nil
+}
+
+// GetHashtagCounts retrieves the uses of each hashtag since windowStart and between baselineStart and windowStart
+func (repository *InMemoryRepository) GetHashtagCounts(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	counts := map[string]*HashtagCount{}
+	for key, count := range repository.hashtags {
+		bucketStart := time.Unix(key.bucketStart, 0)
+		if bucketStart.Before(baselineStart) {
+			continue
+		}
+
+		hashtagCount, exists := counts[key.tag]
+		if !exists {
+			hashtagCount = &HashtagCount{Tag: key.tag}
+			counts[key.tag] = hashtagCount
+		}
+		if bucketStart.Before(windowStart) {
+			hashtagCount.BaselineCount += count
+		} else {
+			hashtagCount.WindowCount += count
+		}
+	}
+
+	result := []*HashtagCount{}
+	for _, hashtagCount := range counts {
+		if hashtagCount.WindowCount > 0 {
+			result = append(result, hashtagCount)
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteHashtagBucketsBefore deletes the hashtag buckets that started before the given time
+func (repository *InMemoryRepository) DeleteHashtagBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	var deleted int64
+	for key := range repository.hashtags {
+		if time.Unix(key.bucketStart, 0).Before(before) {
+			delete(repository.hashtags, key)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
+
+// GetFirstEventTime retrieves the timestamp of the oldest event, or the zero time if there are no events
+func (repository *InMemoryRepository) GetFirstEventTime(ctx context.Context) (time.Time, error) {
+	repository.eventsMu.RLock()
+	defer repository.eventsMu.RUnlock()
+
+	var first time.Time
+	for _, event := range repository.events {
+		if first.IsZero() || event.Timestamp.Before(first) {
+			first = event.Timestamp
+		}
+	}
+
+	return first, nil
+}
+
+// GetLastAggregatedDay retrieves the last day with aggregated metrics, or the zero time if there are none
+func (repository *InMemoryRepository) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var last int64
+	for key := range repository.metrics {
+		if key.day > last {
+			last = key.day
+		}
+	}
+	if last == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(last, 0).UTC(), nil
+}
+
+// AggregateDailyMetrics recomputes the daily user activity and the daily metrics of the days in [from, to)
+func (repository *InMemoryRepository) AggregateDailyMetrics(ctx context.Context, from, to time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	// Recompute the daily user activity from the events
+	for key := range repository.activity {
+		if key.day >= from.Unix() && key.day < to.Unix() {
+			delete(repository.activity, key)
+		}
+	}
+	repository.eventsMu.RLock()
+	for _, event := range repository.events {
+		if event.Timestamp.Before(from) || !event.Timestamp.Before(to) {
+			continue
+		}
+		key := dailyActivityKey{day: truncateDay(event.Timestamp).Unix(), userID: event.UserID}
+		activity, exists := repository.activity[key]
+		if !exists {
+			activity = &DailyUserActivity{Day: truncateDay(event.Timestamp), UserID: event.UserID}
+			repository.activity[key] = activity
+		}
+		switch event.EventType {
+		case events.TypeTweetCreated:
+			activity.Tweets++
+		case events.TypeTimelineViewed:
+			activity.TimelineViews++
+		}
+	}
+	repository.eventsMu.RUnlock()
+
+	// Recompute the daily metrics from the daily user activity
+	for date := from; date.Before(to); date = date.Add(day) {
+		weekActive := map[string]bool{}
+		monthActive := map[string]bool{}
+		var dailyActive, tweets, timelineViews int64
+		for key, activity := range repository.activity {
+			age := date.Sub(time.Unix(key.day, 0))
+			if age < 0 || age >= 30*day {
+				continue
+			}
+			monthActive[key.userID] = true
+			if age < 7*day {
+				weekActive[key.userID] = true
+			}
+			if age == 0 {
+				dailyActive++
+				tweets += activity.Tweets
+				timelineViews += activity.TimelineViews
+			}
+		}
+
+		repository.metrics[dailyMetricKey{day: date.Unix(), metric: MetricDAU}] = dailyActive
+		repository.metrics[dailyMetricKey{day: date.Unix(), metric: MetricWAU}] = int64(len(weekActive))
+		repository.metrics[dailyMetricKey{day: date.Unix(), metric: MetricMAU}] = int64(len(monthActive))
+		repository.metrics[dailyMetricKey{day: date.Unix(), metric: MetricTweets}] = tweets
+		repository.metrics[dailyMetricKey{day: date.Unix(), metric: MetricTimelineViews}] = timelineViews
+	}
+
+	return nil
+}
+
+// GetDailyMetrics retrieves the daily values of a metric between from and to, both included, ordered by day
+func (repository *InMemoryRepository) GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	result := []*DailyMetric{}
+	for date := truncateDay(from); !date.After(to); date = date.Add(day) {
+		if value, exists := repository.metrics[dailyMetricKey{day: date.Unix(), metric: metric}]; exists {
+			result = append(result, &DailyMetric{Day: date, Metric: metric, Value: value})
+		}
+	}
+
+	return result, nil
+}
+
+// dailyTweetStats returns the stats of a tweet during a day, creating them if needed. The caller must hold the lock.
+func (repository *InMemoryRepository) dailyTweetStats(key tweetDayKey) *TweetDailyStats {
+	stats, exists := repository.tweetStats[key]
+	if !exists {
+		stats = &TweetDailyStats{TweetID: key.tweetID, Day: time.Unix(key.day, 0).UTC()}
+		repository.tweetStats[key] = stats
+	}
+	return stats
+}
+
+// GetTweetEngagement retrieves the engagement of a tweet
+func (repository *InMemoryRepository) GetTweetEngagement(ctx context.Context, tweetID string) (*TweetEngagement, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	engagement, exists := repository.engagements[tweetID]
+	if !exists {
+		return nil, ErrTweetNotFound
+	}
+
+	// Return a copy to prevent external modifications
+	result := *engagement
+	return &result, nil
+}
+
+// GetUserTweetEngagements retrieves the engagement of the tweets of a user, newest first
+func (repository *InMemoryRepository) GetUserTweetEngagements(ctx context.Context, userID string, limit, offset int) ([]*TweetEngagement, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	result := []*TweetEngagement{}
+	for _, engagement := range repository.engagements {
+		if engagement.UserID == userID {
+			// Create a copy to prevent external modifications
+			engagementCopy := *engagement
+			result = append(result, &engagementCopy)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.After(result[j].CreatedAt)
+	})
+
+	if offset >= len(result) {
+		return []*TweetEngagement{}, nil
+	}
+	return result[offset:min(offset+limit, len(result))], nil
+}
+
+// GetTweetDailyStats retrieves the daily stats of the tweets since the day of from, ordered by tweet and day
+func (repository *InMemoryRepository) GetTweetDailyStats(ctx context.Context, tweetIDs []string, from time.Time) ([]*TweetDailyStats, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	requested := map[string]bool{}
+	for _, tweetID := range tweetIDs {
+		requested[tweetID] = true
+	}
+
+	result := []*TweetDailyStats{}
+	for key, stats := range repository.tweetStats {
+		if !requested[key.tweetID] || key.day < truncateDay(from).Unix() {
+			continue
+		}
+
+		// Create a copy to prevent external modifications
+		statsCopy := *stats
+		if sketch, exists := repository.viewers[key]; exists {
+			viewers, err := sketch.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			statsCopy.Viewers = viewers
+		}
+		result = append(result, &statsCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TweetID != result[j].TweetID {
+			return result[i].TweetID < result[j].TweetID
+		}
+		return result[i].Day.Before(result[j].Day)
+	})
+
+	return result, nil
+}
+
+// SaveDeadLetter creates or updates a dead letter
+func (repository *InMemoryRepository) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	if deadLetter.CreatedAt.IsZero() {
+		deadLetter.CreatedAt = now
+	}
+	deadLetter.UpdatedAt = now
+
+	// Store a copy to prevent external modifications
+	deadLetterCopy := *deadLetter
+	repository.deadLetters[deadLetter.ID] = &deadLetterCopy
+	return nil
+}
+
+// GetDeadLetter retrieves a dead letter
+func (repository *InMemoryRepository) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	deadLetter, exists := repository.deadLetters[id]
+	if !exists {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	// Return a copy to prevent external modifications
+	result := *deadLetter
+	return &result, nil
+}
+
+// GetDeadLetters retrieves the dead letters, newest first
+func (repository *InMemoryRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	result := make([]*DeadLetter, 0, len(repository.deadLetters))
+	for _, deadLetter := range repository.deadLetters {
+		// Create a copy to prevent external modifications
+		deadLetterCopy := *deadLetter
+		result = append(result, &deadLetterCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if offset >= len(result) {
+		return []*DeadLetter{}, nil
+	}
+	return result[offset:min(offset+limit, len(result))], nil
+}
+
+// CountDeadLetters counts the dead letters
+func (repository *InMemoryRepository) CountDeadLetters(ctx context.Context) (int64, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	return int64(len(repository.deadLetters)), nil
+}
+
+// DeleteDeadLetter deletes a dead letter
+func (repository *InMemoryRepository) DeleteDeadLetter(ctx context.Context, id string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if _, exists := repository.deadLetters[id]; !exists {
+		return ErrDeadLetterNotFound
+	}
+
+	delete(repository.deadLetters, id)
+	return nil
+}
+
+// CountEvents counts the events up to until
+func (repository *InMemoryRepository) CountEvents(ctx context.Context, until time.Time) (int64, error) {
+	repository.eventsMu.RLock()
+	defer repository.eventsMu.RUnlock()
+
+	var count int64
+	for _, event := range repository.events {
+		if !event.Timestamp.After(until) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetEventsAfter retrieves the events after the given timestamp and event ID up to until, ordered by timestamp and ID
+func (repository *InMemoryRepository) GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error) {
+	repository.eventsMu.RLock()
+	defer repository.eventsMu.RUnlock()
+
+	result := []*Event{}
+	for _, event := range repository.events {
+		if event.Timestamp.After(until) {
+			continue
+		}
+		if event.Timestamp.Before(timestamp) || (event.Timestamp.Equal(timestamp) && event.ID <= eventID) {
+			continue
+		}
+		result = append(result, event)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// ResetDerivedState deletes the state derived from the events, starting over from the archived counters
+func (repository *InMemoryRepository) ResetDerivedState(ctx context.Context) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.analytics = map[string]*UserAnalytics{}
+	repository.engagements = map[string]*TweetEngagement{}
+	repository.hashtags = map[hashtagBucketKey]int64{}
+	repository.activity = map[dailyActivityKey]*DailyUserActivity{}
+	repository.metrics = map[dailyMetricKey]int64{}
+	repository.tweetStats = map[tweetDayKey]*TweetDailyStats{}
+	repository.viewers = map[tweetDayKey]*hyperloglog.Sketch{}
 
+	// Start from the counters of the events already dropped
+	for userID, counters := range repository.archived {
+		repository.analytics[userID] = &UserAnalytics{
+			UserID:        userID,
+			Handler:       repository.knownUsers[userID],
+			IsInfluencer:  counters.TweetCount > influencerTweetCount,
+			TweetCount:    counters.TweetCount,
+			FollowerCount: max(counters.FollowerCount, 0),
+			CreatedAt:     counters.CreatedAt,
+			UpdatedAt:     counters.CreatedAt,
+		}
+	}
+	return nil
+}
+
+// ReplayEvents updates the state derived from the stored events and saves the replay checkpoint
+func (repository *InMemoryRepository) ReplayEvents(ctx context.Context, events []*Event, checkpoint *ReplayCheckpoint) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for _, event := range events {
+		repository.applyEvent(event)
+	}
+
+	// Store a copy to prevent external modifications
+	checkpointCopy := *checkpoint
+	checkpointCopy.UpdatedAt = time.Now()
+	repository.checkpoint = &checkpointCopy
+	return nil
+}
+
+// GetReplayCheckpoint retrieves the checkpoint of the replay in progress, or nil if there is none
+func (repository *InMemoryRepository) GetReplayCheckpoint(ctx context.Context) (*ReplayCheckpoint, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	if repository.checkpoint == nil {
+		return nil, nil
+	}
+
+	// Return a copy to prevent external modifications
+	result := *repository.checkpoint
+	return &result, nil
+}
+
+// DeleteReplayCheckpoint deletes the checkpoint of the replay in progress
+func (repository *InMemoryRepository) DeleteReplayCheckpoint(ctx context.Context) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.checkpoint = nil
 	return nil
 }
+
+// LockReplay waits until the events being processed are done and keeps new ones from being processed until unlocked
+func (repository *InMemoryRepository) LockReplay(ctx context.Context) (func(), error) {
+	repository.replayMu.Lock()
+	return repository.replayMu.Unlock, nil
+}
+
+// EnsureEventPartitions creates the missing partitions of the months between from and to and of the stored events
+func (repository *InMemoryRepository) EnsureEventPartitions(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	repository.eventsMu.Lock()
+	defer repository.eventsMu.Unlock()
+
+	for month := truncateMonth(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		repository.partitions[month.Unix()] = true
+	}
+	for _, event := range repository.events {
+		repository.partitions[truncateMonth(event.Timestamp).Unix()] = true
+	}
+
+	months := make([]time.Time, 0, len(repository.partitions))
+	for month := range repository.partitions {
+		months = append(months, time.Unix(month, 0).UTC())
+	}
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Before(months[j])
+	})
+	return months, nil
+}
+
+// DropEventPartition deletes the events of a month, adding their counters to the archived ones
+func (repository *InMemoryRepository) DropEventPartition(ctx context.Context, month time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+	repository.eventsMu.Lock()
+	defer repository.eventsMu.Unlock()
+
+	month = truncateMonth(month)
+	kept := make([]*Event, 0, len(repository.events))
+	for _, event := range repository.events {
+		if !truncateMonth(event.Timestamp).Equal(month) {
+			kept = append(kept, event)
+			continue
+		}
+		repository.processed.remove(event.ID)
+
+		switch event.EventType {
+		case events.TypeTweetCreated:
+			repository.archivedCounters(event.UserID, event.Timestamp).TweetCount++
+		case events.TypeUserFollowed:
+			repository.archivedCounters(event.TargetUserID, event.Timestamp).FollowerCount++
+		case events.TypeUserUnfollowed:
+			repository.archivedCounters(event.TargetUserID, event.Timestamp).FollowerCount--
+		}
+	}
+	repository.events = kept
+	delete(repository.partitions, month.Unix())
+	repository.dropped[month.Unix()] = true
+	return nil
+}
+
+// archivedCounters gets or creates the archived counters of a user, keeping the time of their first event
+func (repository *InMemoryRepository) archivedCounters(userID string, timestamp time.Time) *ArchivedUserCounters {
+	counters, exists := repository.archived[userID]
+	if !exists {
+		counters = &ArchivedUserCounters{UserID: userID, CreatedAt: timestamp}
+		repository.archived[userID] = counters
+	}
+	if timestamp.Before(counters.CreatedAt) {
+		counters.CreatedAt = timestamp
+	}
+	return counters
+}
+
+// GetUserEvents retrieves the events of the given types of a user since the given time, ordered by timestamp and ID
+func (repository *InMemoryRepository) GetUserEvents(ctx context.Context, userID string, eventTypes []string, since time.Time) ([]*Event, error) {
+	repository.eventsMu.RLock()
+	defer repository.eventsMu.RUnlock()
+
+	result := []*Event{}
+	for _, event := range repository.events {
+		if event.UserID != userID || event.Timestamp.Before(since) || !slices.Contains(eventTypes, event.EventType) {
+			continue
+		}
+		// Create a copy to prevent external modifications
+		eventCopy := *event
+		result = append(result, &eventCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Timestamp.Equal(result[j].Timestamp) {
+			return result[i].Timestamp.Before(result[j].Timestamp)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// FlagUser creates an open flag or counts another occurrence of it, and reports whether it was created
+func (repository *InMemoryRepository) FlagUser(ctx context.Context, flag *UserFlag) (bool, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	now := time.Now()
+	for _, existing := range repository.flags {
+		if existing.UserID == flag.UserID && existing.Rule == flag.Rule && existing.Status == UserFlagStatusOpen {
+			existing.Reason = flag.Reason
+			existing.Occurrences++
+			existing.UpdatedAt = now
+			*flag = *existing
+			return false, nil
+		}
+	}
+
+	flag.Status = UserFlagStatusOpen
+	flag.Occurrences = 1
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+
+	// Store a copy to prevent external modifications
+	flagCopy := *flag
+	repository.flags[flag.ID] = &flagCopy
+	return true, nil
+}
+
+// GetUserFlag retrieves a user flag
+func (repository *InMemoryRepository) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	flag, exists := repository.flags[id]
+	if !exists {
+		return nil, ErrUserFlagNotFound
+	}
+
+	// Return a copy to prevent external modifications
+	result := *flag
+	return &result, nil
+}
+
+// GetUserFlags retrieves the user flags that pass the filter, newest first
+func (repository *InMemoryRepository) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	result := []*UserFlag{}
+	for _, flag := range repository.flags {
+		if !matchesUserFlagFilter(flag, filter) {
+			continue
+		}
+		// Create a copy to prevent external modifications
+		flagCopy := *flag
+		result = append(result, &flagCopy)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if offset >= len(result) {
+		return []*UserFlag{}, nil
+	}
+	return result[offset:min(offset+limit, len(result))], nil
+}
+
+// CountUserFlags counts the user flags that pass the filter
+func (repository *InMemoryRepository) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var count int64
+	for _, flag := range repository.flags {
+		if matchesUserFlagFilter(flag, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ResolveUserFlag sets the status of an open user flag
+func (repository *InMemoryRepository) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	flag, exists := repository.flags[id]
+	if !exists {
+		return nil, ErrUserFlagNotFound
+	}
+	if flag.Status != UserFlagStatusOpen {
+		return nil, ErrUserFlagResolved
+	}
+	flag.Status = status
+	flag.UpdatedAt = time.Now()
+
+	// Return a copy to prevent external modifications
+	result := *flag
+	return &result, nil
+}
+
+// matchesUserFlagFilter reports whether a user flag passes the filter
+func matchesUserFlagFilter(flag *UserFlag, filter UserFlagFilter) bool {
+	return (filter.UserID == "" || flag.UserID == filter.UserID) &&
+		(filter.Rule == "" || flag.Rule == filter.Rule) &&
+		(filter.Status == "" || flag.Status == filter.Status)
+}
@@ -12,6 +12,7 @@ package analytics
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,123 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AggregateDailyMetrics mocks base method.
+func (m *MockRepository) AggregateDailyMetrics(ctx context.Context, from, to time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateDailyMetrics", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AggregateDailyMetrics indicates an expected call of AggregateDailyMetrics.
+func (mr *MockRepositoryMockRecorder) AggregateDailyMetrics(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateDailyMetrics", reflect.TypeOf((*MockRepository)(nil).AggregateDailyMetrics), ctx, from, to)
+}
+
+// CountDeadLetters mocks base method.
+func (m *MockRepository) CountDeadLetters(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeadLetters", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeadLetters indicates an expected call of CountDeadLetters.
+func (mr *MockRepositoryMockRecorder) CountDeadLetters(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeadLetters", reflect.TypeOf((*MockRepository)(nil).CountDeadLetters), ctx)
+}
+
+// CountEvents mocks base method.
+func (m *MockRepository) CountEvents(ctx context.Context, until time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEvents", ctx, until)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEvents indicates an expected call of CountEvents.
+func (mr *MockRepositoryMockRecorder) CountEvents(ctx, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEvents", reflect.TypeOf((*MockRepository)(nil).CountEvents), ctx, until)
+}
+
+// CountUserFlags mocks base method.
+func (m *MockRepository) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserFlags", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserFlags indicates an expected call of CountUserFlags.
+func (mr *MockRepositoryMockRecorder) CountUserFlags(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserFlags", reflect.TypeOf((*MockRepository)(nil).CountUserFlags), ctx, filter)
+}
+
+// DeactivateIdleUsers mocks base method.
+func (m *MockRepository) DeactivateIdleUsers(ctx context.Context, idleSince time.Time) ([]*UserAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateIdleUsers", ctx, idleSince)
+	ret0, _ := ret[0].([]*UserAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateIdleUsers indicates an expected call of DeactivateIdleUsers.
+func (mr *MockRepositoryMockRecorder) DeactivateIdleUsers(ctx, idleSince any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateIdleUsers", reflect.TypeOf((*MockRepository)(nil).DeactivateIdleUsers), ctx, idleSince)
+}
+
+// DeleteDeadLetter mocks base method.
+func (m *MockRepository) DeleteDeadLetter(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeadLetter", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeadLetter indicates an expected call of DeleteDeadLetter.
+func (mr *MockRepositoryMockRecorder) DeleteDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeadLetter", reflect.TypeOf((*MockRepository)(nil).DeleteDeadLetter), ctx, id)
+}
+
+// DeleteHashtagBucketsBefore mocks base method.
+func (m *MockRepository) DeleteHashtagBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHashtagBucketsBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteHashtagBucketsBefore indicates an expected call of DeleteHashtagBucketsBefore.
+func (mr *MockRepositoryMockRecorder) DeleteHashtagBucketsBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHashtagBucketsBefore", reflect.TypeOf((*MockRepository)(nil).DeleteHashtagBucketsBefore), ctx, before)
+}
+
+// DeleteReplayCheckpoint mocks base method.
+func (m *MockRepository) DeleteReplayCheckpoint(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReplayCheckpoint", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReplayCheckpoint indicates an expected call of DeleteReplayCheckpoint.
+func (mr *MockRepositoryMockRecorder) DeleteReplayCheckpoint(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplayCheckpoint", reflect.TypeOf((*MockRepository)(nil).DeleteReplayCheckpoint), ctx)
+}
+
 // DeleteUserAnalytics mocks base method.
 func (m *MockRepository) DeleteUserAnalytics(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -54,19 +172,243 @@ func (mr *MockRepositoryMockRecorder) DeleteUserAnalytics(ctx, userID any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAnalytics", reflect.TypeOf((*MockRepository)(nil).DeleteUserAnalytics), ctx, userID)
 }
 
+// DropEventPartition mocks base method.
+func (m *MockRepository) DropEventPartition(ctx context.Context, month time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropEventPartition", ctx, month)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropEventPartition indicates an expected call of DropEventPartition.
+func (mr *MockRepositoryMockRecorder) DropEventPartition(ctx, month any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropEventPartition", reflect.TypeOf((*MockRepository)(nil).DropEventPartition), ctx, month)
+}
+
+// EnsureEventPartitions mocks base method.
+func (m *MockRepository) EnsureEventPartitions(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEventPartitions", ctx, from, to)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEventPartitions indicates an expected call of EnsureEventPartitions.
+func (mr *MockRepositoryMockRecorder) EnsureEventPartitions(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEventPartitions", reflect.TypeOf((*MockRepository)(nil).EnsureEventPartitions), ctx, from, to)
+}
+
+// FlagUser mocks base method.
+func (m *MockRepository) FlagUser(ctx context.Context, flag *UserFlag) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagUser", ctx, flag)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlagUser indicates an expected call of FlagUser.
+func (mr *MockRepositoryMockRecorder) FlagUser(ctx, flag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagUser", reflect.TypeOf((*MockRepository)(nil).FlagUser), ctx, flag)
+}
+
 // GetAllUserAnalytics mocks base method.
-func (m *MockRepository) GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error) {
+func (m *MockRepository) GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter) ([]*UserAnalytics, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUserAnalytics", ctx)
+	ret := m.ctrl.Call(m, "GetAllUserAnalytics", ctx, filter)
 	ret0, _ := ret[0].([]*UserAnalytics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllUserAnalytics indicates an expected call of GetAllUserAnalytics.
-func (mr *MockRepositoryMockRecorder) GetAllUserAnalytics(ctx any) *gomock.Call {
+func (mr *MockRepositoryMockRecorder) GetAllUserAnalytics(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUserAnalytics", reflect.TypeOf((*MockRepository)(nil).GetAllUserAnalytics), ctx, filter)
+}
+
+// GetDailyMetrics mocks base method.
+func (m *MockRepository) GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyMetrics", ctx, metric, from, to)
+	ret0, _ := ret[0].([]*DailyMetric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyMetrics indicates an expected call of GetDailyMetrics.
+func (mr *MockRepositoryMockRecorder) GetDailyMetrics(ctx, metric, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyMetrics", reflect.TypeOf((*MockRepository)(nil).GetDailyMetrics), ctx, metric, from, to)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockRepository) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockRepositoryMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockRepository)(nil).GetDeadLetter), ctx, id)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", ctx, limit, offset)
+	ret0, _ := ret[0].([]*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockRepositoryMockRecorder) GetDeadLetters(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockRepository)(nil).GetDeadLetters), ctx, limit, offset)
+}
+
+// GetEventsAfter mocks base method.
+func (m *MockRepository) GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventsAfter", ctx, timestamp, eventID, until, limit)
+	ret0, _ := ret[0].([]*Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventsAfter indicates an expected call of GetEventsAfter.
+func (mr *MockRepositoryMockRecorder) GetEventsAfter(ctx, timestamp, eventID, until, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventsAfter", reflect.TypeOf((*MockRepository)(nil).GetEventsAfter), ctx, timestamp, eventID, until, limit)
+}
+
+// GetFirstEventTime mocks base method.
+func (m *MockRepository) GetFirstEventTime(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstEventTime", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstEventTime indicates an expected call of GetFirstEventTime.
+func (mr *MockRepositoryMockRecorder) GetFirstEventTime(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstEventTime", reflect.TypeOf((*MockRepository)(nil).GetFirstEventTime), ctx)
+}
+
+// GetHashtagCounts mocks base method.
+func (m *MockRepository) GetHashtagCounts(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHashtagCounts", ctx, windowStart, baselineStart)
+	ret0, _ := ret[0].([]*HashtagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHashtagCounts indicates an expected call of GetHashtagCounts.
+func (mr *MockRepositoryMockRecorder) GetHashtagCounts(ctx, windowStart, baselineStart any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHashtagCounts", reflect.TypeOf((*MockRepository)(nil).GetHashtagCounts), ctx, windowStart, baselineStart)
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// GetLastAggregatedDay mocks base method.
+func (m *MockRepository) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAggregatedDay", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAggregatedDay indicates an expected call of GetLastAggregatedDay.
+func (mr *MockRepositoryMockRecorder) GetLastAggregatedDay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAggregatedDay", reflect.TypeOf((*MockRepository)(nil).GetLastAggregatedDay), ctx)
+}
+
+// GetReplayCheckpoint mocks base method.
+func (m *MockRepository) GetReplayCheckpoint(ctx context.Context) (*ReplayCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplayCheckpoint", ctx)
+	ret0, _ := ret[0].(*ReplayCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReplayCheckpoint indicates an expected call of GetReplayCheckpoint.
+func (mr *MockRepositoryMockRecorder) GetReplayCheckpoint(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplayCheckpoint", reflect.TypeOf((*MockRepository)(nil).GetReplayCheckpoint), ctx)
+}
+
+// GetTweetDailyStats mocks base method.
+func (m *MockRepository) GetTweetDailyStats(ctx context.Context, tweetIDs []string, from time.Time) ([]*TweetDailyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetDailyStats", ctx, tweetIDs, from)
+	ret0, _ := ret[0].([]*TweetDailyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetDailyStats indicates an expected call of GetTweetDailyStats.
+func (mr *MockRepositoryMockRecorder) GetTweetDailyStats(ctx, tweetIDs, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetDailyStats", reflect.TypeOf((*MockRepository)(nil).GetTweetDailyStats), ctx, tweetIDs, from)
+}
+
+// GetTweetEngagement mocks base method.
+func (m *MockRepository) GetTweetEngagement(ctx context.Context, tweetID string) (*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetEngagement", ctx, tweetID)
+	ret0, _ := ret[0].(*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetEngagement indicates an expected call of GetTweetEngagement.
+func (mr *MockRepositoryMockRecorder) GetTweetEngagement(ctx, tweetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetEngagement", reflect.TypeOf((*MockRepository)(nil).GetTweetEngagement), ctx, tweetID)
+}
+
+// GetTweetEngagements mocks base method.
+func (m *MockRepository) GetTweetEngagements(ctx context.Context, since time.Time) ([]*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetEngagements", ctx, since)
+	ret0, _ := ret[0].([]*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetEngagements indicates an expected call of GetTweetEngagements.
+func (mr *MockRepositoryMockRecorder) GetTweetEngagements(ctx, since any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUserAnalytics", reflect.TypeOf((*MockRepository)(nil).GetAllUserAnalytics), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetEngagements", reflect.TypeOf((*MockRepository)(nil).GetTweetEngagements), ctx, since)
 }
 
 // GetUserAnalytics mocks base method.
@@ -84,6 +426,81 @@ func (mr *MockRepositoryMockRecorder) GetUserAnalytics(ctx, userID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAnalytics", reflect.TypeOf((*MockRepository)(nil).GetUserAnalytics), ctx, userID)
 }
 
+// GetUserEvents mocks base method.
+func (m *MockRepository) GetUserEvents(ctx context.Context, userID string, eventTypes []string, since time.Time) ([]*Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID, eventTypes, since)
+	ret0, _ := ret[0].([]*Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockRepositoryMockRecorder) GetUserEvents(ctx, userID, eventTypes, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockRepository)(nil).GetUserEvents), ctx, userID, eventTypes, since)
+}
+
+// GetUserFlag mocks base method.
+func (m *MockRepository) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlag", ctx, id)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlag indicates an expected call of GetUserFlag.
+func (mr *MockRepositoryMockRecorder) GetUserFlag(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlag", reflect.TypeOf((*MockRepository)(nil).GetUserFlag), ctx, id)
+}
+
+// GetUserFlags mocks base method.
+func (m *MockRepository) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlags", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlags indicates an expected call of GetUserFlags.
+func (mr *MockRepositoryMockRecorder) GetUserFlags(ctx, filter, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlags", reflect.TypeOf((*MockRepository)(nil).GetUserFlags), ctx, filter, limit, offset)
+}
+
+// GetUserTweetEngagements mocks base method.
+func (m *MockRepository) GetUserTweetEngagements(ctx context.Context, userID string, limit, offset int) ([]*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTweetEngagements", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTweetEngagements indicates an expected call of GetUserTweetEngagements.
+func (mr *MockRepositoryMockRecorder) GetUserTweetEngagements(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTweetEngagements", reflect.TypeOf((*MockRepository)(nil).GetUserTweetEngagements), ctx, userID, limit, offset)
+}
+
+// LockReplay mocks base method.
+func (m *MockRepository) LockReplay(ctx context.Context) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockReplay", ctx)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockReplay indicates an expected call of LockReplay.
+func (mr *MockRepositoryMockRecorder) LockReplay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockReplay", reflect.TypeOf((*MockRepository)(nil).LockReplay), ctx)
+}
+
 // ProcessEvent mocks base method.
 func (m *MockRepository) ProcessEvent(ctx context.Context, event *Event) error {
 	m.ctrl.T.Helper()
@@ -97,3 +514,889 @@ func (mr *MockRepositoryMockRecorder) ProcessEvent(ctx, event any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessEvent", reflect.TypeOf((*MockRepository)(nil).ProcessEvent), ctx, event)
 }
+
+// RenameUser mocks base method.
+func (m *MockRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", ctx, userID, oldHandler, newHandler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockRepositoryMockRecorder) RenameUser(ctx, userID, oldHandler, newHandler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockRepository)(nil).RenameUser), ctx, userID, oldHandler, newHandler)
+}
+
+// ReplayEvents mocks base method.
+func (m *MockRepository) ReplayEvents(ctx context.Context, events []*Event, checkpoint *ReplayCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayEvents", ctx, events, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayEvents indicates an expected call of ReplayEvents.
+func (mr *MockRepositoryMockRecorder) ReplayEvents(ctx, events, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayEvents", reflect.TypeOf((*MockRepository)(nil).ReplayEvents), ctx, events, checkpoint)
+}
+
+// ResetDerivedState mocks base method.
+func (m *MockRepository) ResetDerivedState(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetDerivedState", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetDerivedState indicates an expected call of ResetDerivedState.
+func (mr *MockRepositoryMockRecorder) ResetDerivedState(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDerivedState", reflect.TypeOf((*MockRepository)(nil).ResetDerivedState), ctx)
+}
+
+// ResolveUserFlag mocks base method.
+func (m *MockRepository) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveUserFlag", ctx, id, status)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveUserFlag indicates an expected call of ResolveUserFlag.
+func (mr *MockRepositoryMockRecorder) ResolveUserFlag(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveUserFlag", reflect.TypeOf((*MockRepository)(nil).ResolveUserFlag), ctx, id, status)
+}
+
+// SaveDeadLetter mocks base method.
+func (m *MockRepository) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetter", ctx, deadLetter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetter indicates an expected call of SaveDeadLetter.
+func (mr *MockRepositoryMockRecorder) SaveDeadLetter(ctx, deadLetter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetter", reflect.TypeOf((*MockRepository)(nil).SaveDeadLetter), ctx, deadLetter)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// MockKnownUserRepository is a mock of KnownUserRepository interface.
+type MockKnownUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKnownUserRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockKnownUserRepositoryMockRecorder is the mock recorder for MockKnownUserRepository.
+type MockKnownUserRepositoryMockRecorder struct {
+	mock *MockKnownUserRepository
+}
+
+// NewMockKnownUserRepository creates a new mock instance.
+func NewMockKnownUserRepository(ctrl *gomock.Controller) *MockKnownUserRepository {
+	mock := &MockKnownUserRepository{ctrl: ctrl}
+	mock.recorder = &MockKnownUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKnownUserRepository) EXPECT() *MockKnownUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockKnownUserRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockKnownUserRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockKnownUserRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockKnownUserRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockKnownUserRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockKnownUserRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// MockInactivityRepository is a mock of InactivityRepository interface.
+type MockInactivityRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockInactivityRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockInactivityRepositoryMockRecorder is the mock recorder for MockInactivityRepository.
+type MockInactivityRepositoryMockRecorder struct {
+	mock *MockInactivityRepository
+}
+
+// NewMockInactivityRepository creates a new mock instance.
+func NewMockInactivityRepository(ctrl *gomock.Controller) *MockInactivityRepository {
+	mock := &MockInactivityRepository{ctrl: ctrl}
+	mock.recorder = &MockInactivityRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInactivityRepository) EXPECT() *MockInactivityRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeactivateIdleUsers mocks base method.
+func (m *MockInactivityRepository) DeactivateIdleUsers(ctx context.Context, idleSince time.Time) ([]*UserAnalytics, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateIdleUsers", ctx, idleSince)
+	ret0, _ := ret[0].([]*UserAnalytics)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeactivateIdleUsers indicates an expected call of DeactivateIdleUsers.
+func (mr *MockInactivityRepositoryMockRecorder) DeactivateIdleUsers(ctx, idleSince any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateIdleUsers", reflect.TypeOf((*MockInactivityRepository)(nil).DeactivateIdleUsers), ctx, idleSince)
+}
+
+// MockTweetEngagementRepository is a mock of TweetEngagementRepository interface.
+type MockTweetEngagementRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTweetEngagementRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTweetEngagementRepositoryMockRecorder is the mock recorder for MockTweetEngagementRepository.
+type MockTweetEngagementRepositoryMockRecorder struct {
+	mock *MockTweetEngagementRepository
+}
+
+// NewMockTweetEngagementRepository creates a new mock instance.
+func NewMockTweetEngagementRepository(ctrl *gomock.Controller) *MockTweetEngagementRepository {
+	mock := &MockTweetEngagementRepository{ctrl: ctrl}
+	mock.recorder = &MockTweetEngagementRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTweetEngagementRepository) EXPECT() *MockTweetEngagementRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetTweetEngagements mocks base method.
+func (m *MockTweetEngagementRepository) GetTweetEngagements(ctx context.Context, since time.Time) ([]*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetEngagements", ctx, since)
+	ret0, _ := ret[0].([]*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetEngagements indicates an expected call of GetTweetEngagements.
+func (mr *MockTweetEngagementRepositoryMockRecorder) GetTweetEngagements(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetEngagements", reflect.TypeOf((*MockTweetEngagementRepository)(nil).GetTweetEngagements), ctx, since)
+}
+
+// MockHashtagTrendRepository is a mock of HashtagTrendRepository interface.
+type MockHashtagTrendRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHashtagTrendRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockHashtagTrendRepositoryMockRecorder is the mock recorder for MockHashtagTrendRepository.
+type MockHashtagTrendRepositoryMockRecorder struct {
+	mock *MockHashtagTrendRepository
+}
+
+// NewMockHashtagTrendRepository creates a new mock instance.
+func NewMockHashtagTrendRepository(ctrl *gomock.Controller) *MockHashtagTrendRepository {
+	mock := &MockHashtagTrendRepository{ctrl: ctrl}
+	mock.recorder = &MockHashtagTrendRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHashtagTrendRepository) EXPECT() *MockHashtagTrendRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteHashtagBucketsBefore mocks base method.
+func (m *MockHashtagTrendRepository) DeleteHashtagBucketsBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteHashtagBucketsBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteHashtagBucketsBefore indicates an expected call of DeleteHashtagBucketsBefore.
+func (mr *MockHashtagTrendRepositoryMockRecorder) DeleteHashtagBucketsBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteHashtagBucketsBefore", reflect.TypeOf((*MockHashtagTrendRepository)(nil).DeleteHashtagBucketsBefore), ctx, before)
+}
+
+// GetHashtagCounts mocks base method.
+func (m *MockHashtagTrendRepository) GetHashtagCounts(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHashtagCounts", ctx, windowStart, baselineStart)
+	ret0, _ := ret[0].([]*HashtagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHashtagCounts indicates an expected call of GetHashtagCounts.
+func (mr *MockHashtagTrendRepositoryMockRecorder) GetHashtagCounts(ctx, windowStart, baselineStart any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHashtagCounts", reflect.TypeOf((*MockHashtagTrendRepository)(nil).GetHashtagCounts), ctx, windowStart, baselineStart)
+}
+
+// MockActivityMetricsRepository is a mock of ActivityMetricsRepository interface.
+type MockActivityMetricsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockActivityMetricsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockActivityMetricsRepositoryMockRecorder is the mock recorder for MockActivityMetricsRepository.
+type MockActivityMetricsRepositoryMockRecorder struct {
+	mock *MockActivityMetricsRepository
+}
+
+// NewMockActivityMetricsRepository creates a new mock instance.
+func NewMockActivityMetricsRepository(ctrl *gomock.Controller) *MockActivityMetricsRepository {
+	mock := &MockActivityMetricsRepository{ctrl: ctrl}
+	mock.recorder = &MockActivityMetricsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockActivityMetricsRepository) EXPECT() *MockActivityMetricsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AggregateDailyMetrics mocks base method.
+func (m *MockActivityMetricsRepository) AggregateDailyMetrics(ctx context.Context, from, to time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateDailyMetrics", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AggregateDailyMetrics indicates an expected call of AggregateDailyMetrics.
+func (mr *MockActivityMetricsRepositoryMockRecorder) AggregateDailyMetrics(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateDailyMetrics", reflect.TypeOf((*MockActivityMetricsRepository)(nil).AggregateDailyMetrics), ctx, from, to)
+}
+
+// GetDailyMetrics mocks base method.
+func (m *MockActivityMetricsRepository) GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyMetrics", ctx, metric, from, to)
+	ret0, _ := ret[0].([]*DailyMetric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyMetrics indicates an expected call of GetDailyMetrics.
+func (mr *MockActivityMetricsRepositoryMockRecorder) GetDailyMetrics(ctx, metric, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyMetrics", reflect.TypeOf((*MockActivityMetricsRepository)(nil).GetDailyMetrics), ctx, metric, from, to)
+}
+
+// GetFirstEventTime mocks base method.
+func (m *MockActivityMetricsRepository) GetFirstEventTime(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstEventTime", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstEventTime indicates an expected call of GetFirstEventTime.
+func (mr *MockActivityMetricsRepositoryMockRecorder) GetFirstEventTime(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstEventTime", reflect.TypeOf((*MockActivityMetricsRepository)(nil).GetFirstEventTime), ctx)
+}
+
+// GetLastAggregatedDay mocks base method.
+func (m *MockActivityMetricsRepository) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAggregatedDay", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAggregatedDay indicates an expected call of GetLastAggregatedDay.
+func (mr *MockActivityMetricsRepositoryMockRecorder) GetLastAggregatedDay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAggregatedDay", reflect.TypeOf((*MockActivityMetricsRepository)(nil).GetLastAggregatedDay), ctx)
+}
+
+// MockTweetStatsRepository is a mock of TweetStatsRepository interface.
+type MockTweetStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTweetStatsRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTweetStatsRepositoryMockRecorder is the mock recorder for MockTweetStatsRepository.
+type MockTweetStatsRepositoryMockRecorder struct {
+	mock *MockTweetStatsRepository
+}
+
+// NewMockTweetStatsRepository creates a new mock instance.
+func NewMockTweetStatsRepository(ctrl *gomock.Controller) *MockTweetStatsRepository {
+	mock := &MockTweetStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockTweetStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTweetStatsRepository) EXPECT() *MockTweetStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetTweetDailyStats mocks base method.
+func (m *MockTweetStatsRepository) GetTweetDailyStats(ctx context.Context, tweetIDs []string, from time.Time) ([]*TweetDailyStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetDailyStats", ctx, tweetIDs, from)
+	ret0, _ := ret[0].([]*TweetDailyStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetDailyStats indicates an expected call of GetTweetDailyStats.
+func (mr *MockTweetStatsRepositoryMockRecorder) GetTweetDailyStats(ctx, tweetIDs, from any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetDailyStats", reflect.TypeOf((*MockTweetStatsRepository)(nil).GetTweetDailyStats), ctx, tweetIDs, from)
+}
+
+// GetTweetEngagement mocks base method.
+func (m *MockTweetStatsRepository) GetTweetEngagement(ctx context.Context, tweetID string) (*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetEngagement", ctx, tweetID)
+	ret0, _ := ret[0].(*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetEngagement indicates an expected call of GetTweetEngagement.
+func (mr *MockTweetStatsRepositoryMockRecorder) GetTweetEngagement(ctx, tweetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetEngagement", reflect.TypeOf((*MockTweetStatsRepository)(nil).GetTweetEngagement), ctx, tweetID)
+}
+
+// GetUserTweetEngagements mocks base method.
+func (m *MockTweetStatsRepository) GetUserTweetEngagements(ctx context.Context, userID string, limit, offset int) ([]*TweetEngagement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTweetEngagements", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]*TweetEngagement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTweetEngagements indicates an expected call of GetUserTweetEngagements.
+func (mr *MockTweetStatsRepositoryMockRecorder) GetUserTweetEngagements(ctx, userID, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTweetEngagements", reflect.TypeOf((*MockTweetStatsRepository)(nil).GetUserTweetEngagements), ctx, userID, limit, offset)
+}
+
+// MockDeadLetterRepository is a mock of DeadLetterRepository interface.
+type MockDeadLetterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDeadLetterRepositoryMockRecorder is the mock recorder for MockDeadLetterRepository.
+type MockDeadLetterRepositoryMockRecorder struct {
+	mock *MockDeadLetterRepository
+}
+
+// NewMockDeadLetterRepository creates a new mock instance.
+func NewMockDeadLetterRepository(ctrl *gomock.Controller) *MockDeadLetterRepository {
+	mock := &MockDeadLetterRepository{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterRepository) EXPECT() *MockDeadLetterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountDeadLetters mocks base method.
+func (m *MockDeadLetterRepository) CountDeadLetters(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeadLetters", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeadLetters indicates an expected call of CountDeadLetters.
+func (mr *MockDeadLetterRepositoryMockRecorder) CountDeadLetters(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeadLetters", reflect.TypeOf((*MockDeadLetterRepository)(nil).CountDeadLetters), ctx)
+}
+
+// DeleteDeadLetter mocks base method.
+func (m *MockDeadLetterRepository) DeleteDeadLetter(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeadLetter", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeadLetter indicates an expected call of DeleteDeadLetter.
+func (mr *MockDeadLetterRepositoryMockRecorder) DeleteDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeadLetter", reflect.TypeOf((*MockDeadLetterRepository)(nil).DeleteDeadLetter), ctx, id)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockDeadLetterRepository) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockDeadLetterRepositoryMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockDeadLetterRepository)(nil).GetDeadLetter), ctx, id)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockDeadLetterRepository) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", ctx, limit, offset)
+	ret0, _ := ret[0].([]*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockDeadLetterRepositoryMockRecorder) GetDeadLetters(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockDeadLetterRepository)(nil).GetDeadLetters), ctx, limit, offset)
+}
+
+// SaveDeadLetter mocks base method.
+func (m *MockDeadLetterRepository) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetter", ctx, deadLetter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetter indicates an expected call of SaveDeadLetter.
+func (mr *MockDeadLetterRepositoryMockRecorder) SaveDeadLetter(ctx, deadLetter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetter", reflect.TypeOf((*MockDeadLetterRepository)(nil).SaveDeadLetter), ctx, deadLetter)
+}
+
+// MockReplayRepository is a mock of ReplayRepository interface.
+type MockReplayRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReplayRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockReplayRepositoryMockRecorder is the mock recorder for MockReplayRepository.
+type MockReplayRepositoryMockRecorder struct {
+	mock *MockReplayRepository
+}
+
+// NewMockReplayRepository creates a new mock instance.
+func NewMockReplayRepository(ctrl *gomock.Controller) *MockReplayRepository {
+	mock := &MockReplayRepository{ctrl: ctrl}
+	mock.recorder = &MockReplayRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReplayRepository) EXPECT() *MockReplayRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AggregateDailyMetrics mocks base method.
+func (m *MockReplayRepository) AggregateDailyMetrics(ctx context.Context, from, to time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AggregateDailyMetrics", ctx, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AggregateDailyMetrics indicates an expected call of AggregateDailyMetrics.
+func (mr *MockReplayRepositoryMockRecorder) AggregateDailyMetrics(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AggregateDailyMetrics", reflect.TypeOf((*MockReplayRepository)(nil).AggregateDailyMetrics), ctx, from, to)
+}
+
+// CountEvents mocks base method.
+func (m *MockReplayRepository) CountEvents(ctx context.Context, until time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEvents", ctx, until)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEvents indicates an expected call of CountEvents.
+func (mr *MockReplayRepositoryMockRecorder) CountEvents(ctx, until any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEvents", reflect.TypeOf((*MockReplayRepository)(nil).CountEvents), ctx, until)
+}
+
+// DeleteReplayCheckpoint mocks base method.
+func (m *MockReplayRepository) DeleteReplayCheckpoint(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReplayCheckpoint", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReplayCheckpoint indicates an expected call of DeleteReplayCheckpoint.
+func (mr *MockReplayRepositoryMockRecorder) DeleteReplayCheckpoint(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplayCheckpoint", reflect.TypeOf((*MockReplayRepository)(nil).DeleteReplayCheckpoint), ctx)
+}
+
+// GetDailyMetrics mocks base method.
+func (m *MockReplayRepository) GetDailyMetrics(ctx context.Context, metric string, from, to time.Time) ([]*DailyMetric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDailyMetrics", ctx, metric, from, to)
+	ret0, _ := ret[0].([]*DailyMetric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDailyMetrics indicates an expected call of GetDailyMetrics.
+func (mr *MockReplayRepositoryMockRecorder) GetDailyMetrics(ctx, metric, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDailyMetrics", reflect.TypeOf((*MockReplayRepository)(nil).GetDailyMetrics), ctx, metric, from, to)
+}
+
+// GetEventsAfter mocks base method.
+func (m *MockReplayRepository) GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventsAfter", ctx, timestamp, eventID, until, limit)
+	ret0, _ := ret[0].([]*Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventsAfter indicates an expected call of GetEventsAfter.
+func (mr *MockReplayRepositoryMockRecorder) GetEventsAfter(ctx, timestamp, eventID, until, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventsAfter", reflect.TypeOf((*MockReplayRepository)(nil).GetEventsAfter), ctx, timestamp, eventID, until, limit)
+}
+
+// GetFirstEventTime mocks base method.
+func (m *MockReplayRepository) GetFirstEventTime(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFirstEventTime", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFirstEventTime indicates an expected call of GetFirstEventTime.
+func (mr *MockReplayRepositoryMockRecorder) GetFirstEventTime(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFirstEventTime", reflect.TypeOf((*MockReplayRepository)(nil).GetFirstEventTime), ctx)
+}
+
+// GetLastAggregatedDay mocks base method.
+func (m *MockReplayRepository) GetLastAggregatedDay(ctx context.Context) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastAggregatedDay", ctx)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastAggregatedDay indicates an expected call of GetLastAggregatedDay.
+func (mr *MockReplayRepositoryMockRecorder) GetLastAggregatedDay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastAggregatedDay", reflect.TypeOf((*MockReplayRepository)(nil).GetLastAggregatedDay), ctx)
+}
+
+// GetReplayCheckpoint mocks base method.
+func (m *MockReplayRepository) GetReplayCheckpoint(ctx context.Context) (*ReplayCheckpoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplayCheckpoint", ctx)
+	ret0, _ := ret[0].(*ReplayCheckpoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReplayCheckpoint indicates an expected call of GetReplayCheckpoint.
+func (mr *MockReplayRepositoryMockRecorder) GetReplayCheckpoint(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplayCheckpoint", reflect.TypeOf((*MockReplayRepository)(nil).GetReplayCheckpoint), ctx)
+}
+
+// LockReplay mocks base method.
+func (m *MockReplayRepository) LockReplay(ctx context.Context) (func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockReplay", ctx)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockReplay indicates an expected call of LockReplay.
+func (mr *MockReplayRepositoryMockRecorder) LockReplay(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockReplay", reflect.TypeOf((*MockReplayRepository)(nil).LockReplay), ctx)
+}
+
+// ReplayEvents mocks base method.
+func (m *MockReplayRepository) ReplayEvents(ctx context.Context, events []*Event, checkpoint *ReplayCheckpoint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayEvents", ctx, events, checkpoint)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayEvents indicates an expected call of ReplayEvents.
+func (mr *MockReplayRepositoryMockRecorder) ReplayEvents(ctx, events, checkpoint any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayEvents", reflect.TypeOf((*MockReplayRepository)(nil).ReplayEvents), ctx, events, checkpoint)
+}
+
+// ResetDerivedState mocks base method.
+func (m *MockReplayRepository) ResetDerivedState(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetDerivedState", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetDerivedState indicates an expected call of ResetDerivedState.
+func (mr *MockReplayRepositoryMockRecorder) ResetDerivedState(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetDerivedState", reflect.TypeOf((*MockReplayRepository)(nil).ResetDerivedState), ctx)
+}
+
+// MockEventRetentionRepository is a mock of EventRetentionRepository interface.
+type MockEventRetentionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRetentionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockEventRetentionRepositoryMockRecorder is the mock recorder for MockEventRetentionRepository.
+type MockEventRetentionRepositoryMockRecorder struct {
+	mock *MockEventRetentionRepository
+}
+
+// NewMockEventRetentionRepository creates a new mock instance.
+func NewMockEventRetentionRepository(ctrl *gomock.Controller) *MockEventRetentionRepository {
+	mock := &MockEventRetentionRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRetentionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRetentionRepository) EXPECT() *MockEventRetentionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DropEventPartition mocks base method.
+func (m *MockEventRetentionRepository) DropEventPartition(ctx context.Context, month time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropEventPartition", ctx, month)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropEventPartition indicates an expected call of DropEventPartition.
+func (mr *MockEventRetentionRepositoryMockRecorder) DropEventPartition(ctx, month any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropEventPartition", reflect.TypeOf((*MockEventRetentionRepository)(nil).DropEventPartition), ctx, month)
+}
+
+// EnsureEventPartitions mocks base method.
+func (m *MockEventRetentionRepository) EnsureEventPartitions(ctx context.Context, from, to time.Time) ([]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureEventPartitions", ctx, from, to)
+	ret0, _ := ret[0].([]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureEventPartitions indicates an expected call of EnsureEventPartitions.
+func (mr *MockEventRetentionRepositoryMockRecorder) EnsureEventPartitions(ctx, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureEventPartitions", reflect.TypeOf((*MockEventRetentionRepository)(nil).EnsureEventPartitions), ctx, from, to)
+}
+
+// GetEventsAfter mocks base method.
+func (m *MockEventRetentionRepository) GetEventsAfter(ctx context.Context, timestamp time.Time, eventID string, until time.Time, limit int) ([]*Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventsAfter", ctx, timestamp, eventID, until, limit)
+	ret0, _ := ret[0].([]*Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventsAfter indicates an expected call of GetEventsAfter.
+func (mr *MockEventRetentionRepositoryMockRecorder) GetEventsAfter(ctx, timestamp, eventID, until, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventsAfter", reflect.TypeOf((*MockEventRetentionRepository)(nil).GetEventsAfter), ctx, timestamp, eventID, until, limit)
+}
+
+// MockSpamRepository is a mock of SpamRepository interface.
+type MockSpamRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSpamRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockSpamRepositoryMockRecorder is the mock recorder for MockSpamRepository.
+type MockSpamRepositoryMockRecorder struct {
+	mock *MockSpamRepository
+}
+
+// NewMockSpamRepository creates a new mock instance.
+func NewMockSpamRepository(ctrl *gomock.Controller) *MockSpamRepository {
+	mock := &MockSpamRepository{ctrl: ctrl}
+	mock.recorder = &MockSpamRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSpamRepository) EXPECT() *MockSpamRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountUserFlags mocks base method.
+func (m *MockSpamRepository) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserFlags", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserFlags indicates an expected call of CountUserFlags.
+func (mr *MockSpamRepositoryMockRecorder) CountUserFlags(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserFlags", reflect.TypeOf((*MockSpamRepository)(nil).CountUserFlags), ctx, filter)
+}
+
+// FlagUser mocks base method.
+func (m *MockSpamRepository) FlagUser(ctx context.Context, flag *UserFlag) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagUser", ctx, flag)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlagUser indicates an expected call of FlagUser.
+func (mr *MockSpamRepositoryMockRecorder) FlagUser(ctx, flag any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagUser", reflect.TypeOf((*MockSpamRepository)(nil).FlagUser), ctx, flag)
+}
+
+// GetUserEvents mocks base method.
+func (m *MockSpamRepository) GetUserEvents(ctx context.Context, userID string, eventTypes []string, since time.Time) ([]*Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserEvents", ctx, userID, eventTypes, since)
+	ret0, _ := ret[0].([]*Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserEvents indicates an expected call of GetUserEvents.
+func (mr *MockSpamRepositoryMockRecorder) GetUserEvents(ctx, userID, eventTypes, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserEvents", reflect.TypeOf((*MockSpamRepository)(nil).GetUserEvents), ctx, userID, eventTypes, since)
+}
+
+// GetUserFlag mocks base method.
+func (m *MockSpamRepository) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlag", ctx, id)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlag indicates an expected call of GetUserFlag.
+func (mr *MockSpamRepositoryMockRecorder) GetUserFlag(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlag", reflect.TypeOf((*MockSpamRepository)(nil).GetUserFlag), ctx, id)
+}
+
+// GetUserFlags mocks base method.
+func (m *MockSpamRepository) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlags", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlags indicates an expected call of GetUserFlags.
+func (mr *MockSpamRepositoryMockRecorder) GetUserFlags(ctx, filter, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlags", reflect.TypeOf((*MockSpamRepository)(nil).GetUserFlags), ctx, filter, limit, offset)
+}
+
+// ResolveUserFlag mocks base method.
+func (m *MockSpamRepository) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveUserFlag", ctx, id, status)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveUserFlag indicates an expected call of ResolveUserFlag.
+func (mr *MockSpamRepositoryMockRecorder) ResolveUserFlag(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveUserFlag", reflect.TypeOf((*MockSpamRepository)(nil).ResolveUserFlag), ctx, id, status)
+}
@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/lucas-soria/microblogging/pkg/events"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -148,6 +150,51 @@ func TestInMemoryRepository_ProcessEvent(t *testing.T) {
 	}
 }
 
+func TestInMemoryRepository_ProcessEventDuplicate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-1", Hashtags: []string{"go"}, Timestamp: now},
+		{ID: "event-2", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet-1", Timestamp: now},
+		// Redeliveries of the same events are skipped
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-1", Hashtags: []string{"go"}, Timestamp: now},
+		{ID: "event-2", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet-1", Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	engagements, err := repo.GetTweetEngagements(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, engagements, 1)
+	assert.Equal(t, int64(1), engagements[0].Likes)
+
+	counts, err := repo.GetHashtagCounts(ctx, now.Add(-time.Hour), now.Add(-HashtagRetention))
+	require.NoError(t, err)
+	assert.Equal(t, []*HashtagCount{{Tag: "go", WindowCount: 1, BaselineCount: 0}}, counts)
+}
+
+func TestProcessedEvents(t *testing.T) {
+	processed := newProcessedEvents(2)
+
+	assert.True(t, processed.add("event-1"))
+	assert.True(t, processed.add("event-2"))
+	assert.False(t, processed.add("event-1"))
+
+	// The window is bounded, the least recently seen ID is evicted
+	assert.True(t, processed.add("event-3"))
+	assert.Len(t, processed.ids, 2)
+	assert.Equal(t, 2, processed.order.Len())
+	assert.False(t, processed.add("event-1"))
+	assert.True(t, processed.add("event-2"))
+
+	processed.remove("event-2")
+	processed.remove("missing")
+	assert.True(t, processed.add("event-2"))
+}
+
 func TestInMemoryRepository_GetAllUserAnalytics(t *testing.T) {
 	ctx := context.Background()
 
@@ -190,7 +237,7 @@ func TestInMemoryRepository_GetAllUserAnalytics(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.expectations()
 
-			result, err := repo.GetAllUserAnalytics(ctx)
+			result, err := repo.GetAllUserAnalytics(ctx, UserAnalyticsFilter{})
 
 			assert.Condition(t, assertUserAnalyticsEqual(tc.want.analytics, result))
 			assert.Equal(t, tc.want.err, err)
@@ -198,6 +245,78 @@ func TestInMemoryRepository_GetAllUserAnalytics(t *testing.T) {
 	}
 }
 
+func TestInMemoryRepository_GetAllUserAnalyticsFilter(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	repo.analytics["user1"] = &UserAnalytics{Handler: "user1", IsInfluencer: true, IsActive: true, UpdatedAt: now.Add(-3 * time.Hour)}
+	repo.analytics["user2"] = &UserAnalytics{Handler: "user2", IsActive: true, UpdatedAt: now.Add(-time.Hour)}
+	repo.analytics["user3"] = &UserAnalytics{Handler: "user3", IsActive: false, UpdatedAt: now.Add(-time.Hour)}
+	repo.analytics["user4"] = &UserAnalytics{Handler: "user4", IsActive: true, UpdatedAt: now.Add(-48 * time.Hour)}
+
+	active, inactive, influencer := true, false, true
+
+	tt := []struct {
+		name   string
+		filter UserAnalyticsFilter
+		want   []string
+	}{
+		{
+			name:   "sorted by handler by default",
+			filter: UserAnalyticsFilter{},
+			want:   []string{"user1", "user2", "user3", "user4"},
+		},
+		{
+			name:   "active users",
+			filter: UserAnalyticsFilter{IsActive: &active},
+			want:   []string{"user1", "user2", "user4"},
+		},
+		{
+			name:   "inactive users",
+			filter: UserAnalyticsFilter{IsActive: &inactive},
+			want:   []string{"user3"},
+		},
+		{
+			name:   "influencers",
+			filter: UserAnalyticsFilter{IsInfluencer: &influencer},
+			want:   []string{"user1"},
+		},
+		{
+			name:   "updated range",
+			filter: UserAnalyticsFilter{UpdatedSince: now.Add(-24 * time.Hour), UpdatedUntil: now.Add(-2 * time.Hour)},
+			want:   []string{"user1"},
+		},
+		{
+			name:   "sorted by updated at descending, ties by handler",
+			filter: UserAnalyticsFilter{SortBy: SortByUpdatedAt, Descending: true},
+			want:   []string{"user3", "user2", "user1", "user4"},
+		},
+		{
+			name: "after the cursor",
+			filter: UserAnalyticsFilter{
+				SortBy:     SortByUpdatedAt,
+				Descending: true,
+				After:      &UserAnalyticsCursor{SortBy: SortByUpdatedAt, Descending: true, Handler: "user3", Time: now.Add(-time.Hour)},
+				Limit:      2,
+			},
+			want: []string{"user2", "user1"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := repo.GetAllUserAnalytics(ctx, tc.filter)
+			require.NoError(t, err)
+
+			handlers := []string{}
+			for _, analytics := range result {
+				handlers = append(handlers, analytics.Handler)
+			}
+			assert.Equal(t, tc.want, handlers)
+		})
+	}
+}
+
 func TestInMemoryRepository_DeleteUserAnalytics(t *testing.T) {
 	ctx := context.Background()
 
@@ -240,14 +359,15 @@ func TestInMemoryRepository_UserBecomesInfluencer(t *testing.T) {
 
 	repo := NewInMemoryRepository()
 
-	userID := "user1"
+	userID := "user1-id"
 
 	// Process exactly the threshold number of tweets (100)
 	for i := 0; i < 100; i++ {
 		event := &Event{
 			ID:        "event-" + string(rune(i)),
 			EventType: "tweet_created",
-			Handler:   userID,
+			UserID:    userID,
+			Handler:   "user1",
 			Timestamp: time.Now(),
 		}
 		err := repo.ProcessEvent(ctx, event)
@@ -263,7 +383,8 @@ func TestInMemoryRepository_UserBecomesInfluencer(t *testing.T) {
 	event := &Event{
 		ID:        "event-101",
 		EventType: "tweet_created",
-		Handler:   userID,
+		UserID:    userID,
+		Handler:   "user1",
 		Timestamp: time.Now(),
 	}
 	err = repo.ProcessEvent(ctx, event)
@@ -274,3 +395,437 @@ func TestInMemoryRepository_UserBecomesInfluencer(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, analytics.IsInfluencer)
 }
+
+func TestInMemoryRepository_DeactivateIdleUsers(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Now()
+	idleSince := now.Add(-24 * time.Hour)
+
+	tt := []struct {
+		name         string
+		expectations func(repo *InMemoryRepository)
+		want         []string
+	}{
+		{
+			name:         "empty repository deactivates nobody",
+			expectations: func(repo *InMemoryRepository) {},
+			want:         []string{},
+		},
+		{
+			name: "idle user is deactivated",
+			expectations: func(repo *InMemoryRepository) {
+				repo.analytics["user1-id"] = &UserAnalytics{UserID: "user1-id", Handler: "user1", IsActive: true, UpdatedAt: now.Add(-48 * time.Hour)}
+			},
+			want: []string{"user1"},
+		},
+		{
+			name: "recently updated user stays active",
+			expectations: func(repo *InMemoryRepository) {
+				repo.analytics["user1-id"] = &UserAnalytics{UserID: "user1-id", Handler: "user1", IsActive: true, UpdatedAt: now}
+			},
+			want: []string{},
+		},
+		{
+			name: "user with a recent event stays active",
+			expectations: func(repo *InMemoryRepository) {
+				repo.analytics["user1-id"] = &UserAnalytics{UserID: "user1-id", Handler: "user1", IsActive: true, UpdatedAt: now.Add(-48 * time.Hour)}
+				repo.events = append(repo.events, &Event{ID: "event-1", EventType: "timeline_viewed", UserID: "user1-id", Handler: "user1", Timestamp: now})
+			},
+			want: []string{},
+		},
+		{
+			name: "inactive user is not deactivated again",
+			expectations: func(repo *InMemoryRepository) {
+				repo.analytics["user1-id"] = &UserAnalytics{UserID: "user1-id", Handler: "user1", IsActive: false, UpdatedAt: now.Add(-48 * time.Hour)}
+			},
+			want: []string{},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewInMemoryRepository()
+			tc.expectations(repo)
+
+			deactivated, err := repo.DeactivateIdleUsers(ctx, idleSince)
+			require.NoError(t, err)
+
+			handlers := []string{}
+			for _, analytics := range deactivated {
+				assert.False(t, analytics.IsActive)
+				assert.False(t, repo.analytics[analytics.UserID].IsActive)
+				handlers = append(handlers, analytics.Handler)
+			}
+			assert.Equal(t, tc.want, handlers)
+		})
+	}
+}
+
+func TestInMemoryRepository_GetTweetEngagements(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "old", Timestamp: now.Add(-48 * time.Hour)},
+		{ID: "event-2", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "recent", Timestamp: now},
+		{ID: "event-3", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "recent", Timestamp: now},
+		{ID: "event-4", EventType: events.TypeTweetLiked, UserID: "user3-id", Handler: "user3", TweetID: "recent", Timestamp: now},
+		{ID: "event-5", EventType: events.TypeTweetReplied, UserID: "user2-id", Handler: "user2", TweetID: "recent", Timestamp: now},
+		// Engagement on tweets created before tracking started is ignored
+		{ID: "event-6", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "untracked", Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	engagements, err := repo.GetTweetEngagements(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, engagements, 1)
+	assert.Equal(t, "recent", engagements[0].TweetID)
+	assert.Equal(t, "user1", engagements[0].Handler)
+	assert.Equal(t, int64(2), engagements[0].Likes)
+	assert.Equal(t, int64(1), engagements[0].Replies)
+}
+
+func TestInMemoryRepository_UserAnalyticsCounters(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now().Truncate(time.Second)
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet1", Timestamp: now.Add(-3 * time.Hour)},
+		{ID: "event-2", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet2", Timestamp: now.Add(-2 * time.Hour)},
+		{ID: "event-3", EventType: events.TypeTimelineViewed, UserID: "user1-id", Handler: "user1", TweetIDs: []string{"tweet9"}, Timestamp: now.Add(-90 * time.Minute)},
+		{ID: "event-4", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet1", Timestamp: now.Add(-time.Hour)},
+		{ID: "event-5", EventType: events.TypeTweetReplied, UserID: "user3-id", Handler: "user3", TweetID: "tweet2", Timestamp: now.Add(-time.Hour)},
+		{ID: "event-6", EventType: events.TypeUserFollowed, UserID: "user2-id", Handler: "user2", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: now.Add(-time.Hour)},
+		{ID: "event-7", EventType: events.TypeUserFollowed, UserID: "user3-id", Handler: "user3", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: now.Add(-time.Hour)},
+		{ID: "event-8", EventType: events.TypeUserUnfollowed, UserID: "user3-id", Handler: "user3", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: now.Add(-30 * time.Minute)},
+		// A user followed before it has any activity
+		{ID: "event-9", EventType: events.TypeUserFollowed, UserID: "user1-id", Handler: "user1", TargetUserID: "user4-id", TargetHandler: "user4", Timestamp: now.Add(-time.Minute)},
+		// Events that arrive late do not move the last activity back
+		{ID: "event-10", EventType: events.TypeProfileViewed, UserID: "user1-id", Handler: "user1", Timestamp: now.Add(-4 * time.Hour)},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	user1, err := repo.GetUserAnalytics(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), user1.TweetCount)
+	assert.Equal(t, int64(1), user1.FollowerCount)
+	assert.Equal(t, likeWeight+replyWeight, user1.EngagementScore)
+	require.NotNil(t, user1.LastActivityAt)
+	assert.Equal(t, now.Add(-time.Minute), *user1.LastActivityAt)
+	require.NotNil(t, user1.LastTimelineViewAt)
+	assert.Equal(t, now.Add(-90*time.Minute), *user1.LastTimelineViewAt)
+
+	user3, err := repo.GetUserAnalytics(ctx, "user3-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), user3.TweetCount)
+	assert.Equal(t, float64(0), user3.EngagementScore)
+	require.NotNil(t, user3.LastActivityAt)
+	assert.Equal(t, now.Add(-30*time.Minute), *user3.LastActivityAt)
+	assert.Nil(t, user3.LastTimelineViewAt)
+
+	user4, err := repo.GetUserAnalytics(ctx, "user4-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user4.FollowerCount)
+	assert.False(t, user4.IsActive)
+	assert.Nil(t, user4.LastActivityAt)
+
+	// Unfollowing more than followed does not make the count negative
+	require.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-11", EventType: events.TypeUserUnfollowed, UserID: "user5-id", Handler: "user5", TargetUserID: "user4-id", TargetHandler: "user4", Timestamp: now}))
+	require.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-12", EventType: events.TypeUserUnfollowed, UserID: "user6-id", Handler: "user6", TargetUserID: "user4-id", TargetHandler: "user4", Timestamp: now}))
+	user4, err = repo.GetUserAnalytics(ctx, "user4-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), user4.FollowerCount)
+}
+
+func TestInMemoryRepository_HashtagBuckets(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now().Truncate(HashtagBucketSize)
+	windowStart := now.Add(-time.Hour)
+	baselineStart := now.Add(-HashtagRetention)
+
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Hashtags: []string{"Go", "go", "rust"}, Timestamp: now},
+		{ID: "event-2", EventType: events.TypeTweetCreated, UserID: "user2-id", Handler: "user2", Hashtags: []string{"go"}, Timestamp: now.Add(-2 * time.Hour)},
+		{ID: "event-3", EventType: events.TypeTweetCreated, UserID: "user2-id", Handler: "user2", Hashtags: []string{"zig"}, Timestamp: now.Add(-3 * time.Hour)},
+		{ID: "event-4", EventType: events.TypeTweetCreated, UserID: "user3-id", Handler: "user3", Hashtags: []string{"go"}, Timestamp: now.Add(-48 * time.Hour)},
+		// Only tweets are counted
+		{ID: "event-5", EventType: events.TypeTimelineViewed, UserID: "user3-id", Handler: "user3", Hashtags: []string{"go"}, Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	counts, err := repo.GetHashtagCounts(ctx, windowStart, baselineStart)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*HashtagCount{
+		{Tag: "go", WindowCount: 1, BaselineCount: 1},
+		{Tag: "rust", WindowCount: 1, BaselineCount: 0},
+	}, counts)
+
+	deleted, err := repo.DeleteHashtagBucketsBefore(ctx, baselineStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Len(t, repo.hashtags, 4)
+}
+
+func TestInMemoryRepository_AggregateDailyMetrics(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	day1 := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	day5 := day1.AddDate(0, 0, 4)
+	day10 := day1.AddDate(0, 0, 9)
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Timestamp: day1.Add(time.Hour)},
+		{ID: "event-2", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Timestamp: day1.Add(2 * time.Hour)},
+		{ID: "event-3", EventType: events.TypeTimelineViewed, UserID: "user2-id", Handler: "user2", Timestamp: day1.Add(3 * time.Hour)},
+		{ID: "event-4", EventType: events.TypeTimelineViewed, UserID: "user3-id", Handler: "user3", Timestamp: day5.Add(time.Hour)},
+		{ID: "event-5", EventType: events.TypeTimelineViewed, UserID: "user1-id", Handler: "user1", Timestamp: day10.Add(time.Hour)},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	first, err := repo.GetFirstEventTime(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, day1.Add(time.Hour), first)
+
+	last, err := repo.GetLastAggregatedDay(ctx)
+	require.NoError(t, err)
+	assert.True(t, last.IsZero())
+
+	// Aggregating the same days twice gives the same metrics
+	for i := 0; i < 2; i++ {
+		require.NoError(t, repo.AggregateDailyMetrics(ctx, day1, day10.Add(day)))
+	}
+
+	last, err = repo.GetLastAggregatedDay(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, day10, last)
+
+	tt := []struct {
+		metric string
+		date   time.Time
+		want   int64
+	}{
+		{metric: MetricDAU, date: day1, want: 2},
+		{metric: MetricTweets, date: day1, want: 2},
+		{metric: MetricTimelineViews, date: day1, want: 1},
+		{metric: MetricDAU, date: day1.Add(day), want: 0},
+		{metric: MetricWAU, date: day5, want: 3},
+		{metric: MetricWAU, date: day10, want: 2},
+		{metric: MetricMAU, date: day10, want: 3},
+	}
+	for _, tc := range tt {
+		metrics, err := repo.GetDailyMetrics(ctx, tc.metric, tc.date, tc.date)
+		require.NoError(t, err)
+		require.Len(t, metrics, 1)
+		assert.Equal(t, tc.want, metrics[0].Value, "%s on %s", tc.metric, tc.date.Format(time.DateOnly))
+	}
+
+	metrics, err := repo.GetDailyMetrics(ctx, MetricDAU, day1, day10.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Len(t, metrics, 10)
+}
+
+func TestInMemoryRepository_TweetStats(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "author-id", Handler: "author", TweetID: "tweet1", Timestamp: yesterday},
+		{ID: "event-2", EventType: events.TypeTweetCreated, UserID: "author-id", Handler: "author", TweetID: "tweet2", Timestamp: now},
+		{ID: "event-3", EventType: events.TypeTimelineViewed, UserID: "user1-id", Handler: "user1", TweetIDs: []string{"tweet1", "tweet1"}, Timestamp: yesterday},
+		{ID: "event-4", EventType: events.TypeTimelineViewed, UserID: "user1-id", Handler: "user1", TweetIDs: []string{"tweet1", "tweet2"}, Timestamp: now},
+		{ID: "event-5", EventType: events.TypeTimelineViewed, UserID: "user2-id", Handler: "user2", TweetIDs: []string{"tweet1"}, Timestamp: now},
+		{ID: "event-6", EventType: events.TypeProfileViewed, UserID: "user2-id", Handler: "user2", TweetID: "tweet1", Timestamp: now},
+		{ID: "event-7", EventType: events.TypeProfileViewed, UserID: "user2-id", Handler: "user2", Timestamp: now},
+		{ID: "event-8", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet1", Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	engagement, err := repo.GetTweetEngagement(ctx, "tweet1")
+	require.NoError(t, err)
+	assert.Equal(t, "author", engagement.Handler)
+
+	_, err = repo.GetTweetEngagement(ctx, "missing")
+	assert.ErrorIs(t, err, ErrTweetNotFound)
+
+	engagements, err := repo.GetUserTweetEngagements(ctx, "author-id", 1, 0)
+	require.NoError(t, err)
+	require.Len(t, engagements, 1)
+	assert.Equal(t, "tweet2", engagements[0].TweetID)
+	engagements, err = repo.GetUserTweetEngagements(ctx, "author-id", 10, 5)
+	require.NoError(t, err)
+	assert.Empty(t, engagements)
+
+	daily, err := repo.GetTweetDailyStats(ctx, []string{"tweet1"}, yesterday)
+	require.NoError(t, err)
+	require.Len(t, daily, 2)
+	assert.Equal(t, truncateDay(yesterday), daily[0].Day)
+	assert.Equal(t, int64(1), daily[0].Impressions)
+	assert.Equal(t, int64(2), daily[1].Impressions)
+	assert.Equal(t, int64(1), daily[1].ProfileViews)
+	assert.Equal(t, int64(1), daily[1].Likes)
+	assert.NotEmpty(t, daily[1].Viewers)
+
+	daily, err = repo.GetTweetDailyStats(ctx, []string{"tweet1", "tweet2"}, now)
+	require.NoError(t, err)
+	assert.Len(t, daily, 2)
+}
+
+func TestInMemoryRepository_DeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now()
+	require.NoError(t, repo.SaveDeadLetter(ctx, &DeadLetter{ID: "old", Topic: "TweetPosted", Reason: "invalid event", Attempts: 1, CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, repo.SaveDeadLetter(ctx, &DeadLetter{ID: "new", Topic: "TweetEngaged", Reason: "timeout", Attempts: 3, CreatedAt: now}))
+
+	// Saving an existing dead letter updates it
+	require.NoError(t, repo.SaveDeadLetter(ctx, &DeadLetter{ID: "old", Topic: "TweetPosted", Reason: "database error", Attempts: 2, CreatedAt: now.Add(-time.Hour)}))
+
+	count, err := repo.CountDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	deadLetters, err := repo.GetDeadLetters(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	assert.Equal(t, "new", deadLetters[0].ID)
+	assert.Equal(t, "old", deadLetters[1].ID)
+
+	deadLetters, err = repo.GetDeadLetters(ctx, 10, 2)
+	require.NoError(t, err)
+	assert.Empty(t, deadLetters)
+
+	deadLetter, err := repo.GetDeadLetter(ctx, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "database error", deadLetter.Reason)
+	assert.Equal(t, 2, deadLetter.Attempts)
+
+	require.NoError(t, repo.DeleteDeadLetter(ctx, "old"))
+	_, err = repo.GetDeadLetter(ctx, "old")
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+	assert.ErrorIs(t, repo.DeleteDeadLetter(ctx, "old"), ErrDeadLetterNotFound)
+}
+
+func TestInMemoryRepository_UserFlags(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	// A second occurrence of an open flag updates it
+	flag := &UserFlag{ID: "flag-1", UserID: "user1-id", Handler: "user1", Rule: RuleDuplicateTweets, Reason: "posted 3 identical tweets"}
+	created, err := repo.FlagUser(ctx, flag)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	again := &UserFlag{ID: "flag-2", UserID: "user1-id", Handler: "user1", Rule: RuleDuplicateTweets, Reason: "posted 4 identical tweets"}
+	created, err = repo.FlagUser(ctx, again)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "flag-1", again.ID)
+	assert.Equal(t, 2, again.Occurrences)
+	assert.Equal(t, "posted 4 identical tweets", again.Reason)
+
+	created, err = repo.FlagUser(ctx, &UserFlag{ID: "flag-3", UserID: "user2-id", Handler: "user2", Rule: RuleFollowChurn, Reason: "churn"})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	count, err := repo.CountUserFlags(ctx, UserFlagFilter{Status: UserFlagStatusOpen})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Once resolved, the rule flags the user again
+	resolved, err := repo.ResolveUserFlag(ctx, "flag-1", UserFlagStatusConfirmed)
+	require.NoError(t, err)
+	assert.Equal(t, UserFlagStatusConfirmed, resolved.Status)
+
+	_, err = repo.ResolveUserFlag(ctx, "flag-1", UserFlagStatusDismissed)
+	assert.ErrorIs(t, err, ErrUserFlagResolved)
+	_, err = repo.ResolveUserFlag(ctx, "missing", UserFlagStatusDismissed)
+	assert.ErrorIs(t, err, ErrUserFlagNotFound)
+
+	created, err = repo.FlagUser(ctx, &UserFlag{ID: "flag-4", UserID: "user1-id", Handler: "user1", Rule: RuleDuplicateTweets, Reason: "posted 3 identical tweets"})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	flags, err := repo.GetUserFlags(ctx, UserFlagFilter{UserID: "user1-id"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.ElementsMatch(t, []string{"flag-1", "flag-4"}, []string{flags[0].ID, flags[1].ID})
+
+	flag, err = repo.GetUserFlag(ctx, "flag-3")
+	require.NoError(t, err)
+	assert.Equal(t, RuleFollowChurn, flag.Rule)
+	_, err = repo.GetUserFlag(ctx, "missing")
+	assert.ErrorIs(t, err, ErrUserFlagNotFound)
+}
+
+func TestInMemoryRepository_RenameUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	day := time.Date(2025, 8, 9, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.SaveKnownUser(ctx, "user-id", "old"))
+	require.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user-id", Handler: "old", TweetID: "tweet-1", Timestamp: day.Add(10 * time.Hour)}))
+	require.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-2", EventType: events.TypeUserFollowed, UserID: "user2-id", Handler: "user2", TargetUserID: "user-id", TargetHandler: "old", Timestamp: day.Add(10 * time.Hour)}))
+	_, err := repo.FlagUser(ctx, &UserFlag{ID: "flag-1", UserID: "user-id", Handler: "old", Rule: RuleDuplicateTweets, Reason: "posted 3 identical tweets"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+	// Renaming the user again is a no-op
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+
+	analytics, err := repo.GetUserAnalytics(ctx, "user-id")
+	require.NoError(t, err)
+	assert.Equal(t, "new", analytics.Handler)
+	assert.Equal(t, int64(1), analytics.TweetCount)
+	assert.Equal(t, int64(1), analytics.FollowerCount)
+	engagement, err := repo.GetTweetEngagement(ctx, "tweet-1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", engagement.Handler)
+	flag, err := repo.GetUserFlag(ctx, "flag-1")
+	require.NoError(t, err)
+	assert.Equal(t, "new", flag.Handler)
+	id, err := repo.GetKnownUserID(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "user-id", id)
+
+	// The events keep the handler they were sent with, the replayed analytics show the current one
+	userEvents, err := repo.GetUserEvents(ctx, "user-id", []string{events.TypeTweetCreated}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, userEvents, 1)
+	assert.Equal(t, "old", userEvents[0].Handler)
+	require.NoError(t, repo.ResetDerivedState(ctx))
+	require.NoError(t, repo.ReplayEvents(ctx, userEvents, &ReplayCheckpoint{}))
+	analytics, err = repo.GetUserAnalytics(ctx, "user-id")
+	require.NoError(t, err)
+	assert.Equal(t, "new", analytics.Handler)
+
+	// A stale change does not show a previous handler again
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "newer", "newest"))
+	require.NoError(t, repo.SaveKnownUser(ctx, "user-id", "newer"))
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+	analytics, err = repo.GetUserAnalytics(ctx, "user-id")
+	require.NoError(t, err)
+	assert.Equal(t, "newer", analytics.Handler)
+
+	// The deleted users are no longer known, so another user can take their handler
+	require.NoError(t, repo.DeleteUserAnalytics(ctx, "user-id"))
+	id, err = repo.GetKnownUserID(ctx, "newer")
+	require.NoError(t, err)
+	assert.Empty(t, id)
+}
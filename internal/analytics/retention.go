@@ -0,0 +1,161 @@
+package analytics
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// eventPartitionsAhead is how many months after the current one have their partition created in advance
+	eventPartitionsAhead = 1
+	// archiveBatchSize is the number of events read at once while archiving a partition
+	archiveBatchSize = 1000
+)
+
+// truncateMonth returns the start of the UTC month of t
+func truncateMonth(t time.Time) time.Time {
+	year, month, _ := t.UTC().Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EventPartitionsManager periodically creates the monthly events partitions, and archives and drops the expired ones
+type EventPartitionsManager struct {
+	repository EventRetentionRepository
+	retention  time.Duration
+	archiveDir string
+	interval   time.Duration
+}
+
+// NewEventPartitionsManager creates a new event partitions manager, a retention of zero keeps the events forever
+func NewEventPartitionsManager(repository EventRetentionRepository, retention time.Duration, archiveDir string, interval time.Duration) *EventPartitionsManager {
+	return &EventPartitionsManager{
+		repository: repository,
+		retention:  retention,
+		archiveDir: archiveDir,
+		interval:   interval,
+	}
+}
+
+// Run manages the partitions at startup and on every interval until the context is cancelled
+func (manager *EventPartitionsManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(manager.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := manager.Manage(ctx); err != nil {
+			log.Printf("failed to manage event partitions: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Manage creates the current and next months partitions, then archives and drops the expired ones and counts them
+func (manager *EventPartitionsManager) Manage(ctx context.Context) (int, error) {
+	now := time.Now()
+	currentMonth := truncateMonth(now)
+
+	months, err := manager.repository.EnsureEventPartitions(ctx, currentMonth, currentMonth.AddDate(0, eventPartitionsAhead, 0))
+	if err != nil {
+		return 0, err
+	}
+	if manager.retention <= 0 {
+		return 0, nil
+	}
+
+	dropped := 0
+	for _, month := range months {
+		if month.AddDate(0, 1, 0).After(now.Add(-manager.retention)) {
+			break // The months are sorted, the next ones are within the retention too
+		}
+
+		// The partition is only dropped once its events are safely archived
+		path, err := manager.archivePartition(ctx, month)
+		if err != nil {
+			return dropped, err
+		}
+		if err := manager.repository.DropEventPartition(ctx, month); err != nil {
+			return dropped, err
+		}
+		log.Printf("archived events of %s to %s", month.Format("2006-01"), path)
+		dropped++
+	}
+
+	return dropped, nil
+}
+
+// archivePartition writes the events of a month as gzip compressed NDJSON to a new archive file and returns its path
+func (manager *EventPartitionsManager) archivePartition(ctx context.Context, month time.Time) (string, error) {
+	if err := os.MkdirAll(manager.archiveDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(manager.archiveDir, ".events-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive file: %w", err)
+	}
+	defer os.Remove(file.Name()) // Nothing to remove once it is renamed
+	defer file.Close()
+
+	writer := gzip.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+
+	// Read the events of the month in batches, in timestamp order
+	lastTimestamp, lastEventID := month, ""
+	until := month.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	for {
+		batch, err := manager.repository.GetEventsAfter(ctx, lastTimestamp, lastEventID, until, archiveBatchSize)
+		if err != nil {
+			return "", err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for _, event := range batch {
+			if err := encoder.Encode(event); err != nil {
+				return "", fmt.Errorf("failed to write archive: %w", err)
+			}
+		}
+		lastTimestamp, lastEventID = batch[len(batch)-1].Timestamp, batch[len(batch)-1].ID
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	path, err := manager.archivePath(month)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write archive: %w", err)
+	}
+	return path, nil
+}
+
+// archivePath returns the first unused path for the archive of a month
+func (manager *EventPartitionsManager) archivePath(month time.Time) (string, error) {
+	name := "events-" + month.Format("2006-01")
+	path := filepath.Join(manager.archiveDir, name+".ndjson.gz")
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path, nil
+		} else if err != nil {
+			return "", fmt.Errorf("failed to check archive file: %w", err)
+		}
+		path = filepath.Join(manager.archiveDir, fmt.Sprintf("%s-%d.ndjson.gz", name, i))
+	}
+}
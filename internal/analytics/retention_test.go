@@ -0,0 +1,192 @@
+package analytics
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readArchive decodes the events of a gzip compressed NDJSON archive
+func readArchive(t *testing.T, path string) []*Event {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	archived := []*Event{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		archived = append(archived, &event)
+	}
+	require.NoError(t, scanner.Err())
+	return archived
+}
+
+func TestTruncateMonth(t *testing.T) {
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), truncateMonth(time.Date(2025, 8, 31, 23, 59, 59, 0, time.UTC)))
+	assert.Equal(t, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), truncateMonth(time.Date(2025, 8, 31, 22, 0, 0, 0, time.FixedZone("UTC-3", -3*60*60))))
+}
+
+func TestEventPartitionsManager_Manage(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	archiveDir := t.TempDir()
+
+	now := time.Now().UTC()
+	currentMonth := truncateMonth(now)
+	oldMonth := currentMonth.AddDate(0, -6, 0)
+	olderMonth := currentMonth.AddDate(0, -7, 0)
+
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-1", Hashtags: []string{"go"}, Timestamp: olderMonth.Add(time.Hour)},
+		{ID: "event-2", EventType: events.TypeTimelineViewed, UserID: "user2-id", Handler: "user2", TweetIDs: []string{"tweet-1"}, Timestamp: oldMonth.Add(2 * time.Hour)},
+		{ID: "event-3", EventType: events.TypeTweetLiked, UserID: "user2-id", Handler: "user2", TweetID: "tweet-1", Timestamp: oldMonth.Add(time.Hour)},
+		{ID: "event-4", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-2", Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	manager := NewEventPartitionsManager(repo, 90*24*time.Hour, archiveDir, time.Hour)
+
+	dropped, err := manager.Manage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+
+	// The old events are archived in timestamp order
+	archived := readArchive(t, filepath.Join(archiveDir, "events-"+olderMonth.Format("2006-01")+".ndjson.gz"))
+	require.Len(t, archived, 1)
+	assert.Equal(t, "event-1", archived[0].ID)
+	assert.Equal(t, []string{"go"}, archived[0].Hashtags)
+
+	archived = readArchive(t, filepath.Join(archiveDir, "events-"+oldMonth.Format("2006-01")+".ndjson.gz"))
+	require.Len(t, archived, 2)
+	assert.Equal(t, "event-3", archived[0].ID)
+	assert.Equal(t, "event-2", archived[1].ID)
+	assert.Equal(t, []string{"tweet-1"}, archived[1].TweetIDs)
+
+	// Only the events within the retention are kept
+	kept, err := repo.GetEventsAfter(ctx, time.Time{}, "", now, 10)
+	require.NoError(t, err)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "event-4", kept[0].ID)
+
+	months, err := repo.EnsureEventPartitions(ctx, currentMonth, currentMonth)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Time{currentMonth, currentMonth.AddDate(0, 1, 0)}, months)
+
+	// Nothing else to drop
+	dropped, err = manager.Manage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	// The events of the dropped months that arrive late or are redelivered are refused, the archived counters count them
+	err = repo.ProcessEvent(ctx, &Event{ID: "event-5", EventType: events.TypeTweetLiked, UserID: "user3-id", Handler: "user3", TweetID: "tweet-1", Timestamp: oldMonth.Add(3 * time.Hour)})
+	assert.ErrorIs(t, err, ErrEventExpired)
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+	assert.ErrorIs(t, repo.ProcessEvent(ctx, processed[0]), ErrEventExpired)
+
+	count, err := repo.CountEvents(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(archiveDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestEventPartitionsManager_ArchivePath(t *testing.T) {
+	archiveDir := t.TempDir()
+	manager := NewEventPartitionsManager(NewInMemoryRepository(), 90*24*time.Hour, archiveDir, time.Hour)
+	month := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// A month archived again does not overwrite the previous archive
+	for _, want := range []string{"events-2025-03.ndjson.gz", "events-2025-03-1.ndjson.gz", "events-2025-03-2.ndjson.gz"} {
+		path, err := manager.archivePath(month)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(archiveDir, want), path)
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+	}
+}
+
+func TestEventPartitionsManager_ManageWithoutRetention(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	archiveDir := t.TempDir()
+
+	old := time.Now().AddDate(-2, 0, 0)
+	require.NoError(t, repo.ProcessEvent(ctx, &Event{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Timestamp: old}))
+
+	dropped, err := NewEventPartitionsManager(repo, 0, archiveDir, time.Hour).Manage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	count, err := repo.CountEvents(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	entries, err := os.ReadDir(archiveDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestEventPartitionsManager_ReplayKeepsDroppedCounters(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+
+	now := time.Now().UTC()
+	oldMonth := truncateMonth(now).AddDate(0, -6, 0)
+
+	processed := []*Event{
+		{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-1", Timestamp: oldMonth.Add(time.Hour)},
+		{ID: "event-2", EventType: events.TypeUserFollowed, UserID: "user2-id", Handler: "user2", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: oldMonth.Add(2 * time.Hour)},
+		{ID: "event-3", EventType: events.TypeUserFollowed, UserID: "user3-id", Handler: "user3", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: oldMonth.Add(3 * time.Hour)},
+		{ID: "event-4", EventType: events.TypeUserUnfollowed, UserID: "user3-id", Handler: "user3", TargetUserID: "user1-id", TargetHandler: "user1", Timestamp: now},
+		{ID: "event-5", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: "tweet-2", Timestamp: now},
+	}
+	for _, event := range processed {
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+	}
+
+	dropped, err := NewEventPartitionsManager(repo, 90*24*time.Hour, t.TempDir(), time.Hour).Manage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped)
+
+	// The replay counts the dropped events from the archived counters, and the kept ones again
+	require.NoError(t, NewReplayer(repo, 10).Replay(ctx, false))
+
+	analytics, err := repo.GetUserAnalytics(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), analytics.TweetCount)
+	assert.Equal(t, int64(1), analytics.FollowerCount)
+	assert.Equal(t, oldMonth.Add(time.Hour), analytics.CreatedAt)
+
+	// The archived counters show the current handler of the user, and are deleted with their analytics
+	require.NoError(t, repo.RenameUser(ctx, "user1-id", "user1", "user4"))
+	require.NoError(t, NewReplayer(repo, 10).Replay(ctx, true))
+	analytics, err = repo.GetUserAnalytics(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.Equal(t, "user4", analytics.Handler)
+	assert.Equal(t, int64(2), analytics.TweetCount)
+	assert.Equal(t, int64(1), analytics.FollowerCount)
+
+	require.NoError(t, repo.DeleteUserAnalytics(ctx, "user1-id"))
+	require.NoError(t, repo.ResetDerivedState(ctx))
+	_, err = repo.GetUserAnalytics(ctx, "user1-id")
+	assert.ErrorIs(t, err, ErrUserAnalyticsNotFound)
+}
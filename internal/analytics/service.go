@@ -2,7 +2,14 @@ package analytics
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+
+	"github.com/google/uuid"
 )
 
 //go:generate mockgen -source=service.go -destination=service_mock.go -package=analytics
@@ -11,21 +18,74 @@ import (
 type Service interface {
 	// User Analytics
 	GetUserAnalytics(ctx context.Context, userID string) (*UserAnalytics, error)
-	GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error)
+	GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, cursor string) (*UserAnalyticsPage, error)
+	ExportUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, write func([]*UserAnalytics) error) error
 	DeleteUserAnalytics(ctx context.Context, userID string) error
 
+	// User Handle Changes
+	RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error
+
 	// Event Processing
 	ProcessEvent(ctx context.Context, event *Event) error
+
+	// Hashtag Trends
+	GetTrends(ctx context.Context, window time.Duration, limit int) ([]*Trend, error)
+
+	// Activity Metrics
+	GetMetrics(ctx context.Context, metric, granularity string, from, to time.Time) ([]*MetricPoint, error)
+
+	// Tweet Stats
+	GetTweetStats(ctx context.Context, tweetID string, days int) (*TweetStats, error)
+	GetUserTweetStats(ctx context.Context, userID string, days, limit, offset int) ([]*TweetStats, error)
+
+	// Dead Letters
+	SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error
+	GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error)
+	GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error)
+	CountDeadLetters(ctx context.Context) (int64, error)
+	ReplayDeadLetter(ctx context.Context, id string) error
+
+	// User Flags
+	GetUserFlag(ctx context.Context, id string) (*UserFlag, error)
+	GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error)
+	CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error)
+	ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error)
+}
+
+// ErrInvalidTrendsWindow is returned when the trends window is out of range
+var ErrInvalidTrendsWindow = fmt.Errorf("window must be between %s and %s", HashtagBucketSize, HashtagRetention/2)
+
+// ErrInvalidEvent is returned when an event can never be processed, so retrying it is pointless
+var ErrInvalidEvent = errors.New("invalid event")
+
+// ErrInvalidMetricsQuery is returned when the metric, the granularity or the range of days of a metrics query is not valid
+var ErrInvalidMetricsQuery = errors.New("invalid metrics query")
+
+// ErrInvalidUserFlagStatus is returned when filtering by an unknown user flag status, or resolving a user flag with a status other than confirmed or dismissed
+var ErrInvalidUserFlagStatus = errors.New("invalid user flag status")
+
+// isValidEventType reports whether eventType is an event type the analytics service processes
+func isValidEventType(eventType string) bool {
+	switch eventType {
+	case events.TypeTweetCreated, events.TypeTimelineViewed, events.TypeTweetLiked, events.TypeTweetReplied, events.TypeProfileViewed,
+		events.TypeUserFollowed, events.TypeUserUnfollowed:
+		return true
+	}
+	return false
 }
 
 type service struct {
 	repository Repository
+	detector   *SpamDetector
+	users      *userIDs
 }
 
-// NewService creates a new analytics service
-func NewService(repository Repository) Service {
+// NewService creates a new analytics service, the detector and the users client are optional
+func NewService(repository Repository, detector *SpamDetector, users UsersClient) Service {
 	return &service{
 		repository: repository,
+		detector:   detector,
+		users:      &userIDs{repository: repository, users: users},
 	}
 }
 
@@ -35,12 +95,92 @@ func (service *service) GetUserAnalytics(ctx context.Context, userID string) (*U
 		return nil, errors.New("user ID is required")
 	}
 
-	return service.repository.GetUserAnalytics(ctx, userID)
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return nil, ErrUserAnalyticsNotFound
+	}
+	return service.repository.GetUserAnalytics(ctx, id)
 }
 
-// GetAllUserAnalytics retrieves analytics for all users
-func (service *service) GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error) {
-	return service.repository.GetAllUserAnalytics(ctx)
+// validateUserAnalyticsFilter validates the filters and the sort, and sets the default sort
+func validateUserAnalyticsFilter(filter *UserAnalyticsFilter) error {
+	if filter.SortBy == "" {
+		filter.SortBy = SortByHandler
+	}
+	if filter.SortBy != SortByHandler && filter.SortBy != SortByCreatedAt && filter.SortBy != SortByUpdatedAt {
+		return fmt.Errorf("%w: unknown sort field %q", ErrInvalidUserAnalyticsQuery, filter.SortBy)
+	}
+	if !filter.UpdatedSince.IsZero() && !filter.UpdatedUntil.IsZero() && !filter.UpdatedUntil.After(filter.UpdatedSince) {
+		return fmt.Errorf("%w: updated until must be after updated since", ErrInvalidUserAnalyticsQuery)
+	}
+	return nil
+}
+
+// GetAllUserAnalytics retrieves the page after the cursor of the analytics of the users that pass the filter
+func (service *service) GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, cursor string) (*UserAnalyticsPage, error) {
+	if err := validateUserAnalyticsFilter(&filter); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultUserAnalyticsLimit
+	}
+	filter.Limit = min(filter.Limit, maxUserAnalyticsLimit)
+
+	filter.After = nil
+	if cursor != "" {
+		after, err := decodeUserAnalyticsCursor(cursor, filter.SortBy, filter.Descending)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// Get one more to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	users, err := service.repository.GetAllUserAnalytics(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &UserAnalyticsPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		page.NextCursor, err = encodeUserAnalyticsCursor(newUserAnalyticsCursor(page.Users[limit-1], filter.SortBy, filter.Descending))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// ExportUserAnalytics reads the analytics of all the users that pass the filter, sorted by it, in batches passed to write
+func (service *service) ExportUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, write func([]*UserAnalytics) error) error {
+	if err := validateUserAnalyticsFilter(&filter); err != nil {
+		return err
+	}
+
+	filter.After = nil
+	filter.Limit = exportBatchSize
+	for {
+		users, err := service.repository.GetAllUserAnalytics(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := write(users); err != nil {
+			return err
+		}
+		if len(users) < exportBatchSize {
+			return nil
+		}
+		filter.After = newUserAnalyticsCursor(users[len(users)-1], filter.SortBy, filter.Descending)
+	}
 }
 
 // DeleteUserAnalytics deletes analytics data for a specific user
@@ -49,22 +189,385 @@ func (service *service) DeleteUserAnalytics(ctx context.Context, userID string)
 		return errors.New("user ID is required")
 	}
 
-	return service.repository.DeleteUserAnalytics(ctx, userID)
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return ErrUserAnalyticsNotFound
+	}
+	return service.repository.DeleteUserAnalytics(ctx, id)
+}
+
+// RenameUser shows the analytics of a user that changed their handle with the new one
+func (service *service) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	if userID == "" || oldHandler == "" || newHandler == "" {
+		return errors.New("user ID is required")
+	}
+
+	return service.repository.RenameUser(ctx, userID, oldHandler, newHandler)
 }
 
-// ProcessEvent processes an analytics event
+// ProcessEvent processes an analytics event, events already processed are skipped
 func (service *service) ProcessEvent(ctx context.Context, event *Event) error {
 	if event == nil {
-		return errors.New("event cannot be nil")
+		return fmt.Errorf("%w: event cannot be nil", ErrInvalidEvent)
+	}
+
+	// The ID is assigned by the producer, so redeliveries of the event can be skipped
+	if event.ID == "" {
+		return fmt.Errorf("%w: event ID is required", ErrInvalidEvent)
 	}
 
 	if event.Handler == "" {
-		return errors.New("user ID is required in event")
+		return fmt.Errorf("%w: user ID is required in event", ErrInvalidEvent)
 	}
 
 	if event.EventType == "" {
-		return errors.New("event type is required")
+		return fmt.Errorf("%w: event type is required", ErrInvalidEvent)
+	}
+
+	// The timestamp is part of the key of the stored events, so a redelivery must keep it to be skipped
+	if event.Timestamp.IsZero() {
+		return fmt.Errorf("%w: event timestamp is required", ErrInvalidEvent)
+	}
+
+	if !isValidEventType(event.EventType) {
+		return fmt.Errorf("%w: unknown event type %q", ErrInvalidEvent, event.EventType)
+	}
+
+	if (event.EventType == events.TypeUserFollowed || event.EventType == events.TypeUserUnfollowed) && event.TargetHandler == "" {
+		return fmt.Errorf("%w: target user ID is required in %s event", ErrInvalidEvent, event.EventType)
+	}
+
+	user, err := service.resolve(ctx, event.Handler)
+	if err != nil {
+		return err
+	}
+	event.UserID, event.Handler = user.ID, user.Handler
+	if event.TargetHandler != "" {
+		target, err := service.resolve(ctx, event.TargetHandler)
+		if err != nil {
+			return err
+		}
+		event.TargetUserID, event.TargetHandler = target.ID, target.Handler
 	}
 
 	return service.repository.ProcessEvent(ctx, event)
 }
+
+// resolve resolves the user of an event, an event of a user that does not exist can never be processed
+func (service *service) resolve(ctx context.Context, handler string) (*User, error) {
+	user, err := service.users.resolve(ctx, handler)
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, fmt.Errorf("%w: user %s not found", ErrInvalidEvent, handler)
+	}
+	return user, err
+}
+
+// GetTrends retrieves the hashtags used within the window, sorted by their velocity spike against the baseline
+func (service *service) GetTrends(ctx context.Context, window time.Duration, limit int) ([]*Trend, error) {
+	// The baseline must be at least as long as the window
+	if window < HashtagBucketSize || window > HashtagRetention/2 {
+		return nil, ErrInvalidTrendsWindow
+	}
+	if limit <= 0 {
+		limit = 10 // Default limit
+	}
+
+	now := time.Now()
+	windowStart := now.Add(-window).Truncate(HashtagBucketSize)
+	baselineStart := now.Add(-HashtagRetention).Truncate(HashtagBucketSize)
+
+	counts, err := service.repository.GetHashtagCounts(ctx, windowStart, baselineStart)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := rankTrends(counts, now.Sub(windowStart), windowStart.Sub(baselineStart))
+	if len(trends) > limit {
+		trends = trends[:limit]
+	}
+
+	return trends, nil
+}
+
+// GetMetrics retrieves the values of an activity metric between from and to, one point per period of the granularity
+func (service *service) GetMetrics(ctx context.Context, metric, granularity string, from, to time.Time) ([]*MetricPoint, error) {
+	if !isValidMetric(metric) {
+		return nil, fmt.Errorf("%w: unknown metric %q", ErrInvalidMetricsQuery, metric)
+	}
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	if granularity != GranularityDay && granularity != GranularityWeek && granularity != GranularityMonth {
+		return nil, fmt.Errorf("%w: unknown granularity %q", ErrInvalidMetricsQuery, granularity)
+	}
+
+	from, to = truncateDay(from), truncateDay(to)
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidMetricsQuery)
+	}
+	if to.Sub(from) > maxMetricsRange {
+		return nil, fmt.Errorf("%w: range must not be longer than %d days", ErrInvalidMetricsQuery, maxMetricsRange/day)
+	}
+
+	dailyMetrics, err := service.repository.GetDailyMetrics(ctx, metric, from, to)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[time.Time]int64, len(dailyMetrics))
+	for _, dailyMetric := range dailyMetrics {
+		values[truncateDay(dailyMetric.Day)] = dailyMetric.Value
+	}
+
+	// Group the days into periods, the days not aggregated count as zero
+	points := []*MetricPoint{}
+	var days int
+	for date := from; !date.After(to); date = date.Add(day) {
+		start := periodStart(date, granularity)
+		if len(points) == 0 || !points[len(points)-1].Date.Equal(start) {
+			if len(points) > 0 && !summedMetrics[metric] {
+				points[len(points)-1].Value /= float64(days)
+			}
+			points = append(points, &MetricPoint{Date: start})
+			days = 0
+		}
+		points[len(points)-1].Value += float64(values[date])
+		days++
+	}
+	if !summedMetrics[metric] {
+		points[len(points)-1].Value /= float64(days)
+	}
+
+	return points, nil
+}
+
+// GetTweetStats retrieves the stats of a tweet over the last days (30 by default, at most 90), with a daily breakdown
+func (service *service) GetTweetStats(ctx context.Context, tweetID string, days int) (*TweetStats, error) {
+	if tweetID == "" {
+		return nil, errors.New("tweet ID is required")
+	}
+
+	engagement, err := service.repository.GetTweetEngagement(ctx, tweetID)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := tweetStatsRange(engagement, days)
+	daily, err := service.repository.GetTweetDailyStats(ctx, []string{tweetID}, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTweetStats(engagement, daily, from, to)
+}
+
+// GetUserTweetStats retrieves the stats of the tweets of a user over the last days, newest first
+func (service *service) GetUserTweetStats(ctx context.Context, userID string, days, limit, offset int) ([]*TweetStats, error) {
+	if userID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	// Set default values if not provided
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return []*TweetStats{}, nil
+	}
+
+	engagements, err := service.repository.GetUserTweetEngagements(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(engagements) == 0 {
+		return []*TweetStats{}, nil
+	}
+
+	// Fetch the stats of all the tweets at once, since the earliest day needed
+	tweetIDs := make([]string, 0, len(engagements))
+	earliest := time.Now()
+	for _, engagement := range engagements {
+		tweetIDs = append(tweetIDs, engagement.TweetID)
+		if from, _ := tweetStatsRange(engagement, days); from.Before(earliest) {
+			earliest = from
+		}
+	}
+	daily, err := service.repository.GetTweetDailyStats(ctx, tweetIDs, earliest)
+	if err != nil {
+		return nil, err
+	}
+	dailyByTweet := map[string][]*TweetDailyStats{}
+	for _, stats := range daily {
+		dailyByTweet[stats.TweetID] = append(dailyByTweet[stats.TweetID], stats)
+	}
+
+	result := make([]*TweetStats, 0, len(engagements))
+	for _, engagement := range engagements {
+		from, to := tweetStatsRange(engagement, days)
+		stats, err := buildTweetStats(engagement, dailyByTweet[engagement.TweetID], from, to)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, stats)
+	}
+
+	return result, nil
+}
+
+// SaveDeadLetter stores a message that could not be processed, an ID is assigned to new dead letters
+func (service *service) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	if deadLetter == nil {
+		return errors.New("dead letter cannot be nil")
+	}
+	if deadLetter.ID == "" {
+		deadLetter.ID = uuid.New().String()
+	}
+
+	return service.repository.SaveDeadLetter(ctx, deadLetter)
+}
+
+// GetDeadLetter retrieves a dead letter
+func (service *service) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	if id == "" {
+		return nil, errors.New("dead letter ID is required")
+	}
+
+	return service.repository.GetDeadLetter(ctx, id)
+}
+
+// GetDeadLetters retrieves the dead letters, newest first
+func (service *service) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	// Set default values if not provided
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return service.repository.GetDeadLetters(ctx, limit, offset)
+}
+
+// CountDeadLetters counts the dead letters
+func (service *service) CountDeadLetters(ctx context.Context) (int64, error) {
+	return service.repository.CountDeadLetters(ctx)
+}
+
+// ReplayDeadLetter processes a dead letter again and deletes it, or keeps it with the new reason if it fails again
+func (service *service) ReplayDeadLetter(ctx context.Context, id string) error {
+	deadLetter, err := service.GetDeadLetter(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	var event Event
+	if err = json.Unmarshal([]byte(deadLetter.Payload), &event); err != nil {
+		err = fmt.Errorf("%w: failed to decode payload: %v", ErrInvalidEvent, err)
+	} else if err = service.ProcessEvent(ctx, &event); err == nil && service.detector != nil {
+		err = service.detector.Check(ctx, &event)
+	}
+	if errors.Is(err, ErrReplayInProgress) {
+		// Not a failure of the event, it can be replayed once the events replay is done
+		return err
+	}
+	if err != nil {
+		deadLetter.Reason = err.Error()
+		deadLetter.Attempts++
+		if saveErr := service.repository.SaveDeadLetter(ctx, deadLetter); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+
+	return service.repository.DeleteDeadLetter(ctx, id)
+}
+
+// GetUserFlag retrieves a user flag
+func (service *service) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	if id == "" {
+		return nil, errors.New("user flag ID is required")
+	}
+
+	return service.repository.GetUserFlag(ctx, id)
+}
+
+// GetUserFlags retrieves the user flags that pass the filter, newest first
+func (service *service) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	if err := validateUserFlagFilter(filter); err != nil {
+		return nil, err
+	}
+
+	// Set default values if not provided
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	known, err := service.knownFlaggedUser(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	if !known {
+		return []*UserFlag{}, nil
+	}
+	return service.repository.GetUserFlags(ctx, filter, limit, offset)
+}
+
+// CountUserFlags counts the user flags that pass the filter
+func (service *service) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	if err := validateUserFlagFilter(filter); err != nil {
+		return 0, err
+	}
+
+	known, err := service.knownFlaggedUser(ctx, &filter)
+	if err != nil || !known {
+		return 0, err
+	}
+	return service.repository.CountUserFlags(ctx, filter)
+}
+
+// knownFlaggedUser sets the user ID of a user flag filter by its handler, and reports whether the user is known
+func (service *service) knownFlaggedUser(ctx context.Context, filter *UserFlagFilter) (bool, error) {
+	if filter.Handler == "" {
+		return true, nil
+	}
+	id, err := service.users.known(ctx, filter.Handler)
+	if err != nil {
+		return false, err
+	}
+	filter.UserID = id
+	return id != "", nil
+}
+
+// ResolveUserFlag confirms or dismisses an open user flag. Once resolved, the rule can flag the user again.
+func (service *service) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	if id == "" {
+		return nil, errors.New("user flag ID is required")
+	}
+	if status != UserFlagStatusConfirmed && status != UserFlagStatusDismissed {
+		return nil, fmt.Errorf("%w: %q, it must be %s or %s", ErrInvalidUserFlagStatus, status, UserFlagStatusConfirmed, UserFlagStatusDismissed)
+	}
+
+	return service.repository.ResolveUserFlag(ctx, id, status)
+}
+
+// validateUserFlagFilter validates the status of a user flag filter
+func validateUserFlagFilter(filter UserFlagFilter) error {
+	switch filter.Status {
+	case "", UserFlagStatusOpen, UserFlagStatusConfirmed, UserFlagStatusDismissed:
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidUserFlagStatus, filter.Status)
+}
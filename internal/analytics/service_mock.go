@@ -12,6 +12,7 @@ package analytics
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,36 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// CountDeadLetters mocks base method.
+func (m *MockService) CountDeadLetters(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeadLetters", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeadLetters indicates an expected call of CountDeadLetters.
+func (mr *MockServiceMockRecorder) CountDeadLetters(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeadLetters", reflect.TypeOf((*MockService)(nil).CountDeadLetters), ctx)
+}
+
+// CountUserFlags mocks base method.
+func (m *MockService) CountUserFlags(ctx context.Context, filter UserFlagFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserFlags", ctx, filter)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserFlags indicates an expected call of CountUserFlags.
+func (mr *MockServiceMockRecorder) CountUserFlags(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserFlags", reflect.TypeOf((*MockService)(nil).CountUserFlags), ctx, filter)
+}
+
 // DeleteUserAnalytics mocks base method.
 func (m *MockService) DeleteUserAnalytics(ctx context.Context, userID string) error {
 	m.ctrl.T.Helper()
@@ -54,19 +85,108 @@ func (mr *MockServiceMockRecorder) DeleteUserAnalytics(ctx, userID any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserAnalytics", reflect.TypeOf((*MockService)(nil).DeleteUserAnalytics), ctx, userID)
 }
 
+// ExportUserAnalytics mocks base method.
+func (m *MockService) ExportUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, write func([]*UserAnalytics) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserAnalytics", ctx, filter, write)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportUserAnalytics indicates an expected call of ExportUserAnalytics.
+func (mr *MockServiceMockRecorder) ExportUserAnalytics(ctx, filter, write any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserAnalytics", reflect.TypeOf((*MockService)(nil).ExportUserAnalytics), ctx, filter, write)
+}
+
 // GetAllUserAnalytics mocks base method.
-func (m *MockService) GetAllUserAnalytics(ctx context.Context) ([]*UserAnalytics, error) {
+func (m *MockService) GetAllUserAnalytics(ctx context.Context, filter UserAnalyticsFilter, cursor string) (*UserAnalyticsPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUserAnalytics", ctx)
-	ret0, _ := ret[0].([]*UserAnalytics)
+	ret := m.ctrl.Call(m, "GetAllUserAnalytics", ctx, filter, cursor)
+	ret0, _ := ret[0].(*UserAnalyticsPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllUserAnalytics indicates an expected call of GetAllUserAnalytics.
-func (mr *MockServiceMockRecorder) GetAllUserAnalytics(ctx any) *gomock.Call {
+func (mr *MockServiceMockRecorder) GetAllUserAnalytics(ctx, filter, cursor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUserAnalytics", reflect.TypeOf((*MockService)(nil).GetAllUserAnalytics), ctx, filter, cursor)
+}
+
+// GetDeadLetter mocks base method.
+func (m *MockService) GetDeadLetter(ctx context.Context, id string) (*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetter", ctx, id)
+	ret0, _ := ret[0].(*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetter indicates an expected call of GetDeadLetter.
+func (mr *MockServiceMockRecorder) GetDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetter", reflect.TypeOf((*MockService)(nil).GetDeadLetter), ctx, id)
+}
+
+// GetDeadLetters mocks base method.
+func (m *MockService) GetDeadLetters(ctx context.Context, limit, offset int) ([]*DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadLetters", ctx, limit, offset)
+	ret0, _ := ret[0].([]*DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeadLetters indicates an expected call of GetDeadLetters.
+func (mr *MockServiceMockRecorder) GetDeadLetters(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadLetters", reflect.TypeOf((*MockService)(nil).GetDeadLetters), ctx, limit, offset)
+}
+
+// GetMetrics mocks base method.
+func (m *MockService) GetMetrics(ctx context.Context, metric, granularity string, from, to time.Time) ([]*MetricPoint, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetrics", ctx, metric, granularity, from, to)
+	ret0, _ := ret[0].([]*MetricPoint)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetrics indicates an expected call of GetMetrics.
+func (mr *MockServiceMockRecorder) GetMetrics(ctx, metric, granularity, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockService)(nil).GetMetrics), ctx, metric, granularity, from, to)
+}
+
+// GetTrends mocks base method.
+func (m *MockService) GetTrends(ctx context.Context, window time.Duration, limit int) ([]*Trend, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrends", ctx, window, limit)
+	ret0, _ := ret[0].([]*Trend)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrends indicates an expected call of GetTrends.
+func (mr *MockServiceMockRecorder) GetTrends(ctx, window, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrends", reflect.TypeOf((*MockService)(nil).GetTrends), ctx, window, limit)
+}
+
+// GetTweetStats mocks base method.
+func (m *MockService) GetTweetStats(ctx context.Context, tweetID string, days int) (*TweetStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweetStats", ctx, tweetID, days)
+	ret0, _ := ret[0].(*TweetStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweetStats indicates an expected call of GetTweetStats.
+func (mr *MockServiceMockRecorder) GetTweetStats(ctx, tweetID, days any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUserAnalytics", reflect.TypeOf((*MockService)(nil).GetAllUserAnalytics), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweetStats", reflect.TypeOf((*MockService)(nil).GetTweetStats), ctx, tweetID, days)
 }
 
 // GetUserAnalytics mocks base method.
@@ -84,6 +204,51 @@ func (mr *MockServiceMockRecorder) GetUserAnalytics(ctx, userID any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAnalytics", reflect.TypeOf((*MockService)(nil).GetUserAnalytics), ctx, userID)
 }
 
+// GetUserFlag mocks base method.
+func (m *MockService) GetUserFlag(ctx context.Context, id string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlag", ctx, id)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlag indicates an expected call of GetUserFlag.
+func (mr *MockServiceMockRecorder) GetUserFlag(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlag", reflect.TypeOf((*MockService)(nil).GetUserFlag), ctx, id)
+}
+
+// GetUserFlags mocks base method.
+func (m *MockService) GetUserFlags(ctx context.Context, filter UserFlagFilter, limit, offset int) ([]*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFlags", ctx, filter, limit, offset)
+	ret0, _ := ret[0].([]*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFlags indicates an expected call of GetUserFlags.
+func (mr *MockServiceMockRecorder) GetUserFlags(ctx, filter, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFlags", reflect.TypeOf((*MockService)(nil).GetUserFlags), ctx, filter, limit, offset)
+}
+
+// GetUserTweetStats mocks base method.
+func (m *MockService) GetUserTweetStats(ctx context.Context, userID string, days, limit, offset int) ([]*TweetStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTweetStats", ctx, userID, days, limit, offset)
+	ret0, _ := ret[0].([]*TweetStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTweetStats indicates an expected call of GetUserTweetStats.
+func (mr *MockServiceMockRecorder) GetUserTweetStats(ctx, userID, days, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTweetStats", reflect.TypeOf((*MockService)(nil).GetUserTweetStats), ctx, userID, days, limit, offset)
+}
+
 // ProcessEvent mocks base method.
 func (m *MockService) ProcessEvent(ctx context.Context, event *Event) error {
 	m.ctrl.T.Helper()
@@ -97,3 +262,60 @@ func (mr *MockServiceMockRecorder) ProcessEvent(ctx, event any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessEvent", reflect.TypeOf((*MockService)(nil).ProcessEvent), ctx, event)
 }
+
+// RenameUser mocks base method.
+func (m *MockService) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", ctx, userID, oldHandler, newHandler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockServiceMockRecorder) RenameUser(ctx, userID, oldHandler, newHandler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockService)(nil).RenameUser), ctx, userID, oldHandler, newHandler)
+}
+
+// ReplayDeadLetter mocks base method.
+func (m *MockService) ReplayDeadLetter(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplayDeadLetter", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplayDeadLetter indicates an expected call of ReplayDeadLetter.
+func (mr *MockServiceMockRecorder) ReplayDeadLetter(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplayDeadLetter", reflect.TypeOf((*MockService)(nil).ReplayDeadLetter), ctx, id)
+}
+
+// ResolveUserFlag mocks base method.
+func (m *MockService) ResolveUserFlag(ctx context.Context, id, status string) (*UserFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveUserFlag", ctx, id, status)
+	ret0, _ := ret[0].(*UserFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolveUserFlag indicates an expected call of ResolveUserFlag.
+func (mr *MockServiceMockRecorder) ResolveUserFlag(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveUserFlag", reflect.TypeOf((*MockService)(nil).ResolveUserFlag), ctx, id, status)
+}
+
+// SaveDeadLetter mocks base method.
+func (m *MockService) SaveDeadLetter(ctx context.Context, deadLetter *DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeadLetter", ctx, deadLetter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeadLetter indicates an expected call of SaveDeadLetter.
+func (mr *MockServiceMockRecorder) SaveDeadLetter(ctx, deadLetter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeadLetter", reflect.TypeOf((*MockService)(nil).SaveDeadLetter), ctx, deadLetter)
+}
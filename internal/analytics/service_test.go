@@ -2,22 +2,40 @@ package analytics
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/events"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+// expectKnownUsers makes the users known by their handlers, each with the ID "<handler>-id", except the "unknown" user
+func expectKnownUsers(repoMock *MockRepository) {
+	repoMock.EXPECT().GetKnownUserID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, handler string) (string, error) {
+			if handler == "unknown" {
+				return "", nil
+			}
+			return handler + "-id", nil
+		}).
+		AnyTimes()
+}
+
 func TestGetUserAnalytics(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repoMock := NewMockRepository(ctrl)
-	service := NewService(repoMock)
+	service := NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
 
 	type want struct {
 		err       error
@@ -34,7 +52,7 @@ func TestGetUserAnalytics(t *testing.T) {
 			name: "success",
 			expectations: func(userID string) {
 				repoMock.EXPECT().
-					GetUserAnalytics(gomock.Any(), userID).
+					GetUserAnalytics(gomock.Any(), userID+"-id").
 					Return(&UserAnalytics{
 						Handler:      userID,
 						IsInfluencer: true,
@@ -51,6 +69,14 @@ func TestGetUserAnalytics(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:         "user not known",
+			expectations: func(userID string) {},
+			userID:       "unknown",
+			want: want{
+				err: ErrUserAnalyticsNotFound,
+			},
+		},
 		{
 			name:         "empty user ID",
 			expectations: func(userID string) {},
@@ -63,7 +89,7 @@ func TestGetUserAnalytics(t *testing.T) {
 			name: "repository error",
 			expectations: func(userID string) {
 				repoMock.EXPECT().
-					GetUserAnalytics(gomock.Any(), userID).
+					GetUserAnalytics(gomock.Any(), userID+"-id").
 					Return(nil, errors.New("database error"))
 			},
 			userID: "test-user-1",
@@ -95,43 +121,129 @@ func TestGetAllUserAnalytics(t *testing.T) {
 	defer ctrl.Finish()
 
 	repoMock := NewMockRepository(ctrl)
-	service := NewService(repoMock)
+	service := NewService(repoMock, nil, nil)
+
+	updatedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	nextCursor, err := encodeUserAnalyticsCursor(&UserAnalyticsCursor{SortBy: SortByUpdatedAt, Descending: true, Handler: "user2", Time: updatedAt})
+	require.NoError(t, err)
 
 	type want struct {
-		analytics []*UserAnalytics
-		err       error
+		page *UserAnalyticsPage
+		err  error
 	}
 
 	tt := []struct {
 		name         string
 		expectations func()
+		filter       UserAnalyticsFilter
+		cursor       string
 		want         want
 	}{
 		{
 			name: "success",
 			expectations: func() {
 				repoMock.EXPECT().
-					GetAllUserAnalytics(gomock.Any()).
+					GetAllUserAnalytics(gomock.Any(), UserAnalyticsFilter{SortBy: SortByHandler, Limit: 101}).
 					Return([]*UserAnalytics{
 						{Handler: "user1", IsActive: true},
 						{Handler: "user2", IsInfluencer: true},
 					}, nil)
 			},
+			filter: UserAnalyticsFilter{},
 			want: want{
-				analytics: []*UserAnalytics{
-					{Handler: "user1", IsActive: true},
-					{Handler: "user2", IsInfluencer: true},
+				page: &UserAnalyticsPage{
+					Users: []*UserAnalytics{
+						{Handler: "user1", IsActive: true},
+						{Handler: "user2", IsInfluencer: true},
+					},
 				},
 				err: nil,
 			},
 		},
+		{
+			name: "page with a next page",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), UserAnalyticsFilter{SortBy: SortByUpdatedAt, Descending: true, Limit: 3}).
+					Return([]*UserAnalytics{
+						{Handler: "user1", UpdatedAt: updatedAt.Add(time.Hour)},
+						{Handler: "user2", UpdatedAt: updatedAt},
+						{Handler: "user3", UpdatedAt: updatedAt},
+					}, nil)
+			},
+			filter: UserAnalyticsFilter{SortBy: SortByUpdatedAt, Descending: true, Limit: 2},
+			want: want{
+				page: &UserAnalyticsPage{
+					Users: []*UserAnalytics{
+						{Handler: "user1", UpdatedAt: updatedAt.Add(time.Hour)},
+						{Handler: "user2", UpdatedAt: updatedAt},
+					},
+					NextCursor: nextCursor,
+				},
+			},
+		},
+		{
+			name: "next page",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetAllUserAnalytics(gomock.Any(), UserAnalyticsFilter{
+						SortBy:     SortByUpdatedAt,
+						Descending: true,
+						After:      &UserAnalyticsCursor{SortBy: SortByUpdatedAt, Descending: true, Handler: "user2", Time: updatedAt},
+						Limit:      3,
+					}).
+					Return([]*UserAnalytics{{Handler: "user3", UpdatedAt: updatedAt}}, nil)
+			},
+			filter: UserAnalyticsFilter{SortBy: SortByUpdatedAt, Descending: true, Limit: 2},
+			cursor: nextCursor,
+			want: want{
+				page: &UserAnalyticsPage{
+					Users: []*UserAnalytics{{Handler: "user3", UpdatedAt: updatedAt}},
+				},
+			},
+		},
+		{
+			name:         "cursor of another sort",
+			expectations: func() {},
+			filter:       UserAnalyticsFilter{SortBy: SortByCreatedAt},
+			cursor:       nextCursor,
+			want: want{
+				err: fmt.Errorf("%w: cursor was created for another sort", ErrInvalidUserAnalyticsQuery),
+			},
+		},
+		{
+			name:         "malformed cursor",
+			expectations: func() {},
+			filter:       UserAnalyticsFilter{},
+			cursor:       "not a cursor",
+			want: want{
+				err: fmt.Errorf("%w: malformed cursor", ErrInvalidUserAnalyticsQuery),
+			},
+		},
+		{
+			name:         "unknown sort field",
+			expectations: func() {},
+			filter:       UserAnalyticsFilter{SortBy: "is_active"},
+			want: want{
+				err: fmt.Errorf("%w: unknown sort field %q", ErrInvalidUserAnalyticsQuery, "is_active"),
+			},
+		},
+		{
+			name:         "empty updated range",
+			expectations: func() {},
+			filter:       UserAnalyticsFilter{UpdatedSince: updatedAt, UpdatedUntil: updatedAt},
+			want: want{
+				err: fmt.Errorf("%w: updated until must be after updated since", ErrInvalidUserAnalyticsQuery),
+			},
+		},
 		{
 			name: "repository error",
 			expectations: func() {
 				repoMock.EXPECT().
-					GetAllUserAnalytics(gomock.Any()).
+					GetAllUserAnalytics(gomock.Any(), gomock.Any()).
 					Return(nil, errors.New("database error"))
 			},
+			filter: UserAnalyticsFilter{},
 			want: want{
 				err: errors.New("database error"),
 			},
@@ -141,21 +253,69 @@ func TestGetAllUserAnalytics(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.expectations()
 
-			result, err := service.GetAllUserAnalytics(ctx)
+			result, err := service.GetAllUserAnalytics(ctx, tc.filter, tc.cursor)
 
-			assert.Equal(t, tc.want.analytics, result)
+			assert.Equal(t, tc.want.page, result)
 			assert.Equal(t, tc.want.err, err)
 		})
 	}
 }
 
+func TestExportUserAnalytics(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	service := NewService(repo, nil, nil)
+
+	// More users than an export batch
+	for i := 0; i < exportBatchSize+1; i++ {
+		handler := fmt.Sprintf("user%04d", i)
+		repo.analytics[handler] = &UserAnalytics{Handler: handler, IsActive: i%2 == 0}
+	}
+
+	active := true
+	var batches, exported int
+	last := ""
+	err := service.ExportUserAnalytics(ctx, UserAnalyticsFilter{IsActive: &active}, func(users []*UserAnalytics) error {
+		batches++
+		for _, analytics := range users {
+			assert.True(t, analytics.IsActive)
+			assert.Greater(t, analytics.Handler, last)
+			last = analytics.Handler
+			exported++
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, exportBatchSize/2+1, exported)
+	assert.Equal(t, 1, batches)
+
+	exported = 0
+	err = service.ExportUserAnalytics(ctx, UserAnalyticsFilter{SortBy: SortByHandler, Descending: true}, func(users []*UserAnalytics) error {
+		exported += len(users)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, exportBatchSize+1, exported)
+
+	err = service.ExportUserAnalytics(ctx, UserAnalyticsFilter{}, func(users []*UserAnalytics) error {
+		return errors.New("broken pipe")
+	})
+	assert.EqualError(t, err, "broken pipe")
+
+	err = service.ExportUserAnalytics(ctx, UserAnalyticsFilter{SortBy: "unknown"}, func(users []*UserAnalytics) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrInvalidUserAnalyticsQuery)
+}
+
 func TestDeleteUserAnalytics(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	repoMock := NewMockRepository(ctrl)
-	service := NewService(repoMock)
+	service := NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
 
 	type want struct {
 		err error
@@ -171,12 +331,18 @@ func TestDeleteUserAnalytics(t *testing.T) {
 			name: "success",
 			expectations: func() {
 				repoMock.EXPECT().
-					DeleteUserAnalytics(gomock.Any(), "test-user-1").
+					DeleteUserAnalytics(gomock.Any(), "test-user-1-id").
 					Return(nil)
 			},
 			userID: "test-user-1",
 			want:   want{err: nil},
 		},
+		{
+			name:         "user not known",
+			expectations: func() {},
+			userID:       "unknown",
+			want:         want{err: ErrUserAnalyticsNotFound},
+		},
 		{
 			name:         "empty user ID",
 			expectations: func() {},
@@ -201,7 +367,8 @@ func TestProcessEvent(t *testing.T) {
 	defer ctrl.Finish()
 
 	repoMock := NewMockRepository(ctrl)
-	service := NewService(repoMock)
+	service := NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
 
 	now := time.Now()
 	validEvent := &Event{
@@ -221,17 +388,57 @@ func TestProcessEvent(t *testing.T) {
 			name: "success",
 			expectations: func() {
 				repoMock.EXPECT().
-					ProcessEvent(gomock.Any(), validEvent).
+					ProcessEvent(gomock.Any(), &Event{ID: "event-1", EventType: "tweet_created", UserID: "user-1-id", Handler: "user-1", Timestamp: now}).
 					Return(nil)
 			},
 			event: validEvent,
 			want:  nil,
 		},
+		{
+			name: "follow",
+			expectations: func() {
+				repoMock.EXPECT().
+					ProcessEvent(gomock.Any(), &Event{
+						ID:            "event-1",
+						EventType:     "user_followed",
+						UserID:        "user-1-id",
+						Handler:       "user-1",
+						TargetUserID:  "user-2-id",
+						TargetHandler: "user-2",
+						Timestamp:     now,
+					}).
+					Return(nil)
+			},
+			event: &Event{ID: "event-1", EventType: "user_followed", Handler: "user-1", TargetHandler: "user-2", Timestamp: now},
+			want:  nil,
+		},
+		{
+			name: "user not known yet",
+			expectations: func() {
+				userID := database.LegacyUserIDOf("unknown")
+				repoMock.EXPECT().SaveKnownUser(gomock.Any(), userID, "unknown").Return(nil)
+				repoMock.EXPECT().
+					ProcessEvent(gomock.Any(), &Event{ID: "event-1", EventType: "tweet_created", UserID: userID, Handler: "unknown", Timestamp: now}).
+					Return(nil)
+			},
+			event: &Event{ID: "event-1", EventType: "tweet_created", Handler: "unknown", Timestamp: now},
+			want:  nil,
+		},
 		{
 			name:         "nil event",
 			expectations: func() {},
 			event:        nil,
-			want:         errors.New("event cannot be nil"),
+			want:         fmt.Errorf("%w: event cannot be nil", ErrInvalidEvent),
+		},
+		{
+			name:         "missing event ID",
+			expectations: func() {},
+			event: &Event{
+				EventType: "tweet_created",
+				Handler:   "user-1",
+				Timestamp: now,
+			},
+			want: fmt.Errorf("%w: event ID is required", ErrInvalidEvent),
 		},
 		{
 			name:         "missing user ID",
@@ -241,7 +448,7 @@ func TestProcessEvent(t *testing.T) {
 				EventType: "tweet_created",
 				Timestamp: now,
 			},
-			want: errors.New("user ID is required in event"),
+			want: fmt.Errorf("%w: user ID is required in event", ErrInvalidEvent),
 		},
 		{
 			name:         "missing event type",
@@ -251,7 +458,39 @@ func TestProcessEvent(t *testing.T) {
 				Handler:   "user-1",
 				Timestamp: now,
 			},
-			want: errors.New("event type is required"),
+			want: fmt.Errorf("%w: event type is required", ErrInvalidEvent),
+		},
+		{
+			name:         "missing timestamp",
+			expectations: func() {},
+			event: &Event{
+				ID:        "event-1",
+				EventType: "tweet_created",
+				Handler:   "user-1",
+			},
+			want: fmt.Errorf("%w: event timestamp is required", ErrInvalidEvent),
+		},
+		{
+			name:         "unknown event type",
+			expectations: func() {},
+			event: &Event{
+				ID:        "event-1",
+				EventType: "tweet_bookmarked",
+				Handler:   "user-1",
+				Timestamp: now,
+			},
+			want: fmt.Errorf("%w: unknown event type %q", ErrInvalidEvent, "tweet_bookmarked"),
+		},
+		{
+			name:         "missing followed user ID",
+			expectations: func() {},
+			event: &Event{
+				ID:        "event-1",
+				EventType: "user_followed",
+				Handler:   "user-1",
+				Timestamp: now,
+			},
+			want: fmt.Errorf("%w: target user ID is required in %s event", ErrInvalidEvent, "user_followed"),
 		},
 	}
 	for _, tc := range tt {
@@ -264,3 +503,725 @@ func TestProcessEvent(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTrends(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	type want struct {
+		err  error
+		tags []string
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		window       time.Duration
+		limit        int
+		want         want
+	}{
+		{
+			name: "success",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetHashtagCounts(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, windowStart, baselineStart time.Time) ([]*HashtagCount, error) {
+						assert.Equal(t, time.Duration(0), windowStart.Sub(windowStart.Truncate(HashtagBucketSize)))
+						assert.True(t, baselineStart.Before(windowStart))
+						return []*HashtagCount{
+							{Tag: "steady", WindowCount: 10, BaselineCount: 230},
+							{Tag: "spiking", WindowCount: 50, BaselineCount: 23},
+							{Tag: "new", WindowCount: 1},
+						}, nil
+					})
+			},
+			window: time.Hour,
+			limit:  2,
+			want: want{
+				err:  nil,
+				tags: []string{"spiking", "new"},
+			},
+		},
+		{
+			name:         "window too short",
+			expectations: func() {},
+			window:       time.Minute,
+			limit:        10,
+			want: want{
+				err: ErrInvalidTrendsWindow,
+			},
+		},
+		{
+			name:         "window too long",
+			expectations: func() {},
+			window:       24 * time.Hour,
+			limit:        10,
+			want: want{
+				err: ErrInvalidTrendsWindow,
+			},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetHashtagCounts(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			window: time.Hour,
+			limit:  10,
+			want: want{
+				err: errors.New("database error"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetTrends(ctx, tc.window, tc.limit)
+
+			if tc.want.err != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.want.err.Error())
+			} else {
+				require.NoError(t, err)
+				tags := []string{}
+				for _, trend := range result {
+					tags = append(tags, trend.Tag)
+				}
+				assert.Equal(t, tc.want.tags, tags)
+			}
+		})
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	// Monday to the Wednesday of the next week
+	from := time.Date(2025, 8, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 8, 13, 0, 0, 0, 0, time.UTC)
+	dailyMetrics := func(metric string) []*DailyMetric {
+		return []*DailyMetric{
+			{Day: from, Metric: metric, Value: 4},
+			{Day: from.AddDate(0, 0, 1), Metric: metric, Value: 10},
+			{Day: to, Metric: metric, Value: 6},
+		}
+	}
+
+	type want struct {
+		err    error
+		points []*MetricPoint
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		metric       string
+		granularity  string
+		from         time.Time
+		to           time.Time
+		want         want
+	}{
+		{
+			name: "daily points fill the missing days with zero",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), MetricDAU, from, from.AddDate(0, 0, 2)).
+					Return(dailyMetrics(MetricDAU)[:2], nil)
+			},
+			metric:      MetricDAU,
+			granularity: GranularityDay,
+			from:        from,
+			to:          from.AddDate(0, 0, 2),
+			want: want{
+				points: []*MetricPoint{
+					{Date: from, Value: 4},
+					{Date: from.AddDate(0, 0, 1), Value: 10},
+					{Date: from.AddDate(0, 0, 2), Value: 0},
+				},
+			},
+		},
+		{
+			name: "weekly active users are averaged",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), MetricDAU, from, to).
+					Return(dailyMetrics(MetricDAU), nil)
+			},
+			metric:      MetricDAU,
+			granularity: GranularityWeek,
+			from:        from,
+			to:          to,
+			want: want{
+				points: []*MetricPoint{
+					{Date: from, Value: 2},
+					{Date: from.AddDate(0, 0, 7), Value: 2},
+				},
+			},
+		},
+		{
+			name: "monthly tweets are added up",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), MetricTweets, from, to).
+					Return(dailyMetrics(MetricTweets), nil)
+			},
+			metric:      MetricTweets,
+			granularity: GranularityMonth,
+			from:        from.Add(5 * time.Hour),
+			to:          to,
+			want: want{
+				points: []*MetricPoint{
+					{Date: time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), Value: 20},
+				},
+			},
+		},
+		{
+			name:         "unknown metric",
+			expectations: func() {},
+			metric:       "unknown",
+			granularity:  GranularityDay,
+			from:         from,
+			to:           to,
+			want: want{
+				err: errors.New(`invalid metrics query: unknown metric "unknown"`),
+			},
+		},
+		{
+			name:         "unknown granularity",
+			expectations: func() {},
+			metric:       MetricDAU,
+			granularity:  "year",
+			from:         from,
+			to:           to,
+			want: want{
+				err: errors.New(`invalid metrics query: unknown granularity "year"`),
+			},
+		},
+		{
+			name:         "from after to",
+			expectations: func() {},
+			metric:       MetricDAU,
+			granularity:  GranularityDay,
+			from:         to,
+			to:           from,
+			want: want{
+				err: errors.New("invalid metrics query: from must not be after to"),
+			},
+		},
+		{
+			name:         "range too long",
+			expectations: func() {},
+			metric:       MetricDAU,
+			granularity:  GranularityDay,
+			from:         from.AddDate(-10, 0, 0),
+			to:           to,
+			want: want{
+				err: errors.New("invalid metrics query: range must not be longer than 1098 days"),
+			},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDailyMetrics(gomock.Any(), MetricDAU, from, to).
+					Return(nil, errors.New("database error"))
+			},
+			metric:      MetricDAU,
+			granularity: GranularityDay,
+			from:        from,
+			to:          to,
+			want: want{
+				err: errors.New("database error"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetMetrics(ctx, tc.metric, tc.granularity, tc.from, tc.to)
+
+			if tc.want.err != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.want.err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want.points, result)
+			}
+		})
+	}
+}
+
+func TestGetTweetStats(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	today := truncateDay(time.Now())
+	engagement := &TweetEngagement{TweetID: "tweet1", Handler: "author", CreatedAt: today.AddDate(0, 0, -1)}
+
+	type want struct {
+		err   error
+		stats *TweetStats
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		tweetID      string
+		want         want
+	}{
+		{
+			name: "success",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(engagement, nil)
+				repoMock.EXPECT().
+					GetTweetDailyStats(gomock.Any(), []string{"tweet1"}, today.AddDate(0, 0, -1)).
+					Return([]*TweetDailyStats{{TweetID: "tweet1", Day: today, Impressions: 4, Likes: 2}}, nil)
+			},
+			tweetID: "tweet1",
+			want: want{
+				stats: &TweetStats{
+					TweetID:     "tweet1",
+					Handler:     "author",
+					CreatedAt:   engagement.CreatedAt,
+					Impressions: 4,
+					Likes:       2,
+					Daily: []*TweetDailyStats{
+						{TweetID: "tweet1", Day: today.AddDate(0, 0, -1)},
+						{TweetID: "tweet1", Day: today, Impressions: 4, Likes: 2},
+					},
+				},
+			},
+		},
+		{
+			name:         "empty tweet ID",
+			expectations: func() {},
+			tweetID:      "",
+			want: want{
+				err: errors.New("tweet ID is required"),
+			},
+		},
+		{
+			name: "tweet not found",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "missing").Return(nil, ErrTweetNotFound)
+			},
+			tweetID: "missing",
+			want: want{
+				err: ErrTweetNotFound,
+			},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().GetTweetEngagement(gomock.Any(), "tweet1").Return(engagement, nil)
+				repoMock.EXPECT().
+					GetTweetDailyStats(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			tweetID: "tweet1",
+			want: want{
+				err: errors.New("database error"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetTweetStats(ctx, tc.tweetID, 30)
+
+			if tc.want.err != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.want.err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want.stats, result)
+			}
+		})
+	}
+}
+
+func TestGetUserTweetStats(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
+
+	today := truncateDay(time.Now())
+	engagements := []*TweetEngagement{
+		{TweetID: "tweet2", Handler: "author", CreatedAt: today},
+		{TweetID: "tweet1", Handler: "author", CreatedAt: today.AddDate(0, 0, -1)},
+	}
+
+	type want struct {
+		err         error
+		impressions map[string]int64
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		userID       string
+		want         want
+	}{
+		{
+			name: "success",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 20, 0).Return(engagements, nil)
+				repoMock.EXPECT().
+					GetTweetDailyStats(gomock.Any(), []string{"tweet2", "tweet1"}, today.AddDate(0, 0, -1)).
+					Return([]*TweetDailyStats{
+						{TweetID: "tweet1", Day: today.AddDate(0, 0, -1), Impressions: 1},
+						{TweetID: "tweet1", Day: today, Impressions: 2},
+						{TweetID: "tweet2", Day: today, Impressions: 5},
+					}, nil)
+			},
+			userID: "author",
+			want: want{
+				impressions: map[string]int64{"tweet1": 3, "tweet2": 5},
+			},
+		},
+		{
+			name: "user without tweets",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 20, 0).Return([]*TweetEngagement{}, nil)
+			},
+			userID: "author",
+			want: want{
+				impressions: map[string]int64{},
+			},
+		},
+		{
+			name:         "user not known",
+			expectations: func() {},
+			userID:       "unknown",
+			want: want{
+				impressions: map[string]int64{},
+			},
+		},
+		{
+			name:         "empty user ID",
+			expectations: func() {},
+			userID:       "",
+			want: want{
+				err: errors.New("user ID is required"),
+			},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().GetUserTweetEngagements(gomock.Any(), "author-id", 20, 0).Return(nil, errors.New("database error"))
+			},
+			userID: "author",
+			want: want{
+				err: errors.New("database error"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetUserTweetStats(ctx, tc.userID, 30, 0, 0)
+
+			if tc.want.err != nil {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.want.err.Error())
+			} else {
+				require.NoError(t, err)
+				impressions := map[string]int64{}
+				for _, stats := range result {
+					impressions[stats.TweetID] = stats.Impressions
+				}
+				assert.Equal(t, tc.want.impressions, impressions)
+			}
+		})
+	}
+}
+
+func TestSaveDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	deadLetter := &DeadLetter{Topic: "TweetPosted", Payload: "{}", Reason: "invalid event", Attempts: 1}
+	repoMock.EXPECT().SaveDeadLetter(gomock.Any(), deadLetter).Return(nil)
+
+	require.NoError(t, service.SaveDeadLetter(ctx, deadLetter))
+	assert.NotEmpty(t, deadLetter.ID)
+
+	assert.EqualError(t, service.SaveDeadLetter(ctx, nil), "dead letter cannot be nil")
+}
+
+func TestReplayDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+	expectKnownUsers(repoMock)
+
+	validPayload := `{"id":"event-1","event_type":"tweet_created","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`
+
+	type want struct {
+		err error
+	}
+	tt := []struct {
+		name         string
+		expectations func()
+		id           string
+		want         want
+	}{
+		{
+			name: "success",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&DeadLetter{ID: "dead-letter-1", Payload: validPayload, Attempts: 3}, nil)
+				repoMock.EXPECT().
+					ProcessEvent(gomock.Any(), &Event{ID: "event-1", EventType: "tweet_created", UserID: "user1-id", Handler: "user1", Timestamp: time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)}).
+					Return(nil)
+				repoMock.EXPECT().DeleteDeadLetter(gomock.Any(), "dead-letter-1").Return(nil)
+			},
+			id:   "dead-letter-1",
+			want: want{},
+		},
+		{
+			name: "failed again",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&DeadLetter{ID: "dead-letter-1", Payload: validPayload, Reason: "timeout", Attempts: 3}, nil)
+				repoMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(errors.New("database error"))
+				repoMock.EXPECT().
+					SaveDeadLetter(gomock.Any(), &DeadLetter{ID: "dead-letter-1", Payload: validPayload, Reason: "database error", Attempts: 4}).
+					Return(nil)
+			},
+			id:   "dead-letter-1",
+			want: want{err: errors.New("database error")},
+		},
+		{
+			name: "events replay in progress",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&DeadLetter{ID: "dead-letter-1", Payload: validPayload, Attempts: 3}, nil)
+				repoMock.EXPECT().ProcessEvent(gomock.Any(), gomock.Any()).Return(ErrReplayInProgress)
+			},
+			id:   "dead-letter-1",
+			want: want{err: ErrReplayInProgress},
+		},
+		{
+			name: "invalid payload",
+			expectations: func() {
+				repoMock.EXPECT().
+					GetDeadLetter(gomock.Any(), "dead-letter-1").
+					Return(&DeadLetter{ID: "dead-letter-1", Payload: "not json", Attempts: 1}, nil)
+				repoMock.EXPECT().SaveDeadLetter(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			id:   "dead-letter-1",
+			want: want{err: ErrInvalidEvent},
+		},
+		{
+			name: "not found",
+			expectations: func() {
+				repoMock.EXPECT().GetDeadLetter(gomock.Any(), "missing").Return(nil, ErrDeadLetterNotFound)
+			},
+			id:   "missing",
+			want: want{err: ErrDeadLetterNotFound},
+		},
+		{
+			name:         "empty ID",
+			expectations: func() {},
+			id:           "",
+			want:         want{err: errors.New("dead letter ID is required")},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.ReplayDeadLetter(ctx, tc.id)
+
+			if tc.want.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			if errors.Is(tc.want.err, ErrInvalidEvent) || errors.Is(tc.want.err, ErrDeadLetterNotFound) || errors.Is(tc.want.err, ErrReplayInProgress) {
+				assert.ErrorIs(t, err, tc.want.err)
+			} else {
+				assert.EqualError(t, err, tc.want.err.Error())
+			}
+		})
+	}
+}
+
+func TestReplayDeadLetterDetectsSpam(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryRepository()
+	detector := NewSpamDetector(repo, ExcessiveMentionsRule{MaxPerTweet: 2, MaxPerPeriod: 10, Period: time.Hour})
+	service := NewService(repo, detector, nil)
+
+	payload, err := json.Marshal(&Event{
+		ID:        "event-1",
+		EventType: events.TypeTweetCreated,
+		Handler:   "user1",
+		TweetID:   "tweet-1",
+		Mentions:  []string{"user2", "user3", "user4"},
+		Timestamp: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	deadLetter := &DeadLetter{ID: "dead-letter-1", Topic: events.TopicTweetPosted, Key: "user1", Payload: string(payload), Attempts: 3}
+	require.NoError(t, repo.SaveDeadLetter(ctx, deadLetter))
+
+	require.NoError(t, service.ReplayDeadLetter(ctx, "dead-letter-1"))
+
+	flags, err := repo.GetUserFlags(ctx, UserFlagFilter{UserID: database.LegacyUserIDOf("user1")}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, RuleExcessiveMentions, flags[0].Rule)
+
+	_, err = repo.GetDeadLetter(ctx, "dead-letter-1")
+	assert.ErrorIs(t, err, ErrDeadLetterNotFound)
+}
+
+func TestResolveUserFlag(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	type want struct {
+		flag *UserFlag
+		err  error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		id           string
+		status       string
+		want         want
+	}{
+		{
+			name: "success",
+			expectations: func() {
+				repoMock.EXPECT().
+					ResolveUserFlag(gomock.Any(), "flag-1", UserFlagStatusDismissed).
+					Return(&UserFlag{ID: "flag-1", Status: UserFlagStatusDismissed}, nil)
+			},
+			id:     "flag-1",
+			status: UserFlagStatusDismissed,
+			want: want{
+				flag: &UserFlag{ID: "flag-1", Status: UserFlagStatusDismissed},
+			},
+		},
+		{
+			name:         "flags cannot be reopened",
+			expectations: func() {},
+			id:           "flag-1",
+			status:       UserFlagStatusOpen,
+			want: want{
+				err: fmt.Errorf("%w: %q, it must be confirmed or dismissed", ErrInvalidUserFlagStatus, "open"),
+			},
+		},
+		{
+			name:         "missing flag ID",
+			expectations: func() {},
+			id:           "",
+			status:       UserFlagStatusConfirmed,
+			want: want{
+				err: errors.New("user flag ID is required"),
+			},
+		},
+		{
+			name: "already resolved",
+			expectations: func() {
+				repoMock.EXPECT().
+					ResolveUserFlag(gomock.Any(), "flag-1", UserFlagStatusConfirmed).
+					Return(nil, ErrUserFlagResolved)
+			},
+			id:     "flag-1",
+			status: UserFlagStatusConfirmed,
+			want: want{
+				err: ErrUserFlagResolved,
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.ResolveUserFlag(ctx, tc.id, tc.status)
+
+			assert.Equal(t, tc.want.flag, result)
+			assert.Equal(t, tc.want.err, err)
+		})
+	}
+}
+
+func TestGetUserFlags(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockRepository(ctrl)
+	service := NewService(repoMock, nil, nil)
+
+	filter := UserFlagFilter{Status: UserFlagStatusOpen}
+	repoMock.EXPECT().
+		GetUserFlags(gomock.Any(), filter, 20, 0).
+		Return([]*UserFlag{{ID: "flag-1"}}, nil)
+
+	flags, err := service.GetUserFlags(ctx, filter, 0, -1)
+	require.NoError(t, err)
+	assert.Equal(t, []*UserFlag{{ID: "flag-1"}}, flags)
+
+	// The flags of a user are filtered by their ID
+	expectKnownUsers(repoMock)
+	repoMock.EXPECT().
+		GetUserFlags(gomock.Any(), UserFlagFilter{Handler: "user1", UserID: "user1-id"}, 10, 0).
+		Return([]*UserFlag{{ID: "flag-1"}}, nil)
+	flags, err = service.GetUserFlags(ctx, UserFlagFilter{Handler: "user1"}, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []*UserFlag{{ID: "flag-1"}}, flags)
+
+	flags, err = service.GetUserFlags(ctx, UserFlagFilter{Handler: "unknown"}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+	count, err := service.CountUserFlags(ctx, UserFlagFilter{Handler: "unknown"})
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	_, err = service.GetUserFlags(ctx, UserFlagFilter{Status: "closed"}, 10, 0)
+	assert.ErrorIs(t, err, ErrInvalidUserFlagStatus)
+	_, err = service.CountUserFlags(ctx, UserFlagFilter{Status: "closed"})
+	assert.ErrorIs(t, err, ErrInvalidUserFlagStatus)
+}
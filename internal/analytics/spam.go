@@ -0,0 +1,232 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+
+	"github.com/google/uuid"
+)
+
+// Spam rule names
+const (
+	RuleDuplicateTweets   = "duplicate_tweets"
+	RuleFollowChurn       = "follow_churn"
+	RuleExcessiveMentions = "excessive_mentions"
+)
+
+// SpamRule detects suspicious behaviour of the user of an event
+type SpamRule interface {
+	// Name identifies the rule in the flags
+	Name() string
+	// EventTypes are the types of the events the rule is evaluated on and looks at
+	EventTypes() []string
+	// Window is how long before the event the events of the user are looked at
+	Window() time.Duration
+	// Evaluate returns the reason to flag the user, or an empty string, given the events of the user of the types
+	// of the rule within the window, ordered by timestamp and including the event
+	Evaluate(event *Event, recent []*Event) string
+}
+
+// DuplicateTweetsRule flags users posting the same text many times in a short period
+type DuplicateTweetsRule struct {
+	Threshold int // Identical tweets within the period
+	Period    time.Duration
+}
+
+// Name implements the SpamRule interface
+func (rule DuplicateTweetsRule) Name() string {
+	return RuleDuplicateTweets
+}
+
+// EventTypes implements the SpamRule interface
+func (rule DuplicateTweetsRule) EventTypes() []string {
+	return []string{events.TypeTweetCreated}
+}
+
+// Window implements the SpamRule interface
+func (rule DuplicateTweetsRule) Window() time.Duration {
+	return rule.Period
+}
+
+// Evaluate implements the SpamRule interface
+func (rule DuplicateTweetsRule) Evaluate(event *Event, recent []*Event) string {
+	if event.ContentHash == "" {
+		return ""
+	}
+	identical := 0
+	for _, other := range recent {
+		if other.ContentHash == event.ContentHash {
+			identical++
+		}
+	}
+	if identical < rule.Threshold {
+		return ""
+	}
+	return fmt.Sprintf("posted %d identical tweets within %s", identical, rule.Period)
+}
+
+// FollowChurnRule flags users that follow and then unfollow many users in a short period
+type FollowChurnRule struct {
+	Threshold int // Users both followed and unfollowed within the period
+	Period    time.Duration
+}
+
+// Name implements the SpamRule interface
+func (rule FollowChurnRule) Name() string {
+	return RuleFollowChurn
+}
+
+// EventTypes implements the SpamRule interface
+func (rule FollowChurnRule) EventTypes() []string {
+	return []string{events.TypeUserFollowed, events.TypeUserUnfollowed}
+}
+
+// Window implements the SpamRule interface
+func (rule FollowChurnRule) Window() time.Duration {
+	return rule.Period
+}
+
+// Evaluate implements the SpamRule interface
+func (rule FollowChurnRule) Evaluate(event *Event, recent []*Event) string {
+	if event.EventType != events.TypeUserUnfollowed {
+		return ""
+	}
+	followed := map[string]bool{}
+	churned := map[string]bool{}
+	for _, other := range recent {
+		if other.EventType == events.TypeUserFollowed {
+			followed[other.TargetUserID] = true
+		} else if followed[other.TargetUserID] {
+			churned[other.TargetUserID] = true
+		}
+	}
+	if len(churned) < rule.Threshold {
+		return ""
+	}
+	return fmt.Sprintf("followed and unfollowed %d users within %s", len(churned), rule.Period)
+}
+
+// ExcessiveMentionsRule flags users mentioning too many users in a tweet, or across their tweets in a short period
+type ExcessiveMentionsRule struct {
+	MaxPerTweet  int
+	MaxPerPeriod int
+	Period       time.Duration
+}
+
+// Name implements the SpamRule interface
+func (rule ExcessiveMentionsRule) Name() string {
+	return RuleExcessiveMentions
+}
+
+// EventTypes implements the SpamRule interface
+func (rule ExcessiveMentionsRule) EventTypes() []string {
+	return []string{events.TypeTweetCreated}
+}
+
+// Window implements the SpamRule interface
+func (rule ExcessiveMentionsRule) Window() time.Duration {
+	return rule.Period
+}
+
+// Evaluate implements the SpamRule interface
+func (rule ExcessiveMentionsRule) Evaluate(event *Event, recent []*Event) string {
+	if len(event.Mentions) == 0 {
+		return ""
+	}
+	if len(event.Mentions) > rule.MaxPerTweet {
+		return fmt.Sprintf("mentioned %d users in a tweet", len(event.Mentions))
+	}
+	mentions := 0
+	for _, other := range recent {
+		mentions += len(other.Mentions)
+	}
+	if mentions <= rule.MaxPerPeriod {
+		return ""
+	}
+	return fmt.Sprintf("mentioned %d users within %s", mentions, rule.Period)
+}
+
+// SpamDetector evaluates the spam rules on the processed events and flags the users that match them
+type SpamDetector struct {
+	repository SpamRepository
+	rules      []SpamRule
+}
+
+// NewSpamDetector creates a new spam detector
+func NewSpamDetector(repository SpamRepository, rules ...SpamRule) *SpamDetector {
+	return &SpamDetector{
+		repository: repository,
+		rules:      rules,
+	}
+}
+
+// Check evaluates the rules of the type of a processed event, flagging its user for each rule that matches
+func (detector *SpamDetector) Check(ctx context.Context, event *Event) error {
+	var rules []SpamRule
+	var eventTypes []string
+	var window time.Duration
+	for _, rule := range detector.rules {
+		if !slices.Contains(rule.EventTypes(), event.EventType) {
+			continue
+		}
+		rules = append(rules, rule)
+		eventTypes = append(eventTypes, rule.EventTypes()...)
+		window = max(window, rule.Window())
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// Read the events of every rule at once
+	recent, err := detector.repository.GetUserEvents(ctx, event.UserID, eventTypes, event.Timestamp.Add(-window))
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(recent, func(other *Event) bool { return other.ID == event.ID }) {
+		recent = append(recent, event)
+	}
+
+	for _, rule := range rules {
+		// Only the events of the rule within its window, up to the event
+		start := event.Timestamp.Add(-rule.Window())
+		var ruleEvents []*Event
+		for _, other := range recent {
+			if slices.Contains(rule.EventTypes(), other.EventType) && !other.Timestamp.Before(start) && !other.Timestamp.After(event.Timestamp) {
+				ruleEvents = append(ruleEvents, other)
+			}
+		}
+
+		reason := rule.Evaluate(event, ruleEvents)
+		if reason == "" {
+			continue
+		}
+		if err := detector.flag(ctx, event, rule.Name(), reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flag flags the user of an event, or counts another occurrence of their open flag of the rule
+func (detector *SpamDetector) flag(ctx context.Context, event *Event, rule, reason string) error {
+	flag := &UserFlag{
+		ID:      uuid.New().String(),
+		UserID:  event.UserID,
+		Handler: event.Handler,
+		Rule:    rule,
+		Reason:  reason,
+	}
+	created, err := detector.repository.FlagUser(ctx, flag)
+	if err != nil {
+		return err
+	}
+	if created {
+		log.Printf("flagged %s for %s: %s", event.Handler, rule, reason)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSpamRules_Evaluate(t *testing.T) {
+	now := time.Now()
+	tweet := func(id, hash string, mentions ...string) *Event {
+		return &Event{ID: id, EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", ContentHash: hash, Mentions: mentions, Timestamp: now}
+	}
+	follow := func(id, eventType, target string) *Event {
+		return &Event{ID: id, EventType: eventType, UserID: "user1-id", Handler: "user1", TargetUserID: target + "-id", TargetHandler: target, Timestamp: now}
+	}
+
+	tt := []struct {
+		name   string
+		rule   SpamRule
+		event  *Event
+		recent []*Event
+		want   string
+	}{
+		{
+			name:   "identical tweets below the threshold",
+			rule:   DuplicateTweetsRule{Threshold: 3, Period: 10 * time.Minute},
+			event:  tweet("event-2", "hash1"),
+			recent: []*Event{tweet("event-1", "hash1"), tweet("event-3", "hash2"), tweet("event-2", "hash1")},
+			want:   "",
+		},
+		{
+			name:   "identical tweets at the threshold",
+			rule:   DuplicateTweetsRule{Threshold: 3, Period: 10 * time.Minute},
+			event:  tweet("event-3", "hash1"),
+			recent: []*Event{tweet("event-1", "hash1"), tweet("event-2", "hash1"), tweet("event-3", "hash1")},
+			want:   "posted 3 identical tweets within 10m0s",
+		},
+		{
+			name:   "tweets without fingerprint are not compared",
+			rule:   DuplicateTweetsRule{Threshold: 1, Period: 10 * time.Minute},
+			event:  tweet("event-1", ""),
+			recent: []*Event{tweet("event-1", "")},
+			want:   "",
+		},
+		{
+			name:  "follow churn at the threshold",
+			rule:  FollowChurnRule{Threshold: 2, Period: time.Hour},
+			event: follow("event-6", events.TypeUserUnfollowed, "user3"),
+			recent: []*Event{
+				follow("event-1", events.TypeUserFollowed, "user2"),
+				follow("event-2", events.TypeUserFollowed, "user3"),
+				follow("event-3", events.TypeUserFollowed, "user4"),
+				follow("event-4", events.TypeUserUnfollowed, "user2"),
+				follow("event-6", events.TypeUserUnfollowed, "user3"),
+			},
+			want: "followed and unfollowed 2 users within 1h0m0s",
+		},
+		{
+			name:  "unfollowing users followed before the period is not churn",
+			rule:  FollowChurnRule{Threshold: 2, Period: time.Hour},
+			event: follow("event-2", events.TypeUserUnfollowed, "user3"),
+			recent: []*Event{
+				follow("event-1", events.TypeUserUnfollowed, "user2"),
+				follow("event-2", events.TypeUserUnfollowed, "user3"),
+			},
+			want: "",
+		},
+		{
+			name:  "follows are not evaluated",
+			rule:  FollowChurnRule{Threshold: 1, Period: time.Hour},
+			event: follow("event-3", events.TypeUserFollowed, "user2"),
+			recent: []*Event{
+				follow("event-1", events.TypeUserFollowed, "user2"),
+				follow("event-2", events.TypeUserUnfollowed, "user2"),
+				follow("event-3", events.TypeUserFollowed, "user2"),
+			},
+			want: "",
+		},
+		{
+			name:   "too many mentions in a tweet",
+			rule:   ExcessiveMentionsRule{MaxPerTweet: 2, MaxPerPeriod: 10, Period: time.Hour},
+			event:  tweet("event-1", "hash1", "user2", "user3", "user4"),
+			recent: []*Event{tweet("event-1", "hash1", "user2", "user3", "user4")},
+			want:   "mentioned 3 users in a tweet",
+		},
+		{
+			name:   "too many mentions within the period",
+			rule:   ExcessiveMentionsRule{MaxPerTweet: 2, MaxPerPeriod: 3, Period: time.Hour},
+			event:  tweet("event-2", "hash2", "user4", "user5"),
+			recent: []*Event{tweet("event-1", "hash1", "user2", "user3"), tweet("event-2", "hash2", "user4", "user5")},
+			want:   "mentioned 4 users within 1h0m0s",
+		},
+		{
+			name:   "mentions within the limits",
+			rule:   ExcessiveMentionsRule{MaxPerTweet: 2, MaxPerPeriod: 4, Period: time.Hour},
+			event:  tweet("event-2", "hash2", "user4", "user5"),
+			recent: []*Event{tweet("event-1", "hash1", "user2", "user3"), tweet("event-2", "hash2", "user4", "user5")},
+			want:   "",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.rule.Evaluate(tc.event, tc.recent))
+		})
+	}
+}
+
+func TestSpamDetector_Check(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	detector := NewSpamDetector(repo, DuplicateTweetsRule{Threshold: 3, Period: 10 * time.Minute})
+
+	now := time.Now()
+	process := func(id string, timestamp time.Time) {
+		event := &Event{ID: id, EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", TweetID: id, ContentHash: "hash1", Timestamp: timestamp}
+		require.NoError(t, repo.ProcessEvent(ctx, event))
+		require.NoError(t, detector.Check(ctx, event))
+	}
+
+	// Identical tweets before the period are not counted
+	process("event-1", now.Add(-time.Hour))
+	process("event-2", now.Add(-2*time.Minute))
+	process("event-3", now.Add(-time.Minute))
+
+	count, err := repo.CountUserFlags(ctx, UserFlagFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	// Further matches are occurrences of the open flag
+	process("event-4", now)
+	process("event-5", now)
+
+	flags, err := repo.GetUserFlags(ctx, UserFlagFilter{UserID: "user1-id"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, RuleDuplicateTweets, flags[0].Rule)
+	assert.Equal(t, UserFlagStatusOpen, flags[0].Status)
+	assert.Equal(t, 2, flags[0].Occurrences)
+	assert.Equal(t, "posted 4 identical tweets within 10m0s", flags[0].Reason)
+
+	// Events of other types are not checked
+	require.NoError(t, detector.Check(ctx, &Event{ID: "event-6", EventType: events.TypeTimelineViewed, UserID: "user1-id", Handler: "user1", Timestamp: now}))
+}
+
+func TestSpamDetector_CheckUnstoredEvent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryRepository()
+	detector := NewSpamDetector(repo, ExcessiveMentionsRule{MaxPerTweet: 1, MaxPerPeriod: 10, Period: time.Hour})
+
+	// The event is evaluated even if it is not stored yet
+	event := &Event{ID: "event-1", EventType: events.TypeTweetCreated, UserID: "user1-id", Handler: "user1", Mentions: []string{"user2", "user3"}, Timestamp: time.Now()}
+	require.NoError(t, detector.Check(ctx, event))
+
+	flags, err := repo.GetUserFlags(ctx, UserFlagFilter{Rule: RuleExcessiveMentions}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, "mentioned 2 users in a tweet", flags[0].Reason)
+}
+
+func TestSpamDetector_CheckRepositoryError(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockSpamRepository(ctrl)
+	detector := NewSpamDetector(repoMock, FollowChurnRule{Threshold: 1, Period: time.Hour})
+
+	now := time.Now()
+	repoMock.EXPECT().
+		GetUserEvents(ctx, "user1-id", []string{events.TypeUserFollowed, events.TypeUserUnfollowed}, now.Add(-time.Hour)).
+		Return([]*Event{{ID: "event-1", EventType: events.TypeUserFollowed, UserID: "user1-id", Handler: "user1", TargetUserID: "user2-id", TargetHandler: "user2", Timestamp: now}}, nil)
+	repoMock.EXPECT().
+		FlagUser(ctx, gomock.Any()).
+		Return(false, errors.New("database error"))
+
+	err := detector.Check(ctx, &Event{ID: "event-2", EventType: events.TypeUserUnfollowed, UserID: "user1-id", Handler: "user1", TargetUserID: "user2-id", TargetHandler: "user2", Timestamp: now})
+	assert.EqualError(t, err, "database error")
+}
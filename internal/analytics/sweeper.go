@@ -0,0 +1,74 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// InactivitySweeper periodically marks users without recent activity as inactive
+type InactivitySweeper struct {
+	repository InactivityRepository
+	publisher  queue.Publisher
+	idleWindow time.Duration
+	interval   time.Duration
+}
+
+// NewInactivitySweeper creates a new inactivity sweeper
+func NewInactivitySweeper(repository InactivityRepository, publisher queue.Publisher, idleWindow, interval time.Duration) *InactivitySweeper {
+	return &InactivitySweeper{
+		repository: repository,
+		publisher:  publisher,
+		idleWindow: idleWindow,
+		interval:   interval,
+	}
+}
+
+// Run sweeps idle users at startup and on every interval until the context is cancelled
+func (sweeper *InactivitySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(sweeper.interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := sweeper.Sweep(ctx); err != nil {
+			log.Printf("failed to sweep idle users: %v", err)
+		} else {
+			log.Printf("marked %d idle users as inactive", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep marks idle users as inactive and publishes a UserInactive event for each of them
+func (sweeper *InactivitySweeper) Sweep(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	deactivated, err := sweeper.repository.DeactivateIdleUsers(ctx, now.Add(-sweeper.idleWindow))
+	if err != nil {
+		return 0, err
+	}
+
+	// Keep publishing on failure, the users are already inactive
+	var errs []error
+	for _, analytics := range deactivated {
+		event := events.UserInactive{
+			Handler:   analytics.Handler,
+			Timestamp: now,
+		}
+		if err := sweeper.publisher.Publish(ctx, events.TopicUserInactive, analytics.Handler, event); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish user inactive event for %s: %w", analytics.Handler, err))
+		}
+	}
+
+	return len(deactivated), errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestInactivitySweeper_Sweep(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockInactivityRepository(ctrl)
+	publisherMock := queue.NewMockPublisher(ctrl)
+	sweeper := NewInactivitySweeper(repoMock, publisherMock, time.Hour, time.Minute)
+
+	type want struct {
+		count int
+		err   error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "publishes an event for each deactivated user",
+			expectations: func() {
+				repoMock.EXPECT().
+					DeactivateIdleUsers(gomock.Any(), gomock.Any()).
+					Return([]*UserAnalytics{{Handler: "user1"}, {Handler: "user2"}}, nil)
+				publisherMock.EXPECT().
+					Publish(gomock.Any(), events.TopicUserInactive, "user1", gomock.Any()).
+					Return(nil)
+				publisherMock.EXPECT().
+					Publish(gomock.Any(), events.TopicUserInactive, "user2", gomock.Any()).
+					Return(nil)
+			},
+			want: want{count: 2, err: nil},
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				repoMock.EXPECT().
+					DeactivateIdleUsers(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error"))
+			},
+			want: want{count: 0, err: errors.New("database error")},
+		},
+		{
+			name: "publish error does not stop the sweep",
+			expectations: func() {
+				repoMock.EXPECT().
+					DeactivateIdleUsers(gomock.Any(), gomock.Any()).
+					Return([]*UserAnalytics{{Handler: "user1"}, {Handler: "user2"}}, nil)
+				publisherMock.EXPECT().
+					Publish(gomock.Any(), events.TopicUserInactive, "user1", gomock.Any()).
+					Return(errors.New("queue error"))
+				publisherMock.EXPECT().
+					Publish(gomock.Any(), events.TopicUserInactive, "user2", gomock.Any()).
+					Return(nil)
+			},
+			want: want{
+				count: 2,
+				err:   errors.Join(errors.New("failed to publish user inactive event for user1: queue error")),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			count, err := sweeper.Sweep(ctx)
+
+			assert.Equal(t, tc.want.count, count)
+			if tc.want.err != nil {
+				assert.EqualError(t, err, tc.want.err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// HashtagBucketSize is the size of the time buckets hashtag uses are counted in
+	HashtagBucketSize = 5 * time.Minute
+	// HashtagRetention is how long hashtag buckets are kept, it bounds the trends baseline
+	HashtagRetention = 24 * time.Hour
+	// spikeThreshold is how many times faster than its baseline a hashtag must be used to spike
+	spikeThreshold = 3.0
+	// minSpikeCount is the minimum number of uses within the window for a hashtag to spike
+	minSpikeCount = 5
+)
+
+// normalizeHashtags lowercases the hashtags and removes the empty and duplicated ones
+func normalizeHashtags(hashtags []string) []string {
+	normalized := make([]string, 0, len(hashtags))
+	seen := map[string]bool{}
+	for _, hashtag := range hashtags {
+		tag := strings.ToLower(strings.TrimPrefix(hashtag, "#"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// rankTrends sorts the hashtags by how much their velocity within the window spiked against their baseline velocity
+func rankTrends(counts []*HashtagCount, window, baseline time.Duration) []*Trend {
+	trends := make([]*Trend, 0, len(counts))
+	for _, count := range counts {
+		// Uses expected within the window at the baseline velocity
+		expected := float64(count.BaselineCount) * window.Hours() / baseline.Hours()
+		// Smooth the ratio so new hashtags with a few uses do not spike
+		spikeRatio := (float64(count.WindowCount) + 1) / (expected + 1)
+
+		trends = append(trends, &Trend{
+			Tag:              count.Tag,
+			Count:            count.WindowCount,
+			Velocity:         float64(count.WindowCount) / window.Hours(),
+			BaselineVelocity: float64(count.BaselineCount) / baseline.Hours(),
+			SpikeRatio:       spikeRatio,
+			IsSpike:          count.WindowCount >= minSpikeCount && spikeRatio >= spikeThreshold,
+		})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].SpikeRatio != trends[j].SpikeRatio {
+			return trends[i].SpikeRatio > trends[j].SpikeRatio
+		}
+		if trends[i].Count != trends[j].Count {
+			return trends[i].Count > trends[j].Count
+		}
+		return trends[i].Tag < trends[j].Tag
+	})
+
+	return trends
+}
+
+// HashtagBucketsPruner periodically deletes the hashtag buckets older than the retention
+type HashtagBucketsPruner struct {
+	repository HashtagTrendRepository
+	interval   time.Duration
+}
+
+// NewHashtagBucketsPruner creates a new hashtag buckets pruner
+func NewHashtagBucketsPruner(repository HashtagTrendRepository, interval time.Duration) *HashtagBucketsPruner {
+	return &HashtagBucketsPruner{
+		repository: repository,
+		interval:   interval,
+	}
+}
+
+// Run prunes the hashtag buckets at startup and on every interval until the context is cancelled
+func (pruner *HashtagBucketsPruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruner.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := pruner.Prune(ctx); err != nil {
+			log.Printf("failed to prune hashtag buckets: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune deletes the hashtag buckets older than the retention and returns how many were deleted
+func (pruner *HashtagBucketsPruner) Prune(ctx context.Context) (int64, error) {
+	return pruner.repository.DeleteHashtagBucketsBefore(ctx, time.Now().Add(-HashtagRetention).Truncate(HashtagBucketSize))
+}
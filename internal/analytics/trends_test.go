@@ -0,0 +1,69 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeHashtags(t *testing.T) {
+	assert.Equal(t, []string{"go", "rust"}, normalizeHashtags([]string{"Go", "#go", "", "rust", "RUST"}))
+	assert.Equal(t, []string{}, normalizeHashtags(nil))
+}
+
+func TestRankTrends(t *testing.T) {
+	counts := []*HashtagCount{
+		// Used at its usual velocity of 10 per hour
+		{Tag: "steady", WindowCount: 10, BaselineCount: 110},
+		// Used 10 times faster than usual
+		{Tag: "spiking", WindowCount: 100, BaselineCount: 110},
+		// First uses, not enough to spike
+		{Tag: "new", WindowCount: 3},
+	}
+
+	trends := rankTrends(counts, time.Hour, 11*time.Hour)
+
+	assert.Len(t, trends, 3)
+	assert.Equal(t, &Trend{
+		Tag:              "spiking",
+		Count:            100,
+		Velocity:         100,
+		BaselineVelocity: 10,
+		SpikeRatio:       101.0 / 11.0,
+		IsSpike:          true,
+	}, trends[0])
+	assert.Equal(t, "new", trends[1].Tag)
+	assert.False(t, trends[1].IsSpike)
+	assert.Equal(t, "steady", trends[2].Tag)
+	assert.Equal(t, 1.0, trends[2].SpikeRatio)
+	assert.False(t, trends[2].IsSpike)
+}
+
+func TestHashtagBucketsPruner_Prune(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repoMock := NewMockHashtagTrendRepository(ctrl)
+	pruner := NewHashtagBucketsPruner(repoMock, time.Minute)
+
+	repoMock.EXPECT().
+		DeleteHashtagBucketsBefore(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-HashtagRetention), before, HashtagBucketSize)
+			return 3, nil
+		})
+	deleted, err := pruner.Prune(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	repoMock.EXPECT().
+		DeleteHashtagBucketsBefore(gomock.Any(), gomock.Any()).
+		Return(int64(0), errors.New("database error"))
+	_, err = pruner.Prune(ctx)
+	assert.EqualError(t, err, "database error")
+}
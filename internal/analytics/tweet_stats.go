@@ -0,0 +1,120 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/hyperloglog"
+)
+
+const (
+	// tweetStatsShards is the number of rows each daily counter of a tweet is split into
+	tweetStatsShards = 8
+	// viewersSketchPrecision is the precision of the unique viewers sketches, 1KB each with a 3.25% standard error
+	viewersSketchPrecision = 10
+	// defaultTweetStatsDays is the default number of days of the daily breakdown of the tweet stats
+	defaultTweetStatsDays = 30
+	// maxTweetStatsDays is the maximum number of days of the daily breakdown of the tweet stats
+	maxTweetStatsDays = 90
+)
+
+// uniqueTweetIDs removes the empty and duplicated tweet IDs, so a tweet served twice in a batch is one impression
+func uniqueTweetIDs(tweetIDs []string) []string {
+	unique := make([]string, 0, len(tweetIDs))
+	seen := map[string]bool{}
+	for _, tweetID := range tweetIDs {
+		if tweetID == "" || seen[tweetID] {
+			continue
+		}
+		seen[tweetID] = true
+		unique = append(unique, tweetID)
+	}
+	return unique
+}
+
+// mergeViewersShards merges the shards of the viewers sketches of each tweet and day
+func mergeViewersShards(shards []*TweetDailyViewers) (map[tweetDayKey][]byte, error) {
+	sketches := map[tweetDayKey]*hyperloglog.Sketch{}
+	for _, shard := range shards {
+		var sketch hyperloglog.Sketch
+		if err := sketch.UnmarshalBinary(shard.Sketch); err != nil {
+			return nil, err
+		}
+
+		key := tweetDayKey{tweetID: shard.TweetID, day: truncateDay(shard.Day).Unix()}
+		merged, exists := sketches[key]
+		if !exists {
+			sketches[key] = &sketch
+			continue
+		}
+		if err := merged.Merge(&sketch); err != nil {
+			return nil, err
+		}
+	}
+
+	viewers := make(map[tweetDayKey][]byte, len(sketches))
+	for key, sketch := range sketches {
+		data, err := sketch.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		viewers[key] = data
+	}
+	return viewers, nil
+}
+
+// tweetStatsRange returns the first and last days of the stats of a tweet over the last days, since its creation
+func tweetStatsRange(engagement *TweetEngagement, days int) (time.Time, time.Time) {
+	if days <= 0 {
+		days = defaultTweetStatsDays
+	}
+	days = min(days, maxTweetStatsDays)
+
+	to := truncateDay(time.Now())
+	from := to.AddDate(0, 0, 1-days)
+	if created := truncateDay(engagement.CreatedAt); created.After(from) {
+		from = created
+	}
+	return from, to
+}
+
+// buildTweetStats adds up the daily stats of a tweet between from and to, with zeros for the days without stats
+func buildTweetStats(engagement *TweetEngagement, daily []*TweetDailyStats, from, to time.Time) (*TweetStats, error) {
+	byDay := make(map[time.Time]*TweetDailyStats, len(daily))
+	for _, stats := range daily {
+		byDay[truncateDay(stats.Day)] = stats
+	}
+
+	result := &TweetStats{
+		TweetID:   engagement.TweetID,
+		Handler:   engagement.Handler,
+		CreatedAt: engagement.CreatedAt,
+		Daily:     []*TweetDailyStats{},
+	}
+	viewers := hyperloglog.New(viewersSketchPrecision)
+	for date := from; !date.After(to); date = date.Add(day) {
+		stats, exists := byDay[date]
+		if !exists {
+			stats = &TweetDailyStats{TweetID: engagement.TweetID, Day: date}
+		}
+
+		if len(stats.Viewers) > 0 {
+			var sketch hyperloglog.Sketch
+			if err := sketch.UnmarshalBinary(stats.Viewers); err != nil {
+				return nil, err
+			}
+			stats.UniqueViewers = sketch.Count()
+			if err := viewers.Merge(&sketch); err != nil {
+				return nil, err
+			}
+		}
+
+		result.Impressions += stats.Impressions
+		result.ProfileViews += stats.ProfileViews
+		result.Likes += stats.Likes
+		result.Replies += stats.Replies
+		result.Daily = append(result.Daily, stats)
+	}
+	result.UniqueViewers = viewers.Count()
+
+	return result, nil
+}
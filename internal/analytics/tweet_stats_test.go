@@ -0,0 +1,104 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/hyperloglog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniqueTweetIDs(t *testing.T) {
+	assert.Equal(t, []string{"1", "2"}, uniqueTweetIDs([]string{"1", "", "2", "1"}))
+}
+
+func TestTweetStatsRange(t *testing.T) {
+	today := truncateDay(time.Now())
+
+	from, to := tweetStatsRange(&TweetEngagement{CreatedAt: today.AddDate(-1, 0, 0)}, 0)
+	assert.Equal(t, today.AddDate(0, 0, -29), from)
+	assert.Equal(t, today, to)
+
+	from, _ = tweetStatsRange(&TweetEngagement{CreatedAt: today.AddDate(-1, 0, 0)}, 365)
+	assert.Equal(t, today.AddDate(0, 0, -89), from)
+
+	// The breakdown starts the day the tweet was created
+	from, _ = tweetStatsRange(&TweetEngagement{CreatedAt: today.AddDate(0, 0, -2).Add(time.Hour)}, 30)
+	assert.Equal(t, today.AddDate(0, 0, -2), from)
+}
+
+func TestBuildTweetStats(t *testing.T) {
+	day1 := time.Date(2025, 8, 9, 0, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+
+	sketch := func(viewers ...string) []byte {
+		sketch := hyperloglog.New(viewersSketchPrecision)
+		for _, viewer := range viewers {
+			sketch.Add(viewer)
+		}
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	engagement := &TweetEngagement{TweetID: "tweet1", Handler: "author", CreatedAt: day1}
+	daily := []*TweetDailyStats{
+		{TweetID: "tweet1", Day: day1, Impressions: 3, Likes: 1, Viewers: sketch("user1", "user2")},
+		{TweetID: "tweet1", Day: day3, Impressions: 2, ProfileViews: 1, Replies: 1, Viewers: sketch("user2", "user3")},
+	}
+
+	stats, err := buildTweetStats(engagement, daily, day1, day3)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tweet1", stats.TweetID)
+	assert.Equal(t, "author", stats.Handler)
+	assert.Equal(t, int64(5), stats.Impressions)
+	assert.Equal(t, uint64(3), stats.UniqueViewers)
+	assert.Equal(t, int64(1), stats.ProfileViews)
+	assert.Equal(t, int64(1), stats.Likes)
+	assert.Equal(t, int64(1), stats.Replies)
+
+	require.Len(t, stats.Daily, 3)
+	assert.Equal(t, uint64(2), stats.Daily[0].UniqueViewers)
+	assert.Equal(t, &TweetDailyStats{TweetID: "tweet1", Day: day1.AddDate(0, 0, 1)}, stats.Daily[1])
+	assert.Equal(t, uint64(2), stats.Daily[2].UniqueViewers)
+}
+
+func TestMergeViewersShards(t *testing.T) {
+	day1 := time.Date(2025, 8, 9, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	sketch := func(viewers ...string) []byte {
+		sketch := hyperloglog.New(viewersSketchPrecision)
+		for _, viewer := range viewers {
+			sketch.Add(viewer)
+		}
+		data, err := sketch.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+	count := func(data []byte) uint64 {
+		var sketch hyperloglog.Sketch
+		require.NoError(t, sketch.UnmarshalBinary(data))
+		return sketch.Count()
+	}
+
+	viewers, err := mergeViewersShards([]*TweetDailyViewers{
+		{TweetID: "tweet1", Day: day1, Shard: 0, Sketch: sketch("user1", "user2")},
+		{TweetID: "tweet1", Day: day1, Shard: 3, Sketch: sketch("user2", "user3")},
+		{TweetID: "tweet1", Day: day2, Shard: 1, Sketch: sketch("user1")},
+		{TweetID: "tweet2", Day: day1, Shard: 5, Sketch: sketch("user4")},
+	})
+	require.NoError(t, err)
+
+	// A viewer in several shards of a day counts once
+	assert.Len(t, viewers, 3)
+	assert.Equal(t, uint64(3), count(viewers[tweetDayKey{tweetID: "tweet1", day: day1.Unix()}]))
+	assert.Equal(t, uint64(1), count(viewers[tweetDayKey{tweetID: "tweet1", day: day2.Unix()}]))
+	assert.Equal(t, uint64(1), count(viewers[tweetDayKey{tweetID: "tweet2", day: day1.Unix()}]))
+
+	_, err = mergeViewersShards([]*TweetDailyViewers{{TweetID: "tweet1", Day: day1, Sketch: []byte("invalid")}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// User analytics sort fields
+const (
+	SortByHandler   = "handler"
+	SortByCreatedAt = "created_at"
+	SortByUpdatedAt = "updated_at"
+)
+
+const (
+	// defaultUserAnalyticsLimit is the page size when none is given
+	defaultUserAnalyticsLimit = 100
+	// maxUserAnalyticsLimit is the largest page size
+	maxUserAnalyticsLimit = 1000
+	// exportBatchSize is the number of user analytics read at once while exporting
+	exportBatchSize = 1000
+)
+
+// ErrInvalidUserAnalyticsQuery is returned when the filters, the sort or the cursor of a user analytics query are not valid
+var ErrInvalidUserAnalyticsQuery = errors.New("invalid user analytics query")
+
+// UserAnalyticsFilter filters, sorts and paginates the user analytics
+type UserAnalyticsFilter struct {
+	IsInfluencer *bool
+	IsActive     *bool
+	UpdatedSince time.Time // Included, ignored if zero
+	UpdatedUntil time.Time // Excluded, ignored if zero
+	SortBy       string    // Ties are broken by handler, in the same direction
+	Descending   bool
+	After        *UserAnalyticsCursor // The page starts after it
+	Limit        int
+}
+
+// UserAnalyticsCursor is the position of the last user analytics of a page
+type UserAnalyticsCursor struct {
+	SortBy     string    `json:"sort_by"`
+	Descending bool      `json:"descending,omitempty"`
+	Handler    string    `json:"handler"`
+	Time       time.Time `json:"time,omitzero"` // Value of the sort field, when sorting by a time
+}
+
+// UserAnalyticsPage is a page of user analytics, NextCursor is empty on the last page
+type UserAnalyticsPage struct {
+	Users      []*UserAnalytics
+	NextCursor string
+}
+
+// newUserAnalyticsCursor returns the cursor positioned at the user analytics
+func newUserAnalyticsCursor(analytics *UserAnalytics, sortBy string, descending bool) *UserAnalyticsCursor {
+	cursor := &UserAnalyticsCursor{SortBy: sortBy, Descending: descending, Handler: analytics.Handler}
+	switch sortBy {
+	case SortByCreatedAt:
+		cursor.Time = analytics.CreatedAt
+	case SortByUpdatedAt:
+		cursor.Time = analytics.UpdatedAt
+	}
+	return cursor
+}
+
+// encodeUserAnalyticsCursor encodes a cursor as an opaque string
+func encodeUserAnalyticsCursor(cursor *UserAnalyticsCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeUserAnalyticsCursor decodes a cursor, which must have been created for the same sort
+func decodeUserAnalyticsCursor(value, sortBy string, descending bool) (*UserAnalyticsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserAnalyticsQuery)
+	}
+	var cursor UserAnalyticsCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidUserAnalyticsQuery)
+	}
+	if cursor.SortBy != sortBy || cursor.Descending != descending {
+		return nil, fmt.Errorf("%w: cursor was created for another sort", ErrInvalidUserAnalyticsQuery)
+	}
+	return &cursor, nil
+}
+
+// matchesUserAnalyticsFilter reports whether the user analytics pass the filters and are after the cursor of the filter
+func matchesUserAnalyticsFilter(analytics *UserAnalytics, filter UserAnalyticsFilter) bool {
+	if filter.IsInfluencer != nil && analytics.IsInfluencer != *filter.IsInfluencer {
+		return false
+	}
+	if filter.IsActive != nil && analytics.IsActive != *filter.IsActive {
+		return false
+	}
+	if !filter.UpdatedSince.IsZero() && analytics.UpdatedAt.Before(filter.UpdatedSince) {
+		return false
+	}
+	if !filter.UpdatedUntil.IsZero() && !analytics.UpdatedAt.Before(filter.UpdatedUntil) {
+		return false
+	}
+	if filter.After != nil {
+		return compareUserAnalyticsCursors(newUserAnalyticsCursor(analytics, filter.SortBy, filter.Descending), filter.After) > 0
+	}
+	return true
+}
+
+// compareUserAnalyticsCursors compares two positions in the sort order of a, it returns a positive number if a comes after b
+func compareUserAnalyticsCursors(a, b *UserAnalyticsCursor) int {
+	result := a.Time.Compare(b.Time)
+	if result == 0 {
+		result = strings.Compare(a.Handler, b.Handler)
+	}
+	if a.Descending {
+		return -result
+	}
+	return result
+}
+
+// sortUserAnalytics sorts the user analytics by the sort of the filter
+func sortUserAnalytics(analytics []*UserAnalytics, filter UserAnalyticsFilter) {
+	sort.Slice(analytics, func(i, j int) bool {
+		a := newUserAnalyticsCursor(analytics[i], filter.SortBy, filter.Descending)
+		b := newUserAnalyticsCursor(analytics[j], filter.SortBy, filter.Descending)
+		return compareUserAnalyticsCursors(a, b) < 0
+	})
+}
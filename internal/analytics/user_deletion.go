@@ -0,0 +1,53 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// UserDeletionConsumer deletes the analytics of the deleted users, and acknowledges it to the users service
+type UserDeletionConsumer struct {
+	service   Service
+	publisher queue.Publisher
+}
+
+// NewUserDeletionConsumer creates a new user deletion consumer that acknowledges the deletions with the publisher
+func NewUserDeletionConsumer(service Service, publisher queue.Publisher) *UserDeletionConsumer {
+	return &UserDeletionConsumer{
+		service:   service,
+		publisher: publisher,
+	}
+}
+
+// Subscribe registers the consumer handler in the subscriber
+func (consumer *UserDeletionConsumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicUserDeleted, consumer.HandleUserDeleted)
+}
+
+// HandleUserDeleted deletes the analytics of a deleted user, then acknowledges it with a UserDataDeleted event
+func (consumer *UserDeletionConsumer) HandleUserDeleted(ctx context.Context, message *queue.Message) error {
+	var event events.UserDeleted
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+	if event.Handler == "" {
+		return fmt.Errorf("%w: user deleted event without handler", queue.ErrInvalidMessage)
+	}
+
+	if err := consumer.service.DeleteUserAnalytics(ctx, event.Handler); err != nil && !errors.Is(err, ErrUserAnalyticsNotFound) {
+		return err
+	}
+
+	acknowledgement := events.UserDataDeleted{
+		DeletionID: event.DeletionID,
+		Handler:    event.Handler,
+		Service:    events.ServiceAnalytics,
+		Timestamp:  time.Now().UTC(),
+	}
+	return consumer.publisher.Publish(ctx, events.TopicUserDataDeleted, event.Handler, acknowledgement)
+}
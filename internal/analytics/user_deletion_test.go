@@ -0,0 +1,123 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserDeletionConsumer_HandleUserDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	consumer := NewUserDeletionConsumer(mockService, mockPublisher)
+
+	message := &queue.Message{
+		Topic:   events.TopicUserDeleted,
+		Key:     "user1",
+		Payload: []byte(`{"deletion_id":"deletion-1","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "deletes the data of the user and acknowledges it",
+			expectations: func() {
+				mockService.EXPECT().
+					DeleteUserAnalytics(ctx, "user1").
+					Return(nil).
+					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDataDeleted, "user1", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.UserDataDeleted)
+						assert.Equal(t, "deletion-1", event.DeletionID)
+						assert.Equal(t, events.ServiceAnalytics, event.Service)
+						return nil
+					})
+			},
+			message: message,
+			wantErr: false,
+		},
+		{
+			name: "user without analytics is acknowledged",
+			expectations: func() {
+				mockService.EXPECT().
+					DeleteUserAnalytics(ctx, "user1").
+					Return(ErrUserAnalyticsNotFound).
+					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDataDeleted, "user1", gomock.Any()).
+					Return(nil)
+			},
+			message: message,
+			wantErr: false,
+		},
+		{
+			name: "deletion failure is not acknowledged",
+			expectations: func() {
+				mockService.EXPECT().
+					DeleteUserAnalytics(ctx, "user1").
+					Return(errors.New("database unavailable")).
+					Times(1)
+			},
+			message: message,
+			wantErr: true,
+		},
+		{
+			name: "acknowledgement failure is returned",
+			expectations: func() {
+				mockService.EXPECT().
+					DeleteUserAnalytics(ctx, "user1").
+					Return(nil).
+					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDataDeleted, "user1", gomock.Any()).
+					Return(errors.New("queue unavailable"))
+			},
+			message: message,
+			wantErr: true,
+		},
+		{
+			name:         "missing handler",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserDeleted,
+				Payload: []byte(`{"deletion_id":"deletion-1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserDeleted,
+				Key:     "user1",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleUserDeleted(ctx, tc.message)
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
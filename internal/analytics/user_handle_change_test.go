@@ -0,0 +1,83 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserHandleChangeConsumer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewUserHandleChangeConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		payload      string
+		wantErr      bool
+	}{
+		{
+			name: "moves the analytics to the new handle",
+			expectations: func() {
+				mockService.EXPECT().
+					RenameUser(ctx, "0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11", "user1", "user2").
+					Return(nil).
+					Times(1)
+			},
+			payload: `{"user_id":"0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11","old_handler":"user1","new_handler":"user2","timestamp":"2025-08-09T05:13:41Z"}`,
+			wantErr: false,
+		},
+		{
+			name: "failure is returned",
+			expectations: func() {
+				mockService.EXPECT().
+					RenameUser(ctx, "0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11", "user1", "user2").
+					Return(errors.New("database unavailable")).
+					Times(1)
+			},
+			payload: `{"user_id":"0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11","old_handler":"user1","new_handler":"user2","timestamp":"2025-08-09T05:13:41Z"}`,
+			wantErr: true,
+		},
+		{
+			name:         "missing handlers",
+			expectations: func() {},
+			payload:      `{"user_id":"0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11","old_handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`,
+			wantErr:      true,
+		},
+		{
+			name:         "missing user ID",
+			expectations: func() {},
+			payload:      `{"old_handler":"user1","new_handler":"user2","timestamp":"2025-08-09T05:13:41Z"}`,
+			wantErr:      true,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			payload:      `{`,
+			wantErr:      true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleUserHandleChanged(ctx, &queue.Message{
+				Topic:   events.TopicUserHandleChanged,
+				Key:     "0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11",
+				Payload: []byte(tc.payload),
+			})
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
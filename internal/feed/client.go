@@ -0,0 +1,133 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+//go:generate mockgen -source=client.go -destination=client_mock.go -package=feed
+
+// UsersClient defines the operations the feed needs from the users service
+type UsersClient interface {
+	GetUserFollowees(ctx context.Context, userID string) ([]string, error)
+	GetUserFollowers(ctx context.Context, userID string) ([]string, error)
+}
+
+// TweetsClient defines the operations the feed needs from the tweets service
+type TweetsClient interface {
+	GetTweet(ctx context.Context, id string) (*Tweet, error)
+	GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error)
+}
+
+// serviceID identifies the feed service in requests to other services
+const serviceID = "feed-service"
+
+// errNotFound is returned when the requested resource does not exist
+var errNotFound = errors.New("not found")
+
+// HTTPUsersClient is an HTTP implementation of the UsersClient interface
+type HTTPUsersClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPUsersClient creates a new users service HTTP client
+func NewHTTPUsersClient(baseURL string, timeout time.Duration) *HTTPUsersClient {
+	return &HTTPUsersClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetUserFollowees retrieves the handlers of the users followed by a user
+func (client *HTTPUsersClient) GetUserFollowees(ctx context.Context, userID string) ([]string, error) {
+	return client.getUserHandlers(ctx, userID, "followees")
+}
+
+// GetUserFollowers retrieves the handlers of the users that follow a user
+func (client *HTTPUsersClient) GetUserFollowers(ctx context.Context, userID string) ([]string, error) {
+	return client.getUserHandlers(ctx, userID, "followers")
+}
+
+// getUserHandlers retrieves the handlers of the followees or followers of a user
+func (client *HTTPUsersClient) getUserHandlers(ctx context.Context, userID string, relation string) ([]string, error) {
+	var users []struct {
+		Handler string `json:"handler"`
+	}
+	endpoint := fmt.Sprintf("%s/v1/users/%s/%s", client.baseURL, url.PathEscape(userID), relation)
+	if err := getJSON(ctx, client.httpClient, endpoint, &users); err != nil {
+		return nil, fmt.Errorf("failed to get %s of %s: %w", relation, userID, err)
+	}
+
+	handlers := make([]string, 0, len(users))
+	for _, user := range users {
+		handlers = append(handlers, user.Handler)
+	}
+	return handlers, nil
+}
+
+// HTTPTweetsClient is an HTTP implementation of the TweetsClient interface
+type HTTPTweetsClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPTweetsClient creates a new tweets service HTTP client
+func NewHTTPTweetsClient(baseURL string, timeout time.Duration) *HTTPTweetsClient {
+	return &HTTPTweetsClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetTweet retrieves a tweet by its ID, returning nil if it does not exist
+func (client *HTTPTweetsClient) GetTweet(ctx context.Context, id string) (*Tweet, error) {
+	var tweet Tweet
+	endpoint := fmt.Sprintf("%s/v1/tweets/%s", client.baseURL, url.PathEscape(id))
+	if err := getJSON(ctx, client.httpClient, endpoint, &tweet); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tweet %s: %w", id, err)
+	}
+	return &tweet, nil
+}
+
+// GetUserTweets retrieves the tweets posted by a user
+func (client *HTTPTweetsClient) GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error) {
+	var tweets []*Tweet
+	endpoint := fmt.Sprintf("%s/v1/tweets/users/%s", client.baseURL, url.PathEscape(userID))
+	if err := getJSON(ctx, client.httpClient, endpoint, &tweets); err != nil {
+		return nil, fmt.Errorf("failed to get tweets of %s: %w", userID, err)
+	}
+	return tweets, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into dest
+func getJSON(ctx context.Context, httpClient *http.Client, endpoint string, dest any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("X-User-Id", serviceID)
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(dest)
+}
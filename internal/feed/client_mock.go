@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: client.go
+//
+// Generated by this command:
+//
+//	mockgen -source=client.go -destination=client_mock.go -package=feed
+//
+
+// Package feed is a generated GoMock package.
+package feed
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockUsersClient is a mock of UsersClient interface.
+type MockUsersClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockUsersClientMockRecorder
+	isgomock struct{}
+}
+
+// MockUsersClientMockRecorder is the mock recorder for MockUsersClient.
+type MockUsersClientMockRecorder struct {
+	mock *MockUsersClient
+}
+
+// NewMockUsersClient creates a new mock instance.
+func NewMockUsersClient(ctrl *gomock.Controller) *MockUsersClient {
+	mock := &MockUsersClient{ctrl: ctrl}
+	mock.recorder = &MockUsersClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUsersClient) EXPECT() *MockUsersClientMockRecorder {
+	return m.recorder
+}
+
+// GetUserFollowees mocks base method.
+func (m *MockUsersClient) GetUserFollowees(ctx context.Context, userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFollowees", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFollowees indicates an expected call of GetUserFollowees.
+func (mr *MockUsersClientMockRecorder) GetUserFollowees(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowees", reflect.TypeOf((*MockUsersClient)(nil).GetUserFollowees), ctx, userID)
+}
+
+// GetUserFollowers mocks base method.
+func (m *MockUsersClient) GetUserFollowers(ctx context.Context, userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserFollowers", ctx, userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserFollowers indicates an expected call of GetUserFollowers.
+func (mr *MockUsersClientMockRecorder) GetUserFollowers(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowers", reflect.TypeOf((*MockUsersClient)(nil).GetUserFollowers), ctx, userID)
+}
+
+// MockTweetsClient is a mock of TweetsClient interface.
+type MockTweetsClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockTweetsClientMockRecorder
+	isgomock struct{}
+}
+
+// MockTweetsClientMockRecorder is the mock recorder for MockTweetsClient.
+type MockTweetsClientMockRecorder struct {
+	mock *MockTweetsClient
+}
+
+// NewMockTweetsClient creates a new mock instance.
+func NewMockTweetsClient(ctrl *gomock.Controller) *MockTweetsClient {
+	mock := &MockTweetsClient{ctrl: ctrl}
+	mock.recorder = &MockTweetsClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTweetsClient) EXPECT() *MockTweetsClientMockRecorder {
+	return m.recorder
+}
+
+// GetTweet mocks base method.
+func (m *MockTweetsClient) GetTweet(ctx context.Context, id string) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTweet", ctx, id)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTweet indicates an expected call of GetTweet.
+func (mr *MockTweetsClientMockRecorder) GetTweet(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTweet", reflect.TypeOf((*MockTweetsClient)(nil).GetTweet), ctx, id)
+}
+
+// GetUserTweets mocks base method.
+func (m *MockTweetsClient) GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserTweets", ctx, userID)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTweets indicates an expected call of GetUserTweets.
+func (mr *MockTweetsClientMockRecorder) GetUserTweets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTweets", reflect.TypeOf((*MockTweetsClient)(nil).GetUserTweets), ctx, userID)
+}
@@ -0,0 +1,116 @@
+package feed
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPUsersClient_GetUserFollowees(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/users/user1/followees":
+			w.Write([]byte(`[{"handler":"user2","first_name":"User","last_name":"Two"},{"handler":"user3","first_name":"User","last_name":"Three"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPUsersClient(server.URL, time.Second)
+
+	followees, err := client.GetUserFollowees(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user2", "user3"}, followees)
+
+	_, err = client.GetUserFollowees(context.Background(), "broken")
+	assert.EqualError(t, err, "failed to get followees of broken: unexpected status code 500")
+}
+
+func TestHTTPUsersClient_GetUserFollowers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/users/user1/followers":
+			w.Write([]byte(`[{"handler":"user4","first_name":"User","last_name":"Four"}]`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPUsersClient(server.URL, time.Second)
+
+	followers, err := client.GetUserFollowers(context.Background(), "user1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user4"}, followers)
+
+	_, err = client.GetUserFollowers(context.Background(), "broken")
+	assert.EqualError(t, err, "failed to get followers of broken: unexpected status code 500")
+}
+
+func TestHTTPTweetsClient_GetUserTweets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-User-Id") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/tweets/users/user2":
+			w.Write([]byte(`[{"id":"1","handler":"user2","content":{"text":"Hello"},"created_at":"2025-08-09T05:13:41Z"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPTweetsClient(server.URL, time.Second)
+
+	tweets, err := client.GetUserTweets(context.Background(), "user2")
+	require.NoError(t, err)
+	assert.Equal(t, []*Tweet{{
+		ID:        "1",
+		Handler:   "user2",
+		Content:   Content{Text: "Hello"},
+		CreatedAt: time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC),
+	}}, tweets)
+
+	_, err = client.GetUserTweets(context.Background(), "missing")
+	assert.EqualError(t, err, "failed to get tweets of missing: not found")
+}
+
+func TestHTTPTweetsClient_GetTweet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/tweets/1":
+			w.Write([]byte(`{"id":"1","handler":"user2","content":{"text":"Hello"},"created_at":"2025-08-09T05:13:41Z"}`))
+		case "/v1/tweets/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPTweetsClient(server.URL, time.Second)
+
+	tweet, err := client.GetTweet(context.Background(), "1")
+	require.NoError(t, err)
+	assert.Equal(t, &Tweet{
+		ID:        "1",
+		Handler:   "user2",
+		Content:   Content{Text: "Hello"},
+		CreatedAt: time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC),
+	}, tweet)
+
+	tweet, err = client.GetTweet(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Nil(t, tweet)
+
+	_, err = client.GetTweet(context.Background(), "broken")
+	assert.EqualError(t, err, "failed to get tweet broken: unexpected status code 500")
+}
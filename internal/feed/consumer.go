@@ -0,0 +1,79 @@
+package feed
+
+import (
+	"context"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// Consumer handles the events the feed service is subscribed to
+type Consumer struct {
+	service Service
+}
+
+// NewConsumer creates a new feed event consumer
+func NewConsumer(service Service) *Consumer {
+	return &Consumer{
+		service: service,
+	}
+}
+
+// Subscribe registers the consumer handlers in the subscriber
+func (consumer *Consumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicUserInactive, consumer.HandleUserInactive)
+	subscriber.Subscribe(events.TopicTweetDeleted, consumer.HandleTweetDeleted)
+	subscriber.Subscribe(events.TopicUserDeactivated, consumer.HandleUserDeactivated)
+	subscriber.Subscribe(events.TopicUserReactivated, consumer.HandleUserReactivated)
+	subscriber.Subscribe(events.TopicUserHandleChanged, consumer.HandleUserHandleChanged)
+}
+
+// HandleUserInactive evicts the cached timeline of a user that became inactive
+func (consumer *Consumer) HandleUserInactive(ctx context.Context, message *queue.Message) error {
+	var event events.UserInactive
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.EvictUserTimeline(ctx, event.Handler)
+}
+
+// HandleTweetDeleted removes a deleted tweet from the cached timelines
+func (consumer *Consumer) HandleTweetDeleted(ctx context.Context, message *queue.Message) error {
+	var event events.TweetDeleted
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.RemoveTweet(ctx, event.TweetID)
+}
+
+// HandleUserDeactivated removes the tweets of a deactivated user from the cached timelines
+func (consumer *Consumer) HandleUserDeactivated(ctx context.Context, message *queue.Message) error {
+	var event events.UserDeactivated
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.HideUserTweets(ctx, event.Handler)
+}
+
+// HandleUserReactivated has the timelines of the followers of a reactivated user rebuilt with their tweets
+func (consumer *Consumer) HandleUserReactivated(ctx context.Context, message *queue.Message) error {
+	var event events.UserReactivated
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.RestoreUserTweets(ctx, event.Handler)
+}
+
+// HandleUserHandleChanged moves the cached tweets of a user that changed their handle to the new one
+func (consumer *Consumer) HandleUserHandleChanged(ctx context.Context, message *queue.Message) error {
+	var event events.UserHandleChanged
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.RenameUser(ctx, event.OldHandler, event.NewHandler)
+}
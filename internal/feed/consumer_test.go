@@ -0,0 +1,250 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConsumer_HandleUserInactive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "evicts the timeline of the inactive user",
+			expectations: func() {
+				mockService.EXPECT().
+					EvictUserTimeline(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserInactive,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserInactive,
+				Key:     "user1",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleUserInactive(ctx, tc.message)
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConsumer_HandleTweetDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "removes the deleted tweet from the timelines",
+			expectations: func() {
+				mockService.EXPECT().
+					RemoveTweet(ctx, "tweet1").
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicTweetDeleted,
+				Key:     "user2",
+				Payload: []byte(`{"tweet_id":"tweet1","handler":"user2","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicTweetDeleted,
+				Key:     "user2",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleTweetDeleted(ctx, tc.message)
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConsumer_HandleUserActivation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "hides the tweets of the deactivated user",
+			expectations: func() {
+				mockService.EXPECT().
+					HideUserTweets(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserDeactivated,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name: "restores the tweets of the reactivated user",
+			expectations: func() {
+				mockService.EXPECT().
+					RestoreUserTweets(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserReactivated,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserReactivated,
+				Key:     "user1",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			var err error
+			if tc.message.Topic == events.TopicUserDeactivated {
+				err = consumer.HandleUserDeactivated(ctx, tc.message)
+			} else {
+				err = consumer.HandleUserReactivated(ctx, tc.message)
+			}
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConsumer_Subscribe(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user2", Content: Content{Text: "Hello"}, CreatedAt: time.Now()})
+
+	messageQueue := queue.NewInMemoryQueue()
+	NewConsumer(NewService(repo, cache.NewInMemoryCache(), nil, nil, messageQueue)).Subscribe(messageQueue)
+
+	err := messageQueue.Publish(ctx, events.TopicUserInactive, "user1", events.UserInactive{Handler: "user1", Timestamp: time.Now()})
+	require.NoError(t, err)
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, timeline)
+}
+
+func TestConsumer_SubscribeTweetDeleted(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user2", Content: Content{Text: "Hello"}, CreatedAt: time.Now()})
+
+	messageQueue := queue.NewInMemoryQueue()
+	NewConsumer(NewService(repo, cache.NewInMemoryCache(), nil, nil, messageQueue)).Subscribe(messageQueue)
+
+	err := messageQueue.Publish(ctx, events.TopicTweetDeleted, "user2", events.TweetDeleted{TweetID: "1", Handler: "user2", Timestamp: time.Now()})
+	require.NoError(t, err)
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, timeline)
+}
+
+func TestConsumer_SubscribeUserHandleChanged(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user2", Content: Content{Text: "Hello"}, CreatedAt: time.Now()})
+	repo.AddTweet("user2", &Tweet{ID: "2", Handler: "user1", Content: Content{Text: "Hi"}, CreatedAt: time.Now()})
+
+	messageQueue := queue.NewInMemoryQueue()
+	NewConsumer(NewService(repo, cache.NewInMemoryCache(), nil, nil, messageQueue)).Subscribe(messageQueue)
+
+	err := messageQueue.Publish(ctx, events.TopicUserHandleChanged, "0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11", events.UserHandleChanged{
+		UserID:     "0b3d5a3c-8f0e-4a8e-9d6c-2f1c7e0b9a11",
+		OldHandler: "user2",
+		NewHandler: "user3",
+		Timestamp:  time.Now(),
+	})
+	require.NoError(t, err)
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, timeline, 1)
+	assert.Equal(t, "user3", timeline[0].Handler)
+	// The timeline of the user is evicted, to be rebuilt under the new handle
+	cached, err := repo.HasUserTimeline(ctx, "user2")
+	require.NoError(t, err)
+	assert.False(t, cached)
+}
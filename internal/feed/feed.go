@@ -14,11 +14,28 @@ type Tweet struct {
 
 // Content represents the content of a tweet
 type Content struct {
-	Text string `json:"text"`
+	Text    string       `json:"text"`
+	URLs    []string     `json:"urls,omitempty"`
+	Preview *LinkPreview `json:"preview,omitempty"` // Card of a link of the text, for timelines to render it as a rich link
+}
+
+// LinkPreview represents the preview card of a link shared in a tweet
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
 }
 
 // TimelineResponse represents the response for the user timeline
 type TimelineResponse struct {
 	Tweets     []*Tweet `json:"tweets"`
 	NextOffset int      `json:"next_offset"`
+	Popular    bool     `json:"popular,omitempty"` // The timeline was empty and was padded with popular tweets
+}
+
+// PopularResponse represents the response for the popular tweets
+type PopularResponse struct {
+	Tweets []*Tweet `json:"tweets"`
 }
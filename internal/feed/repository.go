@@ -11,6 +11,12 @@ import (
 // Repository defines the interface for feed data operations
 type Repository interface {
 	GetUserTimeline(ctx context.Context, userID string, limit, offset int) ([]*Tweet, error)
+	HasUserTimeline(ctx context.Context, userID string) (bool, error)
+	SaveUserTimeline(ctx context.Context, userID string, tweets []*Tweet) error
+	DeleteUserTimeline(ctx context.Context, userID string) error
+	RemoveTweet(ctx context.Context, tweetID string) error
+	RemoveUserTweets(ctx context.Context, userID string) error
+	RenameUserTweets(ctx context.Context, oldUserID, newUserID string) error
 }
 
 // InMemoryFeedRepository is an in-memory implementation of the Repository interface
@@ -58,6 +64,97 @@ func (repository *InMemoryFeedRepository) GetUserTimeline(ctx context.Context, u
 	return result, nil
 }
 
+// HasUserTimeline checks if the timeline of a user is cached
+func (repository *InMemoryFeedRepository) HasUserTimeline(ctx context.Context, userID string) (bool, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	_, exists := repository.tweets[userID]
+	return exists, nil
+}
+
+// SaveUserTimeline replaces the timeline of a user
+func (repository *InMemoryFeedRepository) SaveUserTimeline(ctx context.Context, userID string, tweets []*Tweet) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	timeline := make([]*Tweet, len(tweets))
+	copy(timeline, tweets)
+	repository.tweets[userID] = timeline
+	return nil
+}
+
+// DeleteUserTimeline evicts the timeline of a user
+func (repository *InMemoryFeedRepository) DeleteUserTimeline(ctx context.Context, userID string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	delete(repository.tweets, userID)
+	return nil
+}
+
+// RemoveTweet removes a tweet from all the timelines it is in
+func (repository *InMemoryFeedRepository) RemoveTweet(ctx context.Context, tweetID string) error {
+	repository.removeTweets(func(tweet *Tweet) bool {
+		return tweet.ID == tweetID
+	})
+	return nil
+}
+
+// RemoveUserTweets removes the tweets of a user from all the timelines they are in
+func (repository *InMemoryFeedRepository) RemoveUserTweets(ctx context.Context, userID string) error {
+	repository.removeTweets(func(tweet *Tweet) bool {
+		return tweet.Handler == userID
+	})
+	return nil
+}
+
+// RenameUserTweets sets the new handler of a user on their tweets in all the timelines they are in
+func (repository *InMemoryFeedRepository) RenameUserTweets(ctx context.Context, oldUserID, newUserID string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for userID, timeline := range repository.tweets {
+		// Replace the timeline and the tweets, since they may be shared with the callers of GetUserTimeline
+		var renamed []*Tweet
+		for i, tweet := range timeline {
+			if tweet.Handler != oldUserID {
+				continue
+			}
+			if renamed == nil {
+				renamed = make([]*Tweet, len(timeline))
+				copy(renamed, timeline)
+			}
+			tweetCopy := *tweet
+			tweetCopy.Handler = newUserID
+			renamed[i] = &tweetCopy
+		}
+		if renamed != nil {
+			repository.tweets[userID] = renamed
+		}
+	}
+	return nil
+}
+
+// removeTweets removes the tweets that match from all the timelines
+func (repository *InMemoryFeedRepository) removeTweets(match func(tweet *Tweet) bool) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	for userID, timeline := range repository.tweets {
+		// Replace the timeline, since its tweets may be shared with the callers of GetUserTimeline
+		filtered := make([]*Tweet, 0, len(timeline))
+		for _, tweet := range timeline {
+			if !match(tweet) {
+				filtered = append(filtered, tweet)
+			}
+		}
+		if len(filtered) < len(timeline) {
+			repository.tweets[userID] = filtered
+		}
+	}
+}
+
 // AddTweet adds a tweet to the feed of followers (helper method for testing)
 func (repository *InMemoryFeedRepository) AddTweet(userID string, tweet *Tweet) {
 	repository.mu.Lock()
@@ -40,6 +40,20 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// DeleteUserTimeline mocks base method.
+func (m *MockRepository) DeleteUserTimeline(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTimeline", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserTimeline indicates an expected call of DeleteUserTimeline.
+func (mr *MockRepositoryMockRecorder) DeleteUserTimeline(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTimeline", reflect.TypeOf((*MockRepository)(nil).DeleteUserTimeline), ctx, userID)
+}
+
 // GetUserTimeline mocks base method.
 func (m *MockRepository) GetUserTimeline(ctx context.Context, userID string, limit, offset int) ([]*Tweet, error) {
 	m.ctrl.T.Helper()
@@ -54,3 +68,74 @@ func (mr *MockRepositoryMockRecorder) GetUserTimeline(ctx, userID, limit, offset
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTimeline", reflect.TypeOf((*MockRepository)(nil).GetUserTimeline), ctx, userID, limit, offset)
 }
+
+// HasUserTimeline mocks base method.
+func (m *MockRepository) HasUserTimeline(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUserTimeline", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUserTimeline indicates an expected call of HasUserTimeline.
+func (mr *MockRepositoryMockRecorder) HasUserTimeline(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUserTimeline", reflect.TypeOf((*MockRepository)(nil).HasUserTimeline), ctx, userID)
+}
+
+// RemoveTweet mocks base method.
+func (m *MockRepository) RemoveTweet(ctx context.Context, tweetID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTweet", ctx, tweetID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTweet indicates an expected call of RemoveTweet.
+func (mr *MockRepositoryMockRecorder) RemoveTweet(ctx, tweetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTweet", reflect.TypeOf((*MockRepository)(nil).RemoveTweet), ctx, tweetID)
+}
+
+// RemoveUserTweets mocks base method.
+func (m *MockRepository) RemoveUserTweets(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserTweets", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserTweets indicates an expected call of RemoveUserTweets.
+func (mr *MockRepositoryMockRecorder) RemoveUserTweets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserTweets", reflect.TypeOf((*MockRepository)(nil).RemoveUserTweets), ctx, userID)
+}
+
+// RenameUserTweets mocks base method.
+func (m *MockRepository) RenameUserTweets(ctx context.Context, oldUserID, newUserID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUserTweets", ctx, oldUserID, newUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUserTweets indicates an expected call of RenameUserTweets.
+func (mr *MockRepositoryMockRecorder) RenameUserTweets(ctx, oldUserID, newUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUserTweets", reflect.TypeOf((*MockRepository)(nil).RenameUserTweets), ctx, oldUserID, newUserID)
+}
+
+// SaveUserTimeline mocks base method.
+func (m *MockRepository) SaveUserTimeline(ctx context.Context, userID string, tweets []*Tweet) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUserTimeline", ctx, userID, tweets)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUserTimeline indicates an expected call of SaveUserTimeline.
+func (mr *MockRepositoryMockRecorder) SaveUserTimeline(ctx, userID, tweets any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUserTimeline", reflect.TypeOf((*MockRepository)(nil).SaveUserTimeline), ctx, userID, tweets)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // assertTweetsEqual compares two slices of Tweet pointers by their values regardless of order
@@ -209,3 +210,96 @@ func TestInMemoryFeedRepository_ConcurrentAccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, timeline, numOps)
 }
+
+func TestInMemoryFeedRepository_DeleteUserTimeline(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user2", Content: Content{Text: "Hello"}, CreatedAt: time.Now()})
+	repo.AddTweet("user2", &Tweet{ID: "2", Handler: "user1", Content: Content{Text: "Hi"}, CreatedAt: time.Now()})
+
+	err := repo.DeleteUserTimeline(ctx, "user1")
+	assert.NoError(t, err)
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, timeline)
+
+	// Other timelines are kept
+	timeline, err = repo.GetUserTimeline(ctx, "user2", 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, timeline, 1)
+
+	// Evicting a missing timeline is not an error
+	assert.NoError(t, repo.DeleteUserTimeline(ctx, "nonexistent"))
+}
+
+func TestInMemoryFeedRepository_RemoveTweet(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user3", Content: Content{Text: "Deleted"}, CreatedAt: time.Now()})
+	repo.AddTweet("user1", &Tweet{ID: "2", Handler: "user3", Content: Content{Text: "Kept"}, CreatedAt: time.Now()})
+	repo.AddTweet("user2", &Tweet{ID: "1", Handler: "user3", Content: Content{Text: "Deleted"}, CreatedAt: time.Now()})
+
+	before, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+
+	assert.NoError(t, repo.RemoveTweet(ctx, "1"))
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, timeline, 1)
+	assert.Equal(t, "2", timeline[0].ID)
+
+	// The emptied timeline is still cached, so it is not rebuilt
+	timeline, err = repo.GetUserTimeline(ctx, "user2", 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, timeline)
+	exists, err := repo.HasUserTimeline(ctx, "user2")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// Pages already served are not modified
+	assert.Len(t, before, 2)
+}
+
+func TestInMemoryFeedRepository_RemoveUserTweets(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user3", Content: Content{Text: "Deleted"}, CreatedAt: time.Now()})
+	repo.AddTweet("user1", &Tweet{ID: "2", Handler: "user2", Content: Content{Text: "Kept"}, CreatedAt: time.Now()})
+	repo.AddTweet("user2", &Tweet{ID: "3", Handler: "user3", Content: Content{Text: "Deleted"}, CreatedAt: time.Now()})
+
+	assert.NoError(t, repo.RemoveUserTweets(ctx, "user3"))
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, timeline, 1)
+	assert.Equal(t, "2", timeline[0].ID)
+
+	timeline, err = repo.GetUserTimeline(ctx, "user2", 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, timeline)
+}
+
+func TestInMemoryFeedRepository_RenameUserTweets(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	repo.AddTweet("user1", &Tweet{ID: "1", Handler: "user3", Content: Content{Text: "Renamed"}, CreatedAt: time.Now()})
+	repo.AddTweet("user1", &Tweet{ID: "2", Handler: "user2", Content: Content{Text: "Kept"}, CreatedAt: time.Now().Add(-time.Minute)})
+	previous, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	require.NoError(t, err)
+
+	assert.NoError(t, repo.RenameUserTweets(ctx, "user3", "user4"))
+
+	timeline, err := repo.GetUserTimeline(ctx, "user1", 10, 0)
+	assert.NoError(t, err)
+	require.Len(t, timeline, 2)
+	assert.Equal(t, "user4", timeline[0].Handler)
+	assert.Equal(t, "user2", timeline[1].Handler)
+	// The tweets handed out before are not modified
+	assert.Equal(t, "user3", previous[0].Handler)
+}
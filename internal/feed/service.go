@@ -3,23 +3,57 @@ package feed
 import (
 	"context"
 	"errors"
+	"log"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/google/uuid"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
 //go:generate mockgen -source=service.go -destination=service_mock.go -package=feed
 
+const (
+	// maxTimelineSize is the maximum number of tweets kept in a rebuilt timeline
+	maxTimelineSize = 800
+	// maxConcurrentFetches is the maximum number of concurrent requests to the tweets service
+	maxConcurrentFetches = 10
+)
+
 // Service defines the business logic for feed operations
 type Service interface {
 	GetUserTimeline(ctx context.Context, userID string, limit, offset int) (*TimelineResponse, error)
+	GetPopularTweets(ctx context.Context, limit int) ([]*Tweet, error)
+	EvictUserTimeline(ctx context.Context, userID string) error
+	RemoveTweet(ctx context.Context, tweetID string) error
+	DeleteUserData(ctx context.Context, userID string) error
+	HideUserTweets(ctx context.Context, userID string) error
+	RestoreUserTweets(ctx context.Context, userID string) error
+	RenameUser(ctx context.Context, oldUserID, newUserID string) error
 }
 
 type service struct {
-	repository Repository
+	repository   Repository
+	popularCache cache.Cache
+	usersClient  UsersClient
+	tweetsClient TweetsClient
+	publisher    queue.Publisher
+	rebuilds     singleflight.Group
 }
 
 // NewService creates a new feed service
-func NewService(repository Repository) Service {
+func NewService(repository Repository, popularCache cache.Cache, usersClient UsersClient, tweetsClient TweetsClient, publisher queue.Publisher) Service {
 	return &service{
-		repository: repository,
+		repository:   repository,
+		popularCache: popularCache,
+		usersClient:  usersClient,
+		tweetsClient: tweetsClient,
+		publisher:    publisher,
 	}
 }
 
@@ -37,11 +71,39 @@ func (service *service) GetUserTimeline(ctx context.Context, userID string, limi
 		offset = 0
 	}
 
+	// Rebuild the timeline if it is not cached (new, evicted or inactive user)
+	exists, err := service.repository.HasUserTimeline(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := service.rebuildUserTimeline(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	tweets, err := service.repository.GetUserTimeline(ctx, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
+	// Pad empty timelines (e.g. new users with no followees) with popular tweets
+	if len(tweets) == 0 && offset == 0 {
+		popular, err := service.GetPopularTweets(ctx, limit)
+		if err != nil {
+			log.Printf("failed to pad empty timeline of %s with popular tweets: %v", userID, err)
+		} else if len(popular) > 0 {
+			service.publishTimelineViewed(ctx, userID, popular)
+			return &TimelineResponse{
+				Tweets:     popular,
+				NextOffset: 0,
+				Popular:    true,
+			}, nil
+		}
+	}
+
+	service.publishTimelineViewed(ctx, userID, tweets)
+
 	// Calculate next offset
 	var nextOffset int
 	if len(tweets) < limit {
@@ -55,3 +117,180 @@ func (service *service) GetUserTimeline(ctx context.Context, userID string, limi
 		NextOffset: nextOffset,
 	}, nil
 }
+
+// publishTimelineViewed notifies the analytics service about the tweets served to a user, one impression each
+func (service *service) publishTimelineViewed(ctx context.Context, userID string, tweets []*Tweet) {
+	if len(tweets) == 0 {
+		return
+	}
+
+	tweetIDs := make([]string, 0, len(tweets))
+	for _, tweet := range tweets {
+		tweetIDs = append(tweetIDs, tweet.ID)
+	}
+	event := events.Event{
+		ID:        uuid.New().String(),
+		EventType: events.TypeTimelineViewed,
+		Handler:   userID,
+		TweetIDs:  tweetIDs,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := service.publisher.Publish(ctx, events.TopicTimelineViewed, userID, event); err != nil {
+		log.Printf("failed to publish timeline viewed event for %s: %v", userID, err)
+	}
+}
+
+// GetPopularTweets retrieves the most popular tweets, as ranked by the analytics service
+func (service *service) GetPopularTweets(ctx context.Context, limit int) ([]*Tweet, error) {
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+
+	var popular []cache.PopularTweet
+	if err := service.popularCache.Get(ctx, cache.KeyPopularTweets, &popular); err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return []*Tweet{}, nil
+		}
+		return nil, err
+	}
+	if len(popular) > limit {
+		popular = popular[:limit]
+	}
+
+	// Fetch the tweets keeping the ranking order
+	resolved := make([]*Tweet, len(popular))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxConcurrentFetches)
+	for i, entry := range popular {
+		group.Go(func() error {
+			tweet, err := service.tweetsClient.GetTweet(groupCtx, entry.TweetID)
+			if err != nil {
+				return err
+			}
+			resolved[i] = tweet
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Skip the tweets deleted since they were ranked
+	tweets := make([]*Tweet, 0, len(resolved))
+	for _, tweet := range resolved {
+		if tweet != nil {
+			tweets = append(tweets, tweet)
+		}
+	}
+
+	return tweets, nil
+}
+
+// EvictUserTimeline removes the cached timeline of a user
+func (service *service) EvictUserTimeline(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+
+	return service.repository.DeleteUserTimeline(ctx, userID)
+}
+
+// RemoveTweet removes a deleted tweet from the cached timelines
+func (service *service) RemoveTweet(ctx context.Context, tweetID string) error {
+	if tweetID == "" {
+		return errors.New("tweet ID is required")
+	}
+
+	return service.repository.RemoveTweet(ctx, tweetID)
+}
+
+// DeleteUserData evicts the cached timeline of a deleted user and removes their tweets from the other timelines
+func (service *service) DeleteUserData(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+
+	if err := service.repository.DeleteUserTimeline(ctx, userID); err != nil {
+		return err
+	}
+	return service.repository.RemoveUserTweets(ctx, userID)
+}
+
+// HideUserTweets removes the tweets of a deactivated user from the cached timelines
+func (service *service) HideUserTweets(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+
+	return service.repository.RemoveUserTweets(ctx, userID)
+}
+
+// RestoreUserTweets evicts the cached timelines of the followers of a reactivated user to rebuild them with their tweets
+func (service *service) RestoreUserTweets(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.New("user ID is required")
+	}
+
+	followers, err := service.usersClient.GetUserFollowers(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, follower := range followers {
+		if err := service.repository.DeleteUserTimeline(ctx, follower); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameUser evicts the cached timeline of a user that changed their handle and shows the new one on their cached tweets
+func (service *service) RenameUser(ctx context.Context, oldUserID, newUserID string) error {
+	if oldUserID == "" || newUserID == "" {
+		return errors.New("user ID is required")
+	}
+
+	if err := service.repository.DeleteUserTimeline(ctx, oldUserID); err != nil {
+		return err
+	}
+	return service.repository.RenameUserTweets(ctx, oldUserID, newUserID)
+}
+
+// rebuildUserTimeline builds the timeline of a user from the tweets of their followees, coalescing concurrent rebuilds
+func (service *service) rebuildUserTimeline(ctx context.Context, userID string) error {
+	// Detach from the caller cancellation, the rebuild is shared with other requests
+	rebuildCtx := context.WithoutCancel(ctx)
+
+	_, err, _ := service.rebuilds.Do(userID, func() (any, error) {
+		// Another request may have rebuilt the timeline in the meantime
+		exists, err := service.repository.HasUserTimeline(rebuildCtx, userID)
+		if err != nil || exists {
+			return nil, err
+		}
+
+		followees, err := service.usersClient.GetUserFollowees(rebuildCtx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		lists := make([][]*Tweet, len(followees))
+		group, groupCtx := errgroup.WithContext(rebuildCtx)
+		group.SetLimit(maxConcurrentFetches)
+		for i, followee := range followees {
+			group.Go(func() error {
+				tweets, err := service.tweetsClient.GetUserTweets(groupCtx, followee)
+				if err != nil {
+					return err
+				}
+				lists[i] = tweets
+				return nil
+			})
+		}
+		if err := group.Wait(); err != nil {
+			return nil, err
+		}
+
+		return nil, service.repository.SaveUserTimeline(rebuildCtx, userID, mergeTimelines(lists, maxTimelineSize))
+	})
+
+	return err
+}
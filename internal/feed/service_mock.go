@@ -40,6 +40,49 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// DeleteUserData mocks base method.
+func (m *MockService) DeleteUserData(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserData", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserData indicates an expected call of DeleteUserData.
+func (mr *MockServiceMockRecorder) DeleteUserData(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserData", reflect.TypeOf((*MockService)(nil).DeleteUserData), ctx, userID)
+}
+
+// EvictUserTimeline mocks base method.
+func (m *MockService) EvictUserTimeline(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictUserTimeline", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvictUserTimeline indicates an expected call of EvictUserTimeline.
+func (mr *MockServiceMockRecorder) EvictUserTimeline(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictUserTimeline", reflect.TypeOf((*MockService)(nil).EvictUserTimeline), ctx, userID)
+}
+
+// GetPopularTweets mocks base method.
+func (m *MockService) GetPopularTweets(ctx context.Context, limit int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPopularTweets", ctx, limit)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPopularTweets indicates an expected call of GetPopularTweets.
+func (mr *MockServiceMockRecorder) GetPopularTweets(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPopularTweets", reflect.TypeOf((*MockService)(nil).GetPopularTweets), ctx, limit)
+}
+
 // GetUserTimeline mocks base method.
 func (m *MockService) GetUserTimeline(ctx context.Context, userID string, limit, offset int) (*TimelineResponse, error) {
 	m.ctrl.T.Helper()
@@ -54,3 +97,59 @@ func (mr *MockServiceMockRecorder) GetUserTimeline(ctx, userID, limit, offset an
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTimeline", reflect.TypeOf((*MockService)(nil).GetUserTimeline), ctx, userID, limit, offset)
 }
+
+// HideUserTweets mocks base method.
+func (m *MockService) HideUserTweets(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HideUserTweets", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HideUserTweets indicates an expected call of HideUserTweets.
+func (mr *MockServiceMockRecorder) HideUserTweets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HideUserTweets", reflect.TypeOf((*MockService)(nil).HideUserTweets), ctx, userID)
+}
+
+// RemoveTweet mocks base method.
+func (m *MockService) RemoveTweet(ctx context.Context, tweetID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTweet", ctx, tweetID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTweet indicates an expected call of RemoveTweet.
+func (mr *MockServiceMockRecorder) RemoveTweet(ctx, tweetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTweet", reflect.TypeOf((*MockService)(nil).RemoveTweet), ctx, tweetID)
+}
+
+// RenameUser mocks base method.
+func (m *MockService) RenameUser(ctx context.Context, oldUserID, newUserID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", ctx, oldUserID, newUserID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockServiceMockRecorder) RenameUser(ctx, oldUserID, newUserID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockService)(nil).RenameUser), ctx, oldUserID, newUserID)
+}
+
+// RestoreUserTweets mocks base method.
+func (m *MockService) RestoreUserTweets(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserTweets", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUserTweets indicates an expected call of RestoreUserTweets.
+func (mr *MockServiceMockRecorder) RestoreUserTweets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserTweets", reflect.TypeOf((*MockService)(nil).RestoreUserTweets), ctx, userID)
+}
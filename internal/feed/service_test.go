@@ -3,9 +3,15 @@ package feed
 import (
 	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -16,7 +22,11 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	mockUsersClient := NewMockUsersClient(ctrl)
+	mockTweetsClient := NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, mockPublisher)
 
 	type args struct {
 		userID string
@@ -38,6 +48,10 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 		{
 			name: "successful timeline retrieval",
 			expectations: func() {
+				mockRepo.EXPECT().
+					HasUserTimeline(ctx, "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(ctx, "user1", 20, 0).
 					Return([]*Tweet{
@@ -48,6 +62,13 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 						},
 					}, nil).
 					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicTimelineViewed, "user1", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						assert.Equal(t, []string{"1"}, payload.(events.Event).TweetIDs)
+						return nil
+					}).
+					Times(1)
 			},
 			args: args{
 				userID: "user1",
@@ -86,6 +107,10 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 		{
 			name: "repository error",
 			expectations: func() {
+				mockRepo.EXPECT().
+					HasUserTimeline(ctx, "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(ctx, "user1", 20, 0).
 					Return(nil, errors.New("database error")).
@@ -102,7 +127,7 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 			},
 		},
 		{
-			name: "pagination with next offset",
+			name: "pagination with next offset, publishing error does not fail the timeline",
 			expectations: func() {
 				tweets := make([]*Tweet, 5)
 				for i := 0; i < 5; i++ {
@@ -112,10 +137,18 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 						Content: Content{Text: string(rune('f' + i))},
 					}
 				}
+				mockRepo.EXPECT().
+					HasUserTimeline(ctx, "user1").
+					Return(true, nil).
+					Times(1)
 				mockRepo.EXPECT().
 					GetUserTimeline(ctx, "user1", 5, 5).
 					Return(tweets, nil).
 					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicTimelineViewed, "user1", gomock.Any()).
+					Return(errors.New("queue error")).
+					Times(1)
 			},
 			args: args{
 				userID: "user1",
@@ -175,3 +208,589 @@ func TestFeedService_GetUserTimeline(t *testing.T) {
 		})
 	}
 }
+
+func TestFeedService_EvictUserTimeline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockUsersClient := NewMockUsersClient(ctrl)
+	mockTweetsClient := NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	service := NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		userID       string
+		want         error
+	}{
+		{
+			name: "evicts the timeline",
+			expectations: func() {
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			userID: "user1",
+			want:   nil,
+		},
+		{
+			name:         "empty user ID",
+			expectations: func() {},
+			userID:       "",
+			want:         errors.New("user ID is required"),
+		},
+		{
+			name: "repository error",
+			expectations: func() {
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user1").
+					Return(errors.New("cache error")).
+					Times(1)
+			},
+			userID: "user1",
+			want:   errors.New("cache error"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.EvictUserTimeline(ctx, tc.userID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_RemoveTweet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, cache.NewMockCache(ctrl), NewMockUsersClient(ctrl), NewMockTweetsClient(ctrl), queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		tweetID      string
+		want         error
+	}{
+		{
+			name: "removes the tweet from the timelines",
+			expectations: func() {
+				mockRepo.EXPECT().
+					RemoveTweet(ctx, "tweet1").
+					Return(nil).
+					Times(1)
+			},
+			tweetID: "tweet1",
+			want:    nil,
+		},
+		{
+			name:         "empty tweet ID",
+			expectations: func() {},
+			tweetID:      "",
+			want:         errors.New("tweet ID is required"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.RemoveTweet(ctx, tc.tweetID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_DeleteUserData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, cache.NewMockCache(ctrl), NewMockUsersClient(ctrl), NewMockTweetsClient(ctrl), queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		userID       string
+		want         error
+	}{
+		{
+			name: "evicts the timeline of the user and removes their tweets",
+			expectations: func() {
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user1").
+					Return(nil).
+					Times(1)
+				mockRepo.EXPECT().
+					RemoveUserTweets(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			userID: "user1",
+			want:   nil,
+		},
+		{
+			name:         "empty user ID",
+			expectations: func() {},
+			userID:       "",
+			want:         errors.New("user ID is required"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.DeleteUserData(ctx, tc.userID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_HideUserTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, cache.NewMockCache(ctrl), NewMockUsersClient(ctrl), NewMockTweetsClient(ctrl), queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		userID       string
+		want         error
+	}{
+		{
+			name: "removes the tweets of the user from the timelines",
+			expectations: func() {
+				mockRepo.EXPECT().
+					RemoveUserTweets(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			userID: "user1",
+			want:   nil,
+		},
+		{
+			name:         "empty user ID",
+			expectations: func() {},
+			userID:       "",
+			want:         errors.New("user ID is required"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.HideUserTweets(ctx, tc.userID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_RestoreUserTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockUsersClient := NewMockUsersClient(ctrl)
+	service := NewService(mockRepo, cache.NewMockCache(ctrl), mockUsersClient, NewMockTweetsClient(ctrl), queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		userID       string
+		want         error
+	}{
+		{
+			name: "evicts the timelines of the followers of the user",
+			expectations: func() {
+				mockUsersClient.EXPECT().
+					GetUserFollowers(ctx, "user1").
+					Return([]string{"user2", "user3"}, nil).
+					Times(1)
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user2").
+					Return(nil).
+					Times(1)
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user3").
+					Return(nil).
+					Times(1)
+			},
+			userID: "user1",
+			want:   nil,
+		},
+		{
+			name: "users service error",
+			expectations: func() {
+				mockUsersClient.EXPECT().
+					GetUserFollowers(ctx, "user1").
+					Return(nil, errors.New("users service unavailable")).
+					Times(1)
+			},
+			userID: "user1",
+			want:   errors.New("users service unavailable"),
+		},
+		{
+			name:         "empty user ID",
+			expectations: func() {},
+			userID:       "",
+			want:         errors.New("user ID is required"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.RestoreUserTweets(ctx, tc.userID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_RenameUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, cache.NewMockCache(ctrl), NewMockUsersClient(ctrl), NewMockTweetsClient(ctrl), queue.NewInMemoryQueue())
+
+	tt := []struct {
+		name         string
+		expectations func()
+		oldUserID    string
+		newUserID    string
+		want         error
+	}{
+		{
+			name: "evicts the timeline of the user and renames their tweets",
+			expectations: func() {
+				mockRepo.EXPECT().
+					DeleteUserTimeline(ctx, "user1").
+					Return(nil).
+					Times(1)
+				mockRepo.EXPECT().
+					RenameUserTweets(ctx, "user1", "user2").
+					Return(nil).
+					Times(1)
+			},
+			oldUserID: "user1",
+			newUserID: "user2",
+			want:      nil,
+		},
+		{
+			name:         "empty new user ID",
+			expectations: func() {},
+			oldUserID:    "user1",
+			newUserID:    "",
+			want:         errors.New("user ID is required"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.RenameUser(ctx, tc.oldUserID, tc.newUserID)
+
+			assert.Equal(t, tc.want, err)
+		})
+	}
+}
+
+func TestFeedService_GetUserTimelineRebuild(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockUsersClient := NewMockUsersClient(ctrl)
+	mockTweetsClient := NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	service := NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, queue.NewInMemoryQueue())
+
+	now := time.Now().UTC()
+	user2Tweets := []*Tweet{
+		{ID: "1", Handler: "user2", CreatedAt: now.Add(-3 * time.Minute)},
+		{ID: "3", Handler: "user2", CreatedAt: now.Add(-1 * time.Minute)},
+	}
+	user3Tweets := []*Tweet{
+		{ID: "2", Handler: "user3", CreatedAt: now.Add(-2 * time.Minute)},
+	}
+	timeline := []*Tweet{user2Tweets[1], user3Tweets[0], user2Tweets[0]}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         error
+	}{
+		{
+			name: "rebuilds a timeline that is not cached",
+			expectations: func() {
+				mockRepo.EXPECT().HasUserTimeline(gomock.Any(), "user1").Return(false, nil).Times(2)
+				mockUsersClient.EXPECT().GetUserFollowees(gomock.Any(), "user1").Return([]string{"user2", "user3"}, nil)
+				mockTweetsClient.EXPECT().GetUserTweets(gomock.Any(), "user2").Return(user2Tweets, nil)
+				mockTweetsClient.EXPECT().GetUserTweets(gomock.Any(), "user3").Return(user3Tweets, nil)
+				mockRepo.EXPECT().SaveUserTimeline(gomock.Any(), "user1", timeline).Return(nil)
+				mockRepo.EXPECT().GetUserTimeline(ctx, "user1", 20, 0).Return(timeline, nil)
+			},
+			want: nil,
+		},
+		{
+			name: "users service error",
+			expectations: func() {
+				mockRepo.EXPECT().HasUserTimeline(gomock.Any(), "user1").Return(false, nil).Times(2)
+				mockUsersClient.EXPECT().GetUserFollowees(gomock.Any(), "user1").Return(nil, errors.New("users service error"))
+			},
+			want: errors.New("users service error"),
+		},
+		{
+			name: "tweets service error",
+			expectations: func() {
+				mockRepo.EXPECT().HasUserTimeline(gomock.Any(), "user1").Return(false, nil).Times(2)
+				mockUsersClient.EXPECT().GetUserFollowees(gomock.Any(), "user1").Return([]string{"user2"}, nil)
+				mockTweetsClient.EXPECT().GetUserTweets(gomock.Any(), "user2").Return(nil, errors.New("tweets service error"))
+			},
+			want: errors.New("tweets service error"),
+		},
+		{
+			name: "cache error",
+			expectations: func() {
+				mockRepo.EXPECT().HasUserTimeline(ctx, "user1").Return(false, errors.New("cache error"))
+			},
+			want: errors.New("cache error"),
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetUserTimeline(ctx, "user1", 20, 0)
+
+			assert.Equal(t, tc.want, err)
+			if tc.want == nil {
+				assert.Equal(t, timeline, result.Tweets)
+			}
+		})
+	}
+}
+
+func TestFeedService_GetPopularTweets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockUsersClient := NewMockUsersClient(ctrl)
+	mockTweetsClient := NewMockTweetsClient(ctrl)
+	mockCache := cache.NewMockCache(ctrl)
+	service := NewService(mockRepo, mockCache, mockUsersClient, mockTweetsClient, queue.NewInMemoryQueue())
+
+	ranked := []cache.PopularTweet{
+		{TweetID: "1", Handler: "user2", Score: 3},
+		{TweetID: "2", Handler: "user3", Score: 2},
+		{TweetID: "3", Handler: "user2", Score: 1},
+	}
+	cached := func(popular []cache.PopularTweet) func(ctx context.Context, key string, dest any) error {
+		return func(ctx context.Context, key string, dest any) error {
+			*dest.(*[]cache.PopularTweet) = popular
+			return nil
+		}
+	}
+
+	type want struct {
+		tweets []*Tweet
+		err    error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		limit        int
+		want         want
+	}{
+		{
+			name: "resolves the ranked tweets in order",
+			expectations: func() {
+				mockCache.EXPECT().Get(ctx, cache.KeyPopularTweets, gomock.Any()).DoAndReturn(cached(ranked))
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "1").Return(&Tweet{ID: "1", Handler: "user2"}, nil)
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "2").Return(&Tweet{ID: "2", Handler: "user3"}, nil)
+			},
+			limit: 2,
+			want: want{
+				tweets: []*Tweet{{ID: "1", Handler: "user2"}, {ID: "2", Handler: "user3"}},
+				err:    nil,
+			},
+		},
+		{
+			name: "skips deleted tweets",
+			expectations: func() {
+				mockCache.EXPECT().Get(ctx, cache.KeyPopularTweets, gomock.Any()).DoAndReturn(cached(ranked))
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "1").Return(&Tweet{ID: "1", Handler: "user2"}, nil)
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "2").Return(nil, nil)
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "3").Return(&Tweet{ID: "3", Handler: "user2"}, nil)
+			},
+			limit: 0,
+			want: want{
+				tweets: []*Tweet{{ID: "1", Handler: "user2"}, {ID: "3", Handler: "user2"}},
+				err:    nil,
+			},
+		},
+		{
+			name: "no popular tweets ranked yet",
+			expectations: func() {
+				mockCache.EXPECT().Get(ctx, cache.KeyPopularTweets, gomock.Any()).Return(cache.ErrCacheMiss)
+			},
+			limit: 20,
+			want: want{
+				tweets: []*Tweet{},
+				err:    nil,
+			},
+		},
+		{
+			name: "cache error",
+			expectations: func() {
+				mockCache.EXPECT().Get(ctx, cache.KeyPopularTweets, gomock.Any()).Return(errors.New("cache error"))
+			},
+			limit: 20,
+			want: want{
+				tweets: nil,
+				err:    errors.New("cache error"),
+			},
+		},
+		{
+			name: "tweets service error",
+			expectations: func() {
+				mockCache.EXPECT().Get(ctx, cache.KeyPopularTweets, gomock.Any()).DoAndReturn(cached(ranked[:1]))
+				mockTweetsClient.EXPECT().GetTweet(gomock.Any(), "1").Return(nil, errors.New("tweets service error"))
+			},
+			limit: 20,
+			want: want{
+				tweets: nil,
+				err:    errors.New("tweets service error"),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			result, err := service.GetPopularTweets(ctx, tc.limit)
+
+			assert.Equal(t, tc.want.err, err)
+			assert.Equal(t, tc.want.tweets, result)
+		})
+	}
+}
+
+func TestFeedService_GetUserTimelinePadsWithPopularTweets(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	assert.NoError(t, repo.SaveUserTimeline(ctx, "user1", []*Tweet{}))
+	popularCache := cache.NewInMemoryCache()
+	tweetsClient := &staticTweetsClient{tweets: []*Tweet{{ID: "1", Handler: "user2"}}}
+	service := NewService(repo, popularCache, nil, tweetsClient, queue.NewInMemoryQueue())
+
+	// Nothing ranked yet, the empty timeline is returned as is
+	result, err := service.GetUserTimeline(ctx, "user1", 20, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Tweets)
+	assert.False(t, result.Popular)
+
+	assert.NoError(t, popularCache.Set(ctx, cache.KeyPopularTweets, []cache.PopularTweet{{TweetID: "1", Handler: "user2", Score: 1}}, time.Minute))
+
+	result, err = service.GetUserTimeline(ctx, "user1", 20, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &TimelineResponse{Tweets: tweetsClient.tweets, NextOffset: 0, Popular: true}, result)
+
+	// Later pages are not padded
+	result, err = service.GetUserTimeline(ctx, "user1", 20, 20)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Tweets)
+	assert.False(t, result.Popular)
+}
+
+// blockingUsersClient is a UsersClient that waits for a signal before answering and counts its calls
+type blockingUsersClient struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (client *blockingUsersClient) GetUserFollowees(ctx context.Context, userID string) ([]string, error) {
+	client.calls.Add(1)
+	<-client.release
+	return []string{"user2"}, nil
+}
+
+func (client *blockingUsersClient) GetUserFollowers(ctx context.Context, userID string) ([]string, error) {
+	return nil, nil
+}
+
+// staticTweetsClient is a TweetsClient that always returns the same tweets
+type staticTweetsClient struct {
+	tweets []*Tweet
+}
+
+func (client *staticTweetsClient) GetTweet(ctx context.Context, id string) (*Tweet, error) {
+	for _, tweet := range client.tweets {
+		if tweet.ID == id {
+			return tweet, nil
+		}
+	}
+	return nil, nil
+}
+
+func (client *staticTweetsClient) GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error) {
+	return client.tweets, nil
+}
+
+func TestFeedService_GetUserTimelineCoalescesRebuilds(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryFeedRepository()
+	usersClient := &blockingUsersClient{release: make(chan struct{})}
+	tweetsClient := &staticTweetsClient{tweets: []*Tweet{{ID: "1", Handler: "user2", CreatedAt: time.Now()}}}
+	service := NewService(repo, cache.NewInMemoryCache(), usersClient, tweetsClient, queue.NewInMemoryQueue())
+
+	numRequests := 10
+	var wg sync.WaitGroup
+	errCh := make(chan error, numRequests)
+	for i := 0; i < numRequests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := service.GetUserTimeline(ctx, "user1", 20, 0)
+			if err == nil && len(result.Tweets) != 1 {
+				err = errors.New("unexpected timeline")
+			}
+			errCh <- err
+		}()
+	}
+
+	// Let the requests pile up on the rebuild before releasing it
+	assert.Eventually(t, func() bool { return usersClient.calls.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(usersClient.release)
+	wg.Wait()
+
+	close(errCh)
+	for err := range errCh {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), usersClient.calls.Load())
+}
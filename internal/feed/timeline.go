@@ -0,0 +1,57 @@
+package feed
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// mergeTimelines merges lists of tweets into a timeline of at most limit tweets, newest first
+func mergeTimelines(lists [][]*Tweet, limit int) []*Tweet {
+	cursors := &timelineHeap{}
+	for _, list := range lists {
+		if len(list) == 0 {
+			continue
+		}
+		sorted := make([]*Tweet, len(list))
+		copy(sorted, list)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		})
+		*cursors = append(*cursors, sorted)
+	}
+	heap.Init(cursors)
+
+	timeline := []*Tweet{}
+	for cursors.Len() > 0 && len(timeline) < limit {
+		list := (*cursors)[0]
+		timeline = append(timeline, list[0])
+
+		if len(list) == 1 {
+			heap.Pop(cursors)
+			continue
+		}
+		(*cursors)[0] = list[1:]
+		heap.Fix(cursors, 0)
+	}
+
+	return timeline
+}
+
+// timelineHeap is a max-heap of sorted tweet lists ordered by their newest tweet
+type timelineHeap [][]*Tweet
+
+func (h timelineHeap) Len() int { return len(h) }
+
+func (h timelineHeap) Less(i, j int) bool { return h[i][0].CreatedAt.After(h[j][0].CreatedAt) }
+
+func (h timelineHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *timelineHeap) Push(x any) { *h = append(*h, x.([]*Tweet)) }
+
+func (h *timelineHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
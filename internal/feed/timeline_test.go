@@ -0,0 +1,68 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeTimelines(t *testing.T) {
+	now := time.Now().UTC()
+	tweet := func(id string, minutesAgo int) *Tweet {
+		return &Tweet{ID: id, CreatedAt: now.Add(-time.Duration(minutesAgo) * time.Minute)}
+	}
+
+	tt := []struct {
+		name  string
+		lists [][]*Tweet
+		limit int
+		want  []string
+	}{
+		{
+			name:  "no lists",
+			lists: nil,
+			limit: 10,
+			want:  []string{},
+		},
+		{
+			name: "merges lists newest first",
+			lists: [][]*Tweet{
+				{tweet("a", 1), tweet("c", 3), tweet("e", 5)},
+				{tweet("b", 2), tweet("d", 4)},
+				{},
+			},
+			limit: 10,
+			want:  []string{"a", "b", "c", "d", "e"},
+		},
+		{
+			name: "sorts unsorted lists",
+			lists: [][]*Tweet{
+				{tweet("c", 3), tweet("a", 1)},
+				{tweet("b", 2)},
+			},
+			limit: 10,
+			want:  []string{"a", "b", "c"},
+		},
+		{
+			name: "keeps at most limit tweets",
+			lists: [][]*Tweet{
+				{tweet("a", 1), tweet("c", 3)},
+				{tweet("b", 2), tweet("d", 4)},
+			},
+			limit: 3,
+			want:  []string{"a", "b", "c"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			timeline := mergeTimelines(tc.lists, tc.limit)
+
+			ids := []string{}
+			for _, tweet := range timeline {
+				ids = append(ids, tweet.ID)
+			}
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+}
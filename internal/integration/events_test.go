@@ -0,0 +1,213 @@
+// Package integration tests the events exchanged between the services, each one wired as in its main with its own
+// Kafka queue and consumer group, connected to a mock Kafka cluster.
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/internal/analytics"
+	"github.com/lucas-soria/microblogging/internal/feed"
+	"github.com/lucas-soria/microblogging/internal/tweets"
+	"github.com/lucas-soria/microblogging/internal/users"
+	"github.com/lucas-soria/microblogging/pkg/cache"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cluster is a mock Kafka cluster shared by the queues of the services of a test
+type cluster struct {
+	t       *testing.T
+	kafka   *kafka.MockCluster
+	ctx     context.Context
+	queues  []*queue.KafkaQueue
+	running sync.WaitGroup
+}
+
+// newCluster creates a mock Kafka cluster, stopped with the queues at the end of the test
+func newCluster(t *testing.T) *cluster {
+	mockCluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &cluster{t: t, kafka: mockCluster, ctx: ctx}
+	t.Cleanup(func() {
+		cancel()
+		c.running.Wait()
+		for _, messageQueue := range c.queues {
+			messageQueue.Close()
+		}
+		mockCluster.Close()
+	})
+	return c
+}
+
+// newQueue creates the queue of a service, with the consumer group of the service
+func (c *cluster) newQueue(groupID string) *queue.KafkaQueue {
+	messageQueue, err := queue.NewKafkaQueue(queue.KafkaConfig{
+		Brokers:         c.kafka.BootstrapServers(),
+		GroupID:         groupID,
+		RetryBackoff:    10 * time.Millisecond,
+		MaxRetryBackoff: 100 * time.Millisecond,
+	})
+	require.NoError(c.t, err)
+	c.queues = append(c.queues, messageQueue)
+	return messageQueue
+}
+
+// start consumes the messages of the queues, once every service subscribed its consumers
+func (c *cluster) start() {
+	for _, messageQueue := range c.queues {
+		c.running.Add(1)
+		go func() {
+			defer c.running.Done()
+			assert.NoError(c.t, messageQueue.Run(c.ctx))
+		}()
+	}
+}
+
+func TestEvents_TweetPostedReachesAnalytics(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t)
+
+	// Tweets service
+	tweetsQueue := c.newQueue("tweets-service")
+	tweetService := tweets.NewService(tweets.NewInMemoryTweetRepository(), tweetsQueue, nil, nil)
+
+	// Analytics service
+	analyticsQueue := c.newQueue("analytics-service")
+	analyticsRepo := analytics.NewInMemoryRepository()
+	analyticsService := analytics.NewService(analyticsRepo, nil, nil)
+	analytics.NewConsumer(analyticsService, analytics.NewSpamDetector(analyticsRepo), 3, time.Millisecond).Subscribe(analyticsQueue)
+
+	c.start()
+
+	_, err := tweetService.CreateTweet(ctx, &tweets.Tweet{Handler: "user1", Content: tweets.Content{Text: "Hello"}})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		userAnalytics, err := analyticsService.GetUserAnalytics(ctx, "user1")
+		return err == nil && userAnalytics.TweetCount == 1
+	}, 30*time.Second, 50*time.Millisecond)
+}
+
+func TestEvents_UserInactiveReachesFeed(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t)
+
+	// Analytics service, every user with analytics is idle
+	analyticsQueue := c.newQueue("analytics-service")
+	analyticsRepo := analytics.NewInMemoryRepository()
+	require.NoError(t, analyticsRepo.ProcessEvent(ctx, &analytics.Event{ID: "event-1", EventType: "timeline_viewed", Handler: "user1", Timestamp: time.Now().Add(-time.Hour)}))
+	sweeper := analytics.NewInactivitySweeper(analyticsRepo, analyticsQueue, -time.Hour, time.Hour)
+
+	// Feed service
+	feedQueue := c.newQueue("feed-service")
+	feedRepo := feed.NewInMemoryFeedRepository()
+	feedRepo.AddTweet("user1", &feed.Tweet{ID: "1", Handler: "user2", CreatedAt: time.Now()})
+	feedService := feed.NewService(feedRepo, cache.NewInMemoryCache(), nil, nil, feedQueue)
+	feed.NewConsumer(feedService).Subscribe(feedQueue)
+
+	c.start()
+
+	count, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+
+	// The cached timeline of the inactive user is evicted
+	assert.Eventually(t, func() bool {
+		cached, err := feedRepo.HasUserTimeline(ctx, "user1")
+		return err == nil && !cached
+	}, 30*time.Second, 50*time.Millisecond)
+}
+
+func TestEvents_TweetEngagedReachesAnalytics(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t)
+
+	// Tweets service
+	tweetsQueue := c.newQueue("tweets-service")
+	tweetService := tweets.NewService(tweets.NewInMemoryTweetRepository(), tweetsQueue, nil, nil)
+
+	// Analytics service
+	analyticsQueue := c.newQueue("analytics-service")
+	analyticsRepo := analytics.NewInMemoryRepository()
+	analyticsService := analytics.NewService(analyticsRepo, nil, nil)
+	analytics.NewConsumer(analyticsService, analytics.NewSpamDetector(analyticsRepo), 3, time.Millisecond).Subscribe(analyticsQueue)
+
+	c.start()
+
+	tweet, err := tweetService.CreateTweet(ctx, &tweets.Tweet{Handler: "author", Content: tweets.Content{Text: "Hello"}})
+	require.NoError(t, err)
+	// The engagements are only counted on tweets tracked since they were created
+	require.Eventually(t, func() bool {
+		_, err := analyticsRepo.GetTweetEngagement(ctx, tweet.ID)
+		return err == nil
+	}, 30*time.Second, 50*time.Millisecond)
+
+	_, err = tweetService.CreateTweet(ctx, &tweets.Tweet{Handler: "user2", Content: tweets.Content{Text: "Reply"}, ReplyToID: &tweet.ID})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		engagement, err := analyticsRepo.GetTweetEngagement(ctx, tweet.ID)
+		return err == nil && engagement.Replies == 1
+	}, 30*time.Second, 50*time.Millisecond)
+}
+
+func TestEvents_UserDeletionCompletesAcrossServices(t *testing.T) {
+	ctx := context.Background()
+	c := newCluster(t)
+
+	// Users service
+	usersQueue := c.newQueue("users-service")
+	userService := users.NewService(users.NewInMemoryUserRepository(), usersQueue, 24*time.Hour)
+	users.NewConsumer(userService).Subscribe(usersQueue)
+	_, err := userService.CreateUser(ctx, &users.User{ID: "1", Handler: "user1", FirstName: "User", LastName: "One"})
+	require.NoError(t, err)
+
+	// Tweets service
+	tweetsQueue := c.newQueue("tweets-service")
+	tweetRepo := tweets.NewInMemoryTweetRepository()
+	tweetService := tweets.NewService(tweetRepo, tweetsQueue, nil, nil)
+	tweets.NewUserDeletionConsumer(tweetService, tweetsQueue).Subscribe(tweetsQueue)
+	_, err = tweetRepo.Create(ctx, &tweets.Tweet{ID: "1", Handler: "user1", Status: tweets.TweetStatusPublished})
+	require.NoError(t, err)
+
+	// Feed service
+	feedQueue := c.newQueue("feed-service")
+	feedRepo := feed.NewInMemoryFeedRepository()
+	feedRepo.AddTweet("user1", &feed.Tweet{ID: "2", Handler: "user2", CreatedAt: time.Now()})
+	feedService := feed.NewService(feedRepo, cache.NewInMemoryCache(), nil, nil, feedQueue)
+	feed.NewUserDeletionConsumer(feedService, feedQueue).Subscribe(feedQueue)
+
+	// Analytics service
+	analyticsQueue := c.newQueue("analytics-service")
+	analyticsRepo := analytics.NewInMemoryRepository()
+	analyticsService := analytics.NewService(analyticsRepo, nil, nil)
+	analytics.NewUserDeletionConsumer(analyticsService, analyticsQueue).Subscribe(analyticsQueue)
+	require.NoError(t, analyticsRepo.ProcessEvent(ctx, &analytics.Event{ID: "event-1", EventType: "tweet_posted", Handler: "user1", Timestamp: time.Now()}))
+
+	c.start()
+
+	require.NoError(t, userService.DeleteUser(ctx, "user1"))
+
+	// Every service acknowledges the deletion of its data
+	assert.Eventually(t, func() bool {
+		deletion, err := userService.GetUserDeletion(ctx, "user1")
+		return err == nil && deletion.Status == users.DeletionStatusCompleted
+	}, 30*time.Second, 50*time.Millisecond)
+
+	userTweets, err := tweetService.GetUserTweets(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, userTweets)
+	cached, err := feedRepo.HasUserTimeline(ctx, "user1")
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	_, err = analyticsService.GetUserAnalytics(ctx, "user1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package tweets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+//go:generate mockgen -source=client.go -destination=client_mock.go -package=tweets
+
+// UsersClient defines the operations the tweets service needs from the users service
+type UsersClient interface {
+	GetUser(ctx context.Context, handler string) (*User, error)
+}
+
+// User is a user of the users service, by the ID the tweets reference and their current handler
+type User struct {
+	ID      string `json:"id"`
+	Handler string `json:"handler"`
+}
+
+// serviceID identifies the tweets service in requests to other services
+const serviceID = "tweets-service"
+
+// ErrUserNotFound is returned when the users service has no user with a handler
+var ErrUserNotFound = errors.New("user not found")
+
+// HTTPUsersClient is an HTTP implementation of the UsersClient interface
+type HTTPUsersClient struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPUsersClient creates a new users service HTTP client
+func NewHTTPUsersClient(baseURL string, timeout time.Duration) *HTTPUsersClient {
+	return &HTTPUsersClient{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// GetUser retrieves the user with a handler, or the user a previous handler still redirects to
+func (client *HTTPUsersClient) GetUser(ctx context.Context, handler string) (*User, error) {
+	endpoint := fmt.Sprintf("%s/v1/users/%s", client.baseURL, url.PathEscape(handler))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-User-Id", serviceID)
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user %s: %w", handler, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrUserNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get user %s: unexpected status code %d", handler, response.StatusCode)
+	}
+
+	var user User
+	if err := json.NewDecoder(response.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to decode user %s: %w", handler, err)
+	}
+	return &user, nil
+}
@@ -0,0 +1,44 @@
+package tweets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPUsersClient_GetUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-User-Id") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/users/user1", "/v1/users/old-user1":
+			w.Write([]byte(`{"id":"6f2f3c4e-9a1b-4c55-8e0d-1f2a3b4c5d6e","handler":"user1","first_name":"User","last_name":"One"}`))
+		case "/v1/users/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPUsersClient(server.URL, time.Second)
+
+	for _, handler := range []string{"user1", "old-user1"} {
+		user, err := client.GetUser(context.Background(), handler)
+		require.NoError(t, err)
+		assert.Equal(t, &User{ID: "6f2f3c4e-9a1b-4c55-8e0d-1f2a3b4c5d6e", Handler: "user1"}, user)
+	}
+
+	_, err := client.GetUser(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = client.GetUser(context.Background(), "broken")
+	assert.EqualError(t, err, "failed to get user broken: unexpected status code 500")
+}
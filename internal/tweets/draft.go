@@ -0,0 +1,25 @@
+package tweets
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDraftNotFound is returned when a draft does not exist or belongs to another user
+var ErrDraftNotFound = errors.New("draft not found")
+
+// Draft is an unfinished tweet only visible to its author, its content is validated when it is published
+type Draft struct {
+	ID        string     `gorm:"primaryKey;type:uuid" json:"id"`
+	UserID    string     `gorm:"type:uuid;not null;index" json:"-"`
+	Handler   string     `gorm:"type:varchar(255);not null" json:"handler"`
+	Content   Content    `gorm:"type:jsonb;not null" json:"content"` // Media only have their IDs until published
+	PublishAt *time.Time `json:"publish_at,omitempty"`               // Schedules the tweet once published
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `gorm:"index" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Draft
+func (Draft) TableName() string {
+	return "drafts"
+}
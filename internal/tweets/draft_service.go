@@ -0,0 +1,178 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate mockgen -source=draft_service.go -destination=draft_service_mock.go -package=tweets
+
+// ErrInvalidDraft is returned when saving a draft without text, media or poll
+var ErrInvalidDraft = errors.New("invalid draft")
+
+// DraftService defines the business logic for draft operations
+type DraftService interface {
+	CreateDraft(ctx context.Context, draft *Draft) (*Draft, error)
+	GetDraft(ctx context.Context, id, userID string) (*Draft, error)
+	GetDrafts(ctx context.Context, userID string) ([]*Draft, error)
+	UpdateDraft(ctx context.Context, draft *Draft) (*Draft, error)
+	DeleteDraft(ctx context.Context, id, userID string) error
+	PublishDraft(ctx context.Context, id, userID string) (*Tweet, error)
+}
+
+type draftService struct {
+	repository   DraftRepository
+	tweetService Service
+	users        *userIDs
+}
+
+// NewDraftService creates a new draft service, publishing the drafts through the tweet service
+func NewDraftService(repository DraftRepository, tweetService Service, users UsersClient) DraftService {
+	return &draftService{
+		repository:   repository,
+		tweetService: tweetService,
+		users:        &userIDs{repository: repository, users: users},
+	}
+}
+
+// CreateDraft saves a new draft of a user
+func (service *draftService) CreateDraft(ctx context.Context, draft *Draft) (*Draft, error) {
+	if err := validateDraft(draft); err != nil {
+		return nil, err
+	}
+	author, err := service.users.resolve(ctx, draft.Handler)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	draft.UserID, draft.Handler = author.ID, author.Handler
+	draft.ID = uuid.New().String()
+	draft.CreatedAt, draft.UpdatedAt = now, now
+	if err := service.repository.CreateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (service *draftService) GetDraft(ctx context.Context, id, userID string) (*Draft, error) {
+	if id == "" {
+		return nil, errors.New("draft ID cannot be empty")
+	}
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+	if uuid.Validate(id) != nil {
+		return nil, ErrDraftNotFound
+	}
+
+	author, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := service.repository.GetDraft(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if draft.UserID != author {
+		return nil, ErrDraftNotFound
+	}
+	return draft, nil
+}
+
+// GetDrafts retrieves the drafts of a user, the most recently updated first
+func (service *draftService) GetDrafts(ctx context.Context, userID string) ([]*Draft, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return []*Draft{}, nil
+	}
+	return service.repository.GetDraftsByUserID(ctx, id)
+}
+
+// UpdateDraft replaces the content and publish time of a draft of a user
+func (service *draftService) UpdateDraft(ctx context.Context, draft *Draft) (*Draft, error) {
+	existing, err := service.GetDraft(ctx, draft.ID, draft.Handler)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDraft(draft); err != nil {
+		return nil, err
+	}
+
+	draft.UserID, draft.Handler = existing.UserID, existing.Handler
+	draft.CreatedAt, draft.UpdatedAt = existing.CreatedAt, time.Now().UTC()
+	if err := service.repository.UpdateDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (service *draftService) DeleteDraft(ctx context.Context, id, userID string) error {
+	if _, err := service.GetDraft(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return service.repository.DeleteDraft(ctx, id)
+}
+
+// PublishDraft deletes a draft of a user and creates its tweet, restoring the draft if the tweet cannot be created
+func (service *draftService) PublishDraft(ctx context.Context, id, userID string) (*Tweet, error) {
+	draft, err := service.GetDraft(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.repository.DeleteDraft(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// The tweet service normalizes the content, which must not change the draft to restore
+	content := draft.Content
+	if content.Poll != nil {
+		poll := *content.Poll
+		content.Poll = &poll
+	}
+	tweet, err := service.tweetService.CreateTweet(ctx, &Tweet{
+		Handler:   draft.Handler,
+		Content:   content,
+		PublishAt: draft.PublishAt,
+	})
+	if err != nil {
+		if restoreErr := service.repository.CreateDraft(ctx, draft); restoreErr != nil {
+			log.Printf("failed to restore draft %s after failing to publish it: %v", draft.ID, restoreErr)
+		}
+		return nil, err
+	}
+	return tweet, nil
+}
+
+// validateDraft checks that a draft has an author and some content, and keeps only the content set by its author
+func validateDraft(draft *Draft) error {
+	if draft.Handler == "" {
+		return errors.New("user ID cannot be empty")
+	}
+	if draft.Content.Text == "" && len(draft.Content.Media) == 0 && draft.Content.Poll == nil {
+		return fmt.Errorf("%w: a draft must have text, media or a poll", ErrInvalidDraft)
+	}
+
+	for i, attachment := range draft.Content.Media {
+		draft.Content.Media[i] = Attachment{ID: attachment.ID}
+	}
+	draft.Content.URLs, draft.Content.Preview = nil, nil
+	if draft.PublishAt != nil {
+		publishAt := draft.PublishAt.UTC()
+		draft.PublishAt = &publishAt
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: draft_service.go
+//
+// Generated by this command:
+//
+//	mockgen -source=draft_service.go -destination=draft_service_mock.go -package=tweets
+//
+
+// Package tweets is a generated GoMock package.
+package tweets
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDraftService is a mock of DraftService interface.
+type MockDraftService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDraftServiceMockRecorder
+	isgomock struct{}
+}
+
+// MockDraftServiceMockRecorder is the mock recorder for MockDraftService.
+type MockDraftServiceMockRecorder struct {
+	mock *MockDraftService
+}
+
+// NewMockDraftService creates a new mock instance.
+func NewMockDraftService(ctrl *gomock.Controller) *MockDraftService {
+	mock := &MockDraftService{ctrl: ctrl}
+	mock.recorder = &MockDraftServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDraftService) EXPECT() *MockDraftServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateDraft mocks base method.
+func (m *MockDraftService) CreateDraft(ctx context.Context, draft *Draft) (*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDraft", ctx, draft)
+	ret0, _ := ret[0].(*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDraft indicates an expected call of CreateDraft.
+func (mr *MockDraftServiceMockRecorder) CreateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDraft", reflect.TypeOf((*MockDraftService)(nil).CreateDraft), ctx, draft)
+}
+
+// DeleteDraft mocks base method.
+func (m *MockDraftService) DeleteDraft(ctx context.Context, id, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDraft", ctx, id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDraft indicates an expected call of DeleteDraft.
+func (mr *MockDraftServiceMockRecorder) DeleteDraft(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDraft", reflect.TypeOf((*MockDraftService)(nil).DeleteDraft), ctx, id, userID)
+}
+
+// GetDraft mocks base method.
+func (m *MockDraftService) GetDraft(ctx context.Context, id, userID string) (*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraft", ctx, id, userID)
+	ret0, _ := ret[0].(*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraft indicates an expected call of GetDraft.
+func (mr *MockDraftServiceMockRecorder) GetDraft(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraft", reflect.TypeOf((*MockDraftService)(nil).GetDraft), ctx, id, userID)
+}
+
+// GetDrafts mocks base method.
+func (m *MockDraftService) GetDrafts(ctx context.Context, userID string) ([]*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrafts", ctx, userID)
+	ret0, _ := ret[0].([]*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrafts indicates an expected call of GetDrafts.
+func (mr *MockDraftServiceMockRecorder) GetDrafts(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockDraftService)(nil).GetDrafts), ctx, userID)
+}
+
+// PublishDraft mocks base method.
+func (m *MockDraftService) PublishDraft(ctx context.Context, id, userID string) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDraft", ctx, id, userID)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishDraft indicates an expected call of PublishDraft.
+func (mr *MockDraftServiceMockRecorder) PublishDraft(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDraft", reflect.TypeOf((*MockDraftService)(nil).PublishDraft), ctx, id, userID)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockDraftService) UpdateDraft(ctx context.Context, draft *Draft) (*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", ctx, draft)
+	ret0, _ := ret[0].(*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockDraftServiceMockRecorder) UpdateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockDraftService)(nil).UpdateDraft), ctx, draft)
+}
@@ -0,0 +1,155 @@
+package tweets
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDraftService_SaveDrafts(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	service := NewDraftService(repository, NewService(repository, queue.NewInMemoryQueue(), nil, nil), nil)
+
+	draft, err := service.CreateDraft(ctx, &Draft{
+		Handler: "owner",
+		Content: Content{
+			Text:    "Unfinished https://example.com",
+			Media:   []Attachment{{ID: "media-1", Type: MediaTypeImage, HasThumbnail: true}},
+			URLs:    []string{"https://injected.com/"},
+			Preview: &LinkPreview{Title: "Injected"},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("only the content set by the author is kept", func(t *testing.T) {
+		assert.Equal(t, Content{Text: "Unfinished https://example.com", Media: []Attachment{{ID: "media-1"}}}, draft.Content)
+		assert.NotEmpty(t, draft.ID)
+		assert.Equal(t, draft.CreatedAt, draft.UpdatedAt)
+	})
+
+	t.Run("empty draft", func(t *testing.T) {
+		_, err := service.CreateDraft(ctx, &Draft{Handler: "owner"})
+
+		assert.EqualError(t, err, "invalid draft: a draft must have text, media or a poll")
+	})
+
+	t.Run("drafts are private to their owner", func(t *testing.T) {
+		_, err := service.GetDraft(ctx, draft.ID, "other")
+		assert.ErrorIs(t, err, ErrDraftNotFound)
+
+		_, err = service.UpdateDraft(ctx, &Draft{ID: draft.ID, Handler: "other", Content: Content{Text: "Mine"}})
+		assert.ErrorIs(t, err, ErrDraftNotFound)
+
+		assert.ErrorIs(t, service.DeleteDraft(ctx, draft.ID, "other"), ErrDraftNotFound)
+
+		_, err = service.PublishDraft(ctx, draft.ID, "other")
+		assert.ErrorIs(t, err, ErrDraftNotFound)
+
+		drafts, err := service.GetDrafts(ctx, "other")
+		assert.NoError(t, err)
+		assert.Empty(t, drafts)
+	})
+
+	t.Run("invalid draft ID", func(t *testing.T) {
+		_, err := service.GetDraft(ctx, "invalid", "owner")
+
+		assert.ErrorIs(t, err, ErrDraftNotFound)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		publishAt := time.Now().Add(time.Hour)
+
+		updated, err := service.UpdateDraft(ctx, &Draft{ID: draft.ID, Handler: "owner", Content: Content{Text: "Almost done"}, PublishAt: &publishAt})
+
+		require.NoError(t, err)
+		assert.Equal(t, draft.CreatedAt, updated.CreatedAt)
+		stored, err := service.GetDraft(ctx, draft.ID, "owner")
+		require.NoError(t, err)
+		assert.Equal(t, "Almost done", stored.Content.Text)
+		assert.Equal(t, publishAt.UTC(), *stored.PublishAt)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		assert.NoError(t, service.DeleteDraft(ctx, draft.ID, "owner"))
+
+		drafts, err := service.GetDrafts(ctx, "owner")
+		assert.NoError(t, err)
+		assert.Empty(t, drafts)
+	})
+}
+
+func TestDraftService_PublishDraft(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	service := NewDraftService(repository, NewService(repository, queue.NewInMemoryQueue(), nil, nil), nil)
+
+	t.Run("the tweet is created and the draft deleted", func(t *testing.T) {
+		draft, err := service.CreateDraft(ctx, &Draft{Handler: "owner", Content: Content{Text: "Done https://example.com"}})
+		require.NoError(t, err)
+
+		tweet, err := service.PublishDraft(ctx, draft.ID, "owner")
+
+		require.NoError(t, err)
+		assert.Equal(t, TweetStatusPublished, tweet.Status)
+		assert.Equal(t, []string{"https://example.com/"}, tweet.Content.URLs)
+		_, err = service.GetDraft(ctx, draft.ID, "owner")
+		assert.ErrorIs(t, err, ErrDraftNotFound)
+		userTweets, err := repository.GetByUserID(ctx, database.LegacyUserIDOf("owner"))
+		assert.NoError(t, err)
+		assert.Len(t, userTweets, 1)
+	})
+
+	t.Run("scheduled draft", func(t *testing.T) {
+		publishAt := time.Now().Add(time.Hour)
+		draft, err := service.CreateDraft(ctx, &Draft{Handler: "scheduler", Content: Content{Text: "Later"}, PublishAt: &publishAt})
+		require.NoError(t, err)
+
+		tweet, err := service.PublishDraft(ctx, draft.ID, "scheduler")
+
+		require.NoError(t, err)
+		assert.Equal(t, TweetStatusScheduled, tweet.Status)
+	})
+
+	t.Run("an invalid tweet keeps the draft", func(t *testing.T) {
+		poll := &Poll{Options: []PollOption{{Text: " Go "}, {Text: "Go"}}, ClosesAt: time.Now().Add(time.Hour)}
+		draft, err := service.CreateDraft(ctx, &Draft{Handler: "owner", Content: Content{Text: strings.Repeat("a", 300), Poll: poll}})
+		require.NoError(t, err)
+
+		_, err = service.PublishDraft(ctx, draft.ID, "owner")
+
+		assert.ErrorIs(t, err, ErrContentTooLong)
+		stored, err := service.GetDraft(ctx, draft.ID, "owner")
+		require.NoError(t, err)
+		assert.Equal(t, draft, stored)
+	})
+
+	t.Run("concurrent publications create a single tweet", func(t *testing.T) {
+		draft, err := service.CreateDraft(ctx, &Draft{Handler: "concurrent", Content: Content{Text: "Once"}})
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := service.PublishDraft(ctx, draft.ID, "concurrent"); err != nil {
+					assert.ErrorIs(t, err, ErrDraftNotFound)
+				}
+			}()
+		}
+		wg.Wait()
+
+		userTweets, err := repository.GetByUserID(ctx, database.LegacyUserIDOf("concurrent"))
+		assert.NoError(t, err)
+		assert.Len(t, userTweets, 1)
+	})
+}
@@ -0,0 +1,47 @@
+package tweets
+
+import (
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+// graphemeClusters splits a text into its user-perceived characters
+func graphemeClusters(text string) []string {
+	var clusters []string
+	graphemes := uniseg.NewGraphemes(text)
+	for graphemes.Next() {
+		clusters = append(clusters, graphemes.Str())
+	}
+	return clusters
+}
+
+// isRegionalIndicator reports whether a rune is one of the regional indicators that make up the flags
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isEmojiPresentation reports whether a rune is in the blocks of the symbols shown as emoji by default
+func isEmojiPresentation(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF && !isRegionalIndicator(r) && !(r >= 0x1F3FB && r <= 0x1F3FF)) ||
+		(r >= 0x231A && r <= 0x23FF) ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2B05 && r <= 0x2B55)
+}
+
+// isEmojiCluster reports whether a user-perceived character is shown as an emoji
+func isEmojiCluster(cluster string) bool {
+	first, size := utf8.DecodeRuneInString(cluster)
+	switch {
+	case isEmojiPresentation(first):
+		return true
+	case isRegionalIndicator(first):
+		return len(cluster) > size // A lone regional indicator is not a flag
+	}
+	for _, r := range cluster[size:] {
+		if r == 0x20E3 || r == 0xFE0F {
+			return true
+		}
+	}
+	return false
+}
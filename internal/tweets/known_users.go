@@ -0,0 +1,53 @@
+package tweets
+
+import (
+	"context"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+)
+
+// KnownUser is the current handler of a user with data in the tweets service, by their ID
+type KnownUser struct {
+	ID        string    `gorm:"primaryKey;type:uuid"`
+	Handler   string    `gorm:"type:varchar(255);not null;index"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the KnownUser
+func (KnownUser) TableName() string {
+	return "known_users"
+}
+
+// userIDs resolves the IDs the data of the users reference by their handlers
+type userIDs struct {
+	repository KnownUserRepository
+	users      UsersClient
+}
+
+// known returns the ID of the user known by a handler, "" if the user has no data
+func (ids *userIDs) known(ctx context.Context, handler string) (string, error) {
+	return ids.repository.GetKnownUserID(ctx, handler)
+}
+
+// resolve returns the ID and current handler of the user with a handler, asking the users service if it is not known
+func (ids *userIDs) resolve(ctx context.Context, handler string) (*User, error) {
+	id, err := ids.known(ctx, handler)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		return &User{ID: id, Handler: handler}, nil
+	}
+
+	user := &User{ID: database.LegacyUserIDOf(handler), Handler: handler}
+	if ids.users != nil {
+		if user, err = ids.users.GetUser(ctx, handler); err != nil {
+			return nil, err
+		}
+	}
+	if err := ids.repository.SaveKnownUser(ctx, user.ID, user.Handler); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
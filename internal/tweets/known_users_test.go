@@ -0,0 +1,93 @@
+package tweets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lucas-soria/microblogging/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserIDs_Resolve(t *testing.T) {
+	ctx := context.Background()
+
+	tt := []struct {
+		name         string
+		handler      string
+		withUsers    bool
+		expectations func(repository *InMemoryTweetRepository, users *MockUsersClient)
+		want         *User
+		wantErr      error
+	}{
+		{
+			name:      "known user",
+			handler:   "user1",
+			withUsers: true,
+			expectations: func(repository *InMemoryTweetRepository, users *MockUsersClient) {
+				require.NoError(t, repository.SaveKnownUser(ctx, "user1-id", "user1"))
+			},
+			want: &User{ID: "user1-id", Handler: "user1"},
+		},
+		{
+			name:      "user not known yet",
+			handler:   "user1",
+			withUsers: true,
+			expectations: func(repository *InMemoryTweetRepository, users *MockUsersClient) {
+				users.EXPECT().GetUser(ctx, "user1").Return(&User{ID: "user1-id", Handler: "user1"}, nil)
+			},
+			want: &User{ID: "user1-id", Handler: "user1"},
+		},
+		{
+			name:      "user not known yet by a previous handler",
+			handler:   "old-user1",
+			withUsers: true,
+			expectations: func(repository *InMemoryTweetRepository, users *MockUsersClient) {
+				users.EXPECT().GetUser(ctx, "old-user1").Return(&User{ID: "user1-id", Handler: "user1"}, nil)
+			},
+			want: &User{ID: "user1-id", Handler: "user1"},
+		},
+		{
+			name:         "user not known yet without a users service",
+			handler:      "user1",
+			expectations: func(repository *InMemoryTweetRepository, users *MockUsersClient) {},
+			want:         &User{ID: database.LegacyUserIDOf("user1"), Handler: "user1"},
+		},
+		{
+			name:      "users service error",
+			handler:   "user1",
+			withUsers: true,
+			expectations: func(repository *InMemoryTweetRepository, users *MockUsersClient) {
+				users.EXPECT().GetUser(ctx, "user1").Return(nil, ErrUserNotFound)
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := NewInMemoryTweetRepository()
+			users := NewMockUsersClient(gomock.NewController(t))
+			tc.expectations(repository, users)
+
+			ids := &userIDs{repository: repository}
+			if tc.withUsers {
+				ids.users = users
+			}
+			user, err := ids.resolve(ctx, tc.handler)
+
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, user)
+
+			// The user is known by their current handler from now on
+			id, err := repository.GetKnownUserID(ctx, tc.want.Handler)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want.ID, id)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package tweets
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxTweetURLs is the maximum number of links of a tweet that are stored with its content
+const maxTweetURLs = 10
+
+// linkPattern matches the http and https links of a text, with any punctuation following them
+var linkPattern = regexp.MustCompile(`(?i)https?://[^\s]+`)
+
+// trackingParams are the query parameters that only track where a link was shared, removed when normalizing it
+var trackingParams = []string{"fbclid", "gclid", "dclid", "msclkid", "mc_eid", "igshid"}
+
+// findLinks returns the start and end indexes of the links of a text, without the punctuation that ends them
+func findLinks(text string) [][]int {
+	links := linkPattern.FindAllStringIndex(text, -1)
+	for _, link := range links {
+		for link[1] > link[0] {
+			last := text[link[1]-1]
+			if strings.IndexByte(`.,;:!?'"*>]`, last) < 0 &&
+				(last != ')' || strings.Count(text[link[0]:link[1]], "(") >= strings.Count(text[link[0]:link[1]], ")")) {
+				break
+			}
+			link[1]--
+		}
+	}
+	return links
+}
+
+// links returns the links of a text, as written
+func links(text string) []string {
+	var found []string
+	for _, link := range findLinks(text) {
+		found = append(found, text[link[0]:link[1]])
+	}
+	return found
+}
+
+// normalizeURL returns the canonical form of a link, reporting false if it is not a valid http or https URL
+func normalizeURL(link string) (string, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return "", false
+	}
+	switch port := parsed.Port(); {
+	case port != "" && !(parsed.Scheme == "http" && port == "80") && !(parsed.Scheme == "https" && port == "443"):
+		parsed.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		parsed.Host = "[" + host + "]" // IPv6 address
+	default:
+		parsed.Host = host
+	}
+	parsed.User = nil
+	parsed.Fragment, parsed.RawFragment = "", ""
+	if parsed.Path == "" {
+		parsed.Path = "/"
+	}
+
+	if parsed.RawQuery != "" {
+		query, err := url.ParseQuery(parsed.RawQuery)
+		if err == nil {
+			for param := range query {
+				if strings.HasPrefix(strings.ToLower(param), "utm_") || containsFold(trackingParams, param) {
+					query.Del(param)
+				}
+			}
+			parsed.RawQuery = query.Encode() // Sorted by key
+		}
+	}
+	parsed.ForceQuery = false
+	return parsed.String(), true
+}
+
+// containsFold reports whether a list contains a value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// LinkPreview is the card of a link shared in a tweet, built from the OpenGraph metadata of the linked page
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
@@ -0,0 +1,61 @@
+package tweets
+
+import (
+	"errors"
+	"time"
+)
+
+// Media types
+const (
+	MediaTypeImage = "image"
+	MediaTypeGIF   = "gif"
+	MediaTypeVideo = "video"
+)
+
+// maxTweetMedia is the maximum number of media attachments of a tweet
+const maxTweetMedia = 4
+
+// ErrMediaNotFound is returned when a media or its blob does not exist
+var ErrMediaNotFound = errors.New("media not found")
+
+// Media is an uploaded image, GIF or video that tweets can attach
+type Media struct {
+	ID           string    `gorm:"primaryKey;type:uuid" json:"id"`
+	UserID       string    `gorm:"type:uuid;not null;index" json:"-"` // Uploader, the only user that can attach it
+	Handler      string    `gorm:"type:varchar(255);not null" json:"handler"`
+	Type         string    `gorm:"type:varchar(20);not null" json:"type"`
+	MIMEType     string    `gorm:"column:mime_type;type:varchar(100);not null" json:"mime_type"`
+	Size         int64     `gorm:"not null" json:"size"`
+	Width        int       `json:"width,omitempty"` // Only known for images and GIFs
+	Height       int       `json:"height,omitempty"`
+	StorageKey   string    `gorm:"type:varchar(255);not null" json:"-"`
+	ThumbnailKey string    `gorm:"type:varchar(255)" json:"-"` // Empty for videos
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Media
+func (Media) TableName() string {
+	return "media"
+}
+
+// Attachment returns the attachment of the media to a tweet
+func (m *Media) Attachment() Attachment {
+	return Attachment{
+		ID:           m.ID,
+		Type:         m.Type,
+		MIMEType:     m.MIMEType,
+		Width:        m.Width,
+		Height:       m.Height,
+		HasThumbnail: m.ThumbnailKey != "",
+	}
+}
+
+// Attachment is a media attached to a tweet, stored with its content
+type Attachment struct {
+	ID           string `json:"id"`
+	Type         string `json:"type,omitempty"`
+	MIMEType     string `json:"mime_type,omitempty"`
+	Width        int    `json:"width,omitempty"`
+	Height       int    `json:"height,omitempty"`
+	HasThumbnail bool   `json:"has_thumbnail,omitempty"`
+}
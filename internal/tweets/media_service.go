@@ -0,0 +1,295 @@
+package tweets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	// Register the decoders of the image formats accepted
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/google/uuid"
+)
+
+//go:generate mockgen -source=media_service.go -destination=media_service_mock.go -package=tweets
+
+const (
+	// thumbnailSize is the maximum width and height of the thumbnails
+	thumbnailSize = 320
+	// maxImagePixels is the maximum number of pixels of the images, larger ones are not decoded
+	maxImagePixels = 50_000_000
+	// sniffLength is the number of bytes used to detect the MIME type of an upload
+	sniffLength = 512
+)
+
+// ErrUnsupportedMediaType is returned when uploading a file that is not an accepted image, GIF or video
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// ErrMediaTooLarge is returned when uploading a file larger than the limit of its type
+var ErrMediaTooLarge = errors.New("media is too large")
+
+// MediaLimits are the maximum sizes in bytes of the uploads of each media type
+type MediaLimits struct {
+	MaxImageSize int64
+	MaxGIFSize   int64
+	MaxVideoSize int64
+}
+
+// DefaultMediaLimits are the upload limits used unless configured otherwise
+var DefaultMediaLimits = MediaLimits{
+	MaxImageSize: 5 << 20,
+	MaxGIFSize:   15 << 20,
+	MaxVideoSize: 512 << 20,
+}
+
+// mediaTypes are the media types of the accepted MIME types
+var mediaTypes = map[string]string{
+	"image/jpeg": MediaTypeImage,
+	"image/png":  MediaTypeImage,
+	"image/gif":  MediaTypeGIF,
+	"video/mp4":  MediaTypeVideo,
+	"video/webm": MediaTypeVideo,
+}
+
+// MediaService defines the business logic for media operations
+type MediaService interface {
+	UploadMedia(ctx context.Context, handler string, file io.Reader) (*Media, error)
+	GetMedia(ctx context.Context, id string) (*Media, error)
+	OpenMedia(ctx context.Context, id string, thumbnail bool) (*Media, io.ReadCloser, error)
+}
+
+type mediaService struct {
+	repository MediaRepository
+	store      MediaStore
+	limits     MediaLimits
+	users      *userIDs
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(repository MediaRepository, store MediaStore, limits MediaLimits, users UsersClient) MediaService {
+	return &mediaService{
+		repository: repository,
+		store:      store,
+		limits:     limits,
+		users:      &userIDs{repository: repository, users: users},
+	}
+}
+
+// UploadMedia validates the type and size of a file by its content and stores it, with a thumbnail for images and GIFs
+func (service *mediaService) UploadMedia(ctx context.Context, handler string, file io.Reader) (*Media, error) {
+	if handler == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	// The MIME type is detected from the content, the one declared by the client is not trusted
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("failed to read media: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%w: empty file", ErrUnsupportedMediaType)
+	}
+	mimeType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	mediaType, ok := mediaTypes[mimeType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mimeType)
+	}
+
+	uploader, err := service.users.resolve(ctx, handler)
+	if err != nil {
+		return nil, err
+	}
+	media := &Media{
+		ID:        uuid.New().String(),
+		UserID:    uploader.ID,
+		Handler:   uploader.Handler,
+		Type:      mediaType,
+		MIMEType:  mimeType,
+		CreatedAt: time.Now().UTC(),
+	}
+	media.StorageKey = "media/" + media.ID
+
+	// Read one byte over the limit to tell a file at the limit from a larger one
+	maxSize := service.maxSize(mediaType)
+	content := &countingReader{reader: io.LimitReader(io.MultiReader(bytes.NewReader(head[:n]), file), maxSize+1)}
+
+	if mediaType == MediaTypeVideo {
+		// Videos are streamed to the store, and deleted if they turn out to be too large
+		if err := service.store.Put(ctx, media.StorageKey, content); err != nil {
+			return nil, err
+		}
+		media.Size = content.count
+		if media.Size > maxSize {
+			service.deleteBlobs(ctx, media)
+			return nil, fmt.Errorf("%w: the maximum %s size is %d bytes", ErrMediaTooLarge, mediaType, maxSize)
+		}
+	} else {
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read media: %w", err)
+		}
+		media.Size = int64(len(data))
+		if media.Size > maxSize {
+			return nil, fmt.Errorf("%w: the maximum %s size is %d bytes", ErrMediaTooLarge, mediaType, maxSize)
+		}
+		thumbnail, err := service.decodeImage(media, data)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := service.store.Put(ctx, media.StorageKey, bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		media.ThumbnailKey = media.StorageKey + "_thumbnail"
+		if err := service.store.Put(ctx, media.ThumbnailKey, bytes.NewReader(thumbnail)); err != nil {
+			service.deleteBlobs(ctx, media)
+			return nil, err
+		}
+	}
+
+	if err := service.repository.CreateMedia(ctx, media); err != nil {
+		service.deleteBlobs(ctx, media)
+		return nil, err
+	}
+	return media, nil
+}
+
+// decodeImage sets the dimensions of an image or GIF media and returns its JPEG thumbnail
+func (service *mediaService) decodeImage(media *Media, data []byte) ([]byte, error) {
+	// Check the dimensions before decoding, so small files with huge dimensions are not decoded
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrUnsupportedMediaType, media.MIMEType)
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("%w: the maximum %s dimensions are %d pixels", ErrMediaTooLarge, media.Type, maxImagePixels)
+	}
+	media.Width, media.Height = config.Width, config.Height
+
+	img, _, err := image.Decode(bytes.NewReader(data)) // The first frame of GIFs
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrUnsupportedMediaType, media.MIMEType)
+	}
+	var thumbnail bytes.Buffer
+	if err := jpeg.Encode(&thumbnail, resizeImage(img, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	return thumbnail.Bytes(), nil
+}
+
+// deleteBlobs deletes the stored blobs of a media that could not be created, a failure is only logged
+func (service *mediaService) deleteBlobs(ctx context.Context, media *Media) {
+	for _, key := range []string{media.StorageKey, media.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := service.store.Delete(ctx, key); err != nil {
+			log.Printf("failed to delete media blob %s: %v", key, err)
+		}
+	}
+}
+
+// maxSize returns the upload limit of a media type
+func (service *mediaService) maxSize(mediaType string) int64 {
+	switch mediaType {
+	case MediaTypeGIF:
+		return service.limits.MaxGIFSize
+	case MediaTypeVideo:
+		return service.limits.MaxVideoSize
+	}
+	return service.limits.MaxImageSize
+}
+
+func (service *mediaService) GetMedia(ctx context.Context, id string) (*Media, error) {
+	if id == "" {
+		return nil, errors.New("media ID cannot be empty")
+	}
+	if uuid.Validate(id) != nil {
+		return nil, ErrMediaNotFound
+	}
+
+	return service.repository.GetMedia(ctx, id)
+}
+
+// OpenMedia opens the blob of a media, or of its thumbnail, the caller must close it
+func (service *mediaService) OpenMedia(ctx context.Context, id string, thumbnail bool) (*Media, io.ReadCloser, error) {
+	media, err := service.GetMedia(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key := media.StorageKey
+	if thumbnail {
+		if media.ThumbnailKey == "" {
+			return nil, nil, fmt.Errorf("%w: %s has no thumbnail", ErrMediaNotFound, media.Type)
+		}
+		key = media.ThumbnailKey
+	}
+	blob, err := service.store.Open(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return media, blob, nil
+}
+
+// resizeImage scales an image down to fit in a square of the size, blending the transparent pixels over white
+func resizeImage(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	if width >= height {
+		width, height = size, max(height*size/width, 1)
+	} else {
+		width, height = max(width*size/height, 1), size
+	}
+
+	resized := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		top, bottom := bounds.Min.Y+y*bounds.Dy()/height, bounds.Min.Y+(y+1)*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			left, right := bounds.Min.X+x*bounds.Dx()/width, bounds.Min.X+(x+1)*bounds.Dx()/width
+			var r, g, b, a, pixels uint64
+			for sy := top; sy < max(bottom, top+1); sy++ {
+				for sx := left; sx < max(right, left+1); sx++ {
+					pr, pg, pb, pa := img.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					pixels++
+				}
+			}
+			// The colors are premultiplied by the alpha, so blending over white adds the transparency to them
+			transparency := 0xffff - a/pixels
+			resized.Set(x, y, color.RGBA64{
+				R: uint16(r/pixels + transparency),
+				G: uint16(g/pixels + transparency),
+				B: uint16(b/pixels + transparency),
+				A: 0xffff,
+			})
+		}
+	}
+	return resized
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (reader *countingReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	reader.count += int64(n)
+	return n, err
+}
@@ -0,0 +1,204 @@
+package tweets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// testImage returns an image of the size with a transparent left half
+func testImage(width, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := width / 2; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	var buffer bytes.Buffer
+	require.NoError(t, png.Encode(&buffer, testImage(width, height)))
+	return buffer.Bytes()
+}
+
+// testVideo returns the start of an MP4 file followed by size bytes
+func testVideo(size int) []byte {
+	return append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), make([]byte, size)...)
+}
+
+func TestMediaService_UploadMedia(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo := NewInMemoryTweetRepository()
+	store := NewLocalMediaStore(dir)
+	service := NewMediaService(repo, store, MediaLimits{MaxImageSize: 1 << 20, MaxGIFSize: 1 << 20, MaxVideoSize: 1024}, nil)
+
+	var gifData bytes.Buffer
+	require.NoError(t, gif.Encode(&gifData, testImage(100, 50), nil))
+
+	type want struct {
+		mediaType string
+		mimeType  string
+		width     int
+		height    int
+		thumbnail image.Point // Zero if there is no thumbnail
+		err       string
+	}
+
+	tt := []struct {
+		name string
+		data []byte
+		want want
+	}{
+		{
+			name: "image with thumbnail",
+			data: encodePNG(t, 640, 480),
+			want: want{mediaType: MediaTypeImage, mimeType: "image/png", width: 640, height: 480, thumbnail: image.Pt(320, 240)},
+		},
+		{
+			name: "small image is not scaled up",
+			data: encodePNG(t, 10, 20),
+			want: want{mediaType: MediaTypeImage, mimeType: "image/png", width: 10, height: 20, thumbnail: image.Pt(10, 20)},
+		},
+		{
+			name: "gif with thumbnail",
+			data: gifData.Bytes(),
+			want: want{mediaType: MediaTypeGIF, mimeType: "image/gif", width: 100, height: 50, thumbnail: image.Pt(100, 50)},
+		},
+		{
+			name: "video without thumbnail",
+			data: testVideo(1000),
+			want: want{mediaType: MediaTypeVideo, mimeType: "video/mp4"},
+		},
+		{
+			name: "unsupported type",
+			data: []byte("Hello, world!"),
+			want: want{err: "unsupported media type: text/plain"},
+		},
+		{
+			name: "empty file",
+			data: nil,
+			want: want{err: "unsupported media type: empty file"},
+		},
+		{
+			name: "corrupted image",
+			data: encodePNG(t, 640, 480)[:100],
+			want: want{err: "unsupported media type: invalid image/png"},
+		},
+		{
+			name: "image too large",
+			data: append(encodePNG(t, 10, 10), make([]byte, 1<<20)...),
+			want: want{err: "media is too large: the maximum image size is 1048576 bytes"},
+		},
+		{
+			name: "video too large",
+			data: testVideo(1024),
+			want: want{err: "media is too large: the maximum video size is 1024 bytes"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			media, err := service.UploadMedia(ctx, "user1", bytes.NewReader(tc.data))
+
+			if tc.want.err != "" {
+				assert.EqualError(t, err, tc.want.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user1", media.Handler)
+			assert.Equal(t, tc.want.mediaType, media.Type)
+			assert.Equal(t, tc.want.mimeType, media.MIMEType)
+			assert.Equal(t, int64(len(tc.data)), media.Size)
+			assert.Equal(t, tc.want.width, media.Width)
+			assert.Equal(t, tc.want.height, media.Height)
+
+			stored, err := repo.GetMedia(ctx, media.ID)
+			require.NoError(t, err)
+			assert.Equal(t, media, stored)
+
+			_, blob, err := service.OpenMedia(ctx, media.ID, false)
+			require.NoError(t, err)
+			data, err := io.ReadAll(blob)
+			require.NoError(t, err)
+			require.NoError(t, blob.Close())
+			assert.Equal(t, tc.data, data)
+
+			_, blob, err = service.OpenMedia(ctx, media.ID, true)
+			if tc.want.thumbnail == (image.Point{}) {
+				assert.ErrorIs(t, err, ErrMediaNotFound)
+				return
+			}
+			require.NoError(t, err)
+			thumbnail, err := jpeg.Decode(blob)
+			require.NoError(t, err)
+			require.NoError(t, blob.Close())
+			assert.Equal(t, tc.want.thumbnail, thumbnail.Bounds().Size())
+		})
+	}
+
+	// The blobs of the rejected uploads are deleted
+	entries, err := os.ReadDir(filepath.Join(dir, "media"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 7) // 3 images and GIFs with their thumbnails, and a video
+}
+
+func TestMediaService_UploadMediaRepositoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockStore := NewMockMediaStore(ctrl)
+	service := NewMediaService(mockRepo, mockStore, DefaultMediaLimits, nil)
+	expectKnownUsers(mockRepo)
+
+	var keys []string
+	mockStore.EXPECT().Put(ctx, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string, _ io.Reader) error {
+			keys = append(keys, key)
+			return nil
+		}).
+		Times(2)
+	mockRepo.EXPECT().CreateMedia(ctx, gomock.Any()).Return(errors.New("database error"))
+	mockStore.EXPECT().Delete(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, key string) error {
+			assert.Contains(t, keys, key)
+			return nil
+		}).
+		Times(2)
+
+	_, err := service.UploadMedia(ctx, "user1", bytes.NewReader(encodePNG(t, 10, 10)))
+
+	assert.EqualError(t, err, "database error")
+	assert.True(t, strings.HasSuffix(keys[1], "_thumbnail"))
+}
+
+func TestMediaService_GetMedia(t *testing.T) {
+	ctx := context.Background()
+	service := NewMediaService(NewInMemoryTweetRepository(), NewLocalMediaStore(t.TempDir()), DefaultMediaLimits, nil)
+
+	_, err := service.GetMedia(ctx, "not-a-uuid")
+	assert.Equal(t, ErrMediaNotFound, err)
+
+	_, err = service.GetMedia(ctx, "00000000-0000-0000-0000-000000000000")
+	assert.Equal(t, ErrMediaNotFound, err)
+
+	_, err = service.GetMedia(ctx, "")
+	assert.EqualError(t, err, "media ID cannot be empty")
+}
@@ -0,0 +1,96 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//go:generate mockgen -source=media_store.go -destination=media_store_mock.go -package=tweets
+
+// MediaStore stores the blobs of the media by key
+type MediaStore interface {
+	Put(ctx context.Context, key string, data io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// LocalMediaStore is a local filesystem implementation of the MediaStore interface, each key is a file under its directory
+type LocalMediaStore struct {
+	dir string
+}
+
+// NewLocalMediaStore creates a new local filesystem media store
+func NewLocalMediaStore(dir string) *LocalMediaStore {
+	return &LocalMediaStore{
+		dir: dir,
+	}
+}
+
+// Put writes a blob under a temporary name and then renames it, so an interrupted write is never read as a complete blob
+func (store *LocalMediaStore) Put(ctx context.Context, key string, data io.Reader) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".media-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create media file: %w", err)
+	}
+	defer os.Remove(file.Name()) // Nothing to remove once it is renamed
+	defer file.Close()
+
+	if _, err := io.Copy(file, data); err != nil {
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write media file: %w", err)
+	}
+	return nil
+}
+
+// Open opens a blob for reading, the caller must close it
+func (store *LocalMediaStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("failed to open media file: %w", err)
+	}
+	return file, nil
+}
+
+// Delete deletes a blob, deleting a missing blob is not an error
+func (store *LocalMediaStore) Delete(ctx context.Context, key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete media file: %w", err)
+	}
+	return nil
+}
+
+// path returns the path of the file of a key, which must not point outside the directory
+func (store *LocalMediaStore) path(key string) (string, error) {
+	if !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid media key %q", key)
+	}
+	return filepath.Join(store.dir, key), nil
+}
@@ -0,0 +1,45 @@
+package tweets
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalMediaStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalMediaStore(dir)
+
+	require.NoError(t, store.Put(ctx, "media/blob", strings.NewReader("content")))
+
+	blob, err := store.Open(ctx, "media/blob")
+	require.NoError(t, err)
+	data, err := io.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	assert.Equal(t, "content", string(data))
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Join(dir, "media"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "media/blob"))
+	_, err = store.Open(ctx, "media/blob")
+	assert.Equal(t, ErrMediaNotFound, err)
+
+	// Deleting a missing blob is not an error
+	assert.NoError(t, store.Delete(ctx, "media/blob"))
+
+	// Keys cannot point outside the directory
+	assert.EqualError(t, store.Put(ctx, "../outside", strings.NewReader("content")), `invalid media key "../outside"`)
+	_, err = store.Open(ctx, "/etc/passwd")
+	assert.EqualError(t, err, `invalid media key "/etc/passwd"`)
+}
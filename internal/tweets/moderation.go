@@ -0,0 +1,168 @@
+package tweets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+//go:generate mockgen -source=moderation.go -destination=moderation_mock.go -package=tweets
+
+// Moderation actions
+const (
+	ModerationAllow  = "allow"
+	ModerationReject = "reject"
+	ModerationHold   = "hold"
+)
+
+// ModerationResult is the decision of a moderator on a tweet, with the reason to reject or hold it
+type ModerationResult struct {
+	Action string
+	Reason string
+}
+
+// Moderator decides whether a tweet is published, rejected or held for review before it is stored
+type Moderator interface {
+	Moderate(ctx context.Context, tweet *Tweet) (ModerationResult, error)
+}
+
+// ModerationRules are the keywords, regular expressions and link domains that trigger a moderation action
+type ModerationRules struct {
+	Keywords []string `json:"keywords"` // Matched as whole words, ignoring case
+	Patterns []string `json:"patterns"` // Regular expressions, matched against the text as is
+	Domains  []string `json:"domains"`  // Matched against the links to the domain and its subdomains
+}
+
+// ModerationConfig configures the rules of the tweets that are rejected, and of the ones held for review
+type ModerationConfig struct {
+	Reject ModerationRules `json:"reject"`
+	Hold   ModerationRules `json:"hold"`
+}
+
+// NewModerator creates the moderator of a configuration, the reject rules take precedence over the hold rules
+func NewModerator(config ModerationConfig) (Moderator, error) {
+	var moderators ChainModerator
+	for _, action := range []string{ModerationReject, ModerationHold} {
+		rules := config.Reject
+		if action == ModerationHold {
+			rules = config.Hold
+		}
+
+		blocklist, err := NewBlocklistModerator(action, rules.Keywords, rules.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		moderators = append(moderators, blocklist, NewDomainDenylistModerator(action, rules.Domains))
+	}
+	return moderators, nil
+}
+
+// ChainModerator runs several moderators, a tweet is rejected if any of them rejects it, otherwise it is held if any of them holds it
+type ChainModerator []Moderator
+
+// Moderate implements the Moderator interface
+func (moderators ChainModerator) Moderate(ctx context.Context, tweet *Tweet) (ModerationResult, error) {
+	result := ModerationResult{Action: ModerationAllow}
+	for _, moderator := range moderators {
+		current, err := moderator.Moderate(ctx, tweet)
+		if err != nil {
+			return ModerationResult{}, err
+		}
+		if current.Action == ModerationReject {
+			return current, nil
+		}
+		if current.Action == ModerationHold && result.Action == ModerationAllow {
+			result = current
+		}
+	}
+	return result, nil
+}
+
+// blocklistEntry is a compiled keyword or pattern of a blocklist
+type blocklistEntry struct {
+	pattern *regexp.Regexp
+	reason  string
+}
+
+// BlocklistModerator applies its action to the tweets that contain a keyword or match a pattern
+type BlocklistModerator struct {
+	action  string
+	entries []blocklistEntry
+}
+
+// NewBlocklistModerator creates a new blocklist moderator, it fails if a pattern is not a valid regular expression
+func NewBlocklistModerator(action string, keywords, patterns []string) (*BlocklistModerator, error) {
+	moderator := &BlocklistModerator{action: action}
+	for _, keyword := range keywords {
+		if strings.TrimSpace(keyword) == "" {
+			continue
+		}
+		// Not part of a longer word (e.g. "scam" does not match "scampi")
+		pattern := regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(strings.TrimSpace(keyword)) + `(?:$|[^\p{L}\p{N}_])`)
+		moderator.entries = append(moderator.entries, blocklistEntry{
+			pattern: pattern,
+			reason:  fmt.Sprintf("contains blocked keyword %q", strings.TrimSpace(keyword)),
+		})
+	}
+	for _, expression := range patterns {
+		pattern, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation pattern %q: %w", expression, err)
+		}
+		moderator.entries = append(moderator.entries, blocklistEntry{
+			pattern: pattern,
+			reason:  fmt.Sprintf("matches blocked pattern %q", expression),
+		})
+	}
+	return moderator, nil
+}
+
+// Moderate implements the Moderator interface
+func (moderator *BlocklistModerator) Moderate(ctx context.Context, tweet *Tweet) (ModerationResult, error) {
+	for _, entry := range moderator.entries {
+		if entry.pattern.MatchString(tweet.Content.Text) {
+			return ModerationResult{Action: moderator.action, Reason: entry.reason}, nil
+		}
+	}
+	return ModerationResult{Action: ModerationAllow}, nil
+}
+
+// DomainDenylistModerator applies its action to the tweets that link to a denied domain or its subdomains
+type DomainDenylistModerator struct {
+	action  string
+	domains []string
+}
+
+// NewDomainDenylistModerator creates a new domain denylist moderator
+func NewDomainDenylistModerator(action string, domains []string) *DomainDenylistModerator {
+	moderator := &DomainDenylistModerator{action: action}
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			moderator.domains = append(moderator.domains, domain)
+		}
+	}
+	return moderator
+}
+
+// Moderate implements the Moderator interface
+func (moderator *DomainDenylistModerator) Moderate(ctx context.Context, tweet *Tweet) (ModerationResult, error) {
+	if len(moderator.domains) == 0 {
+		return ModerationResult{Action: ModerationAllow}, nil
+	}
+	for _, link := range links(tweet.Content.Text) {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue // Not a link
+		}
+		host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+		for _, domain := range moderator.domains {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return ModerationResult{Action: moderator.action, Reason: fmt.Sprintf("links to denied domain %q", domain)}, nil
+			}
+		}
+	}
+	return ModerationResult{Action: ModerationAllow}, nil
+}
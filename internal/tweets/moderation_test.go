@@ -0,0 +1,108 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewModerator(t *testing.T) {
+	moderator, err := NewModerator(ModerationConfig{
+		Reject: ModerationRules{
+			Keywords: []string{"scam", "buy followers"},
+			Domains:  []string{"malware.example"},
+		},
+		Hold: ModerationRules{
+			Patterns: []string{`\d{4}-\d{4}-\d{4}-\d{4}`},
+			Domains:  []string{"Shortener.example."},
+		},
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name string
+		text string
+		want ModerationResult
+	}{
+		{
+			name: "allowed",
+			text: "Hello, world! https://example.com/news",
+			want: ModerationResult{Action: ModerationAllow},
+		},
+		{
+			name: "blocked keyword ignoring case",
+			text: "Not a SCAM, I promise",
+			want: ModerationResult{Action: ModerationReject, Reason: `contains blocked keyword "scam"`},
+		},
+		{
+			name: "blocked keyword with several words",
+			text: "Buy followers now!",
+			want: ModerationResult{Action: ModerationReject, Reason: `contains blocked keyword "buy followers"`},
+		},
+		{
+			name: "keyword part of a longer word",
+			text: "Scampi for dinner",
+			want: ModerationResult{Action: ModerationAllow},
+		},
+		{
+			name: "held pattern",
+			text: "My card is 1234-5678-9012-3456",
+			want: ModerationResult{Action: ModerationHold, Reason: `matches blocked pattern "\\d{4}-\\d{4}-\\d{4}-\\d{4}"`},
+		},
+		{
+			name: "link to a subdomain of a denied domain",
+			text: "Check https://cdn.MALWARE.example/file.exe",
+			want: ModerationResult{Action: ModerationReject, Reason: `links to denied domain "malware.example"`},
+		},
+		{
+			name: "link to a held domain",
+			text: "Look at http://shortener.example/abc",
+			want: ModerationResult{Action: ModerationHold, Reason: `links to denied domain "shortener.example"`},
+		},
+		{
+			name: "domain that only ends like a denied domain",
+			text: "Look at https://notshortener.example/abc",
+			want: ModerationResult{Action: ModerationAllow},
+		},
+		{
+			name: "reject takes precedence over hold",
+			text: "http://shortener.example/abc is a scam",
+			want: ModerationResult{Action: ModerationReject, Reason: `contains blocked keyword "scam"`},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := moderator.Moderate(context.Background(), &Tweet{Content: Content{Text: tc.text}})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, result)
+		})
+	}
+}
+
+func TestNewModerator_InvalidPattern(t *testing.T) {
+	_, err := NewModerator(ModerationConfig{Hold: ModerationRules{Patterns: []string{"("}}})
+
+	assert.ErrorContains(t, err, `invalid moderation pattern "("`)
+}
+
+func TestChainModerator_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	tweet := &Tweet{Content: Content{Text: "Hello"}}
+	first := NewMockModerator(ctrl)
+	second := NewMockModerator(ctrl)
+
+	first.EXPECT().Moderate(ctx, tweet).Return(ModerationResult{Action: ModerationHold, Reason: "suspicious"}, nil)
+	second.EXPECT().Moderate(ctx, tweet).Return(ModerationResult{}, errors.New("moderation service unavailable"))
+
+	_, err := ChainModerator{first, second}.Moderate(ctx, tweet)
+
+	assert.EqualError(t, err, "moderation service unavailable")
+}
@@ -0,0 +1,107 @@
+package tweets
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// minPollOptions and maxPollOptions are the number of options a poll can have
+	minPollOptions = 2
+	maxPollOptions = 4
+	// maxPollOptionLength is the maximum number of user-perceived characters of an option
+	maxPollOptionLength = 25
+	// minPollDuration and maxPollDuration bound the time between posting a poll and its closing time
+	minPollDuration = 5 * time.Minute
+	maxPollDuration = 7 * 24 * time.Hour
+)
+
+// ErrInvalidPoll is returned when creating a poll with invalid options or closing time, or voting for a missing option
+var ErrInvalidPoll = errors.New("invalid poll")
+
+// ErrPollNotFound is returned when voting on a tweet without a poll
+var ErrPollNotFound = errors.New("poll not found")
+
+// ErrPollClosed is returned when voting on a poll after its closing time
+var ErrPollClosed = errors.New("poll is closed")
+
+// ErrAlreadyVoted is returned when a user votes twice on the same poll
+var ErrAlreadyVoted = errors.New("already voted on this poll")
+
+// Poll is a question attached to a tweet, its tallies and the vote of the caller are filled in when it is read
+type Poll struct {
+	Options    []PollOption `json:"options"`
+	ClosesAt   time.Time    `json:"closes_at"`
+	TotalVotes int64        `json:"total_votes"`
+	Closed     bool         `json:"closed"`
+	Vote       *int         `json:"vote,omitempty"` // Index of the option voted by the caller
+}
+
+// PollOption is an option of a poll, with its votes
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int64  `json:"votes"`
+}
+
+// IsClosed reports whether the poll no longer accepts votes at a time
+func (p *Poll) IsClosed(at time.Time) bool {
+	return !at.Before(p.ClosesAt)
+}
+
+// PollVote is the vote of a user on the poll of a tweet, a user votes once per poll
+type PollVote struct {
+	TweetID   string    `gorm:"primaryKey;type:uuid" json:"tweet_id"`
+	UserID    string    `gorm:"primaryKey;type:uuid" json:"user_id"`
+	Option    int       `gorm:"not null" json:"option"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
+
+// TableName specifies the table name for the PollVote
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
+
+// validatePoll normalizes the options of a poll posted at a time and checks its options and duration
+func validatePoll(poll *Poll, postedAt time.Time) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return fmt.Errorf("%w: a poll must have between %d and %d options", ErrInvalidPoll, minPollOptions, maxPollOptions)
+	}
+
+	options := make([]PollOption, 0, len(poll.Options))
+	seen := map[string]bool{}
+	for _, option := range poll.Options {
+		text := strings.Join(strings.Fields(option.Text), " ")
+		if text == "" {
+			return fmt.Errorf("%w: options cannot be empty", ErrInvalidPoll)
+		}
+		if len(graphemeClusters(text)) > maxPollOptionLength {
+			return fmt.Errorf("%w: options can be up to %d characters long", ErrInvalidPoll, maxPollOptionLength)
+		}
+		if seen[strings.ToLower(text)] {
+			return fmt.Errorf("%w: duplicate option %q", ErrInvalidPoll, text)
+		}
+		seen[strings.ToLower(text)] = true
+		options = append(options, PollOption{Text: text})
+	}
+
+	if err := validatePollClosingTime(poll, postedAt); err != nil {
+		return err
+	}
+
+	poll.Options = options
+	poll.ClosesAt = poll.ClosesAt.UTC()
+	poll.TotalVotes, poll.Closed, poll.Vote = 0, false, nil
+	return nil
+}
+
+// validatePollClosingTime checks that a poll closes between minPollDuration and maxPollDuration after it is posted
+func validatePollClosingTime(poll *Poll, postedAt time.Time) error {
+	duration := poll.ClosesAt.Sub(postedAt)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return fmt.Errorf("%w: a poll must close between %d minutes and %d days after it is posted",
+			ErrInvalidPoll, int(minPollDuration.Minutes()), int(maxPollDuration.Hours()/24))
+	}
+	return nil
+}
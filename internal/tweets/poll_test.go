@@ -0,0 +1,86 @@
+package tweets
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePoll(t *testing.T) {
+	now := time.Date(2025, 8, 9, 12, 0, 0, 0, time.UTC)
+	options := func(texts ...string) []PollOption {
+		var options []PollOption
+		for _, text := range texts {
+			options = append(options, PollOption{Text: text})
+		}
+		return options
+	}
+
+	tt := []struct {
+		name    string
+		poll    *Poll
+		want    *Poll
+		wantErr string
+	}{
+		{
+			name: "valid poll",
+			poll: &Poll{Options: options("  Go ", "Rust"), ClosesAt: now.Add(24 * time.Hour), TotalVotes: 10, Closed: true},
+			want: &Poll{Options: options("Go", "Rust"), ClosesAt: now.Add(24 * time.Hour)},
+		},
+		{
+			name: "four options closing in seven days",
+			poll: &Poll{Options: options("A", "B", "C", "D"), ClosesAt: now.Add(7 * 24 * time.Hour)},
+			want: &Poll{Options: options("A", "B", "C", "D"), ClosesAt: now.Add(7 * 24 * time.Hour)},
+		},
+		{
+			name:    "single option",
+			poll:    &Poll{Options: options("Go"), ClosesAt: now.Add(time.Hour)},
+			wantErr: "invalid poll: a poll must have between 2 and 4 options",
+		},
+		{
+			name:    "too many options",
+			poll:    &Poll{Options: options("A", "B", "C", "D", "E"), ClosesAt: now.Add(time.Hour)},
+			wantErr: "invalid poll: a poll must have between 2 and 4 options",
+		},
+		{
+			name:    "empty option",
+			poll:    &Poll{Options: options("Go", " "), ClosesAt: now.Add(time.Hour)},
+			wantErr: "invalid poll: options cannot be empty",
+		},
+		{
+			name:    "duplicate options",
+			poll:    &Poll{Options: options("Go", "go"), ClosesAt: now.Add(time.Hour)},
+			wantErr: `invalid poll: duplicate option "go"`,
+		},
+		{
+			name:    "option too long",
+			poll:    &Poll{Options: options("Go", strings.Repeat("a", 26)), ClosesAt: now.Add(time.Hour)},
+			wantErr: "invalid poll: options can be up to 25 characters long",
+		},
+		{
+			name:    "closing too soon",
+			poll:    &Poll{Options: options("Go", "Rust"), ClosesAt: now.Add(time.Minute)},
+			wantErr: "invalid poll: a poll must close between 5 minutes and 7 days after it is posted",
+		},
+		{
+			name:    "closing too late",
+			poll:    &Poll{Options: options("Go", "Rust"), ClosesAt: now.Add(8 * 24 * time.Hour)},
+			wantErr: "invalid poll: a poll must close between 5 minutes and 7 days after it is posted",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validatePoll(tc.poll, now)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.ErrorIs(t, err, ErrInvalidPoll)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, tc.poll)
+		})
+	}
+}
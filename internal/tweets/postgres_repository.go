@@ -2,15 +2,85 @@ package tweets
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/lucas-soria/microblogging/pkg/database"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// userIDMigration references the users of the tables created before they had an ID by their database.LegacyUserID
+const userIDMigration = `
+DO $$
+BEGIN
+	IF to_regclass('tweets') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'tweets' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE tweets ADD COLUMN user_id uuid;
+		UPDATE tweets SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE tweets ALTER COLUMN user_id SET NOT NULL;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM tweets ON CONFLICT DO NOTHING;
+	END IF;
+
+	IF to_regclass('drafts') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'drafts' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE drafts ADD COLUMN user_id uuid;
+		UPDATE drafts SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE drafts ALTER COLUMN user_id SET NOT NULL;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM drafts ON CONFLICT DO NOTHING;
+	END IF;
+
+	IF to_regclass('media') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'media' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE media ADD COLUMN user_id uuid;
+		UPDATE media SET user_id = ` + database.LegacyUserID + `;
+		ALTER TABLE media ALTER COLUMN user_id SET NOT NULL;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM media ON CONFLICT DO NOTHING;
+	END IF;
+
+	IF to_regclass('poll_votes') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'poll_votes' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE poll_votes ADD COLUMN user_id uuid;
+		UPDATE poll_votes SET user_id = ` + database.LegacyUserID + `;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM poll_votes ON CONFLICT DO NOTHING;
+		ALTER TABLE poll_votes DROP CONSTRAINT IF EXISTS poll_votes_pkey;
+		ALTER TABLE poll_votes DROP COLUMN handler;
+		ALTER TABLE poll_votes ALTER COLUMN user_id SET NOT NULL;
+		ALTER TABLE poll_votes ADD PRIMARY KEY (tweet_id, user_id);
+	END IF;
+
+	IF to_regclass('deactivated_users') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'deactivated_users' AND column_name = 'user_id'
+	) THEN
+		ALTER TABLE deactivated_users ADD COLUMN user_id uuid;
+		UPDATE deactivated_users SET user_id = ` + database.LegacyUserID + `;
+		INSERT INTO known_users (id, handler, updated_at)
+		SELECT DISTINCT user_id, handler, now() FROM deactivated_users ON CONFLICT DO NOTHING;
+		ALTER TABLE deactivated_users DROP CONSTRAINT IF EXISTS deactivated_users_pkey;
+		ALTER TABLE deactivated_users DROP COLUMN handler;
+		ALTER TABLE deactivated_users ALTER COLUMN user_id SET NOT NULL;
+		ALTER TABLE deactivated_users ADD PRIMARY KEY (user_id);
+	END IF;
+END $$;
+`
+
 // PostgresTweetRepository is a PostgreSQL implementation of the Repository interface
 type PostgresTweetRepository struct {
 	db database.DBClient
@@ -18,10 +88,31 @@ type PostgresTweetRepository struct {
 
 // NewPostgresTweetRepository creates a new PostgreSQL tweet repository
 func NewPostgresTweetRepository(db database.DBClient) *PostgresTweetRepository {
+	// The existing data references the users by ID before the schemas are migrated, which AutoMigrate cannot add to a
+	// primary key
+	if err := db.AutoMigrate(&KnownUser{}); err != nil {
+		log.Fatalf("failed to migrate known users schema: %v", err)
+	}
+	if err := db.WithContext(context.Background()).Exec(userIDMigration).Error; err != nil {
+		log.Fatalf("failed to migrate the users to IDs: %v", err)
+	}
+
 	// Auto migrate the schema
 	if err := db.AutoMigrate(&Tweet{}); err != nil {
 		log.Fatalf("failed to migrate database schema: %v", err)
 	}
+	if err := db.AutoMigrate(&Media{}); err != nil {
+		log.Fatalf("failed to migrate media schema: %v", err)
+	}
+	if err := db.AutoMigrate(&PollVote{}); err != nil {
+		log.Fatalf("failed to migrate poll votes schema: %v", err)
+	}
+	if err := db.AutoMigrate(&Draft{}); err != nil {
+		log.Fatalf("failed to migrate drafts schema: %v", err)
+	}
+	if err := db.AutoMigrate(&DeactivatedUser{}); err != nil {
+		log.Fatalf("failed to migrate deactivated users schema: %v", err)
+	}
 
 	// Create index on handler if it doesn't exist
 	if err := db.WithContext(context.Background()).Exec(`
@@ -51,8 +142,14 @@ func NewPostgresTweetRepository(db database.DBClient) *PostgresTweetRepository {
 	// Create mock tweets for each user
 	users := []string{"lucas", "lucas1", "lucas2"}
 	for _, user := range users {
+		userID := database.LegacyUserIDOf(user)
+		if err := repo.SaveKnownUser(ctx, userID, user); err != nil {
+			log.Printf("Failed to save mock user %s: %v", user, err)
+			continue
+		}
 		for i, content := range tweetContents {
 			tweet := &Tweet{
+				UserID:  userID,
 				Handler: user,
 				Content: Content{
 					Text: fmt.Sprintf("%s - %d", content, i+1), // Add index to make tweets unique
@@ -89,14 +186,13 @@ func (r *PostgresTweetRepository) Create(ctx context.Context, tweet *Tweet) (*Tw
 	return tweet, nil
 }
 
-// GetByID retrieves a tweet by its ID
+// GetByID retrieves a tweet by its ID, returning nil if it does not exist or was deleted
 func (r *PostgresTweetRepository) GetByID(ctx context.Context, id string) (*Tweet, error) {
 	var tweet Tweet
 	err := r.db.WithContext(ctx).First(&tweet, "id = ?", id).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Printf("tweet not found with ID: %s", id)
-			return nil, fmt.Errorf("tweet not found with ID: %s", id)
+			return nil, nil
 		}
 		log.Printf("error fetching tweet with ID %s: %v", id, err)
 		return nil, err
@@ -106,16 +202,455 @@ func (r *PostgresTweetRepository) GetByID(ctx context.Context, id string) (*Twee
 }
 
 // GetByUserID retrieves all tweets by a specific user
-func (r *PostgresTweetRepository) GetByUserID(ctx context.Context, handler string) ([]*Tweet, error) {
+func (r *PostgresTweetRepository) GetByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
 	var tweets []*Tweet
-	if err := r.db.WithContext(ctx).Where("handler = ?", handler).Order("created_at DESC").Find(&tweets).Error; err != nil {
-		log.Printf("error fetching tweets for handler %s: %v", handler, err)
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND status = ?", userID, TweetStatusPublished).Order("created_at DESC").Find(&tweets).Error; err != nil {
+		log.Printf("error fetching tweets for user %s: %v", userID, err)
 		return nil, err
 	}
 	return tweets, nil
 }
 
-// Delete implements the Repository interface
+// Delete implements the Repository interface, setting the deleted_at tombstone of the tweet until it is purged
 func (r *PostgresTweetRepository) Delete(ctx context.Context, id string) error {
-	return r.db.WithContext(ctx).Delete(&Tweet{}, "id = ?", id).Error
+	if err := r.db.WithContext(ctx).Delete(&Tweet{}, "id = ?", id).Error; err != nil {
+		return fmt.Errorf("failed to delete tweet: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeleted permanently deletes up to limit tweets deleted before a time with their votes, and returns how many
+func (r *PostgresTweetRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var purged int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []string
+		if err := tx.Unscoped().Model(&Tweet{}).
+			Where("deleted_at < ?", before).
+			Order("deleted_at").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&PollVote{}, "tweet_id IN ?", ids).Error; err != nil {
+			return err
+		}
+		result := tx.Unscoped().Delete(&Tweet{}, "id IN ?", ids)
+		purged = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted tweets: %w", err)
+	}
+	return purged, nil
+}
+
+// GetKnownUserID retrieves the ID of the user known by a handler, "" if no user is known by it
+func (r *PostgresTweetRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	var known KnownUser
+	if err := r.db.WithContext(ctx).Where("handler = ?", handler).Order("updated_at DESC").First(&known).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get known user: %w", err)
+	}
+	return known.ID, nil
+}
+
+// SaveKnownUser records the current handler of a user
+func (r *PostgresTweetRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	if err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return saveKnownUser(tx, userID, handler)
+	}); err != nil {
+		return fmt.Errorf("failed to save known user: %w", err)
+	}
+	return nil
+}
+
+// saveKnownUser records the current handler of a user, which is no longer the one of the other users known by it
+func saveKnownUser(tx *gorm.DB, userID, handler string) error {
+	if err := tx.Where("handler = ? AND id <> ?", handler, userID).Delete(&KnownUser{}).Error; err != nil {
+		return err
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"handler", "updated_at"}),
+	}).Create(&KnownUser{ID: userID, Handler: handler}).Error
+}
+
+// DeleteUserData soft deletes the tweets of a user, and deletes their drafts, votes and known handler
+func (r *PostgresTweetRepository) DeleteUserData(ctx context.Context, userID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&Tweet{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&Draft{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&PollVote{}, "user_id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&KnownUser{}, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&DeactivatedUser{}, "user_id = ?", userID).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user data: %w", err)
+	}
+	return nil
+}
+
+// SaveDeactivatedUser records that a user deactivated their account, saving it again keeps the first deactivation time
+func (r *PostgresTweetRepository) SaveDeactivatedUser(ctx context.Context, userID string, deactivatedAt time.Time) error {
+	deactivated := &DeactivatedUser{UserID: userID, DeactivatedAt: deactivatedAt}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(deactivated).Error; err != nil {
+		return fmt.Errorf("failed to save deactivated user: %w", err)
+	}
+	return nil
+}
+
+// DeleteDeactivatedUser records that a user reactivated their account
+func (r *PostgresTweetRepository) DeleteDeactivatedUser(ctx context.Context, userID string) error {
+	if err := r.db.WithContext(ctx).Delete(&DeactivatedUser{}, "user_id = ?", userID).Error; err != nil {
+		return fmt.Errorf("failed to delete deactivated user: %w", err)
+	}
+	return nil
+}
+
+// IsUserDeactivated checks if a user deactivated their account
+func (r *PostgresTweetRepository) IsUserDeactivated(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&DeactivatedUser{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check deactivated user: %w", err)
+	}
+	return count > 0, nil
+}
+
+// RenameUser makes a user known by their new handler unless a later change was applied, and shows their data with it
+func (r *PostgresTweetRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var known KnownUser
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&known, "id = ?", userID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		handler := known.Handler
+		if err != nil || known.Handler == oldHandler {
+			handler = newHandler
+			if err := saveKnownUser(tx, userID, handler); err != nil {
+				return err
+			}
+		}
+
+		// Unscoped to show the tombstones of the deleted tweets with it too
+		if err := tx.Unscoped().Model(&Tweet{}).Where("user_id = ? AND handler <> ?", userID, handler).Update("handler", handler).Error; err != nil {
+			return err
+		}
+		// UpdateColumn not to show the drafts as edited
+		for _, model := range []any{&Draft{}, &Media{}} {
+			if err := tx.Model(model).Where("user_id = ? AND handler <> ?", userID, handler).UpdateColumn("handler", handler).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rename user: %w", err)
+	}
+	return nil
+}
+
+// GetByStatus retrieves the tweets with a status, oldest first
+func (r *PostgresTweetRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).
+		Where("status = ?", status).
+		Order("created_at, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s tweets: %w", status, err)
+	}
+	return tweets, nil
+}
+
+// CountByStatus counts the tweets with a status
+func (r *PostgresTweetRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Tweet{}).Where("status = ?", status).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count %s tweets: %w", status, err)
+	}
+	return count, nil
+}
+
+// Review sets the status of a tweet held for review
+func (r *PostgresTweetRepository) Review(ctx context.Context, id, status string, reviewedAt time.Time) (*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE tweets SET status = ?, reviewed_at = ? WHERE id = ? AND status = ? AND deleted_at IS NULL RETURNING *
+	`, status, reviewedAt, id, TweetStatusHeld).Scan(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to review tweet: %w", err)
+	}
+	if len(tweets) == 0 {
+		// Tell a missing tweet from a reviewed one
+		return nil, r.notUpdatedError(ctx, id, ErrTweetNotHeld)
+	}
+	return tweets[0], nil
+}
+
+// notUpdatedError returns the error of a conditional update of a tweet that matched no row
+func (r *PostgresTweetRepository) notUpdatedError(ctx context.Context, id string, conditionErr error) error {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Tweet{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to find tweet: %w", err)
+	}
+	if count == 0 {
+		return ErrTweetNotFound
+	}
+	return conditionErr
+}
+
+// CreateMedia saves an uploaded media
+func (r *PostgresTweetRepository) CreateMedia(ctx context.Context, media *Media) error {
+	if err := r.db.WithContext(ctx).Create(media).Error; err != nil {
+		return fmt.Errorf("failed to create media: %w", err)
+	}
+	return nil
+}
+
+// GetMedia retrieves a media
+func (r *PostgresTweetRepository) GetMedia(ctx context.Context, id string) (*Media, error) {
+	var media Media
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&media).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return &media, nil
+}
+
+// GetMediaByIDs retrieves the existing media among the IDs, in no particular order
+func (r *PostgresTweetRepository) GetMediaByIDs(ctx context.Context, ids []string) ([]*Media, error) {
+	var media []*Media
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&media).Error; err != nil {
+		return nil, fmt.Errorf("failed to get media: %w", err)
+	}
+	return media, nil
+}
+
+// SetPreview attaches the preview card of a link to the content of a tweet
+func (r *PostgresTweetRepository) SetPreview(ctx context.Context, id string, preview *LinkPreview) error {
+	data, err := json.Marshal(preview)
+	if err != nil {
+		return fmt.Errorf("failed to encode link preview: %w", err)
+	}
+	result := r.db.WithContext(ctx).Exec(`
+		UPDATE tweets SET content = jsonb_set(content, '{preview}', ?::jsonb) WHERE id = ? AND deleted_at IS NULL
+	`, string(data), id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set link preview: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTweetNotFound
+	}
+	return nil
+}
+
+// CreateVote saves the vote of a user on a poll, returning ErrAlreadyVoted if the user already voted on it
+func (r *PostgresTweetRepository) CreateVote(ctx context.Context, vote *PollVote) error {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO poll_votes (tweet_id, user_id, option, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (tweet_id, user_id) DO NOTHING
+	`, vote.TweetID, vote.UserID, vote.Option, vote.CreatedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to create vote: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAlreadyVoted
+	}
+	return nil
+}
+
+// GetVote retrieves the vote of a user on a poll, returning nil if the user did not vote on it
+func (r *PostgresTweetRepository) GetVote(ctx context.Context, tweetID, userID string) (*PollVote, error) {
+	var vote PollVote
+	if err := r.db.WithContext(ctx).Where("tweet_id = ? AND user_id = ?", tweetID, userID).First(&vote).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get vote: %w", err)
+	}
+	return &vote, nil
+}
+
+// CountVotes counts the votes of each option of a poll cast before a time
+func (r *PostgresTweetRepository) CountVotes(ctx context.Context, tweetID string, before time.Time) (map[int]int64, error) {
+	var rows []struct {
+		Option int
+		Votes  int64
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT option, COUNT(*) AS votes FROM poll_votes WHERE tweet_id = ? AND created_at < ? GROUP BY option
+	`, tweetID, before).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count votes: %w", err)
+	}
+
+	tallies := make(map[int]int64, len(rows))
+	for _, row := range rows {
+		tallies[row.Option] = row.Votes
+	}
+	return tallies, nil
+}
+
+// GetScheduledByUserID retrieves the scheduled tweets of a user, the soonest to be published first
+func (r *PostgresTweetRepository) GetScheduledByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND status = ?", userID, TweetStatusScheduled).
+		Order("publish_at, id").
+		Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scheduled tweets: %w", err)
+	}
+	return tweets, nil
+}
+
+// GetDueScheduled retrieves up to limit scheduled tweets to publish before a time, the soonest first
+func (r *PostgresTweetRepository) GetDueScheduled(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", TweetStatusScheduled, before).
+		Order("publish_at, id").
+		Limit(limit).
+		Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due scheduled tweets: %w", err)
+	}
+	return tweets, nil
+}
+
+// PublishScheduled publishes a scheduled tweet pending to be posted, returning ErrTweetNotScheduled if it is not scheduled
+func (r *PostgresTweetRepository) PublishScheduled(ctx context.Context, id string, publishedAt time.Time) (*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE tweets SET status = ?, publish_at = NULL, created_at = ?, posted_pending = TRUE
+		WHERE id = ? AND status = ? AND deleted_at IS NULL
+		RETURNING *
+	`, TweetStatusPublished, publishedAt, id, TweetStatusScheduled).Scan(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to publish scheduled tweet: %w", err)
+	}
+	if len(tweets) == 0 {
+		return nil, r.notUpdatedError(ctx, id, ErrTweetNotScheduled)
+	}
+	return tweets[0], nil
+}
+
+// Reschedule changes the publish time of a scheduled tweet
+func (r *PostgresTweetRepository) Reschedule(ctx context.Context, id string, publishAt time.Time) (*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE tweets SET publish_at = ? WHERE id = ? AND status = ? AND deleted_at IS NULL RETURNING *
+	`, publishAt, id, TweetStatusScheduled).Scan(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to reschedule tweet: %w", err)
+	}
+	if len(tweets) == 0 {
+		return nil, r.notUpdatedError(ctx, id, ErrTweetNotScheduled)
+	}
+	return tweets[0], nil
+}
+
+// CancelScheduled deletes a scheduled tweet without a tombstone, since it was never visible
+func (r *PostgresTweetRepository) CancelScheduled(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Unscoped().
+		Delete(&Tweet{}, "id = ? AND status = ? AND deleted_at IS NULL", id, TweetStatusScheduled)
+	if result.Error != nil {
+		return fmt.Errorf("failed to cancel scheduled tweet: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return r.notUpdatedError(ctx, id, ErrTweetNotScheduled)
+	}
+	return nil
+}
+
+// GetPostedPending retrieves up to limit published tweets pending to be posted, published before a time, the oldest first
+func (r *PostgresTweetRepository) GetPostedPending(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	var tweets []*Tweet
+	if err := r.db.WithContext(ctx).
+		Where("posted_pending AND created_at < ?", before).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&tweets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get tweets pending to be posted: %w", err)
+	}
+	return tweets, nil
+}
+
+// MarkPosted marks a published tweet as posted, a missing tweet is ignored
+func (r *PostgresTweetRepository) MarkPosted(ctx context.Context, id string) error {
+	if err := r.db.WithContext(ctx).Model(&Tweet{}).
+		Where("id = ? AND posted_pending", id).
+		Update("posted_pending", false).Error; err != nil {
+		return fmt.Errorf("failed to mark tweet as posted: %w", err)
+	}
+	return nil
+}
+
+// CreateDraft saves a new draft
+func (r *PostgresTweetRepository) CreateDraft(ctx context.Context, draft *Draft) error {
+	if err := r.db.WithContext(ctx).Create(draft).Error; err != nil {
+		return fmt.Errorf("failed to create draft: %w", err)
+	}
+	return nil
+}
+
+// GetDraft retrieves a draft
+func (r *PostgresTweetRepository) GetDraft(ctx context.Context, id string) (*Draft, error) {
+	var draft Draft
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDraftNotFound
+		}
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// GetDraftsByUserID retrieves the drafts of a user, the most recently updated first
+func (r *PostgresTweetRepository) GetDraftsByUserID(ctx context.Context, userID string) ([]*Draft, error) {
+	var drafts []*Draft
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("updated_at DESC, id").Find(&drafts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+// UpdateDraft replaces the content and publish time of a draft
+func (r *PostgresTweetRepository) UpdateDraft(ctx context.Context, draft *Draft) error {
+	result := r.db.WithContext(ctx).Model(&Draft{}).Where("id = ?", draft.ID).Updates(map[string]any{
+		"content":    draft.Content,
+		"publish_at": draft.PublishAt,
+		"updated_at": draft.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDraftNotFound
+	}
+	return nil
+}
+
+// DeleteDraft deletes a draft, only one of concurrent deletions succeeds and the others return ErrDraftNotFound
+func (r *PostgresTweetRepository) DeleteDraft(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&Draft{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete draft: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDraftNotFound
+	}
+	return nil
 }
@@ -0,0 +1,158 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// mockDBClient runs the queries of the repository against a mocked database
+type mockDBClient struct {
+	db *gorm.DB
+}
+
+func (client *mockDBClient) AutoMigrate(value any) error { return client.db.AutoMigrate(value) }
+
+func (client *mockDBClient) Create(ctx context.Context, value any) error {
+	return client.db.WithContext(ctx).Create(value).Error
+}
+
+func (client *mockDBClient) First(ctx context.Context, dest any, conds ...any) error {
+	return client.db.WithContext(ctx).First(dest, conds...).Error
+}
+
+func (client *mockDBClient) Save(ctx context.Context, value any) error {
+	return client.db.WithContext(ctx).Save(value).Error
+}
+
+func (client *mockDBClient) Delete(ctx context.Context, value any, conds ...any) error {
+	return client.db.WithContext(ctx).Delete(value, conds...).Error
+}
+
+func (client *mockDBClient) WithContext(ctx context.Context) *gorm.DB {
+	return client.db.WithContext(ctx)
+}
+
+func newMockPostgresTweetRepository(t *testing.T) (*PostgresTweetRepository, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return &PostgresTweetRepository{db: &mockDBClient{db: db}}, mock
+}
+
+func TestPostgresTweetRepository_GetByID(t *testing.T) {
+	dbErr := errors.New("connection refused")
+
+	type want struct {
+		err   error
+		tweet *Tweet
+	}
+
+	tt := []struct {
+		name         string
+		expectations func(sqlmock.Sqlmock)
+		want         want
+	}{
+		{
+			name: "existing tweet",
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "tweets" WHERE id = \$1 AND "tweets"."deleted_at" IS NULL`).
+					WithArgs("123", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "handler", "status"}).AddRow("123", "testuser", TweetStatusPublished))
+			},
+			want: want{tweet: &Tweet{ID: "123", Handler: "testuser", Status: TweetStatusPublished}},
+		},
+		{
+			name: "missing or deleted tweet",
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "tweets" WHERE id = \$1 AND "tweets"."deleted_at" IS NULL`).
+					WithArgs("123", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "handler", "status"}))
+			},
+			want: want{},
+		},
+		{
+			name: "database error",
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(`SELECT \* FROM "tweets"`).WillReturnError(dbErr)
+			},
+			want: want{err: dbErr},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock := newMockPostgresTweetRepository(t)
+			tc.expectations(mock)
+
+			tweet, err := repo.GetByID(context.Background(), "123")
+
+			assert.ErrorIs(t, err, tc.want.err)
+			assert.Equal(t, tc.want.tweet, tweet)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresTweetRepository_RenameUser(t *testing.T) {
+	expectDisplayedHandler := func(mock sqlmock.Sqlmock, handler string) {
+		for _, table := range []string{"tweets", "drafts", "media"} {
+			mock.ExpectExec(`UPDATE "`+table+`" SET "handler"=\$1.* WHERE user_id = \$\d AND handler <> \$\d`).
+				WithArgs(handler, "user-id", handler).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+	}
+	tt := []struct {
+		name         string
+		expectations func(sqlmock.Sqlmock)
+	}{
+		{
+			name: "makes the user known by the previous handler known by the new one",
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "known_users" WHERE id = \$1 .* FOR UPDATE`).
+					WithArgs("user-id", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "handler"}).AddRow("user-id", "old"))
+				mock.ExpectExec(`DELETE FROM "known_users" WHERE handler = \$1 AND id <> \$2`).
+					WithArgs("new", "user-id").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO "known_users" .* ON CONFLICT \("id"\) DO UPDATE SET "handler"="excluded"."handler"`).
+					WithArgs("user-id", "new", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDisplayedHandler(mock, "new")
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "keeps the handler of the user known by another handler",
+			expectations: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT \* FROM "known_users" WHERE id = \$1 .* FOR UPDATE`).
+					WithArgs("user-id", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "handler"}).AddRow("user-id", "newer"))
+				expectDisplayedHandler(mock, "newer")
+				mock.ExpectCommit()
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock := newMockPostgresTweetRepository(t)
+			tc.expectations(mock)
+
+			err := repo.RenameUser(context.Background(), "user-id", "old", "new")
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,288 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	// maxPreviewPageSize is the maximum number of bytes of a page read to find its metadata
+	maxPreviewPageSize = 512 << 10
+	// maxPreviewRedirects is the maximum number of redirects followed when fetching a page
+	maxPreviewRedirects = 5
+	// maxPreviewTitleLength and maxPreviewDescriptionLength are the maximum characters of the texts of a preview card
+	maxPreviewTitleLength       = 200
+	maxPreviewDescriptionLength = 300
+	// previewUserAgent identifies the preview fetcher to the linked sites
+	previewUserAgent = "MicrobloggingBot/1.0 (link previews)"
+)
+
+// errNoPreview is returned when a linked page has no metadata to build a preview card from
+var errNoPreview = errors.New("no preview metadata")
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not reachable from the internet either
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// PreviewWorker attaches the preview cards of the links of the posted tweets in the background
+type PreviewWorker struct {
+	repository PreviewRepository
+	client     *http.Client
+	jobs       chan string
+}
+
+// NewPreviewWorker creates a new preview worker fetching the linked pages with the client, queueing up to queueSize tweets
+func NewPreviewWorker(repository PreviewRepository, client *http.Client, queueSize int) *PreviewWorker {
+	return &PreviewWorker{
+		repository: repository,
+		client:     client,
+		jobs:       make(chan string, max(queueSize, 1)),
+	}
+}
+
+// NewPreviewHTTPClient creates the HTTP client that fetches the linked pages, refusing to connect to internal addresses
+func NewPreviewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip, err := netip.ParseAddr(host)
+			if err != nil || !isPublicAddr(ip) {
+				return fmt.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   2,
+			IdleConnTimeout:       time.Minute,
+		},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxPreviewRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxPreviewRedirects)
+			}
+			if request.URL.Scheme != "http" && request.URL.Scheme != "https" {
+				return fmt.Errorf("refusing to follow redirect to %s", request.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// isPublicAddr reports whether an IP address is reachable from the internet
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// Subscribe registers the worker handler in the subscriber
+func (worker *PreviewWorker) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicTweetPosted, worker.HandleTweetPosted)
+}
+
+// HandleTweetPosted queues a posted tweet to fetch the preview card of its links, without waiting for the queue
+func (worker *PreviewWorker) HandleTweetPosted(ctx context.Context, message *queue.Message) error {
+	var event events.Event
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+	if event.TweetID == "" {
+		return fmt.Errorf("%w: tweet posted event without tweet ID", queue.ErrInvalidMessage)
+	}
+
+	select {
+	case worker.jobs <- event.TweetID:
+	default:
+		log.Printf("preview queue is full, skipping link preview of tweet %s", event.TweetID)
+	}
+	return nil
+}
+
+// Run processes the queued tweets until the context is cancelled
+func (worker *PreviewWorker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-worker.jobs:
+			if err := worker.Process(ctx, id); err != nil {
+				log.Printf("failed to attach link preview to tweet %s: %v", id, err)
+			}
+		}
+	}
+}
+
+// Process attaches to a tweet without a preview the card of the first of its links whose page has metadata
+func (worker *PreviewWorker) Process(ctx context.Context, id string) error {
+	tweet, err := worker.repository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if tweet == nil || tweet.Content.Preview != nil || len(tweet.Content.URLs) == 0 {
+		return nil
+	}
+
+	for _, link := range tweet.Content.URLs {
+		preview, err := worker.fetchPreview(ctx, link)
+		if err != nil {
+			// A link that cannot be previewed is expected (e.g. a missing page or an image), try the next one
+			log.Printf("no link preview for %s: %v", link, err)
+			continue
+		}
+		return worker.repository.SetPreview(ctx, id, preview)
+	}
+	return nil
+}
+
+// fetchPreview fetches a linked page and builds its preview card from its OpenGraph or meta tags
+func (worker *PreviewWorker) fetchPreview(ctx context.Context, link string) (*LinkPreview, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link: %w", err)
+	}
+	request.Header.Set("User-Agent", previewUserAgent)
+	request.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	response, err := worker.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+	contentType := response.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: %s is not a page", errNoPreview, mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(response.Body, maxPreviewPageSize), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode page: %w", err)
+	}
+	// Relative image URLs are resolved against the page URL, after the redirects
+	preview := parsePreview(body, response.Request.URL)
+	if preview == nil {
+		return nil, errNoPreview
+	}
+	preview.URL = link
+	return preview, nil
+}
+
+// parsePreview reads the metadata in the head of a page, it returns nil if the page has no title
+func parsePreview(page io.Reader, pageURL *url.URL) *LinkPreview {
+	meta := map[string]string{}
+	var title string
+	tokenizer := html.NewTokenizer(page)
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break // The end of the page, or of the bytes read
+		}
+		name, hasAttributes := tokenizer.TagName()
+		tag := string(name)
+		if tokenType == html.EndTagToken && tag == "head" || tokenType == html.StartTagToken && tag == "body" {
+			break // The metadata is in the head
+		}
+		if tokenType != html.StartTagToken && tokenType != html.SelfClosingTagToken {
+			continue
+		}
+
+		switch {
+		case tag == "title" && title == "":
+			if tokenizer.Next() == html.TextToken {
+				title = string(tokenizer.Text())
+			}
+		case tag == "meta" && hasAttributes:
+			var key, content string
+			for {
+				attribute, value, more := tokenizer.TagAttr()
+				switch string(attribute) {
+				case "property", "name":
+					key = strings.ToLower(strings.TrimSpace(string(value)))
+				case "content":
+					content = string(value)
+				}
+				if !more {
+					break
+				}
+			}
+			// The first value of a key wins, like in the OpenGraph protocol
+			if _, exists := meta[key]; key != "" && !exists {
+				meta[key] = content
+			}
+		}
+	}
+
+	preview := &LinkPreview{
+		Title:       truncateText(firstNonEmpty(meta["og:title"], meta["twitter:title"], title), maxPreviewTitleLength),
+		Description: truncateText(firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"]), maxPreviewDescriptionLength),
+		ImageURL:    resolveURL(pageURL, firstNonEmpty(meta["og:image:secure_url"], meta["og:image"], meta["og:image:url"], meta["twitter:image"])),
+		SiteName:    truncateText(meta["og:site_name"], maxPreviewTitleLength),
+	}
+	if preview.Title == "" {
+		return nil
+	}
+	return preview
+}
+
+// resolveURL resolves a possibly relative http or https URL against a base URL, it returns an empty string for any other URL
+func resolveURL(base *url.URL, reference string) string {
+	parsed, err := url.Parse(strings.TrimSpace(reference))
+	if err != nil || reference == "" {
+		return ""
+	}
+	resolved := base.ResolveReference(parsed)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// firstNonEmpty returns the first value that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// truncateText collapses the whitespace of a text and cuts it to a maximum number of characters with an ellipsis
+func truncateText(text string, maxLength int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxLength-1])) + "…"
+}
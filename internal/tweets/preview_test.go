@@ -0,0 +1,274 @@
+package tweets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPreviewSite starts a local site serving the pages and responses used by the preview tests
+func newPreviewSite(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<!DOCTYPE html>
+<html><head>
+	<title>Page title</title>
+	<meta property="og:title" content="Go 1.24   is released">
+	<meta property="og:description" content="Read about the changes.">
+	<meta property="og:image" content="/images/gopher.png">
+	<meta property="og:site_name" content="The Go Blog">
+	<meta property="og:title" content="Second title">
+</head><body><meta property="og:description" content="In the body"></body></html>`))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title> Just a
+			title </title><meta name="description" content="A description"></head></html>`))
+	})
+	mux.HandleFunc("/latin1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=iso-8859-1")
+		w.Write([]byte("<html><head><meta property=\"og:title\" content=\"Caf\xe9\"></head></html>"))
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/blog/article", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/blog/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><meta property="og:title" content="Moved"><meta property="og:image" content="cover.jpg"></head></html>`))
+	})
+	mux.HandleFunc("/untitled", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><meta property="og:description" content="No title"></head></html>`))
+	})
+	mux.HandleFunc("/image.png", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPreviewWorker_FetchPreview(t *testing.T) {
+	site := newPreviewSite(t)
+
+	tt := []struct {
+		name    string
+		path    string
+		want    *LinkPreview
+		wantErr string
+	}{
+		{
+			name: "OpenGraph metadata",
+			path: "/article",
+			want: &LinkPreview{
+				Title:       "Go 1.24 is released",
+				Description: "Read about the changes.",
+				ImageURL:    site.URL + "/images/gopher.png",
+				SiteName:    "The Go Blog",
+			},
+		},
+		{
+			name: "title and description fallback",
+			path: "/plain",
+			want: &LinkPreview{
+				Title:       "Just a title",
+				Description: "A description",
+			},
+		},
+		{
+			name: "page charset",
+			path: "/latin1",
+			want: &LinkPreview{
+				Title: "Café",
+			},
+		},
+		{
+			name: "redirects are followed and the image resolved against the final page",
+			path: "/moved",
+			want: &LinkPreview{
+				Title:    "Moved",
+				ImageURL: site.URL + "/blog/cover.jpg",
+			},
+		},
+		{
+			name:    "page without title",
+			path:    "/untitled",
+			wantErr: "no preview metadata",
+		},
+		{
+			name:    "not a page",
+			path:    "/image.png",
+			wantErr: "no preview metadata: image/png is not a page",
+		},
+		{
+			name:    "missing page",
+			path:    "/missing",
+			wantErr: "unexpected status 404",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			worker := NewPreviewWorker(NewInMemoryTweetRepository(), site.Client(), 1)
+
+			preview, err := worker.fetchPreview(context.Background(), site.URL+tc.path)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.Nil(t, preview)
+				return
+			}
+			require.NoError(t, err)
+			tc.want.URL = site.URL + tc.path
+			assert.Equal(t, tc.want, preview)
+		})
+	}
+}
+
+func TestPreviewWorker_Process(t *testing.T) {
+	site := newPreviewSite(t)
+
+	tt := []struct {
+		name        string
+		content     Content
+		wantPreview *LinkPreview
+	}{
+		{
+			name:    "the first link with metadata is previewed",
+			content: Content{Text: "links", URLs: []string{site.URL + "/missing", site.URL + "/image.png", site.URL + "/plain", site.URL + "/article"}},
+			wantPreview: &LinkPreview{
+				URL:         site.URL + "/plain",
+				Title:       "Just a title",
+				Description: "A description",
+			},
+		},
+		{
+			name:        "no link with metadata",
+			content:     Content{Text: "links", URLs: []string{site.URL + "/untitled"}},
+			wantPreview: nil,
+		},
+		{
+			name:        "a tweet with a preview is skipped",
+			content:     Content{Text: "links", URLs: []string{site.URL + "/article"}, Preview: &LinkPreview{URL: "https://example.com/", Title: "Kept"}},
+			wantPreview: &LinkPreview{URL: "https://example.com/", Title: "Kept"},
+		},
+		{
+			name:        "a tweet without links is skipped",
+			content:     Content{Text: "no links"},
+			wantPreview: nil,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			repository := NewInMemoryTweetRepository()
+			_, err := repository.Create(context.Background(), &Tweet{ID: "tweet-1", Handler: "lucas", Content: tc.content})
+			require.NoError(t, err)
+			worker := NewPreviewWorker(repository, site.Client(), 1)
+
+			require.NoError(t, worker.Process(context.Background(), "tweet-1"))
+
+			tweet, err := repository.GetByID(context.Background(), "tweet-1")
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantPreview, tweet.Content.Preview)
+		})
+	}
+
+	t.Run("a missing tweet is skipped", func(t *testing.T) {
+		worker := NewPreviewWorker(NewInMemoryTweetRepository(), site.Client(), 1)
+		assert.NoError(t, worker.Process(context.Background(), "missing"))
+	})
+}
+
+func TestPreviewWorker_Run(t *testing.T) {
+	site := newPreviewSite(t)
+	repository := NewInMemoryTweetRepository()
+	messageQueue := queue.NewInMemoryQueue()
+	service := NewService(repository, messageQueue, nil, nil)
+	worker := NewPreviewWorker(repository, site.Client(), 10)
+	worker.Subscribe(messageQueue)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.Run(ctx)
+
+	created, err := service.CreateTweet(context.Background(), &Tweet{
+		Handler: "lucas",
+		Content: Content{Text: "New release: " + site.URL + "/article?utm_source=feed"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{site.URL + "/article"}, created.Content.URLs)
+
+	// The preview is attached in the background
+	assert.Eventually(t, func() bool {
+		tweet, err := repository.GetByID(context.Background(), created.ID)
+		return err == nil && tweet.Content.Preview != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	tweet, err := repository.GetByID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go 1.24 is released", tweet.Content.Preview.Title)
+	assert.Equal(t, site.URL+"/article", tweet.Content.Preview.URL)
+}
+
+func TestPreviewWorker_HandleTweetPosted(t *testing.T) {
+	worker := NewPreviewWorker(NewInMemoryTweetRepository(), http.DefaultClient, 1)
+	message := func(event events.Event) *queue.Message {
+		payload, err := json.Marshal(event)
+		require.NoError(t, err)
+		return &queue.Message{Topic: events.TopicTweetPosted, Key: "lucas", Payload: payload}
+	}
+
+	assert.NoError(t, worker.HandleTweetPosted(context.Background(), message(events.Event{TweetID: "tweet-1"})))
+	// The queue is full, the tweet is skipped without blocking
+	assert.NoError(t, worker.HandleTweetPosted(context.Background(), message(events.Event{TweetID: "tweet-2"})))
+	assert.Error(t, worker.HandleTweetPosted(context.Background(), message(events.Event{})))
+
+	assert.Equal(t, "tweet-1", <-worker.jobs)
+	assert.Empty(t, worker.jobs)
+}
+
+func TestNewPreviewHTTPClient(t *testing.T) {
+	site := newPreviewSite(t)
+	client := NewPreviewHTTPClient(time.Second)
+
+	_, err := client.Get(site.URL + "/article")
+
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "refusing to connect to non-public address 127.0.0.1"), err.Error())
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	tt := []struct {
+		address string
+		want    bool
+	}{
+		{address: "93.184.216.34", want: true},
+		{address: "2606:2800:220:1:248:1893:25c8:1946", want: true},
+		{address: "127.0.0.1", want: false},
+		{address: "::1", want: false},
+		{address: "10.0.0.1", want: false},
+		{address: "172.16.5.4", want: false},
+		{address: "192.168.1.1", want: false},
+		{address: "169.254.169.254", want: false},
+		{address: "100.64.0.1", want: false},
+		{address: "0.0.0.0", want: false},
+		{address: "fd00::1", want: false},
+		{address: "::ffff:127.0.0.1", want: false},
+	}
+	for _, tc := range tt {
+		t.Run(tc.address, func(t *testing.T) {
+			assert.Equal(t, tc.want, isPublicAddr(netip.MustParseAddr(tc.address)))
+		})
+	}
+}
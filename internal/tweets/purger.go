@@ -0,0 +1,63 @@
+package tweets
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// purgeBatchSize is the number of tombstones permanently deleted per query
+const purgeBatchSize = 500
+
+// TweetPurger periodically deletes for good the tombstones of the tweets deleted longer than a retention period ago
+type TweetPurger struct {
+	repository TombstoneRepository
+	retention  time.Duration
+	interval   time.Duration
+}
+
+// NewTweetPurger creates a new tweet purger
+func NewTweetPurger(repository TombstoneRepository, retention, interval time.Duration) *TweetPurger {
+	return &TweetPurger{
+		repository: repository,
+		retention:  retention,
+		interval:   interval,
+	}
+}
+
+// Run purges the expired tombstones at startup and on every interval until the context is cancelled
+func (purger *TweetPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(purger.interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := purger.Purge(ctx); err != nil {
+			log.Printf("failed to purge deleted tweets: %v", err)
+		} else if count > 0 {
+			log.Printf("purged %d deleted tweets", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge permanently deletes the tweets deleted before the retention period, in batches, and returns how many were purged
+func (purger *TweetPurger) Purge(ctx context.Context) (int64, error) {
+	before := time.Now().UTC().Add(-purger.retention)
+
+	var purged int64
+	for {
+		count, err := purger.repository.PurgeDeleted(ctx, before, purgeBatchSize)
+		purged += count
+		if err != nil {
+			return purged, err
+		}
+		if count < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestTweetPurger_Purge(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	purger := NewTweetPurger(repository, 24*time.Hour, time.Hour)
+
+	// More expired tombstones than a batch, and a recent one
+	for i := 0; i < purgeBatchSize+1; i++ {
+		id := fmt.Sprintf("expired-%d", i)
+		repository.deleted[id] = &Tweet{ID: id, DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-48 * time.Hour), Valid: true}}
+	}
+	_, err := repository.Create(ctx, &Tweet{ID: "recent", Handler: "testuser"})
+	require.NoError(t, err)
+	require.NoError(t, repository.Delete(ctx, "recent"))
+
+	purged, err := purger.Purge(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(purgeBatchSize+1), purged)
+	assert.Len(t, repository.deleted, 1)
+	assert.Contains(t, repository.deleted, "recent")
+}
+
+func TestTweetPurger_PurgeError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockTombstoneRepository(ctrl)
+	purger := NewTweetPurger(mockRepo, 24*time.Hour, time.Hour)
+
+	before := time.Now().Add(-24 * time.Hour)
+	gomock.InOrder(
+		mockRepo.EXPECT().PurgeDeleted(ctx, gomock.Any(), purgeBatchSize).
+			DoAndReturn(func(_ context.Context, purgeBefore time.Time, _ int) (int64, error) {
+				assert.WithinDuration(t, before, purgeBefore, time.Minute)
+				return purgeBatchSize, nil
+			}),
+		mockRepo.EXPECT().PurgeDeleted(ctx, gomock.Any(), purgeBatchSize).Return(int64(0), errors.New("database error")),
+	)
+
+	purged, err := purger.Purge(ctx)
+
+	assert.EqualError(t, err, "database error")
+	assert.Equal(t, int64(purgeBatchSize), purged)
+}
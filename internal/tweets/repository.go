@@ -2,7 +2,12 @@ package tweets
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"sync"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 //go:generate mockgen -source=repository.go -destination=repository_mock.go -package=tweets
@@ -13,18 +18,134 @@ type Repository interface {
 	GetByID(ctx context.Context, id string) (*Tweet, error)
 	GetByUserID(ctx context.Context, userID string) ([]*Tweet, error)
 	Delete(ctx context.Context, id string) error
+
+	// Tombstones
+	TombstoneRepository
+
+	// Known Users
+	KnownUserRepository
+
+	// User Deletions
+	DeleteUserData(ctx context.Context, userID string) error
+
+	// User Deactivations
+	SaveDeactivatedUser(ctx context.Context, userID string, deactivatedAt time.Time) error
+	DeleteDeactivatedUser(ctx context.Context, userID string) error
+	IsUserDeactivated(ctx context.Context, userID string) (bool, error)
+
+	// User Handle Changes
+	RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error
+
+	// Review Queue
+	GetByStatus(ctx context.Context, status string, limit, offset int) ([]*Tweet, error)
+	CountByStatus(ctx context.Context, status string) (int64, error)
+	Review(ctx context.Context, id, status string, reviewedAt time.Time) (*Tweet, error)
+
+	// Media
+	MediaRepository
+
+	// Link Previews
+	PreviewRepository
+
+	// Polls
+	PollRepository
+
+	// Scheduled Tweets
+	ScheduledTweetRepository
+
+	// Drafts
+	DraftRepository
+}
+
+// KnownUserRepository defines the interface for known users data operations
+type KnownUserRepository interface {
+	GetKnownUserID(ctx context.Context, handler string) (string, error)
+	SaveKnownUser(ctx context.Context, userID, handler string) error
+}
+
+// MediaRepository defines the interface for media data operations
+type MediaRepository interface {
+	KnownUserRepository
+
+	CreateMedia(ctx context.Context, media *Media) error
+	GetMedia(ctx context.Context, id string) (*Media, error)
+	GetMediaByIDs(ctx context.Context, ids []string) ([]*Media, error)
+}
+
+// PreviewRepository defines the interface for link previews data operations
+type PreviewRepository interface {
+	GetByID(ctx context.Context, id string) (*Tweet, error)
+	SetPreview(ctx context.Context, id string, preview *LinkPreview) error
+}
+
+// PollRepository defines the interface for poll votes data operations
+type PollRepository interface {
+	CreateVote(ctx context.Context, vote *PollVote) error
+	GetVote(ctx context.Context, tweetID, userID string) (*PollVote, error)
+	CountVotes(ctx context.Context, tweetID string, before time.Time) (map[int]int64, error)
+}
+
+// ScheduledTweetRepository defines the interface for scheduled tweets data operations
+type ScheduledTweetRepository interface {
+	GetScheduledByUserID(ctx context.Context, userID string) ([]*Tweet, error)
+	GetDueScheduled(ctx context.Context, before time.Time, limit int) ([]*Tweet, error)
+	PublishScheduled(ctx context.Context, id string, publishedAt time.Time) (*Tweet, error)
+	Reschedule(ctx context.Context, id string, publishAt time.Time) (*Tweet, error)
+	CancelScheduled(ctx context.Context, id string) error
+	GetPostedPending(ctx context.Context, before time.Time, limit int) ([]*Tweet, error)
+	MarkPosted(ctx context.Context, id string) error
+}
+
+// DraftRepository defines the interface for draft data operations
+type DraftRepository interface {
+	KnownUserRepository
+
+	CreateDraft(ctx context.Context, draft *Draft) error
+	GetDraft(ctx context.Context, id string) (*Draft, error)
+	GetDraftsByUserID(ctx context.Context, userID string) ([]*Draft, error)
+	UpdateDraft(ctx context.Context, draft *Draft) error
+	DeleteDraft(ctx context.Context, id string) error
 }
 
+// TombstoneRepository defines the interface for the tombstones of the deleted tweets
+type TombstoneRepository interface {
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
+}
+
+// ErrTweetNotFound is returned when reviewing a tweet that does not exist
+var ErrTweetNotFound = errors.New("tweet not found")
+
+// ErrTweetNotHeld is returned when reviewing a tweet that is not held for review
+var ErrTweetNotHeld = errors.New("tweet is not held for review")
+
+// ErrTweetNotScheduled is returned when publishing, rescheduling or cancelling a tweet that is no longer scheduled
+var ErrTweetNotScheduled = errors.New("tweet is not scheduled")
+
 // InMemoryTweetRepository is an in-memory implementation of the Repository interface
 type InMemoryTweetRepository struct {
-	tweets map[string]*Tweet
-	mu     sync.RWMutex
+	tweets  map[string]*Tweet
+	deleted map[string]*Tweet // Tombstones of the deleted tweets, until they are purged
+	media   map[string]*Media
+	votes   map[string]map[string]*PollVote // tweet ID -> user ID -> vote
+	drafts  map[string]*Draft
+	// deactivatedUsers are the IDs of the users whose tweets are hidden, with their deactivation time
+	deactivatedUsers map[string]time.Time
+	// knownUsers are the current handlers of the users by their ID
+	knownUsers map[string]string
+	mu         sync.RWMutex
 }
 
 // NewInMemoryTweetRepository creates a new in-memory tweet repository
 func NewInMemoryTweetRepository() *InMemoryTweetRepository {
 	return &InMemoryTweetRepository{
-		tweets: make(map[string]*Tweet),
+		tweets:  make(map[string]*Tweet),
+		deleted: make(map[string]*Tweet),
+		media:   make(map[string]*Media),
+		votes:   make(map[string]map[string]*PollVote),
+		drafts:  make(map[string]*Draft),
+
+		deactivatedUsers: make(map[string]time.Time),
+		knownUsers:       make(map[string]string),
 	}
 }
 
@@ -53,17 +174,532 @@ func (repository *InMemoryTweetRepository) GetByUserID(ctx context.Context, user
 
 	var userTweets []*Tweet
 	for _, tweet := range repository.tweets {
-		if tweet.Handler == userID {
+		if tweet.UserID == userID && tweet.IsVisible() {
 			userTweets = append(userTweets, tweet)
 		}
 	}
+	// Newest first, like the PostgreSQL repository
+	sort.Slice(userTweets, func(i, j int) bool {
+		if !userTweets[i].CreatedAt.Equal(userTweets[j].CreatedAt) {
+			return userTweets[i].CreatedAt.After(userTweets[j].CreatedAt)
+		}
+		return userTweets[i].ID < userTweets[j].ID
+	})
 	return userTweets, nil
 }
 
+// Delete soft deletes a tweet, keeping its tombstone until it is purged
 func (repository *InMemoryTweetRepository) Delete(ctx context.Context, id string) error {
 	repository.mu.Lock()
 	defer repository.mu.Unlock()
 
+	tweet, exists := repository.tweets[id]
+	if !exists {
+		return nil
+	}
+	tombstone := *tweet
+	tombstone.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	repository.deleted[id] = &tombstone
 	delete(repository.tweets, id)
 	return nil
 }
+
+// PurgeDeleted permanently deletes up to limit tweets deleted before a time with their votes, and returns how many
+func (repository *InMemoryTweetRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	var purged int64
+	for id, tombstone := range repository.deleted {
+		if purged == int64(limit) {
+			break
+		}
+		if tombstone.DeletedAt.Time.Before(before) {
+			delete(repository.deleted, id)
+			delete(repository.votes, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// GetKnownUserID retrieves the ID of the user known by a handler, "" if no user is known by it
+func (repository *InMemoryTweetRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	for userID, knownHandler := range repository.knownUsers {
+		if knownHandler == handler {
+			return userID, nil
+		}
+	}
+	return "", nil
+}
+
+// SaveKnownUser records the current handler of a user
+func (repository *InMemoryTweetRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.saveKnownUser(userID, handler)
+	return nil
+}
+
+// saveKnownUser records the current handler of a user, which is no longer the one of the other users known by it
+func (repository *InMemoryTweetRepository) saveKnownUser(userID, handler string) {
+	for knownID, knownHandler := range repository.knownUsers {
+		if knownHandler == handler && knownID != userID {
+			delete(repository.knownUsers, knownID)
+		}
+	}
+	repository.knownUsers[userID] = handler
+}
+
+// DeleteUserData soft deletes the tweets of a user, and deletes their drafts, votes and known handler
+func (repository *InMemoryTweetRepository) DeleteUserData(ctx context.Context, userID string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	deletedAt := gorm.DeletedAt{Time: time.Now().UTC(), Valid: true}
+	for id, tweet := range repository.tweets {
+		if tweet.UserID == userID {
+			tombstone := *tweet
+			tombstone.DeletedAt = deletedAt
+			repository.deleted[id] = &tombstone
+			delete(repository.tweets, id)
+		}
+	}
+	for id, draft := range repository.drafts {
+		if draft.UserID == userID {
+			delete(repository.drafts, id)
+		}
+	}
+	for _, votes := range repository.votes {
+		delete(votes, userID)
+	}
+	delete(repository.deactivatedUsers, userID)
+	delete(repository.knownUsers, userID)
+	return nil
+}
+
+// SaveDeactivatedUser records that a user deactivated their account, saving it again keeps the first deactivation time
+func (repository *InMemoryTweetRepository) SaveDeactivatedUser(ctx context.Context, userID string, deactivatedAt time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if _, exists := repository.deactivatedUsers[userID]; !exists {
+		repository.deactivatedUsers[userID] = deactivatedAt
+	}
+	return nil
+}
+
+// DeleteDeactivatedUser records that a user reactivated their account
+func (repository *InMemoryTweetRepository) DeleteDeactivatedUser(ctx context.Context, userID string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	delete(repository.deactivatedUsers, userID)
+	return nil
+}
+
+// IsUserDeactivated checks if a user deactivated their account
+func (repository *InMemoryTweetRepository) IsUserDeactivated(ctx context.Context, userID string) (bool, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	_, exists := repository.deactivatedUsers[userID]
+	return exists, nil
+}
+
+// RenameUser makes a user known by their new handler unless a later change was applied, and shows their data with it
+func (repository *InMemoryTweetRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	handler, known := repository.knownUsers[userID]
+	if !known || handler == oldHandler {
+		handler = newHandler
+		repository.saveKnownUser(userID, handler)
+	}
+
+	// Replace the tweets, drafts and media, since they may be shared with the callers
+	for _, tweets := range []map[string]*Tweet{repository.tweets, repository.deleted} {
+		for id, tweet := range tweets {
+			if tweet.UserID == userID && tweet.Handler != handler {
+				renamed := *tweet
+				renamed.Handler = handler
+				tweets[id] = &renamed
+			}
+		}
+	}
+	for id, draft := range repository.drafts {
+		if draft.UserID == userID && draft.Handler != handler {
+			renamed := *draft
+			renamed.Handler = handler
+			repository.drafts[id] = &renamed
+		}
+	}
+	for id, media := range repository.media {
+		if media.UserID == userID && media.Handler != handler {
+			renamed := *media
+			renamed.Handler = handler
+			repository.media[id] = &renamed
+		}
+	}
+	return nil
+}
+
+// GetByStatus retrieves the tweets with a status, oldest first
+func (repository *InMemoryTweetRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*Tweet, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var tweets []*Tweet
+	for _, tweet := range repository.tweets {
+		if tweet.Status == status {
+			tweets = append(tweets, tweet)
+		}
+	}
+	sort.Slice(tweets, func(i, j int) bool {
+		if !tweets[i].CreatedAt.Equal(tweets[j].CreatedAt) {
+			return tweets[i].CreatedAt.Before(tweets[j].CreatedAt)
+		}
+		return tweets[i].ID < tweets[j].ID
+	})
+
+	if offset >= len(tweets) {
+		return []*Tweet{}, nil
+	}
+	return tweets[offset:min(offset+limit, len(tweets))], nil
+}
+
+// CountByStatus counts the tweets with a status
+func (repository *InMemoryTweetRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var count int64
+	for _, tweet := range repository.tweets {
+		if tweet.Status == status {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Review sets the status of a tweet held for review
+func (repository *InMemoryTweetRepository) Review(ctx context.Context, id, status string, reviewedAt time.Time) (*Tweet, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	tweet, exists := repository.tweets[id]
+	if !exists {
+		return nil, ErrTweetNotFound
+	}
+	if tweet.Status != TweetStatusHeld {
+		return nil, ErrTweetNotHeld
+	}
+	tweet.Status = status
+	tweet.ReviewedAt = &reviewedAt
+
+	// Return a copy to prevent external modifications
+	result := *tweet
+	return &result, nil
+}
+
+// CreateMedia saves an uploaded media
+func (repository *InMemoryTweetRepository) CreateMedia(ctx context.Context, media *Media) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	stored := *media
+	repository.media[media.ID] = &stored
+	return nil
+}
+
+// GetMedia retrieves a media
+func (repository *InMemoryTweetRepository) GetMedia(ctx context.Context, id string) (*Media, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	media, exists := repository.media[id]
+	if !exists {
+		return nil, ErrMediaNotFound
+	}
+	result := *media
+	return &result, nil
+}
+
+// GetMediaByIDs retrieves the existing media among the IDs, in no particular order
+func (repository *InMemoryTweetRepository) GetMediaByIDs(ctx context.Context, ids []string) ([]*Media, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var found []*Media
+	for _, id := range ids {
+		if media, exists := repository.media[id]; exists {
+			result := *media
+			found = append(found, &result)
+		}
+	}
+	return found, nil
+}
+
+// SetPreview attaches the preview card of a link to the content of a tweet
+func (repository *InMemoryTweetRepository) SetPreview(ctx context.Context, id string, preview *LinkPreview) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	tweet, exists := repository.tweets[id]
+	if !exists {
+		return ErrTweetNotFound
+	}
+	// Replace the tweet, since the stored one may be shared with the callers of GetByID
+	updated := *tweet
+	updated.Content.Preview = preview
+	repository.tweets[id] = &updated
+	return nil
+}
+
+// CreateVote saves the vote of a user on a poll, returning ErrAlreadyVoted if the user already voted on it
+func (repository *InMemoryTweetRepository) CreateVote(ctx context.Context, vote *PollVote) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	votes, exists := repository.votes[vote.TweetID]
+	if !exists {
+		votes = make(map[string]*PollVote)
+		repository.votes[vote.TweetID] = votes
+	}
+	if _, voted := votes[vote.UserID]; voted {
+		return ErrAlreadyVoted
+	}
+	stored := *vote
+	votes[vote.UserID] = &stored
+	return nil
+}
+
+// GetVote retrieves the vote of a user on a poll, returning nil if the user did not vote on it
+func (repository *InMemoryTweetRepository) GetVote(ctx context.Context, tweetID, userID string) (*PollVote, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	vote, exists := repository.votes[tweetID][userID]
+	if !exists {
+		return nil, nil
+	}
+	result := *vote
+	return &result, nil
+}
+
+// CountVotes counts the votes of each option of a poll cast before a time
+func (repository *InMemoryTweetRepository) CountVotes(ctx context.Context, tweetID string, before time.Time) (map[int]int64, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	tallies := map[int]int64{}
+	for _, vote := range repository.votes[tweetID] {
+		if vote.CreatedAt.Before(before) {
+			tallies[vote.Option]++
+		}
+	}
+	return tallies, nil
+}
+
+// GetScheduledByUserID retrieves the scheduled tweets of a user, the soonest to be published first
+func (repository *InMemoryTweetRepository) GetScheduledByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var scheduled []*Tweet
+	for _, tweet := range repository.tweets {
+		if tweet.UserID == userID && tweet.Status == TweetStatusScheduled {
+			scheduled = append(scheduled, tweet)
+		}
+	}
+	sortByPublishTime(scheduled)
+	return scheduled, nil
+}
+
+// GetDueScheduled retrieves up to limit scheduled tweets to publish before a time, the soonest first
+func (repository *InMemoryTweetRepository) GetDueScheduled(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var due []*Tweet
+	for _, tweet := range repository.tweets {
+		if tweet.Status == TweetStatusScheduled && tweet.PublishAt != nil && !tweet.PublishAt.After(before) {
+			due = append(due, tweet)
+		}
+	}
+	sortByPublishTime(due)
+	return due[:min(limit, len(due))], nil
+}
+
+// sortByPublishTime sorts scheduled tweets by publish time, then by ID
+func sortByPublishTime(tweets []*Tweet) {
+	sort.Slice(tweets, func(i, j int) bool {
+		if !tweets[i].PublishAt.Equal(*tweets[j].PublishAt) {
+			return tweets[i].PublishAt.Before(*tweets[j].PublishAt)
+		}
+		return tweets[i].ID < tweets[j].ID
+	})
+}
+
+// PublishScheduled publishes a scheduled tweet pending to be posted, returning ErrTweetNotScheduled if it is not scheduled
+func (repository *InMemoryTweetRepository) PublishScheduled(ctx context.Context, id string, publishedAt time.Time) (*Tweet, error) {
+	return repository.updateScheduled(id, func(tweet *Tweet) {
+		tweet.Status = TweetStatusPublished
+		tweet.PublishAt = nil
+		tweet.CreatedAt = publishedAt
+		tweet.PostedPending = true
+	})
+}
+
+// Reschedule changes the publish time of a scheduled tweet
+func (repository *InMemoryTweetRepository) Reschedule(ctx context.Context, id string, publishAt time.Time) (*Tweet, error) {
+	return repository.updateScheduled(id, func(tweet *Tweet) {
+		tweet.PublishAt = &publishAt
+	})
+}
+
+// updateScheduled replaces a scheduled tweet with an updated copy and returns another copy
+func (repository *InMemoryTweetRepository) updateScheduled(id string, update func(tweet *Tweet)) (*Tweet, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	tweet, exists := repository.tweets[id]
+	if !exists {
+		return nil, ErrTweetNotFound
+	}
+	if tweet.Status != TweetStatusScheduled {
+		return nil, ErrTweetNotScheduled
+	}
+	updated := *tweet
+	update(&updated)
+	repository.tweets[id] = &updated
+
+	result := updated
+	return &result, nil
+}
+
+// CancelScheduled deletes a scheduled tweet before it is published
+func (repository *InMemoryTweetRepository) CancelScheduled(ctx context.Context, id string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	tweet, exists := repository.tweets[id]
+	if !exists {
+		return ErrTweetNotFound
+	}
+	if tweet.Status != TweetStatusScheduled {
+		return ErrTweetNotScheduled
+	}
+	delete(repository.tweets, id)
+	return nil
+}
+
+// GetPostedPending retrieves up to limit published tweets pending to be posted, published before a time, the oldest first
+func (repository *InMemoryTweetRepository) GetPostedPending(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var pending []*Tweet
+	for _, tweet := range repository.tweets {
+		if tweet.PostedPending && tweet.CreatedAt.Before(before) {
+			copied := *tweet
+			pending = append(pending, &copied)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+	return pending[:min(limit, len(pending))], nil
+}
+
+// MarkPosted marks a published tweet as posted, a missing tweet is ignored
+func (repository *InMemoryTweetRepository) MarkPosted(ctx context.Context, id string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if tweet, exists := repository.tweets[id]; exists && tweet.PostedPending {
+		updated := *tweet
+		updated.PostedPending = false
+		repository.tweets[id] = &updated
+	}
+	return nil
+}
+
+// CreateDraft saves a new draft
+func (repository *InMemoryTweetRepository) CreateDraft(ctx context.Context, draft *Draft) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	stored := *draft
+	repository.drafts[draft.ID] = &stored
+	return nil
+}
+
+// GetDraft retrieves a draft
+func (repository *InMemoryTweetRepository) GetDraft(ctx context.Context, id string) (*Draft, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	draft, exists := repository.drafts[id]
+	if !exists {
+		return nil, ErrDraftNotFound
+	}
+	result := *draft
+	return &result, nil
+}
+
+// GetDraftsByUserID retrieves the drafts of a user, the most recently updated first
+func (repository *InMemoryTweetRepository) GetDraftsByUserID(ctx context.Context, userID string) ([]*Draft, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var drafts []*Draft
+	for _, draft := range repository.drafts {
+		if draft.UserID == userID {
+			result := *draft
+			drafts = append(drafts, &result)
+		}
+	}
+	sort.Slice(drafts, func(i, j int) bool {
+		if !drafts[i].UpdatedAt.Equal(drafts[j].UpdatedAt) {
+			return drafts[i].UpdatedAt.After(drafts[j].UpdatedAt)
+		}
+		return drafts[i].ID < drafts[j].ID
+	})
+	return drafts, nil
+}
+
+// UpdateDraft replaces the content and publish time of a draft
+func (repository *InMemoryTweetRepository) UpdateDraft(ctx context.Context, draft *Draft) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	stored, exists := repository.drafts[draft.ID]
+	if !exists {
+		return ErrDraftNotFound
+	}
+	updated := *stored
+	updated.Content, updated.PublishAt, updated.UpdatedAt = draft.Content, draft.PublishAt, draft.UpdatedAt
+	repository.drafts[draft.ID] = &updated
+	return nil
+}
+
+// DeleteDraft deletes a draft, only one of concurrent deletions succeeds and the others return ErrDraftNotFound
+func (repository *InMemoryTweetRepository) DeleteDraft(ctx context.Context, id string) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	if _, exists := repository.drafts[id]; !exists {
+		return ErrDraftNotFound
+	}
+	delete(repository.drafts, id)
+	return nil
+}
@@ -12,6 +12,7 @@ package tweets
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,50 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CancelScheduled mocks base method.
+func (m *MockRepository) CancelScheduled(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduled", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelScheduled indicates an expected call of CancelScheduled.
+func (mr *MockRepositoryMockRecorder) CancelScheduled(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduled", reflect.TypeOf((*MockRepository)(nil).CancelScheduled), ctx, id)
+}
+
+// CountByStatus mocks base method.
+func (m *MockRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByStatus", ctx, status)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByStatus indicates an expected call of CountByStatus.
+func (mr *MockRepositoryMockRecorder) CountByStatus(ctx, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByStatus", reflect.TypeOf((*MockRepository)(nil).CountByStatus), ctx, status)
+}
+
+// CountVotes mocks base method.
+func (m *MockRepository) CountVotes(ctx context.Context, tweetID string, before time.Time) (map[int]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVotes", ctx, tweetID, before)
+	ret0, _ := ret[0].(map[int]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountVotes indicates an expected call of CountVotes.
+func (mr *MockRepositoryMockRecorder) CountVotes(ctx, tweetID, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVotes", reflect.TypeOf((*MockRepository)(nil).CountVotes), ctx, tweetID, before)
+}
+
 // Create mocks base method.
 func (m *MockRepository) Create(ctx context.Context, tweet *Tweet) (*Tweet, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +100,48 @@ func (mr *MockRepositoryMockRecorder) Create(ctx, tweet any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRepository)(nil).Create), ctx, tweet)
 }
 
+// CreateDraft mocks base method.
+func (m *MockRepository) CreateDraft(ctx context.Context, draft *Draft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDraft", ctx, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDraft indicates an expected call of CreateDraft.
+func (mr *MockRepositoryMockRecorder) CreateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDraft", reflect.TypeOf((*MockRepository)(nil).CreateDraft), ctx, draft)
+}
+
+// CreateMedia mocks base method.
+func (m *MockRepository) CreateMedia(ctx context.Context, media *Media) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMedia", ctx, media)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMedia indicates an expected call of CreateMedia.
+func (mr *MockRepositoryMockRecorder) CreateMedia(ctx, media any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMedia", reflect.TypeOf((*MockRepository)(nil).CreateMedia), ctx, media)
+}
+
+// CreateVote mocks base method.
+func (m *MockRepository) CreateVote(ctx context.Context, vote *PollVote) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVote", ctx, vote)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVote indicates an expected call of CreateVote.
+func (mr *MockRepositoryMockRecorder) CreateVote(ctx, vote any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVote", reflect.TypeOf((*MockRepository)(nil).CreateVote), ctx, vote)
+}
+
 // Delete mocks base method.
 func (m *MockRepository) Delete(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -69,6 +156,48 @@ func (mr *MockRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockRepository)(nil).Delete), ctx, id)
 }
 
+// DeleteDeactivatedUser mocks base method.
+func (m *MockRepository) DeleteDeactivatedUser(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeactivatedUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeactivatedUser indicates an expected call of DeleteDeactivatedUser.
+func (mr *MockRepositoryMockRecorder) DeleteDeactivatedUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeactivatedUser", reflect.TypeOf((*MockRepository)(nil).DeleteDeactivatedUser), ctx, userID)
+}
+
+// DeleteDraft mocks base method.
+func (m *MockRepository) DeleteDraft(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDraft", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDraft indicates an expected call of DeleteDraft.
+func (mr *MockRepositoryMockRecorder) DeleteDraft(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDraft", reflect.TypeOf((*MockRepository)(nil).DeleteDraft), ctx, id)
+}
+
+// DeleteUserData mocks base method.
+func (m *MockRepository) DeleteUserData(ctx context.Context, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserData", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserData indicates an expected call of DeleteUserData.
+func (mr *MockRepositoryMockRecorder) DeleteUserData(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserData", reflect.TypeOf((*MockRepository)(nil).DeleteUserData), ctx, userID)
+}
+
 // GetByID mocks base method.
 func (m *MockRepository) GetByID(ctx context.Context, id string) (*Tweet, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +213,21 @@ func (mr *MockRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByStatus mocks base method.
+func (m *MockRepository) GetByStatus(ctx context.Context, status string, limit, offset int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByStatus", ctx, status, limit, offset)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByStatus indicates an expected call of GetByStatus.
+func (mr *MockRepositoryMockRecorder) GetByStatus(ctx, status, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByStatus", reflect.TypeOf((*MockRepository)(nil).GetByStatus), ctx, status, limit, offset)
+}
+
 // GetByUserID mocks base method.
 func (m *MockRepository) GetByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
 	m.ctrl.T.Helper()
@@ -98,3 +242,859 @@ func (mr *MockRepositoryMockRecorder) GetByUserID(ctx, userID any) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByUserID", reflect.TypeOf((*MockRepository)(nil).GetByUserID), ctx, userID)
 }
+
+// GetDraft mocks base method.
+func (m *MockRepository) GetDraft(ctx context.Context, id string) (*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraft", ctx, id)
+	ret0, _ := ret[0].(*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraft indicates an expected call of GetDraft.
+func (mr *MockRepositoryMockRecorder) GetDraft(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraft", reflect.TypeOf((*MockRepository)(nil).GetDraft), ctx, id)
+}
+
+// GetDraftsByUserID mocks base method.
+func (m *MockRepository) GetDraftsByUserID(ctx context.Context, userID string) ([]*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraftsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraftsByUserID indicates an expected call of GetDraftsByUserID.
+func (mr *MockRepositoryMockRecorder) GetDraftsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraftsByUserID", reflect.TypeOf((*MockRepository)(nil).GetDraftsByUserID), ctx, userID)
+}
+
+// GetDueScheduled mocks base method.
+func (m *MockRepository) GetDueScheduled(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueScheduled", ctx, before, limit)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueScheduled indicates an expected call of GetDueScheduled.
+func (mr *MockRepositoryMockRecorder) GetDueScheduled(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueScheduled", reflect.TypeOf((*MockRepository)(nil).GetDueScheduled), ctx, before, limit)
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// GetMedia mocks base method.
+func (m *MockRepository) GetMedia(ctx context.Context, id string) (*Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMedia", ctx, id)
+	ret0, _ := ret[0].(*Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMedia indicates an expected call of GetMedia.
+func (mr *MockRepositoryMockRecorder) GetMedia(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMedia", reflect.TypeOf((*MockRepository)(nil).GetMedia), ctx, id)
+}
+
+// GetMediaByIDs mocks base method.
+func (m *MockRepository) GetMediaByIDs(ctx context.Context, ids []string) ([]*Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByIDs indicates an expected call of GetMediaByIDs.
+func (mr *MockRepositoryMockRecorder) GetMediaByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByIDs", reflect.TypeOf((*MockRepository)(nil).GetMediaByIDs), ctx, ids)
+}
+
+// GetPostedPending mocks base method.
+func (m *MockRepository) GetPostedPending(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostedPending", ctx, before, limit)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostedPending indicates an expected call of GetPostedPending.
+func (mr *MockRepositoryMockRecorder) GetPostedPending(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostedPending", reflect.TypeOf((*MockRepository)(nil).GetPostedPending), ctx, before, limit)
+}
+
+// GetScheduledByUserID mocks base method.
+func (m *MockRepository) GetScheduledByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledByUserID indicates an expected call of GetScheduledByUserID.
+func (mr *MockRepositoryMockRecorder) GetScheduledByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledByUserID", reflect.TypeOf((*MockRepository)(nil).GetScheduledByUserID), ctx, userID)
+}
+
+// GetVote mocks base method.
+func (m *MockRepository) GetVote(ctx context.Context, tweetID, userID string) (*PollVote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVote", ctx, tweetID, userID)
+	ret0, _ := ret[0].(*PollVote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVote indicates an expected call of GetVote.
+func (mr *MockRepositoryMockRecorder) GetVote(ctx, tweetID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVote", reflect.TypeOf((*MockRepository)(nil).GetVote), ctx, tweetID, userID)
+}
+
+// IsUserDeactivated mocks base method.
+func (m *MockRepository) IsUserDeactivated(ctx context.Context, userID string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsUserDeactivated", ctx, userID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsUserDeactivated indicates an expected call of IsUserDeactivated.
+func (mr *MockRepositoryMockRecorder) IsUserDeactivated(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsUserDeactivated", reflect.TypeOf((*MockRepository)(nil).IsUserDeactivated), ctx, userID)
+}
+
+// MarkPosted mocks base method.
+func (m *MockRepository) MarkPosted(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPosted", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPosted indicates an expected call of MarkPosted.
+func (mr *MockRepositoryMockRecorder) MarkPosted(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPosted", reflect.TypeOf((*MockRepository)(nil).MarkPosted), ctx, id)
+}
+
+// PublishScheduled mocks base method.
+func (m *MockRepository) PublishScheduled(ctx context.Context, id string, publishedAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishScheduled", ctx, id, publishedAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishScheduled indicates an expected call of PublishScheduled.
+func (mr *MockRepositoryMockRecorder) PublishScheduled(ctx, id, publishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishScheduled", reflect.TypeOf((*MockRepository)(nil).PublishScheduled), ctx, id, publishedAt)
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockRepositoryMockRecorder) PurgeDeleted(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockRepository)(nil).PurgeDeleted), ctx, before, limit)
+}
+
+// RenameUser mocks base method.
+func (m *MockRepository) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", ctx, userID, oldHandler, newHandler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockRepositoryMockRecorder) RenameUser(ctx, userID, oldHandler, newHandler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockRepository)(nil).RenameUser), ctx, userID, oldHandler, newHandler)
+}
+
+// Reschedule mocks base method.
+func (m *MockRepository) Reschedule(ctx context.Context, id string, publishAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, id, publishAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockRepositoryMockRecorder) Reschedule(ctx, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockRepository)(nil).Reschedule), ctx, id, publishAt)
+}
+
+// Review mocks base method.
+func (m *MockRepository) Review(ctx context.Context, id, status string, reviewedAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Review", ctx, id, status, reviewedAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Review indicates an expected call of Review.
+func (mr *MockRepositoryMockRecorder) Review(ctx, id, status, reviewedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Review", reflect.TypeOf((*MockRepository)(nil).Review), ctx, id, status, reviewedAt)
+}
+
+// SaveDeactivatedUser mocks base method.
+func (m *MockRepository) SaveDeactivatedUser(ctx context.Context, userID string, deactivatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeactivatedUser", ctx, userID, deactivatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeactivatedUser indicates an expected call of SaveDeactivatedUser.
+func (mr *MockRepositoryMockRecorder) SaveDeactivatedUser(ctx, userID, deactivatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeactivatedUser", reflect.TypeOf((*MockRepository)(nil).SaveDeactivatedUser), ctx, userID, deactivatedAt)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// SetPreview mocks base method.
+func (m *MockRepository) SetPreview(ctx context.Context, id string, preview *LinkPreview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreview", ctx, id, preview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreview indicates an expected call of SetPreview.
+func (mr *MockRepositoryMockRecorder) SetPreview(ctx, id, preview any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreview", reflect.TypeOf((*MockRepository)(nil).SetPreview), ctx, id, preview)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockRepository) UpdateDraft(ctx context.Context, draft *Draft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", ctx, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockRepositoryMockRecorder) UpdateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockRepository)(nil).UpdateDraft), ctx, draft)
+}
+
+// MockKnownUserRepository is a mock of KnownUserRepository interface.
+type MockKnownUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockKnownUserRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockKnownUserRepositoryMockRecorder is the mock recorder for MockKnownUserRepository.
+type MockKnownUserRepositoryMockRecorder struct {
+	mock *MockKnownUserRepository
+}
+
+// NewMockKnownUserRepository creates a new mock instance.
+func NewMockKnownUserRepository(ctrl *gomock.Controller) *MockKnownUserRepository {
+	mock := &MockKnownUserRepository{ctrl: ctrl}
+	mock.recorder = &MockKnownUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKnownUserRepository) EXPECT() *MockKnownUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockKnownUserRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockKnownUserRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockKnownUserRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockKnownUserRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockKnownUserRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockKnownUserRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// MockMediaRepository is a mock of MediaRepository interface.
+type MockMediaRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMediaRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMediaRepositoryMockRecorder is the mock recorder for MockMediaRepository.
+type MockMediaRepositoryMockRecorder struct {
+	mock *MockMediaRepository
+}
+
+// NewMockMediaRepository creates a new mock instance.
+func NewMockMediaRepository(ctrl *gomock.Controller) *MockMediaRepository {
+	mock := &MockMediaRepository{ctrl: ctrl}
+	mock.recorder = &MockMediaRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMediaRepository) EXPECT() *MockMediaRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateMedia mocks base method.
+func (m *MockMediaRepository) CreateMedia(ctx context.Context, media *Media) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMedia", ctx, media)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMedia indicates an expected call of CreateMedia.
+func (mr *MockMediaRepositoryMockRecorder) CreateMedia(ctx, media any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMedia", reflect.TypeOf((*MockMediaRepository)(nil).CreateMedia), ctx, media)
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockMediaRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockMediaRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockMediaRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// GetMedia mocks base method.
+func (m *MockMediaRepository) GetMedia(ctx context.Context, id string) (*Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMedia", ctx, id)
+	ret0, _ := ret[0].(*Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMedia indicates an expected call of GetMedia.
+func (mr *MockMediaRepositoryMockRecorder) GetMedia(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMedia", reflect.TypeOf((*MockMediaRepository)(nil).GetMedia), ctx, id)
+}
+
+// GetMediaByIDs mocks base method.
+func (m *MockMediaRepository) GetMediaByIDs(ctx context.Context, ids []string) ([]*Media, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMediaByIDs", ctx, ids)
+	ret0, _ := ret[0].([]*Media)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMediaByIDs indicates an expected call of GetMediaByIDs.
+func (mr *MockMediaRepositoryMockRecorder) GetMediaByIDs(ctx, ids any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMediaByIDs", reflect.TypeOf((*MockMediaRepository)(nil).GetMediaByIDs), ctx, ids)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockMediaRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockMediaRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockMediaRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// MockPreviewRepository is a mock of PreviewRepository interface.
+type MockPreviewRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreviewRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPreviewRepositoryMockRecorder is the mock recorder for MockPreviewRepository.
+type MockPreviewRepositoryMockRecorder struct {
+	mock *MockPreviewRepository
+}
+
+// NewMockPreviewRepository creates a new mock instance.
+func NewMockPreviewRepository(ctrl *gomock.Controller) *MockPreviewRepository {
+	mock := &MockPreviewRepository{ctrl: ctrl}
+	mock.recorder = &MockPreviewRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreviewRepository) EXPECT() *MockPreviewRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetByID mocks base method.
+func (m *MockPreviewRepository) GetByID(ctx context.Context, id string) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockPreviewRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockPreviewRepository)(nil).GetByID), ctx, id)
+}
+
+// SetPreview mocks base method.
+func (m *MockPreviewRepository) SetPreview(ctx context.Context, id string, preview *LinkPreview) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreview", ctx, id, preview)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPreview indicates an expected call of SetPreview.
+func (mr *MockPreviewRepositoryMockRecorder) SetPreview(ctx, id, preview any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreview", reflect.TypeOf((*MockPreviewRepository)(nil).SetPreview), ctx, id, preview)
+}
+
+// MockPollRepository is a mock of PollRepository interface.
+type MockPollRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockPollRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockPollRepositoryMockRecorder is the mock recorder for MockPollRepository.
+type MockPollRepositoryMockRecorder struct {
+	mock *MockPollRepository
+}
+
+// NewMockPollRepository creates a new mock instance.
+func NewMockPollRepository(ctrl *gomock.Controller) *MockPollRepository {
+	mock := &MockPollRepository{ctrl: ctrl}
+	mock.recorder = &MockPollRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPollRepository) EXPECT() *MockPollRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountVotes mocks base method.
+func (m *MockPollRepository) CountVotes(ctx context.Context, tweetID string, before time.Time) (map[int]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountVotes", ctx, tweetID, before)
+	ret0, _ := ret[0].(map[int]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountVotes indicates an expected call of CountVotes.
+func (mr *MockPollRepositoryMockRecorder) CountVotes(ctx, tweetID, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountVotes", reflect.TypeOf((*MockPollRepository)(nil).CountVotes), ctx, tweetID, before)
+}
+
+// CreateVote mocks base method.
+func (m *MockPollRepository) CreateVote(ctx context.Context, vote *PollVote) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateVote", ctx, vote)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateVote indicates an expected call of CreateVote.
+func (mr *MockPollRepositoryMockRecorder) CreateVote(ctx, vote any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateVote", reflect.TypeOf((*MockPollRepository)(nil).CreateVote), ctx, vote)
+}
+
+// GetVote mocks base method.
+func (m *MockPollRepository) GetVote(ctx context.Context, tweetID, userID string) (*PollVote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVote", ctx, tweetID, userID)
+	ret0, _ := ret[0].(*PollVote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVote indicates an expected call of GetVote.
+func (mr *MockPollRepositoryMockRecorder) GetVote(ctx, tweetID, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVote", reflect.TypeOf((*MockPollRepository)(nil).GetVote), ctx, tweetID, userID)
+}
+
+// MockScheduledTweetRepository is a mock of ScheduledTweetRepository interface.
+type MockScheduledTweetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduledTweetRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockScheduledTweetRepositoryMockRecorder is the mock recorder for MockScheduledTweetRepository.
+type MockScheduledTweetRepositoryMockRecorder struct {
+	mock *MockScheduledTweetRepository
+}
+
+// NewMockScheduledTweetRepository creates a new mock instance.
+func NewMockScheduledTweetRepository(ctrl *gomock.Controller) *MockScheduledTweetRepository {
+	mock := &MockScheduledTweetRepository{ctrl: ctrl}
+	mock.recorder = &MockScheduledTweetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduledTweetRepository) EXPECT() *MockScheduledTweetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduled mocks base method.
+func (m *MockScheduledTweetRepository) CancelScheduled(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduled", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelScheduled indicates an expected call of CancelScheduled.
+func (mr *MockScheduledTweetRepositoryMockRecorder) CancelScheduled(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduled", reflect.TypeOf((*MockScheduledTweetRepository)(nil).CancelScheduled), ctx, id)
+}
+
+// GetDueScheduled mocks base method.
+func (m *MockScheduledTweetRepository) GetDueScheduled(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueScheduled", ctx, before, limit)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueScheduled indicates an expected call of GetDueScheduled.
+func (mr *MockScheduledTweetRepositoryMockRecorder) GetDueScheduled(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueScheduled", reflect.TypeOf((*MockScheduledTweetRepository)(nil).GetDueScheduled), ctx, before, limit)
+}
+
+// GetPostedPending mocks base method.
+func (m *MockScheduledTweetRepository) GetPostedPending(ctx context.Context, before time.Time, limit int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostedPending", ctx, before, limit)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostedPending indicates an expected call of GetPostedPending.
+func (mr *MockScheduledTweetRepositoryMockRecorder) GetPostedPending(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostedPending", reflect.TypeOf((*MockScheduledTweetRepository)(nil).GetPostedPending), ctx, before, limit)
+}
+
+// GetScheduledByUserID mocks base method.
+func (m *MockScheduledTweetRepository) GetScheduledByUserID(ctx context.Context, userID string) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledByUserID indicates an expected call of GetScheduledByUserID.
+func (mr *MockScheduledTweetRepositoryMockRecorder) GetScheduledByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledByUserID", reflect.TypeOf((*MockScheduledTweetRepository)(nil).GetScheduledByUserID), ctx, userID)
+}
+
+// MarkPosted mocks base method.
+func (m *MockScheduledTweetRepository) MarkPosted(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkPosted", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkPosted indicates an expected call of MarkPosted.
+func (mr *MockScheduledTweetRepositoryMockRecorder) MarkPosted(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPosted", reflect.TypeOf((*MockScheduledTweetRepository)(nil).MarkPosted), ctx, id)
+}
+
+// PublishScheduled mocks base method.
+func (m *MockScheduledTweetRepository) PublishScheduled(ctx context.Context, id string, publishedAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishScheduled", ctx, id, publishedAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishScheduled indicates an expected call of PublishScheduled.
+func (mr *MockScheduledTweetRepositoryMockRecorder) PublishScheduled(ctx, id, publishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishScheduled", reflect.TypeOf((*MockScheduledTweetRepository)(nil).PublishScheduled), ctx, id, publishedAt)
+}
+
+// Reschedule mocks base method.
+func (m *MockScheduledTweetRepository) Reschedule(ctx context.Context, id string, publishAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reschedule", ctx, id, publishAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reschedule indicates an expected call of Reschedule.
+func (mr *MockScheduledTweetRepositoryMockRecorder) Reschedule(ctx, id, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reschedule", reflect.TypeOf((*MockScheduledTweetRepository)(nil).Reschedule), ctx, id, publishAt)
+}
+
+// MockDraftRepository is a mock of DraftRepository interface.
+type MockDraftRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDraftRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockDraftRepositoryMockRecorder is the mock recorder for MockDraftRepository.
+type MockDraftRepositoryMockRecorder struct {
+	mock *MockDraftRepository
+}
+
+// NewMockDraftRepository creates a new mock instance.
+func NewMockDraftRepository(ctrl *gomock.Controller) *MockDraftRepository {
+	mock := &MockDraftRepository{ctrl: ctrl}
+	mock.recorder = &MockDraftRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDraftRepository) EXPECT() *MockDraftRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateDraft mocks base method.
+func (m *MockDraftRepository) CreateDraft(ctx context.Context, draft *Draft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDraft", ctx, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDraft indicates an expected call of CreateDraft.
+func (mr *MockDraftRepositoryMockRecorder) CreateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDraft", reflect.TypeOf((*MockDraftRepository)(nil).CreateDraft), ctx, draft)
+}
+
+// DeleteDraft mocks base method.
+func (m *MockDraftRepository) DeleteDraft(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDraft", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDraft indicates an expected call of DeleteDraft.
+func (mr *MockDraftRepositoryMockRecorder) DeleteDraft(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDraft", reflect.TypeOf((*MockDraftRepository)(nil).DeleteDraft), ctx, id)
+}
+
+// GetDraft mocks base method.
+func (m *MockDraftRepository) GetDraft(ctx context.Context, id string) (*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraft", ctx, id)
+	ret0, _ := ret[0].(*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraft indicates an expected call of GetDraft.
+func (mr *MockDraftRepositoryMockRecorder) GetDraft(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraft", reflect.TypeOf((*MockDraftRepository)(nil).GetDraft), ctx, id)
+}
+
+// GetDraftsByUserID mocks base method.
+func (m *MockDraftRepository) GetDraftsByUserID(ctx context.Context, userID string) ([]*Draft, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDraftsByUserID", ctx, userID)
+	ret0, _ := ret[0].([]*Draft)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDraftsByUserID indicates an expected call of GetDraftsByUserID.
+func (mr *MockDraftRepositoryMockRecorder) GetDraftsByUserID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDraftsByUserID", reflect.TypeOf((*MockDraftRepository)(nil).GetDraftsByUserID), ctx, userID)
+}
+
+// GetKnownUserID mocks base method.
+func (m *MockDraftRepository) GetKnownUserID(ctx context.Context, handler string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKnownUserID", ctx, handler)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetKnownUserID indicates an expected call of GetKnownUserID.
+func (mr *MockDraftRepositoryMockRecorder) GetKnownUserID(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKnownUserID", reflect.TypeOf((*MockDraftRepository)(nil).GetKnownUserID), ctx, handler)
+}
+
+// SaveKnownUser mocks base method.
+func (m *MockDraftRepository) SaveKnownUser(ctx context.Context, userID, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveKnownUser", ctx, userID, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveKnownUser indicates an expected call of SaveKnownUser.
+func (mr *MockDraftRepositoryMockRecorder) SaveKnownUser(ctx, userID, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveKnownUser", reflect.TypeOf((*MockDraftRepository)(nil).SaveKnownUser), ctx, userID, handler)
+}
+
+// UpdateDraft mocks base method.
+func (m *MockDraftRepository) UpdateDraft(ctx context.Context, draft *Draft) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDraft", ctx, draft)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockDraftRepositoryMockRecorder) UpdateDraft(ctx, draft any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockDraftRepository)(nil).UpdateDraft), ctx, draft)
+}
+
+// MockTombstoneRepository is a mock of TombstoneRepository interface.
+type MockTombstoneRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTombstoneRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockTombstoneRepositoryMockRecorder is the mock recorder for MockTombstoneRepository.
+type MockTombstoneRepositoryMockRecorder struct {
+	mock *MockTombstoneRepository
+}
+
+// NewMockTombstoneRepository creates a new mock instance.
+func NewMockTombstoneRepository(ctrl *gomock.Controller) *MockTombstoneRepository {
+	mock := &MockTombstoneRepository{ctrl: ctrl}
+	mock.recorder = &MockTombstoneRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTombstoneRepository) EXPECT() *MockTombstoneRepositoryMockRecorder {
+	return m.recorder
+}
+
+// PurgeDeleted mocks base method.
+func (m *MockTombstoneRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeleted", ctx, before, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeleted indicates an expected call of PurgeDeleted.
+func (mr *MockTombstoneRepositoryMockRecorder) PurgeDeleted(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeleted", reflect.TypeOf((*MockTombstoneRepository)(nil).PurgeDeleted), ctx, before, limit)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInMemoryTweetRepository_Create(t *testing.T) {
@@ -80,7 +81,7 @@ func TestInMemoryTweetRepository_GetByID(t *testing.T) {
 			id: "123",
 			want: want{
 				err:   nil,
-				tweet: &Tweet{ID: "123", Handler: "testuser", Content: Content{Text: "Hello"}},
+				tweet: &Tweet{ID: "123", UserID: "testuser-id", Handler: "testuser", Content: Content{Text: "Hello"}},
 			},
 		},
 		{
@@ -127,16 +128,16 @@ func TestInMemoryTweetRepository_GetByUserID(t *testing.T) {
 		{
 			name: "tweets found",
 			setup: func(r *InMemoryTweetRepository) {
-				r.tweets["1"] = &Tweet{ID: "1", Handler: "user1", Content: Content{Text: "Tweet 1"}}
-				r.tweets["2"] = &Tweet{ID: "2", Handler: "user1", Content: Content{Text: "Tweet 2"}}
-				r.tweets["3"] = &Tweet{ID: "3", Handler: "user2", Content: Content{Text: "Tweet 3"}}
+				r.tweets["1"] = &Tweet{ID: "1", UserID: "user1-id", Handler: "user1", Content: Content{Text: "Tweet 1"}}
+				r.tweets["2"] = &Tweet{ID: "2", UserID: "user1-id", Handler: "user1", Content: Content{Text: "Tweet 2"}}
+				r.tweets["3"] = &Tweet{ID: "3", UserID: "user2-id", Handler: "user2", Content: Content{Text: "Tweet 3"}}
 			},
-			userID: "user1",
+			userID: "user1-id",
 			want: want{
 				err: nil,
 				tweets: []*Tweet{
-					{ID: "1", Handler: "user1", Content: Content{Text: "Tweet 1"}},
-					{ID: "2", Handler: "user1", Content: Content{Text: "Tweet 2"}},
+					{ID: "1", UserID: "user1-id", Handler: "user1", Content: Content{Text: "Tweet 1"}},
+					{ID: "2", UserID: "user1-id", Handler: "user1", Content: Content{Text: "Tweet 2"}},
 				},
 			},
 		},
@@ -182,7 +183,7 @@ func TestInMemoryTweetRepository_Delete(t *testing.T) {
 		{
 			name: "successful deletion",
 			setup: func(r *InMemoryTweetRepository) {
-				r.tweets["123"] = &Tweet{ID: "123", Handler: "testuser"}
+				r.tweets["123"] = &Tweet{ID: "123", UserID: "testuser-id", Handler: "testuser"}
 			},
 			id: "123",
 			want: want{
@@ -227,6 +228,7 @@ func TestInMemoryTweetRepository_ConcurrentAccess(t *testing.T) {
 		go func(i int) {
 			tweet := &Tweet{
 				ID:        uuid.NewString(),
+				UserID:    "user1-id",
 				Handler:   "user1",
 				Content:   Content{Text: "Concurrent test"},
 				CreatedAt: time.Now().UTC(),
@@ -243,7 +245,349 @@ func TestInMemoryTweetRepository_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all tweets were created
-	tweets, err := repo.GetByUserID(ctx, "user1")
+	tweets, err := repo.GetByUserID(ctx, "user1-id")
 	assert.NoError(t, err)
 	assert.Len(t, tweets, count)
 }
+
+func TestInMemoryTweetRepository_ReviewQueue(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	now := time.Now().UTC()
+
+	for _, tweet := range []*Tweet{
+		{ID: "held-2", UserID: "user1-id", Handler: "user1", Status: TweetStatusHeld, CreatedAt: now},
+		{ID: "published", UserID: "user1-id", Handler: "user1", Status: TweetStatusPublished, CreatedAt: now},
+		{ID: "held-1", UserID: "user1-id", Handler: "user1", Status: TweetStatusHeld, CreatedAt: now.Add(-time.Minute)},
+	} {
+		_, err := repo.Create(ctx, tweet)
+		assert.NoError(t, err)
+	}
+
+	// The queue is oldest first
+	held, err := repo.GetByStatus(ctx, TweetStatusHeld, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"held-1", "held-2"}, []string{held[0].ID, held[1].ID})
+
+	count, err := repo.CountByStatus(ctx, TweetStatusHeld)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	// Held tweets are not listed with the user tweets until approved
+	userTweets, err := repo.GetByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Len(t, userTweets, 1)
+
+	approved, err := repo.Review(ctx, "held-1", TweetStatusPublished, now)
+	assert.NoError(t, err)
+	assert.Equal(t, TweetStatusPublished, approved.Status)
+	assert.Equal(t, &now, approved.ReviewedAt)
+
+	userTweets, err = repo.GetByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Len(t, userTweets, 2)
+
+	// Only held tweets are reviewed
+	_, err = repo.Review(ctx, "held-1", TweetStatusRejected, now)
+	assert.Equal(t, ErrTweetNotHeld, err)
+	_, err = repo.Review(ctx, "missing", TweetStatusRejected, now)
+	assert.Equal(t, ErrTweetNotFound, err)
+
+	page, err := repo.GetByStatus(ctx, TweetStatusHeld, 10, 5)
+	assert.NoError(t, err)
+	assert.Empty(t, page)
+}
+
+func TestInMemoryTweetRepository_SetPreview(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	_, err := repo.Create(ctx, &Tweet{ID: "tweet-1", UserID: "user1-id", Handler: "user1", Content: Content{Text: "https://example.com", URLs: []string{"https://example.com/"}}})
+	assert.NoError(t, err)
+	before, err := repo.GetByID(ctx, "tweet-1")
+	assert.NoError(t, err)
+
+	preview := &LinkPreview{URL: "https://example.com/", Title: "Example"}
+	assert.NoError(t, repo.SetPreview(ctx, "tweet-1", preview))
+
+	after, err := repo.GetByID(ctx, "tweet-1")
+	assert.NoError(t, err)
+	assert.Equal(t, preview, after.Content.Preview)
+	assert.Equal(t, []string{"https://example.com/"}, after.Content.URLs)
+	// The tweets already returned are not modified
+	assert.Nil(t, before.Content.Preview)
+
+	assert.Equal(t, ErrTweetNotFound, repo.SetPreview(ctx, "missing", preview))
+}
+
+func TestInMemoryTweetRepository_ScheduledTweets(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	now := time.Now().UTC()
+	at := func(d time.Duration) *time.Time {
+		publishAt := now.Add(d)
+		return &publishAt
+	}
+
+	for _, tweet := range []*Tweet{
+		{ID: "later", UserID: "user1-id", Handler: "user1", Status: TweetStatusScheduled, PublishAt: at(time.Hour), CreatedAt: now},
+		{ID: "due-2", UserID: "user1-id", Handler: "user1", Status: TweetStatusScheduled, PublishAt: at(-time.Minute), CreatedAt: now},
+		{ID: "due-1", UserID: "user2-id", Handler: "user2", Status: TweetStatusScheduled, PublishAt: at(-time.Hour), CreatedAt: now},
+		{ID: "published", UserID: "user1-id", Handler: "user1", Status: TweetStatusPublished, CreatedAt: now},
+	} {
+		_, err := repo.Create(ctx, tweet)
+		assert.NoError(t, err)
+	}
+
+	// Scheduled tweets are not listed with the user tweets until published
+	userTweets, err := repo.GetByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Len(t, userTweets, 1)
+
+	scheduled, err := repo.GetScheduledByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"due-2", "later"}, []string{scheduled[0].ID, scheduled[1].ID})
+
+	due, err := repo.GetDueScheduled(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"due-1", "due-2"}, []string{due[0].ID, due[1].ID})
+	due, err = repo.GetDueScheduled(ctx, now, 1)
+	assert.NoError(t, err)
+	assert.Len(t, due, 1)
+
+	published, err := repo.PublishScheduled(ctx, "due-1", now)
+	assert.NoError(t, err)
+	assert.Equal(t, TweetStatusPublished, published.Status)
+	assert.Nil(t, published.PublishAt)
+	assert.Equal(t, now, published.CreatedAt)
+	// A tweet is published once
+	_, err = repo.PublishScheduled(ctx, "due-1", now)
+	assert.Equal(t, ErrTweetNotScheduled, err)
+
+	// A published tweet is pending to be posted until marked as posted
+	pending, err := repo.GetPostedPending(ctx, now.Add(time.Second), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"due-1"}, []string{pending[0].ID})
+	pending, err = repo.GetPostedPending(ctx, now, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.NoError(t, repo.MarkPosted(ctx, "due-1"))
+	pending, err = repo.GetPostedPending(ctx, now.Add(time.Second), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rescheduled, err := repo.Reschedule(ctx, "later", now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(2*time.Hour), *rescheduled.PublishAt)
+	_, err = repo.Reschedule(ctx, "published", now)
+	assert.Equal(t, ErrTweetNotScheduled, err)
+
+	assert.NoError(t, repo.CancelScheduled(ctx, "later"))
+	assert.Equal(t, ErrTweetNotFound, repo.CancelScheduled(ctx, "later"))
+	assert.Equal(t, ErrTweetNotScheduled, repo.CancelScheduled(ctx, "published"))
+}
+
+func TestInMemoryTweetRepository_Drafts(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	now := time.Now().UTC()
+
+	assert.NoError(t, repo.CreateDraft(ctx, &Draft{ID: "draft-1", UserID: "user1-id", Handler: "user1", Content: Content{Text: "First"}, UpdatedAt: now.Add(-time.Hour)}))
+	assert.NoError(t, repo.CreateDraft(ctx, &Draft{ID: "draft-2", UserID: "user1-id", Handler: "user1", Content: Content{Text: "Second"}, UpdatedAt: now}))
+	assert.NoError(t, repo.CreateDraft(ctx, &Draft{ID: "draft-3", UserID: "user2-id", Handler: "user2", Content: Content{Text: "Other"}, UpdatedAt: now}))
+
+	// Drafts are stored apart from the tweets
+	userTweets, err := repo.GetByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Empty(t, userTweets)
+
+	drafts, err := repo.GetDraftsByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"draft-2", "draft-1"}, []string{drafts[0].ID, drafts[1].ID})
+
+	assert.NoError(t, repo.UpdateDraft(ctx, &Draft{ID: "draft-1", Content: Content{Text: "Edited"}, UpdatedAt: now.Add(time.Minute)}))
+	draft, err := repo.GetDraft(ctx, "draft-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Edited", draft.Content.Text)
+	assert.Equal(t, "user1", draft.Handler)
+	assert.Equal(t, ErrDraftNotFound, repo.UpdateDraft(ctx, &Draft{ID: "missing"}))
+
+	assert.NoError(t, repo.DeleteDraft(ctx, "draft-1"))
+	assert.Equal(t, ErrDraftNotFound, repo.DeleteDraft(ctx, "draft-1"))
+	_, err = repo.GetDraft(ctx, "draft-1")
+	assert.Equal(t, ErrDraftNotFound, err)
+}
+
+func TestInMemoryTweetRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	_, err := repo.Create(ctx, &Tweet{ID: "123", UserID: "testuser-id", Handler: "testuser", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateVote(ctx, &PollVote{TweetID: "123", UserID: "voter-id", CreatedAt: time.Now()}))
+
+	require.NoError(t, repo.Delete(ctx, "123"))
+
+	// The tweet is hidden from all the queries
+	tweet, err := repo.GetByID(ctx, "123")
+	assert.NoError(t, err)
+	assert.Nil(t, tweet)
+	userTweets, err := repo.GetByUserID(ctx, "testuser-id")
+	assert.NoError(t, err)
+	assert.Empty(t, userTweets)
+	count, err := repo.CountByStatus(ctx, TweetStatusPublished)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	// The tombstone is kept until the retention period is over
+	require.Contains(t, repo.deleted, "123")
+	assert.True(t, repo.deleted["123"].DeletedAt.Valid)
+	purged, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+
+	purged, err = repo.PurgeDeleted(ctx, time.Now().Add(time.Hour), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Empty(t, repo.deleted)
+	assert.Empty(t, repo.votes)
+}
+
+func TestInMemoryTweetRepository_DeleteUserData(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	require.NoError(t, repo.SaveKnownUser(ctx, "deleted-id", "deleted"))
+	_, err := repo.Create(ctx, &Tweet{ID: "1", UserID: "deleted-id", Handler: "deleted", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &Tweet{ID: "2", UserID: "other-id", Handler: "other", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateVote(ctx, &PollVote{TweetID: "2", UserID: "deleted-id", CreatedAt: time.Now()}))
+	require.NoError(t, repo.CreateVote(ctx, &PollVote{TweetID: "2", UserID: "other-id", CreatedAt: time.Now()}))
+	require.NoError(t, repo.CreateDraft(ctx, &Draft{ID: "draft-1", UserID: "deleted-id", Handler: "deleted"}))
+
+	require.NoError(t, repo.DeleteUserData(ctx, "deleted-id"))
+	// Deleting the data again is a no-op
+	require.NoError(t, repo.DeleteUserData(ctx, "deleted-id"))
+	assert.NotContains(t, repo.knownUsers, "deleted-id")
+
+	tweets, err := repo.GetByUserID(ctx, "deleted-id")
+	assert.NoError(t, err)
+	assert.Empty(t, tweets)
+	assert.Contains(t, repo.deleted, "1")
+	drafts, err := repo.GetDraftsByUserID(ctx, "deleted-id")
+	assert.NoError(t, err)
+	assert.Empty(t, drafts)
+	vote, err := repo.GetVote(ctx, "2", "deleted-id")
+	assert.NoError(t, err)
+	assert.Nil(t, vote)
+
+	// The data of the other users is kept
+	tweet, err := repo.GetByID(ctx, "2")
+	assert.NoError(t, err)
+	assert.NotNil(t, tweet)
+	vote, err = repo.GetVote(ctx, "2", "other-id")
+	assert.NoError(t, err)
+	assert.NotNil(t, vote)
+}
+
+func TestInMemoryTweetRepository_KnownUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+
+	userID, err := repo.GetKnownUserID(ctx, "user1")
+	require.NoError(t, err)
+	assert.Empty(t, userID)
+
+	require.NoError(t, repo.SaveKnownUser(ctx, "user-id", "user1"))
+	userID, err = repo.GetKnownUserID(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "user-id", userID)
+
+	// Another user takes the handler, the user is no longer known by it
+	require.NoError(t, repo.SaveKnownUser(ctx, "other-id", "user1"))
+	userID, err = repo.GetKnownUserID(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, "other-id", userID)
+	assert.Equal(t, map[string]string{"other-id": "user1"}, repo.knownUsers)
+}
+
+func TestInMemoryTweetRepository_RenameUser(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	require.NoError(t, repo.SaveKnownUser(ctx, "user-id", "old"))
+	require.NoError(t, repo.SaveKnownUser(ctx, "other-id", "other"))
+	_, err := repo.Create(ctx, &Tweet{ID: "1", UserID: "user-id", Handler: "old", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	_, err = repo.Create(ctx, &Tweet{ID: "2", UserID: "other-id", Handler: "other", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateDraft(ctx, &Draft{ID: "draft-1", UserID: "user-id", Handler: "old"}))
+	require.NoError(t, repo.CreateMedia(ctx, &Media{ID: "media-1", UserID: "user-id", Handler: "old"}))
+	previous, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+	// Renaming the user again is a no-op
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+
+	userID, err := repo.GetKnownUserID(ctx, "new")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-id", userID)
+	userID, err = repo.GetKnownUserID(ctx, "old")
+	assert.NoError(t, err)
+	assert.Empty(t, userID)
+	tweets, err := repo.GetByUserID(ctx, "user-id")
+	assert.NoError(t, err)
+	require.Len(t, tweets, 1)
+	assert.Equal(t, "new", tweets[0].Handler)
+	// The tweets handed out before are not modified
+	assert.Equal(t, "old", previous.Handler)
+	drafts, err := repo.GetDraftsByUserID(ctx, "user-id")
+	assert.NoError(t, err)
+	require.Len(t, drafts, 1)
+	assert.Equal(t, "new", drafts[0].Handler)
+	media, err := repo.GetMedia(ctx, "media-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", media.Handler)
+
+	// The tweets of the other users are kept
+	tweet, err := repo.GetByID(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, "other", tweet.Handler)
+
+	// A later change applied first is kept
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "new", "newer"))
+	require.NoError(t, repo.RenameUser(ctx, "user-id", "old", "new"))
+	tweet, err = repo.GetByID(ctx, "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "newer", tweet.Handler)
+
+	// A user already known by their new handler is shown with it
+	require.NoError(t, repo.SaveKnownUser(ctx, "other-id", "other-new"))
+	require.NoError(t, repo.RenameUser(ctx, "other-id", "other", "other-new"))
+	tweet, err = repo.GetByID(ctx, "2")
+	assert.NoError(t, err)
+	assert.Equal(t, "other-new", tweet.Handler)
+	assert.Equal(t, map[string]string{"user-id": "newer", "other-id": "other-new"}, repo.knownUsers)
+}
+
+func TestInMemoryTweetRepository_DeactivatedUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+
+	deactivated, err := repo.IsUserDeactivated(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.False(t, deactivated)
+
+	deactivatedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	require.NoError(t, repo.SaveDeactivatedUser(ctx, "user1-id", deactivatedAt))
+	// Saving it again keeps the first deactivation time
+	require.NoError(t, repo.SaveDeactivatedUser(ctx, "user1-id", deactivatedAt.Add(time.Hour)))
+	assert.Equal(t, deactivatedAt, repo.deactivatedUsers["user1-id"])
+
+	deactivated, err = repo.IsUserDeactivated(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.True(t, deactivated)
+
+	require.NoError(t, repo.DeleteDeactivatedUser(ctx, "user1-id"))
+	deactivated, err = repo.IsUserDeactivated(ctx, "user1-id")
+	require.NoError(t, err)
+	assert.False(t, deactivated)
+}
@@ -0,0 +1,48 @@
+package tweets
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// TweetScheduler periodically publishes the scheduled tweets whose publish time has come
+type TweetScheduler struct {
+	service  Service
+	interval time.Duration
+}
+
+// NewTweetScheduler creates a new tweet scheduler
+func NewTweetScheduler(service Service, interval time.Duration) *TweetScheduler {
+	return &TweetScheduler{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run publishes the due tweets at startup and on every interval until the context is cancelled
+func (scheduler *TweetScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		scheduler.publish(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publish publishes the due tweets, a failure is only logged since they are retried on the next interval
+func (scheduler *TweetScheduler) publish(ctx context.Context) {
+	published, err := scheduler.service.PublishDueTweets(ctx)
+	if err != nil {
+		log.Printf("failed to publish scheduled tweets: %v", err)
+	}
+	if published > 0 {
+		log.Printf("published %d scheduled tweets", published)
+	}
+}
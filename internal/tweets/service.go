@@ -3,8 +3,14 @@ package tweets
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
 	"time"
 
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/google/uuid"
 )
 
@@ -16,28 +22,291 @@ type Service interface {
 	GetTweet(ctx context.Context, id string) (*Tweet, error)
 	GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error)
 	DeleteTweet(ctx context.Context, id string) error
+
+	// Review Queue
+	GetHeldTweets(ctx context.Context, limit, offset int) ([]*Tweet, error)
+	CountHeldTweets(ctx context.Context) (int64, error)
+	ApproveTweet(ctx context.Context, id string) (*Tweet, error)
+	RejectTweet(ctx context.Context, id string) (*Tweet, error)
+
+	// Polls
+	Vote(ctx context.Context, tweetID, handler string, option int) (*Poll, error)
+	GetPoll(ctx context.Context, tweet *Tweet, handler string) (*Poll, error)
+
+	// Scheduled Tweets
+	GetScheduledTweets(ctx context.Context, userID string) ([]*Tweet, error)
+	RescheduleTweet(ctx context.Context, id, userID string, publishAt time.Time) (*Tweet, error)
+	CancelScheduledTweet(ctx context.Context, id, userID string) error
+	PublishDueTweets(ctx context.Context) (int, error)
+
+	// User Deletions
+	DeleteUserData(ctx context.Context, handler string) error
+
+	// User Deactivations
+	HideUserTweets(ctx context.Context, handler string, deactivatedAt time.Time) error
+	RestoreUserTweets(ctx context.Context, handler string) error
+
+	// User Handle Changes
+	RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error
+}
+
+const (
+	// minScheduleDelay and maxScheduleDelay bound the time between scheduling a tweet and publishing it
+	minScheduleDelay = time.Minute
+	maxScheduleDelay = 365 * 24 * time.Hour
+	// scheduledBatchSize is the number of due scheduled tweets published per query
+	scheduledBatchSize = 100
+	// postedRetryDelay is how long after its publication a scheduled tweet that was not posted is posted again,
+	// so a scheduler does not post the tweets another one is posting
+	postedRetryDelay = time.Minute
+)
+
+// ErrTweetRejected is returned when the moderator rejects a tweet
+var ErrTweetRejected = errors.New("tweet rejected")
+
+// ErrContentTooLong is returned when the text of a tweet is longer than MaxContentLength
+var ErrContentTooLong = errors.New("tweet content is too long")
+
+// ErrInvalidSchedule is returned when scheduling a tweet too soon or too far in the future
+var ErrInvalidSchedule = errors.New("invalid publish time")
+
+// ErrInvalidMedia is returned when the media attached to a tweet do not exist, belong to another user or are too many
+var ErrInvalidMedia = errors.New("invalid tweet media")
+
+// ErrInvalidReply is returned when replying to a tweet that does not exist or is not visible
+var ErrInvalidReply = errors.New("invalid reply")
+
+// ContentLengthError is the ErrContentTooLong of a tweet, with the length of its text
+type ContentLengthError struct {
+	Length    int
+	MaxLength int
+}
+
+func (err *ContentLengthError) Error() string {
+	return fmt.Sprintf("%s: %d characters, the maximum is %d", ErrContentTooLong, err.Length, err.MaxLength)
+}
+
+func (err *ContentLengthError) Unwrap() error {
+	return ErrContentTooLong
 }
 
 type service struct {
 	repository Repository
+	publisher  queue.Publisher
+	moderator  Moderator
+	users      *userIDs
 }
 
-// NewService creates a new tweet service
-func NewService(repository Repository) Service {
+// NewService creates a new tweet service, every tweet is published if moderator is nil
+func NewService(repository Repository, publisher queue.Publisher, moderator Moderator, users UsersClient) Service {
 	return &service{
 		repository: repository,
+		publisher:  publisher,
+		moderator:  moderator,
+		users:      &userIDs{repository: repository, users: users},
 	}
 }
 
 func (service *service) CreateTweet(ctx context.Context, tweetToCreate *Tweet) (*Tweet, error) {
+	if tweetToCreate.Handler == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
 	if tweetToCreate.Content.Text == "" {
 		return nil, errors.New("tweet content cannot be empty")
 	}
+	if length := tweetToCreate.Content.Length(); length > MaxContentLength {
+		return nil, &ContentLengthError{Length: length, MaxLength: MaxContentLength}
+	}
+	author, err := service.users.resolve(ctx, tweetToCreate.Handler)
+	if err != nil {
+		return nil, err
+	}
+	tweetToCreate.UserID, tweetToCreate.Handler = author.ID, author.Handler
+	if err := service.attachMedia(ctx, tweetToCreate); err != nil {
+		return nil, err
+	}
+	if err := service.validateReply(ctx, tweetToCreate); err != nil {
+		return nil, err
+	}
+
+	// Scheduled tweets are posted at their publish time
+	postedAt := time.Now().UTC()
+	if tweetToCreate.PublishAt != nil {
+		if err := validatePublishTime(*tweetToCreate.PublishAt, postedAt); err != nil {
+			return nil, err
+		}
+		publishAt := tweetToCreate.PublishAt.UTC()
+		tweetToCreate.PublishAt, postedAt = &publishAt, publishAt
+	}
+	if poll := tweetToCreate.Content.Poll; poll != nil {
+		if len(tweetToCreate.Content.Media) > 0 {
+			return nil, fmt.Errorf("%w: a tweet cannot have both a poll and media", ErrInvalidPoll)
+		}
+		if err := validatePoll(poll, postedAt); err != nil {
+			return nil, err
+		}
+	}
+	// The preview card is fetched later by the PreviewWorker, once the tweet is posted
+	tweetToCreate.Content.URLs = tweetToCreate.Content.ExtractURLs()
+	tweetToCreate.Content.Preview = nil
+
+	result, err := service.moderate(ctx, tweetToCreate)
+	if err != nil {
+		return nil, err
+	}
+	if result.Action == ModerationReject {
+		return nil, fmt.Errorf("%w: %s", ErrTweetRejected, result.Reason)
+	}
 
 	tweetToCreate.ID = uuid.New().String()
 	tweetToCreate.CreatedAt = time.Now().UTC()
+	tweetToCreate.Status = TweetStatusPublished
+	if tweetToCreate.PublishAt != nil {
+		tweetToCreate.Status = TweetStatusScheduled
+	}
+	if result.Action == ModerationHold {
+		// Held tweets are published when approved, whatever their publish time
+		tweetToCreate.Status = TweetStatusHeld
+		tweetToCreate.ModerationReason = result.Reason
+		tweetToCreate.PublishAt = nil
+	}
+
+	createdTweet, err := service.repository.Create(ctx, tweetToCreate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Held tweets only fan out once they are approved, and scheduled tweets once they are published
+	if createdTweet.Status == TweetStatusPublished {
+		if err := service.publishTweetPosted(ctx, createdTweet); err != nil {
+			log.Print(err)
+		}
+	}
+
+	return createdTweet, nil
+}
+
+// validateReply checks that the tweet a new tweet replies to exists and is visible
+func (service *service) validateReply(ctx context.Context, tweet *Tweet) error {
+	if tweet.ReplyToID == nil {
+		return nil
+	}
+
+	parent, err := service.GetTweet(ctx, *tweet.ReplyToID)
+	if err != nil {
+		return err
+	}
+	if parent == nil || !parent.IsVisible() {
+		return fmt.Errorf("%w: tweet %s not found", ErrInvalidReply, *tweet.ReplyToID)
+	}
+	return nil
+}
+
+// validatePublishTime checks that a tweet is scheduled between minScheduleDelay and maxScheduleDelay later
+func validatePublishTime(publishAt, now time.Time) error {
+	delay := publishAt.Sub(now)
+	if delay < minScheduleDelay || delay > maxScheduleDelay {
+		return fmt.Errorf("%w: a tweet must be scheduled between %d minute and %d days ahead",
+			ErrInvalidSchedule, int(minScheduleDelay.Minutes()), int(maxScheduleDelay.Hours()/24))
+	}
+	return nil
+}
+
+// attachMedia replaces the media IDs attached to a tweet with the attachments of the media uploaded by its author
+func (service *service) attachMedia(ctx context.Context, tweet *Tweet) error {
+	if len(tweet.Content.Media) == 0 {
+		return nil
+	}
+	if len(tweet.Content.Media) > maxTweetMedia {
+		return fmt.Errorf("%w: a tweet can have up to %d media", ErrInvalidMedia, maxTweetMedia)
+	}
+
+	ids := make([]string, 0, len(tweet.Content.Media))
+	for _, attachment := range tweet.Content.Media {
+		if uuid.Validate(attachment.ID) != nil || slices.Contains(ids, attachment.ID) {
+			return fmt.Errorf("%w: media %q not found", ErrInvalidMedia, attachment.ID)
+		}
+		ids = append(ids, attachment.ID)
+	}
+
+	found, err := service.repository.GetMediaByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	uploads := map[string]*Media{}
+	for _, media := range found {
+		uploads[media.ID] = media
+	}
 
-	return service.repository.Create(ctx, tweetToCreate)
+	attachments := make([]Attachment, 0, len(ids))
+	for _, id := range ids {
+		media, exists := uploads[id]
+		// Other users' media are not found, so their IDs are not disclosed
+		if !exists || media.UserID != tweet.UserID {
+			return fmt.Errorf("%w: media %q not found", ErrInvalidMedia, id)
+		}
+		if media.Type != MediaTypeImage && len(ids) > 1 {
+			return fmt.Errorf("%w: a %s must be the only media of a tweet", ErrInvalidMedia, media.Type)
+		}
+		attachments = append(attachments, media.Attachment())
+	}
+	tweet.Content.Media = attachments
+	return nil
+}
+
+// moderate runs the moderator on a tweet, allowing every tweet if there is none
+func (service *service) moderate(ctx context.Context, tweet *Tweet) (ModerationResult, error) {
+	if service.moderator == nil {
+		return ModerationResult{Action: ModerationAllow}, nil
+	}
+	result, err := service.moderator.Moderate(ctx, tweet)
+	if err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to moderate tweet: %w", err)
+	}
+	return result, nil
+}
+
+// publishTweetPosted notifies the other services about a new tweet, with event IDs derived from the tweet
+func (service *service) publishTweetPosted(ctx context.Context, tweet *Tweet) error {
+	event := events.Event{
+		ID:          tweetEventID(events.TypeTweetCreated, tweet.ID),
+		EventType:   events.TypeTweetCreated,
+		Handler:     tweet.Handler,
+		TweetID:     tweet.ID,
+		Hashtags:    tweet.Content.Hashtags(),
+		Mentions:    tweet.Content.Mentions(),
+		ContentHash: tweet.Content.Fingerprint(),
+		Timestamp:   tweet.CreatedAt,
+	}
+	if err := service.publisher.Publish(ctx, events.TopicTweetPosted, tweet.Handler, event); err != nil {
+		return fmt.Errorf("failed to publish tweet posted event for tweet %s: %w", tweet.ID, err)
+	}
+
+	// A reply is an engagement on the tweet it replies to
+	if tweet.ReplyToID != nil {
+		return service.publishTweetEngaged(ctx, tweetEventID(events.TypeTweetReplied, tweet.ID), events.TypeTweetReplied, *tweet.ReplyToID, tweet.Handler, tweet.CreatedAt)
+	}
+	return nil
+}
+
+// tweetEventID derives the ID of an event of a type about a tweet
+func tweetEventID(eventType, tweetID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(eventType+":"+tweetID)).String()
+}
+
+// publishTweetEngaged notifies the analytics service about the engagement of a user on a tweet
+func (service *service) publishTweetEngaged(ctx context.Context, id, eventType, tweetID, handler string, engagedAt time.Time) error {
+	event := events.Event{
+		ID:        id,
+		EventType: eventType,
+		Handler:   handler,
+		TweetID:   tweetID,
+		Timestamp: engagedAt,
+	}
+	if err := service.publisher.Publish(ctx, events.TopicTweetEngaged, tweetID, event); err != nil {
+		return fmt.Errorf("failed to publish tweet engaged event for tweet %s: %w", tweetID, err)
+	}
+	return nil
 }
 
 func (service *service) GetTweet(ctx context.Context, id string) (*Tweet, error) {
@@ -45,7 +314,20 @@ func (service *service) GetTweet(ctx context.Context, id string) (*Tweet, error)
 		return nil, errors.New("tweet ID cannot be empty")
 	}
 
-	return service.repository.GetByID(ctx, id)
+	tweet, err := service.repository.GetByID(ctx, id)
+	if err != nil || tweet == nil {
+		return tweet, err
+	}
+
+	// The tweets of deactivated users are hidden as if they did not exist
+	deactivated, err := service.repository.IsUserDeactivated(ctx, tweet.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated {
+		return nil, nil
+	}
+	return tweet, nil
 }
 
 func (service *service) GetUserTweets(ctx context.Context, userID string) ([]*Tweet, error) {
@@ -53,7 +335,21 @@ func (service *service) GetUserTweets(ctx context.Context, userID string) ([]*Tw
 		return nil, errors.New("user ID cannot be empty")
 	}
 
-	return service.repository.GetByUserID(ctx, userID)
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return []*Tweet{}, nil
+	}
+	deactivated, err := service.repository.IsUserDeactivated(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deactivated {
+		return []*Tweet{}, nil
+	}
+	return service.repository.GetByUserID(ctx, id)
 }
 
 func (service *service) DeleteTweet(ctx context.Context, id string) error {
@@ -71,5 +367,322 @@ func (service *service) DeleteTweet(ctx context.Context, id string) error {
 		return errors.New("tweet not found")
 	}
 
-	return service.repository.Delete(ctx, id)
+	if err := service.repository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	service.publishTweetDeleted(ctx, foundTweet)
+	return nil
+}
+
+// publishTweetDeleted notifies the other services that a tweet was deleted, so they remove their copies of it
+func (service *service) publishTweetDeleted(ctx context.Context, tweet *Tweet) {
+	event := events.TweetDeleted{
+		TweetID:   tweet.ID,
+		Handler:   tweet.Handler,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := service.publisher.Publish(ctx, events.TopicTweetDeleted, tweet.Handler, event); err != nil {
+		log.Printf("failed to publish tweet deleted event for tweet %s: %v", tweet.ID, err)
+	}
+}
+
+func (service *service) GetHeldTweets(ctx context.Context, limit, offset int) ([]*Tweet, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return service.repository.GetByStatus(ctx, TweetStatusHeld, limit, offset)
+}
+
+func (service *service) CountHeldTweets(ctx context.Context) (int64, error) {
+	return service.repository.CountByStatus(ctx, TweetStatusHeld)
+}
+
+// ApproveTweet publishes a tweet held for review, fanning it out
+func (service *service) ApproveTweet(ctx context.Context, id string) (*Tweet, error) {
+	if id == "" {
+		return nil, errors.New("tweet ID cannot be empty")
+	}
+
+	approvedTweet, err := service.repository.Review(ctx, id, TweetStatusPublished, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.publishTweetPosted(ctx, approvedTweet); err != nil {
+		log.Print(err)
+	}
+
+	return approvedTweet, nil
+}
+
+// RejectTweet rejects a tweet held for review, it stays visible to its author only
+func (service *service) RejectTweet(ctx context.Context, id string) (*Tweet, error) {
+	if id == "" {
+		return nil, errors.New("tweet ID cannot be empty")
+	}
+
+	return service.repository.Review(ctx, id, TweetStatusRejected, time.Now().UTC())
+}
+
+// Vote casts the only vote of a user on an open poll of a tweet, and returns the poll with the updated tallies
+func (service *service) Vote(ctx context.Context, tweetID, handler string, option int) (*Poll, error) {
+	if tweetID == "" {
+		return nil, errors.New("tweet ID cannot be empty")
+	}
+	if handler == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	tweet, err := service.repository.GetByID(ctx, tweetID)
+	if err != nil {
+		return nil, err
+	}
+	if tweet == nil || !tweet.IsVisible() {
+		return nil, ErrTweetNotFound
+	}
+	poll := tweet.Content.Poll
+	if poll == nil {
+		return nil, ErrPollNotFound
+	}
+	if option < 0 || option >= len(poll.Options) {
+		return nil, fmt.Errorf("%w: option %d does not exist", ErrInvalidPoll, option)
+	}
+
+	// The vote is timestamped before checking the closing time, so every vote accepted counts in the final results
+	now := time.Now().UTC()
+	if poll.IsClosed(now) {
+		return nil, ErrPollClosed
+	}
+	voter, err := service.users.resolve(ctx, handler)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.repository.CreateVote(ctx, &PollVote{
+		TweetID:   tweet.ID,
+		UserID:    voter.ID,
+		Option:    option,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return service.GetPoll(ctx, tweet, handler)
+}
+
+// GetPoll returns a copy of the poll of a tweet with its frozen tallies and the vote of a user, nil without a poll
+func (service *service) GetPoll(ctx context.Context, tweet *Tweet, handler string) (*Poll, error) {
+	if tweet.Content.Poll == nil {
+		return nil, nil
+	}
+
+	tallies, err := service.repository.CountVotes(ctx, tweet.ID, tweet.Content.Poll.ClosesAt)
+	if err != nil {
+		return nil, err
+	}
+	poll := &Poll{
+		Options:  make([]PollOption, len(tweet.Content.Poll.Options)),
+		ClosesAt: tweet.Content.Poll.ClosesAt,
+		Closed:   tweet.Content.Poll.IsClosed(time.Now()),
+	}
+	for i, option := range tweet.Content.Poll.Options {
+		poll.Options[i] = PollOption{Text: option.Text, Votes: tallies[i]}
+		poll.TotalVotes += tallies[i]
+	}
+
+	if handler == "" {
+		return poll, nil
+	}
+	userID, err := service.users.known(ctx, handler)
+	if err != nil || userID == "" {
+		return poll, err
+	}
+	vote, err := service.repository.GetVote(ctx, tweet.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if vote != nil {
+		poll.Vote = &vote.Option
+	}
+	return poll, nil
+}
+
+// GetScheduledTweets retrieves the tweets of a user waiting for their publish time, the soonest first
+func (service *service) GetScheduledTweets(ctx context.Context, userID string) ([]*Tweet, error) {
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	id, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return []*Tweet{}, nil
+	}
+	return service.repository.GetScheduledByUserID(ctx, id)
+}
+
+// RescheduleTweet changes the publish time of a scheduled tweet of a user, validating its poll against it
+func (service *service) RescheduleTweet(ctx context.Context, id, userID string, publishAt time.Time) (*Tweet, error) {
+	tweet, err := service.getScheduledTweet(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePublishTime(publishAt, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	if tweet.Content.Poll != nil {
+		if err := validatePollClosingTime(tweet.Content.Poll, publishAt); err != nil {
+			return nil, err
+		}
+	}
+
+	return service.repository.Reschedule(ctx, id, publishAt.UTC())
+}
+
+// CancelScheduledTweet deletes a scheduled tweet of a user before it is published
+func (service *service) CancelScheduledTweet(ctx context.Context, id, userID string) error {
+	if _, err := service.getScheduledTweet(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return service.repository.CancelScheduled(ctx, id)
+}
+
+// getScheduledTweet retrieves a scheduled tweet of a user, the ones of other users are not found
+func (service *service) getScheduledTweet(ctx context.Context, id, userID string) (*Tweet, error) {
+	if id == "" {
+		return nil, errors.New("tweet ID cannot be empty")
+	}
+	if userID == "" {
+		return nil, errors.New("user ID cannot be empty")
+	}
+
+	author, err := service.users.known(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	tweet, err := service.repository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tweet == nil || tweet.UserID != author {
+		return nil, ErrTweetNotFound
+	}
+	if tweet.Status != TweetStatusScheduled {
+		return nil, ErrTweetNotScheduled
+	}
+	return tweet, nil
+}
+
+// PublishDueTweets publishes the due scheduled tweets, posting the pending ones again, and returns how many were published
+func (service *service) PublishDueTweets(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		due, err := service.repository.GetDueScheduled(ctx, time.Now().UTC(), scheduledBatchSize)
+		if err != nil {
+			return published, err
+		}
+
+		for _, tweet := range due {
+			publishedTweet, err := service.repository.PublishScheduled(ctx, tweet.ID, time.Now().UTC())
+			if errors.Is(err, ErrTweetNotScheduled) || errors.Is(err, ErrTweetNotFound) {
+				continue // Published by another scheduler, or cancelled meanwhile
+			}
+			if err != nil {
+				return published, err
+			}
+			published++
+			if err := service.postScheduled(ctx, publishedTweet); err != nil {
+				log.Printf("scheduled tweet %s will be posted again: %v", publishedTweet.ID, err)
+			}
+		}
+
+		if len(due) < scheduledBatchSize {
+			break
+		}
+	}
+
+	return published, service.postPending(ctx)
+}
+
+// postScheduled posts a published scheduled tweet, marking it as posted once its events are published
+func (service *service) postScheduled(ctx context.Context, tweet *Tweet) error {
+	if err := service.publishTweetPosted(ctx, tweet); err != nil {
+		return err
+	}
+	return service.repository.MarkPosted(ctx, tweet.ID)
+}
+
+// postPending posts again the scheduled tweets published before the retry delay that are still pending to be posted
+func (service *service) postPending(ctx context.Context) error {
+	for {
+		pending, err := service.repository.GetPostedPending(ctx, time.Now().UTC().Add(-postedRetryDelay), scheduledBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, tweet := range pending {
+			if err := service.postScheduled(ctx, tweet); err != nil {
+				return err
+			}
+		}
+
+		if len(pending) < scheduledBatchSize {
+			return nil
+		}
+	}
+}
+
+// DeleteUserData deletes the tweets, drafts and votes of a deleted user, leaving tombstones of the tweets
+func (service *service) DeleteUserData(ctx context.Context, handler string) error {
+	if handler == "" {
+		return errors.New("user ID cannot be empty")
+	}
+
+	userID, err := service.users.known(ctx, handler)
+	if err != nil || userID == "" {
+		return err
+	}
+	return service.repository.DeleteUserData(ctx, userID)
+}
+
+// HideUserTweets hides the tweets of a user that deactivated their account, they are kept to be restored
+func (service *service) HideUserTweets(ctx context.Context, handler string, deactivatedAt time.Time) error {
+	if handler == "" {
+		return errors.New("user ID cannot be empty")
+	}
+
+	userID, err := service.users.known(ctx, handler)
+	if err != nil || userID == "" {
+		return err
+	}
+	return service.repository.SaveDeactivatedUser(ctx, userID, deactivatedAt)
+}
+
+// RestoreUserTweets shows again the tweets of a user that reactivated their account
+func (service *service) RestoreUserTweets(ctx context.Context, handler string) error {
+	if handler == "" {
+		return errors.New("user ID cannot be empty")
+	}
+
+	userID, err := service.users.known(ctx, handler)
+	if err != nil || userID == "" {
+		return err
+	}
+	return service.repository.DeleteDeactivatedUser(ctx, userID)
+}
+
+// RenameUser shows the data of a user that changed their handle with the new one
+func (service *service) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	if userID == "" || oldHandler == "" || newHandler == "" {
+		return errors.New("user ID cannot be empty")
+	}
+
+	return service.repository.RenameUser(ctx, userID, oldHandler, newHandler)
 }
@@ -12,6 +12,7 @@ package tweets
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,19 +41,63 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// ApproveTweet mocks base method.
+func (m *MockService) ApproveTweet(ctx context.Context, id string) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveTweet", ctx, id)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveTweet indicates an expected call of ApproveTweet.
+func (mr *MockServiceMockRecorder) ApproveTweet(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveTweet", reflect.TypeOf((*MockService)(nil).ApproveTweet), ctx, id)
+}
+
+// CancelScheduledTweet mocks base method.
+func (m *MockService) CancelScheduledTweet(ctx context.Context, id, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledTweet", ctx, id, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelScheduledTweet indicates an expected call of CancelScheduledTweet.
+func (mr *MockServiceMockRecorder) CancelScheduledTweet(ctx, id, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledTweet", reflect.TypeOf((*MockService)(nil).CancelScheduledTweet), ctx, id, userID)
+}
+
+// CountHeldTweets mocks base method.
+func (m *MockService) CountHeldTweets(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountHeldTweets", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountHeldTweets indicates an expected call of CountHeldTweets.
+func (mr *MockServiceMockRecorder) CountHeldTweets(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountHeldTweets", reflect.TypeOf((*MockService)(nil).CountHeldTweets), ctx)
+}
+
 // CreateTweet mocks base method.
-func (m *MockService) CreateTweet(ctx context.Context, req *Tweet) (*Tweet, error) {
+func (m *MockService) CreateTweet(ctx context.Context, tweetToCreate *Tweet) (*Tweet, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTweet", ctx, req)
+	ret := m.ctrl.Call(m, "CreateTweet", ctx, tweetToCreate)
 	ret0, _ := ret[0].(*Tweet)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateTweet indicates an expected call of CreateTweet.
-func (mr *MockServiceMockRecorder) CreateTweet(ctx, req any) *gomock.Call {
+func (mr *MockServiceMockRecorder) CreateTweet(ctx, tweetToCreate any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTweet", reflect.TypeOf((*MockService)(nil).CreateTweet), ctx, req)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTweet", reflect.TypeOf((*MockService)(nil).CreateTweet), ctx, tweetToCreate)
 }
 
 // DeleteTweet mocks base method.
@@ -69,6 +114,65 @@ func (mr *MockServiceMockRecorder) DeleteTweet(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTweet", reflect.TypeOf((*MockService)(nil).DeleteTweet), ctx, id)
 }
 
+// DeleteUserData mocks base method.
+func (m *MockService) DeleteUserData(ctx context.Context, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserData", ctx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserData indicates an expected call of DeleteUserData.
+func (mr *MockServiceMockRecorder) DeleteUserData(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserData", reflect.TypeOf((*MockService)(nil).DeleteUserData), ctx, handler)
+}
+
+// GetHeldTweets mocks base method.
+func (m *MockService) GetHeldTweets(ctx context.Context, limit, offset int) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHeldTweets", ctx, limit, offset)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHeldTweets indicates an expected call of GetHeldTweets.
+func (mr *MockServiceMockRecorder) GetHeldTweets(ctx, limit, offset any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHeldTweets", reflect.TypeOf((*MockService)(nil).GetHeldTweets), ctx, limit, offset)
+}
+
+// GetPoll mocks base method.
+func (m *MockService) GetPoll(ctx context.Context, tweet *Tweet, handler string) (*Poll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPoll", ctx, tweet, handler)
+	ret0, _ := ret[0].(*Poll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPoll indicates an expected call of GetPoll.
+func (mr *MockServiceMockRecorder) GetPoll(ctx, tweet, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPoll", reflect.TypeOf((*MockService)(nil).GetPoll), ctx, tweet, handler)
+}
+
+// GetScheduledTweets mocks base method.
+func (m *MockService) GetScheduledTweets(ctx context.Context, userID string) ([]*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledTweets", ctx, userID)
+	ret0, _ := ret[0].([]*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledTweets indicates an expected call of GetScheduledTweets.
+func (mr *MockServiceMockRecorder) GetScheduledTweets(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledTweets", reflect.TypeOf((*MockService)(nil).GetScheduledTweets), ctx, userID)
+}
+
 // GetTweet mocks base method.
 func (m *MockService) GetTweet(ctx context.Context, id string) (*Tweet, error) {
 	m.ctrl.T.Helper()
@@ -98,3 +202,105 @@ func (mr *MockServiceMockRecorder) GetUserTweets(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTweets", reflect.TypeOf((*MockService)(nil).GetUserTweets), ctx, userID)
 }
+
+// HideUserTweets mocks base method.
+func (m *MockService) HideUserTweets(ctx context.Context, handler string, deactivatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HideUserTweets", ctx, handler, deactivatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HideUserTweets indicates an expected call of HideUserTweets.
+func (mr *MockServiceMockRecorder) HideUserTweets(ctx, handler, deactivatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HideUserTweets", reflect.TypeOf((*MockService)(nil).HideUserTweets), ctx, handler, deactivatedAt)
+}
+
+// PublishDueTweets mocks base method.
+func (m *MockService) PublishDueTweets(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDueTweets", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishDueTweets indicates an expected call of PublishDueTweets.
+func (mr *MockServiceMockRecorder) PublishDueTweets(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDueTweets", reflect.TypeOf((*MockService)(nil).PublishDueTweets), ctx)
+}
+
+// RejectTweet mocks base method.
+func (m *MockService) RejectTweet(ctx context.Context, id string) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectTweet", ctx, id)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectTweet indicates an expected call of RejectTweet.
+func (mr *MockServiceMockRecorder) RejectTweet(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectTweet", reflect.TypeOf((*MockService)(nil).RejectTweet), ctx, id)
+}
+
+// RenameUser mocks base method.
+func (m *MockService) RenameUser(ctx context.Context, userID, oldHandler, newHandler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameUser", ctx, userID, oldHandler, newHandler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameUser indicates an expected call of RenameUser.
+func (mr *MockServiceMockRecorder) RenameUser(ctx, userID, oldHandler, newHandler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameUser", reflect.TypeOf((*MockService)(nil).RenameUser), ctx, userID, oldHandler, newHandler)
+}
+
+// RescheduleTweet mocks base method.
+func (m *MockService) RescheduleTweet(ctx context.Context, id, userID string, publishAt time.Time) (*Tweet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RescheduleTweet", ctx, id, userID, publishAt)
+	ret0, _ := ret[0].(*Tweet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RescheduleTweet indicates an expected call of RescheduleTweet.
+func (mr *MockServiceMockRecorder) RescheduleTweet(ctx, id, userID, publishAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RescheduleTweet", reflect.TypeOf((*MockService)(nil).RescheduleTweet), ctx, id, userID, publishAt)
+}
+
+// RestoreUserTweets mocks base method.
+func (m *MockService) RestoreUserTweets(ctx context.Context, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserTweets", ctx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUserTweets indicates an expected call of RestoreUserTweets.
+func (mr *MockServiceMockRecorder) RestoreUserTweets(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserTweets", reflect.TypeOf((*MockService)(nil).RestoreUserTweets), ctx, handler)
+}
+
+// Vote mocks base method.
+func (m *MockService) Vote(ctx context.Context, tweetID, handler string, option int) (*Poll, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Vote", ctx, tweetID, handler, option)
+	ret0, _ := ret[0].(*Poll)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Vote indicates an expected call of Vote.
+func (mr *MockServiceMockRecorder) Vote(ctx, tweetID, handler, option any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Vote", reflect.TypeOf((*MockService)(nil).Vote), ctx, tweetID, handler, option)
+}
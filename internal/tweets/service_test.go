@@ -3,20 +3,41 @@ package tweets
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
+// expectKnownUsers makes the users known by their handlers, each with the ID "<handler>-id", except the "unknown" user
+func expectKnownUsers(mockRepo *MockRepository) {
+	mockRepo.EXPECT().GetKnownUserID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, handler string) (string, error) {
+			if handler == "unknown" {
+				return "", nil
+			}
+			return handler + "-id", nil
+		}).
+		AnyTimes()
+}
+
 func TestTweetService_CreateTweet(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	ctx := context.Background()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, nil, nil)
+	expectKnownUsers(mockRepo)
 
 	type args struct {
 		req *Tweet
@@ -46,12 +67,24 @@ func TestTweetService_CreateTweet(t *testing.T) {
 				mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
 					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
 						assert.NotEmpty(t, tweet.ID)
+						assert.Equal(t, "testuser-id", tweet.UserID)
 						assert.Equal(t, "testuser", tweet.Handler)
 						assert.Equal(t, "Hello, world!", tweet.Content.Text)
 						assert.False(t, tweet.CreatedAt.IsZero())
 						return tweet, nil
 					}).
 					Times(1)
+				mockPublisher.EXPECT().Publish(gomock.Any(), events.TopicTweetPosted, "testuser", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, _ string, payload any) error {
+						event := payload.(events.Event)
+						assert.NotEmpty(t, event.ID)
+						assert.NotEmpty(t, event.TweetID)
+						assert.Equal(t, events.TypeTweetCreated, event.EventType)
+						assert.Equal(t, "testuser", event.Handler)
+						assert.Equal(t, Content{Text: "Hello, world!"}.Fingerprint(), event.ContentHash)
+						return nil
+					}).
+					Times(1)
 			},
 			want: want{
 				tweet: &Tweet{
@@ -62,6 +95,94 @@ func TestTweetService_CreateTweet(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "publishing error does not fail the creation",
+			args: args{
+				req: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: "Hello, world!"},
+				},
+			},
+			expectations: func() {
+				mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
+						return tweet, nil
+					}).
+					Times(1)
+				mockPublisher.EXPECT().Publish(gomock.Any(), events.TopicTweetPosted, "testuser", gomock.Any()).
+					Return(errors.New("queue error")).
+					Times(1)
+			},
+			want: want{
+				tweet: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: "Hello, world!"},
+				},
+				err: nil,
+			},
+			wantErr: false,
+		},
+		{
+			name: "repository error",
+			args: args{
+				req: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: "Hello, world!"},
+				},
+			},
+			expectations: func() {
+				mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("database error")).
+					Times(1)
+			},
+			want: want{
+				tweet: nil,
+				err:   errors.New("database error"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "content too long",
+			args: args{
+				req: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: strings.Repeat("é", 140) + strings.Repeat("👍", 71)},
+				},
+			},
+			expectations: func() {},
+			want: want{
+				tweet: nil,
+				err:   &ContentLengthError{Length: 282, MaxLength: 280},
+			},
+			wantErr: true,
+		},
+		{
+			name: "content at the maximum length",
+			args: args{
+				req: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: strings.Repeat("e\u0301", 140) + strings.Repeat("👍", 70)},
+				},
+			},
+			expectations: func() {
+				mockRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
+						return tweet, nil
+					}).
+					Times(1)
+				mockPublisher.EXPECT().Publish(gomock.Any(), events.TopicTweetPosted, "testuser", gomock.Any()).
+					Return(nil).
+					Times(1)
+			},
+			want: want{
+				tweet: &Tweet{
+					Handler: "testuser",
+					Content: Content{Text: strings.Repeat("e\u0301", 140) + strings.Repeat("👍", 70)},
+				},
+				err: nil,
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty content",
 			args: args{
@@ -102,7 +223,8 @@ func TestTweetService_GetTweet(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
 
 	type want struct {
 		tweet *Tweet
@@ -123,15 +245,21 @@ func TestTweetService_GetTweet(t *testing.T) {
 					GetByID(ctx, "123").
 					Return(&Tweet{
 						ID:        "123",
+						UserID:    "testuser-id",
 						Handler:   "testuser",
 						Content:   Content{Text: "Hello"},
 						CreatedAt: mockTime(),
 					}, nil).
 					Times(1)
+				mockRepo.EXPECT().
+					IsUserDeactivated(ctx, "testuser-id").
+					Return(false, nil).
+					Times(1)
 			},
 			want: want{
 				tweet: &Tweet{
 					ID:        "123",
+					UserID:    "testuser-id",
 					Handler:   "testuser",
 					Content:   Content{Text: "Hello"},
 					CreatedAt: mockTime(),
@@ -139,6 +267,30 @@ func TestTweetService_GetTweet(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name:    "tweet of a deactivated user",
+			tweetID: "123",
+			expectations: func() {
+				mockRepo.EXPECT().
+					GetByID(ctx, "123").
+					Return(&Tweet{
+						ID:        "123",
+						UserID:    "testuser-id",
+						Handler:   "testuser",
+						Content:   Content{Text: "Hello"},
+						CreatedAt: mockTime(),
+					}, nil).
+					Times(1)
+				mockRepo.EXPECT().
+					IsUserDeactivated(ctx, "testuser-id").
+					Return(true, nil).
+					Times(1)
+			},
+			want: want{
+				tweet: nil,
+				err:   nil,
+			},
+		},
 		{
 			name:         "empty tweet id",
 			tweetID:      "",
@@ -158,6 +310,9 @@ func TestTweetService_GetTweet(t *testing.T) {
 			if tc.want.err != nil {
 				assert.Error(t, err)
 				assert.Equal(t, err, tc.want.err)
+			} else if tc.want.tweet == nil {
+				assert.NoError(t, err)
+				assert.Nil(t, tweet)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tc.want.tweet.Handler, tweet.Handler)
@@ -173,7 +328,8 @@ func TestTweetService_GetUserTweets(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
 
 	type want struct {
 		tweets []*Tweet
@@ -191,7 +347,11 @@ func TestTweetService_GetUserTweets(t *testing.T) {
 			userID: "user1",
 			expectations: func() {
 				mockRepo.EXPECT().
-					GetByUserID(ctx, "user1").
+					IsUserDeactivated(ctx, "user1-id").
+					Return(false, nil).
+					Times(1)
+				mockRepo.EXPECT().
+					GetByUserID(ctx, "user1-id").
 					Return([]*Tweet{{
 						ID:        "1",
 						Handler:   "user1",
@@ -210,6 +370,29 @@ func TestTweetService_GetUserTweets(t *testing.T) {
 				err: nil,
 			},
 		},
+		{
+			name:   "deactivated user",
+			userID: "user1",
+			expectations: func() {
+				mockRepo.EXPECT().
+					IsUserDeactivated(ctx, "user1-id").
+					Return(true, nil).
+					Times(1)
+			},
+			want: want{
+				tweets: []*Tweet{},
+				err:    nil,
+			},
+		},
+		{
+			name:         "unknown user",
+			userID:       "unknown",
+			expectations: func() {},
+			want: want{
+				tweets: []*Tweet{},
+				err:    nil,
+			},
+		},
 		{
 			name:         "empty user id",
 			userID:       "",
@@ -238,7 +421,8 @@ func TestTweetService_DeleteTweet(t *testing.T) {
 
 	ctx := context.Background()
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
 
 	type want struct {
 		err error
@@ -300,6 +484,725 @@ func TestTweetService_DeleteTweet(t *testing.T) {
 	}
 }
 
+func TestTweetService_DeleteTweetPublishesEvent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(repository, mockPublisher, nil, nil)
+
+	_, err := repository.Create(ctx, &Tweet{ID: "123", Handler: "testuser", Status: TweetStatusPublished})
+	require.NoError(t, err)
+
+	mockPublisher.EXPECT().Publish(ctx, events.TopicTweetDeleted, "testuser", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, event any) error {
+			deleted := event.(events.TweetDeleted)
+			assert.Equal(t, "123", deleted.TweetID)
+			assert.Equal(t, "testuser", deleted.Handler)
+			assert.WithinDuration(t, time.Now(), deleted.Timestamp, time.Minute)
+			return errors.New("queue error") // Only logged, the tweet is deleted
+		})
+
+	assert.NoError(t, service.DeleteTweet(ctx, "123"))
+
+	tweet, err := service.GetTweet(ctx, "123")
+	assert.NoError(t, err)
+	assert.Nil(t, tweet)
+}
+
+func TestTweetService_UserDeactivation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryTweetRepository()
+	service := NewService(repo, queue.NewInMemoryQueue(), nil, nil)
+	require.NoError(t, repo.SaveKnownUser(ctx, "testuser-id", "testuser"))
+	_, err := repo.Create(ctx, &Tweet{ID: "123", UserID: "testuser-id", Handler: "testuser", Status: TweetStatusPublished, CreatedAt: mockTime()})
+	require.NoError(t, err)
+
+	require.NoError(t, service.HideUserTweets(ctx, "testuser", mockTime()))
+
+	// The tweets of the deactivated user are hidden
+	tweet, err := service.GetTweet(ctx, "123")
+	assert.NoError(t, err)
+	assert.Nil(t, tweet)
+	userTweets, err := service.GetUserTweets(ctx, "testuser")
+	assert.NoError(t, err)
+	assert.Empty(t, userTweets)
+
+	require.NoError(t, service.RestoreUserTweets(ctx, "testuser"))
+
+	// Reactivating the user restores their tweets
+	tweet, err = service.GetTweet(ctx, "123")
+	assert.NoError(t, err)
+	assert.NotNil(t, tweet)
+	userTweets, err = service.GetUserTweets(ctx, "testuser")
+	assert.NoError(t, err)
+	assert.Len(t, userTweets, 1)
+
+	assert.EqualError(t, service.HideUserTweets(ctx, "", mockTime()), "user ID cannot be empty")
+	assert.EqualError(t, service.RestoreUserTweets(ctx, ""), "user ID cannot be empty")
+}
+
+func TestTweetService_CreateTweetModeration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	mockModerator := NewMockModerator(ctrl)
+	service := NewService(mockRepo, mockPublisher, mockModerator, nil)
+	expectKnownUsers(mockRepo)
+
+	type want struct {
+		status string
+		reason string
+		err    error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "allowed tweet is published",
+			expectations: func() {
+				mockModerator.EXPECT().Moderate(ctx, gomock.Any()).Return(ModerationResult{Action: ModerationAllow}, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
+						return tweet, nil
+					})
+				mockPublisher.EXPECT().Publish(ctx, events.TopicTweetPosted, "testuser", gomock.Any()).Return(nil)
+			},
+			want: want{
+				status: TweetStatusPublished,
+			},
+		},
+		{
+			name: "held tweet is stored but not published",
+			expectations: func() {
+				mockModerator.EXPECT().Moderate(ctx, gomock.Any()).Return(ModerationResult{Action: ModerationHold, Reason: "suspicious link"}, nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
+						return tweet, nil
+					})
+			},
+			want: want{
+				status: TweetStatusHeld,
+				reason: "suspicious link",
+			},
+		},
+		{
+			name: "rejected tweet is not stored",
+			expectations: func() {
+				mockModerator.EXPECT().Moderate(ctx, gomock.Any()).Return(ModerationResult{Action: ModerationReject, Reason: "blocked keyword"}, nil)
+			},
+			want: want{
+				err: fmt.Errorf("%w: blocked keyword", ErrTweetRejected),
+			},
+		},
+		{
+			name: "moderator error",
+			expectations: func() {
+				mockModerator.EXPECT().Moderate(ctx, gomock.Any()).Return(ModerationResult{}, errors.New("moderation service unavailable"))
+			},
+			want: want{
+				err: fmt.Errorf("failed to moderate tweet: %w", errors.New("moderation service unavailable")),
+			},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			tweet, err := service.CreateTweet(ctx, &Tweet{Handler: "testuser", Content: Content{Text: "Hello, world!"}})
+
+			if tc.want.err != nil {
+				assert.EqualError(t, err, tc.want.err.Error())
+				assert.Nil(t, tweet)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want.status, tweet.Status)
+				assert.Equal(t, tc.want.reason, tweet.ModerationReason)
+			}
+		})
+	}
+}
+
+func TestTweetService_CreateTweetMedia(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil)
+	expectKnownUsers(mockRepo)
+
+	const (
+		imageID = "7f1b6a52-1f1e-4c1a-9d43-5a2f3d1c8e01"
+		otherID = "7f1b6a52-1f1e-4c1a-9d43-5a2f3d1c8e02"
+		videoID = "7f1b6a52-1f1e-4c1a-9d43-5a2f3d1c8e03"
+	)
+	uploads := []*Media{
+		{ID: imageID, UserID: "testuser-id", Handler: "testuser", Type: MediaTypeImage, MIMEType: "image/png", Width: 640, Height: 480, ThumbnailKey: "media/1_thumbnail"},
+		{ID: otherID, UserID: "otheruser-id", Handler: "otheruser", Type: MediaTypeImage, MIMEType: "image/png"},
+		{ID: videoID, UserID: "testuser-id", Handler: "testuser", Type: MediaTypeVideo, MIMEType: "video/mp4"},
+	}
+
+	tt := []struct {
+		name         string
+		mediaIDs     []string
+		expectations func()
+		want         []Attachment
+		wantErr      string
+	}{
+		{
+			name:     "media of the user are attached",
+			mediaIDs: []string{imageID},
+			expectations: func() {
+				mockRepo.EXPECT().GetMediaByIDs(ctx, []string{imageID}).Return(uploads[:1], nil)
+				mockRepo.EXPECT().Create(ctx, gomock.Any()).
+					DoAndReturn(func(_ context.Context, tweet *Tweet) (*Tweet, error) {
+						return tweet, nil
+					})
+			},
+			want: []Attachment{{ID: imageID, Type: MediaTypeImage, MIMEType: "image/png", Width: 640, Height: 480, HasThumbnail: true}},
+		},
+		{
+			name:     "media of another user",
+			mediaIDs: []string{imageID, otherID},
+			expectations: func() {
+				mockRepo.EXPECT().GetMediaByIDs(ctx, []string{imageID, otherID}).Return(uploads[:2], nil)
+			},
+			wantErr: `invalid tweet media: media "` + otherID + `" not found`,
+		},
+		{
+			name:         "invalid media ID",
+			mediaIDs:     []string{"invalid"},
+			expectations: func() {},
+			wantErr:      `invalid tweet media: media "invalid" not found`,
+		},
+		{
+			name:     "video with other media",
+			mediaIDs: []string{imageID, videoID},
+			expectations: func() {
+				mockRepo.EXPECT().GetMediaByIDs(ctx, []string{imageID, videoID}).Return([]*Media{uploads[2], uploads[0]}, nil)
+			},
+			wantErr: "invalid tweet media: a video must be the only media of a tweet",
+		},
+		{
+			name:         "too many media",
+			mediaIDs:     []string{imageID, imageID, imageID, imageID, imageID},
+			expectations: func() {},
+			wantErr:      "invalid tweet media: a tweet can have up to 4 media",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			tweet := &Tweet{Handler: "testuser", Content: Content{Text: "Hello, world!"}}
+			for _, id := range tc.mediaIDs {
+				tweet.Content.Media = append(tweet.Content.Media, Attachment{ID: id})
+			}
+			created, err := service.CreateTweet(ctx, tweet)
+
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				assert.ErrorIs(t, err, ErrInvalidMedia)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.want, created.Content.Media)
+			}
+		})
+	}
+}
+
+func TestTweetService_ReviewTweet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, nil, nil)
+	expectKnownUsers(mockRepo)
+
+	reviewedTweet := func(status string) *Tweet {
+		return &Tweet{ID: "123", Handler: "testuser", Content: Content{Text: "Hello"}, Status: status, CreatedAt: mockTime()}
+	}
+
+	t.Run("approved tweet is published", func(t *testing.T) {
+		mockRepo.EXPECT().Review(ctx, "123", TweetStatusPublished, gomock.Any()).Return(reviewedTweet(TweetStatusPublished), nil)
+		mockPublisher.EXPECT().Publish(ctx, events.TopicTweetPosted, "testuser", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, _ string, payload any) error {
+				event := payload.(events.Event)
+				assert.Equal(t, "123", event.TweetID)
+				assert.Equal(t, mockTime(), event.Timestamp)
+				return nil
+			})
+
+		tweet, err := service.ApproveTweet(ctx, "123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, reviewedTweet(TweetStatusPublished), tweet)
+	})
+
+	t.Run("rejected tweet is not published", func(t *testing.T) {
+		mockRepo.EXPECT().Review(ctx, "123", TweetStatusRejected, gomock.Any()).Return(reviewedTweet(TweetStatusRejected), nil)
+
+		tweet, err := service.RejectTweet(ctx, "123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, reviewedTweet(TweetStatusRejected), tweet)
+	})
+
+	t.Run("tweet not held", func(t *testing.T) {
+		mockRepo.EXPECT().Review(ctx, "123", TweetStatusPublished, gomock.Any()).Return(nil, ErrTweetNotHeld)
+
+		tweet, err := service.ApproveTweet(ctx, "123")
+
+		assert.ErrorIs(t, err, ErrTweetNotHeld)
+		assert.Nil(t, tweet)
+	})
+
+	t.Run("empty tweet id", func(t *testing.T) {
+		_, err := service.RejectTweet(ctx, "")
+
+		assert.EqualError(t, err, "tweet ID cannot be empty")
+	})
+}
+
+func TestTweetService_CreateTweetPoll(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(NewInMemoryTweetRepository(), queue.NewInMemoryQueue(), nil, nil)
+	closesAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	t.Run("poll is stored with the tweet", func(t *testing.T) {
+		created, err := service.CreateTweet(ctx, &Tweet{
+			Handler: "testuser",
+			Content: Content{Text: "Favorite language?", Poll: &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt.UTC()}, created.Content.Poll)
+	})
+
+	t.Run("poll with media", func(t *testing.T) {
+		mockRepo := NewMockRepository(gomock.NewController(t))
+		expectKnownUsers(mockRepo)
+		mediaID := "7f1b6a52-1f1e-4c1a-9d43-5a2f3d1c8e01"
+		mockRepo.EXPECT().GetMediaByIDs(ctx, []string{mediaID}).Return([]*Media{{ID: mediaID, UserID: "testuser-id", Handler: "testuser", Type: MediaTypeImage}}, nil)
+
+		_, err := NewService(mockRepo, queue.NewInMemoryQueue(), nil, nil).CreateTweet(ctx, &Tweet{
+			Handler: "testuser",
+			Content: Content{
+				Text:  "Favorite language?",
+				Media: []Attachment{{ID: mediaID}},
+				Poll:  &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt},
+			},
+		})
+
+		assert.EqualError(t, err, "invalid poll: a tweet cannot have both a poll and media")
+	})
+
+	t.Run("invalid poll", func(t *testing.T) {
+		_, err := service.CreateTweet(ctx, &Tweet{
+			Handler: "testuser",
+			Content: Content{Text: "Favorite language?", Poll: &Poll{Options: []PollOption{{Text: "Go"}}, ClosesAt: closesAt}},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPoll)
+	})
+}
+
+func TestTweetService_Vote(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	service := NewService(repository, queue.NewInMemoryQueue(), nil, nil)
+	now := time.Now().UTC()
+
+	newPoll := func(id string, closesAt time.Time) *Tweet {
+		tweet := &Tweet{
+			ID:      id,
+			Handler: "author",
+			Status:  TweetStatusPublished,
+			Content: Content{Text: "Favorite language?", Poll: &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: closesAt}},
+		}
+		_, err := repository.Create(ctx, tweet)
+		assert.NoError(t, err)
+		return tweet
+	}
+	open := newPoll("open", now.Add(time.Hour))
+
+	t.Run("vote returns the tallies and the vote of the user", func(t *testing.T) {
+		poll, err := service.Vote(ctx, "open", "user1", 1)
+
+		assert.NoError(t, err)
+		vote := 1
+		assert.Equal(t, &Poll{
+			Options:    []PollOption{{Text: "Go"}, {Text: "Rust", Votes: 1}},
+			ClosesAt:   open.Content.Poll.ClosesAt,
+			TotalVotes: 1,
+			Vote:       &vote,
+		}, poll)
+		// The stored poll is not modified
+		assert.Zero(t, open.Content.Poll.TotalVotes)
+	})
+
+	t.Run("a user votes once", func(t *testing.T) {
+		_, err := service.Vote(ctx, "open", "user1", 0)
+
+		assert.ErrorIs(t, err, ErrAlreadyVoted)
+	})
+
+	t.Run("missing option", func(t *testing.T) {
+		_, err := service.Vote(ctx, "open", "user2", 2)
+
+		assert.EqualError(t, err, "invalid poll: option 2 does not exist")
+	})
+
+	t.Run("tweet without poll", func(t *testing.T) {
+		_, err := repository.Create(ctx, &Tweet{ID: "no-poll", Handler: "author", Content: Content{Text: "No poll"}})
+		assert.NoError(t, err)
+
+		_, err = service.Vote(ctx, "no-poll", "user2", 0)
+
+		assert.ErrorIs(t, err, ErrPollNotFound)
+	})
+
+	t.Run("missing tweet", func(t *testing.T) {
+		_, err := service.Vote(ctx, "missing", "user2", 0)
+
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+	})
+
+	t.Run("closed poll results are frozen", func(t *testing.T) {
+		closed := newPoll("closed", now.Add(-time.Minute))
+		assert.NoError(t, repository.CreateVote(ctx, &PollVote{TweetID: "closed", UserID: "user1-id", Option: 0, CreatedAt: now.Add(-time.Hour)}))
+		// A vote stored after the closing time is not counted
+		assert.NoError(t, repository.CreateVote(ctx, &PollVote{TweetID: "closed", UserID: "user2-id", Option: 1, CreatedAt: now}))
+
+		_, err := service.Vote(ctx, "closed", "user3", 0)
+		assert.ErrorIs(t, err, ErrPollClosed)
+
+		poll, err := service.GetPoll(ctx, closed, "user3")
+		assert.NoError(t, err)
+		assert.Equal(t, &Poll{
+			Options:    []PollOption{{Text: "Go", Votes: 1}, {Text: "Rust"}},
+			ClosesAt:   closed.Content.Poll.ClosesAt,
+			TotalVotes: 1,
+			Closed:     true,
+		}, poll)
+	})
+
+	t.Run("concurrent votes", func(t *testing.T) {
+		newPoll("concurrent", now.Add(time.Hour))
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		accepted := 0
+		for i := 0; i < 50; i++ {
+			for attempt := 0; attempt < 3; attempt++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					// Each user votes three times at once, only one vote is accepted
+					if _, err := service.Vote(ctx, "concurrent", fmt.Sprintf("user%d", i), attempt%2); err == nil {
+						mu.Lock()
+						accepted++
+						mu.Unlock()
+					} else {
+						assert.ErrorIs(t, err, ErrAlreadyVoted)
+					}
+				}()
+			}
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, accepted)
+		poll, err := service.GetPoll(ctx, &Tweet{ID: "concurrent", Content: Content{Poll: &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: now.Add(time.Hour)}}}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(50), poll.TotalVotes)
+		assert.Equal(t, int64(50), poll.Options[0].Votes+poll.Options[1].Votes)
+	})
+}
+
+func TestTweetService_Reply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(repository, mockPublisher, nil, nil)
+
+	_, err := repository.Create(ctx, &Tweet{ID: "123", Handler: "author", Status: TweetStatusPublished})
+	require.NoError(t, err)
+	_, err = repository.Create(ctx, &Tweet{ID: "held", Handler: "author", Status: TweetStatusHeld})
+	require.NoError(t, err)
+
+	t.Run("a reply is an engagement on the tweet it replies to", func(t *testing.T) {
+		parentID := "123"
+		mockPublisher.EXPECT().Publish(ctx, events.TopicTweetPosted, "user1", gomock.Any()).Return(nil)
+		mockPublisher.EXPECT().Publish(ctx, events.TopicTweetEngaged, "123", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, event any) error {
+				engaged := event.(events.Event)
+				assert.Equal(t, events.TypeTweetReplied, engaged.EventType)
+				assert.Equal(t, "user1", engaged.Handler)
+				assert.Equal(t, "123", engaged.TweetID)
+				return nil
+			})
+
+		reply, err := service.CreateTweet(ctx, &Tweet{Handler: "user1", Content: Content{Text: "Reply"}, ReplyToID: &parentID})
+
+		require.NoError(t, err)
+		assert.Equal(t, &parentID, reply.ReplyToID)
+	})
+
+	t.Run("a scheduled reply is an engagement once published", func(t *testing.T) {
+		parentID := "123"
+		publishAt := time.Now().Add(time.Hour)
+
+		_, err := service.CreateTweet(ctx, &Tweet{Handler: "user1", Content: Content{Text: "Reply"}, ReplyToID: &parentID, PublishAt: &publishAt})
+
+		assert.NoError(t, err)
+	})
+
+	for _, parentID := range []string{"missing", "held"} {
+		t.Run("cannot reply to a "+parentID+" tweet", func(t *testing.T) {
+			_, err := service.CreateTweet(ctx, &Tweet{Handler: "user1", Content: Content{Text: "Reply"}, ReplyToID: &parentID})
+
+			assert.ErrorIs(t, err, ErrInvalidReply)
+		})
+	}
+}
+
+func TestTweetService_ScheduleTweet(t *testing.T) {
+	ctx := context.Background()
+	publishAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	t.Run("scheduled tweets are not published", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockPublisher := queue.NewMockPublisher(ctrl)
+		mockPublisher.EXPECT().Publish(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+		service := NewService(NewInMemoryTweetRepository(), mockPublisher, nil, nil)
+
+		created, err := service.CreateTweet(ctx, &Tweet{Handler: "testuser", Content: Content{Text: "Later"}, PublishAt: &publishAt})
+
+		assert.NoError(t, err)
+		assert.Equal(t, TweetStatusScheduled, created.Status)
+		assert.Equal(t, publishAt.UTC(), *created.PublishAt)
+	})
+
+	t.Run("held tweets are published when approved", func(t *testing.T) {
+		moderator, err := NewBlocklistModerator(ModerationHold, []string{"scam"}, nil)
+		assert.NoError(t, err)
+		service := NewService(NewInMemoryTweetRepository(), queue.NewInMemoryQueue(), moderator, nil)
+
+		created, err := service.CreateTweet(ctx, &Tweet{Handler: "testuser", Content: Content{Text: "A scam"}, PublishAt: &publishAt})
+
+		assert.NoError(t, err)
+		assert.Equal(t, TweetStatusHeld, created.Status)
+		assert.Nil(t, created.PublishAt)
+	})
+
+	t.Run("poll closing time is relative to the publish time", func(t *testing.T) {
+		service := NewService(NewInMemoryTweetRepository(), queue.NewInMemoryQueue(), nil, nil)
+
+		_, err := service.CreateTweet(ctx, &Tweet{
+			Handler:   "testuser",
+			Content:   Content{Text: "Later", Poll: &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: publishAt.Add(-time.Minute)}},
+			PublishAt: &publishAt,
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPoll)
+	})
+
+	for name, publishAt := range map[string]time.Time{
+		"publish time in the past":   time.Now().Add(-time.Hour),
+		"publish time too far ahead": time.Now().Add(2 * 365 * 24 * time.Hour),
+	} {
+		t.Run(name, func(t *testing.T) {
+			service := NewService(NewInMemoryTweetRepository(), queue.NewInMemoryQueue(), nil, nil)
+
+			_, err := service.CreateTweet(ctx, &Tweet{Handler: "testuser", Content: Content{Text: "Later"}, PublishAt: &publishAt})
+
+			assert.EqualError(t, err, "invalid publish time: a tweet must be scheduled between 1 minute and 365 days ahead")
+		})
+	}
+}
+
+func TestTweetService_ManageScheduledTweets(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	service := NewService(repository, queue.NewInMemoryQueue(), nil, nil)
+	publishAt := time.Now().Add(time.Hour).UTC()
+
+	scheduled, err := service.CreateTweet(ctx, &Tweet{
+		Handler:   "owner",
+		Content:   Content{Text: "Later", Poll: &Poll{Options: []PollOption{{Text: "Go"}, {Text: "Rust"}}, ClosesAt: publishAt.Add(time.Hour)}},
+		PublishAt: &publishAt,
+	})
+	assert.NoError(t, err)
+	published, err := service.CreateTweet(ctx, &Tweet{Handler: "owner", Content: Content{Text: "Now"}})
+	assert.NoError(t, err)
+
+	t.Run("list", func(t *testing.T) {
+		tweets, err := service.GetScheduledTweets(ctx, "owner")
+
+		assert.NoError(t, err)
+		assert.Len(t, tweets, 1)
+		assert.Equal(t, scheduled.ID, tweets[0].ID)
+	})
+
+	t.Run("reschedule", func(t *testing.T) {
+		rescheduled, err := service.RescheduleTweet(ctx, scheduled.ID, "owner", publishAt.Add(30*time.Minute))
+
+		assert.NoError(t, err)
+		assert.Equal(t, publishAt.Add(30*time.Minute), *rescheduled.PublishAt)
+	})
+
+	t.Run("reschedule after the poll closing time", func(t *testing.T) {
+		_, err := service.RescheduleTweet(ctx, scheduled.ID, "owner", publishAt.Add(2*time.Hour))
+
+		assert.ErrorIs(t, err, ErrInvalidPoll)
+	})
+
+	t.Run("reschedule in the past", func(t *testing.T) {
+		_, err := service.RescheduleTweet(ctx, scheduled.ID, "owner", time.Now().Add(-time.Hour))
+
+		assert.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("tweets of other users are not found", func(t *testing.T) {
+		_, err := service.RescheduleTweet(ctx, scheduled.ID, "other", publishAt)
+		assert.ErrorIs(t, err, ErrTweetNotFound)
+
+		assert.ErrorIs(t, service.CancelScheduledTweet(ctx, scheduled.ID, "other"), ErrTweetNotFound)
+	})
+
+	t.Run("published tweets are not scheduled", func(t *testing.T) {
+		_, err := service.RescheduleTweet(ctx, published.ID, "owner", publishAt)
+		assert.ErrorIs(t, err, ErrTweetNotScheduled)
+
+		assert.ErrorIs(t, service.CancelScheduledTweet(ctx, published.ID, "owner"), ErrTweetNotScheduled)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		assert.NoError(t, service.CancelScheduledTweet(ctx, scheduled.ID, "owner"))
+
+		tweet, err := repository.GetByID(ctx, scheduled.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, tweet)
+	})
+}
+
+func TestTweetService_PublishDueTweets(t *testing.T) {
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	messageQueue := queue.NewInMemoryQueue()
+	service := NewService(repository, messageQueue, nil, nil)
+	now := time.Now().UTC()
+
+	var mu sync.Mutex
+	var posted []string
+	messageQueue.Subscribe(events.TopicTweetPosted, func(ctx context.Context, message *queue.Message) error {
+		var event events.Event
+		assert.NoError(t, message.Decode(&event))
+		mu.Lock()
+		posted = append(posted, event.TweetID)
+		mu.Unlock()
+		return nil
+	})
+
+	// More due tweets than a batch, and one not due yet
+	for i := 0; i < scheduledBatchSize+5; i++ {
+		publishAt := now.Add(-time.Duration(i) * time.Second)
+		_, err := repository.Create(ctx, &Tweet{ID: fmt.Sprintf("due-%03d", i), UserID: "user1-id", Handler: "user1", Status: TweetStatusScheduled, PublishAt: &publishAt})
+		assert.NoError(t, err)
+	}
+	later := now.Add(time.Hour)
+	_, err := repository.Create(ctx, &Tweet{ID: "later", UserID: "user1-id", Handler: "user1", Status: TweetStatusScheduled, PublishAt: &later})
+	assert.NoError(t, err)
+
+	// Concurrent schedulers, e.g. of several instances, post each tweet once
+	var wg sync.WaitGroup
+	total := 0
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			published, err := service.PublishDueTweets(ctx)
+			assert.NoError(t, err)
+			mu.Lock()
+			total += published
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, scheduledBatchSize+5, total)
+	assert.Len(t, posted, scheduledBatchSize+5)
+	userTweets, err := repository.GetByUserID(ctx, "user1-id")
+	assert.NoError(t, err)
+	assert.Len(t, userTweets, scheduledBatchSize+5)
+
+	// Publishing again after a restart posts nothing
+	published, err := service.PublishDueTweets(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+	assert.Len(t, posted, scheduledBatchSize+5)
+}
+
+func TestTweetService_PublishDueTweetsPostsPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	repository := NewInMemoryTweetRepository()
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(repository, mockPublisher, nil, nil)
+	now := time.Now().UTC()
+
+	// A due tweet whose event fails to be published is published, and stays pending to be posted
+	publishAt := now.Add(-time.Second)
+	_, err := repository.Create(ctx, &Tweet{ID: "due", Handler: "user1", Status: TweetStatusScheduled, PublishAt: &publishAt})
+	require.NoError(t, err)
+	mockPublisher.EXPECT().Publish(ctx, events.TopicTweetPosted, "user1", gomock.Any()).Return(errors.New("broker unavailable"))
+
+	published, err := service.PublishDueTweets(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	pending, err := repository.GetPostedPending(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, TweetStatusPublished, pending[0].Status)
+
+	// A tweet published by a scheduler that stopped before posting it is posted after the retry delay,
+	// with the same event ID however many times it is posted
+	_, err = repository.Create(ctx, &Tweet{ID: "stopped", Handler: "user1", Status: TweetStatusPublished, PostedPending: true, CreatedAt: now.Add(-2 * postedRetryDelay)})
+	require.NoError(t, err)
+	mockPublisher.EXPECT().
+		Publish(ctx, events.TopicTweetPosted, "user1", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+			event := payload.(events.Event)
+			assert.Equal(t, "stopped", event.TweetID)
+			assert.Equal(t, tweetEventID(events.TypeTweetCreated, "stopped"), event.ID)
+			return nil
+		})
+
+	published, err = service.PublishDueTweets(ctx)
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+	pending, err = repository.GetPostedPending(ctx, now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "due", pending[0].ID)
+}
+
 // Helper function to provide consistent timestamps in tests
 func mockTime() time.Time {
 	t, _ := time.Parse(time.RFC3339, "2025-01-01T00:00:00Z")
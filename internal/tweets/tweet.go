@@ -1,23 +1,75 @@
 package tweets
 
 import (
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// maxHashtagLength is the maximum number of characters of a hashtag
+const maxHashtagLength = 100
+
+const (
+	// MaxContentLength is the maximum length of the text of a tweet, as counted by Content.Length
+	MaxContentLength = 280
+	// linkWeight is the length a link counts as, whatever its actual length
+	linkWeight = 23
+	// emojiWeight is the length an emoji counts as
+	emojiWeight = 2
+)
+
+// hashtagPattern matches a hashtag that is not part of a word (e.g. not "a#b")
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])#([\p{L}\p{N}_]+)`)
+
+// mentionPattern matches a mention that is not part of a word or an email address (e.g. not "a@b.com")
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@])@([\p{L}\p{N}_]+)`)
+
+// Tweet statuses
+const (
+	TweetStatusPublished = "published"
+	TweetStatusHeld      = "held"      // Waiting for review, only visible to its author
+	TweetStatusRejected  = "rejected"  // Rejected on review, only visible to its author
+	TweetStatusScheduled = "scheduled" // Waiting for its publish time, only visible to its author
 )
 
 // Tweet represents the tweet domain and DB model merged
 type Tweet struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	Handler   string    `gorm:"type:varchar(255);not null;index" json:"handler"`
-	Content   Content   `gorm:"type:jsonb;not null" json:"content"`
-	CreatedAt time.Time `gorm:"index" json:"created_at"`
+	ID               string     `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID           string     `gorm:"type:uuid;not null;index" json:"-"`
+	Handler          string     `gorm:"type:varchar(255);not null;index" json:"handler"`
+	Content          Content    `gorm:"type:jsonb;not null" json:"content"`
+	ReplyToID        *string    `gorm:"type:uuid;index" json:"reply_to_id,omitempty"` // Tweet this tweet replies to
+	Status           string     `gorm:"type:varchar(20);not null;default:published;index" json:"status,omitempty"`
+	ModerationReason string     `gorm:"type:text" json:"moderation_reason,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	PublishAt        *time.Time `gorm:"index" json:"publish_at,omitempty"`     // Only set while the tweet is scheduled
+	PostedPending    bool       `gorm:"not null;default:false;index" json:"-"` // Published by the scheduler, not posted yet
+	CreatedAt        time.Time  `gorm:"index" json:"created_at"`
+	// DeletedAt is the tombstone of a deleted tweet, which is hidden from all queries until it is purged
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// IsVisible reports whether the tweet is visible to users other than its author
+func (t *Tweet) IsVisible() bool {
+	return t.Status != TweetStatusHeld && t.Status != TweetStatusRejected && t.Status != TweetStatusScheduled
 }
 
 // Content represents the content of a tweet
 type Content struct {
-	Text string `json:"text" validate:"max=280"`
+	Text    string       `json:"text"` // Up to MaxContentLength, enforced by the service
+	Media   []Attachment `json:"media,omitempty"`
+	Poll    *Poll        `json:"poll,omitempty"`
+	URLs    []string     `json:"urls,omitempty"`    // Normalized links of the text, set by the service
+	Preview *LinkPreview `json:"preview,omitempty"` // Card of the first link with metadata, attached by the PreviewWorker
 }
 
 // Implement driver.Valuer interface: converts Content to JSON for DB storage
@@ -33,3 +85,80 @@ func (c *Content) Scan(value interface{}) error {
 	}
 	return json.Unmarshal(bytes, c)
 }
+
+// Length returns the length of the text in user-perceived characters, weighting the links and the emojis
+func (c Content) Length() int {
+	length := 0
+	start := 0
+	for _, link := range findLinks(c.Text) {
+		length += textLength(c.Text[start:link[0]]) + linkWeight
+		start = link[1]
+	}
+	return length + textLength(c.Text[start:])
+}
+
+// textLength returns the length of a text without links
+func textLength(text string) int {
+	length := 0
+	for _, cluster := range graphemeClusters(text) {
+		if isEmojiCluster(cluster) {
+			length += emojiWeight
+		} else {
+			length++
+		}
+	}
+	return length
+}
+
+// ExtractURLs returns the first maxTweetURLs distinct normalized links of the text in order of appearance
+func (c Content) ExtractURLs() []string {
+	var urls []string
+	for _, link := range links(c.Text) {
+		normalized, ok := normalizeURL(link)
+		if !ok || slices.Contains(urls, normalized) {
+			continue
+		}
+		urls = append(urls, normalized)
+		if len(urls) == maxTweetURLs {
+			break
+		}
+	}
+	return urls
+}
+
+// Hashtags returns the distinct lowercase hashtags of the content in order of appearance, ignoring the invalid ones
+func (c Content) Hashtags() []string {
+	var hashtags []string
+	seen := map[string]bool{}
+	for _, match := range hashtagPattern.FindAllStringSubmatch(c.Text, -1) {
+		tag := strings.ToLower(match[1])
+		if seen[tag] || utf8.RuneCountInString(tag) > maxHashtagLength || strings.Trim(tag, "0123456789") == "" {
+			continue
+		}
+		seen[tag] = true
+		hashtags = append(hashtags, tag)
+	}
+	return hashtags
+}
+
+// Mentions returns the handlers mentioned in the content, without duplicates and in order of appearance
+func (c Content) Mentions() []string {
+	var mentions []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(c.Text, -1) {
+		handler := match[1]
+		if seen[handler] {
+			continue
+		}
+		seen[handler] = true
+		mentions = append(mentions, handler)
+	}
+	return mentions
+}
+
+// Fingerprint returns a hash of the content that ignores case and whitespace, to detect repeated tweets
+func (c Content) Fingerprint() string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(c.Text), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
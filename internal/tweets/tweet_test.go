@@ -0,0 +1,147 @@
+package tweets
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContent_Hashtags(t *testing.T) {
+	tt := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "no hashtags",
+			text: "Hello, world!",
+			want: nil,
+		},
+		{
+			name: "hashtags are lowercased and deduplicated",
+			text: "#Go is great, #golang #go",
+			want: []string{"go", "golang"},
+		},
+		{
+			name: "hashtags followed by punctuation",
+			text: "Loving #Go,#rust! and (#zig)",
+			want: []string{"go", "rust", "zig"},
+		},
+		{
+			name: "unicode hashtags",
+			text: "Buenos días #café #日本",
+			want: []string{"café", "日本"},
+		},
+		{
+			name: "hashtags inside words and numeric tags are ignored",
+			text: "email#tag issue #1 and ##double &#39; #v2",
+			want: []string{"v2"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Content{Text: tc.text}.Hashtags())
+		})
+	}
+}
+
+func TestContent_Mentions(t *testing.T) {
+	tt := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "no mentions",
+			text: "Hello, world!",
+			want: nil,
+		},
+		{
+			name: "mentions are deduplicated",
+			text: "@alice and @bob, thanks @alice!",
+			want: []string{"alice", "bob"},
+		},
+		{
+			name: "email addresses and double at signs are ignored",
+			text: "write to alice@example.com or @@bob (@carol)",
+			want: []string{"carol"},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Content{Text: tc.text}.Mentions())
+		})
+	}
+}
+
+func TestContent_Fingerprint(t *testing.T) {
+	fingerprint := Content{Text: "Buy   cheap followers NOW"}.Fingerprint()
+
+	assert.Len(t, fingerprint, 64)
+	assert.Equal(t, fingerprint, Content{Text: " buy cheap\nfollowers now "}.Fingerprint())
+	assert.NotEqual(t, fingerprint, Content{Text: "Buy cheap followers later"}.Fingerprint())
+}
+
+func TestContent_Length(t *testing.T) {
+	tt := []struct {
+		name string
+		text string
+		want int
+	}{
+		{
+			name: "ascii",
+			text: "Hello, world!",
+			want: 13,
+		},
+		{
+			name: "combining marks count once",
+			text: "café não",
+			want: 8,
+		},
+		{
+			name: "non latin scripts",
+			text: "日本語 한국어 \u1100\u1161\u11A8", // The last one is 각 as conjoining jamo
+			want: 9,
+		},
+		{
+			name: "line breaks",
+			text: "a\r\nb\nc",
+			want: 5,
+		},
+		{
+			name: "prepended characters join the next one",
+			text: "\u0600123", // Arabic number sign
+			want: 3,
+		},
+		{
+			name: "emoji",
+			text: "Hi 👋",
+			want: 5,
+		},
+		{
+			name: "emoji sequences count as one emoji",
+			text: "👍🏽👨‍👩‍👧‍👦🏳️‍🌈",
+			want: 6,
+		},
+		{
+			name: "flags are pairs of regional indicators",
+			text: "🇦🇷🇺🇸🇯",
+			want: 5,
+		},
+		{
+			name: "keycaps and emoji presentation",
+			text: "1️⃣ ©️ ©",
+			want: 7,
+		},
+		{
+			name: "links have a fixed length",
+			text: "Read https://example.com/a/very/long/path/that/goes/on/and/on?with=query and http://x.io",
+			want: 5 + 23 + 5 + 23,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Content{Text: tc.text}.Length())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package tweets
+
+import (
+	"context"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// DeactivatedUser is a user that deactivated their account, whose tweets are hidden until they reactivate it
+type DeactivatedUser struct {
+	UserID        string    `gorm:"primaryKey;type:uuid"`
+	DeactivatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for the DeactivatedUser
+func (DeactivatedUser) TableName() string {
+	return "deactivated_users"
+}
+
+// UserDeactivationConsumer hides the tweets of the deactivated users and restores them when they are reactivated
+type UserDeactivationConsumer struct {
+	service Service
+}
+
+// NewUserDeactivationConsumer creates a new user deactivation consumer
+func NewUserDeactivationConsumer(service Service) *UserDeactivationConsumer {
+	return &UserDeactivationConsumer{
+		service: service,
+	}
+}
+
+// Subscribe registers the consumer handlers in the subscriber
+func (consumer *UserDeactivationConsumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicUserDeactivated, consumer.HandleUserDeactivated)
+	subscriber.Subscribe(events.TopicUserReactivated, consumer.HandleUserReactivated)
+}
+
+// HandleUserDeactivated hides the tweets of a user that deactivated their account
+func (consumer *UserDeactivationConsumer) HandleUserDeactivated(ctx context.Context, message *queue.Message) error {
+	var event events.UserDeactivated
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.HideUserTweets(ctx, event.Handler, event.Timestamp)
+}
+
+// HandleUserReactivated restores the tweets of a user that reactivated their account
+func (consumer *UserDeactivationConsumer) HandleUserReactivated(ctx context.Context, message *queue.Message) error {
+	var event events.UserReactivated
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+
+	return consumer.service.RestoreUserTweets(ctx, event.Handler)
+}
@@ -0,0 +1,100 @@
+package tweets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserDeactivationConsumer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewUserDeactivationConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "hides the tweets of the deactivated user",
+			expectations: func() {
+				mockService.EXPECT().
+					HideUserTweets(ctx, "user1", time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)).
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserDeactivated,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name: "restores the tweets of the reactivated user",
+			expectations: func() {
+				mockService.EXPECT().
+					RestoreUserTweets(ctx, "user1").
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserReactivated,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name: "failure is returned",
+			expectations: func() {
+				mockService.EXPECT().
+					RestoreUserTweets(ctx, "user1").
+					Return(errors.New("database unavailable")).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserReactivated,
+				Key:     "user1",
+				Payload: []byte(`{"handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserDeactivated,
+				Key:     "user1",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			var err error
+			if tc.message.Topic == events.TopicUserDeactivated {
+				err = consumer.HandleUserDeactivated(ctx, tc.message)
+			} else {
+				err = consumer.HandleUserReactivated(ctx, tc.message)
+			}
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
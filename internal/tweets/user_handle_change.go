@@ -0,0 +1,39 @@
+package tweets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// UserHandleChangeConsumer shows the tweets of the users that change their handle with the new one
+type UserHandleChangeConsumer struct {
+	service Service
+}
+
+// NewUserHandleChangeConsumer creates a new user handle change consumer
+func NewUserHandleChangeConsumer(service Service) *UserHandleChangeConsumer {
+	return &UserHandleChangeConsumer{
+		service: service,
+	}
+}
+
+// Subscribe registers the consumer handler in the subscriber
+func (consumer *UserHandleChangeConsumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicUserHandleChanged, consumer.HandleUserHandleChanged)
+}
+
+// HandleUserHandleChanged makes the user known by their new handle, so the event can be delivered again
+func (consumer *UserHandleChangeConsumer) HandleUserHandleChanged(ctx context.Context, message *queue.Message) error {
+	var event events.UserHandleChanged
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+	if event.UserID == "" || event.OldHandler == "" || event.NewHandler == "" {
+		return fmt.Errorf("%w: user handle changed event without user ID or handlers", queue.ErrInvalidMessage)
+	}
+
+	return consumer.service.RenameUser(ctx, event.UserID, event.OldHandler, event.NewHandler)
+}
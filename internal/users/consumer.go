@@ -0,0 +1,39 @@
+package users
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+)
+
+// Consumer handles the events the users service is subscribed to
+type Consumer struct {
+	service Service
+}
+
+// NewConsumer creates a new users event consumer
+func NewConsumer(service Service) *Consumer {
+	return &Consumer{
+		service: service,
+	}
+}
+
+// Subscribe registers the consumer handlers in the subscriber
+func (consumer *Consumer) Subscribe(subscriber queue.Subscriber) {
+	subscriber.Subscribe(events.TopicUserDataDeleted, consumer.HandleUserDataDeleted)
+}
+
+// HandleUserDataDeleted records that a service deleted its data about a deleted user
+func (consumer *Consumer) HandleUserDataDeleted(ctx context.Context, message *queue.Message) error {
+	var event events.UserDataDeleted
+	if err := message.Decode(&event); err != nil {
+		return err
+	}
+	if event.DeletionID == "" || event.Service == "" {
+		return fmt.Errorf("%w: user data deleted event without deletion ID or service", queue.ErrInvalidMessage)
+	}
+
+	return consumer.service.CompleteUserDeletion(ctx, event.DeletionID, event.Service)
+}
@@ -0,0 +1,129 @@
+package users
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestConsumer_HandleUserDataDeleted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockService := NewMockService(ctrl)
+	consumer := NewConsumer(mockService)
+
+	tt := []struct {
+		name         string
+		expectations func()
+		message      *queue.Message
+		wantErr      bool
+	}{
+		{
+			name: "records the deletion by the service",
+			expectations: func() {
+				mockService.EXPECT().
+					CompleteUserDeletion(ctx, "deletion-1", events.ServiceTweets).
+					Return(nil).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserDataDeleted,
+				Key:     "user1",
+				Payload: []byte(`{"deletion_id":"deletion-1","handler":"user1","service":"tweets","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown deletion",
+			expectations: func() {
+				mockService.EXPECT().
+					CompleteUserDeletion(ctx, "deletion-1", events.ServiceTweets).
+					Return(ErrDeletionNotFound).
+					Times(1)
+			},
+			message: &queue.Message{
+				Topic:   events.TopicUserDataDeleted,
+				Key:     "user1",
+				Payload: []byte(`{"deletion_id":"deletion-1","handler":"user1","service":"tweets","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:         "missing service",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserDataDeleted,
+				Key:     "user1",
+				Payload: []byte(`{"deletion_id":"deletion-1","handler":"user1","timestamp":"2025-08-09T05:13:41Z"}`),
+			},
+			wantErr: true,
+		},
+		{
+			name:         "invalid payload",
+			expectations: func() {},
+			message: &queue.Message{
+				Topic:   events.TopicUserDataDeleted,
+				Key:     "user1",
+				Payload: []byte(`{`),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := consumer.HandleUserDataDeleted(ctx, tc.message)
+
+			assert.Equal(t, tc.wantErr, err != nil)
+		})
+	}
+}
+
+func TestConsumer_SubscribeUserDeletion(t *testing.T) {
+	ctx := context.Background()
+
+	repo := NewInMemoryUserRepository()
+	require.NoError(t, repo.CreateUser(ctx, &User{Handler: "user1"}))
+
+	messageQueue := queue.NewInMemoryQueue()
+	service := NewService(repo, messageQueue, 24*time.Hour)
+	NewConsumer(service).Subscribe(messageQueue)
+
+	// Every service acknowledges the deletion once it deleted its data about the user
+	for _, serviceName := range []string{events.ServiceAnalytics, events.ServiceFeed, events.ServiceTweets} {
+		messageQueue.Subscribe(events.TopicUserDeleted, func(ctx context.Context, message *queue.Message) error {
+			var event events.UserDeleted
+			if err := message.Decode(&event); err != nil {
+				return err
+			}
+			acknowledgement := events.UserDataDeleted{
+				DeletionID: event.DeletionID,
+				Handler:    event.Handler,
+				Service:    serviceName,
+				Timestamp:  time.Now().UTC(),
+			}
+			return messageQueue.Publish(ctx, events.TopicUserDataDeleted, event.Handler, acknowledgement)
+		})
+	}
+
+	require.NoError(t, service.DeleteUser(ctx, "user1"))
+
+	deletion, err := service.GetUserDeletion(ctx, "user1")
+	require.NoError(t, err)
+	assert.Equal(t, DeletionStatusCompleted, deletion.Status)
+	assert.NotNil(t, deletion.CompletedAt)
+	require.Len(t, deletion.Services, 3)
+	for _, step := range deletion.Services {
+		assert.NotNil(t, step.CompletedAt, step.Service)
+	}
+}
@@ -0,0 +1,86 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	// ErrUserDeactivated is returned when deactivating a user that is already deactivated
+	ErrUserDeactivated = NewRepositoryError("user already deactivated")
+	// ErrUserNotDeactivated is returned when reactivating a user that is not deactivated
+	ErrUserNotDeactivated = NewRepositoryError("user not deactivated")
+	// ErrDeactivationExpired is returned when reactivating a user deactivated before the grace period
+	ErrDeactivationExpired = NewRepositoryError("user deactivation expired")
+)
+
+// deactivatedBatchSize is the number of deactivated users deleted per batch
+const deactivatedBatchSize = 100
+
+// DeactivatedUsersDeleter periodically deletes the users that did not reactivate their account within the grace period
+type DeactivatedUsersDeleter struct {
+	repository  Repository
+	service     Service
+	gracePeriod time.Duration
+	interval    time.Duration
+}
+
+// NewDeactivatedUsersDeleter creates a new deactivated users deleter, deleting the users through the service
+func NewDeactivatedUsersDeleter(repository Repository, service Service, gracePeriod, interval time.Duration) *DeactivatedUsersDeleter {
+	return &DeactivatedUsersDeleter{
+		repository:  repository,
+		service:     service,
+		gracePeriod: gracePeriod,
+		interval:    interval,
+	}
+}
+
+// Run deletes the expired deactivated users at startup and on every interval until the context is cancelled
+func (deleter *DeactivatedUsersDeleter) Run(ctx context.Context) {
+	ticker := time.NewTicker(deleter.interval)
+	defer ticker.Stop()
+
+	for {
+		if count, err := deleter.Delete(ctx); err != nil {
+			log.Printf("failed to delete deactivated users: %v", err)
+		} else if count > 0 {
+			log.Printf("deleted %d deactivated users", count)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Delete deletes the users deactivated before the grace period, in batches, and returns how many were deleted
+func (deleter *DeactivatedUsersDeleter) Delete(ctx context.Context) (int, error) {
+	before := time.Now().UTC().Add(-deleter.gracePeriod)
+
+	deleted := 0
+	for {
+		deactivated, err := deleter.repository.GetDeactivatedUsers(ctx, before, deactivatedBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, user := range deactivated {
+			// The user may have been deleted or reactivated in the meantime
+			err := deleter.service.DeleteDeactivatedUser(ctx, user.Handler, before)
+			if errors.Is(err, ErrUserNotFound) {
+				continue
+			}
+			if err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+		if len(deactivated) < deactivatedBatchSize {
+			return deleted, nil
+		}
+	}
+}
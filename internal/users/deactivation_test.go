@@ -0,0 +1,67 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDeactivatedUsersDeleter_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, handler := range []string{"expired", "recent", "active"} {
+		require.NoError(t, repo.CreateUser(ctx, &User{Handler: handler}))
+	}
+	require.NoError(t, repo.DeactivateUser(ctx, "expired", time.Now().UTC().Add(-48*time.Hour)))
+	require.NoError(t, repo.DeactivateUser(ctx, "recent", time.Now().UTC().Add(-time.Hour)))
+
+	var deleted []string
+	messageQueue := queue.NewInMemoryQueue()
+	messageQueue.Subscribe(events.TopicUserDeleted, func(ctx context.Context, message *queue.Message) error {
+		deleted = append(deleted, message.Key)
+		return nil
+	})
+	deleter := NewDeactivatedUsersDeleter(repo, NewService(repo, messageQueue, 24*time.Hour), 24*time.Hour, time.Hour)
+
+	count, err := deleter.Delete(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// The deletion of the expired user cascades to the other services
+	assert.Equal(t, []string{"expired"}, deleted)
+	_, err = repo.GetUser(ctx, "expired")
+	assert.Equal(t, ErrUserNotFound, err)
+	_, err = repo.GetUser(ctx, "recent")
+	assert.NoError(t, err)
+	_, err = repo.GetUser(ctx, "active")
+	assert.NoError(t, err)
+}
+
+func TestDeactivatedUsersDeleter_DeleteError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	mockRepo := NewMockRepository(ctrl)
+	mockService := NewMockService(ctrl)
+	deleter := NewDeactivatedUsersDeleter(mockRepo, mockService, 24*time.Hour, time.Hour)
+
+	mockRepo.EXPECT().
+		GetDeactivatedUsers(ctx, gomock.Any(), deactivatedBatchSize).
+		Return([]User{{Handler: "user1"}, {Handler: "gone"}, {Handler: "user2"}}, nil)
+	mockService.EXPECT().DeleteDeactivatedUser(ctx, "user1", gomock.Any()).Return(nil)
+	mockService.EXPECT().DeleteDeactivatedUser(ctx, "gone", gomock.Any()).Return(ErrUserNotFound)
+	mockService.EXPECT().DeleteDeactivatedUser(ctx, "user2", gomock.Any()).Return(errors.New("database unavailable"))
+
+	count, err := deleter.Delete(ctx)
+	assert.EqualError(t, err, "database unavailable")
+	assert.Equal(t, 1, count)
+}
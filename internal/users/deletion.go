@@ -0,0 +1,63 @@
+package users
+
+import (
+	"time"
+)
+
+// ErrDeletionNotFound is returned when a user was never deleted, or when acknowledging an unknown deletion
+var ErrDeletionNotFound = NewRepositoryError("user deletion not found")
+
+// User deletion statuses
+const (
+	DeletionStatusPending   = "pending"   // Some services did not delete their data about the user yet
+	DeletionStatusCompleted = "completed" // Every service deleted its data about the user
+)
+
+// UserDeletion tracks the deletion of a user across the services until each one acknowledges it
+type UserDeletion struct {
+	ID          string                `gorm:"primaryKey;type:uuid" json:"id"`
+	Handler     string                `gorm:"type:varchar(255);not null;index" json:"handler"`
+	Status      string                `gorm:"type:varchar(20);not null" json:"status"`
+	Services    []UserDeletionService `gorm:"foreignKey:DeletionID" json:"services"`
+	RequestedAt time.Time             `gorm:"not null" json:"requested_at"`
+	CompletedAt *time.Time            `json:"completed_at,omitempty"`
+}
+
+// TableName specifies the table name for the UserDeletion
+func (UserDeletion) TableName() string {
+	return "user_deletions"
+}
+
+// UserDeletionService is the deletion of the data of a deleted user by a service
+type UserDeletionService struct {
+	DeletionID  string     `gorm:"primaryKey;type:uuid" json:"-"`
+	Service     string     `gorm:"primaryKey;type:varchar(50)" json:"service"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // Set when the service acknowledged the deletion
+}
+
+// TableName specifies the table name for the UserDeletionService
+func (UserDeletionService) TableName() string {
+	return "user_deletion_services"
+}
+
+// complete marks the deletion by a service as completed, reporting false if the service is not part of it
+func (d *UserDeletion) complete(service string, completedAt time.Time) bool {
+	found, pending := false, false
+	for i := range d.Services {
+		step := &d.Services[i]
+		if step.Service == service {
+			found = true
+			if step.CompletedAt == nil {
+				step.CompletedAt = &completedAt
+			}
+		}
+		if step.CompletedAt == nil {
+			pending = true
+		}
+	}
+	if found && !pending && d.Status != DeletionStatusCompleted {
+		d.Status = DeletionStatusCompleted
+		d.CompletedAt = &completedAt
+	}
+	return found
+}
@@ -0,0 +1,34 @@
+package users
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// ErrInvalidHandler is returned when changing the handler of a user to one that does not match handlerPattern
+var ErrInvalidHandler = errors.New("invalid handler")
+
+// handlerPattern is the format of the handlers, up to 64 letters, digits or underscores
+var handlerPattern = regexp.MustCompile(`^[A-Za-z0-9_]{1,64}$`)
+
+// handlerRedirectPeriod is how long a previous handler of a user keeps redirecting to them
+const handlerRedirectPeriod = 30 * 24 * time.Hour
+
+// UserHandle is a previous handler of a user, which redirects to the user until it expires
+type UserHandle struct {
+	Handler   string    `gorm:"primaryKey;type:varchar(255)" json:"handler"`
+	UserID    string    `gorm:"type:uuid;not null;index" json:"-"`
+	ChangedAt time.Time `gorm:"not null" json:"changed_at"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+// TableName specifies the table name for the UserHandle
+func (UserHandle) TableName() string {
+	return "user_handles"
+}
+
+// isReservedFor reports whether the previous handler still redirects to another user than userID at a time
+func (handle *UserHandle) isReservedFor(userID string, at time.Time) bool {
+	return handle.UserID != userID && handle.ExpiresAt.After(at)
+}
@@ -6,12 +6,47 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/lucas-soria/microblogging/pkg/database"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// usersIDMigration makes the database.LegacyUserID the primary key of the users created before they had an ID
+const usersIDMigration = `
+DO $$
+BEGIN
+	IF to_regclass('users') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'id'
+	) THEN
+		ALTER TABLE users ADD COLUMN id uuid;
+		UPDATE users SET id = ` + database.LegacyUserID + `;
+		ALTER TABLE users ALTER COLUMN id SET NOT NULL;
+		ALTER TABLE users DROP CONSTRAINT IF EXISTS users_pkey;
+		ALTER TABLE users ADD PRIMARY KEY (id);
+	END IF;
+
+	IF to_regclass('user_follows') IS NOT NULL AND NOT EXISTS (
+		SELECT 1 FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'user_follows' AND column_name = 'follower_id'
+	) THEN
+		ALTER TABLE user_follows ADD COLUMN follower_id uuid, ADD COLUMN followee_id uuid;
+		UPDATE user_follows uf SET follower_id = u.id FROM users u WHERE u.handler = uf.follower_handler;
+		UPDATE user_follows uf SET followee_id = u.id FROM users u WHERE u.handler = uf.followee_handler;
+		-- Relationships with users that no longer exist are dropped
+		DELETE FROM user_follows WHERE follower_id IS NULL OR followee_id IS NULL;
+		ALTER TABLE user_follows DROP CONSTRAINT IF EXISTS user_follows_pkey;
+		ALTER TABLE user_follows DROP COLUMN follower_handler, DROP COLUMN followee_handler;
+		ALTER TABLE user_follows ALTER COLUMN follower_id SET NOT NULL, ALTER COLUMN followee_id SET NOT NULL;
+		ALTER TABLE user_follows ADD PRIMARY KEY (follower_id, followee_id);
+	END IF;
+END $$;
+`
+
 // PostgresUserRepository implements the Repository interface for PostgreSQL
 type PostgresUserRepository struct {
 	db database.DBClient
@@ -19,8 +54,13 @@ type PostgresUserRepository struct {
 
 // NewPostgresUserRepository creates a new PostgreSQL user repository
 func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
+	// The existing users get an ID before the schemas are migrated, which AutoMigrate cannot add to a primary key
+	if err := db.WithContext(context.Background()).Exec(usersIDMigration).Error; err != nil {
+		log.Fatalf("failed to migrate the users to IDs: %v", err)
+	}
+
 	// Auto migrate the schemas
-	for _, model := range []interface{}{&User{}, &UserFollow{}} {
+	for _, model := range []interface{}{&User{}, &UserFollow{}, &UserHandle{}, &UserDeletion{}, &UserDeletionService{}} {
 		if err := db.AutoMigrate(model); err != nil {
 			log.Fatalf("failed to migrate database schema for %T: %v", model, err)
 		}
@@ -29,8 +69,8 @@ func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
 	// Create indexes if they don't exist
 	if err := db.WithContext(context.Background()).Exec(`
 		CREATE INDEX IF NOT EXISTS idx_users_handler ON users(handler);
-		CREATE INDEX IF NOT EXISTS idx_user_follows_follower ON user_follows(follower_handler);
-		CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_handler);
+		CREATE INDEX IF NOT EXISTS idx_user_follows_follower ON user_follows(follower_id);
+		CREATE INDEX IF NOT EXISTS idx_user_follows_followee ON user_follows(followee_id);
 	`).Error; err != nil {
 		log.Fatalf("failed to create database indexes: %v", err)
 	}
@@ -40,16 +80,19 @@ func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
 	// Create mock users
 	mockUsers := []*User{
 		{
+			ID:        uuid.New().String(),
 			Handler:   "lucas",
 			FirstName: "Lucas",
 			LastName:  "Soria",
 		},
 		{
+			ID:        uuid.New().String(),
 			Handler:   "lucas1",
 			FirstName: "Lucas",
 			LastName:  "Soria",
 		},
 		{
+			ID:        uuid.New().String(),
 			Handler:   "lucas2",
 			FirstName: "Lucas",
 			LastName:  "Soria",
@@ -92,7 +135,7 @@ func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
 		// Check if relationship already exists
 		var count int64
 		err := repo.db.WithContext(ctx).Model(&UserFollow{}).
-			Where("follower_handler = ? AND followee_handler = ?", rel.follower, rel.followee).
+			Where("follower_id = (SELECT id FROM users WHERE handler = ?) AND followee_id = (SELECT id FROM users WHERE handler = ?)", rel.follower, rel.followee).
 			Count(&count).Error
 
 		if err != nil {
@@ -106,12 +149,7 @@ func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
 		}
 
 		// Create the follow relationship
-		follow := UserFollow{
-			FollowerHandler: rel.follower,
-			FolloweeHandler: rel.followee,
-		}
-
-		if err := repo.db.WithContext(ctx).Create(&follow).Error; err != nil {
+		if err := repo.FollowUser(ctx, rel.follower, rel.followee); err != nil {
 			log.Printf("failed to create follow relationship %s -> %s: %v", rel.follower, rel.followee, err)
 			continue
 		}
@@ -132,7 +170,7 @@ func NewPostgresUserRepository(db database.DBClient) *PostgresUserRepository {
 	return repo
 }
 
-// CreateUser implements the Repository interface
+// CreateUser implements the Repository interface, generating the ID of the user if not provided
 func (r *PostgresUserRepository) CreateUser(ctx context.Context, user *User) error {
 	// Check if handler already exists
 	exists, err := r.handlerExists(ctx, user.Handler)
@@ -145,6 +183,21 @@ func (r *PostgresUserRepository) CreateUser(ctx context.Context, user *User) err
 		return ErrHandlerExists
 	}
 
+	// A previous handler of another user cannot be taken until it stops redirecting to them
+	reserved, err := r.handlerReserved(r.db.WithContext(ctx), user.Handler, user.ID, time.Now().UTC())
+	if err != nil {
+		log.Printf("error checking if handler %s is reserved: %v", user.Handler, err)
+		return err
+	}
+	if reserved {
+		log.Printf("attempted to create user with reserved handler: %s", user.Handler)
+		return ErrHandlerExists
+	}
+
+	if user.ID == "" {
+		user.ID = uuid.New().String()
+	}
+
 	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
 		log.Printf("error creating user with handler %s: %v", user.Handler, err)
 		return err
@@ -170,39 +223,70 @@ func (r *PostgresUserRepository) GetUser(ctx context.Context, handler string) (*
 	return &user, nil
 }
 
-// DeleteUser implements the Repository interface
+// DeleteUser implements the Repository interface, deleting the follow relationships and previous handlers of the user too
 func (r *PostgresUserRepository) DeleteUser(ctx context.Context, handler string) error {
-	result := r.db.WithContext(ctx).Where("handler = ?", handler).Delete(&User{})
-	if result.Error != nil {
-		log.Printf("error deleting user with handler %s: %v", handler, result.Error)
-		return result.Error
-	}
+	return r.deleteUser(ctx, handler, "handler = ?", handler)
+}
 
-	if result.RowsAffected == 0 {
-		log.Printf("attempted to delete non-existent user with handler: %s", handler)
-		return ErrUserNotFound
-	}
+// DeleteDeactivatedUser implements the Repository interface, checking the deactivation in the deletion itself
+func (r *PostgresUserRepository) DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error {
+	return r.deleteUser(ctx, handler, "handler = ? AND deactivated_at IS NOT NULL AND deactivated_at < ?", handler, deactivatedBefore)
+}
 
-	return nil
+// deleteUser deletes the user matching the conditions with their follow relationships and previous handlers
+func (r *PostgresUserRepository) deleteUser(ctx context.Context, handler string, query string, args ...any) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user User
+		result := tx.Clauses(clause.Returning{}).Where(query, args...).Delete(&user)
+		if result.Error != nil {
+			log.Printf("error deleting user with handler %s: %v", handler, result.Error)
+			return result.Error
+		}
+
+		if result.RowsAffected == 0 {
+			log.Printf("attempted to delete non-existent user with handler: %s", handler)
+			return ErrUserNotFound
+		}
+
+		if err := tx.Where("follower_id = ? OR followee_id = ?", user.ID, user.ID).Delete(&UserFollow{}).Error; err != nil {
+			log.Printf("error deleting follow relationships of %s: %v", handler, err)
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&UserHandle{}).Error; err != nil {
+			log.Printf("error deleting previous handlers of %s: %v", handler, err)
+			return err
+		}
+
+		return nil
+	})
 }
 
 // FollowUser implements the Repository interface
 func (r *PostgresUserRepository) FollowUser(ctx context.Context, followerHandler string, followeeHandler string) error {
 	// Check if both users exist
-	if _, err := r.GetUser(ctx, followerHandler); err != nil {
+	follower, err := r.GetUser(ctx, followerHandler)
+	if err != nil {
 		log.Printf("error verifying follower %s: %v", followerHandler, err)
 		return fmt.Errorf("failed to verify follower: %w", err)
 	}
 
-	if _, err := r.GetUser(ctx, followeeHandler); err != nil {
+	followee, err := r.GetUser(ctx, followeeHandler)
+	if err != nil {
 		log.Printf("error verifying followee %s: %v", followeeHandler, err)
 		return fmt.Errorf("failed to verify followee: %w", err)
 	}
 
+	// Deactivated users cannot be followed until they reactivate their account
+	if followee.IsDeactivated() {
+		log.Printf("user %s attempted to follow deactivated user %s", followerHandler, followeeHandler)
+		return fmt.Errorf("failed to verify followee: %w", ErrUserNotFound)
+	}
+
 	// Create follow relationship
 	follow := UserFollow{
-		FollowerHandler: followerHandler,
-		FolloweeHandler: followeeHandler,
+		FollowerID: follower.ID,
+		FolloweeID: followee.ID,
 	}
 
 	if err := r.db.WithContext(ctx).Create(&follow).Error; err != nil {
@@ -220,7 +304,7 @@ func (r *PostgresUserRepository) FollowUser(ctx context.Context, followerHandler
 // UnfollowUser implements the Repository interface
 func (r *PostgresUserRepository) UnfollowUser(ctx context.Context, followerHandler string, followeeHandler string) error {
 	result := r.db.WithContext(ctx).
-		Where("follower_handler = ? AND followee_handler = ?", followerHandler, followeeHandler).
+		Where("follower_id = (SELECT id FROM users WHERE handler = ?) AND followee_id = (SELECT id FROM users WHERE handler = ?)", followerHandler, followeeHandler).
 		Delete(&UserFollow{})
 
 	if result.Error != nil {
@@ -243,8 +327,9 @@ func (r *PostgresUserRepository) GetUserFollowers(ctx context.Context, followeeH
 	err := r.db.WithContext(ctx).Raw(`
 		SELECT u.*
 		FROM users u
-		JOIN user_follows uf ON u.handler = uf.follower_handler
-		WHERE uf.followee_handler = ?
+		JOIN user_follows uf ON u.id = uf.follower_id
+		JOIN users followee ON followee.id = uf.followee_id
+		WHERE followee.handler = ? AND u.deactivated_at IS NULL
 	`, followeeHandler).Scan(&followers).Error
 
 	if err != nil {
@@ -261,8 +346,9 @@ func (r *PostgresUserRepository) GetUserFollowees(ctx context.Context, followerH
 	err := r.db.WithContext(ctx).Raw(`
 		SELECT u.*
 		FROM users u
-		JOIN user_follows uf ON u.handler = uf.followee_handler
-		WHERE uf.follower_handler = ?
+		JOIN user_follows uf ON u.id = uf.followee_id
+		JOIN users follower ON follower.id = uf.follower_id
+		WHERE follower.handler = ? AND u.deactivated_at IS NULL
 	`, followerHandler).Scan(&followees).Error
 
 	if err != nil {
@@ -272,6 +358,201 @@ func (r *PostgresUserRepository) GetUserFollowees(ctx context.Context, followerH
 	return followees, nil
 }
 
+// CreateDeletion saves the deletion of a user with its services
+func (r *PostgresUserRepository) CreateDeletion(ctx context.Context, deletion *UserDeletion) error {
+	if err := r.db.WithContext(ctx).Create(deletion).Error; err != nil {
+		return fmt.Errorf("failed to create user deletion: %w", err)
+	}
+	return nil
+}
+
+// GetLatestDeletion retrieves the last deletion of a user
+func (r *PostgresUserRepository) GetLatestDeletion(ctx context.Context, handler string) (*UserDeletion, error) {
+	var deletion UserDeletion
+	err := r.db.WithContext(ctx).
+		Preload("Services", func(db *gorm.DB) *gorm.DB { return db.Order("service") }).
+		Where("handler = ?", handler).
+		Order("requested_at DESC, id DESC").
+		First(&deletion).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeletionNotFound
+		}
+		return nil, fmt.Errorf("failed to get user deletion: %w", err)
+	}
+	return &deletion, nil
+}
+
+// CompleteDeletion locks the deletion of a user and marks the deletion of its data by a service as completed
+func (r *PostgresUserRepository) CompleteDeletion(ctx context.Context, id, service string, completedAt time.Time) (*UserDeletion, error) {
+	var deletion UserDeletion
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&deletion, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDeletionNotFound
+			}
+			return err
+		}
+		if err := tx.Where("deletion_id = ?", id).Order("service").Find(&deletion.Services).Error; err != nil {
+			return err
+		}
+		if !deletion.complete(service, completedAt) {
+			return ErrDeletionNotFound
+		}
+
+		if err := tx.Model(&UserDeletionService{}).
+			Where("deletion_id = ? AND service = ? AND completed_at IS NULL", id, service).
+			Update("completed_at", completedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&UserDeletion{}).Where("id = ?", id).Updates(map[string]any{
+			"status":       deletion.Status,
+			"completed_at": deletion.CompletedAt,
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrDeletionNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to complete user deletion: %w", err)
+	}
+	return &deletion, nil
+}
+
+// DeactivateUser implements the Repository interface, returning ErrUserDeactivated if the user already is
+func (r *PostgresUserRepository) DeactivateUser(ctx context.Context, handler string, deactivatedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("handler = ? AND deactivated_at IS NULL", handler).
+		Update("deactivated_at", deactivatedAt)
+	if result.Error != nil {
+		log.Printf("error deactivating user with handler %s: %v", handler, result.Error)
+		return fmt.Errorf("failed to deactivate user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return r.stateError(ctx, handler, ErrUserDeactivated)
+	}
+	return nil
+}
+
+// ReactivateUser implements the Repository interface, checking the deactivation in the update itself
+func (r *PostgresUserRepository) ReactivateUser(ctx context.Context, handler string, deactivatedAfter time.Time) error {
+	result := r.db.WithContext(ctx).Model(&User{}).
+		Where("handler = ? AND deactivated_at IS NOT NULL AND deactivated_at >= ?", handler, deactivatedAfter).
+		Update("deactivated_at", nil)
+	if result.Error != nil {
+		log.Printf("error reactivating user with handler %s: %v", handler, result.Error)
+		return fmt.Errorf("failed to reactivate user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		user, err := r.GetUser(ctx, handler)
+		if err != nil {
+			return err
+		}
+		if !user.IsDeactivated() {
+			return ErrUserNotDeactivated
+		}
+		return ErrDeactivationExpired
+	}
+	return nil
+}
+
+// GetDeactivatedUsers implements the Repository interface, returning the first deactivated users first
+func (r *PostgresUserRepository) GetDeactivatedUsers(ctx context.Context, before time.Time, limit int) ([]User, error) {
+	var deactivated []User
+	err := r.db.WithContext(ctx).
+		Where("deactivated_at IS NOT NULL AND deactivated_at < ?", before).
+		Order("deactivated_at, handler").
+		Limit(limit).
+		Find(&deactivated).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deactivated users: %w", err)
+	}
+	return deactivated, nil
+}
+
+// ChangeHandler implements the Repository interface, locking the user while the new handler is checked
+func (r *PostgresUserRepository) ChangeHandler(ctx context.Context, handler, newHandler string, changedAt, expiresAt time.Time) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "handler = ?", handler).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&User{}).Where("handler = ?", newHandler).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrHandlerExists
+		}
+		reserved, err := r.handlerReserved(tx, newHandler, user.ID, changedAt)
+		if err != nil {
+			return err
+		}
+		if reserved {
+			return ErrHandlerExists
+		}
+
+		// Taking back a previous handler removes its redirect, as well as taking an expired one
+		if err := tx.Where("handler = ?", newHandler).Delete(&UserHandle{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&User{}).Where("id = ?", user.ID).Update("handler", newHandler).Error; err != nil {
+			return err
+		}
+		previous := &UserHandle{Handler: handler, UserID: user.ID, ChangedAt: changedAt, ExpiresAt: expiresAt}
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(previous).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) || errors.Is(err, ErrHandlerExists) {
+			return nil, err
+		}
+		// Another user took the handler concurrently
+		if strings.Contains(err.Error(), "duplicate key value violates unique constraint") {
+			return nil, ErrHandlerExists
+		}
+		log.Printf("error changing handler of %s to %s: %v", handler, newHandler, err)
+		return nil, fmt.Errorf("failed to change handler: %w", err)
+	}
+
+	user.Handler = newHandler
+	return &user, nil
+}
+
+// GetUserByPreviousHandler implements the Repository interface
+func (r *PostgresUserRepository) GetUserByPreviousHandler(ctx context.Context, handler string, at time.Time) (*User, error) {
+	var user User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN user_handles uh ON uh.user_id = users.id").
+		Where("uh.handler = ? AND uh.expires_at > ?", handler, at).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		log.Printf("error fetching user with previous handler %s: %v", handler, err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// stateError returns the error of an update that matched no user, ErrUserNotFound if the user does not exist
+func (r *PostgresUserRepository) stateError(ctx context.Context, handler string, stateErr error) error {
+	exists, err := r.handlerExists(ctx, handler)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return stateErr
+}
+
 // handlerExists checks if a user with the given handler exists
 func (r *PostgresUserRepository) handlerExists(ctx context.Context, handler string) (bool, error) {
 	var count int64
@@ -282,3 +563,17 @@ func (r *PostgresUserRepository) handlerExists(ctx context.Context, handler stri
 
 	return count > 0, nil
 }
+
+// handlerReserved checks if a handler is a previous handler of another user that still redirects to them at a time
+func (r *PostgresUserRepository) handlerReserved(db *gorm.DB, handler, userID string, at time.Time) (bool, error) {
+	var count int64
+	query := db.Model(&UserHandle{}).Where("handler = ? AND expires_at > ?", handler, at)
+	if userID != "" {
+		query = query.Where("user_id <> ?", userID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -2,7 +2,9 @@ package users
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -22,18 +24,37 @@ type Repository interface {
 	UnfollowUser(ctx context.Context, followerHandler string, followeeHandler string) error
 	GetUserFollowers(ctx context.Context, followeeHandler string) ([]User, error)
 	GetUserFollowees(ctx context.Context, followerHandler string) ([]User, error)
+
+	// User Deletions
+	CreateDeletion(ctx context.Context, deletion *UserDeletion) error
+	GetLatestDeletion(ctx context.Context, handler string) (*UserDeletion, error)
+	CompleteDeletion(ctx context.Context, id, service string, completedAt time.Time) (*UserDeletion, error)
+
+	// User Deactivations
+	DeactivateUser(ctx context.Context, handler string, deactivatedAt time.Time) error
+	ReactivateUser(ctx context.Context, handler string, deactivatedAfter time.Time) error
+	GetDeactivatedUsers(ctx context.Context, before time.Time, limit int) ([]User, error)
+	DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error
+
+	// User Handles
+	ChangeHandler(ctx context.Context, handler, newHandler string, changedAt, expiresAt time.Time) (*User, error)
+	GetUserByPreviousHandler(ctx context.Context, handler string, at time.Time) (*User, error)
 }
 
 type InMemoryUserRepository struct {
-	mu     sync.RWMutex
-	users  map[string]*User
-	follow map[string]map[string]bool // followerHandler -> followeeHandler -> bool
+	mu        sync.RWMutex
+	users     map[string]*User
+	follow    map[string]map[string]bool // followerHandler -> followeeHandler -> bool
+	deletions map[string]*UserDeletion
+	handles   map[string]*UserHandle // previous handler -> handle
 }
 
 func NewInMemoryUserRepository() *InMemoryUserRepository {
 	return &InMemoryUserRepository{
-		users:  make(map[string]*User),
-		follow: make(map[string]map[string]bool),
+		users:     make(map[string]*User),
+		follow:    make(map[string]map[string]bool),
+		deletions: make(map[string]*UserDeletion),
+		handles:   make(map[string]*UserHandle),
 	}
 }
 
@@ -41,16 +62,19 @@ func (repository *InMemoryUserRepository) CreateUser(ctx context.Context, user *
 	repository.mu.Lock()
 	defer repository.mu.Unlock()
 
-	// Check if handler already exists
+	// Check if handler already exists, or still redirects to another user
 	for _, u := range repository.users {
 		if u.Handler == user.Handler {
 			return ErrHandlerExists
 		}
 	}
+	if handle, exists := repository.handles[user.Handler]; exists && handle.isReservedFor(user.ID, time.Now().UTC()) {
+		return ErrHandlerExists
+	}
 
 	// Generate new ID if not provided
-	if user.Handler == "" {
-		user.Handler = uuid.New().String()
+	if user.ID == "" {
+		user.ID = uuid.New().String()
 	}
 
 	repository.users[user.Handler] = user
@@ -75,20 +99,46 @@ func (repository *InMemoryUserRepository) DeleteUser(ctx context.Context, handle
 	repository.mu.Lock()
 	defer repository.mu.Unlock()
 
-	if _, exists := repository.users[handler]; !exists {
+	user, exists := repository.users[handler]
+	if !exists {
 		return ErrUserNotFound
 	}
+	repository.deleteUser(user)
+	return nil
+}
+
+// DeleteDeactivatedUser deletes a user still deactivated since before a time, returning ErrUserNotFound otherwise
+func (repository *InMemoryUserRepository) DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user, exists := repository.users[handler]
+	if !exists || !user.IsDeactivated() || !user.DeactivatedAt.Before(deactivatedBefore) {
+		return ErrUserNotFound
+	}
+	repository.deleteUser(user)
+	return nil
+}
+
+// deleteUser deletes a user with their previous handlers and follow relationships, the lock must be held
+func (repository *InMemoryUserRepository) deleteUser(user *User) {
+	handler := user.Handler
 
 	// Remove user from users map
 	delete(repository.users, handler)
 
+	// The previous handlers of the user no longer redirect to them
+	for previous, handle := range repository.handles {
+		if handle.UserID == user.ID {
+			delete(repository.handles, previous)
+		}
+	}
+
 	// Remove user from follow relationships
 	delete(repository.follow, handler) // Remove user's following relationships
 	for followerID := range repository.follow {
 		delete(repository.follow[followerID], handler) // Remove user from others' followers
 	}
-
-	return nil
 }
 
 func (repository *InMemoryUserRepository) FollowUser(ctx context.Context, followerHandler string, followeeHandler string) error {
@@ -99,7 +149,8 @@ func (repository *InMemoryUserRepository) FollowUser(ctx context.Context, follow
 	if _, exists := repository.users[followerHandler]; !exists {
 		return ErrUserNotFound
 	}
-	if _, exists := repository.users[followeeHandler]; !exists {
+	// Deactivated users cannot be followed until they reactivate their account
+	if followee, exists := repository.users[followeeHandler]; !exists || followee.IsDeactivated() {
 		return ErrUserNotFound
 	}
 
@@ -134,11 +185,12 @@ func (repository *InMemoryUserRepository) GetUserFollowers(ctx context.Context,
 	var followers []User
 	for followerID, followees := range repository.follow {
 		if followees[handler] {
-			if user, exists := repository.users[followerID]; exists {
+			if user, exists := repository.users[followerID]; exists && !user.IsDeactivated() {
 				followers = append(followers, *user)
 			}
 		}
 	}
+	sortByHandler(followers)
 
 	return followers, nil
 }
@@ -154,13 +206,203 @@ func (repository *InMemoryUserRepository) GetUserFollowees(ctx context.Context,
 
 	var following []User
 	for followeeID := range repository.follow[handler] {
-		if user, exists := repository.users[followeeID]; exists {
+		if user, exists := repository.users[followeeID]; exists && !user.IsDeactivated() {
 			following = append(following, *user)
 		}
 	}
+	sortByHandler(following)
 	return following, nil
 }
 
+// CreateDeletion saves the deletion of a user
+func (repository *InMemoryUserRepository) CreateDeletion(ctx context.Context, deletion *UserDeletion) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	repository.deletions[deletion.ID] = copyDeletion(deletion)
+	return nil
+}
+
+// GetLatestDeletion retrieves the last deletion of a user
+func (repository *InMemoryUserRepository) GetLatestDeletion(ctx context.Context, handler string) (*UserDeletion, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var deletions []*UserDeletion
+	for _, deletion := range repository.deletions {
+		if deletion.Handler == handler {
+			deletions = append(deletions, deletion)
+		}
+	}
+	if len(deletions) == 0 {
+		return nil, ErrDeletionNotFound
+	}
+	sort.Slice(deletions, func(i, j int) bool {
+		if !deletions[i].RequestedAt.Equal(deletions[j].RequestedAt) {
+			return deletions[i].RequestedAt.After(deletions[j].RequestedAt)
+		}
+		return deletions[i].ID > deletions[j].ID
+	})
+	return copyDeletion(deletions[0]), nil
+}
+
+// CompleteDeletion marks the deletion of the data of a deleted user by a service as completed
+func (repository *InMemoryUserRepository) CompleteDeletion(ctx context.Context, id, service string, completedAt time.Time) (*UserDeletion, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	deletion, exists := repository.deletions[id]
+	if !exists {
+		return nil, ErrDeletionNotFound
+	}
+	updated := copyDeletion(deletion)
+	if !updated.complete(service, completedAt) {
+		return nil, ErrDeletionNotFound
+	}
+	repository.deletions[id] = updated
+	return copyDeletion(updated), nil
+}
+
+// DeactivateUser marks a user as deactivated, returning ErrUserDeactivated if they already are
+func (repository *InMemoryUserRepository) DeactivateUser(ctx context.Context, handler string, deactivatedAt time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user, exists := repository.users[handler]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if user.IsDeactivated() {
+		return ErrUserDeactivated
+	}
+
+	// Replace the user, since it may be shared with the callers of CreateUser
+	updated := *user
+	updated.DeactivatedAt = &deactivatedAt
+	repository.users[handler] = &updated
+	return nil
+}
+
+// ReactivateUser marks a user deactivated after deactivatedAfter as active again
+func (repository *InMemoryUserRepository) ReactivateUser(ctx context.Context, handler string, deactivatedAfter time.Time) error {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user, exists := repository.users[handler]
+	if !exists {
+		return ErrUserNotFound
+	}
+	if !user.IsDeactivated() {
+		return ErrUserNotDeactivated
+	}
+	if user.DeactivatedAt.Before(deactivatedAfter) {
+		return ErrDeactivationExpired
+	}
+
+	updated := *user
+	updated.DeactivatedAt = nil
+	repository.users[handler] = &updated
+	return nil
+}
+
+// GetDeactivatedUsers retrieves the users deactivated before a time, the first deactivated first
+func (repository *InMemoryUserRepository) GetDeactivatedUsers(ctx context.Context, before time.Time, limit int) ([]User, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	var deactivated []User
+	for _, user := range repository.users {
+		if user.IsDeactivated() && user.DeactivatedAt.Before(before) {
+			deactivated = append(deactivated, *user)
+		}
+	}
+	sort.Slice(deactivated, func(i, j int) bool {
+		if !deactivated[i].DeactivatedAt.Equal(*deactivated[j].DeactivatedAt) {
+			return deactivated[i].DeactivatedAt.Before(*deactivated[j].DeactivatedAt)
+		}
+		return deactivated[i].Handler < deactivated[j].Handler
+	})
+	if len(deactivated) > limit {
+		deactivated = deactivated[:limit]
+	}
+	return deactivated, nil
+}
+
+// ChangeHandler changes the handler of a user, the previous one redirecting to them until expiresAt
+func (repository *InMemoryUserRepository) ChangeHandler(ctx context.Context, handler, newHandler string, changedAt, expiresAt time.Time) (*User, error) {
+	repository.mu.Lock()
+	defer repository.mu.Unlock()
+
+	user, exists := repository.users[handler]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if _, taken := repository.users[newHandler]; taken {
+		return nil, ErrHandlerExists
+	}
+	if handle, exists := repository.handles[newHandler]; exists && handle.isReservedFor(user.ID, changedAt) {
+		return nil, ErrHandlerExists
+	}
+
+	// Replace the user, since it may be shared with the callers of CreateUser
+	updated := *user
+	updated.Handler = newHandler
+	delete(repository.users, handler)
+	repository.users[newHandler] = &updated
+
+	// The follow relationships are kept by handler in memory, move them to the new one
+	if followees, exists := repository.follow[handler]; exists {
+		delete(repository.follow, handler)
+		repository.follow[newHandler] = followees
+	}
+	for _, followees := range repository.follow {
+		if followees[handler] {
+			delete(followees, handler)
+			followees[newHandler] = true
+		}
+	}
+
+	// Taking back a previous handler removes its redirect
+	delete(repository.handles, newHandler)
+	repository.handles[handler] = &UserHandle{Handler: handler, UserID: user.ID, ChangedAt: changedAt, ExpiresAt: expiresAt}
+
+	result := updated
+	return &result, nil
+}
+
+// GetUserByPreviousHandler retrieves the user a previous handler redirects to at a time
+func (repository *InMemoryUserRepository) GetUserByPreviousHandler(ctx context.Context, handler string, at time.Time) (*User, error) {
+	repository.mu.RLock()
+	defer repository.mu.RUnlock()
+
+	handle, exists := repository.handles[handler]
+	if !exists || !handle.ExpiresAt.After(at) {
+		return nil, ErrUserNotFound
+	}
+	for _, user := range repository.users {
+		if user.ID == handle.UserID {
+			userCopy := *user
+			return &userCopy, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// sortByHandler sorts users by their handler, so the follow lists have a stable order
+func sortByHandler(users []User) {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Handler < users[j].Handler
+	})
+}
+
+// copyDeletion returns a copy of a deletion that does not share its services
+func copyDeletion(deletion *UserDeletion) *UserDeletion {
+	result := *deletion
+	result.Services = make([]UserDeletionService, len(deletion.Services))
+	copy(result.Services, deletion.Services)
+	return &result
+}
+
 // Errors
 type RepositoryError struct {
 	message string
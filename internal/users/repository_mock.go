@@ -12,6 +12,7 @@ package users
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,50 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangeHandler mocks base method.
+func (m *MockRepository) ChangeHandler(ctx context.Context, handler, newHandler string, changedAt, expiresAt time.Time) (*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeHandler", ctx, handler, newHandler, changedAt, expiresAt)
+	ret0, _ := ret[0].(*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeHandler indicates an expected call of ChangeHandler.
+func (mr *MockRepositoryMockRecorder) ChangeHandler(ctx, handler, newHandler, changedAt, expiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeHandler", reflect.TypeOf((*MockRepository)(nil).ChangeHandler), ctx, handler, newHandler, changedAt, expiresAt)
+}
+
+// CompleteDeletion mocks base method.
+func (m *MockRepository) CompleteDeletion(ctx context.Context, id, service string, completedAt time.Time) (*UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteDeletion", ctx, id, service, completedAt)
+	ret0, _ := ret[0].(*UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteDeletion indicates an expected call of CompleteDeletion.
+func (mr *MockRepositoryMockRecorder) CompleteDeletion(ctx, id, service, completedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteDeletion", reflect.TypeOf((*MockRepository)(nil).CompleteDeletion), ctx, id, service, completedAt)
+}
+
+// CreateDeletion mocks base method.
+func (m *MockRepository) CreateDeletion(ctx context.Context, deletion *UserDeletion) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeletion", ctx, deletion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeletion indicates an expected call of CreateDeletion.
+func (mr *MockRepositoryMockRecorder) CreateDeletion(ctx, deletion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeletion", reflect.TypeOf((*MockRepository)(nil).CreateDeletion), ctx, deletion)
+}
+
 // CreateUser mocks base method.
 func (m *MockRepository) CreateUser(ctx context.Context, user *User) error {
 	m.ctrl.T.Helper()
@@ -54,6 +99,34 @@ func (mr *MockRepositoryMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockRepository)(nil).CreateUser), ctx, user)
 }
 
+// DeactivateUser mocks base method.
+func (m *MockRepository) DeactivateUser(ctx context.Context, handler string, deactivatedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", ctx, handler, deactivatedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockRepositoryMockRecorder) DeactivateUser(ctx, handler, deactivatedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockRepository)(nil).DeactivateUser), ctx, handler, deactivatedAt)
+}
+
+// DeleteDeactivatedUser mocks base method.
+func (m *MockRepository) DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeactivatedUser", ctx, handler, deactivatedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeactivatedUser indicates an expected call of DeleteDeactivatedUser.
+func (mr *MockRepositoryMockRecorder) DeleteDeactivatedUser(ctx, handler, deactivatedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeactivatedUser", reflect.TypeOf((*MockRepository)(nil).DeleteDeactivatedUser), ctx, handler, deactivatedBefore)
+}
+
 // DeleteUser mocks base method.
 func (m *MockRepository) DeleteUser(ctx context.Context, handler string) error {
 	m.ctrl.T.Helper()
@@ -82,6 +155,36 @@ func (mr *MockRepositoryMockRecorder) FollowUser(ctx, followerHandler, followeeH
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FollowUser", reflect.TypeOf((*MockRepository)(nil).FollowUser), ctx, followerHandler, followeeHandler)
 }
 
+// GetDeactivatedUsers mocks base method.
+func (m *MockRepository) GetDeactivatedUsers(ctx context.Context, before time.Time, limit int) ([]User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeactivatedUsers", ctx, before, limit)
+	ret0, _ := ret[0].([]User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeactivatedUsers indicates an expected call of GetDeactivatedUsers.
+func (mr *MockRepositoryMockRecorder) GetDeactivatedUsers(ctx, before, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeactivatedUsers", reflect.TypeOf((*MockRepository)(nil).GetDeactivatedUsers), ctx, before, limit)
+}
+
+// GetLatestDeletion mocks base method.
+func (m *MockRepository) GetLatestDeletion(ctx context.Context, handler string) (*UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLatestDeletion", ctx, handler)
+	ret0, _ := ret[0].(*UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLatestDeletion indicates an expected call of GetLatestDeletion.
+func (mr *MockRepositoryMockRecorder) GetLatestDeletion(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestDeletion", reflect.TypeOf((*MockRepository)(nil).GetLatestDeletion), ctx, handler)
+}
+
 // GetUser mocks base method.
 func (m *MockRepository) GetUser(ctx context.Context, handler string) (*User, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +200,21 @@ func (mr *MockRepositoryMockRecorder) GetUser(ctx, handler any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockRepository)(nil).GetUser), ctx, handler)
 }
 
+// GetUserByPreviousHandler mocks base method.
+func (m *MockRepository) GetUserByPreviousHandler(ctx context.Context, handler string, at time.Time) (*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByPreviousHandler", ctx, handler, at)
+	ret0, _ := ret[0].(*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByPreviousHandler indicates an expected call of GetUserByPreviousHandler.
+func (mr *MockRepositoryMockRecorder) GetUserByPreviousHandler(ctx, handler, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByPreviousHandler", reflect.TypeOf((*MockRepository)(nil).GetUserByPreviousHandler), ctx, handler, at)
+}
+
 // GetUserFollowees mocks base method.
 func (m *MockRepository) GetUserFollowees(ctx context.Context, followerHandler string) ([]User, error) {
 	m.ctrl.T.Helper()
@@ -127,6 +245,20 @@ func (mr *MockRepositoryMockRecorder) GetUserFollowers(ctx, followeeHandler any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowers", reflect.TypeOf((*MockRepository)(nil).GetUserFollowers), ctx, followeeHandler)
 }
 
+// ReactivateUser mocks base method.
+func (m *MockRepository) ReactivateUser(ctx context.Context, handler string, deactivatedAfter time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", ctx, handler, deactivatedAfter)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockRepositoryMockRecorder) ReactivateUser(ctx, handler, deactivatedAfter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockRepository)(nil).ReactivateUser), ctx, handler, deactivatedAfter)
+}
+
 // UnfollowUser mocks base method.
 func (m *MockRepository) UnfollowUser(ctx context.Context, followerHandler, followeeHandler string) error {
 	m.ctrl.T.Helper()
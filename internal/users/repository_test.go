@@ -3,9 +3,11 @@ package users
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestInMemoryUserRepository_CreateUser(t *testing.T) {
@@ -404,6 +406,181 @@ func TestInMemoryUserRepository_GetUserFollowing(t *testing.T) {
 	}
 }
 
+func TestInMemoryUserRepository_Deletions(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+
+	_, err := repo.GetLatestDeletion(ctx, "user")
+	assert.Equal(t, ErrDeletionNotFound, err)
+
+	requestedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	require.NoError(t, repo.CreateDeletion(ctx, &UserDeletion{
+		ID:          "deletion-1",
+		Handler:     "user",
+		Status:      DeletionStatusPending,
+		Services:    []UserDeletionService{{DeletionID: "deletion-1", Service: "feed"}, {DeletionID: "deletion-1", Service: "tweets"}},
+		RequestedAt: requestedAt,
+	}))
+
+	// Unknown deletions and services are not acknowledged
+	_, err = repo.CompleteDeletion(ctx, "unknown", "feed", requestedAt)
+	assert.Equal(t, ErrDeletionNotFound, err)
+	_, err = repo.CompleteDeletion(ctx, "deletion-1", "unknown", requestedAt)
+	assert.Equal(t, ErrDeletionNotFound, err)
+
+	// The deletion is completed once every service acknowledged it, acknowledging it again is a no-op
+	feedCompletedAt := requestedAt.Add(time.Second)
+	deletion, err := repo.CompleteDeletion(ctx, "deletion-1", "feed", feedCompletedAt)
+	require.NoError(t, err)
+	assert.Equal(t, DeletionStatusPending, deletion.Status)
+	assert.Nil(t, deletion.CompletedAt)
+
+	tweetsCompletedAt := requestedAt.Add(2 * time.Second)
+	deletion, err = repo.CompleteDeletion(ctx, "deletion-1", "tweets", tweetsCompletedAt)
+	require.NoError(t, err)
+	assert.Equal(t, DeletionStatusCompleted, deletion.Status)
+	assert.Equal(t, &tweetsCompletedAt, deletion.CompletedAt)
+
+	deletion, err = repo.CompleteDeletion(ctx, "deletion-1", "feed", requestedAt.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, &feedCompletedAt, deletion.Services[0].CompletedAt)
+	assert.Equal(t, &tweetsCompletedAt, deletion.CompletedAt)
+
+	// The latest deletion of a user that was created again is returned
+	require.NoError(t, repo.CreateDeletion(ctx, &UserDeletion{
+		ID:          "deletion-2",
+		Handler:     "user",
+		Status:      DeletionStatusPending,
+		RequestedAt: requestedAt.Add(time.Hour),
+	}))
+	deletion, err = repo.GetLatestDeletion(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, "deletion-2", deletion.ID)
+}
+
+func TestInMemoryUserRepository_Deactivation(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, handler := range []string{"user1", "user2", "user3"} {
+		require.NoError(t, repo.CreateUser(ctx, &User{Handler: handler}))
+	}
+	require.NoError(t, repo.FollowUser(ctx, "user1", "user2"))
+	require.NoError(t, repo.FollowUser(ctx, "user2", "user1"))
+
+	deactivatedAt := time.Date(2025, 8, 9, 5, 13, 41, 0, time.UTC)
+	assert.Equal(t, ErrUserNotFound, repo.DeactivateUser(ctx, "nonexistent", deactivatedAt))
+	assert.Equal(t, ErrUserNotDeactivated, repo.ReactivateUser(ctx, "user2", deactivatedAt))
+
+	require.NoError(t, repo.DeactivateUser(ctx, "user2", deactivatedAt))
+	assert.Equal(t, ErrUserDeactivated, repo.DeactivateUser(ctx, "user2", deactivatedAt.Add(time.Hour)))
+
+	user, err := repo.GetUser(ctx, "user2")
+	require.NoError(t, err)
+	assert.Equal(t, &deactivatedAt, user.DeactivatedAt)
+
+	// The deactivated user is hidden from the follow lists and cannot be followed
+	followees, err := repo.GetUserFollowees(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, followees)
+	followers, err := repo.GetUserFollowers(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Empty(t, followers)
+	assert.Equal(t, ErrUserNotFound, repo.FollowUser(ctx, "user3", "user2"))
+
+	// Only the users deactivated before the time are returned, the first deactivated first
+	require.NoError(t, repo.DeactivateUser(ctx, "user3", deactivatedAt.Add(-time.Hour)))
+	deactivated, err := repo.GetDeactivatedUsers(ctx, deactivatedAt.Add(time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, deactivated, 2)
+	assert.Equal(t, "user3", deactivated[0].Handler)
+	assert.Equal(t, "user2", deactivated[1].Handler)
+	deactivated, err = repo.GetDeactivatedUsers(ctx, deactivatedAt, 10)
+	require.NoError(t, err)
+	require.Len(t, deactivated, 1)
+	assert.Equal(t, "user3", deactivated[0].Handler)
+
+	// Only the users still deactivated before the time are deleted
+	assert.Equal(t, ErrUserNotFound, repo.DeleteDeactivatedUser(ctx, "user2", deactivatedAt))
+	assert.Equal(t, ErrUserNotFound, repo.DeleteDeactivatedUser(ctx, "user1", deactivatedAt))
+	require.NoError(t, repo.DeleteDeactivatedUser(ctx, "user3", deactivatedAt))
+	_, err = repo.GetUser(ctx, "user3")
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// The users deactivated before the grace period cannot reactivate their account
+	assert.Equal(t, ErrDeactivationExpired, repo.ReactivateUser(ctx, "user2", deactivatedAt.Add(time.Second)))
+
+	// Reactivating the user restores their follow relationships
+	require.NoError(t, repo.ReactivateUser(ctx, "user2", deactivatedAt))
+	user, err = repo.GetUser(ctx, "user2")
+	require.NoError(t, err)
+	followees, err = repo.GetUserFollowees(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{*user}, followees)
+	followers, err = repo.GetUserFollowers(ctx, "user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{*user}, followers)
+}
+
+func TestInMemoryUserRepository_ChangeHandler(t *testing.T) {
+	ctx := context.Background()
+	repo := NewInMemoryUserRepository()
+	for _, handler := range []string{"user1", "user2"} {
+		require.NoError(t, repo.CreateUser(ctx, &User{Handler: handler}))
+	}
+	require.NoError(t, repo.FollowUser(ctx, "user1", "user2"))
+	require.NoError(t, repo.FollowUser(ctx, "user2", "user1"))
+	original, err := repo.GetUser(ctx, "user1")
+	require.NoError(t, err)
+
+	changedAt := time.Now().UTC()
+	expiresAt := changedAt.Add(time.Hour)
+	_, err = repo.ChangeHandler(ctx, "nonexistent", "renamed", changedAt, expiresAt)
+	assert.Equal(t, ErrUserNotFound, err)
+	_, err = repo.ChangeHandler(ctx, "user1", "user2", changedAt, expiresAt)
+	assert.Equal(t, ErrHandlerExists, err)
+
+	user, err := repo.ChangeHandler(ctx, "user1", "renamed", changedAt, expiresAt)
+	require.NoError(t, err)
+	assert.Equal(t, &User{ID: original.ID, Handler: "renamed"}, user)
+
+	// The previous handler redirects to the user until it expires
+	_, err = repo.GetUser(ctx, "user1")
+	assert.Equal(t, ErrUserNotFound, err)
+	redirected, err := repo.GetUserByPreviousHandler(ctx, "user1", changedAt)
+	require.NoError(t, err)
+	assert.Equal(t, user, redirected)
+	_, err = repo.GetUserByPreviousHandler(ctx, "user1", expiresAt)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// The follow relationships are kept
+	followees, err := repo.GetUserFollowees(ctx, "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{*user}, followees)
+	followers, err := repo.GetUserFollowers(ctx, "user2")
+	assert.NoError(t, err)
+	assert.Equal(t, []User{*user}, followers)
+
+	// The user can take back their previous handler, which stops redirecting
+	_, err = repo.ChangeHandler(ctx, "renamed", "user1", changedAt, expiresAt)
+	require.NoError(t, err)
+	_, err = repo.GetUserByPreviousHandler(ctx, "user1", changedAt)
+	assert.Equal(t, ErrUserNotFound, err)
+
+	// Other users cannot take a previous handler until it expires
+	assert.Equal(t, ErrHandlerExists, repo.CreateUser(ctx, &User{Handler: "renamed"}))
+	_, err = repo.ChangeHandler(ctx, "user2", "renamed", changedAt, expiresAt)
+	assert.Equal(t, ErrHandlerExists, err)
+	_, err = repo.ChangeHandler(ctx, "user2", "renamed", expiresAt, expiresAt.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Deleting a user removes the redirects of their previous handlers
+	_, err = repo.GetUserByPreviousHandler(ctx, "user2", expiresAt)
+	assert.NoError(t, err)
+	require.NoError(t, repo.DeleteUser(ctx, "renamed"))
+	_, err = repo.GetUserByPreviousHandler(ctx, "user2", expiresAt)
+	assert.Equal(t, ErrUserNotFound, err)
+}
+
 func TestInMemoryUserRepository_ConcurrentAccess(t *testing.T) {
 	ctx := context.Background()
 
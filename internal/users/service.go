@@ -2,6 +2,14 @@ package users
 
 import (
 	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
+
+	"github.com/google/uuid"
 )
 
 //go:generate mockgen -source=service.go -destination=service_mock.go -package=users
@@ -14,15 +22,36 @@ type Service interface {
 	UnfollowUser(ctx context.Context, followerHandler string, followeeHandler string) error
 	GetUserFollowers(ctx context.Context, followeeHandler string) ([]User, error)
 	GetUserFollowees(ctx context.Context, followerHandler string) ([]User, error)
+	RecordProfileView(ctx context.Context, viewerHandler string, profileHandler string, tweetID string)
+
+	// User Deletions
+	GetUserDeletion(ctx context.Context, handler string) (*UserDeletion, error)
+	CompleteUserDeletion(ctx context.Context, deletionID string, serviceName string) error
+
+	// User Deactivations
+	DeactivateUser(ctx context.Context, handler string) error
+	ReactivateUser(ctx context.Context, handler string) error
+	DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error
+
+	// User Handles
+	ChangeHandler(ctx context.Context, handler string, newHandler string) (*User, error)
 }
 
+// deletionServices are the services that delete their data about a deleted user
+var deletionServices = []string{events.ServiceAnalytics, events.ServiceFeed, events.ServiceTweets}
+
 type service struct {
 	repository Repository
+	publisher  queue.Publisher
+	// deactivationGracePeriod is how long a deactivated user can reactivate their account
+	deactivationGracePeriod time.Duration
 }
 
-func NewService(repository Repository) Service {
+func NewService(repository Repository, publisher queue.Publisher, deactivationGracePeriod time.Duration) Service {
 	return &service{
-		repository: repository,
+		repository:              repository,
+		publisher:               publisher,
+		deactivationGracePeriod: deactivationGracePeriod,
 	}
 }
 
@@ -34,20 +63,172 @@ func (service *service) CreateUser(ctx context.Context, user *User) (*User, erro
 	return user, nil
 }
 
+// GetUser retrieves a user by their handler, or by a previous handler while it redirects to them
 func (service *service) GetUser(ctx context.Context, id string) (*User, error) {
-	return service.repository.GetUser(ctx, id)
+	user, err := service.repository.GetUser(ctx, id)
+	if errors.Is(err, ErrUserNotFound) {
+		return service.repository.GetUserByPreviousHandler(ctx, id, time.Now().UTC())
+	}
+	return user, err
 }
 
+// DeleteUser deletes a user with their follow relationships, and publishes a UserDeleted event to the other services
 func (service *service) DeleteUser(ctx context.Context, id string) error {
-	return service.repository.DeleteUser(ctx, id)
+	if err := service.repository.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+
+	service.userDeleted(ctx, id)
+	return nil
+}
+
+// DeleteDeactivatedUser deletes a user still deactivated since before a time like DeleteUser
+func (service *service) DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error {
+	if err := service.repository.DeleteDeactivatedUser(ctx, handler, deactivatedBefore); err != nil {
+		return err
+	}
+
+	service.userDeleted(ctx, handler)
+	return nil
+}
+
+// userDeleted tracks the deletion of a deleted user and publishes its UserDeleted event
+func (service *service) userDeleted(ctx context.Context, id string) {
+	now := time.Now().UTC()
+	deletion := &UserDeletion{
+		ID:          uuid.New().String(),
+		Handler:     id,
+		Status:      DeletionStatusPending,
+		RequestedAt: now,
+	}
+	for _, serviceName := range deletionServices {
+		deletion.Services = append(deletion.Services, UserDeletionService{DeletionID: deletion.ID, Service: serviceName})
+	}
+	// The user is already deleted, the other services must delete their data even if the deletion cannot be tracked
+	if err := service.repository.CreateDeletion(ctx, deletion); err != nil {
+		log.Printf("failed to track deletion %s of user %s: %v", deletion.ID, id, err)
+	}
+
+	event := events.UserDeleted{
+		DeletionID: deletion.ID,
+		Handler:    id,
+		Timestamp:  now,
+	}
+	if err := service.publisher.Publish(ctx, events.TopicUserDeleted, id, event); err != nil {
+		log.Printf("failed to publish user deleted event for %s: %v", id, err)
+	}
+}
+
+// GetUserDeletion retrieves the last deletion of a user, with the services that deleted their data about the user
+func (service *service) GetUserDeletion(ctx context.Context, handler string) (*UserDeletion, error) {
+	return service.repository.GetLatestDeletion(ctx, handler)
+}
+
+// CompleteUserDeletion records that a service deleted its data about a deleted user
+func (service *service) CompleteUserDeletion(ctx context.Context, deletionID string, serviceName string) error {
+	deletion, err := service.repository.CompleteDeletion(ctx, deletionID, serviceName, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if deletion.Status == DeletionStatusCompleted {
+		log.Printf("deletion %s of user %s completed by every service", deletion.ID, deletion.Handler)
+	}
+	return nil
+}
+
+// DeactivateUser deactivates the account of a user and publishes a UserDeactivated event to the other services
+func (service *service) DeactivateUser(ctx context.Context, handler string) error {
+	now := time.Now().UTC()
+	if err := service.repository.DeactivateUser(ctx, handler, now); err != nil {
+		return err
+	}
+
+	event := events.UserDeactivated{
+		Handler:   handler,
+		Timestamp: now,
+	}
+	if err := service.publisher.Publish(ctx, events.TopicUserDeactivated, handler, event); err != nil {
+		log.Printf("failed to publish user deactivated event for %s: %v", handler, err)
+	}
+	return nil
+}
+
+// ReactivateUser reactivates a user deactivated within the grace period and publishes a UserReactivated event
+func (service *service) ReactivateUser(ctx context.Context, handler string) error {
+	deactivatedAfter := time.Now().UTC().Add(-service.deactivationGracePeriod)
+	if err := service.repository.ReactivateUser(ctx, handler, deactivatedAfter); err != nil {
+		return err
+	}
+
+	event := events.UserReactivated{
+		Handler:   handler,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := service.publisher.Publish(ctx, events.TopicUserReactivated, handler, event); err != nil {
+		log.Printf("failed to publish user reactivated event for %s: %v", handler, err)
+	}
+	return nil
+}
+
+// ChangeHandler changes the handler of a user and publishes a UserHandleChanged event to the other services
+func (service *service) ChangeHandler(ctx context.Context, handler string, newHandler string) (*User, error) {
+	if !handlerPattern.MatchString(newHandler) {
+		return nil, ErrInvalidHandler
+	}
+	if newHandler == handler {
+		return service.repository.GetUser(ctx, handler)
+	}
+
+	now := time.Now().UTC()
+	user, err := service.repository.ChangeHandler(ctx, handler, newHandler, now, now.Add(handlerRedirectPeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	// Keyed by the ID, so the successive changes of the handler of a user keep their order
+	event := events.UserHandleChanged{
+		UserID:     user.ID,
+		OldHandler: handler,
+		NewHandler: newHandler,
+		Timestamp:  now,
+	}
+	if err := service.publisher.Publish(ctx, events.TopicUserHandleChanged, user.ID, event); err != nil {
+		log.Printf("failed to publish user handle changed event for %s -> %s: %v", handler, newHandler, err)
+	}
+	return user, nil
 }
 
 func (service *service) FollowUser(ctx context.Context, followerHandler string, followeeHandler string) error {
-	return service.repository.FollowUser(ctx, followerHandler, followeeHandler)
+	if err := service.repository.FollowUser(ctx, followerHandler, followeeHandler); err != nil {
+		return err
+	}
+
+	service.publishFollowChanged(ctx, events.TypeUserFollowed, followerHandler, followeeHandler)
+	return nil
 }
 
 func (service *service) UnfollowUser(ctx context.Context, followerHandler string, followeeHandler string) error {
-	return service.repository.UnfollowUser(ctx, followerHandler, followeeHandler)
+	if err := service.repository.UnfollowUser(ctx, followerHandler, followeeHandler); err != nil {
+		return err
+	}
+
+	service.publishFollowChanged(ctx, events.TypeUserUnfollowed, followerHandler, followeeHandler)
+	return nil
+}
+
+// publishFollowChanged notifies the analytics service that a user followed or unfollowed another one, keyed by the followee
+func (service *service) publishFollowChanged(ctx context.Context, eventType string, followerHandler string, followeeHandler string) {
+	event := events.Event{
+		ID:            uuid.New().String(),
+		EventType:     eventType,
+		Handler:       followerHandler,
+		TargetHandler: followeeHandler,
+		Timestamp:     time.Now().UTC(),
+	}
+	if err := service.publisher.Publish(ctx, events.TopicFollowChanged, followeeHandler, event); err != nil {
+		log.Printf("failed to publish %s event for %s -> %s: %v", eventType, followerHandler, followeeHandler, err)
+	}
 }
 
 func (service *service) GetUserFollowers(ctx context.Context, followeeHandler string) ([]User, error) {
@@ -57,3 +238,21 @@ func (service *service) GetUserFollowers(ctx context.Context, followeeHandler st
 func (service *service) GetUserFollowees(ctx context.Context, followerHandler string) ([]User, error) {
 	return service.repository.GetUserFollowees(ctx, followerHandler)
 }
+
+// RecordProfileView notifies the analytics service that a user visited another profile from a tweet
+func (service *service) RecordProfileView(ctx context.Context, viewerHandler string, profileHandler string, tweetID string) {
+	if viewerHandler == "" || viewerHandler == profileHandler || tweetID == "" {
+		return
+	}
+
+	event := events.Event{
+		ID:        uuid.New().String(),
+		EventType: events.TypeProfileViewed,
+		Handler:   viewerHandler,
+		TweetID:   tweetID,
+		Timestamp: time.Now().UTC(),
+	}
+	if err := service.publisher.Publish(ctx, events.TopicProfileViewed, viewerHandler, event); err != nil {
+		log.Printf("failed to publish profile viewed event for %s: %v", profileHandler, err)
+	}
+}
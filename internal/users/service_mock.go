@@ -12,6 +12,7 @@ package users
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -40,6 +41,35 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// ChangeHandler mocks base method.
+func (m *MockService) ChangeHandler(ctx context.Context, handler, newHandler string) (*User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeHandler", ctx, handler, newHandler)
+	ret0, _ := ret[0].(*User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeHandler indicates an expected call of ChangeHandler.
+func (mr *MockServiceMockRecorder) ChangeHandler(ctx, handler, newHandler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeHandler", reflect.TypeOf((*MockService)(nil).ChangeHandler), ctx, handler, newHandler)
+}
+
+// CompleteUserDeletion mocks base method.
+func (m *MockService) CompleteUserDeletion(ctx context.Context, deletionID, serviceName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteUserDeletion", ctx, deletionID, serviceName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteUserDeletion indicates an expected call of CompleteUserDeletion.
+func (mr *MockServiceMockRecorder) CompleteUserDeletion(ctx, deletionID, serviceName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteUserDeletion", reflect.TypeOf((*MockService)(nil).CompleteUserDeletion), ctx, deletionID, serviceName)
+}
+
 // CreateUser mocks base method.
 func (m *MockService) CreateUser(ctx context.Context, user *User) (*User, error) {
 	m.ctrl.T.Helper()
@@ -55,6 +85,34 @@ func (mr *MockServiceMockRecorder) CreateUser(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockService)(nil).CreateUser), ctx, user)
 }
 
+// DeactivateUser mocks base method.
+func (m *MockService) DeactivateUser(ctx context.Context, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", ctx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockServiceMockRecorder) DeactivateUser(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockService)(nil).DeactivateUser), ctx, handler)
+}
+
+// DeleteDeactivatedUser mocks base method.
+func (m *MockService) DeleteDeactivatedUser(ctx context.Context, handler string, deactivatedBefore time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeactivatedUser", ctx, handler, deactivatedBefore)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeactivatedUser indicates an expected call of DeleteDeactivatedUser.
+func (mr *MockServiceMockRecorder) DeleteDeactivatedUser(ctx, handler, deactivatedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeactivatedUser", reflect.TypeOf((*MockService)(nil).DeleteDeactivatedUser), ctx, handler, deactivatedBefore)
+}
+
 // DeleteUser mocks base method.
 func (m *MockService) DeleteUser(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -98,6 +156,21 @@ func (mr *MockServiceMockRecorder) GetUser(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockService)(nil).GetUser), ctx, id)
 }
 
+// GetUserDeletion mocks base method.
+func (m *MockService) GetUserDeletion(ctx context.Context, handler string) (*UserDeletion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDeletion", ctx, handler)
+	ret0, _ := ret[0].(*UserDeletion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDeletion indicates an expected call of GetUserDeletion.
+func (mr *MockServiceMockRecorder) GetUserDeletion(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDeletion", reflect.TypeOf((*MockService)(nil).GetUserDeletion), ctx, handler)
+}
+
 // GetUserFollowees mocks base method.
 func (m *MockService) GetUserFollowees(ctx context.Context, followerHandler string) ([]User, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +201,32 @@ func (mr *MockServiceMockRecorder) GetUserFollowers(ctx, followeeHandler any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserFollowers", reflect.TypeOf((*MockService)(nil).GetUserFollowers), ctx, followeeHandler)
 }
 
+// ReactivateUser mocks base method.
+func (m *MockService) ReactivateUser(ctx context.Context, handler string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", ctx, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockServiceMockRecorder) ReactivateUser(ctx, handler any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockService)(nil).ReactivateUser), ctx, handler)
+}
+
+// RecordProfileView mocks base method.
+func (m *MockService) RecordProfileView(ctx context.Context, viewerHandler, profileHandler, tweetID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RecordProfileView", ctx, viewerHandler, profileHandler, tweetID)
+}
+
+// RecordProfileView indicates an expected call of RecordProfileView.
+func (mr *MockServiceMockRecorder) RecordProfileView(ctx, viewerHandler, profileHandler, tweetID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordProfileView", reflect.TypeOf((*MockService)(nil).RecordProfileView), ctx, viewerHandler, profileHandler, tweetID)
+}
+
 // UnfollowUser mocks base method.
 func (m *MockService) UnfollowUser(ctx context.Context, followerHandler, followeeHandler string) error {
 	m.ctrl.T.Helper()
@@ -2,7 +2,13 @@ package users
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/lucas-soria/microblogging/pkg/events"
+	"github.com/lucas-soria/microblogging/pkg/queue"
 
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -15,7 +21,7 @@ func TestUserService_CreateUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 
 	user := &User{
 		Handler:   "testuser",
@@ -76,7 +82,7 @@ func TestUserService_GetUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 
 	user := &User{
 		Handler:   "testuser",
@@ -106,18 +112,48 @@ func TestUserService_GetUser(t *testing.T) {
 				err:  nil,
 			},
 		},
+		{
+			name: "retrieval by a previous handler",
+			expectations: func(user *User) {
+				mockRepo.EXPECT().GetUser(ctx, user.Handler).
+					Return(nil, ErrUserNotFound).
+					Times(1)
+				mockRepo.EXPECT().GetUserByPreviousHandler(ctx, user.Handler, gomock.Any()).
+					Return(&User{Handler: "newuser"}, nil).
+					Times(1)
+			},
+			want: want{
+				user: &User{Handler: "newuser"},
+				err:  nil,
+			},
+		},
 		{
 			name: "failed user retrieval",
 			expectations: func(user *User) {
 				mockRepo.EXPECT().GetUser(ctx, user.Handler).
 					Return(nil, ErrUserNotFound).
 					Times(1)
+				mockRepo.EXPECT().GetUserByPreviousHandler(ctx, user.Handler, gomock.Any()).
+					Return(nil, ErrUserNotFound).
+					Times(1)
 			},
 			want: want{
 				user: nil,
 				err:  ErrUserNotFound,
 			},
 		},
+		{
+			name: "repository error",
+			expectations: func(user *User) {
+				mockRepo.EXPECT().GetUser(ctx, user.Handler).
+					Return(nil, errors.New("database unavailable")).
+					Times(1)
+			},
+			want: want{
+				user: nil,
+				err:  errors.New("database unavailable"),
+			},
+		},
 	}
 	for _, tc := range tt {
 		t.Run(tc.name, func(t *testing.T) {
@@ -137,7 +173,8 @@ func TestUserService_DeleteUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
 
 	userID := "testid"
 
@@ -152,10 +189,43 @@ func TestUserService_DeleteUser(t *testing.T) {
 	}{
 		{
 			name: "successful user deletion",
+			expectations: func() {
+				var deletionID string
+				mockRepo.EXPECT().DeleteUser(ctx, userID).
+					Return(nil).
+					Times(1)
+				mockRepo.EXPECT().CreateDeletion(ctx, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, deletion *UserDeletion) error {
+						deletionID = deletion.ID
+						assert.Equal(t, userID, deletion.Handler)
+						assert.Equal(t, DeletionStatusPending, deletion.Status)
+						assert.Len(t, deletion.Services, 3)
+						return nil
+					})
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDeleted, userID, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.UserDeleted)
+						assert.Equal(t, deletionID, event.DeletionID)
+						assert.Equal(t, userID, event.Handler)
+						return nil
+					})
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "successful user deletion - tracking and publishing failures are ignored",
 			expectations: func() {
 				mockRepo.EXPECT().DeleteUser(ctx, userID).
 					Return(nil).
 					Times(1)
+				mockRepo.EXPECT().CreateDeletion(ctx, gomock.Any()).
+					Return(errors.New("database unavailable"))
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDeleted, userID, gomock.Any()).
+					Return(errors.New("queue unavailable"))
 			},
 			want: want{
 				err: nil,
@@ -184,6 +254,212 @@ func TestUserService_DeleteUser(t *testing.T) {
 	}
 }
 
+func TestUserService_CompleteUserDeletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := NewMockRepository(ctrl)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
+
+	deletionID := "deletion-1"
+
+	type want struct {
+		err error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "successful completion",
+			expectations: func() {
+				mockRepo.EXPECT().CompleteDeletion(ctx, deletionID, events.ServiceFeed, gomock.Any()).
+					Return(&UserDeletion{ID: deletionID, Handler: "testid", Status: DeletionStatusCompleted}, nil).
+					Times(1)
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "failed completion - deletion not found",
+			expectations: func() {
+				mockRepo.EXPECT().CompleteDeletion(ctx, deletionID, events.ServiceFeed, gomock.Any()).
+					Return(nil, ErrDeletionNotFound).
+					Times(1)
+			},
+			want: want{
+				err: ErrDeletionNotFound,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.CompleteUserDeletion(ctx, deletionID, events.ServiceFeed)
+			assert.Equal(t, tc.want.err, err)
+		})
+	}
+}
+
+func TestUserService_DeactivateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
+
+	userID := "testid"
+
+	type want struct {
+		err error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "successful deactivation",
+			expectations: func() {
+				var deactivatedAt time.Time
+				mockRepo.EXPECT().DeactivateUser(ctx, userID, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, handler string, at time.Time) error {
+						deactivatedAt = at
+						return nil
+					})
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDeactivated, userID, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.UserDeactivated)
+						assert.Equal(t, userID, event.Handler)
+						assert.Equal(t, deactivatedAt, event.Timestamp)
+						return nil
+					})
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "successful deactivation - publishing failure is ignored",
+			expectations: func() {
+				mockRepo.EXPECT().DeactivateUser(ctx, userID, gomock.Any()).
+					Return(nil)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserDeactivated, userID, gomock.Any()).
+					Return(errors.New("queue unavailable"))
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "failed deactivation - already deactivated",
+			expectations: func() {
+				mockRepo.EXPECT().DeactivateUser(ctx, userID, gomock.Any()).
+					Return(ErrUserDeactivated)
+			},
+			want: want{
+				err: ErrUserDeactivated,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.DeactivateUser(ctx, userID)
+			assert.Equal(t, tc.want.err, err)
+		})
+	}
+}
+
+func TestUserService_ReactivateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
+
+	userID := "testid"
+
+	type want struct {
+		err error
+	}
+
+	tt := []struct {
+		name         string
+		expectations func()
+		want         want
+	}{
+		{
+			name: "successful reactivation",
+			expectations: func() {
+				mockRepo.EXPECT().ReactivateUser(ctx, userID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, deactivatedAfter time.Time) error {
+						// Only the users deactivated within the grace period can reactivate their account
+						assert.WithinDuration(t, time.Now().Add(-24*time.Hour), deactivatedAfter, time.Minute)
+						return nil
+					})
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserReactivated, userID, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.UserReactivated)
+						assert.Equal(t, userID, event.Handler)
+						return nil
+					})
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "failed reactivation - not deactivated",
+			expectations: func() {
+				mockRepo.EXPECT().ReactivateUser(ctx, userID, gomock.Any()).
+					Return(ErrUserNotDeactivated)
+			},
+			want: want{
+				err: ErrUserNotDeactivated,
+			},
+		},
+		{
+			name: "failed reactivation - grace period over",
+			expectations: func() {
+				mockRepo.EXPECT().ReactivateUser(ctx, userID, gomock.Any()).
+					Return(ErrDeactivationExpired)
+			},
+			want: want{
+				err: ErrDeactivationExpired,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			err := service.ReactivateUser(ctx, userID)
+			assert.Equal(t, tc.want.err, err)
+		})
+	}
+}
+
 func TestUserService_FollowUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -191,7 +467,8 @@ func TestUserService_FollowUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
 
 	follower := "follower1"
 	followee := "followee1"
@@ -211,6 +488,29 @@ func TestUserService_FollowUser(t *testing.T) {
 				mockRepo.EXPECT().FollowUser(ctx, follower, followee).
 					Return(nil).
 					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicFollowChanged, followee, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.Event)
+						assert.Equal(t, events.TypeUserFollowed, event.EventType)
+						assert.Equal(t, follower, event.Handler)
+						assert.Equal(t, followee, event.TargetHandler)
+						return nil
+					})
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "successful follow - publishing failure is ignored",
+			expectations: func() {
+				mockRepo.EXPECT().FollowUser(ctx, follower, followee).
+					Return(nil).
+					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicFollowChanged, followee, gomock.Any()).
+					Return(errors.New("queue unavailable"))
 			},
 			want: want{
 				err: nil,
@@ -246,7 +546,8 @@ func TestUserService_UnfollowUser(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
 
 	follower := "follower1"
 	followee := "followee1"
@@ -266,6 +567,29 @@ func TestUserService_UnfollowUser(t *testing.T) {
 				mockRepo.EXPECT().UnfollowUser(ctx, follower, followee).
 					Return(nil).
 					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicFollowChanged, followee, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.Event)
+						assert.Equal(t, events.TypeUserUnfollowed, event.EventType)
+						assert.Equal(t, follower, event.Handler)
+						assert.Equal(t, followee, event.TargetHandler)
+						return nil
+					})
+			},
+			want: want{
+				err: nil,
+			},
+		},
+		{
+			name: "successful unfollow - publishing failure is ignored",
+			expectations: func() {
+				mockRepo.EXPECT().UnfollowUser(ctx, follower, followee).
+					Return(nil).
+					Times(1)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicFollowChanged, followee, gomock.Any()).
+					Return(errors.New("queue unavailable"))
 			},
 			want: want{
 				err: nil,
@@ -294,6 +618,135 @@ func TestUserService_UnfollowUser(t *testing.T) {
 	}
 }
 
+func TestUserService_ChangeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
+
+	renamed := &User{ID: "user-id", Handler: "newuser"}
+
+	type want struct {
+		user *User
+		err  error
+	}
+
+	tt := []struct {
+		name         string
+		newHandler   string
+		expectations func()
+		want         want
+	}{
+		{
+			name:       "successful handler change",
+			newHandler: "newuser",
+			expectations: func() {
+				var changedAt time.Time
+				mockRepo.EXPECT().ChangeHandler(ctx, "olduser", "newuser", gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, handler, newHandler string, at, expiresAt time.Time) (*User, error) {
+						changedAt = at
+						assert.Equal(t, handlerRedirectPeriod, expiresAt.Sub(at))
+						return renamed, nil
+					})
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserHandleChanged, "user-id", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						assert.Equal(t, events.UserHandleChanged{
+							UserID:     "user-id",
+							OldHandler: "olduser",
+							NewHandler: "newuser",
+							Timestamp:  changedAt,
+						}, payload)
+						return nil
+					})
+			},
+			want: want{
+				user: renamed,
+				err:  nil,
+			},
+		},
+		{
+			name:       "successful handler change - publishing failure is ignored",
+			newHandler: "newuser",
+			expectations: func() {
+				mockRepo.EXPECT().ChangeHandler(ctx, "olduser", "newuser", gomock.Any(), gomock.Any()).
+					Return(renamed, nil)
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicUserHandleChanged, "user-id", gomock.Any()).
+					Return(errors.New("queue unavailable"))
+			},
+			want: want{
+				user: renamed,
+				err:  nil,
+			},
+		},
+		{
+			name:       "failed handler change - handler taken",
+			newHandler: "newuser",
+			expectations: func() {
+				mockRepo.EXPECT().ChangeHandler(ctx, "olduser", "newuser", gomock.Any(), gomock.Any()).
+					Return(nil, ErrHandlerExists)
+			},
+			want: want{
+				user: nil,
+				err:  ErrHandlerExists,
+			},
+		},
+		{
+			name:       "successful handler change - current handler is a no-op",
+			newHandler: "olduser",
+			expectations: func() {
+				mockRepo.EXPECT().GetUser(ctx, "olduser").Return(&User{ID: "user-id", Handler: "olduser"}, nil)
+			},
+			want: want{
+				user: &User{ID: "user-id", Handler: "olduser"},
+				err:  nil,
+			},
+		},
+		{
+			name:         "failed handler change - empty handler",
+			newHandler:   "",
+			expectations: func() {},
+			want: want{
+				user: nil,
+				err:  ErrInvalidHandler,
+			},
+		},
+		{
+			name:         "failed handler change - invalid characters",
+			newHandler:   "new user!",
+			expectations: func() {},
+			want: want{
+				user: nil,
+				err:  ErrInvalidHandler,
+			},
+		},
+		{
+			name:         "failed handler change - too long",
+			newHandler:   strings.Repeat("a", 65),
+			expectations: func() {},
+			want: want{
+				user: nil,
+				err:  ErrInvalidHandler,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			user, err := service.ChangeHandler(ctx, "olduser", tc.newHandler)
+			assert.Equal(t, tc.want.err, err)
+			assert.Equal(t, tc.want.user, user)
+		})
+	}
+}
+
 func TestUserService_GetUserFollowers(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -301,7 +754,7 @@ func TestUserService_GetUserFollowers(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 
 	userID := "testuser"
 	followers := []User{
@@ -363,7 +816,7 @@ func TestUserService_GetUserFollowees(t *testing.T) {
 	ctx := context.Background()
 
 	mockRepo := NewMockRepository(ctrl)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, queue.NewInMemoryQueue(), 24*time.Hour)
 
 	userID := "testuser"
 	followees := []User{
@@ -417,3 +870,67 @@ func TestUserService_GetUserFollowees(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_RecordProfileView(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockPublisher := queue.NewMockPublisher(ctrl)
+	service := NewService(mockRepo, mockPublisher, 24*time.Hour)
+
+	tt := []struct {
+		name           string
+		expectations   func()
+		viewerHandler  string
+		profileHandler string
+		tweetID        string
+	}{
+		{
+			name: "visit from a tweet is published",
+			expectations: func() {
+				mockPublisher.EXPECT().
+					Publish(ctx, events.TopicProfileViewed, "viewer", gomock.Any()).
+					DoAndReturn(func(ctx context.Context, topic, key string, payload any) error {
+						event := payload.(events.Event)
+						assert.Equal(t, events.TypeProfileViewed, event.EventType)
+						assert.Equal(t, "tweet1", event.TweetID)
+						return nil
+					})
+			},
+			viewerHandler:  "viewer",
+			profileHandler: "author",
+			tweetID:        "tweet1",
+		},
+		{
+			name:           "visit not coming from a tweet is not published",
+			expectations:   func() {},
+			viewerHandler:  "viewer",
+			profileHandler: "author",
+			tweetID:        "",
+		},
+		{
+			name:           "visit to the own profile is not published",
+			expectations:   func() {},
+			viewerHandler:  "author",
+			profileHandler: "author",
+			tweetID:        "tweet1",
+		},
+		{
+			name:           "anonymous visit is not published",
+			expectations:   func() {},
+			viewerHandler:  "",
+			profileHandler: "author",
+			tweetID:        "tweet1",
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expectations()
+
+			service.RecordProfileView(ctx, tc.viewerHandler, tc.profileHandler, tc.tweetID)
+		})
+	}
+}
@@ -1,10 +1,14 @@
 package users
 
+import "time"
+
 // User represents the user domain and DB model merged
 type User struct {
-	Handler   string `gorm:"primaryKey;type:varchar(255);uniqueIndex" json:"handler"`
-	FirstName string `gorm:"type:varchar(255);not null" json:"first_name"`
-	LastName  string `gorm:"type:varchar(255);not null" json:"last_name"`
+	ID            string     `gorm:"primaryKey;type:uuid" json:"id"`
+	Handler       string     `gorm:"type:varchar(255);not null;uniqueIndex" json:"handler"`
+	FirstName     string     `gorm:"type:varchar(255);not null" json:"first_name"`
+	LastName      string     `gorm:"type:varchar(255);not null" json:"last_name"`
+	DeactivatedAt *time.Time `gorm:"index" json:"deactivated_at,omitempty"` // Set while the account is deactivated
 }
 
 // TableName specifies the table name for the User
@@ -12,10 +16,15 @@ func (User) TableName() string {
 	return "users"
 }
 
-// UserFollow represents the follow relationship between users
+// IsDeactivated reports whether the user deactivated their account
+func (user *User) IsDeactivated() bool {
+	return user.DeactivatedAt != nil
+}
+
+// UserFollow represents the follow relationship between users, by their IDs so it survives the handler changes
 type UserFollow struct {
-	FollowerHandler string `gorm:"primaryKey;type:varchar(255);not null"`
-	FolloweeHandler string `gorm:"primaryKey;type:varchar(255);not null"`
+	FollowerID string `gorm:"primaryKey;type:uuid;not null"`
+	FolloweeID string `gorm:"primaryKey;type:uuid;not null"`
 }
 
 // TableName specifies the table name for the UserFollow
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleAdmin is the role of the administrators, who can manage the account of any user
+const RoleAdmin = "admin"
+
+// RoleSignatureHeader is the header with the signature of the X-User-Role header, set by the gateway
+const RoleSignatureHeader = "X-User-Role-Signature"
+
+// Caller is the user that sent a request, identified by the X-User-Id header with the role of the X-User-Role header
+type Caller struct {
+	ID   string
+	Role string
+}
+
+// IsAdmin reports whether the caller is an administrator
+func (caller Caller) IsAdmin() bool {
+	return caller.Role == RoleAdmin
+}
+
+// CanManageUser reports whether the caller owns the account of a user or is an administrator
+func (caller Caller) CanManageUser(handler string) bool {
+	if caller.ID == "" {
+		return false
+	}
+	return caller.ID == handler || caller.IsAdmin()
+}
+
+// SignRole signs the role of a user with the secret shared with the gateway
+func SignRole(secret, id, role string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(id + "\x00" + role))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Authenticate returns the caller of a request from its headers, trusting only the roles signed with the secret
+func Authenticate(header http.Header, secret string) Caller {
+	caller := Caller{ID: header.Get("X-User-Id")}
+	role := header.Get("X-User-Role")
+	if secret == "" || role == "" {
+		return caller
+	}
+	signature, err := hex.DecodeString(header.Get(RoleSignatureHeader))
+	if err != nil {
+		return caller
+	}
+	expected, _ := hex.DecodeString(SignRole(secret, caller.ID, role))
+	if hmac.Equal(signature, expected) {
+		caller.Role = role
+	}
+	return caller
+}
+
+// SetCaller stores the caller of a request in its context
+func SetCaller(ctx *gin.Context, caller Caller) {
+	ctx.Set("user_id", caller.ID)
+	ctx.Set("user_role", caller.Role)
+}
+
+// CallerFrom returns the caller of a request stored in its context by the auth middleware
+func CallerFrom(ctx *gin.Context) Caller {
+	return Caller{ID: ctx.GetString("user_id"), Role: ctx.GetString("user_role")}
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaller_CanManageUser(t *testing.T) {
+	tt := []struct {
+		name    string
+		caller  Caller
+		handler string
+		want    bool
+	}{
+		{
+			name:    "owner",
+			caller:  Caller{ID: "user1"},
+			handler: "user1",
+			want:    true,
+		},
+		{
+			name:    "other user",
+			caller:  Caller{ID: "user2"},
+			handler: "user1",
+			want:    false,
+		},
+		{
+			name:    "other user with another role",
+			caller:  Caller{ID: "user2", Role: "moderator"},
+			handler: "user1",
+			want:    false,
+		},
+		{
+			name:    "admin",
+			caller:  Caller{ID: "admin1", Role: RoleAdmin},
+			handler: "user1",
+			want:    true,
+		},
+		{
+			name:    "anonymous admin",
+			caller:  Caller{Role: RoleAdmin},
+			handler: "user1",
+			want:    false,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.caller.CanManageUser(tc.handler))
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	const secret = "secret"
+
+	tt := []struct {
+		name    string
+		headers map[string]string
+		secret  string
+		want    Caller
+	}{
+		{
+			name:    "signed role",
+			headers: map[string]string{"X-User-Id": "admin1", "X-User-Role": RoleAdmin, RoleSignatureHeader: SignRole(secret, "admin1", RoleAdmin)},
+			secret:  secret,
+			want:    Caller{ID: "admin1", Role: RoleAdmin},
+		},
+		{
+			name:    "unsigned role",
+			headers: map[string]string{"X-User-Id": "user1", "X-User-Role": RoleAdmin},
+			secret:  secret,
+			want:    Caller{ID: "user1"},
+		},
+		{
+			name:    "role signed for another user",
+			headers: map[string]string{"X-User-Id": "user1", "X-User-Role": RoleAdmin, RoleSignatureHeader: SignRole(secret, "admin1", RoleAdmin)},
+			secret:  secret,
+			want:    Caller{ID: "user1"},
+		},
+		{
+			name:    "role signed with another secret",
+			headers: map[string]string{"X-User-Id": "admin1", "X-User-Role": RoleAdmin, RoleSignatureHeader: SignRole("other", "admin1", RoleAdmin)},
+			secret:  secret,
+			want:    Caller{ID: "admin1"},
+		},
+		{
+			name:    "without secret",
+			headers: map[string]string{"X-User-Id": "admin1", "X-User-Role": RoleAdmin, RoleSignatureHeader: SignRole("", "admin1", RoleAdmin)},
+			want:    Caller{ID: "admin1"},
+		},
+		{
+			name: "anonymous",
+			want: Caller{},
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			for key, value := range tc.headers {
+				header.Set(key, value)
+			}
+			assert.Equal(t, tc.want, Authenticate(header, tc.secret))
+		})
+	}
+}
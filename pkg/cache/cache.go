@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//go:generate mockgen -source=cache.go -destination=cache_mock.go -package=cache
+
+// ErrCacheMiss is returned when a key is not in the cache
+var ErrCacheMiss = errors.New("cache miss")
+
+// Cache defines the interface for key-value cache operations
+type Cache interface {
+	Get(ctx context.Context, key string, dest any) error
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+type item struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// InMemoryCache is an in-memory implementation of the Cache interface
+type InMemoryCache struct {
+	mu    sync.RWMutex
+	items map[string]item
+}
+
+// NewInMemoryCache creates a new in-memory cache
+func NewInMemoryCache() *InMemoryCache {
+	return &InMemoryCache{
+		items: make(map[string]item),
+	}
+}
+
+// Get decodes the value stored under key into dest
+func (cache *InMemoryCache) Get(ctx context.Context, key string, dest any) error {
+	cache.mu.RLock()
+	stored, exists := cache.items[key]
+	cache.mu.RUnlock()
+
+	if !exists || (!stored.expiresAt.IsZero() && time.Now().After(stored.expiresAt)) {
+		return ErrCacheMiss
+	}
+
+	if err := json.Unmarshal(stored.value, dest); err != nil {
+		return fmt.Errorf("failed to decode cached value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// Set stores value under key, a zero ttl means the value never expires
+func (cache *InMemoryCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s: %w", key, err)
+	}
+
+	stored := item{value: bytes}
+	if ttl > 0 {
+		stored.expiresAt = time.Now().Add(ttl)
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	cache.items[key] = stored
+	return nil
+}
+
+// Delete removes key from the cache
+func (cache *InMemoryCache) Delete(ctx context.Context, key string) error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	delete(cache.items, key)
+	return nil
+}
@@ -0,0 +1,51 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewInMemoryCache()
+
+	var popular []PopularTweet
+	assert.ErrorIs(t, cache.Get(ctx, KeyPopularTweets, &popular), ErrCacheMiss)
+
+	stored := []PopularTweet{{TweetID: "1", Handler: "user1", Score: 1.5}}
+	require.NoError(t, cache.Set(ctx, KeyPopularTweets, stored, time.Minute))
+	require.NoError(t, cache.Get(ctx, KeyPopularTweets, &popular))
+	assert.Equal(t, stored, popular)
+
+	require.NoError(t, cache.Delete(ctx, KeyPopularTweets))
+	assert.ErrorIs(t, cache.Get(ctx, KeyPopularTweets, &popular), ErrCacheMiss)
+}
+
+func TestInMemoryCache_Expiration(t *testing.T) {
+	ctx := context.Background()
+	cache := NewInMemoryCache()
+
+	require.NoError(t, cache.Set(ctx, "expiring", "value", time.Millisecond))
+	require.NoError(t, cache.Set(ctx, "permanent", "value", 0))
+	time.Sleep(5 * time.Millisecond)
+
+	var value string
+	assert.ErrorIs(t, cache.Get(ctx, "expiring", &value), ErrCacheMiss)
+	require.NoError(t, cache.Get(ctx, "permanent", &value))
+	assert.Equal(t, "value", value)
+}
+
+func TestInMemoryCache_InvalidValue(t *testing.T) {
+	ctx := context.Background()
+	cache := NewInMemoryCache()
+
+	assert.Error(t, cache.Set(ctx, "key", make(chan int), time.Minute))
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Minute))
+	var value int
+	assert.Error(t, cache.Get(ctx, "key", &value))
+}
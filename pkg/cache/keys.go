@@ -0,0 +1,13 @@
+package cache
+
+// Keys shared between services
+const (
+	KeyPopularTweets = "popular_tweets"
+)
+
+// PopularTweet is an entry of the popular tweets list, ordered by score
+type PopularTweet struct {
+	TweetID string  `json:"tweet_id"`
+	Handler string  `json:"handler"`
+	Score   float64 `json:"score"`
+}
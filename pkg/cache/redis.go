@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCache is a Redis implementation of the Cache interface, shared by every replica of the services
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache creates a new Redis cache connected to the server at addr
+func NewRedisCache(addr string) *RedisCache {
+	return &RedisCache{
+		client: redis.NewClient(&redis.Options{Addr: addr}),
+	}
+}
+
+// Get decodes the value stored under key into dest
+func (cache *RedisCache) Get(ctx context.Context, key string, dest any) error {
+	bytes, err := cache.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get cached value for %s: %w", key, err)
+	}
+
+	if err := json.Unmarshal(bytes, dest); err != nil {
+		return fmt.Errorf("failed to decode cached value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// Set stores value under key, a zero ttl means the value never expires
+func (cache *RedisCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode value for %s: %w", key, err)
+	}
+
+	if err := cache.client.Set(ctx, key, bytes, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key from the cache
+func (cache *RedisCache) Delete(ctx context.Context, key string) error {
+	if err := cache.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete cached value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close closes the connections to the server
+func (cache *RedisCache) Close() error {
+	return cache.client.Close()
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRedisCache creates a Redis cache connected to an in-process server, closed at the end of the test
+func newTestRedisCache(t *testing.T) (*RedisCache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	cache := NewRedisCache(server.Addr())
+	t.Cleanup(func() { _ = cache.Close() })
+	return cache, server
+}
+
+func TestRedisCache(t *testing.T) {
+	ctx := context.Background()
+	cache, _ := newTestRedisCache(t)
+
+	var popular []PopularTweet
+	assert.ErrorIs(t, cache.Get(ctx, KeyPopularTweets, &popular), ErrCacheMiss)
+
+	stored := []PopularTweet{{TweetID: "1", Handler: "user1", Score: 1.5}}
+	require.NoError(t, cache.Set(ctx, KeyPopularTweets, stored, time.Minute))
+	require.NoError(t, cache.Get(ctx, KeyPopularTweets, &popular))
+	assert.Equal(t, stored, popular)
+
+	require.NoError(t, cache.Delete(ctx, KeyPopularTweets))
+	assert.ErrorIs(t, cache.Get(ctx, KeyPopularTweets, &popular), ErrCacheMiss)
+}
+
+func TestRedisCache_SharedBetweenServices(t *testing.T) {
+	ctx := context.Background()
+	analyticsCache, server := newTestRedisCache(t)
+	feedCache := NewRedisCache(server.Addr())
+	defer feedCache.Close()
+
+	// The popular tweets ranked by analytics are read by the feed
+	stored := []PopularTweet{{TweetID: "1", Handler: "user1", Score: 1.5}}
+	require.NoError(t, analyticsCache.Set(ctx, KeyPopularTweets, stored, time.Minute))
+
+	var popular []PopularTweet
+	require.NoError(t, feedCache.Get(ctx, KeyPopularTweets, &popular))
+	assert.Equal(t, stored, popular)
+}
+
+func TestRedisCache_Expiration(t *testing.T) {
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+
+	require.NoError(t, cache.Set(ctx, "expiring", "value", time.Minute))
+	require.NoError(t, cache.Set(ctx, "permanent", "value", 0))
+	server.FastForward(2 * time.Minute)
+
+	var value string
+	assert.ErrorIs(t, cache.Get(ctx, "expiring", &value), ErrCacheMiss)
+	require.NoError(t, cache.Get(ctx, "permanent", &value))
+	assert.Equal(t, "value", value)
+}
+
+func TestRedisCache_InvalidValue(t *testing.T) {
+	ctx := context.Background()
+	cache, server := newTestRedisCache(t)
+
+	assert.Error(t, cache.Set(ctx, "key", make(chan int), time.Minute))
+
+	require.NoError(t, cache.Set(ctx, "key", "value", time.Minute))
+	var value int
+	assert.Error(t, cache.Get(ctx, "key", &value))
+
+	// An unreachable server is an error, not a miss
+	server.Close()
+	err := cache.Get(ctx, "key", &value)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCacheMiss)
+}
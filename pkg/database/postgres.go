@@ -2,14 +2,24 @@ package database
 
 import (
 	"context"
+	"crypto/md5"
 	"fmt"
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// LegacyUserID is the SQL expression of the ID of a user created before the users had an ID, shared by every service
+const LegacyUserID = "md5('user:' || handler)::uuid"
+
+// LegacyUserIDOf computes the LegacyUserID of a handler
+func LegacyUserIDOf(handler string) string {
+	return uuid.UUID(md5.Sum([]byte("user:" + handler))).String()
+}
+
 type DBClient interface {
 	AutoMigrate(value any) error
 	Create(ctx context.Context, value any) error
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"time"
+)
+
+// Topics shared between services
+const (
+	TopicTweetPosted       = "TweetPosted"
+	TopicTweetDeleted      = "TweetDeleted"
+	TopicTimelineViewed    = "TimelineViewed"
+	TopicTweetEngaged      = "TweetEngaged"
+	TopicProfileViewed     = "ProfileViewed"
+	TopicFollowChanged     = "FollowChanged"
+	TopicUserInactive      = "UserInactive"
+	TopicUserDeleted       = "UserDeleted"
+	TopicUserDataDeleted   = "UserDataDeleted"
+	TopicUserDeactivated   = "UserDeactivated"
+	TopicUserReactivated   = "UserReactivated"
+	TopicUserHandleChanged = "UserHandleChanged"
+)
+
+// Services that delete their data about a deleted user, and acknowledge it with a UserDataDeleted event
+const (
+	ServiceTweets    = "tweets"
+	ServiceFeed      = "feed"
+	ServiceAnalytics = "analytics"
+)
+
+// Event types processed by the analytics service
+const (
+	TypeTweetCreated   = "tweet_created"
+	TypeTimelineViewed = "timeline_viewed"
+	TypeTweetLiked     = "tweet_liked"
+	TypeTweetReplied   = "tweet_replied"
+	TypeProfileViewed  = "profile_viewed"
+	TypeUserFollowed   = "user_followed"
+	TypeUserUnfollowed = "user_unfollowed"
+)
+
+// Event is an activity event published to the analytics service
+type Event struct {
+	ID            string    `json:"id"`
+	EventType     string    `json:"event_type"`
+	Handler       string    `json:"handler"`
+	TargetHandler string    `json:"target_handler,omitempty"` // User followed or unfollowed
+	TweetID       string    `json:"tweet_id,omitempty"`
+	TweetIDs      []string  `json:"tweet_ids,omitempty"`
+	Hashtags      []string  `json:"hashtags,omitempty"`
+	Mentions      []string  `json:"mentions,omitempty"`
+	ContentHash   string    `json:"content_hash,omitempty"` // Fingerprint of the text of the tweet
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// TweetDeleted is published when a tweet is deleted, so the copies of the tweet kept by other services can be removed
+type TweetDeleted struct {
+	TweetID   string    `json:"tweet_id"`
+	Handler   string    `json:"handler"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UserInactive is published when a user is marked inactive after an idle window
+type UserInactive struct {
+	Handler   string    `json:"handler"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UserDeleted is published when a user is deleted, so every service deletes its data about the user
+type UserDeleted struct {
+	DeletionID string    `json:"deletion_id"`
+	Handler    string    `json:"handler"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// UserDataDeleted is published by a service once it deleted its data about a deleted user, to track the deletion
+type UserDataDeleted struct {
+	DeletionID string    `json:"deletion_id"`
+	Handler    string    `json:"handler"`
+	Service    string    `json:"service"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// UserDeactivated is published when a user deactivates their account, so the services hide their content
+type UserDeactivated struct {
+	Handler   string    `json:"handler"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UserReactivated is published when a deactivated user reactivates their account, so the services restore their content
+type UserReactivated struct {
+	Handler   string    `json:"handler"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// UserHandleChanged is published when a user changes their handle, their ID does not change
+type UserHandleChanged struct {
+	UserID     string    `json:"user_id"`
+	OldHandler string    `json:"old_handler"`
+	NewHandler string    `json:"new_handler"`
+	Timestamp  time.Time `json:"timestamp"`
+}
@@ -0,0 +1,130 @@
+package hyperloglog
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+const (
+	// MinPrecision is the minimum number of bits used to select a register
+	MinPrecision = 4
+	// MaxPrecision is the maximum number of bits used to select a register
+	MaxPrecision = 16
+)
+
+// ErrPrecisionMismatch is returned when merging sketches with different precisions
+var ErrPrecisionMismatch = errors.New("sketches have different precisions")
+
+// Sketch estimates the number of distinct values added to it using 2^precision registers of one byte
+type Sketch struct {
+	precision uint8
+	registers []uint8
+}
+
+// New creates an empty sketch, the precision is clamped to [MinPrecision, MaxPrecision]
+func New(precision uint8) *Sketch {
+	precision = min(max(precision, MinPrecision), MaxPrecision)
+	return &Sketch{
+		precision: precision,
+		registers: make([]uint8, 1<<precision),
+	}
+}
+
+// Add adds a value to the sketch
+func (sketch *Sketch) Add(value string) {
+	hash := hash64(value)
+
+	// The first bits select the register, the rank of the remaining ones is kept
+	index := hash >> (64 - sketch.precision)
+	rank := uint8(bits.LeadingZeros64(hash<<sketch.precision|1<<(sketch.precision-1)) + 1)
+	if rank > sketch.registers[index] {
+		sketch.registers[index] = rank
+	}
+}
+
+// Merge adds the values of other to the sketch
+func (sketch *Sketch) Merge(other *Sketch) error {
+	if sketch.precision != other.precision {
+		return ErrPrecisionMismatch
+	}
+	for i, rank := range other.registers {
+		if rank > sketch.registers[i] {
+			sketch.registers[i] = rank
+		}
+	}
+	return nil
+}
+
+// Count estimates the number of distinct values added to the sketch
+func (sketch *Sketch) Count() uint64 {
+	registers := float64(len(sketch.registers))
+
+	sum := 0.0
+	zeros := 0
+	for _, rank := range sketch.registers {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+	estimate := alpha(len(sketch.registers)) * registers * registers / sum
+
+	// Use linear counting for small cardinalities
+	if estimate <= 2.5*registers && zeros > 0 {
+		estimate = registers * math.Log(registers/float64(zeros))
+	}
+
+	return uint64(estimate + 0.5)
+}
+
+// MarshalBinary encodes the sketch as its precision followed by its registers
+func (sketch *Sketch) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 0, len(sketch.registers)+1)
+	data = append(data, sketch.precision)
+	return append(data, sketch.registers...), nil
+}
+
+// UnmarshalBinary decodes a sketch encoded by MarshalBinary
+func (sketch *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return errors.New("empty sketch data")
+	}
+	precision := data[0]
+	if precision < MinPrecision || precision > MaxPrecision || len(data)-1 != 1<<precision {
+		return errors.New("invalid sketch data")
+	}
+
+	sketch.precision = precision
+	sketch.registers = append([]uint8(nil), data[1:]...)
+	return nil
+}
+
+// alpha is the bias correction constant for the number of registers
+func alpha(registers int) float64 {
+	switch registers {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	default:
+		return 0.7213 / (1 + 1.079/float64(registers))
+	}
+}
+
+// hash64 hashes a value with FNV-1a, finalized with the SplitMix64 mixer to spread its bits
+func hash64(value string) uint64 {
+	hasher := fnv.New64a()
+	hasher.Write([]byte(value))
+	hash := hasher.Sum64()
+
+	hash ^= hash >> 30
+	hash *= 0xbf58476d1ce4e5b9
+	hash ^= hash >> 27
+	hash *= 0x94d049bb133111eb
+	hash ^= hash >> 31
+	return hash
+}
@@ -0,0 +1,69 @@
+package hyperloglog
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSketch_Count(t *testing.T) {
+	tt := []struct {
+		name     string
+		distinct int
+	}{
+		{name: "empty", distinct: 0},
+		{name: "small cardinality", distinct: 100},
+		{name: "large cardinality", distinct: 100000},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sketch := New(10)
+			for i := 0; i < tc.distinct; i++ {
+				// Duplicates must not be counted
+				sketch.Add(fmt.Sprintf("user%d", i))
+				sketch.Add(fmt.Sprintf("user%d", i))
+			}
+
+			// Allow three times the standard error
+			assert.InDelta(t, tc.distinct, sketch.Count(), float64(tc.distinct)*3*0.0325)
+		})
+	}
+}
+
+func TestSketch_Merge(t *testing.T) {
+	first := New(10)
+	second := New(10)
+	for i := 0; i < 1000; i++ {
+		first.Add(fmt.Sprintf("user%d", i))
+		second.Add(fmt.Sprintf("user%d", i+500))
+	}
+
+	require.NoError(t, first.Merge(second))
+	assert.InDelta(t, 1500, first.Count(), 1500*3*0.0325)
+
+	assert.ErrorIs(t, first.Merge(New(12)), ErrPrecisionMismatch)
+}
+
+func TestSketch_MarshalBinary(t *testing.T) {
+	sketch := New(4)
+	sketch.Add("user1")
+	sketch.Add("user2")
+
+	data, err := sketch.MarshalBinary()
+	require.NoError(t, err)
+	assert.Len(t, data, 17)
+
+	var decoded Sketch
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	assert.Equal(t, sketch, &decoded)
+
+	assert.Error(t, decoded.UnmarshalBinary(nil))
+	assert.Error(t, decoded.UnmarshalBinary(data[:10]))
+}
+
+func TestNew_ClampsPrecision(t *testing.T) {
+	assert.Len(t, New(0).registers, 1<<MinPrecision)
+	assert.Len(t, New(30).registers, 1<<MaxPrecision)
+}
@@ -0,0 +1,267 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// KafkaConfig configures a Kafka queue
+type KafkaConfig struct {
+	Brokers         string        // Comma separated list of bootstrap servers
+	GroupID         string        // Consumer group of the service, each group gets every message of the topics it subscribes to
+	RetryBackoff    time.Duration // Wait before consuming a failed message again, doubled after every failure
+	MaxRetryBackoff time.Duration // Longest wait before consuming a failed message again
+}
+
+// DeadLetterTopic is the topic the invalid messages of a topic are moved to
+func DeadLetterTopic(topic string) string {
+	return topic + ".DeadLetter"
+}
+
+// KafkaQueue is a Kafka implementation of the Publisher and Subscriber interfaces
+type KafkaQueue struct {
+	producer *kafka.Producer
+	consumer *kafka.Consumer
+	config   KafkaConfig
+	mu       sync.RWMutex
+	handlers map[string][]Handler // topic -> []Handler
+	// failures are the consecutive failures of the partitions, and paused the partitions waiting to consume a failed
+	// message again, both by topic and partition and only used by Run
+	failures map[string]int
+	paused   map[string]*pausedPartition
+}
+
+// pausedPartition is a partition paused after a message failed, consumed again from that message once resumed
+type pausedPartition struct {
+	partition kafka.TopicPartition
+	resumeAt  time.Time
+}
+
+// NewKafkaQueue creates a new Kafka queue connected to the brokers
+func NewKafkaQueue(config KafkaConfig) (*KafkaQueue, error) {
+	config.MaxRetryBackoff = max(config.MaxRetryBackoff, config.RetryBackoff)
+
+	producer, err := kafka.NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers": config.Brokers,
+		"acks":              "all",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka producer: %w", err)
+	}
+	consumer, err := kafka.NewConsumer(&kafka.ConfigMap{
+		"bootstrap.servers":        config.Brokers,
+		"group.id":                 config.GroupID,
+		"auto.offset.reset":        "earliest",
+		"enable.auto.commit":       false,
+		"allow.auto.create.topics": true,
+	})
+	if err != nil {
+		producer.Close()
+		return nil, fmt.Errorf("failed to create kafka consumer: %w", err)
+	}
+
+	// Drain the producer events, delivery reports go to the channel of each message
+	go func() {
+		for event := range producer.Events() {
+			if err, ok := event.(kafka.Error); ok {
+				log.Printf("kafka producer error: %v", err)
+			}
+		}
+	}()
+
+	return &KafkaQueue{
+		producer: producer,
+		consumer: consumer,
+		config:   config,
+		handlers: make(map[string][]Handler),
+		failures: make(map[string]int),
+		paused:   make(map[string]*pausedPartition),
+	}, nil
+}
+
+// Publish encodes the payload as JSON and produces it to the topic, the messages of a key keep their order
+func (queue *KafkaQueue) Publish(ctx context.Context, topic string, key string, payload any) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", topic, err)
+	}
+
+	return queue.produce(ctx, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            []byte(key),
+		Value:          bytes,
+	})
+}
+
+// produce produces a message, waiting until the brokers acknowledge it
+func (queue *KafkaQueue) produce(ctx context.Context, message *kafka.Message) error {
+	topic := *message.TopicPartition.Topic
+	deliveries := make(chan kafka.Event, 1)
+	if err := queue.producer.Produce(message, deliveries); err != nil {
+		return fmt.Errorf("failed to publish %s message: %w", topic, err)
+	}
+
+	select {
+	case event := <-deliveries:
+		if message, ok := event.(*kafka.Message); ok && message.TopicPartition.Error != nil {
+			return fmt.Errorf("failed to publish %s message: %w", topic, message.TopicPartition.Error)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Subscribe registers a handler for a topic, it must be called before Run
+func (queue *KafkaQueue) Subscribe(topic string, handler Handler) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.handlers[topic] = append(queue.handlers[topic], handler)
+}
+
+// Run consumes the topics with handlers until the context is done, retrying the failed messages after a backoff
+func (queue *KafkaQueue) Run(ctx context.Context) error {
+	queue.mu.RLock()
+	topics := make([]string, 0, len(queue.handlers))
+	for topic := range queue.handlers {
+		topics = append(topics, topic)
+	}
+	queue.mu.RUnlock()
+
+	if len(topics) == 0 {
+		<-ctx.Done()
+		return nil
+	}
+	if err := queue.consumer.SubscribeTopics(topics, nil); err != nil {
+		return fmt.Errorf("failed to subscribe to kafka topics: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		queue.resumePartitions(time.Now())
+
+		consumed, err := queue.consumer.ReadMessage(100 * time.Millisecond)
+		if err != nil {
+			var kafkaErr kafka.Error
+			if !errors.As(err, &kafkaErr) || kafkaErr.Code() != kafka.ErrTimedOut {
+				log.Printf("failed to consume kafka message: %v", err)
+			}
+			continue
+		}
+
+		message := &Message{
+			Topic:   *consumed.TopicPartition.Topic,
+			Key:     string(consumed.Key),
+			Payload: consumed.Value,
+		}
+		err = queue.dispatch(ctx, message)
+		if errors.Is(err, ErrInvalidMessage) {
+			err = queue.deadLetter(ctx, consumed, err)
+		}
+		if err != nil {
+			queue.retryLater(consumed, err)
+			continue
+		}
+
+		delete(queue.failures, partitionKey(consumed.TopicPartition))
+		if _, err := queue.consumer.CommitMessage(consumed); err != nil {
+			log.Printf("failed to commit %s message: %v", message.Topic, err)
+		}
+	}
+	return nil
+}
+
+// dispatch delivers a message to the topic handlers until one fails with an error other than ErrInvalidMessage
+func (queue *KafkaQueue) dispatch(ctx context.Context, message *Message) error {
+	queue.mu.RLock()
+	handlers := make([]Handler, len(queue.handlers[message.Topic]))
+	copy(handlers, queue.handlers[message.Topic])
+	queue.mu.RUnlock()
+
+	var invalid error
+	for _, handler := range handlers {
+		err := handler(ctx, message)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, ErrInvalidMessage) {
+			return err
+		}
+		invalid = err
+	}
+	return invalid
+}
+
+// deadLetter moves an invalid message to the dead letter topic of its topic, with the reason in the error header
+func (queue *KafkaQueue) deadLetter(ctx context.Context, consumed *kafka.Message, reason error) error {
+	topic := DeadLetterTopic(*consumed.TopicPartition.Topic)
+	log.Printf("dead-lettering %s message with key %s to %s: %v", *consumed.TopicPartition.Topic, consumed.Key, topic, reason)
+
+	return queue.produce(ctx, &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
+		Key:            consumed.Key,
+		Value:          consumed.Value,
+		Headers:        []kafka.Header{{Key: "error", Value: []byte(reason.Error())}},
+	})
+}
+
+// retryLater pauses the partition of a failed message and seeks it back to consume it again after a backoff
+func (queue *KafkaQueue) retryLater(consumed *kafka.Message, reason error) {
+	key := partitionKey(consumed.TopicPartition)
+	queue.failures[key]++
+	backoff := retryBackoff(queue.config.RetryBackoff, queue.config.MaxRetryBackoff, queue.failures[key])
+	log.Printf("failed to handle %s message with key %s, retrying in %s: %v", *consumed.TopicPartition.Topic, consumed.Key, backoff, reason)
+
+	partition := consumed.TopicPartition
+	partition.Error = nil
+	if err := queue.consumer.Pause([]kafka.TopicPartition{partition}); err != nil {
+		log.Printf("failed to pause %s: %v", key, err)
+	}
+	if err := queue.consumer.Seek(partition, 1000); err != nil {
+		log.Printf("failed to seek %s back to offset %v: %v", key, partition.Offset, err)
+	}
+	queue.paused[key] = &pausedPartition{partition: partition, resumeAt: time.Now().Add(backoff)}
+}
+
+// resumePartitions resumes the paused partitions whose backoff is over
+func (queue *KafkaQueue) resumePartitions(now time.Time) {
+	for key, paused := range queue.paused {
+		if now.Before(paused.resumeAt) {
+			continue
+		}
+		// A partition revoked in the meantime is consumed again from its committed offset by its new owner
+		if err := queue.consumer.Resume([]kafka.TopicPartition{paused.partition}); err != nil {
+			log.Printf("failed to resume %s: %v", key, err)
+		}
+		delete(queue.paused, key)
+	}
+}
+
+// partitionKey identifies a partition of a topic
+func partitionKey(partition kafka.TopicPartition) string {
+	return fmt.Sprintf("%s[%d]", *partition.Topic, partition.Partition)
+}
+
+// retryBackoff is the wait after the given consecutive failures, doubled after every failure up to the max
+func retryBackoff(backoff, maxBackoff time.Duration, failures int) time.Duration {
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// Close flushes the messages not delivered yet and closes the connections to the brokers
+func (queue *KafkaQueue) Close() {
+	queue.producer.Flush(5000)
+	queue.producer.Close()
+	if err := queue.consumer.Close(); err != nil {
+		log.Printf("failed to close kafka consumer: %v", err)
+	}
+}
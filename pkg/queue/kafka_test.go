@@ -0,0 +1,213 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKafkaQueue creates a Kafka queue of a consumer group connected to the cluster, closed at the end of the test
+func newTestKafkaQueue(t *testing.T, cluster *kafka.MockCluster, groupID string) *KafkaQueue {
+	messageQueue, err := NewKafkaQueue(KafkaConfig{
+		Brokers:         cluster.BootstrapServers(),
+		GroupID:         groupID,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(messageQueue.Close)
+	return messageQueue
+}
+
+func TestKafkaQueue_PublishAcrossServices(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Each service has its own queue and consumer group, as if they ran in separate processes
+	publisher := newTestKafkaQueue(t, cluster, "users-service")
+
+	var mu sync.Mutex
+	received := map[string][]testPayload{}
+	var wg sync.WaitGroup
+	for _, groupID := range []string{"feed-service", "analytics-service"} {
+		consumer := newTestKafkaQueue(t, cluster, groupID)
+		consumer.Subscribe("topic", func(ctx context.Context, message *Message) error {
+			var payload testPayload
+			if err := message.Decode(&payload); err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			received[groupID] = append(received[groupID], payload)
+			return nil
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, consumer.Run(ctx))
+		}()
+	}
+
+	require.NoError(t, publisher.Publish(ctx, "topic", "user1", testPayload{Handler: "user1"}))
+	require.NoError(t, publisher.Publish(ctx, "topic", "user1", testPayload{Handler: "user2"}))
+
+	// Every service gets every message, in the order they were published with the same key
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received["feed-service"]) == 2 && len(received["analytics-service"]) == 2
+	}, 30*time.Second, 50*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+	assert.Equal(t, []testPayload{{Handler: "user1"}, {Handler: "user2"}}, received["feed-service"])
+	assert.Equal(t, []testPayload{{Handler: "user1"}, {Handler: "user2"}}, received["analytics-service"])
+}
+
+func TestKafkaQueue_PublishInvalidPayload(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	messageQueue := newTestKafkaQueue(t, cluster, "users-service")
+
+	err = messageQueue.Publish(context.Background(), "topic", "key", make(chan int))
+
+	assert.Error(t, err)
+}
+
+func TestKafkaQueue_Dispatch(t *testing.T) {
+	ctx := context.Background()
+	transientErr := errors.New("database unavailable")
+	invalidErr := fmt.Errorf("%w: event without handler", ErrInvalidMessage)
+
+	tt := []struct {
+		name          string
+		errs          []error
+		wantErr       error
+		wantDelivered int
+	}{
+		{
+			name:          "handled",
+			errs:          []error{nil, nil},
+			wantDelivered: 2,
+		},
+		{
+			name:          "failed, delivered again to every handler",
+			errs:          []error{transientErr, nil},
+			wantErr:       transientErr,
+			wantDelivered: 1,
+		},
+		{
+			name:          "invalid",
+			errs:          []error{invalidErr, nil},
+			wantErr:       invalidErr,
+			wantDelivered: 2,
+		},
+		{
+			name:          "failed after invalid",
+			errs:          []error{invalidErr, transientErr},
+			wantErr:       transientErr,
+			wantDelivered: 2,
+		},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			messageQueue := &KafkaQueue{handlers: make(map[string][]Handler)}
+			delivered := 0
+			for _, err := range tc.errs {
+				messageQueue.Subscribe("topic", func(ctx context.Context, message *Message) error {
+					delivered++
+					return err
+				})
+			}
+
+			err := messageQueue.dispatch(ctx, &Message{Topic: "topic", Key: "user1", Payload: []byte(`{}`)})
+
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantDelivered, delivered)
+		})
+	}
+}
+
+func TestKafkaQueue_RunRetriesFailedMessages(t *testing.T) {
+	cluster, err := kafka.NewMockCluster(1)
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := newTestKafkaQueue(t, cluster, "users-service")
+	consumer := newTestKafkaQueue(t, cluster, "feed-service")
+
+	// The first message fails twice, the invalid one is dead-lettered
+	var mu sync.Mutex
+	var handled []string
+	failures := 0
+	consumer.Subscribe("topic", func(ctx context.Context, message *Message) error {
+		var payload testPayload
+		if err := message.Decode(&payload); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if payload.Handler == "user1" && failures < 2 {
+			failures++
+			return errors.New("database unavailable")
+		}
+		handled = append(handled, payload.Handler)
+		return nil
+	})
+	deadLetters := newTestKafkaQueue(t, cluster, "dead-letters")
+	var deadLettered []string
+	deadLetters.Subscribe(DeadLetterTopic("topic"), func(ctx context.Context, message *Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		deadLettered = append(deadLettered, string(message.Payload))
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for _, messageQueue := range []*KafkaQueue{consumer, deadLetters} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, messageQueue.Run(ctx))
+		}()
+	}
+
+	require.NoError(t, publisher.Publish(ctx, "topic", "user1", testPayload{Handler: "user1"}))
+	require.NoError(t, publisher.Publish(ctx, "topic", "user1", "invalid"))
+	require.NoError(t, publisher.Publish(ctx, "topic", "user1", testPayload{Handler: "user2"}))
+
+	// The failed message is consumed again before the next ones of its partition
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 2 && len(deadLettered) == 1
+	}, 30*time.Second, 50*time.Millisecond)
+
+	cancel()
+	wg.Wait()
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, []string{"user1", "user2"}, handled)
+	assert.Equal(t, []string{`"invalid"`}, deadLettered)
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, retryBackoff(time.Second, time.Minute, 1))
+	assert.Equal(t, 4*time.Second, retryBackoff(time.Second, time.Minute, 3))
+	assert.Equal(t, time.Minute, retryBackoff(time.Second, time.Minute, 1000))
+}
@@ -0,0 +1,90 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+//go:generate mockgen -source=queue.go -destination=queue_mock.go -package=queue
+
+// ErrInvalidMessage is returned by the handlers of a message that can never be handled, like an undecodable one
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Message represents a message published to a topic
+type Message struct {
+	Topic   string
+	Key     string
+	Payload []byte
+}
+
+// Decode unmarshals the message payload into value
+func (m *Message) Decode(value any) error {
+	if err := json.Unmarshal(m.Payload, value); err != nil {
+		return fmt.Errorf("%w: failed to decode %s message: %w", ErrInvalidMessage, m.Topic, err)
+	}
+	return nil
+}
+
+// Handler processes a message consumed from a topic
+type Handler func(ctx context.Context, message *Message) error
+
+// Publisher publishes messages to topics. The changes are stored first, so the services only log a publishing failure.
+type Publisher interface {
+	Publish(ctx context.Context, topic string, key string, payload any) error
+}
+
+// Subscriber registers handlers for topics
+type Subscriber interface {
+	Subscribe(topic string, handler Handler)
+}
+
+// InMemoryQueue is an in-memory implementation of the Publisher and Subscriber interfaces
+type InMemoryQueue struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler // topic -> []Handler
+}
+
+// NewInMemoryQueue creates a new in-memory queue
+func NewInMemoryQueue() *InMemoryQueue {
+	return &InMemoryQueue{
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Publish encodes the payload as JSON and delivers it to the topic handlers
+func (queue *InMemoryQueue) Publish(ctx context.Context, topic string, key string, payload any) error {
+	bytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", topic, err)
+	}
+
+	queue.mu.RLock()
+	handlers := make([]Handler, len(queue.handlers[topic]))
+	copy(handlers, queue.handlers[topic])
+	queue.mu.RUnlock()
+
+	message := &Message{
+		Topic:   topic,
+		Key:     key,
+		Payload: bytes,
+	}
+	for _, handler := range handlers {
+		if err := handler(ctx, message); err != nil {
+			log.Printf("failed to handle %s message with key %s: %v", topic, key, err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe registers a handler for a topic
+func (queue *InMemoryQueue) Subscribe(topic string, handler Handler) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.handlers[topic] = append(queue.handlers[topic], handler)
+}
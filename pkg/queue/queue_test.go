@@ -0,0 +1,47 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	Handler string `json:"handler"`
+}
+
+func TestInMemoryQueue_Publish(t *testing.T) {
+	ctx := context.Background()
+
+	messageQueue := NewInMemoryQueue()
+
+	var received []testPayload
+	messageQueue.Subscribe("topic", func(ctx context.Context, message *Message) error {
+		var payload testPayload
+		if err := message.Decode(&payload); err != nil {
+			return err
+		}
+		received = append(received, payload)
+		return nil
+	})
+	// A failing handler must not prevent delivery to the others
+	messageQueue.Subscribe("topic", func(ctx context.Context, message *Message) error {
+		return errors.New("handler error")
+	})
+
+	require.NoError(t, messageQueue.Publish(ctx, "topic", "user1", testPayload{Handler: "user1"}))
+	require.NoError(t, messageQueue.Publish(ctx, "other-topic", "user2", testPayload{Handler: "user2"}))
+
+	assert.Equal(t, []testPayload{{Handler: "user1"}}, received)
+}
+
+func TestInMemoryQueue_PublishInvalidPayload(t *testing.T) {
+	messageQueue := NewInMemoryQueue()
+
+	err := messageQueue.Publish(context.Background(), "topic", "key", make(chan int))
+
+	assert.Error(t, err)
+}